@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	mathrand "math/rand"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"universal_api/internal/cluster"
+	"universal_api/internal/redact"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxLoggedBody is the most of a request body read for the access log
+const maxLoggedBody = 64 << 10
+
+// configureAccessLog returns the access log middleware configured by ACCESS_LOG (on or off, default on),
+// ACCESS_LOG_SAMPLE (the fraction of successful requests logged, default 1), and ACCESS_LOG_DETAIL
+// (true to log request headers and bodies), or nil when the access log is off
+func configureAccessLog(enabled, sampleRate, detail string) (gin.HandlerFunc, error) {
+	if enabled == "off" || enabled == "false" {
+		return nil, nil
+	}
+
+	rate := 1.0
+	if sampleRate != "" {
+		var err error
+		rate, err = strconv.ParseFloat(sampleRate, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("invalid ACCESS_LOG_SAMPLE %q: must be between 0 and 1", sampleRate)
+		}
+	}
+
+	return accessLog(slog.New(slog.NewJSONHandler(os.Stdout, nil)), rate, detail == "true"), nil
+}
+
+// accessLog logs each request as a structured line: its method, path, status, latency, client, and request ID.
+// Requests that fail are always logged, and a sample of the others. Credentials never reach the log: the
+// values of credential query parameters, headers, and body fields, and the passwords of URLs, are redacted.
+func accessLog(logger *slog.Logger, sampleRate float64, detail bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		var body []byte
+		if detail && c.Request.Body != nil {
+			body, _ = io.ReadAll(io.LimitReader(c.Request.Body, maxLoggedBody))
+			c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < 400 && mathrand.Float64() >= sampleRate {
+			return
+		}
+
+		attributes := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", redact.URL(c.Request.URL.RequestURI())),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client", c.ClientIP()),
+			slog.String("request_id", requestID(c)),
+			slog.String("instance", cluster.InstanceID()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if detail {
+			attributes = append(attributes, slog.Any("headers", redact.Headers(c.Request.Header)))
+			if fields := loggedBody(c.ContentType(), body); fields != nil {
+				attributes = append(attributes, slog.Any("body", fields))
+			}
+		}
+		if len(c.Errors) > 0 {
+			attributes = append(attributes, slog.String("error", c.Errors.String()))
+		}
+
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelError
+		}
+		logger.LogAttrs(c.Request.Context(), level, "request", attributes...)
+	}
+}
+
+// loggedBody decodes a JSON or form body for the access log with its credentials redacted,
+// or returns nil for other bodies, which are left out
+func loggedBody(contentType string, body []byte) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+
+	switch contentType {
+	case "application/json":
+		var fields interface{}
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return nil
+		}
+		return redact.Fields(fields)
+	case "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil
+		}
+		fields := make(map[string]interface{}, len(form))
+		for name, values := range form {
+			fields[name] = values[0]
+		}
+		return redact.Fields(fields)
+	}
+	return nil
+}
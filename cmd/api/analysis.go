@@ -0,0 +1,79 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"universal_api/internal/analysis"
+	"universal_api/internal/linkcheck"
+	"universal_api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler to report near-duplicate docs across the catalog
+func getDuplicateAPIDocs(c *gin.Context) {
+	threshold := analysis.DefaultThreshold
+	if value := c.Query("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 || parsed > 1 {
+			respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "Invalid threshold: must be a number in (0, 1]")
+			return
+		}
+		threshold = parsed
+	}
+
+	docs, err := docStore(c).GetAllAPIDocs()
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get API docs: "+err.Error())
+		return
+	}
+
+	respondList(c, analysis.FindDuplicates(docs, threshold))
+}
+
+// Handler to report the docs of the workspace whose source URL is gone, longest gone first
+func getStaleSources(c *gin.Context) {
+	checks, err := sourceCheckStore.GetSourceChecks()
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get source checks: "+err.Error())
+		return
+	}
+
+	var inWorkspace []*models.SourceCheck
+	for _, check := range checks {
+		if inCurrentWorkspace(c, check.Workspace) {
+			inWorkspace = append(inWorkspace, check)
+		}
+	}
+
+	respondList(c, linkcheck.Stale(inWorkspace))
+}
+
+// Handler to check an API doc's source URL now instead of waiting for the scheduled check
+func checkAPIDocSource(c *gin.Context) {
+	doc, err := docStore(c).GetAPIDoc(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	check, err := sourceChecker.CheckDoc(doc.ID)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "Failed to check source: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusOK, check)
+}
+
+// Handler to get the dependency graph of an API doc's endpoints
+func getAPIDocGraph(c *gin.Context) {
+	doc, err := docStore(c).GetAPIDoc(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusOK, analysis.DependencyGraph(doc))
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"universal_api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAttachmentSize limits the size of uploaded attachments
+const maxAttachmentSize = 20 << 20
+
+// inlineContentTypes are the attachment types safe to show in the browser; others are always downloaded
+var inlineContentTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+// Handler to list the attachments of an API doc
+func getAPIDocAttachments(c *gin.Context) {
+	if _, err := docStore(c).GetAPIDoc(c.Param("id")); err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	attachments, err := attachmentStore.GetDocAttachments(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get attachments: "+err.Error())
+		return
+	}
+
+	respondList(c, attachments)
+}
+
+// Handler to attach a file uploaded as the multipart field file to an API doc. The kind field
+// is file by default, or logo for an image replacing the doc's current logo.
+func uploadAPIDocAttachment(c *gin.Context) {
+	if _, err := docStore(c).GetAPIDoc(c.Param("id")); err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if bodyTooLarge(c, err) {
+		return
+	}
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "Invalid upload: "+err.Error())
+		return
+	}
+	if header.Size > maxAttachmentSize {
+		respondProblem(c, http.StatusRequestEntityTooLarge, codePayloadTooLarge, fmt.Sprintf("Attachment is larger than %d MB", maxAttachmentSize>>20))
+		return
+	}
+
+	kind := c.DefaultPostForm("kind", models.AttachmentKindFile)
+	if kind != models.AttachmentKindFile && kind != models.AttachmentKindLogo {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "Invalid kind: must be file or logo")
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "Invalid upload: "+err.Error())
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "Invalid upload: "+err.Error())
+		return
+	}
+
+	// Trust the content, not the client's claimed type, for what the browser may show
+	contentType := http.DetectContentType(data)
+	if kind == models.AttachmentKindLogo && (!inlineContentTypes[contentType] || contentType == "application/pdf") {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "Logo must be a PNG, JPEG, GIF, or WebP image")
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	now := time.Now()
+	attachment := &models.Attachment{
+		ID:          fmt.Sprintf("att-%d", now.UnixNano()),
+		DocID:       c.Param("id"),
+		Kind:        kind,
+		Name:        filepath.Base(header.Filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		SHA256:      hex.EncodeToString(sum[:]),
+		CreatedAt:   now,
+	}
+
+	// A doc has a single logo
+	if kind == models.AttachmentKindLogo {
+		existing, _ := attachmentStore.GetDocAttachments(attachment.DocID)
+		for _, previous := range existing {
+			if previous.Kind == models.AttachmentKindLogo {
+				attachmentStore.DeleteAttachment(previous.ID)
+			}
+		}
+	}
+
+	if err := attachmentStore.SaveAttachment(attachment, data); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to save attachment: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusCreated, attachment)
+}
+
+// docAttachment returns an attachment of the API doc in the path, responding with an error and returning nil if there's none
+func docAttachment(c *gin.Context) *models.Attachment {
+	if _, err := docStore(c).GetAPIDoc(c.Param("id")); err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return nil
+	}
+
+	attachment, err := attachmentStore.GetAttachment(c.Param("attachment"))
+	if err != nil || attachment.DocID != c.Param("id") {
+		respondProblem(c, http.StatusNotFound, codeNotFound, "Attachment not found: "+c.Param("attachment"))
+		return nil
+	}
+	return attachment
+}
+
+// Handler to download an attachment of an API doc
+func downloadAPIDocAttachment(c *gin.Context) {
+	attachment := docAttachment(c)
+	if attachment == nil {
+		return
+	}
+
+	data, err := attachmentStore.GetAttachmentData(attachment.ID)
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get attachment: "+err.Error())
+		return
+	}
+
+	disposition := "attachment"
+	if inlineContentTypes[attachment.ContentType] {
+		disposition = "inline"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, attachment.Name))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, attachment.ContentType, data)
+}
+
+// Handler to delete an attachment of an API doc
+func deleteAPIDocAttachment(c *gin.Context) {
+	attachment := docAttachment(c)
+	if attachment == nil {
+		return
+	}
+
+	if err := attachmentStore.DeleteAttachment(attachment.ID); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to delete attachment: "+err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
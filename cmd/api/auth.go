@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"universal_api/internal/auth"
+	"universal_api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userContextKey is the Gin context key of the authenticated user
+const userContextKey = "user"
+
+// Middleware authenticating API requests by API key or OIDC JWT, once users exist
+func authenticate(c *gin.Context) {
+	if !auth.Enabled(userStore) {
+		c.Next()
+		return
+	}
+
+	user, err := auth.AuthenticateToken(userStore, oidcProvider, requestAPIKey(c))
+	if err != nil {
+		respondProblem(c, http.StatusUnauthorized, codeUnauthenticated, err.Error())
+		return
+	}
+
+	c.Set(userContextKey, user)
+	c.Next()
+}
+
+// authorize returns middleware requiring the authenticated user to have a permission
+// in the request's workspace. Every request is allowed until users exist.
+func authorize(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := currentUser(c)
+		if user == nil {
+			c.Next()
+			return
+		}
+
+		// Routes outside a workspace group have no workspace to check
+		if !auth.Authorize(user, permission, c.GetString(workspaceContextKey)) {
+			respondProblem(c, http.StatusForbidden, codeForbidden, "The "+user.Role+" role can't do this here")
+			return
+		}
+		c.Next()
+	}
+}
+
+// requestAPIKey returns the API key or JWT of a request, from an Authorization bearer token or the X-API-Key header,
+// or else from the cookies the UI is signed in with, so its scripts can call the API. The cookies are SameSite=Lax,
+// so other sites' scripts and forms can't send them.
+func requestAPIKey(c *gin.Context) string {
+	if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+	for _, name := range []string{"api_key", "id_token"} {
+		if cookie, err := c.Cookie(name); err == nil && cookie != "" {
+			return cookie
+		}
+	}
+	return ""
+}
+
+// currentUser returns the authenticated user, or nil while no users exist
+func currentUser(c *gin.Context) *models.User {
+	if user, ok := c.Get(userContextKey); ok {
+		return user.(*models.User)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"universal_api/internal/auth"
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authTestUser creates a user of a role with an API key in a new user store for the test, returning the key
+func authTestUser(t *testing.T, role string) string {
+	t.Helper()
+	store := storage.NewMemoryStorage()
+	users := userStore
+	userStore = store
+	t.Cleanup(func() { userStore = users })
+
+	store.SaveUser(&models.User{ID: "user-1", Name: "Ada", Role: role})
+	key, apiKey, err := auth.NewAPIKey("user-1", "test")
+	if err != nil {
+		t.Fatalf("Failed to create API key: %v", err)
+	}
+	store.SaveAPIKey(apiKey)
+	return key
+}
+
+// TestAuthenticate tests that API requests are signed in with an API key header or the UI's session cookie
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	key := authTestUser(t, auth.RoleViewer)
+
+	r := gin.New()
+	r.GET("/api/v2/docs", authenticate, func(c *gin.Context) {
+		c.String(http.StatusOK, currentUserID(c))
+	})
+
+	tests := []struct {
+		name     string
+		header   string
+		value    string
+		expected int
+	}{
+		{"bearer token", "Authorization", "Bearer " + key, http.StatusOK},
+		{"API key header", "X-API-Key", key, http.StatusOK},
+		{"UI session cookie", "Cookie", "api_key=" + key, http.StatusOK},
+		{"unknown key", "X-API-Key", "uapi_unknown", http.StatusUnauthorized},
+		{"unknown session cookie", "Cookie", "api_key=uapi_unknown", http.StatusUnauthorized},
+		{"other cookie", "Cookie", "theme=dark", http.StatusUnauthorized},
+		{"nothing", "", "", http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/docs", nil)
+			if test.header != "" {
+				req.Header.Set(test.header, test.value)
+			}
+			recorder := httptest.NewRecorder()
+			r.ServeHTTP(recorder, req)
+
+			if recorder.Code != test.expected {
+				t.Fatalf("Expected %d, got %d: %s", test.expected, recorder.Code, recorder.Body.String())
+			}
+			if test.expected == http.StatusOK && recorder.Body.String() != "user-1" {
+				t.Errorf("Expected to be signed in as user-1, got %q", recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"universal_api/internal/models"
+	"universal_api/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBatchGetItems bounds the number of docs requested in one batch get
+const maxBatchGetItems = 100
+
+// batchGetRequest lists the docs to get by ID, URL, or both
+type batchGetRequest struct {
+	IDs  []string `json:"ids"`
+	URLs []string `json:"urls"` // the URLs the docs were scraped from
+}
+
+// Validate checks a batch get request lists at least one doc, and not too many
+func (r *batchGetRequest) Validate() error {
+	var v validation.Validator
+	if len(r.IDs)+len(r.URLs) == 0 {
+		v.Add("ids", validation.CodeRequired, "list at least one ID or URL")
+	}
+	if len(r.IDs)+len(r.URLs) > maxBatchGetItems {
+		v.Add("ids", validation.CodeTooLong, fmt.Sprintf("list at most %d IDs and URLs together", maxBatchGetItems))
+	}
+	for i, id := range r.IDs {
+		field := fmt.Sprintf("ids[%d]", i)
+		if v.Required(field, id) {
+			v.MaxLength(field, id, validation.MaxIDLength)
+		}
+	}
+	for i, url := range r.URLs {
+		field := fmt.Sprintf("urls[%d]", i)
+		if v.Required(field, url) {
+			v.MaxLength(field, url, validation.MaxURLLength)
+		}
+	}
+	return v.Err()
+}
+
+// batchGetItem is the outcome of getting one doc of a batch, marked not found instead of failing the batch
+type batchGetItem struct {
+	ID    string      `json:"id,omitempty"`  // as requested, when requested by ID
+	URL   string      `json:"url,omitempty"` // as requested, when requested by URL
+	Found bool        `json:"found"`
+	Doc   interface{} `json:"doc,omitempty"` // the fields of the doc selected by the fields and expand query parameters
+
+	doc *models.APIDoc
+}
+
+// Handler to get several docs in one request, by ID and by URL. Items are returned in the order
+// requested, IDs first, each marked found or not, so one missing doc doesn't fail the rest.
+func batchGetAPIDocs(c *gin.Context) {
+	selection, ok := selectDocFields(c, false)
+	if !ok {
+		return
+	}
+	var request batchGetRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	items := make([]batchGetItem, 0, len(request.IDs)+len(request.URLs))
+	for _, id := range request.IDs {
+		item := batchGetItem{ID: id}
+		if doc, err := docStore(c).GetAPIDoc(id); err == nil {
+			item.Found, item.doc = true, doc
+		}
+		items = append(items, item)
+	}
+
+	if len(request.URLs) > 0 {
+		docs, err := docStore(c).GetAllAPIDocs()
+		if err != nil {
+			respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get API docs: "+err.Error())
+			return
+		}
+		// Of docs scraped from the same URL, the one with the lowest ID is returned
+		byURL := make(map[string]*models.APIDoc, len(docs))
+		for _, doc := range docs {
+			if existing, ok := byURL[doc.URL]; !ok || doc.ID < existing.ID {
+				byURL[doc.URL] = doc
+			}
+		}
+		for _, url := range request.URLs {
+			item := batchGetItem{URL: url}
+			if doc, ok := byURL[url]; ok {
+				item.Found, item.doc = true, doc
+			}
+			items = append(items, item)
+		}
+	}
+
+	for i, item := range items {
+		if !item.Found {
+			continue
+		}
+		// Count views as if each doc was got on its own
+		if err := viewStore.RecordView(item.doc.ID, "", models.ViewKindAPI); err != nil {
+			log.Printf("Failed to record view of %s: %v", item.doc.ID, err)
+		}
+
+		var err error
+		if items[i].Doc, err = selection.apply(item.doc); err != nil {
+			respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to select fields of API docs: "+err.Error())
+			return
+		}
+	}
+
+	respond(c, http.StatusOK, items)
+}
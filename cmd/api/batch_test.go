@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"universal_api/internal/auth"
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// batchTestResponse is the response of a batch get
+type batchTestResponse struct {
+	Data []struct {
+		ID    string                 `json:"id"`
+		URL   string                 `json:"url"`
+		Found bool                   `json:"found"`
+		Doc   map[string]interface{} `json:"doc"`
+	} `json:"data"`
+}
+
+// TestBatchGetAPIDocs tests getting docs by ID and URL in the order requested, marking the ones the caller can't get
+func TestBatchGetAPIDocs(t *testing.T) {
+	r, memoryStore := apiTestServer(t)
+	if err := memoryStore.SaveWorkspace(&models.Workspace{ID: "team-a", Name: "Team A"}); err != nil {
+		t.Fatalf("Failed to save workspace: %v", err)
+	}
+	viewer := apiTestUser(t, memoryStore, "viewer-1", auth.RoleViewer)
+	admin := apiTestUser(t, memoryStore, "admin-1", auth.RoleAdmin)
+
+	docs := []*models.APIDoc{
+		{ID: "pets", Title: "Pets", URL: "https://pets.example.com/openapi.json"},
+		{ID: "stores", Title: "Stores", URL: "https://stores.example.com/openapi.json"},
+		{ID: "mirror-b", Title: "Mirror B", URL: "https://mirror.example.com/openapi.json"},
+		{ID: "mirror-a", Title: "Mirror A", URL: "https://mirror.example.com/openapi.json"},
+		{ID: storage.WorkspaceDocID("team-a", "secret"), Title: "Secret", Workspace: "team-a", URL: "https://secret.example.com/openapi.json"},
+		{ID: "draft", Title: "Draft", URL: "https://draft.example.com/openapi.json", Status: models.StatusDraft, SubmittedBy: "editor-1"},
+	}
+	for _, doc := range docs {
+		if err := store.SaveAPIDoc(doc); err != nil {
+			t.Fatalf("Failed to save doc: %v", err)
+		}
+	}
+
+	body := `{
+		"ids": ["stores", "missing", "pets", "team-a~secret", "secret", "draft"],
+		"urls": ["https://mirror.example.com/openapi.json", "https://none.example.com", "https://secret.example.com/openapi.json", "https://draft.example.com/openapi.json"]
+	}`
+	recorder := serveAPI(r, http.MethodPost, "/api/v2/docs/batch-get?fields=title", body, viewer)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var response batchTestResponse
+	decodeResponse(t, recorder, &response)
+
+	// Docs of other workspaces and drafts of other users are as missing as docs that don't exist
+	expected := []struct {
+		key   string
+		title string
+	}{
+		{"stores", "Stores"},
+		{"missing", ""},
+		{"pets", "Pets"},
+		{"team-a~secret", ""},
+		{"secret", ""},
+		{"draft", ""},
+		{"https://mirror.example.com/openapi.json", "Mirror A"}, // of docs from the same URL, the lowest ID
+		{"https://none.example.com", ""},
+		{"https://secret.example.com/openapi.json", ""},
+		{"https://draft.example.com/openapi.json", ""},
+	}
+	if len(response.Data) != len(expected) {
+		t.Fatalf("Expected %d items, got %s", len(expected), recorder.Body.String())
+	}
+	for i, item := range response.Data {
+		if key := item.ID + item.URL; key != expected[i].key {
+			t.Errorf("Expected item %d to be %s, got %s", i, expected[i].key, key)
+		}
+		if found := expected[i].title != ""; item.Found != found || (item.Doc != nil) != found {
+			t.Errorf("Expected %s found %v, got %v with doc %v", expected[i].key, found, item.Found, item.Doc)
+			continue
+		}
+		if item.Found && (item.Doc["title"] != expected[i].title || item.Doc["url"] != nil) {
+			t.Errorf("Expected only the selected fields of %s, got %v", expected[i].title, item.Doc)
+		}
+	}
+
+	// Admins see drafts, and the docs of a workspace are got in it
+	recorder = serveAPI(r, http.MethodPost, "/api/v2/docs/batch-get", `{"ids": ["draft"]}`, admin)
+	response = batchTestResponse{}
+	decodeResponse(t, recorder, &response)
+	if len(response.Data) != 1 || !response.Data[0].Found {
+		t.Errorf("Expected an admin to get the draft, got %s", recorder.Body.String())
+	}
+	recorder = serveAPI(r, http.MethodPost, "/api/v2/workspaces/team-a/docs/batch-get", `{"ids": ["secret"], "urls": ["https://pets.example.com/openapi.json"]}`, admin)
+	response = batchTestResponse{}
+	decodeResponse(t, recorder, &response)
+	if len(response.Data) != 2 || !response.Data[0].Found || response.Data[0].Doc["title"] != "Secret" || response.Data[1].Found {
+		t.Errorf("Expected team-a's doc only in team-a, got %s", recorder.Body.String())
+	}
+}
+
+// TestBatchGetLimits tests that a batch get lists between one and 100 docs
+func TestBatchGetLimits(t *testing.T) {
+	r, _ := apiTestServer(t)
+
+	ids := make([]string, maxBatchGetItems)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%q", fmt.Sprintf("doc-%d", i))
+	}
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"100 items", `{"ids": [` + strings.Join(ids, ",") + `]}`, http.StatusOK},
+		{"101 items", `{"ids": [` + strings.Join(ids, ",") + `], "urls": ["https://example.com"]}`, http.StatusBadRequest},
+		{"no items", `{"ids": [], "urls": []}`, http.StatusBadRequest},
+		{"empty ID", `{"ids": [""]}`, http.StatusBadRequest},
+		{"no body", ``, http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := serveAPI(r, http.MethodPost, "/api/v2/docs/batch-get", test.body, nil)
+			if recorder.Code != test.status {
+				t.Fatalf("Expected %d, got %d: %s", test.status, recorder.Code, recorder.Body.String())
+			}
+			if test.status == http.StatusBadRequest && !hasProblemCode(t, recorder, codeInvalidRequest) {
+				t.Errorf("Expected an invalid_request problem, got %s", recorder.Body.String())
+			}
+		})
+	}
+}
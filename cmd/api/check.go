@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"universal_api/internal/diff"
+	"universal_api/internal/models"
+	"universal_api/internal/validation"
+	"universal_api/pkg/scraper"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCandidateSpecSize limits the size of candidate specs uploaded to be checked
+const maxCandidateSpecSize = 32 << 20
+
+// checkRequest represents a request to check a candidate spec by URL
+type checkRequest struct {
+	URL string `json:"url" binding:"required"`
+}
+
+// Validate checks the URL of the candidate spec
+func (r checkRequest) Validate() error {
+	var v validation.Validator
+	if v.Required("url", r.URL) {
+		v.URL("url", r.URL, "http", "https")
+	}
+	return v.Err()
+}
+
+// Handler to check a candidate spec against a stored doc for breaking changes.
+// The candidate is accepted as a multipart "spec" file, a JSON {"url": ...} body,
+// or the raw spec as the request body.
+func checkAPIDoc(c *gin.Context) {
+	existing, err := docStore(c).GetAPIDoc(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	candidate, err := readCandidateSpec(c)
+	var fieldErrs validation.Errors
+	if errors.As(err, &fieldErrs) {
+		respondInvalid(c, fieldErrs)
+		return
+	}
+	if bodyTooLarge(c, err) || (err != nil && timedOut(c)) {
+		return
+	}
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "Failed to read candidate spec: "+err.Error())
+		return
+	}
+
+	changes := diff.Compare(existing, candidate)
+	status := http.StatusOK
+	if changes.IsBreaking() {
+		// A non-2xx status lets CI fail with a plain `curl --fail`
+		status = http.StatusConflict
+	}
+
+	respond(c, status, gin.H{
+		"pass":             !changes.IsBreaking(),
+		"breaking_changes": changes.BreakingChanges,
+		"diff":             changes,
+	})
+}
+
+// readCandidateSpec reads and parses the candidate spec from the request
+func readCandidateSpec(c *gin.Context) (*models.APIDoc, error) {
+	contentType := c.ContentType()
+
+	switch {
+	case strings.HasPrefix(contentType, "multipart/form-data"):
+		file, header, err := c.Request.FormFile("spec")
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		content, err := io.ReadAll(file)
+		if err != nil {
+			return nil, err
+		}
+		return scraper.ParseAPIDoc(content, header.Header.Get("Content-Type"))
+
+	case contentType == "application/json" && c.Query("raw") != "true":
+		var request checkRequest
+		if err := decodeJSON(c, &request); err != nil {
+			return nil, jsonError(c, err)
+		}
+		if err := request.Validate(); err != nil {
+			return nil, err
+		}
+		return scraper.ScrapeAPIDocWithOptions(request.URL, scraper.Options{Cache: true, Context: c.Request.Context()})
+
+	default:
+		content, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return nil, err
+		}
+		return scraper.ParseAPIDoc(content, contentType)
+	}
+}
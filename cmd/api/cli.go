@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"universal_api/internal/export"
+	"universal_api/internal/models"
+)
+
+// commandUsage lists the command-line commands
+const commandUsage = `usage: api <command> [arguments]
+
+Commands run against the server at UAPI_SERVER (default http://localhost:8080),
+authenticating with UAPI_API_KEY in the workspace UAPI_WORKSPACE:
+
+  export-site <dir>       render the catalog into a static HTML site in dir
+  export-archive <file>   save the catalog with its versions as a .uapi archive
+  import-archive <file>   import the docs and versions of a .uapi archive`
+
+// runCommand runs a command-line command
+func runCommand(args []string) error {
+	switch args[0] {
+	case "export-site":
+		if len(args) != 2 {
+			return errors.New("usage: api export-site <dir>")
+		}
+		return exportSiteCommand(args[1])
+	case "export-archive":
+		if len(args) != 2 {
+			return errors.New("usage: api export-archive <file>")
+		}
+		return exportArchiveCommand(args[1])
+	case "import-archive":
+		if len(args) != 2 {
+			return errors.New("usage: api import-archive <file>")
+		}
+		return importArchiveCommand(args[1])
+	case "help", "-h", "--help":
+		fmt.Println(commandUsage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], commandUsage)
+	}
+}
+
+// exportSiteCommand renders the server's catalog into a static site in a directory
+func exportSiteCommand(dir string) error {
+	var docs []*models.APIDoc
+	if err := serverRequest(http.MethodGet, "/api/v2/docs?expand=endpoints,schemas", nil, &docs); err != nil {
+		return fmt.Errorf("failed to get API docs: %w", err)
+	}
+
+	if err := export.BuildSite(docs, export.DirWriter(dir)); err != nil {
+		return fmt.Errorf("failed to build site: %w", err)
+	}
+	fmt.Printf("Exported %d API docs to %s\n", len(docs), dir)
+	return nil
+}
+
+// exportArchiveCommand saves the server's catalog as a .uapi archive
+func exportArchiveCommand(file string) error {
+	resp, err := serverDo(http.MethodGet, "/api/v2/export/archive", "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to export archive: %w", err)
+	}
+	defer resp.Body.Close()
+
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to save archive: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Exported archive to %s\n", file)
+	return nil
+}
+
+// importArchiveCommand imports a .uapi archive into the server
+func importArchiveCommand(file string) error {
+	in, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	resp, err := serverDo(http.MethodPost, "/api/v2/import/archive", "application/zip", in)
+	if err != nil {
+		return fmt.Errorf("failed to import archive: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result export.ArchiveImportResult
+	if err := decodeData(resp.Body, &result); err != nil {
+		return fmt.Errorf("failed to import archive: %w", err)
+	}
+	fmt.Printf("Imported %d API docs\n", len(result.Imported))
+	for id, reason := range result.Failed {
+		fmt.Printf("Failed to import %s: %s\n", id, reason)
+	}
+	return nil
+}
+
+// serverRequest sends a JSON request to the server configured by the environment, decoding a JSON response into out
+func serverRequest(method, path string, body io.Reader, out interface{}) error {
+	resp, err := serverDo(method, path, "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	return decodeData(resp.Body, out)
+}
+
+// decodeData decodes the data of a JSON response envelope into out
+func decodeData(body io.Reader, out interface{}) error {
+	response := struct {
+		Data interface{} `json:"data"`
+	}{Data: out}
+	return json.NewDecoder(body).Decode(&response)
+}
+
+// serverDo sends a request to the server configured by the environment, failing on error statuses.
+// The caller must close the response body.
+func serverDo(method, path, contentType string, body io.Reader) (*http.Response, error) {
+	server := strings.TrimSuffix(os.Getenv("UAPI_SERVER"), "/")
+	if server == "" {
+		server = "http://localhost:8080"
+	}
+
+	req, err := http.NewRequest(method, server+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if key := os.Getenv("UAPI_API_KEY"); key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	if workspace := os.Getenv("UAPI_WORKSPACE"); workspace != "" {
+		req.Header.Set(workspaceHeader, workspace)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Minute}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var problem struct {
+			Code   string `json:"code"`
+			Detail string `json:"detail"`
+		}
+		if json.Unmarshal(data, &problem) == nil && problem.Code != "" {
+			return nil, fmt.Errorf("%s %s: %s: %s (%s)", method, path, resp.Status, problem.Detail, problem.Code)
+		}
+		return nil, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	return resp, nil
+}
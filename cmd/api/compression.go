@@ -0,0 +1,194 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// minCompressedSize is the size below which responses are sent as they are, as compressing them saves
+// less than the gzip header and footer cost
+const minCompressedSize = 1024
+
+// gzipWriters pools gzip writers, whose buffers are large to allocate for every response
+var gzipWriters = sync.Pool{New: func() interface{} {
+	return gzip.NewWriter(nil)
+}}
+
+// configureCompression returns the middleware compressing responses, configured by COMPRESSION (gzip
+// or off, default gzip), or nil when compression is off
+func configureCompression(encoding string) (gin.HandlerFunc, error) {
+	switch encoding {
+	case "", "gzip":
+		return compressResponses, nil
+	case "off", "false":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unsupported COMPRESSION %q: must be gzip or off", encoding)
+}
+
+// Middleware compressing the responses of clients accepting gzip, on the API and the UI alike. Only
+// text formats such as JSON, HTML, and YAML are compressed: PDFs, archives, images, and anything
+// already encoded, such as proxied responses, are sent as they are, as are small responses.
+func compressResponses(c *gin.Context) {
+	c.Header("Vary", "Accept-Encoding")
+	if c.Request.Method == http.MethodHead || c.GetHeader("Range") != "" || !acceptsGzip(c.GetHeader("Accept-Encoding")) {
+		c.Next()
+		return
+	}
+
+	writer := &compressWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+	defer writer.finish()
+	c.Next()
+}
+
+// acceptsGzip checks if an Accept-Encoding header accepts gzip, by name or as any encoding, with a
+// non-zero quality
+func acceptsGzip(header string) bool {
+	accepted := false
+	for _, item := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(item, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "gzip" && name != "*" {
+			continue
+		}
+
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if q, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = q
+			}
+		}
+		// gzip named on its own wins over any encoding
+		if name == "gzip" {
+			return quality > 0
+		}
+		accepted = quality > 0
+	}
+	return accepted
+}
+
+// compressibleContentType checks if a content type is text worth compressing
+func compressibleContentType(contentType string) bool {
+	contentType, _, _ = strings.Cut(strings.ToLower(contentType), ";")
+	if strings.HasPrefix(contentType, "text/") {
+		return true
+	}
+	for _, suffix := range []string{"json", "xml", "javascript", "yaml", "ndjson", "svg"} {
+		if strings.Contains(contentType, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter gzips a response once it's known to be worth it: its first bytes are held back until
+// there are enough of them, or the handler flushes or finishes
+type compressWriter struct {
+	gin.ResponseWriter
+	buffer  []byte
+	gzip    *gzip.Writer
+	decided bool
+}
+
+// compressible checks if the response, as its handler set it up so far, should be compressed
+func (w *compressWriter) compressible() bool {
+	status := w.Status()
+	return status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified &&
+		status != http.StatusPartialContent && w.Header().Get("Content-Encoding") == "" &&
+		compressibleContentType(w.Header().Get("Content-Type"))
+}
+
+// decide starts sending the response, compressed or not, with the bytes held back so far
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	if compress {
+		header := w.Header()
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		// The compressed body isn't byte for byte the one the ETag was made for
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+		w.gzip = gzipWriters.Get().(*gzip.Writer)
+		w.gzip.Reset(w.ResponseWriter)
+	}
+
+	buffer := w.buffer
+	w.buffer = nil
+	if len(buffer) == 0 {
+		return nil
+	}
+	_, err := w.write(buffer)
+	return err
+}
+
+// write writes to the response, through gzip if it's compressed
+func (w *compressWriter) write(data []byte) (int, error) {
+	if w.gzip != nil {
+		return w.gzip.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		return w.write(data)
+	}
+	if !w.compressible() {
+		if err := w.decide(false); err != nil {
+			return 0, err
+		}
+		return w.write(data)
+	}
+
+	w.buffer = append(w.buffer, data...)
+	if len(w.buffer) >= minCompressedSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow decides on compression before the headers are sent, as they say whether it's compressed
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		w.decide(w.compressible())
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush sends what's been written so far, compressing streamed responses however small their first bytes
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(w.compressible())
+	}
+	if w.gzip != nil {
+		w.gzip.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// finish sends what's held back, uncompressed as it's small, and ends the compressed stream
+func (w *compressWriter) finish() {
+	if !w.decided {
+		w.decide(false)
+	}
+	if w.gzip != nil {
+		w.gzip.Close()
+		w.gzip.Reset(nil)
+		gzipWriters.Put(w.gzip)
+		w.gzip = nil
+	}
+}
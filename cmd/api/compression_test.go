@@ -0,0 +1,135 @@
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// compressionTestServer routes requests through the compression middleware to handlers sending bodies
+// of various sizes and content types
+func compressionTestServer() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	large := strings.Repeat(`{"id": "doc-1", "title": "Pets"}`, 100)
+
+	r := gin.New()
+	r.Use(compressResponses)
+	r.GET("/small", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte(`{"id": "doc-1"}`))
+	})
+	r.GET("/large", func(c *gin.Context) {
+		c.Header("ETag", `"v1"`)
+		c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(large))
+	})
+	r.GET("/pdf", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/pdf", []byte(large))
+	})
+	r.GET("/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Writer.WriteString(`{"id": "doc-1"}` + "\n")
+		c.Writer.Flush()
+		c.Writer.WriteString(`{"id": "doc-2"}` + "\n")
+	})
+	return r
+}
+
+// getCompressed sends a GET request with an Accept-Encoding header
+func getCompressed(r *gin.Engine, path, acceptEncoding string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// gunzip decompresses a gzipped response body
+func gunzip(t *testing.T, recorder *httptest.ResponseRecorder) string {
+	t.Helper()
+	reader, err := gzip.NewReader(recorder.Body)
+	if err != nil {
+		t.Fatalf("Expected a gzipped body: %v", err)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to decompress body: %v", err)
+	}
+	return string(body)
+}
+
+// TestCompressResponses tests that only large text responses are gzipped, with their ETag weakened
+func TestCompressResponses(t *testing.T) {
+	r := compressionTestServer()
+
+	small := getCompressed(r, "/small", "gzip")
+	if small.Header().Get("Content-Encoding") != "" || small.Body.String() != `{"id": "doc-1"}` {
+		t.Errorf("Expected the small response to be sent as it is, got %q", small.Header().Get("Content-Encoding"))
+	}
+	if small.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("Expected Vary: Accept-Encoding, got %q", small.Header().Get("Vary"))
+	}
+
+	large := getCompressed(r, "/large", "br, gzip")
+	if large.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Expected the large response to be gzipped, got %q", large.Header().Get("Content-Encoding"))
+	}
+	if body := gunzip(t, large); !strings.HasPrefix(body, `{"id": "doc-1"`) || len(body) != 3200 {
+		t.Errorf("Expected the whole body once decompressed, got %d bytes", len(body))
+	}
+	if large.Header().Get("ETag") != `W/"v1"` {
+		t.Errorf(`Expected the ETag to be weakened to W/"v1", got %s`, large.Header().Get("ETag"))
+	}
+	if large.Header().Get("Content-Length") != "" {
+		t.Errorf("Expected no Content-Length on the compressed response, got %s", large.Header().Get("Content-Length"))
+	}
+
+	uncompressed := getCompressed(r, "/large", "")
+	if uncompressed.Header().Get("Content-Encoding") != "" || uncompressed.Header().Get("ETag") != `"v1"` {
+		t.Errorf("Expected the response to a client not accepting gzip to be sent as it is")
+	}
+
+	pdf := getCompressed(r, "/pdf", "gzip")
+	if pdf.Header().Get("Content-Encoding") != "" || pdf.Body.Len() != 3200 {
+		t.Errorf("Expected the PDF to be sent as it is, got %q", pdf.Header().Get("Content-Encoding"))
+	}
+}
+
+// TestCompressRefusedEncoding tests that gzip refused with a zero quality isn't used
+func TestCompressRefusedEncoding(t *testing.T) {
+	r := compressionTestServer()
+
+	for _, header := range []string{"gzip;q=0", "*;q=0", "gzip; q=0, *", "br", "identity"} {
+		recorder := getCompressed(r, "/large", header)
+		if recorder.Header().Get("Content-Encoding") != "" {
+			t.Errorf("Expected no compression for Accept-Encoding %q, got %q", header, recorder.Header().Get("Content-Encoding"))
+		}
+	}
+	for _, header := range []string{"gzip;q=0.5", "*", "br;q=1, gzip;q=0.1", "GZIP"} {
+		recorder := getCompressed(r, "/large", header)
+		if recorder.Header().Get("Content-Encoding") != "gzip" {
+			t.Errorf("Expected gzip for Accept-Encoding %q, got %q", header, recorder.Header().Get("Content-Encoding"))
+		}
+	}
+}
+
+// TestCompressStream tests that streamed responses are compressed from their first flush, however small
+func TestCompressStream(t *testing.T) {
+	r := compressionTestServer()
+
+	recorder := getCompressed(r, "/stream", "gzip")
+	if recorder.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Expected the stream to be gzipped, got %q", recorder.Header().Get("Content-Encoding"))
+	}
+	if !recorder.Flushed {
+		t.Errorf("Expected the stream to be flushed")
+	}
+	if body := gunzip(t, recorder); body != `{"id": "doc-1"}`+"\n"+`{"id": "doc-2"}`+"\n" {
+		t.Errorf("Expected both lines once decompressed, got %q", body)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"universal_api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// docETag returns the entity tag of a doc's revision. The digest tells apart docs deleted and saved
+// again under the same ID, whose revisions start over.
+func docETag(doc *models.APIDoc) string {
+	digest := doc.Digest
+	if len(digest) > 16 {
+		digest = digest[:16]
+	}
+	return `"` + strconv.Itoa(doc.Revision) + "-" + digest + `"`
+}
+
+// notModified sets the ETag and Last-Modified of a response, and responds 304 Not Modified when the
+// request's If-None-Match or, without one, If-Modified-Since shows the client has the revision already
+func notModified(c *gin.Context, etag string, modified time.Time) bool {
+	c.Header("ETag", etag)
+	if !modified.IsZero() {
+		c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	// If-Modified-Since is only a fallback for clients without the ETag (RFC 9110, section 13.2.2)
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		if !etagMatches(match, etag) {
+			return false
+		}
+	} else {
+		since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+		if err != nil || modified.IsZero() || modified.Truncate(time.Second).After(since) {
+			return false
+		}
+	}
+
+	c.AbortWithStatus(http.StatusNotModified)
+	return true
+}
+
+// etagMatches checks if an If-None-Match header lists an entity tag, comparing weakly as GETs do
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"universal_api/internal/contract"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contractPayloads are the published payloads returned by routes, by method and route with the version
+// and workspace prefixes removed
+var contractPayloads = map[string]struct {
+	name string
+	list bool
+}{
+	"GET /docs":                           {"APIDoc", true},
+	"POST /docs":                          {"APIDoc", false},
+	"GET /docs/:id":                       {"APIDoc", false},
+	"PATCH /docs/:id":                     {"APIDoc", false},
+	"POST /docs/:id/submit":               {"APIDoc", false},
+	"GET /docs/:id/endpoints/:endpointId": {"Endpoint", false},
+}
+
+// Handler to get the JSON Schemas of the service's payloads, to code integrations against
+func getContractSchemas(c *gin.Context) {
+	schemas := gin.H{}
+	for _, name := range contract.Names() {
+		schemas[name], _ = contract.Schema(name)
+	}
+	respond(c, http.StatusOK, gin.H{"version": contract.Version, "schemas": schemas})
+}
+
+// Handler to get the JSON Schema of one of the service's payloads
+func getContractSchema(c *gin.Context) {
+	s, ok := contract.Schema(c.Param("name"))
+	if !ok {
+		respondProblem(c, http.StatusNotFound, codeNotFound, "Schema not found; one of "+strings.Join(contract.Names(), ", "))
+		return
+	}
+	c.Header("Content-Type", "application/schema+json")
+	c.JSON(http.StatusOK, s)
+}
+
+// configureResponseValidation returns the middleware validating responses against the published schemas
+// when VALIDATE_RESPONSES is true, or nil. Validation decodes every response again, so it's meant for
+// development and CI rather than production.
+func configureResponseValidation(enabled string) gin.HandlerFunc {
+	if enabled != "true" {
+		return nil
+	}
+	return validateResponses
+}
+
+// responseRecorder copies what's written to a response, so it can be checked after the handler
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// validateResponses logs the successful responses of routes returning published payloads whose data
+// doesn't match their schemas. The response is sent unchanged either way.
+func validateResponses(c *gin.Context) {
+	route := strings.TrimPrefix(c.FullPath(), "/api/"+apiVersion(c))
+	if _, rest, ok := strings.Cut(route, "/workspaces/:workspace/"); ok {
+		route = "/" + rest
+	}
+	payload, ok := contractPayloads[c.Request.Method+" "+route]
+	if !ok {
+		c.Next()
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	c.Next()
+
+	// Docs left sparse by the fields and expand query parameters lack fields the schemas require
+	if c.GetBool(sparseContextKey) {
+		return
+	}
+	status := recorder.Status()
+	if status < 200 || status >= 300 || !strings.Contains(recorder.Header().Get("Content-Type"), "json") {
+		return
+	}
+	data := recorder.body.Bytes()
+	if apiVersion(c) != apiV1 {
+		var response struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(data, &response); err != nil {
+			log.Printf("Failed to validate response of %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+			return
+		}
+		data = response.Data
+	}
+	mismatches, err := contract.Check(payload.name, payload.list, data)
+	if err != nil {
+		log.Printf("Failed to validate response of %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		return
+	}
+	for _, mismatch := range mismatches {
+		log.Printf("Response of %s %s doesn't match the %s schema: %s", c.Request.Method, c.Request.URL.Path, payload.name, mismatch)
+	}
+}
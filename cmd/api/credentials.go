@@ -0,0 +1,230 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"universal_api/internal/models"
+	"universal_api/internal/secrets"
+	"universal_api/internal/validation"
+	"universal_api/pkg/scraper"
+
+	"github.com/gin-gonic/gin"
+)
+
+// credentialRequest represents a request to store or rotate a scrape credential
+type credentialRequest struct {
+	Name     string `json:"name"`
+	Type     string `json:"type" binding:"required"`
+	Key      string `json:"key"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+}
+
+// Validate checks the fields of a credential request; the secret a type needs is checked when it's sealed
+func (r *credentialRequest) Validate() error {
+	var v validation.Validator
+	if v.Required("type", r.Type) {
+		v.OneOf("type", r.Type, models.CredentialTypes...)
+	}
+	v.MaxLength("name", r.Name, validation.MaxNameLength)
+	v.MaxLength("key", r.Key, validation.MaxNameLength)
+	return v.Err()
+}
+
+// docCredentialRequest represents a request to set the credential a doc is scraped with
+type docCredentialRequest struct {
+	CredentialID string `json:"credential_id"`
+}
+
+// errCredentialsDisabled is returned when no secrets key or Vault is configured
+var errCredentialsDisabled = errors.New("credentials store isn't configured, set SECRETS_KEY or SECRETS_VAULT_ADDR")
+
+// credentialAuth returns scraper authentication with a workspace's stored credential
+func credentialAuth(workspace, id string) (scraper.RequestAuth, error) {
+	if secretStore == nil {
+		return nil, errCredentialsDisabled
+	}
+
+	credential, err := credentialStore.GetCredential(id)
+	if err != nil || credential.Workspace != workspace {
+		return nil, errors.New("credential not found: " + id)
+	}
+	return secretStore.RequestAuth(credential)
+}
+
+// sealCredential validates a credential request and encrypts its secret into credential, responding with an error if it fails
+func sealCredential(c *gin.Context, credential *models.Credential, request *credentialRequest) bool {
+	if secretStore == nil {
+		respondProblem(c, http.StatusServiceUnavailable, codeUnavailable, errCredentialsDisabled.Error())
+		return false
+	}
+
+	credential.Type = request.Type
+	credential.Key = request.Key
+	secret := &models.CredentialSecret{Username: request.Username, Password: request.Password, Token: request.Token}
+	if err := secrets.Validate(credential, secret); err != nil {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return false
+	}
+
+	if err := secretStore.Seal(credential, secret); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to encrypt credential: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// Handler to list the workspace's credentials, without their secrets
+func getCredentials(c *gin.Context) {
+	credentials, err := credentialStore.GetAllCredentials()
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get credentials: "+err.Error())
+		return
+	}
+
+	filtered := []*models.Credential{}
+	for _, credential := range credentials {
+		if inCurrentWorkspace(c, credential.Workspace) {
+			filtered = append(filtered, credential)
+		}
+	}
+
+	respondList(c, filtered)
+}
+
+// Handler to store a credential
+func createCredential(c *gin.Context) {
+	var request credentialRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	now := time.Now()
+	credential := &models.Credential{
+		ID:        fmt.Sprintf("cred-%d", now.UnixNano()),
+		Workspace: currentWorkspace(c),
+		Name:      request.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if !sealCredential(c, credential, &request) {
+		return
+	}
+
+	if err := credentialStore.SaveCredential(credential); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to save credential: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusCreated, credential)
+}
+
+// Handler to rotate a credential's secret
+func updateCredential(c *gin.Context) {
+	existing, err := credentialStore.GetCredential(c.Param("id"))
+	if err != nil || !inCurrentWorkspace(c, existing.Workspace) {
+		respondProblem(c, http.StatusNotFound, codeNotFound, "Credential not found: "+c.Param("id"))
+		return
+	}
+
+	var request credentialRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	credential := *existing
+	if request.Name != "" {
+		credential.Name = request.Name
+	}
+	credential.UpdatedAt = time.Now()
+	if !sealCredential(c, &credential, &request) {
+		return
+	}
+
+	if err := credentialStore.SaveCredential(&credential); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to save credential: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusOK, credential)
+}
+
+// Handler to delete a credential no doc uses anymore
+func deleteCredential(c *gin.Context) {
+	credential, err := credentialStore.GetCredential(c.Param("id"))
+	if err != nil || !inCurrentWorkspace(c, credential.Workspace) {
+		respondProblem(c, http.StatusNotFound, codeNotFound, "Credential not found: "+c.Param("id"))
+		return
+	}
+
+	docs, err := docStore(c).GetAllAPIDocs()
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get API docs: "+err.Error())
+		return
+	}
+	for _, doc := range docs {
+		if doc.CredentialID == credential.ID {
+			respondProblem(c, http.StatusConflict, codeConflict, "Credential is used by API doc "+doc.ID)
+			return
+		}
+	}
+
+	if err := credentialStore.DeleteCredential(credential.ID); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to delete credential: "+err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Handler to set or clear the credential an API doc is scraped with
+func setAPIDocCredential(c *gin.Context) {
+	doc, err := docStore(c).GetAPIDoc(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	var request docCredentialRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+	if request.CredentialID != "" {
+		if _, err := credentialAuth(currentWorkspace(c), request.CredentialID); err != nil {
+			respondInvalid(c, validation.Errors{{Field: "credential_id", Code: validation.CodeInvalid, Message: err.Error()}})
+			return
+		}
+	}
+
+	updated := *doc
+	updated.CredentialID = request.CredentialID
+	if err := docStore(c).SaveAPIDoc(&updated); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to save API doc: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusOK, updated)
+}
+
+// newSecretStore creates the credentials store configured by SECRETS_KEY or SECRETS_VAULT_*, or nil if disabled
+func newSecretStore(key, vaultAddr, vaultToken, vaultKey string) (*secrets.Store, error) {
+	switch {
+	case vaultAddr != "":
+		if vaultKey == "" {
+			vaultKey = "universal-api"
+		}
+		return secrets.NewStore(secrets.NewVaultTransit(vaultAddr, vaultToken, vaultKey)), nil
+	case key != "":
+		cipher, err := secrets.NewAESGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		return secrets.NewStore(cipher), nil
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package main
+
+import "errors"
+
+// diskUsageSupported tells if diskUsage can measure disks on this platform
+const diskUsageSupported = false
+
+// diskUsage can't measure disks on this platform
+func diskUsage(path string) (free, total int64, err error) {
+	return 0, 0, errors.New("disk usage isn't supported on this platform")
+}
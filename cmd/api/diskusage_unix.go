@@ -0,0 +1,17 @@
+//go:build linux || darwin
+
+package main
+
+import "syscall"
+
+// diskUsageSupported tells if diskUsage can measure disks on this platform
+const diskUsageSupported = true
+
+// diskUsage returns the space free for the service and the total space of the disk of a path, in bytes
+func diskUsage(path string) (free, total int64, err error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), int64(stat.Blocks) * int64(stat.Bsize), nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"universal_api/internal/export"
+	"universal_api/internal/models"
+	"universal_api/internal/review"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxArchiveSize limits the size of imported archives
+const maxArchiveSize = 256 << 20
+
+// Handler to export the workspace's docs, or the selected ones, merged into a single spec
+func exportCatalog(c *gin.Context) {
+	if format := c.DefaultQuery("format", "openapi"); format != "openapi" {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "Unsupported export format: "+format)
+		return
+	}
+
+	group := c.DefaultQuery("group", export.GroupByPrefix)
+	if group != export.GroupByPrefix && group != export.GroupByTag {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "Invalid group: must be prefix or tag")
+		return
+	}
+
+	docs, ok := selectedDocs(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, export.MergeOpenAPI(docs, group))
+}
+
+// Handler to export an API doc as a printable PDF reference
+func exportAPIDocPDF(c *gin.Context) {
+	doc, err := docStore(c).GetAPIDoc(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := export.WritePDF(&buf, doc); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to export PDF: "+err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+export.PDFName(doc)+`"`)
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// selectedDocs returns the docs of the workspace named by the ids query parameter, or all of
+// them without it, responding with an error and returning false on failure
+func selectedDocs(c *gin.Context) ([]*models.APIDoc, bool) {
+	docs, err := docStore(c).GetAllAPIDocs()
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get API docs: "+err.Error())
+		return nil, false
+	}
+
+	ids := c.Query("ids")
+	if ids == "" {
+		return docs, true
+	}
+
+	byID := make(map[string]*models.APIDoc, len(docs))
+	for _, doc := range docs {
+		byID[doc.ID] = doc
+	}
+	var selected []*models.APIDoc
+	for _, id := range strings.Split(ids, ",") {
+		doc, ok := byID[strings.TrimSpace(id)]
+		if !ok {
+			respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+id)
+			return nil, false
+		}
+		selected = append(selected, doc)
+	}
+	return selected, true
+}
+
+// Handler to download the workspace's published docs as a static HTML site in a zip archive
+func exportSite(c *gin.Context) {
+	docs, err := docStore(c).GetAllAPIDocs()
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get API docs: "+err.Error())
+		return
+	}
+	docs = review.Published(docs)
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	if err := export.BuildSite(docs, export.ZipWriter(archive)); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to build site: "+err.Error())
+		return
+	}
+	if err := archive.Close(); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to build site: "+err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="catalog-site.zip"`)
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// Handler to download the workspace's docs, or the selected ones, with their versions and attachments as a .uapi archive
+func exportArchive(c *gin.Context) {
+	docs, ok := selectedDocs(c)
+	if !ok {
+		return
+	}
+
+	archived := make([]export.ArchivedDoc, 0, len(docs))
+	for _, doc := range docs {
+		versions, err := docStore(c).GetAPIDocVersions(doc.ID)
+		if err != nil {
+			respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get versions of "+doc.ID+": "+err.Error())
+			return
+		}
+		attachments, err := archivedAttachments(doc.ID)
+		if err != nil {
+			respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get attachments of "+doc.ID+": "+err.Error())
+			return
+		}
+		archived = append(archived, export.ArchivedDoc{Doc: doc, Versions: versions, Attachments: attachments})
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteArchive(&buf, archived); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to write archive: "+err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="catalog`+export.ArchiveExtension+`"`)
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// Handler to import the docs and versions of a .uapi archive sent as the request body
+func importArchive(c *gin.Context) {
+	data, err := io.ReadAll(c.Request.Body)
+	if bodyTooLarge(c, err) {
+		return
+	}
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "Failed to read archive: "+err.Error())
+		return
+	}
+
+	docs, err := export.ReadArchive(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	respond(c, http.StatusOK, export.RestoreArchive(docStore(c), versionStore, attachmentStore, docs))
+}
+
+// archivedAttachments returns the attachments of a doc with their content
+func archivedAttachments(docID string) ([]export.ArchivedAttachment, error) {
+	attachments, err := attachmentStore.GetDocAttachments(docID)
+	if err != nil {
+		return nil, err
+	}
+
+	archived := make([]export.ArchivedAttachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		data, err := attachmentStore.GetAttachmentData(attachment.ID)
+		if err != nil {
+			return nil, err
+		}
+		archived = append(archived, export.ArchivedAttachment{Attachment: attachment, Data: data})
+	}
+	return archived, nil
+}
+
+// Handler to stream the workspace's docs as newline-delimited JSON, one doc per line. Each line
+// is flushed as it's written, so a slow reader holds back the export instead of it buffering.
+func exportDocsNDJSON(c *gin.Context) {
+	docs, err := docStore(c).GetAllAPIDocs()
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get API docs: "+err.Error())
+		return
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].ID < docs[j].ID })
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	for _, doc := range docs {
+		if c.Request.Context().Err() != nil {
+			return
+		}
+		if err := encoder.Encode(doc); err != nil {
+			log.Printf("Stopped NDJSON export: %v", err)
+			return
+		}
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// TestExportDocsNDJSON tests exporting the workspace's docs as one JSON object per line, in ID order
+func TestExportDocsNDJSON(t *testing.T) {
+	r, memoryStore := apiTestServer(t)
+	if err := memoryStore.SaveWorkspace(&models.Workspace{ID: "team-a", Name: "Team A"}); err != nil {
+		t.Fatalf("Failed to save workspace: %v", err)
+	}
+	docs := []*models.APIDoc{
+		{ID: "stores", Title: "Stores", Description: "Line one\nline two", URL: "https://stores.example.com/openapi.json"},
+		{ID: "pets", Title: "Pets", URL: "https://pets.example.com/openapi.json"},
+		{ID: "orders", Title: "Orders", URL: "https://orders.example.com/openapi.json"},
+		{ID: storage.WorkspaceDocID("team-a", "secret"), Title: "Secret", Workspace: "team-a", URL: "https://secret.example.com/openapi.json"},
+	}
+	for _, doc := range docs {
+		if err := store.SaveAPIDoc(doc); err != nil {
+			t.Fatalf("Failed to save doc: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		expected []string
+	}{
+		{name: "default workspace", expected: []string{"orders", "pets", "stores"}},
+		{name: "other workspace", header: http.Header{"X-Workspace": {"team-a"}}, expected: []string{storage.WorkspaceDocID("team-a", "secret")}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := serveAPI(r, http.MethodGet, "/api/v2/docs/export.ndjson", "", test.header)
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("Expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+			}
+			if contentType := recorder.Header().Get("Content-Type"); contentType != "application/x-ndjson" {
+				t.Errorf("Expected content type application/x-ndjson, got %q", contentType)
+			}
+
+			var ids []string
+			scanner := bufio.NewScanner(strings.NewReader(recorder.Body.String()))
+			for scanner.Scan() {
+				var doc models.APIDoc
+				if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+					t.Fatalf("Expected a JSON object per line, got %q: %v", scanner.Text(), err)
+				}
+				ids = append(ids, doc.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(test.expected, ",") {
+				t.Errorf("Expected docs %v, got %v", test.expected, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+	"universal_api/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sparseContextKey is the Gin context key set when a response leaves fields of docs out
+const sparseContextKey = "sparse"
+
+// docFieldNames are the JSON names of the top-level fields of docs, which fields can select
+var docFieldNames = jsonFieldNames(reflect.TypeOf(models.APIDoc{}))
+
+// docSelection is what of docs a request asks for with the fields and expand query parameters
+type docSelection struct {
+	list   bool            // lists leave out the parts of docs in storage.Parts unless they're asked for
+	fields map[string]bool // fields to include; every field when nil
+	expand map[string]bool // parts to include, in addition to the fields
+}
+
+// selectDocFields reads the fields and expand query parameters of a request for docs, responding with
+// a problem if they name fields docs don't have. v1 ignores them and returns whole docs.
+func selectDocFields(c *gin.Context, list bool) (docSelection, bool) {
+	selection := docSelection{expand: map[string]bool{}}
+	if apiVersion(c) == apiV1 {
+		return selection, true
+	}
+	selection.list = list
+
+	var v validation.Validator
+	if raw := c.Query("fields"); raw != "" {
+		// The ID is always included, so the doc can be got again
+		selection.fields = map[string]bool{"id": true}
+		for _, name := range splitList(raw) {
+			if !slices.Contains(docFieldNames, name) {
+				v.Add("fields", validation.CodeNotAllowed, "docs have no field "+name)
+				continue
+			}
+			selection.fields[name] = true
+		}
+	}
+	for _, name := range splitList(c.Query("expand")) {
+		if !slices.Contains(storage.Parts, name) {
+			v.OneOf("expand", name, storage.Parts...)
+			continue
+		}
+		selection.expand[name] = true
+	}
+	if err := v.Err(); err != nil {
+		respondInvalid(c, err)
+		return selection, false
+	}
+
+	if !selection.whole() {
+		c.Set(sparseContextKey, true)
+	}
+	return selection, true
+}
+
+// includes checks if a field of docs is selected
+func (s docSelection) includes(name string) bool {
+	switch {
+	case s.expand[name]:
+		return true
+	case s.fields != nil:
+		return s.fields[name]
+	}
+	return !s.list || !slices.Contains(storage.Parts, name)
+}
+
+// whole checks if every field of docs is selected
+func (s docSelection) whole() bool {
+	for _, name := range docFieldNames {
+		if !s.includes(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// parts returns the parts of docs to load from storage
+func (s docSelection) parts() []string {
+	var parts []string
+	for _, part := range storage.Parts {
+		if s.includes(part) {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// apply returns the selected fields of a doc, or the doc itself when every field is selected
+func (s docSelection) apply(doc *models.APIDoc) (interface{}, error) {
+	if s.whole() {
+		return doc, nil
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for name := range fields {
+		if !s.includes(name) {
+			delete(fields, name)
+		}
+	}
+	return fields, nil
+}
+
+// applyAll returns the selected fields of each of docs
+func (s docSelection) applyAll(docs []*models.APIDoc) ([]interface{}, error) {
+	selected := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		var err error
+		if selected[i], err = s.apply(doc); err != nil {
+			return nil, err
+		}
+	}
+	return selected, nil
+}
+
+// splitList splits a comma-separated query parameter, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// jsonFieldNames returns the JSON names of the fields of a struct type
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
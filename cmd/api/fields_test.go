@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestSelectDocFields tests selecting the fields of docs with the fields and expand query parameters
+func TestSelectDocFields(t *testing.T) {
+	r, _ := apiTestServer(t)
+	doc := &models.APIDoc{
+		ID:        "pets",
+		Title:     "Pets",
+		Version:   "1.0",
+		Endpoints: []models.Endpoint{{Path: "/pets", Method: "GET"}},
+		Schemas:   map[string]string{"Pet": `{"type":"object"}`},
+	}
+	if err := store.SaveAPIDoc(doc); err != nil {
+		t.Fatalf("Failed to save doc: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		list     bool
+		included []string
+		excluded []string
+	}{
+		{"list by default", "/api/v2/docs", true, []string{"id", "title", "version"}, []string{"endpoints", "schemas"}},
+		{"list expanded", "/api/v2/docs?expand=endpoints", true, []string{"id", "title", "endpoints"}, []string{"schemas"}},
+		{"list fields", "/api/v2/docs?fields=title", true, []string{"id", "title"}, []string{"version", "endpoints", "schemas"}},
+		{"list fields expanded", "/api/v2/docs?fields=title&expand=schemas,endpoints", true, []string{"id", "title", "endpoints", "schemas"}, []string{"version"}},
+		{"list fields naming parts", "/api/v2/docs?fields=title,endpoints", true, []string{"id", "title", "endpoints"}, []string{"version", "schemas"}},
+		{"doc whole", "/api/v2/docs/pets", false, []string{"id", "title", "version", "endpoints", "schemas"}, nil},
+		{"doc fields", "/api/v2/docs/pets?fields=version,schemas", false, []string{"id", "version", "schemas"}, []string{"title", "endpoints"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := serveAPI(r, http.MethodGet, test.path, "", nil)
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("Expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+			}
+			var selected map[string]interface{}
+			if test.list {
+				var list struct {
+					Data []map[string]interface{} `json:"data"`
+				}
+				decodeResponse(t, recorder, &list)
+				if len(list.Data) != 1 {
+					t.Fatalf("Expected 1 doc, got %s", recorder.Body.String())
+				}
+				selected = list.Data[0]
+			} else {
+				var one struct {
+					Data map[string]interface{} `json:"data"`
+				}
+				decodeResponse(t, recorder, &one)
+				selected = one.Data
+			}
+			for _, name := range test.included {
+				if _, ok := selected[name]; !ok {
+					t.Errorf("Expected %s to be included, got %v", name, selected)
+				}
+			}
+			for _, name := range test.excluded {
+				if _, ok := selected[name]; ok {
+					t.Errorf("Expected %s to be left out, got %v", name, selected)
+				}
+			}
+		})
+	}
+
+	for _, path := range []string{"/api/v2/docs?fields=title,colour", "/api/v2/docs?expand=examples", "/api/v2/docs/pets?fields=colour"} {
+		recorder := serveAPI(r, http.MethodGet, path, "", nil)
+		if recorder.Code != http.StatusBadRequest || !hasProblemCode(t, recorder, codeInvalidRequest) {
+			t.Errorf("Expected 400 invalid_request for %s, got %d: %s", path, recorder.Code, recorder.Body.String())
+		}
+	}
+
+	// v1 ignores both parameters and returns whole docs
+	recorder := serveAPI(r, http.MethodGet, "/api/v1/docs?fields=colour", "", nil)
+	var docs []map[string]interface{}
+	decodeResponse(t, recorder, &docs)
+	if len(docs) != 1 || docs[0]["endpoints"] == nil || docs[0]["schemas"] == nil {
+		t.Errorf("Expected whole docs in v1, got %s", recorder.Body.String())
+	}
+
+	// Leaving parts out of responses leaves the stored doc whole
+	stored, err := store.GetAPIDoc("pets")
+	if err != nil || len(stored.Endpoints) != 1 || len(stored.Schemas) != 1 {
+		t.Errorf("Expected the stored doc to keep its endpoints and schemas, got %+v, %v", stored, err)
+	}
+}
+
+// TestDocSelection tests which fields and parts of docs a selection includes
+func TestDocSelection(t *testing.T) {
+	whole := docSelection{expand: map[string]bool{}}
+	if !whole.whole() || len(whole.parts()) != 2 {
+		t.Errorf("Expected a doc without parameters to be whole, got parts %v", whole.parts())
+	}
+
+	list := docSelection{list: true, expand: map[string]bool{}}
+	if list.whole() || list.includes("endpoints") || list.includes("schemas") || !list.includes("title") {
+		t.Errorf("Expected lists to leave out endpoints and schemas only")
+	}
+	if parts := list.parts(); len(parts) != 0 {
+		t.Errorf("Expected lists to load no parts, got %v", parts)
+	}
+
+	fields := docSelection{list: true, fields: map[string]bool{"id": true, "title": true}, expand: map[string]bool{"schemas": true}}
+	if !fields.includes("title") || fields.includes("version") || !fields.includes("schemas") || fields.includes("endpoints") {
+		t.Errorf("Expected the fields and expanded parts only")
+	}
+
+	selected, err := fields.apply(&models.APIDoc{ID: "pets", Title: "Pets", Version: "1.0", Schemas: map[string]string{"Pet": "{}"}})
+	if err != nil {
+		t.Fatalf("Failed to apply selection: %v", err)
+	}
+	if names, ok := selected.(map[string]json.RawMessage); !ok || len(names) != 3 {
+		t.Errorf("Expected id, title, and schemas, got %v", selected)
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"universal_api/internal/cluster"
+	"universal_api/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Statuses of health checks, and of the instance as a whole
+const (
+	healthOK       = "ok"       // the dependency works
+	healthDegraded = "degraded" // the dependency works poorly, or an optional one is down; requests are still served
+	healthDown     = "down"     // the dependency doesn't work; the instance is down when a required one is
+)
+
+// healthCheckQueue is the job queue the queue check pushes to and pops from, private to the instance
+var healthCheckQueue = "health:" + cluster.InstanceID()
+
+// healthCheck checks a dependency of the service
+type healthCheck struct {
+	name     string
+	required bool // the instance can't serve requests while the dependency is down
+	check    func() healthResult
+}
+
+// healthResult is the outcome of a health check
+type healthResult struct {
+	Status    string                 `json:"status"`
+	Required  bool                   `json:"required"`
+	LatencyMS float64                `json:"latency_ms"`
+	Error     string                 `json:"error,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// healthReport is the health of the instance and of each of its dependencies
+type healthReport struct {
+	Status   string                  `json:"status"`
+	Instance string                  `json:"instance"`
+	ReadOnly bool                    `json:"read_only"` // the instance serves reads only, for maintenance
+	Checks   map[string]healthResult `json:"checks"`
+}
+
+// healthChecker runs the health checks of the service's dependencies
+type healthChecker struct {
+	checks  []healthCheck
+	timeout time.Duration // how long a check may take before it's reported down
+}
+
+// The checks of the service's dependencies run by /health, from configureHealth
+var health = &healthChecker{timeout: 2 * time.Second}
+
+// configureHealth returns the checks of the storage backend, the job queue of coordinator, and the
+// schedules run by /health, configured by HEALTH_CHECK_TIMEOUT (default 2s), HEALTH_CHECK_URL (a URL
+// whose reachability tells if the internet is, unchecked by default), and HEALTH_MIN_DISK_FREE (the free
+// space of dataDir below which its disk is degraded, default 512MB). dataDir is the directory of
+// file-backed storage, if any.
+func configureHealth(backend storage.Pinger, coordinator cluster.Coordinator, dataDir, timeout, probeURL, minDiskFree string) (*healthChecker, error) {
+	checker := &healthChecker{timeout: 2 * time.Second}
+	if timeout != "" {
+		var err error
+		if checker.timeout, err = time.ParseDuration(timeout); err != nil || checker.timeout <= 0 {
+			return nil, fmt.Errorf("invalid HEALTH_CHECK_TIMEOUT: %s", timeout)
+		}
+	}
+
+	checker.checks = []healthCheck{
+		{name: "storage", required: true, check: pingStorage(backend)},
+		{name: "queue", required: true, check: checkJobQueue(coordinator)},
+		{name: "scheduler", check: checkSchedules},
+	}
+
+	if dataDir != "" && diskUsageSupported {
+		minFree := int64(512 << 20)
+		if minDiskFree != "" {
+			var err error
+			if minFree, err = parseByteSize(minDiskFree); err != nil {
+				return nil, fmt.Errorf("invalid HEALTH_MIN_DISK_FREE: %s", minDiskFree)
+			}
+		}
+		checker.checks = append(checker.checks, healthCheck{name: "disk", check: checkDisk(dataDir, minFree)})
+	}
+
+	if probeURL != "" {
+		if u, err := url.Parse(probeURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid HEALTH_CHECK_URL: %s", probeURL)
+		}
+		checker.checks = append(checker.checks, healthCheck{name: "internet", check: checkInternet(probeURL, checker.timeout)})
+	}
+	return checker, nil
+}
+
+// run runs every check at once. The instance is down when a required check is, and degraded when any
+// other check isn't ok.
+func (h *healthChecker) run() healthReport {
+	results := make([]healthResult, len(h.checks))
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.runCheck(check)
+		}()
+	}
+	wg.Wait()
+
+	report := healthReport{
+		Status:   healthOK,
+		Instance: cluster.InstanceID(),
+		ReadOnly: currentMaintenance().ReadOnly,
+		Checks:   make(map[string]healthResult, len(h.checks)),
+	}
+	for i, check := range h.checks {
+		result := results[i]
+		result.Required = check.required
+		report.Checks[check.name] = result
+
+		switch {
+		case result.Status == healthDown && check.required:
+			report.Status = healthDown
+		case result.Status != healthOK && report.Status == healthOK:
+			report.Status = healthDegraded
+		}
+	}
+	return report
+}
+
+// runCheck runs a check, reporting it down if it takes longer than the timeout. A check stuck on a
+// dependency is left to finish on its own.
+func (h *healthChecker) runCheck(check healthCheck) healthResult {
+	start := time.Now()
+	done := make(chan healthResult, 1)
+	go func() {
+		done <- check.check()
+	}()
+
+	var result healthResult
+	select {
+	case result = <-done:
+	case <-time.After(h.timeout):
+		result = healthResult{Status: healthDown, Error: fmt.Sprintf("timed out after %s", h.timeout)}
+	}
+	result.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+	return result
+}
+
+// Handler to report the health of the instance and of each of its dependencies. A required dependency
+// being down fails the check with 503, so orchestrators stop routing requests to the instance.
+func getHealth(c *gin.Context) {
+	report := health.run()
+	status := http.StatusOK
+	if report.Status == healthDown {
+		status = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
+
+// Handler to report the instance is alive without checking its dependencies, for liveness probes that
+// shouldn't restart an instance because a dependency is down
+func getLiveness(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"status":   healthOK,
+		"instance": cluster.InstanceID(),
+	})
+}
+
+// pingStorage returns the check of the storage backend
+func pingStorage(backend storage.Pinger) func() healthResult {
+	return func() healthResult {
+		if err := backend.Ping(); err != nil {
+			return healthResult{Status: healthDown, Error: err.Error()}
+		}
+		return healthResult{Status: healthOK}
+	}
+}
+
+// checkJobQueue returns the check of the queue of scheduled jobs, pushing a job and popping it back
+func checkJobQueue(coordinator cluster.Coordinator) func() healthResult {
+	backend := "memory"
+	if _, ok := coordinator.(*cluster.Redis); ok {
+		backend = "redis"
+	}
+
+	return func() healthResult {
+		details := map[string]interface{}{"backend": backend}
+		if err := coordinator.Push(healthCheckQueue, "ping"); err != nil {
+			return healthResult{Status: healthDown, Error: "failed to push a job: " + err.Error(), Details: details}
+		}
+		job, ok, err := coordinator.Pop(healthCheckQueue)
+		switch {
+		case err != nil:
+			return healthResult{Status: healthDown, Error: "failed to pop a job: " + err.Error(), Details: details}
+		case !ok || job != "ping":
+			return healthResult{Status: healthDown, Error: "the pushed job wasn't popped back", Details: details}
+		}
+		return healthResult{Status: healthOK, Details: details}
+	}
+}
+
+// checkSchedules checks the schedules running on the instance, such as git sync and source checks,
+// still poll their queues, degrading when one is overdue
+func checkSchedules() healthResult {
+	now := time.Now()
+	var overdue []string
+	states := make([]gin.H, 0)
+	for _, state := range cluster.Schedules() {
+		late := state.Overdue(now)
+		if late {
+			overdue = append(overdue, state.Name)
+		}
+		states = append(states, gin.H{"name": state.Name, "last_tick": state.LastTick, "overdue": late})
+	}
+
+	result := healthResult{Status: healthOK, Details: map[string]interface{}{"schedules": states}}
+	if len(overdue) > 0 {
+		result.Status = healthDegraded
+		result.Error = "overdue schedules: " + strings.Join(overdue, ", ")
+	}
+	return result
+}
+
+// checkDisk returns the check of the free space of the disk of a directory, which may not exist yet
+func checkDisk(dir string, minFree int64) func() healthResult {
+	return func() healthResult {
+		// Before it's created, the directory will be on the disk of its closest existing parent
+		path := dir
+		for {
+			if _, err := os.Stat(path); err == nil || filepath.Dir(path) == path {
+				break
+			}
+			path = filepath.Dir(path)
+		}
+
+		free, total, err := diskUsage(path)
+		if err != nil {
+			return healthResult{Status: healthDown, Error: err.Error(), Details: map[string]interface{}{"path": dir}}
+		}
+		result := healthResult{Status: healthOK, Details: map[string]interface{}{"path": dir, "free_bytes": free, "total_bytes": total}}
+		if free < minFree {
+			result.Status = healthDegraded
+			result.Error = fmt.Sprintf("%d MB free, under the minimum of %d MB", free>>20, minFree>>20)
+		}
+		return result
+	}
+}
+
+// checkInternet returns the check of outbound requests, which reaches a URL with a HEAD request. Any
+// response, whatever its status, shows the URL is reachable.
+func checkInternet(probeURL string, timeout time.Duration) func() healthResult {
+	client := &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	return func() healthResult {
+		details := map[string]interface{}{"url": probeURL}
+		resp, err := client.Head(probeURL)
+		if err != nil {
+			return healthResult{Status: healthDown, Error: err.Error(), Details: details}
+		}
+		resp.Body.Close()
+		details["status_code"] = resp.StatusCode
+		return healthResult{Status: healthOK, Details: details}
+	}
+}
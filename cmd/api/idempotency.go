@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"universal_api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// idempotencyKeyHeader is the header clients set to make a request safe to retry
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength bounds the length of idempotency keys, which are usually UUIDs
+const maxIdempotencyKeyLength = 255
+
+// How long the response to a request with an idempotency key is kept for retries, from IDEMPOTENCY_TTL
+var idempotencyTTL = 24 * time.Hour
+
+// configureIdempotencyTTL parses IDEMPOTENCY_TTL, a duration such as 1h
+func configureIdempotencyTTL(value string) (time.Duration, error) {
+	if value == "" {
+		return 24 * time.Hour, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("invalid IDEMPOTENCY_TTL: %s", value)
+	}
+	return ttl, nil
+}
+
+// Middleware making a request safe to retry with an Idempotency-Key header: the first request with a key
+// is carried out and its response kept, and retries with the key get that response again, marked with
+// Idempotent-Replayed, instead of being carried out again. A retry while the first request is in progress
+// gets 409, and a key reused for a different request gets 422. Server errors aren't kept, so the request
+// can be retried. Requests without the header are carried out as usual.
+func idempotent(c *gin.Context) {
+	key := c.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		c.Next()
+		return
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, fmt.Sprintf("Invalid %s: must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength))
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if bodyTooLarge(c, err) {
+		return
+	}
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "Failed to read request body: "+err.Error())
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	// Keys are the caller's own, so they're scoped to the workspace and user
+	now := time.Now()
+	record := &models.IdempotencyRecord{
+		Key:         currentWorkspace(c) + "\x00" + currentUserID(c) + "\x00" + key,
+		Fingerprint: requestFingerprint(c.Request.Method, c.Request.URL.Path, body),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(idempotencyTTL),
+	}
+	existing, err := idempotencyStore.ClaimIdempotencyKey(record)
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to check "+idempotencyKeyHeader+": "+err.Error())
+		return
+	}
+	switch {
+	case existing == nil:
+	case existing.Fingerprint != record.Fingerprint:
+		respondProblem(c, http.StatusUnprocessableEntity, codeUnprocessable, idempotencyKeyHeader+" was already used for a different request")
+		return
+	case existing.Status == 0:
+		c.Header("Retry-After", "1")
+		respondProblem(c, http.StatusConflict, codeConflict, "A request with this "+idempotencyKeyHeader+" is still in progress")
+		return
+	default:
+		c.Header("Idempotent-Replayed", "true")
+		c.Data(existing.Status, existing.ContentType, existing.Body)
+		c.Abort()
+		return
+	}
+
+	// The claim is released unless the response is kept, so retries aren't refused with 409 until the key
+	// expires after a server error or a panic
+	saved := false
+	defer func() {
+		if !saved {
+			idempotencyStore.DeleteIdempotencyRecord(record.Key)
+		}
+	}()
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+	c.Next()
+
+	if recorder.Status() >= http.StatusInternalServerError {
+		return
+	}
+	finished := *record
+	finished.Status = recorder.Status()
+	finished.ContentType = recorder.Header().Get("Content-Type")
+	finished.Body = recorder.body.Bytes()
+	saved = idempotencyStore.SaveIdempotencyRecord(&finished) == nil
+}
+
+// requestFingerprint hashes what identifies a request, to tell retries from other requests
+func requestFingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
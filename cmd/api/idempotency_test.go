@@ -0,0 +1,203 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"universal_api/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// idempotencyTestServer routes POST /api/v2/docs through the idempotent middleware to handle, counting
+// the requests carried out
+func idempotencyTestServer(t *testing.T, handle func(c *gin.Context)) (*gin.Engine, *int) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	idempotencyStore = storage.NewMemoryStorage()
+
+	calls := 0
+	r := gin.New()
+	r.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, _ any) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	r.POST("/api/v2/docs", idempotent, func(c *gin.Context) {
+		calls++
+		handle(c)
+	})
+	return r, &calls
+}
+
+// sendIdempotent sends a POST request with an idempotency key
+func sendIdempotent(r *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/docs", strings.NewReader(body))
+	req.Header.Set(idempotencyKeyHeader, key)
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// TestIdempotentReplay tests that retries get the response of the first request without carrying it out again
+func TestIdempotentReplay(t *testing.T) {
+	r, calls := idempotencyTestServer(t, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"id": "doc-1"})
+	})
+
+	first := sendIdempotent(r, "key-1", `{"url": "https://example.com"}`)
+	retry := sendIdempotent(r, "key-1", `{"url": "https://example.com"}`)
+
+	if *calls != 1 {
+		t.Errorf("Expected the request to be carried out once, got %d", *calls)
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("Expected the retry to get the first response, got %d %s", retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("Expected only the retry to be marked as replayed")
+	}
+	if retry.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Errorf("Expected the retry to keep the content type, got %s", retry.Header().Get("Content-Type"))
+	}
+
+	if other := sendIdempotent(r, "key-2", `{"url": "https://example.com"}`); other.Code != http.StatusCreated || *calls != 2 {
+		t.Errorf("Expected a request with another key to be carried out, got %d after %d calls", other.Code, *calls)
+	}
+}
+
+// TestIdempotentDifferentRequest tests that a key reused for a different request is refused with 422
+func TestIdempotentDifferentRequest(t *testing.T) {
+	r, calls := idempotencyTestServer(t, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"id": "doc-1"})
+	})
+
+	sendIdempotent(r, "key-1", `{"url": "https://example.com"}`)
+	reused := sendIdempotent(r, "key-1", `{"url": "https://example.org"}`)
+
+	if reused.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for a key reused with another body, got %d", reused.Code)
+	}
+	if *calls != 1 {
+		t.Errorf("Expected the different request not to be carried out, got %d calls", *calls)
+	}
+}
+
+// TestIdempotentInProgress tests that a retry while the first request is in progress is refused with 409
+func TestIdempotentInProgress(t *testing.T) {
+	started, finish := make(chan struct{}), make(chan struct{})
+	r, _ := idempotencyTestServer(t, func(c *gin.Context) {
+		close(started)
+		<-finish
+		c.JSON(http.StatusCreated, gin.H{"id": "doc-1"})
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- sendIdempotent(r, "key-1", `{}`)
+	}()
+	<-started
+
+	retry := sendIdempotent(r, "key-1", `{}`)
+	if retry.Code != http.StatusConflict || retry.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 409 with Retry-After while the request is in progress, got %d", retry.Code)
+	}
+
+	close(finish)
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Errorf("Expected the first request to finish, got %d", first.Code)
+	}
+	if replayed := sendIdempotent(r, "key-1", `{}`); replayed.Code != http.StatusCreated {
+		t.Errorf("Expected a retry after the request finished to be replayed, got %d", replayed.Code)
+	}
+}
+
+// TestIdempotentConcurrent tests that of concurrent requests with the same key one is carried out, and the
+// others either wait with 409 or get its whole response
+func TestIdempotentConcurrent(t *testing.T) {
+	r, calls := idempotencyTestServer(t, func(c *gin.Context) {
+		time.Sleep(10 * time.Millisecond)
+		c.JSON(http.StatusCreated, gin.H{"id": "doc-1"})
+	})
+
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 20)
+	for i := range responses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i] = sendIdempotent(r, "key-1", `{}`)
+		}()
+		time.Sleep(time.Millisecond)
+	}
+	wg.Wait()
+
+	if *calls != 1 {
+		t.Errorf("Expected the request to be carried out once, got %d", *calls)
+	}
+	for _, response := range responses {
+		switch response.Code {
+		case http.StatusConflict:
+		case http.StatusCreated:
+			if response.Body.String() != `{"id":"doc-1"}` {
+				t.Errorf("Expected the whole response, got %q", response.Body.String())
+			}
+		default:
+			t.Errorf("Expected 201 or 409, got %d", response.Code)
+		}
+	}
+}
+
+// TestIdempotentServerErrors tests that requests failing with a server error or a panic can be retried
+func TestIdempotentServerErrors(t *testing.T) {
+	failure := "error"
+	r, calls := idempotencyTestServer(t, func(c *gin.Context) {
+		switch failure {
+		case "error":
+			c.JSON(http.StatusBadGateway, gin.H{"error": "upstream failed"})
+		case "panic":
+			panic("handler failed")
+		default:
+			c.JSON(http.StatusCreated, gin.H{"id": "doc-1"})
+		}
+	})
+
+	if failed := sendIdempotent(r, "key-1", `{}`); failed.Code != http.StatusBadGateway {
+		t.Fatalf("Expected the server error to be returned, got %d", failed.Code)
+	}
+
+	failure = "panic"
+	if panicked := sendIdempotent(r, "key-1", `{}`); panicked.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected the retry to be carried out and panic, got %d", panicked.Code)
+	}
+
+	failure = ""
+	if retry := sendIdempotent(r, "key-1", `{}`); retry.Code != http.StatusCreated {
+		t.Errorf("Expected the retry after a panic to be carried out, got %d", retry.Code)
+	}
+	if *calls != 3 {
+		t.Errorf("Expected every failed request to be retried, got %d calls", *calls)
+	}
+}
+
+// TestIdempotentExpiry tests that keys can be used again once their response expires
+func TestIdempotentExpiry(t *testing.T) {
+	ttl := idempotencyTTL
+	idempotencyTTL = 50 * time.Millisecond
+	defer func() { idempotencyTTL = ttl }()
+
+	r, calls := idempotencyTestServer(t, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"id": "doc-1"})
+	})
+
+	sendIdempotent(r, "key-1", `{"url": "https://example.com"}`)
+	time.Sleep(100 * time.Millisecond)
+
+	retry := sendIdempotent(r, "key-1", `{"url": "https://example.org"}`)
+	if retry.Code != http.StatusCreated || retry.Header().Get("Idempotent-Replayed") != "" || *calls != 2 {
+		t.Errorf("Expected the expired key to be used for a new request, got %d after %d calls", retry.Code, *calls)
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"universal_api/internal/importer"
+	"universal_api/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// directoryImportRequest represents a request to import APIs from an OpenAPI directory
+type directoryImportRequest struct {
+	importer.ImportRequest
+}
+
+// Validate checks the fields of a directory import request
+func (r *directoryImportRequest) Validate() error {
+	var v validation.Validator
+	v.URL("url", r.URL, "http", "https")
+	if r.Limit < 0 {
+		v.Add("limit", validation.CodeInvalid, "can't be negative")
+	}
+	return v.Err()
+}
+
+// Handler to bulk-import APIs from an OpenAPI directory
+func importDirectory(c *gin.Context) {
+	// An empty body imports the whole default directory
+	var request directoryImportRequest
+	if !bindOptionalJSON(c, &request) {
+		return
+	}
+
+	result, err := importer.NewImporter(docStore(c)).Import(request.ImportRequest)
+	if err != nil {
+		respondProblem(c, http.StatusBadGateway, codeUpstreamFailed, "Failed to import directory: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusOK, result)
+}
+
+// gatewayImportRequest represents a request to import APIs from an API gateway
+type gatewayImportRequest struct {
+	importer.GatewayImportRequest
+}
+
+// Validate checks the fields each type of gateway needs are set
+func (r *gatewayImportRequest) Validate() error {
+	var v validation.Validator
+	if !v.Required("type", r.Type) {
+		return v.Err()
+	}
+	switch r.Type {
+	case "kong":
+		if v.Required("admin_url", r.AdminURL) {
+			v.URL("admin_url", r.AdminURL, "http", "https")
+		}
+	case "aws":
+		v.Required("region", r.Region)
+		v.Required("access_key_id", r.AccessKeyID)
+		v.Required("secret_access_key", r.SecretAccessKey)
+	case "apigee":
+		v.Required("organization", r.Organization)
+		v.Required("token", r.Token)
+	default:
+		v.OneOf("type", r.Type, importer.GatewayTypes...)
+	}
+	return v.Err()
+}
+
+// Handler to import APIs from an API gateway
+func importGateway(c *gin.Context) {
+	var request gatewayImportRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	gateway, err := importer.NewGateway(request.GatewayImportRequest)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	result, err := importer.ImportGateway(docStore(c), gateway)
+	if err != nil {
+		respondProblem(c, http.StatusBadGateway, codeUpstreamFailed, "Failed to import gateway: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusOK, result)
+}
+
+// gitWebhookPayload covers the fields of GitHub and GitLab push payloads that identify the repository
+type gitWebhookPayload struct {
+	Repository json.RawMessage `json:"repository"`
+	Project    struct {
+		GitHTTPURL string `json:"git_http_url"`
+	} `json:"project"`
+}
+
+// Handler to sync configured git repositories, either all or the one named in a push webhook
+func syncGitSource(c *gin.Context) {
+	if gitSource == nil {
+		respondProblem(c, http.StatusNotFound, codeNotFound, "Git sync is not configured")
+		return
+	}
+
+	var payload gitWebhookPayload
+	if !bindOptionalJSON(c, &payload) {
+		return
+	}
+
+	repository := webhookRepository(payload)
+	if repository == "" {
+		result, err := gitSource.Sync()
+		if err != nil {
+			respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to sync git sources: "+err.Error())
+			return
+		}
+		respond(c, http.StatusOK, result)
+		return
+	}
+
+	// Only sync repositories that are configured, matching with or without a pinned branch
+	for _, configured := range gitSource.Repositories() {
+		if configured == repository || strings.HasPrefix(configured, repository+"#") {
+			result, err := gitSource.SyncRepository(configured)
+			if err != nil {
+				respondProblem(c, http.StatusBadGateway, codeUpstreamFailed, "Failed to sync git source: "+err.Error())
+				return
+			}
+			respond(c, http.StatusOK, result)
+			return
+		}
+	}
+
+	respondProblem(c, http.StatusNotFound, codeNotFound, "Repository is not configured for sync: "+repository)
+}
+
+// webhookRepository extracts the repository clone URL from a webhook payload
+func webhookRepository(payload gitWebhookPayload) string {
+	if payload.Project.GitHTTPURL != "" {
+		return payload.Project.GitHTTPURL
+	}
+	if len(payload.Repository) == 0 {
+		return ""
+	}
+
+	// Plain {"repository": "url"} requests
+	var url string
+	if err := json.Unmarshal(payload.Repository, &url); err == nil {
+		return url
+	}
+
+	// GitHub {"repository": {"clone_url": "url"}} push events
+	var repo struct {
+		CloneURL string `json:"clone_url"`
+	}
+	if err := json.Unmarshal(payload.Repository, &repo); err == nil {
+		return repo.CloneURL
+	}
+
+	return ""
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// limitsContextKey is the Gin context key of the limits of a request
+const limitsContextKey = "limits"
+
+// responseGrace is how long after a long-running request's deadline its response may still be written
+const responseGrace = 10 * time.Second
+
+// serverTimeouts bound how long the server waits on clients and how long requests may take
+type serverTimeouts struct {
+	readHeader  time.Duration // to read a request's headers
+	read        time.Duration // to read a whole request, body included
+	write       time.Duration // from the end of a request's headers to the end of its response
+	idle        time.Duration // to wait for the next request on a kept-alive connection
+	request     time.Duration // the deadline of handlers, canceling what they fetch on the request's behalf
+	longRequest time.Duration // the deadline of routes marked longRunning, such as imports and exports
+}
+
+// How long the server waits on clients and requests may take, from configureTimeouts; 0 for no limit
+var timeouts = serverTimeouts{
+	readHeader:  10 * time.Second,
+	read:        time.Minute,
+	write:       2 * time.Minute,
+	idle:        2 * time.Minute,
+	request:     time.Minute,
+	longRequest: 10 * time.Minute,
+}
+
+// The largest request body of most routes, in bytes, from MAX_BODY_SIZE; 0 for no limit
+var maxBodySize int64 = 1 << 20
+
+// configureTimeouts parses SERVER_READ_HEADER_TIMEOUT, SERVER_READ_TIMEOUT, SERVER_WRITE_TIMEOUT,
+// SERVER_IDLE_TIMEOUT, REQUEST_TIMEOUT, and LONG_REQUEST_TIMEOUT, durations such as 30s, keeping the
+// defaults of those left empty
+func configureTimeouts(readHeader, read, write, idle, request, longRequest string) (serverTimeouts, error) {
+	configured := timeouts
+	for _, setting := range []struct {
+		name  string
+		value string
+		field *time.Duration
+	}{
+		{"SERVER_READ_HEADER_TIMEOUT", readHeader, &configured.readHeader},
+		{"SERVER_READ_TIMEOUT", read, &configured.read},
+		{"SERVER_WRITE_TIMEOUT", write, &configured.write},
+		{"SERVER_IDLE_TIMEOUT", idle, &configured.idle},
+		{"REQUEST_TIMEOUT", request, &configured.request},
+		{"LONG_REQUEST_TIMEOUT", longRequest, &configured.longRequest},
+	} {
+		if setting.value == "" {
+			continue
+		}
+		timeout, err := time.ParseDuration(setting.value)
+		if err != nil || timeout < 0 {
+			return configured, fmt.Errorf("invalid %s: %s", setting.name, setting.value)
+		}
+		*setting.field = timeout
+	}
+	return configured, nil
+}
+
+// configureMaxBodySize parses MAX_BODY_SIZE, a size such as 1MB
+func configureMaxBodySize(value string) (int64, error) {
+	if value == "" {
+		return 1 << 20, nil
+	}
+	size, err := parseByteSize(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid MAX_BODY_SIZE: %s", value)
+	}
+	return size, nil
+}
+
+// parseByteSize parses a number of bytes with an optional KB, MB, or GB suffix
+func parseByteSize(value string) (int64, error) {
+	number, unit := strings.ToUpper(value), int64(1)
+	for suffix, size := range map[string]int64{"KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30} {
+		if trimmed, ok := strings.CutSuffix(number, suffix); ok {
+			number, unit = trimmed, size
+			break
+		}
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(number), 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size: %s", value)
+	}
+	return size * unit, nil
+}
+
+// newServer creates the HTTP server of a handler, with the configured timeouts
+func newServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: timeouts.readHeader,
+		ReadTimeout:       timeouts.read,
+		WriteTimeout:      timeouts.write,
+		IdleTimeout:       timeouts.idle,
+	}
+}
+
+// requestLimits are the limits limitRequests set on a request, for its route to change
+type requestLimits struct {
+	body       *limitedBody
+	ctx        context.Context // the request's context before the deadline of REQUEST_TIMEOUT
+	controller *http.ResponseController
+}
+
+// Middleware limiting every request's body to MAX_BODY_SIZE and its handler to REQUEST_TIMEOUT. A body
+// over the limit fails to be read, and the deadline cancels the fetches made on the request's behalf.
+// Routes taking larger bodies or longer change the limits with limitBody and longRunning.
+func limitRequests(c *gin.Context) {
+	body := &limitedBody{ReadCloser: c.Request.Body, limit: maxBodySize}
+	c.Set(limitsContextKey, &requestLimits{
+		body:       body,
+		ctx:        c.Request.Context(),
+		controller: http.NewResponseController(c.Writer),
+	})
+
+	c.Request.Body = body
+	if timeouts.request > 0 {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeouts.request)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+	}
+	c.Next()
+}
+
+// limitBody returns the middleware changing the body size limit of a route, such as one taking uploads.
+// Bodies declared larger than the limit are refused before they're read.
+func limitBody(size int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > size {
+			respondBodyTooLarge(c, size)
+			return
+		}
+		if limits, ok := c.Get(limitsContextKey); ok {
+			limits.(*requestLimits).body.limit = size
+		}
+		c.Next()
+	}
+}
+
+// Middleware giving a route that may take long, such as an import or an export, LONG_REQUEST_TIMEOUT
+// instead of REQUEST_TIMEOUT, extending the server's read and write timeouts to match
+func longRunning(c *gin.Context) {
+	value, ok := c.Get(limitsContextKey)
+	if !ok {
+		c.Next()
+		return
+	}
+	limits := value.(*requestLimits)
+
+	ctx := limits.ctx
+	var deadline time.Time
+	if timeouts.longRequest > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeouts.longRequest)
+		defer cancel()
+		deadline = time.Now().Add(timeouts.longRequest)
+	}
+	c.Request = c.Request.WithContext(ctx)
+
+	// A zero deadline lifts the server's timeouts. Connections without deadlines, such as HTTP/2 ones,
+	// keep the server's.
+	limits.controller.SetReadDeadline(deadline)
+	if !deadline.IsZero() {
+		deadline = deadline.Add(responseGrace)
+	}
+	limits.controller.SetWriteDeadline(deadline)
+	c.Next()
+}
+
+// limitedBody is a request body failing to be read past a limit, like http.MaxBytesReader, except that
+// the limit can be changed until the body is read
+type limitedBody struct {
+	io.ReadCloser
+	limit int64 // 0 for no limit
+	read  int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.limit <= 0 {
+		return b.ReadCloser.Read(p)
+	}
+
+	// Reading a byte past the limit tells a body over it from one just at it
+	if remaining := b.limit - b.read + 1; int64(len(p)) > remaining {
+		p = p[:max(remaining, 0)]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		n -= int(b.read - b.limit)
+		b.read = b.limit
+		return n, &http.MaxBytesError{Limit: b.limit}
+	}
+	return n, err
+}
+
+// bodyTooLarge checks if reading a request body failed for being over the size limit, responding with a
+// problem if it did
+func bodyTooLarge(c *gin.Context, err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return false
+	}
+	respondBodyTooLarge(c, maxBytesErr.Limit)
+	return true
+}
+
+// respondBodyTooLarge responds to a request whose body is over the size limit
+func respondBodyTooLarge(c *gin.Context, limit int64) {
+	respondProblem(c, http.StatusRequestEntityTooLarge, codePayloadTooLarge, fmt.Sprintf("Request body is larger than the limit of %d bytes", limit))
+}
+
+// timedOut checks if a request ran out of time, responding with a problem if it did. Handlers check it
+// when what they did on the request's behalf failed, as the deadline is the likely cause.
+func timedOut(c *gin.Context) bool {
+	if !errors.Is(c.Request.Context().Err(), context.DeadlineExceeded) {
+		return false
+	}
+	respondProblem(c, http.StatusGatewayTimeout, codeTimeout, "The request took longer than the server allows")
+	return true
+}
@@ -1,13 +1,35 @@
 package main
 
 import (
+	"errors"
 	"log"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
+	"universal_api/internal/auth"
+	"universal_api/internal/cluster"
+	"universal_api/internal/events"
+	"universal_api/internal/governance"
+	"universal_api/internal/i18n"
+	"universal_api/internal/importer"
+	"universal_api/internal/linkcheck"
 	"universal_api/internal/models"
-	"universal_api/internal/scraper"
+	"universal_api/internal/notify"
+	"universal_api/internal/ownership"
+	"universal_api/internal/proxy"
+	"universal_api/internal/redact"
+	"universal_api/internal/review"
+	"universal_api/internal/sanitize"
+	"universal_api/internal/schema"
+	"universal_api/internal/search"
+	"universal_api/internal/secrets"
 	"universal_api/internal/storage"
 	"universal_api/internal/ui"
+	"universal_api/internal/validation"
+	"universal_api/pkg/parser"
+	"universal_api/pkg/scraper"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,69 +37,660 @@ import (
 // Global storage instance
 var store storage.Storage
 
+// Global watch storage instance
+var watchStore storage.WatchStorage
+
+// Global drift findings storage instance
+var driftStore storage.DriftStorage
+
+// Global scrape records storage instance
+var scrapeStore storage.ScrapeStorage
+
+// Global workspace registry instance
+var workspaces storage.WorkspaceRegistry
+
+// Global users and API keys storage instance
+var userStore storage.UserStorage
+
+// OpenID Connect provider, nil unless OIDC_ISSUER is set
+var oidcProvider *auth.OIDCProvider
+
+// Global scrape credentials storage instance
+var credentialStore storage.CredentialStorage
+
+// Encryption of stored credentials, nil unless SECRETS_KEY or SECRETS_VAULT_ADDR is set
+var secretStore *secrets.Store
+
+// Global per-domain scrape policies storage instance
+var policyStore storage.PolicyStorage
+
+// Global schema catalog storage instance
+var schemaStore storage.SchemaStorage
+
+// Global version history storage instance, for restoring histories from archives
+var versionStore storage.VersionStorage
+
+// Global doc attachment storage instance
+var attachmentStore storage.AttachmentStorage
+
+// Global raw source archive storage instance
+var rawSourceStore storage.RawSourceStorage
+
+// Global view counts storage instance
+var viewStore storage.ViewStorage
+
+// Global source URL checks storage instance
+var sourceCheckStore storage.SourceCheckStorage
+
+// Global idempotency records storage instance
+var idempotencyStore storage.IdempotencyStorage
+
+// Checker of the source URLs of docs
+var sourceChecker *linkcheck.Checker
+
+// Name, logo, colors, and footer of the UI
+var branding = ui.DefaultBranding
+
+// Directory overriding the UI's templates and static files, from UI_THEME_DIR
+var themeDir string
+
+// Catalog-wide endpoint search index
+var searchIndex *search.Index
+
+// Response validation proxy
+var validationProxy *proxy.Proxy
+
+// Notifier for doc changes and watches
+var notifier *notify.Notifier
+
+// Git repository sync source, nil unless GIT_SYNC_REPOS is set
+var gitSource *importer.GitSource
+
 func main() {
+	// Run a command against a running server instead of serving
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Initialize storage
-	store = storage.NewMemoryStorage()
+	memoryStore := storage.NewMemoryStorage()
+	store = memoryStore
+	watchStore = memoryStore
+	driftStore = memoryStore
+	scrapeStore = memoryStore
+	viewStore = memoryStore
+	sourceCheckStore = memoryStore
+	versionStore = memoryStore
+	attachmentStore = memoryStore
+	rawSourceStore = memoryStore
+	workspaces = memoryStore
+	userStore = memoryStore
+	credentialStore = memoryStore
+	policyStore = memoryStore
+	schemaStore = memoryStore
+	idempotencyStore = memoryStore
+
+	// Scrape every domain as politely as its policy asks
+	scraper.SetPolicySource(policyStore)
+	if err := configureLimiterStore(os.Getenv("POLITENESS_REDIS_URL")); err != nil {
+		log.Fatalf("Failed to configure politeness state: %v", err)
+	}
+
+	// Reuse recently fetched documentation instead of refetching it
+	if err := configureFetchCache(os.Getenv("FETCH_CACHE"), os.Getenv("FETCH_CACHE_TTL"), os.Getenv("FETCH_CACHE_REDIS_URL")); err != nil {
+		log.Fatalf("Failed to configure fetch cache: %v", err)
+	}
+
+	// Summarize long descriptions of scraped HTML docs for listings
+	if err := configureSummaries(os.Getenv("SUMMARY_LENGTH"), os.Getenv("SUMMARY_FIRST_SENTENCE")); err != nil {
+		log.Fatalf("Failed to configure summaries: %v", err)
+	}
+
+	// Keep endpoints of scraped HTML docs whose path couldn't be found, if configured
+	if err := configureUnknownPaths(os.Getenv("KEEP_UNKNOWN_PATHS")); err != nil {
+		log.Fatalf("Failed to configure unknown paths: %v", err)
+	}
+
+	// Tune the heuristics of HTML scraping with a rules file
+	if err := configureScrapingRules(os.Getenv("SCRAPING_RULES")); err != nil {
+		log.Fatalf("Failed to configure scraping rules: %v", err)
+	}
 
-	r := gin.Default()
+	// Post-process parsed docs with the configured hooks before they're stored
+	if err := configureHooks(os.Getenv("DOC_HOOKS")); err != nil {
+		log.Fatalf("Failed to configure hooks: %v", err)
+	}
+
+	// Strip scripts and markup from scraped text before it's stored
+	store = sanitize.NewSanitizingStorage(store)
+
+	// Redact secrets and personal data from examples before they're stored
+	redactor, err := configureRedaction(os.Getenv("REDACT_PATTERNS"))
+	if err != nil {
+		log.Fatalf("Failed to configure redaction: %v", err)
+	}
+	store = redact.NewRedactingStorage(store, redactor)
+
+	// Check saved docs against the organization's governance policies, blocking violations in strict mode
+	engine, err := configureGovernance(os.Getenv("GOVERNANCE_POLICIES"), os.Getenv("GOVERNANCE_STRICT"))
+	if err != nil {
+		log.Fatalf("Failed to configure governance: %v", err)
+	}
+	store = governance.NewEnforcingStorage(store, engine)
+
+	// Save new docs as drafts that only appear in the catalog once a reviewer publishes them
+	workflow, err := configurePublishingWorkflow(os.Getenv("PUBLISHING_WORKFLOW"))
+	if err != nil {
+		log.Fatalf("Failed to configure publishing workflow: %v", err)
+	}
+	if workflow {
+		store = review.NewWorkflowStorage(store)
+	}
+
+	// Assign saved docs to the teams owning them in the team directory
+	directory, err := configureOwnership(os.Getenv("TEAMS_FILE"), os.Getenv("TEAMS_DIRECTORY_URL"))
+	if err != nil {
+		log.Fatalf("Failed to configure ownership: %v", err)
+	}
+	if directory != nil {
+		store = ownership.NewAssigningStorage(store, directory)
+	}
+
+	// Detect the language of saved docs, translating them for a multilingual catalog
+	var translator i18n.Translator
+	if provider := os.Getenv("TRANSLATION_PROVIDER"); provider != "" {
+		var err error
+		translator, err = i18n.NewTranslator(provider, os.Getenv("TRANSLATION_URL"), os.Getenv("TRANSLATION_API_KEY"))
+		if err != nil {
+			log.Fatalf("Failed to configure translation: %v", err)
+		}
+	}
+	store = i18n.NewDetectingStorage(store, translator, os.Getenv("TRANSLATION_TARGET"))
+
+	// Keep the schema catalog in sync with saved docs
+	store = schema.NewCatalogingStorage(store, schemaStore)
+
+	// Keep the search index in sync with saved docs
+	searchIndex = search.NewIndex()
+	if provider := os.Getenv("SEARCH_EMBEDDINGS"); provider != "" {
+		embedder, err := search.NewEmbedder(provider, os.Getenv("SEARCH_EMBEDDINGS_URL"),
+			os.Getenv("SEARCH_EMBEDDINGS_MODEL"), os.Getenv("SEARCH_EMBEDDINGS_API_KEY"))
+		if err != nil {
+			log.Fatalf("Failed to configure semantic search: %v", err)
+		}
+		searchIndex.SetEmbedder(embedder)
+	}
+	searchIndex.SetPopularity(docViews)
+	store = search.NewIndexingStorage(store, searchIndex)
+
+	// Initialize event publishing
+	if publisher, err := newEventPublisher(); err != nil {
+		log.Fatalf("Failed to start event publishing: %v", err)
+	} else if publisher != nil {
+		store = events.NewPublishingStorage(store, events.NewBus(publisher))
+	}
+	validationProxy = proxy.NewProxy(store, driftStore)
+
+	// Initialize notifications
+	notifyConfig := &notify.Config{}
+	if path := os.Getenv("NOTIFICATIONS_CONFIG"); path != "" {
+		config, err := notify.LoadConfig(path)
+		if err != nil {
+			log.Fatalf("Failed to load notifications: %v", err)
+		}
+		notifyConfig = config
+	}
+	notifier, err = notify.NewNotifier(notifyConfig, watchStore)
+	if err != nil {
+		log.Fatalf("Failed to configure notifications: %v", err)
+	}
+
+	// Initialize the credentials store
+	secretStore, err = newSecretStore(os.Getenv("SECRETS_KEY"), os.Getenv("SECRETS_VAULT_ADDR"),
+		os.Getenv("SECRETS_VAULT_TOKEN"), os.Getenv("SECRETS_VAULT_KEY"))
+	if err != nil {
+		log.Fatalf("Failed to configure credentials store: %v", err)
+	}
+
+	// Initialize OpenID Connect login
+	if issuer := os.Getenv("OIDC_ISSUER"); issuer != "" {
+		oidcProvider, err = auth.NewOIDCProvider(auth.OIDCConfig{
+			Issuer:       issuer,
+			ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+			ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+			Audience:     os.Getenv("OIDC_AUDIENCE"),
+			UserClaim:    os.Getenv("OIDC_USER_CLAIM"),
+			DefaultRole:  os.Getenv("OIDC_DEFAULT_ROLE"),
+			RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		})
+		if err != nil {
+			log.Fatalf("Failed to configure OIDC: %v", err)
+		}
+	}
+
+	// Coordinate scheduled syncs with the other instances
+	coordinator, err := configureCoordinator(os.Getenv("CLUSTER_REDIS_URL"))
+	if err != nil {
+		log.Fatalf("Failed to configure cluster coordination: %v", err)
+	}
+
+	// Initialize git repository sync
+	if repos := os.Getenv("GIT_SYNC_REPOS"); repos != "" {
+		workDir := os.Getenv("GIT_SYNC_DIR")
+		if workDir == "" {
+			workDir = "data/git"
+		}
+		gitSource = importer.NewGitSource(storage.NewWorkspaceStorage(store, models.DefaultWorkspace), workDir, strings.Split(repos, ","))
+
+		interval, err := time.ParseDuration(os.Getenv("GIT_SYNC_INTERVAL"))
+		if err != nil {
+			interval = 15 * time.Minute
+		}
+		go gitSource.Run(coordinator, interval, nil)
+	}
+
+	// Initialize Kubernetes service discovery
+	if os.Getenv("K8S_DISCOVERY") == "true" {
+		kubeSource, err := importer.NewInClusterKubernetesSource(storage.NewWorkspaceStorage(store, models.DefaultWorkspace), os.Getenv("K8S_DISCOVERY_NAMESPACE"))
+		if err != nil {
+			log.Fatalf("Failed to start Kubernetes discovery: %v", err)
+		}
+
+		interval, err := time.ParseDuration(os.Getenv("K8S_DISCOVERY_INTERVAL"))
+		if err != nil {
+			interval = 5 * time.Minute
+		}
+		go kubeSource.Run(coordinator, interval, nil)
+	}
+
+	// Check that the source URLs of docs are still reachable, unless turned off
+	sourceChecker = linkcheck.NewChecker(store, sourceCheckStore)
+	if os.Getenv("SOURCE_CHECK_INTERVAL") != "off" {
+		interval, err := time.ParseDuration(os.Getenv("SOURCE_CHECK_INTERVAL"))
+		if err != nil {
+			interval = linkcheck.DefaultInterval
+		}
+		go sourceChecker.Run(coordinator, interval, nil)
+	}
+
+	// Check the dependencies of the service for /health
+	var dataDir string
+	if gitSource != nil {
+		dataDir = gitSource.WorkDir()
+	}
+	health, err = configureHealth(memoryStore, coordinator, dataDir, os.Getenv("HEALTH_CHECK_TIMEOUT"), os.Getenv("HEALTH_CHECK_URL"), os.Getenv("HEALTH_MIN_DISK_FREE"))
+	if err != nil {
+		log.Fatalf("Failed to configure health checks: %v", err)
+	}
+
+	// Give the UI the organization's identity
+	branding, err = configureBranding(os.Getenv("UI_BRAND_NAME"), os.Getenv("UI_BRAND_LOGO"), os.Getenv("UI_BRAND_COLOR"), os.Getenv("UI_FOOTER_TEXT"), os.Getenv("UI_FOOTER_LINKS"))
+	if err != nil {
+		log.Fatalf("Failed to configure branding: %v", err)
+	}
+	themeDir = os.Getenv("UI_THEME_DIR")
+
+	// Log requests with their credentials redacted
+	accessLogger, err := configureAccessLog(os.Getenv("ACCESS_LOG"), os.Getenv("ACCESS_LOG_SAMPLE"), os.Getenv("ACCESS_LOG_DETAIL"))
+	if err != nil {
+		log.Fatalf("Failed to configure access log: %v", err)
+	}
+
+	// Compress text responses for clients accepting it
+	compressor, err := configureCompression(os.Getenv("COMPRESSION"))
+	if err != nil {
+		log.Fatalf("Failed to configure compression: %v", err)
+	}
+
+	// Bound how long requests may take and how large their bodies may be
+	timeouts, err = configureTimeouts(os.Getenv("SERVER_READ_HEADER_TIMEOUT"), os.Getenv("SERVER_READ_TIMEOUT"), os.Getenv("SERVER_WRITE_TIMEOUT"),
+		os.Getenv("SERVER_IDLE_TIMEOUT"), os.Getenv("REQUEST_TIMEOUT"), os.Getenv("LONG_REQUEST_TIMEOUT"))
+	if err != nil {
+		log.Fatalf("Failed to configure timeouts: %v", err)
+	}
+	maxBodySize, err = configureMaxBodySize(os.Getenv("MAX_BODY_SIZE"))
+	if err != nil {
+		log.Fatalf("Failed to configure body size limit: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(gin.Recovery(), assignRequestID, limitRequests)
+	if accessLogger != nil {
+		r.Use(accessLogger)
+	}
+	if compressor != nil {
+		r.Use(compressor)
+	}
+
+	// Announce when the deprecated v1 API stops being served
+	apiV1Sunset, err = configureV1Sunset(os.Getenv("API_V1_SUNSET"))
+	if err != nil {
+		log.Fatalf("Failed to configure API versions: %v", err)
+	}
+
+	// Keep the responses to requests with an Idempotency-Key for retries
+	idempotencyTTL, err = configureIdempotencyTTL(os.Getenv("IDEMPOTENCY_TTL"))
+	if err != nil {
+		log.Fatalf("Failed to configure idempotency: %v", err)
+	}
+
+	// Share the maintenance mode with the other instances, starting read-only if configured
+	if err := configureMaintenance(coordinator, os.Getenv("MAINTENANCE_MODE")); err != nil {
+		log.Fatalf("Failed to configure maintenance mode: %v", err)
+	}
+	go watchMaintenance(maintenanceRefreshInterval, nil)
+
+	// Check responses against the published schemas while developing
+	if validator := configureResponseValidation(os.Getenv("VALIDATE_RESPONSES")); validator != nil {
+		r.Use(validator)
+	}
 
 	// Setup routes
 	setupRoutes(r)
 
 	// Start server
 	log.Println("Starting server on :8080")
-	if err := r.Run(":8080"); err != nil {
+	if err := newServer(":8080", r).ListenAndServe(); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
 
+// newEventPublisher creates the event publisher selected by EVENTS_BACKEND, or nil if disabled
+func newEventPublisher() (events.Publisher, error) {
+	switch os.Getenv("EVENTS_BACKEND") {
+	case "":
+		return nil, nil
+	case "nats":
+		subject := os.Getenv("EVENTS_NATS_SUBJECT")
+		if subject == "" {
+			subject = "universal_api.docs"
+		}
+		return events.NewNATSPublisher(os.Getenv("EVENTS_NATS_URL"), subject)
+	case "kafka":
+		topic := os.Getenv("EVENTS_KAFKA_TOPIC")
+		if topic == "" {
+			topic = "universal_api.docs"
+		}
+		return events.NewKafkaPublisher(os.Getenv("EVENTS_KAFKA_REST_URL"), topic), nil
+	default:
+		return nil, errors.New("unsupported events backend: " + os.Getenv("EVENTS_BACKEND"))
+	}
+}
+
 func setupRoutes(r *gin.Engine) {
-	// Health check
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "ok",
-		})
+	// Name the instance answering, so responses behind a load balancer can be traced to it
+	r.Use(func(c *gin.Context) {
+		c.Header("X-Instance-ID", cluster.InstanceID())
+		c.Next()
 	})
 
-	// API routes
-	api := r.Group("/api/v1")
-	{
-		// Submit a new API documentation URL for scraping
-		api.POST("/docs", submitAPIDoc)
+	// Reject writes while the instance is read-only for maintenance
+	r.Use(rejectWritesInMaintenance)
 
-		// Get all API docs
-		api.GET("/docs", getAllAPIDocs)
+	// Health check of the instance and its dependencies, and a liveness check of the instance alone
+	r.GET("/health", getHealth)
+	r.GET("/health/live", getLiveness)
 
-		// Get a specific API doc by ID
-		api.GET("/docs/:id", getAPIDocByID)
-	}
+	// Unknown API routes get a problem like every other API error
+	r.NoRoute(apiNoRoute)
+
+	// API routes, with the same handlers in each version. v1 keeps its original responses and is
+	// deprecated in favor of v2.
+	registerAPIRoutes(r.Group("/api/"+apiV1, deprecateV1, authenticate))
+	registerAPIRoutes(r.Group("/api/"+apiV2, authenticate))
 
 	// UI routes
-	uiHandler := ui.NewGinHandler(store)
+	uiHandler := ui.NewHandler(store, searchIndex, schemaStore, attachmentStore, scrapeStore, viewStore, sourceCheckStore, rawSourceStore, workspaces, userStore, oidcProvider)
+	uiHandler.SetBranding(branding)
+	if themeDir != "" {
+		if err := uiHandler.SetThemeDir(themeDir); err != nil {
+			log.Fatalf("Failed to configure UI theme: %v", err)
+		}
+	}
 	uiHandler.RegisterRoutes(r)
 }
 
+// registerAPIRoutes registers the routes of a version of the API. Routes work in the workspace named by the
+// X-Workspace header (default: "default"). Once users exist, requests need an API key and each route
+// declares the permission it needs.
+func registerAPIRoutes(api *gin.RouterGroup) {
+	// Manage workspaces
+	api.GET("/workspaces", authorize(auth.PermissionRead), getWorkspaces)
+	api.POST("/workspaces", authorize(auth.PermissionAdmin), createWorkspace)
+
+	// Manage users and their API keys
+	api.GET("/users", authorize(auth.PermissionAdmin), getUsers)
+	api.POST("/users", authorize(auth.PermissionAdmin), createUser)
+	api.PUT("/users/:id", authorize(auth.PermissionAdmin), updateUser)
+	api.DELETE("/users/:id", authorize(auth.PermissionAdmin), deleteUser)
+	api.GET("/users/:id/keys", authorize(auth.PermissionAdmin), getUserAPIKeys)
+	api.POST("/users/:id/keys", authorize(auth.PermissionAdmin), createUserAPIKey)
+	api.DELETE("/users/:id/keys/:key", authorize(auth.PermissionAdmin), deleteUserAPIKey)
+
+	// Manage per-domain scrape politeness
+	api.GET("/scrape-policies", authorize(auth.PermissionAdmin), getScrapePolicies)
+	api.PUT("/scrape-policies/:domain", authorize(auth.PermissionAdmin), putScrapePolicy)
+	api.DELETE("/scrape-policies/:domain", authorize(auth.PermissionAdmin), deleteScrapePolicy)
+
+	// Circuit breakers of hosts the scraper is failing to fetch from
+	api.GET("/scrape-breakers", authorize(auth.PermissionAdmin), getScrapeBreakers)
+
+	// The heuristics HTML docs are scraped with
+	api.GET("/scrape-rules", authorize(auth.PermissionAdmin), getScrapingRules)
+
+	// Inspect and purge the cache of fetched documentation
+	api.GET("/fetch-cache", authorize(auth.PermissionAdmin), getFetchCache)
+	api.DELETE("/fetch-cache", authorize(auth.PermissionAdmin), purgeFetchCache)
+
+	// JSON Schemas of the service's payloads, versioned for integrators
+	api.GET("/schemas/meta", authorize(auth.PermissionRead), getContractSchemas)
+	api.GET("/schemas/meta/:name", authorize(auth.PermissionRead), getContractSchema)
+
+	// Re-parse the archived raw sources of docs with the current parsers
+	api.POST("/admin/reparse", authorize(auth.PermissionAdmin), longRunning, reparseAPIDocs)
+
+	// Make the instance read-only for maintenance, rejecting writes while serving reads
+	api.GET("/admin/maintenance", authorize(auth.PermissionAdmin), getMaintenance)
+	api.PUT("/admin/maintenance", authorize(auth.PermissionAdmin), putMaintenance)
+
+	// Trigger a git repository sync (usable as a GitHub/GitLab push webhook)
+	api.POST("/sources/git/sync", authorize(auth.PermissionWrite), longRunning, syncGitSource)
+
+	// Routes whose data is scoped to the workspace of the X-Workspace header
+	registerWorkspaceRoutes(api.Group("", requireWorkspace))
+
+	// The same routes, in the workspace named by the path
+	registerWorkspaceRoutes(api.Group("/workspaces/:workspace", requireWorkspace))
+}
+
+// registerWorkspaceRoutes registers the API routes whose data is scoped to a workspace
+func registerWorkspaceRoutes(api *gin.RouterGroup) {
+	// Submit a new API documentation URL for scraping
+	api.POST("/docs", authorize(auth.PermissionWrite), idempotent, submitAPIDoc)
+
+	// Get all API docs
+	api.GET("/docs", authorize(auth.PermissionRead), getAllAPIDocs)
+
+	// Stream all docs as newline-delimited JSON
+	api.GET("/docs/export.ndjson", authorize(auth.PermissionRead), longRunning, exportDocsNDJSON)
+
+	// Get several API docs by ID or URL in one request
+	api.POST("/docs/batch-get", authorize(auth.PermissionRead), batchGetAPIDocs)
+
+	// Get a specific API doc by ID
+	api.GET("/docs/:id", authorize(auth.PermissionRead), getAPIDocByID)
+
+	// Update the metadata of an API doc, such as its lifecycle stage and owner
+	api.PATCH("/docs/:id", authorize(auth.PermissionWrite), updateAPIDoc)
+
+	// Get an endpoint of an API doc by its stable ID
+	api.GET("/docs/:id/endpoints/:endpointId", authorize(auth.PermissionRead), getAPIDocEndpoint)
+
+	// Get a ready-to-run request to an endpoint as a curl or HTTPie command, fetch call, or Postman collection
+	api.GET("/docs/:id/endpoints/:endpointId/snippet", authorize(auth.PermissionRead), getAPIDocEndpointSnippet)
+
+	// Export an API doc as a printable PDF reference
+	api.GET("/docs/:id/pdf", authorize(auth.PermissionRead), exportAPIDocPDF)
+
+	// Re-scrape an API doc and report what changed
+	api.POST("/docs/:id/refresh", authorize(auth.PermissionWrite), refreshAPIDocByID)
+
+	// Submit a draft for review, and approve or reject docs in review
+	api.POST("/docs/:id/submit", authorize(auth.PermissionWrite), submitAPIDocForReview)
+	api.POST("/docs/:id/approve", authorize(auth.PermissionReview), approveAPIDoc)
+	api.POST("/docs/:id/reject", authorize(auth.PermissionReview), rejectAPIDoc)
+	api.GET("/reviews", authorize(auth.PermissionReview), getReviewQueue)
+
+	// Check a candidate spec against an API doc for breaking changes
+	api.POST("/docs/:id/check", authorize(auth.PermissionRead), limitBody(maxCandidateSpecSize), checkAPIDoc)
+
+	// Proxy requests to the documented API, recording responses that drift from the doc,
+	// with access tokens obtained through the API's OAuth2 client credentials flow
+	api.Any("/docs/:id/proxy/*path", authorize(auth.PermissionWrite), proxyAPIDoc)
+	api.POST("/docs/:id/token", authorize(auth.PermissionWrite), getAPIDocToken)
+	api.GET("/docs/:id/drift", authorize(auth.PermissionRead), getAPIDocDrift)
+
+	// Set the stored credential an API doc is scraped with
+	api.PUT("/docs/:id/credential", authorize(auth.PermissionWrite), setAPIDocCredential)
+
+	// Get the changelog of an API doc
+	api.GET("/docs/:id/changelog", authorize(auth.PermissionRead), getAPIDocChangelog)
+
+	// Get the graph of related endpoints of an API doc
+	api.GET("/docs/:id/graph", authorize(auth.PermissionRead), getAPIDocGraph)
+
+	// Get the raw content an API doc was scraped from, and list the archived versions
+	api.GET("/docs/:id/source", authorize(auth.PermissionRead), getAPIDocSource)
+	api.GET("/docs/:id/sources", authorize(auth.PermissionRead), getAPIDocSources)
+
+	// Attach files such as a provider logo or an SLA PDF to an API doc
+	api.GET("/docs/:id/attachments", authorize(auth.PermissionRead), getAPIDocAttachments)
+	api.POST("/docs/:id/attachments", authorize(auth.PermissionWrite), limitBody(maxAttachmentSize+1<<20), longRunning, uploadAPIDocAttachment)
+	api.GET("/docs/:id/attachments/:attachment", authorize(auth.PermissionRead), downloadAPIDocAttachment)
+	api.DELETE("/docs/:id/attachments/:attachment", authorize(auth.PermissionWrite), deleteAPIDocAttachment)
+
+	// Browse and search reusable schemas across docs
+	api.GET("/schemas", authorize(auth.PermissionRead), getSchemas)
+	api.GET("/schemas/:id", authorize(auth.PermissionRead), getSchema)
+	api.GET("/schemas/:id/compatibility", authorize(auth.PermissionRead), getSchemaCompatibility)
+
+	// Search endpoints across all docs, with facet counts
+	api.GET("/search", authorize(auth.PermissionRead), searchAPIDocs)
+
+	// Suggest docs and endpoints as a query is typed, for quick-open
+	api.GET("/suggest", authorize(auth.PermissionRead), suggestAPIDocs)
+
+	// Get the view counts of an API doc
+	api.GET("/docs/:id/views", authorize(auth.PermissionRead), getAPIDocViews)
+
+	// Catalog analytics
+	api.GET("/stats", authorize(auth.PermissionRead), getStats)
+
+	// Get the scrape history of a URL or domain
+	api.GET("/scrapes", authorize(auth.PermissionRead), getScrapeHistory)
+
+	// Report near-duplicate docs across the catalog
+	api.GET("/analysis/duplicates", authorize(auth.PermissionRead), getDuplicateAPIDocs)
+
+	// Report docs whose source URL is gone, and check a doc's source now
+	api.GET("/analysis/stale-sources", authorize(auth.PermissionRead), getStaleSources)
+	api.POST("/docs/:id/check-source", authorize(auth.PermissionWrite), checkAPIDocSource)
+
+	// Export docs as a merged spec, a static site, or a .uapi archive
+	api.GET("/export/catalog", authorize(auth.PermissionRead), longRunning, exportCatalog)
+	api.GET("/export/site", authorize(auth.PermissionAdmin), longRunning, exportSite)
+	api.GET("/export/archive", authorize(auth.PermissionRead), longRunning, exportArchive)
+
+	// Manage encrypted credentials for scraping docs behind a login
+	api.GET("/credentials", authorize(auth.PermissionRead), getCredentials)
+	api.POST("/credentials", authorize(auth.PermissionWrite), createCredential)
+	api.PUT("/credentials/:id", authorize(auth.PermissionWrite), updateCredential)
+	api.DELETE("/credentials/:id", authorize(auth.PermissionWrite), deleteCredential)
+
+	// Manage the caller's doc watches
+	api.GET("/watches", authorize(auth.PermissionRead), getWatches)
+	api.POST("/watches", authorize(auth.PermissionRead), createWatch)
+	api.DELETE("/watches/:id", authorize(auth.PermissionRead), deleteWatch)
+
+	// In-app notifications for the caller's watches
+	api.GET("/notifications", authorize(auth.PermissionRead), getNotifications)
+
+	// Bulk-import APIs from an OpenAPI directory such as APIs.guru
+	api.POST("/import/directory", authorize(auth.PermissionWrite), longRunning, idempotent, importDirectory)
+
+	// Import APIs from a gateway admin API (Kong, AWS API Gateway, Apigee)
+	api.POST("/import/gateway", authorize(auth.PermissionWrite), longRunning, idempotent, importGateway)
+
+	// Import a .uapi archive exported by another instance
+	api.POST("/import/archive", authorize(auth.PermissionWrite), limitBody(maxArchiveSize), longRunning, importArchive)
+}
+
+// docSubmitRequest represents a request to scrape an API documentation URL
+type docSubmitRequest struct {
+	models.APIDocRequest
+	Rules *parser.Rules `json:"rules"` // overrides the scraping rules for this doc
+}
+
+// Validate checks the fields of a submit request
+func (r *docSubmitRequest) Validate() error {
+	var v validation.Validator
+	if v.Required("url", r.URL) {
+		v.URL("url", r.URL, "http", "https")
+	}
+	v.MaxLength("description", r.Description, validation.MaxDescriptionLength)
+	v.MaxLength("credential_id", r.CredentialID, validation.MaxIDLength)
+
+	// Detection is bypassed for docs it gets wrong
+	if _, err := scraper.ParseFormat(r.Format); err != nil {
+		v.Add("format", validation.CodeNotAllowed, err.Error())
+	}
+
+	// Tuned heuristics must compile
+	if r.Rules != nil {
+		if err := scraper.Rules().Override(r.Rules).Validate(); err != nil {
+			v.Add("rules", validation.CodeInvalid, err.Error())
+		}
+	}
+	return v.Err()
+}
+
 // Handler to submit a new API documentation URL
 func submitAPIDoc(c *gin.Context) {
-	var request models.APIDocRequest
-
-	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var request docSubmitRequest
+	if !bindJSON(c, &request) {
 		return
 	}
+	format, _ := scraper.ParseFormat(request.Format)
 
-	// Validate URL
-	if request.URL == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "URL is required"})
-		return
+	// Use the stored credential for docs behind a login
+	var requestAuth scraper.RequestAuth
+	if request.CredentialID != "" {
+		var err error
+		if requestAuth, err = credentialAuth(currentWorkspace(c), request.CredentialID); err != nil {
+			respondInvalid(c, validation.Errors{{Field: "credential_id", Code: validation.CodeInvalid, Message: err.Error()}})
+			return
+		}
 	}
 
 	// Scrape the API documentation
-	apiDoc, err := scraper.ScrapeAPIDoc(request.URL)
+	apiDoc, err := scraper.ScrapeAPIDocWithOptions(request.URL, scraper.Options{Auth: requestAuth, Cache: true, Rules: request.Rules, Format: format, Context: c.Request.Context()})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scrape API documentation: " + err.Error()})
+		recordScrape(currentWorkspace(c), request.URL, "", nil, err)
+		if timedOut(c) {
+			return
+		}
+		respondProblem(c, http.StatusInternalServerError, codeScrapeFailed, "Failed to scrape API documentation: "+err.Error())
 		return
 	}
+	apiDoc.ID = storage.WorkspaceDocID(currentWorkspace(c), apiDoc.ID)
+	recordScrape(currentWorkspace(c), request.URL, apiDoc.ID, apiDoc, nil)
+	source := takeRawSource(apiDoc)
+
+	apiDoc.CredentialID = request.CredentialID
+	apiDoc.Format = string(format)
 
 	// Set description from request if provided
 	if request.Description != "" {
@@ -85,35 +698,181 @@ func submitAPIDoc(c *gin.Context) {
 	}
 
 	// Save the API doc
-	if err := store.SaveAPIDoc(apiDoc); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save API documentation: " + err.Error()})
+	if err := docStore(c).SaveAPIDoc(apiDoc); err != nil {
+		var violation *governance.ViolationError
+		if errors.As(err, &violation) {
+			respondProblemWith(c, http.StatusUnprocessableEntity, codePolicyViolation, "API documentation "+err.Error(), gin.H{"violations": violation.Violations})
+			return
+		}
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to save API documentation: "+err.Error())
 		return
 	}
+	archiveRawSource(apiDoc.ID, source)
 
 	// Return the API doc
-	c.JSON(http.StatusOK, apiDoc)
+	respond(c, http.StatusOK, apiDoc)
 }
 
 // Handler to get all API docs
 func getAllAPIDocs(c *gin.Context) {
-	docs, err := store.GetAllAPIDocs()
+	selection, ok := selectDocFields(c, true)
+	if !ok {
+		return
+	}
+
+	docs, err := docStore(c).GetAllAPIDocsPartial(selection.parts()...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get API docs: " + err.Error()})
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get API docs: "+err.Error())
 		return
 	}
 
-	c.JSON(http.StatusOK, docs)
+	if language := c.Query("language"); language != "" {
+		docs = i18n.FilterByLanguage(docs, language)
+	}
+	if lifecycle := c.Query("lifecycle"); lifecycle != "" {
+		filtered := make([]*models.APIDoc, 0, len(docs))
+		for _, doc := range docs {
+			if doc.Lifecycle == lifecycle {
+				filtered = append(filtered, doc)
+			}
+		}
+		docs = filtered
+	}
+	if c.Query("free_tier") == "true" {
+		filtered := make([]*models.APIDoc, 0, len(docs))
+		for _, doc := range docs {
+			if doc.HasFreeTier() {
+				filtered = append(filtered, doc)
+			}
+		}
+		docs = filtered
+	}
+	if team := c.Query("team"); team != "" {
+		filtered := make([]*models.APIDoc, 0, len(docs))
+		for _, doc := range docs {
+			if doc.Owner != nil && strings.EqualFold(doc.Owner.Team, team) {
+				filtered = append(filtered, doc)
+			}
+		}
+		docs = filtered
+	}
+
+	selected, err := selection.applyAll(docs)
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to select fields of API docs: "+err.Error())
+		return
+	}
+	respondList(c, selected)
+}
+
+// docUpdateRequest represents a request to update the metadata of an API doc; omitted fields are kept
+type docUpdateRequest struct {
+	Lifecycle *string       `json:"lifecycle"`
+	Owner     *models.Owner `json:"owner"` // an owner without any field clears it
+}
+
+// Validate checks the fields of an update request
+func (r *docUpdateRequest) Validate() error {
+	var v validation.Validator
+	if r.Lifecycle != nil {
+		v.OneOf("lifecycle", *r.Lifecycle, models.Lifecycles...)
+	}
+	if r.Owner != nil && *r.Owner != (models.Owner{}) {
+		if v.Required("owner.team", r.Owner.Team) {
+			v.MaxLength("owner.team", r.Owner.Team, validation.MaxNameLength)
+		}
+		v.MaxLength("owner.email", r.Owner.Email, validation.MaxNameLength)
+		v.MaxLength("owner.slack", r.Owner.Slack, validation.MaxNameLength)
+		v.MaxLength("owner.escalation", r.Owner.Escalation, validation.MaxNameLength)
+	}
+	return v.Err()
+}
+
+// Handler to update the metadata of an API doc
+func updateAPIDoc(c *gin.Context) {
+	doc, err := docStore(c).GetAPIDoc(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	var request docUpdateRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	updated := *doc
+	if request.Lifecycle != nil {
+		updated.Lifecycle = *request.Lifecycle
+	}
+	if request.Owner != nil {
+		if *request.Owner == (models.Owner{}) {
+			updated.Owner = nil
+		} else {
+			updated.Owner = request.Owner
+		}
+	}
+
+	if err := docStore(c).SaveAPIDoc(&updated); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to save API doc: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusOK, updated)
 }
 
 // Handler to get a specific API doc by ID
 func getAPIDocByID(c *gin.Context) {
 	id := c.Param("id")
+	selection, ok := selectDocFields(c, false)
+	if !ok {
+		return
+	}
+
+	doc, err := docStore(c).GetAPIDoc(id)
+	if err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	if err := viewStore.RecordView(id, "", models.ViewKindAPI); err != nil {
+		log.Printf("Failed to record view of %s: %v", id, err)
+	}
+	if notModified(c, docETag(doc), doc.UpdatedAt) {
+		return
+	}
+
+	selected, err := selection.apply(doc)
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to select fields of API doc: "+err.Error())
+		return
+	}
+	respond(c, http.StatusOK, selected)
+}
+
+// Handler to get an endpoint of an API doc by its stable ID
+func getAPIDocEndpoint(c *gin.Context) {
+	id := c.Param("id")
 
-	doc, err := store.GetAPIDoc(id)
+	doc, err := docStore(c).GetAPIDoc(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "API doc not found: " + err.Error()})
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	for _, endpoint := range doc.Endpoints {
+		if endpoint.StableID() != c.Param("endpointId") {
+			continue
+		}
+		if err := viewStore.RecordView(id, endpoint.Method+" "+endpoint.Path, models.ViewKindAPI); err != nil {
+			log.Printf("Failed to record view of %s: %v", id, err)
+		}
+		if notModified(c, docETag(doc), doc.UpdatedAt) {
+			return
+		}
+		respond(c, http.StatusOK, endpoint)
 		return
 	}
 
-	c.JSON(http.StatusOK, doc)
+	respondProblem(c, http.StatusNotFound, codeEndpointNotFound, "Endpoint not found")
 }
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"universal_api/internal/auth"
+	"universal_api/internal/models"
+	"universal_api/internal/notify"
+	"universal_api/internal/proxy"
+	"universal_api/internal/search"
+	"universal_api/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiTestServer routes both versions of the API as setupRoutes does, backed by a new memory storage that's
+// returned to seed it. The UI isn't routed, as its templates are loaded relative to the repository root.
+func apiTestServer(t *testing.T) (*gin.Engine, *storage.MemoryStorage) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	docs, watches, users := store, watchStore, userStore
+	t.Cleanup(func() { store, watchStore, userStore = docs, watches, users })
+
+	memoryStore := storage.NewMemoryStorage()
+	watchStore = memoryStore
+	driftStore = memoryStore
+	scrapeStore = memoryStore
+	viewStore = memoryStore
+	sourceCheckStore = memoryStore
+	versionStore = memoryStore
+	attachmentStore = memoryStore
+	rawSourceStore = memoryStore
+	workspaces = memoryStore
+	userStore = memoryStore
+	credentialStore = memoryStore
+	policyStore = memoryStore
+	schemaStore = memoryStore
+	idempotencyStore = memoryStore
+
+	searchIndex = search.NewIndex()
+	store = search.NewIndexingStorage(memoryStore, searchIndex)
+	validationProxy = proxy.NewProxy(store, driftStore)
+	var err error
+	if notifier, err = notify.NewNotifier(&notify.Config{}, watchStore); err != nil {
+		t.Fatalf("Failed to create notifier: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(assignRequestID)
+	r.NoRoute(apiNoRoute)
+	registerAPIRoutes(r.Group("/api/"+apiV1, deprecateV1, authenticate))
+	registerAPIRoutes(r.Group("/api/"+apiV2, authenticate))
+	return r, memoryStore
+}
+
+// apiTestUser creates a user of a role in the storage of the test server, returning the header of an API key of theirs
+func apiTestUser(t *testing.T, memoryStore *storage.MemoryStorage, id, role string) http.Header {
+	t.Helper()
+	if err := memoryStore.SaveUser(&models.User{ID: id, Name: id, Role: role}); err != nil {
+		t.Fatalf("Failed to save user: %v", err)
+	}
+	key, apiKey, err := auth.NewAPIKey(id, "test")
+	if err != nil {
+		t.Fatalf("Failed to create API key: %v", err)
+	}
+	if err := memoryStore.SaveAPIKey(apiKey); err != nil {
+		t.Fatalf("Failed to save API key: %v", err)
+	}
+	return http.Header{"X-API-Key": {key}}
+}
+
+// serveAPI sends a request to the router, with a JSON body unless body is empty, and records the response
+func serveAPI(r http.Handler, method, path, body string, header http.Header) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// decodeResponse decodes the JSON body of a response
+func decodeResponse(t *testing.T, recorder *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(recorder.Body.Bytes(), v); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", recorder.Body.String(), err)
+	}
+}
+
+// hasProblemCode reports whether a response is a problem of the code
+func hasProblemCode(t *testing.T, recorder *httptest.ResponseRecorder, code string) bool {
+	t.Helper()
+	var problem struct {
+		Code string `json:"code"`
+	}
+	decodeResponse(t, recorder, &problem)
+	return problem.Code == code
+}
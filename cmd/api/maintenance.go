@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"universal_api/internal/cluster"
+	"universal_api/internal/proxy"
+	"universal_api/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultMaintenanceRetryAfter is how many seconds clients whose writes are rejected are told to wait by default
+const defaultMaintenanceRetryAfter = 300
+
+// maintenanceSetting is the cluster setting holding the maintenance mode shared by the instances
+const maintenanceSetting = "maintenance"
+
+// maintenanceRefreshInterval is how often instances pick up the maintenance mode set on another instance
+const maintenanceRefreshInterval = time.Second
+
+// readOnlyRoutes are the routes, matched by their end, that take POST requests but only read, so they're
+// served in maintenance, and the route ending it
+var readOnlyRoutes = []string{"/docs/batch-get", "/docs/:id/check", "/docs/:id/token", "/admin/maintenance", "/login", "/logout", "/theme"}
+
+// maintenanceMode is whether the service is read-only for maintenance, such as a storage migration
+type maintenanceMode struct {
+	ReadOnly   bool       `json:"read_only"`
+	Reason     string     `json:"reason,omitempty"`      // told to clients whose writes are rejected
+	RetryAfter int        `json:"retry_after,omitempty"` // seconds clients whose writes are rejected are told to wait
+	Since      *time.Time `json:"since,omitempty"`
+	SetBy      string     `json:"set_by,omitempty"` // the ID of the user who made the service read-only
+}
+
+// Validate checks the reason and retry delay of a maintenance mode
+func (m *maintenanceMode) Validate() error {
+	var v validation.Validator
+	v.MaxLength("reason", m.Reason, validation.MaxDescriptionLength)
+	if m.RetryAfter < 0 {
+		v.Add("retry_after", validation.CodeInvalid, "can't be negative")
+	}
+	return v.Err()
+}
+
+// The maintenance mode, shared by the instances through the coordinator and cached by each of them
+var maintenance struct {
+	sync.RWMutex
+	coordinator cluster.Coordinator
+	mode        maintenanceMode
+}
+
+// currentMaintenance returns the maintenance mode as this instance last saw it
+func currentMaintenance() maintenanceMode {
+	maintenance.RLock()
+	defer maintenance.RUnlock()
+	return maintenance.mode
+}
+
+// setMaintenance sets the maintenance mode of every instance sharing the coordinator. This instance
+// applies it right away, and the others when they next refresh it.
+func setMaintenance(mode maintenanceMode) error {
+	if mode.ReadOnly {
+		if mode.RetryAfter == 0 {
+			mode.RetryAfter = defaultMaintenanceRetryAfter
+		}
+		now := time.Now()
+		mode.Since = &now
+	} else {
+		mode = maintenanceMode{}
+	}
+
+	value, err := json.Marshal(mode)
+	if err != nil {
+		return err
+	}
+	if err := maintenanceCoordinator().Set(maintenanceSetting, string(value)); err != nil {
+		return err
+	}
+	applyMaintenance(mode)
+	return nil
+}
+
+// refreshMaintenance applies the maintenance mode shared by the instances, which may have been set
+// on another instance
+func refreshMaintenance() error {
+	value, ok, err := maintenanceCoordinator().Get(maintenanceSetting)
+	if err != nil {
+		return err
+	}
+
+	var mode maintenanceMode
+	if ok {
+		if err := json.Unmarshal([]byte(value), &mode); err != nil {
+			return fmt.Errorf("invalid maintenance mode %q: %w", value, err)
+		}
+	}
+	applyMaintenance(mode)
+	return nil
+}
+
+// watchMaintenance refreshes the maintenance mode every interval until stop is closed. The mode last
+// seen is kept while the coordinator can't be reached.
+func watchMaintenance(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := refreshMaintenance(); err != nil {
+				log.Printf("Failed to refresh maintenance mode: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// applyMaintenance caches the maintenance mode on this instance, pausing its scheduled syncs while it's
+// read-only, as they write
+func applyMaintenance(mode maintenanceMode) {
+	maintenance.Lock()
+	defer maintenance.Unlock()
+	maintenance.mode = mode
+	cluster.SetPaused(mode.ReadOnly)
+}
+
+// maintenanceCoordinator returns the coordinator sharing the maintenance mode, a memory one until
+// configureMaintenance sets it
+func maintenanceCoordinator() cluster.Coordinator {
+	maintenance.Lock()
+	defer maintenance.Unlock()
+	if maintenance.coordinator == nil {
+		maintenance.coordinator = cluster.NewMemory()
+	}
+	return maintenance.coordinator
+}
+
+// configureMaintenance shares the maintenance mode through the coordinator, making every instance
+// read-only when MAINTENANCE_MODE is read-only. Otherwise, the instance starts with the mode the others
+// share, so restarting it doesn't end the maintenance.
+func configureMaintenance(coordinator cluster.Coordinator, mode string) error {
+	maintenance.Lock()
+	maintenance.coordinator = coordinator
+	maintenance.Unlock()
+
+	switch mode {
+	case "", "off", "false":
+		return refreshMaintenance()
+	case "read-only", "true":
+		return setMaintenance(maintenanceMode{ReadOnly: true})
+	}
+	return fmt.Errorf("unsupported MAINTENANCE_MODE %q: must be read-only or off", mode)
+}
+
+// Middleware rejecting the requests that write, such as scrapes, imports, and edits, with 503 and
+// Retry-After while the service is read-only. Reads are still served, as are the POST requests of
+// readOnlyRoutes.
+func rejectWritesInMaintenance(c *gin.Context) {
+	mode := currentMaintenance()
+	if !mode.ReadOnly || !writes(c) {
+		c.Next()
+		return
+	}
+
+	detail := "The service is read-only for maintenance"
+	if mode.Reason != "" {
+		detail += ": " + mode.Reason
+	}
+	c.Header("Retry-After", strconv.Itoa(mode.RetryAfter))
+	if strings.HasPrefix(c.Request.URL.Path, "/api/") {
+		respondProblem(c, http.StatusServiceUnavailable, codeReadOnly, detail)
+		return
+	}
+	c.String(http.StatusServiceUnavailable, detail)
+	c.Abort()
+}
+
+// writes checks if a request may write. Unknown routes are left to respond 404. Proxied requests
+// recording an example write it to their doc, whatever their method.
+func writes(c *gin.Context) bool {
+	route := c.FullPath()
+	if route == "" {
+		return false
+	}
+	if strings.HasSuffix(route, "/docs/:id/proxy/*path") && c.GetHeader(proxy.RecordExampleHeader) == "true" {
+		return true
+	}
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	for _, readOnly := range readOnlyRoutes {
+		if strings.HasSuffix(route, readOnly) {
+			return false
+		}
+	}
+	return true
+}
+
+// Handler to get the maintenance mode of the service
+func getMaintenance(c *gin.Context) {
+	respond(c, http.StatusOK, currentMaintenance())
+}
+
+// Handler to make every instance of the service read-only for maintenance, or writable again
+func putMaintenance(c *gin.Context) {
+	var mode maintenanceMode
+	if !bindJSON(c, &mode) {
+		return
+	}
+	mode.SetBy = currentUserID(c)
+
+	if err := setMaintenance(mode); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to set maintenance mode: "+err.Error())
+		return
+	}
+	if mode.ReadOnly {
+		log.Printf("Read-only for maintenance, set by %q: %s", mode.SetBy, mode.Reason)
+	} else {
+		log.Printf("Writable again after maintenance, set by %q", mode.SetBy)
+	}
+	respond(c, http.StatusOK, currentMaintenance())
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"universal_api/internal/cluster"
+	"universal_api/internal/proxy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestMaintenanceShared tests that the maintenance mode set on one instance reaches the others sharing
+// the coordinator, including instances starting during the maintenance
+func TestMaintenanceShared(t *testing.T) {
+	coordinator := cluster.NewMemory()
+	if err := configureMaintenance(coordinator, "read-only"); err != nil {
+		t.Fatalf("Failed to configure maintenance mode: %v", err)
+	}
+	defer setMaintenance(maintenanceMode{})
+
+	if mode := currentMaintenance(); !mode.ReadOnly || mode.RetryAfter != defaultMaintenanceRetryAfter || mode.Since == nil {
+		t.Errorf("Expected the instance to start read-only, got %+v", mode)
+	}
+	if _, ok, _ := coordinator.Get(maintenanceSetting); !ok {
+		t.Errorf("Expected the maintenance mode to be shared")
+	}
+
+	// An instance restarting without MAINTENANCE_MODE stays read-only
+	applyMaintenance(maintenanceMode{})
+	if err := configureMaintenance(coordinator, ""); err != nil {
+		t.Fatalf("Failed to configure maintenance mode: %v", err)
+	}
+	if !currentMaintenance().ReadOnly {
+		t.Errorf("Expected the instance to pick up the shared read-only mode")
+	}
+
+	// Another instance makes the service writable again
+	coordinator.Set(maintenanceSetting, `{"read_only": false}`)
+	stop := make(chan struct{})
+	go watchMaintenance(10*time.Millisecond, stop)
+	time.Sleep(50 * time.Millisecond)
+	close(stop)
+	if currentMaintenance().ReadOnly {
+		t.Errorf("Expected the instance to pick up the mode set on another instance")
+	}
+
+	coordinator.Set(maintenanceSetting, "not json")
+	if err := refreshMaintenance(); err == nil {
+		t.Errorf("Expected an invalid shared mode to fail")
+	}
+}
+
+// maintenanceTestServer routes requests through rejectWritesInMaintenance to handlers responding 200
+func maintenanceTestServer() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	r := gin.New()
+	r.Use(rejectWritesInMaintenance)
+	r.GET("/api/v2/docs", ok)
+	r.POST("/api/v2/docs", ok)
+	r.POST("/api/v2/docs/batch-get", ok)
+	r.Any("/api/v2/docs/:id/proxy/*path", ok)
+	r.POST("/submit", ok)
+	return r
+}
+
+// TestRejectWritesInMaintenance tests that only the requests that write are rejected while read-only
+func TestRejectWritesInMaintenance(t *testing.T) {
+	r := maintenanceTestServer()
+	configureMaintenance(cluster.NewMemory(), "")
+	if err := setMaintenance(maintenanceMode{ReadOnly: true, Reason: "migrating storage", RetryAfter: 600}); err != nil {
+		t.Fatalf("Failed to set maintenance mode: %v", err)
+	}
+	defer setMaintenance(maintenanceMode{})
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		record   bool
+		expected int
+	}{
+		{"read", http.MethodGet, "/api/v2/docs", false, http.StatusOK},
+		{"write", http.MethodPost, "/api/v2/docs", false, http.StatusServiceUnavailable},
+		{"read-only POST", http.MethodPost, "/api/v2/docs/batch-get", false, http.StatusOK},
+		{"unknown route", http.MethodPost, "/api/v2/unknown", false, http.StatusNotFound},
+		{"proxied GET", http.MethodGet, "/api/v2/docs/doc-1/proxy/users", false, http.StatusOK},
+		{"proxied POST", http.MethodPost, "/api/v2/docs/doc-1/proxy/users", false, http.StatusServiceUnavailable},
+		{"proxied GET recording an example", http.MethodGet, "/api/v2/docs/doc-1/proxy/users", true, http.StatusServiceUnavailable},
+		{"UI write", http.MethodPost, "/submit", false, http.StatusServiceUnavailable},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.path, nil)
+			if test.record {
+				req.Header.Set(proxy.RecordExampleHeader, "true")
+			}
+			recorder := httptest.NewRecorder()
+			r.ServeHTTP(recorder, req)
+
+			if recorder.Code != test.expected {
+				t.Fatalf("Expected %d, got %d", test.expected, recorder.Code)
+			}
+			if recorder.Code != http.StatusServiceUnavailable {
+				return
+			}
+			if recorder.Header().Get("Retry-After") != "600" {
+				t.Errorf("Expected Retry-After: 600, got %q", recorder.Header().Get("Retry-After"))
+			}
+			if !strings.Contains(recorder.Body.String(), "migrating storage") {
+				t.Errorf("Expected the reason in the response, got %s", recorder.Body.String())
+			}
+			if strings.HasPrefix(test.path, "/api/") && !strings.Contains(recorder.Body.String(), codeReadOnly) {
+				t.Errorf("Expected the %s code, got %s", codeReadOnly, recorder.Body.String())
+			}
+		})
+	}
+}
+
+// TestWritesWhenWritable tests that nothing is rejected while the service is writable
+func TestWritesWhenWritable(t *testing.T) {
+	r := maintenanceTestServer()
+	configureMaintenance(cluster.NewMemory(), "")
+
+	for _, path := range []string{"/api/v2/docs", "/api/v2/docs/doc-1/proxy/users", "/submit"} {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set(proxy.RecordExampleHeader, "true")
+		recorder := httptest.NewRecorder()
+		r.ServeHTTP(recorder, req)
+		if recorder.Code != http.StatusOK {
+			t.Errorf("Expected POST %s to be served, got %d", path, recorder.Code)
+		}
+	}
+}
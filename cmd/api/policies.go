@@ -0,0 +1,307 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"universal_api/internal/cluster"
+	"universal_api/internal/governance"
+	"universal_api/internal/models"
+	"universal_api/internal/ownership"
+	"universal_api/internal/redact"
+	"universal_api/internal/ui"
+	"universal_api/internal/validation"
+	"universal_api/pkg/parser"
+	"universal_api/pkg/scraper"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler to get all scrape policies
+func getScrapePolicies(c *gin.Context) {
+	policies, err := policyStore.GetAllScrapePolicies()
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get scrape policies: "+err.Error())
+		return
+	}
+
+	respondList(c, policies)
+}
+
+// Handler to create or replace the scrape policy of a domain
+func putScrapePolicy(c *gin.Context) {
+	var policy models.ScrapePolicy
+	if !bindJSON(c, &policy) {
+		return
+	}
+
+	var v validation.Validator
+	if policy.MinIntervalSeconds < 0 {
+		v.Add("min_interval_seconds", validation.CodeInvalid, "can't be negative")
+	}
+	if policy.MaxConcurrency < 0 {
+		v.Add("max_concurrency", validation.CodeInvalid, "can't be negative")
+	}
+	v.MaxLength("user_agent", policy.UserAgent, validation.MaxNameLength)
+	for name := range policy.Headers {
+		if strings.EqualFold(name, "Authorization") || strings.EqualFold(name, "Cookie") {
+			v.Add("headers."+name, validation.CodeNotAllowed, "use a stored credential instead")
+		}
+	}
+	if err := v.Err(); err != nil {
+		respondInvalid(c, err)
+		return
+	}
+
+	policy.Domain = strings.ToLower(c.Param("domain"))
+	policy.UpdatedAt = time.Now()
+
+	if err := policyStore.SaveScrapePolicy(&policy); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to save scrape policy: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusOK, policy)
+}
+
+// Handler to delete the scrape policy of a domain
+func deleteScrapePolicy(c *gin.Context) {
+	if err := policyStore.DeleteScrapePolicy(strings.ToLower(c.Param("domain"))); err != nil {
+		respondProblem(c, http.StatusNotFound, codeNotFound, "Scrape policy not found: "+c.Param("domain"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Handler to get the circuit breakers of hosts the scraper recently failed to fetch from
+func getScrapeBreakers(c *gin.Context) {
+	respondList(c, scraper.BreakerStates())
+}
+
+// Handler to get the fetch cache's hit and miss counts
+func getFetchCache(c *gin.Context) {
+	respond(c, http.StatusOK, scraper.CacheStatistics())
+}
+
+// Handler to purge a URL from the fetch cache, or every URL without ?url=
+func purgeFetchCache(c *gin.Context) {
+	if err := scraper.PurgeCache(c.Query("url")); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to purge fetch cache: "+err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// configureLimiterStore keeps the politeness state of hosts in the Redis server at POLITENESS_REDIS_URL, when set,
+// so their intervals and concurrency caps hold across restarts and replicas
+func configureLimiterStore(redisURL string) error {
+	if redisURL == "" {
+		return nil
+	}
+	limiter, err := scraper.NewRedisLimiter(redisURL)
+	if err != nil {
+		return err
+	}
+	scraper.SetLimiterStore(limiter)
+	return nil
+}
+
+// configureCoordinator coordinates the scheduled syncs through the Redis server at CLUSTER_REDIS_URL, when set,
+// so instances sharing it don't sync the same sources twice; without it the instance schedules syncs on its own
+func configureCoordinator(redisURL string) (cluster.Coordinator, error) {
+	if redisURL == "" {
+		return cluster.NewMemory(), nil
+	}
+	return cluster.NewRedis(redisURL)
+}
+
+// configureBranding returns the UI branding set by UI_BRAND_NAME, UI_BRAND_LOGO (an image URL), UI_BRAND_COLOR
+// (a CSS color), UI_FOOTER_TEXT, and UI_FOOTER_LINKS (comma-separated Label=URL pairs)
+func configureBranding(name, logoURL, color, footer, links string) (ui.Branding, error) {
+	branding := ui.Branding{Name: name, LogoURL: logoURL, PrimaryColor: color, Footer: footer}
+	if branding.Name == "" {
+		branding.Name = ui.DefaultBranding.Name
+	}
+	footerLinks, err := ui.ParseLinks(links)
+	if err != nil {
+		return ui.Branding{}, err
+	}
+	branding.FooterLinks = footerLinks
+	return branding, nil
+}
+
+// configureFetchCache sets up the fetch cache configured by FETCH_CACHE (memory, redis, or off) and FETCH_CACHE_TTL
+func configureFetchCache(backend, ttl, redisURL string) error {
+	duration := 5 * time.Minute
+	if ttl != "" {
+		var err error
+		if duration, err = time.ParseDuration(ttl); err != nil || duration <= 0 {
+			return fmt.Errorf("invalid FETCH_CACHE_TTL: %s", ttl)
+		}
+	}
+
+	switch backend {
+	case "", "memory":
+		scraper.SetCache(scraper.NewMemoryCache(), "memory", duration)
+	case "redis":
+		cache, err := scraper.NewRedisCache(redisURL)
+		if err != nil {
+			return err
+		}
+		scraper.SetCache(cache, "redis", duration)
+	case "off":
+		scraper.SetCache(nil, "", 0)
+	default:
+		return fmt.Errorf("unknown FETCH_CACHE backend: %s", backend)
+	}
+	return nil
+}
+
+// configureSummaries sets how scraped HTML docs summarize long descriptions, as configured by
+// SUMMARY_LENGTH (in characters) and SUMMARY_FIRST_SENTENCE (true or false)
+func configureSummaries(length, firstSentence string) error {
+	options := parser.SummaryOptions{MaxLength: parser.DefaultSummaryLength}
+	if length != "" {
+		var err error
+		if options.MaxLength, err = strconv.Atoi(length); err != nil || options.MaxLength <= 0 {
+			return fmt.Errorf("invalid SUMMARY_LENGTH: %s", length)
+		}
+	}
+	if firstSentence != "" {
+		var err error
+		if options.FirstSentence, err = strconv.ParseBool(firstSentence); err != nil {
+			return fmt.Errorf("invalid SUMMARY_FIRST_SENTENCE: %s", firstSentence)
+		}
+	}
+	scraper.SetSummaryOptions(options)
+	return nil
+}
+
+// configureUnknownPaths sets whether scraped HTML docs keep endpoints whose path couldn't be found
+func configureUnknownPaths(keep string) error {
+	if keep == "" {
+		return nil
+	}
+	value, err := strconv.ParseBool(keep)
+	if err != nil {
+		return fmt.Errorf("invalid KEEP_UNKNOWN_PATHS: %s", keep)
+	}
+	scraper.SetKeepUnknownPaths(value)
+	return nil
+}
+
+// configurePublishingWorkflow reports if PUBLISHING_WORKFLOW enables the approval workflow, in
+// which new docs are saved as drafts until a reviewer publishes them
+func configurePublishingWorkflow(enabled string) (bool, error) {
+	if enabled == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(enabled)
+	if err != nil {
+		return false, fmt.Errorf("invalid PUBLISHING_WORKFLOW: %s", enabled)
+	}
+	return value, nil
+}
+
+// configureOwnership returns the team directory assigning owners to saved docs: the teams.yaml
+// file at TEAMS_FILE, or the external directory serving teams as JSON at TEAMS_DIRECTORY_URL.
+// It returns nil when neither is set.
+func configureOwnership(path, directoryURL string) (ownership.Directory, error) {
+	switch {
+	case path != "":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TEAMS_FILE: %w", err)
+		}
+		return ownership.ParseTeams(data)
+	case directoryURL != "":
+		return ownership.NewHTTPDirectory(directoryURL), nil
+	}
+	return nil, nil
+}
+
+// configureRedaction returns the redactor of secrets and personal data in examples, finding the
+// default patterns and those of REDACT_PATTERNS, a JSON object of kinds to regular expressions
+func configureRedaction(patterns string) (*redact.Redactor, error) {
+	if patterns == "" {
+		return redact.NewRedactor(), nil
+	}
+	var expressions map[string]string
+	if err := json.Unmarshal([]byte(patterns), &expressions); err != nil {
+		return nil, fmt.Errorf("invalid REDACT_PATTERNS: %w", err)
+	}
+	extra, err := redact.ParsePatterns(expressions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDACT_PATTERNS: %w", err)
+	}
+	return redact.NewRedactor(extra...), nil
+}
+
+// configureGovernance returns the engine checking saved docs against the policies in the YAML file
+// at GOVERNANCE_POLICIES, blocking docs that violate one with error severity if GOVERNANCE_STRICT is true
+func configureGovernance(path, strict string) (*governance.Engine, error) {
+	engine := &governance.Engine{}
+	if strict != "" {
+		enabled, err := strconv.ParseBool(strict)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GOVERNANCE_STRICT: %w", err)
+		}
+		engine.Strict = enabled
+	}
+	if path == "" {
+		return engine, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GOVERNANCE_POLICIES: %w", err)
+	}
+	if engine.Policies, err = governance.ParsePolicies(data); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
+// configureHooks sets the chain of registered hooks that run on every parsed doc before it's
+// stored, from the comma-separated names in DOC_HOOKS
+func configureHooks(names string) error {
+	var chain []string
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			chain = append(chain, name)
+		}
+	}
+	if err := scraper.SetHooks(chain...); err != nil {
+		return fmt.Errorf("invalid DOC_HOOKS: %w", err)
+	}
+	return nil
+}
+
+// configureScrapingRules loads the heuristics scraped HTML docs are parsed with from the YAML
+// rules file at SCRAPING_RULES, keeping the defaults when it isn't set
+func configureScrapingRules(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read SCRAPING_RULES: %w", err)
+	}
+	rules, err := parser.ParseRules(data)
+	if err != nil {
+		return err
+	}
+	scraper.SetRules(rules)
+	return nil
+}
+
+// Handler to get the heuristics scraped HTML docs are parsed with
+func getScrapingRules(c *gin.Context) {
+	respond(c, http.StatusOK, scraper.Rules())
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"universal_api/internal/models"
+	"universal_api/internal/proxy"
+	"universal_api/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler to proxy a request to the documented API and validate the response
+func proxyAPIDoc(c *gin.Context) {
+	doc, err := docStore(c).GetAPIDoc(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	if err := validationProxy.ServeHTTP(c.Writer, c.Request, doc, c.Param("path")); err != nil {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "Failed to proxy request: "+err.Error())
+		return
+	}
+}
+
+// tokenRequest is the body of a request for an access token to a documented API
+type tokenRequest struct {
+	Scheme       string   `json:"scheme"` // OAuth2 security scheme; the first with a client credentials flow when empty
+	ClientID     string   `json:"client_id" binding:"required"`
+	ClientSecret string   `json:"client_secret" binding:"required"`
+	Scopes       []string `json:"scopes"`
+}
+
+// Validate checks the client credentials are set
+func (r tokenRequest) Validate() error {
+	var v validation.Validator
+	v.Required("client_id", r.ClientID)
+	v.Required("client_secret", r.ClientSecret)
+	return v.Err()
+}
+
+// Handler to get an access token to the documented API with the OAuth2 client credentials flow, to send
+// in the X-Upstream-Authorization header of requests through the proxy, as their Authorization header
+// carries the caller's credentials to this service
+func getAPIDocToken(c *gin.Context) {
+	var request tokenRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	doc, err := docStore(c).GetAPIDoc(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	flow, err := proxy.ClientCredentialsFlow(doc, request.Scheme)
+	if err != nil {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+
+	token, err := proxy.ClientCredentialsToken(doc, flow, request.ClientID, request.ClientSecret, request.Scopes)
+	if err != nil {
+		respondProblem(c, http.StatusBadGateway, codeUpstreamFailed, "Failed to get access token: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusOK, token)
+}
+
+// Handler to get the drift findings of an API doc, optionally filtered by endpoint
+func getAPIDocDrift(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := docStore(c).GetAPIDoc(id); err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	findings, err := driftStore.GetDriftFindings(id)
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get drift findings: "+err.Error())
+		return
+	}
+
+	method, path := c.Query("method"), c.Query("path")
+	filtered := []*models.DriftFinding{}
+	for _, finding := range findings {
+		if method != "" && !strings.EqualFold(finding.Method, method) {
+			continue
+		}
+		if path != "" && finding.Path != path {
+			continue
+		}
+		filtered = append(filtered, finding)
+	}
+
+	respondList(c, filtered)
+}
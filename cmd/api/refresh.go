@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"universal_api/internal/diff"
+	"universal_api/internal/models"
+	"universal_api/internal/notify"
+	"universal_api/internal/schema"
+	"universal_api/internal/storage"
+	"universal_api/pkg/parser"
+	"universal_api/pkg/scraper"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler to re-scrape a stored API doc and report what changed
+func refreshAPIDocByID(c *gin.Context) {
+	id := c.Param("id")
+
+	existing, err := docStore(c).GetAPIDoc(id)
+	if err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	doc, changes, err := refreshAPIDoc(c.Request.Context(), existing)
+	if err != nil {
+		if timedOut(c) {
+			return
+		}
+		respondProblem(c, http.StatusBadGateway, codeScrapeFailed, "Failed to refresh API documentation: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusOK, gin.H{
+		"doc":  doc,
+		"diff": changes,
+	})
+}
+
+// refreshAPIDoc re-scrapes a doc from its URL, saves the new version, and notifies about changes. The
+// scrape is given up when ctx is done.
+func refreshAPIDoc(ctx context.Context, existing *models.APIDoc) (*models.APIDoc, *diff.Diff, error) {
+	// Docs from sync sources are refreshed by their source, not by URL
+	if existing.Source != nil {
+		return nil, nil, errors.New("doc is managed by the " + existing.Source.Type + " source")
+	}
+
+	var requestAuth scraper.RequestAuth
+	var err error
+	if existing.CredentialID != "" {
+		requestAuth, err = credentialAuth(storage.DocWorkspace(existing), existing.CredentialID)
+	}
+
+	var doc *models.APIDoc
+	if err == nil {
+		doc, err = scraper.ScrapeAPIDocWithOptions(existing.URL, scraper.Options{Auth: requestAuth, Format: scraper.Format(existing.Format), Context: ctx})
+	}
+	if err != nil {
+		recordScrape(existing.Workspace, existing.URL, existing.ID, nil, err)
+		notifier.Notify(notify.Event{Type: notify.EventScrapeFailure, Doc: existing, Error: err})
+		return nil, nil, err
+	}
+
+	recordScrape(existing.Workspace, existing.URL, existing.ID, doc, nil)
+	source := takeRawSource(doc)
+
+	carryOver(existing, doc)
+
+	if err := store.SaveAPIDoc(doc); err != nil {
+		return nil, nil, err
+	}
+	archiveRawSource(doc.ID, source)
+
+	changes := diff.Compare(existing, doc)
+	for _, event := range notify.Events(doc, changes) {
+		notifier.Notify(event)
+	}
+
+	return doc, changes, nil
+}
+
+// carryOver keeps what a doc's new version can't get from its source: its identity, how it's
+// scraped, what users set on it, and the examples recorded through the proxy
+func carryOver(existing, doc *models.APIDoc) {
+	doc.ID = existing.ID
+	doc.Workspace = existing.Workspace
+	doc.CredentialID = existing.CredentialID
+	doc.Format = existing.Format
+	doc.Lifecycle = existing.Lifecycle
+	doc.Owner = existing.Owner
+	doc.CreatedAt = existing.CreatedAt
+	keepRecordedExamples(existing, doc)
+}
+
+// keepRecordedExamples carries examples recorded through the proxy over to the re-scraped endpoints
+// and re-infers the response schemas, pagination, and safety the new scrape is missing
+func keepRecordedExamples(existing, doc *models.APIDoc) {
+	for i := range doc.Endpoints {
+		endpoint := &doc.Endpoints[i]
+		for _, old := range existing.Endpoints {
+			if old.Method != endpoint.Method || old.Path != endpoint.Path {
+				continue
+			}
+			for _, example := range old.Examples {
+				if example.Source == "proxy" {
+					endpoint.Examples = append(endpoint.Examples, example)
+				}
+			}
+		}
+		schema.InferResponses(endpoint)
+		parser.AnnotatePagination(endpoint)
+		parser.ClassifySafety(endpoint)
+	}
+}
+
+// Handler to get the changelog of an API doc
+func getAPIDocChangelog(c *gin.Context) {
+	versions, err := docStore(c).GetAPIDocVersions(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	respondList(c, diff.Changelog(versions))
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"universal_api/internal/diff"
+	"universal_api/internal/models"
+)
+
+// TestGetAPIDocChangelog tests that a doc's changelog lists the changes of each version, newest first
+func TestGetAPIDocChangelog(t *testing.T) {
+	r, memoryStore := apiTestServer(t)
+
+	doc := &models.APIDoc{ID: "pets", Title: "Pets", Version: "1.0", Endpoints: []models.Endpoint{{Path: "/pets", Method: "GET"}}}
+	if err := store.SaveAPIDoc(doc); err != nil {
+		t.Fatalf("Failed to save doc: %v", err)
+	}
+	saved, _ := memoryStore.GetAPIDoc("pets")
+	saved.Version = "1.1"
+	saved.Endpoints = append(saved.Endpoints, models.Endpoint{Path: "/pets", Method: "POST"})
+	if err := store.SaveAPIDoc(saved); err != nil {
+		t.Fatalf("Failed to save doc: %v", err)
+	}
+
+	recorder := serveAPI(r, http.MethodGet, "/api/v2/docs/pets/changelog", "", nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var body struct {
+		Data []diff.ChangelogEntry `json:"data"`
+		Meta responseMeta          `json:"meta"`
+	}
+	decodeResponse(t, recorder, &body)
+	if len(body.Data) != 2 || body.Meta.Pagination == nil || body.Meta.Pagination.Total != 2 {
+		t.Fatalf("Expected 2 entries, got %+v", body)
+	}
+	latest := body.Data[0]
+	if latest.Version != 2 || latest.Label != "1.1" || len(latest.Added) != 1 || latest.Added[0].String() != "POST /pets" {
+		t.Errorf("Expected version 2 first, adding POST /pets, got %+v", latest)
+	}
+	if first := body.Data[1]; first.Version != 1 || first.Label != "1.0" || len(first.Added) != 1 {
+		t.Errorf("Expected version 1 last, adding GET /pets, got %+v", first)
+	}
+
+	// v1 lists the whole changelog bare
+	recorder = serveAPI(r, http.MethodGet, "/api/v1/docs/pets/changelog", "", nil)
+	var entries []diff.ChangelogEntry
+	decodeResponse(t, recorder, &entries)
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 entries in v1, got %d", len(entries))
+	}
+
+	recorder = serveAPI(r, http.MethodGet, "/api/v2/docs/missing/changelog", "", nil)
+	if recorder.Code != http.StatusNotFound || !hasProblemCode(t, recorder, codeDocNotFound) {
+		t.Errorf("Expected 404 doc_not_found for an unknown doc, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+}
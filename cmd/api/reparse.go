@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"universal_api/internal/diff"
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+	"universal_api/internal/validation"
+	"universal_api/pkg/scraper"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Outcomes of re-parsing a doc
+const (
+	reparseUpdated = "reparsed" // re-parsed, and saved unless it's a dry run
+	reparseSkipped = "skipped"  // no archived source to re-parse
+	reparseFailed  = "failed"   // the doc wasn't found, or the current parsers failed on its source
+)
+
+// reparseRequest selects the docs to re-parse; every doc with an archived source when empty
+type reparseRequest struct {
+	IDs       []string `json:"ids"`
+	Workspace string   `json:"workspace"`
+	Format    string   `json:"format"`  // only docs whose latest source was parsed as this format, e.g. html
+	DryRun    bool     `json:"dry_run"` // report what would change without saving
+}
+
+// Validate checks the fields of a reparse request
+func (r *reparseRequest) Validate() error {
+	var v validation.Validator
+	for i, id := range r.IDs {
+		field := fmt.Sprintf("ids[%d]", i)
+		if v.Required(field, id) {
+			v.MaxLength(field, id, validation.MaxIDLength)
+		}
+	}
+	v.MaxLength("workspace", r.Workspace, validation.MaxIDLength)
+	if _, err := scraper.ParseFormat(r.Format); err != nil {
+		v.Add("format", validation.CodeNotAllowed, err.Error())
+	}
+	return v.Err()
+}
+
+// reparseResult is the outcome of re-parsing one doc
+type reparseResult struct {
+	DocID   string     `json:"doc_id"`
+	Title   string     `json:"title"`
+	Outcome string     `json:"outcome"`
+	Error   string     `json:"error,omitempty"`
+	Source  int        `json:"source_version,omitempty"` // version of the raw source re-parsed
+	Diff    *diff.Diff `json:"diff,omitempty"`
+}
+
+// Handler to re-parse the archived raw sources of docs with the current parsers and update the docs,
+// without fetching them again
+func reparseAPIDocs(c *gin.Context) {
+	var request reparseRequest
+	if !bindOptionalJSON(c, &request) {
+		return
+	}
+	format, _ := scraper.ParseFormat(request.Format)
+
+	docs, missing, err := reparseCandidates(request)
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get API docs: "+err.Error())
+		return
+	}
+
+	results := []reparseResult{}
+	counts := map[string]int{reparseUpdated: 0, reparseSkipped: 0, reparseFailed: 0}
+	for _, id := range missing {
+		counts[reparseFailed]++
+		results = append(results, reparseResult{DocID: id, Outcome: reparseFailed, Error: "API doc not found"})
+	}
+	for _, existing := range docs {
+		result := reparseResult{DocID: existing.ID, Title: existing.Title}
+		source, err := rawSourceStore.GetRawSource(existing.ID, 0)
+		switch {
+		case err != nil:
+			result.Outcome = reparseSkipped
+		case format != "" && source.Format != string(format):
+			continue
+		default:
+			result.Source = source.Version
+			doc, err := reparseAPIDoc(existing, source)
+			if err == nil && !request.DryRun {
+				err = store.SaveAPIDoc(doc)
+			}
+			if err != nil {
+				result.Outcome, result.Error = reparseFailed, err.Error()
+			} else {
+				result.Outcome, result.Diff = reparseUpdated, diff.Compare(existing, doc)
+			}
+		}
+		counts[result.Outcome]++
+		results = append(results, result)
+	}
+
+	respond(c, http.StatusOK, gin.H{
+		"dry_run": request.DryRun,
+		"counts":  counts,
+		"results": results,
+	})
+}
+
+// reparseCandidates returns the docs a re-parse request selects, and the requested IDs of docs that
+// don't exist
+func reparseCandidates(request reparseRequest) ([]*models.APIDoc, []string, error) {
+	docs := storage.Storage(store)
+	if request.Workspace != "" {
+		docs = storage.NewWorkspaceStorage(store, request.Workspace)
+	}
+	if len(request.IDs) == 0 {
+		all, err := docs.GetAllAPIDocs()
+		return all, nil, err
+	}
+
+	var selected []*models.APIDoc
+	var missing []string
+	for _, id := range request.IDs {
+		if doc, err := docs.GetAPIDoc(id); err == nil {
+			selected = append(selected, doc)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return selected, missing, nil
+}
+
+// reparseAPIDoc parses a doc's archived raw source with the current parsers, returning the doc's new
+// version. The doc keeps its URL, and the spec URL of a spec discovered on its page.
+func reparseAPIDoc(existing *models.APIDoc, source *models.RawSource) (*models.APIDoc, error) {
+	content, err := rawSourceStore.GetRawSourceContent(existing.ID, source.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := scraper.ParseSource(source.URL, content, source.ContentType, scraper.Format(existing.Format))
+	if err != nil {
+		return nil, err
+	}
+	doc.URL = existing.URL
+	doc.SpecURL = existing.SpecURL
+	carryOver(existing, doc)
+	return doc, nil
+}
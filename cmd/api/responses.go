@@ -0,0 +1,169 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Codes of problems, the machine-readable reason of an error response. Clients should branch on the code,
+// which is stable, rather than on the detail, which is meant for people and may change.
+const (
+	codeInvalidRequest    = "invalid_request"    // the request is malformed or one of its values is invalid
+	codeUnauthenticated   = "unauthenticated"    // the request has no valid API key or token
+	codeForbidden         = "forbidden"          // the caller's role doesn't allow the request
+	codeNotFound          = "not_found"          // the route, or a resource other than those below, doesn't exist
+	codeDocNotFound       = "doc_not_found"      // the API doc doesn't exist or isn't visible to the caller
+	codeEndpointNotFound  = "endpoint_not_found" // the API doc has no endpoint of the ID
+	codeWorkspaceNotFound = "workspace_not_found"
+	codeConflict          = "conflict"          // the request conflicts with the current state, e.g. a duplicate ID
+	codePayloadTooLarge   = "payload_too_large" // the request body is over the size limit
+	codePolicyViolation   = "policy_violation"  // the doc violates strict governance policies; see violations
+	codeUnprocessable     = "unprocessable"     // the request is well-formed but can't be carried out
+	codeScrapeFailed      = "scrape_failed"     // the documentation couldn't be fetched or parsed
+	codeUpstreamFailed    = "upstream_failed"   // a service the request depends on failed
+	codeUnavailable       = "unavailable"       // the feature isn't configured on this server
+	codeTimeout           = "timeout"           // the request took longer than the server allows
+	codeReadOnly          = "read_only"         // the service is read-only for maintenance; retry after Retry-After
+	codeInternal          = "internal_error"
+)
+
+// problemTypePrefix prefixes the code of a problem to make its type URI
+const problemTypePrefix = "urn:universal-api:problem:"
+
+// problemContentType is the content type of error responses, see RFC 7807
+const problemContentType = "application/problem+json"
+
+// requestIDContextKey is the Gin context key of the request's ID
+const requestIDContextKey = "request_id"
+
+// envelope wraps the data of every successful JSON response of v2 of the API
+type envelope struct {
+	Data interface{}  `json:"data"`
+	Meta responseMeta `json:"meta"`
+}
+
+// responseMeta describes a response rather than the data
+type responseMeta struct {
+	RequestID  string      `json:"request_id"`
+	Pagination *pagination `json:"pagination,omitempty"` // set on lists
+}
+
+// pagination is the page of a list in a response
+type pagination struct {
+	Total  int `json:"total"`           // items in the whole list
+	Offset int `json:"offset"`          // items skipped before the page
+	Limit  int `json:"limit,omitempty"` // most items on a page; 0 when the page has the rest of the list
+}
+
+// Middleware assigning each request an ID, reusing the ID of a request forwarded by a proxy so
+// its log lines can be matched up, and returning it in the X-Request-ID header
+func assignRequestID(c *gin.Context) {
+	id := c.GetHeader("X-Request-ID")
+	if id == "" {
+		id = newRequestID()
+	}
+	c.Set(requestIDContextKey, id)
+	c.Header("X-Request-ID", id)
+	c.Next()
+}
+
+// newRequestID returns a random request ID
+func newRequestID() string {
+	var random [8]byte
+	rand.Read(random[:])
+	return hex.EncodeToString(random[:])
+}
+
+// requestID returns the ID of the request
+func requestID(c *gin.Context) string {
+	return c.GetString(requestIDContextKey)
+}
+
+// respond writes data wrapped in the response envelope, or bare in v1
+func respond(c *gin.Context, status int, data interface{}) {
+	if apiVersion(c) == apiV1 {
+		c.JSON(status, data)
+		return
+	}
+	c.JSON(status, envelope{Data: data, Meta: responseMeta{RequestID: requestID(c)}})
+}
+
+// respondList writes the page of a list selected by the limit and offset query parameters, wrapped in
+// the response envelope with the pagination. Without a limit, the rest of the list is returned. v1 returns
+// the whole list, bare.
+func respondList[T any](c *gin.Context, items []T) {
+	if apiVersion(c) == apiV1 {
+		c.JSON(http.StatusOK, items)
+		return
+	}
+
+	page := pagination{Total: len(items)}
+	for name, value := range map[string]*int{"limit": &page.Limit, "offset": &page.Offset} {
+		if raw := c.Query(name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "Invalid "+name+": must be a non-negative integer")
+				return
+			}
+			*value = n
+		}
+	}
+
+	start := min(page.Offset, len(items))
+	end := len(items)
+	if page.Limit > 0 {
+		end = min(start+page.Limit, len(items))
+	}
+	if items == nil {
+		items = []T{}
+	}
+	c.JSON(http.StatusOK, envelope{
+		Data: items[start:end],
+		Meta: responseMeta{RequestID: requestID(c), Pagination: &page},
+	})
+}
+
+// respondProblem writes an RFC 7807 problem and aborts the request, so middleware can reject requests with it
+func respondProblem(c *gin.Context, status int, code, detail string) {
+	respondProblemWith(c, status, code, detail, nil)
+}
+
+// respondProblemWith writes an RFC 7807 problem with extension members, such as the violations of a policy.
+// v1 writes the detail as the error of a plain JSON body, beside the extension members.
+func respondProblemWith(c *gin.Context, status int, code, detail string, extensions gin.H) {
+	body := gin.H{}
+	for name, value := range extensions {
+		body[name] = value
+	}
+	if apiVersion(c) == apiV1 {
+		body["error"] = detail
+		c.AbortWithStatusJSON(status, body)
+		return
+	}
+
+	body["type"] = problemTypePrefix + code
+	body["title"] = http.StatusText(status)
+	body["status"] = status
+	body["detail"] = detail
+	body["instance"] = c.Request.URL.Path
+	body["code"] = code
+	body["request_id"] = requestID(c)
+
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(status, body)
+}
+
+// Handler answering requests to unknown API routes with a problem; other paths, and v1 as it always has,
+// get the plain 404 page
+func apiNoRoute(c *gin.Context) {
+	if strings.HasPrefix(c.Request.URL.Path, "/api/") && apiVersion(c) != apiV1 {
+		respondProblem(c, http.StatusNotFound, codeNotFound, "No such API route: "+c.Request.Method+" "+c.Request.URL.Path)
+		return
+	}
+	c.String(http.StatusNotFound, "404 page not found")
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// responsesTestRouter routes a handler in both versions of the API, after assignRequestID
+func responsesTestRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(assignRequestID)
+	r.NoRoute(apiNoRoute)
+	r.GET("/api/v1/items", handler)
+	r.GET("/api/v2/items", handler)
+	return r
+}
+
+// TestRespond tests that data is wrapped in the envelope with the request's ID, and bare in v1
+func TestRespond(t *testing.T) {
+	r := responsesTestRouter(func(c *gin.Context) {
+		respond(c, http.StatusCreated, gin.H{"name": "pets"})
+	})
+
+	recorder := serveAPI(r, http.MethodGet, "/api/v2/items", "", http.Header{"X-Request-ID": {"req-1"}})
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d", recorder.Code)
+	}
+	var body struct {
+		Data map[string]string      `json:"data"`
+		Meta map[string]interface{} `json:"meta"`
+	}
+	decodeResponse(t, recorder, &body)
+	if body.Data["name"] != "pets" || body.Meta["request_id"] != "req-1" {
+		t.Errorf("Expected the data in the envelope with the request ID, got %s", recorder.Body.String())
+	}
+	if _, ok := body.Meta["pagination"]; ok {
+		t.Errorf("Expected no pagination outside lists, got %s", recorder.Body.String())
+	}
+
+	recorder = serveAPI(r, http.MethodGet, "/api/v1/items", "", nil)
+	if recorder.Code != http.StatusCreated || strings.TrimSpace(recorder.Body.String()) != `{"name":"pets"}` {
+		t.Errorf("Expected the bare data in v1, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+// TestRespondList tests paging lists with the limit and offset query parameters
+func TestRespondList(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	r := responsesTestRouter(func(c *gin.Context) {
+		respondList(c, items)
+	})
+
+	tests := []struct {
+		query    string
+		expected []int
+		page     pagination
+	}{
+		{"", []int{1, 2, 3, 4, 5}, pagination{Total: 5}},
+		{"?limit=2", []int{1, 2}, pagination{Total: 5, Limit: 2}},
+		{"?limit=2&offset=2", []int{3, 4}, pagination{Total: 5, Offset: 2, Limit: 2}},
+		{"?limit=2&offset=4", []int{5}, pagination{Total: 5, Offset: 4, Limit: 2}},
+		{"?offset=3", []int{4, 5}, pagination{Total: 5, Offset: 3}},
+		{"?offset=9", []int{}, pagination{Total: 5, Offset: 9}},
+		{"?limit=0", []int{1, 2, 3, 4, 5}, pagination{Total: 5}},
+		{"?limit=10", []int{1, 2, 3, 4, 5}, pagination{Total: 5, Limit: 10}},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			recorder := serveAPI(r, http.MethodGet, "/api/v2/items"+test.query, "", nil)
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("Expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+			}
+			var body struct {
+				Data []int        `json:"data"`
+				Meta responseMeta `json:"meta"`
+			}
+			decodeResponse(t, recorder, &body)
+			if body.Data == nil || len(body.Data) != len(test.expected) {
+				t.Fatalf("Expected %v, got %s", test.expected, recorder.Body.String())
+			}
+			for i := range test.expected {
+				if body.Data[i] != test.expected[i] {
+					t.Fatalf("Expected %v, got %v", test.expected, body.Data)
+				}
+			}
+			if body.Meta.Pagination == nil || *body.Meta.Pagination != test.page {
+				t.Errorf("Expected pagination %+v, got %+v", test.page, body.Meta.Pagination)
+			}
+		})
+	}
+
+	for _, query := range []string{"?limit=-1", "?limit=ten", "?offset=-2", "?offset=1.5"} {
+		recorder := serveAPI(r, http.MethodGet, "/api/v2/items"+query, "", nil)
+		if recorder.Code != http.StatusBadRequest || !hasProblemCode(t, recorder, codeInvalidRequest) {
+			t.Errorf("Expected 400 invalid_request for %s, got %d: %s", query, recorder.Code, recorder.Body.String())
+		}
+	}
+
+	// v1 ignores paging and returns the whole list bare
+	recorder := serveAPI(r, http.MethodGet, "/api/v1/items?limit=2&offset=1", "", nil)
+	if recorder.Code != http.StatusOK || strings.TrimSpace(recorder.Body.String()) != "[1,2,3,4,5]" {
+		t.Errorf("Expected the whole list bare in v1, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	// An empty list is an empty array, not null
+	items = nil
+	recorder = serveAPI(r, http.MethodGet, "/api/v2/items", "", nil)
+	if !strings.Contains(recorder.Body.String(), `"data":[]`) {
+		t.Errorf("Expected an empty array for an empty list, got %s", recorder.Body.String())
+	}
+}
+
+// TestRespondProblem tests the members and content type of problems, and the plain body of v1 errors
+func TestRespondProblem(t *testing.T) {
+	r := responsesTestRouter(func(c *gin.Context) {
+		respondProblemWith(c, http.StatusUnprocessableEntity, codePolicyViolation, "Doc violates policies", gin.H{"violations": []string{"no-auth"}})
+	})
+
+	recorder := serveAPI(r, http.MethodGet, "/api/v2/items", "", http.Header{"X-Request-ID": {"req-1"}})
+	if recorder.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected 422, got %d", recorder.Code)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, problemContentType) {
+		t.Errorf("Expected content type %s, got %s", problemContentType, contentType)
+	}
+	var problem map[string]interface{}
+	decodeResponse(t, recorder, &problem)
+	expected := map[string]interface{}{
+		"type":       problemTypePrefix + codePolicyViolation,
+		"title":      "Unprocessable Entity",
+		"status":     float64(http.StatusUnprocessableEntity),
+		"detail":     "Doc violates policies",
+		"instance":   "/api/v2/items",
+		"code":       codePolicyViolation,
+		"request_id": "req-1",
+	}
+	for name, value := range expected {
+		if problem[name] != value {
+			t.Errorf("Expected %s to be %v, got %v", name, value, problem[name])
+		}
+	}
+	if violations, ok := problem["violations"].([]interface{}); !ok || len(violations) != 1 {
+		t.Errorf("Expected the violations extension member, got %v", problem["violations"])
+	}
+
+	// v1 errors are plain JSON with the detail as the error, beside the extension members
+	recorder = serveAPI(r, http.MethodGet, "/api/v1/items", "", nil)
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/json") {
+		t.Errorf("Expected v1 errors to be application/json, got %s", contentType)
+	}
+	problem = nil
+	decodeResponse(t, recorder, &problem)
+	if len(problem) != 2 || problem["error"] != "Doc violates policies" || problem["violations"] == nil {
+		t.Errorf("Expected the error and violations in v1, got %s", recorder.Body.String())
+	}
+}
+
+// TestAPINoRoute tests that unknown v2 API routes get a problem, and v1 and other paths the plain 404 page
+func TestAPINoRoute(t *testing.T) {
+	r := responsesTestRouter(func(c *gin.Context) {})
+
+	recorder := serveAPI(r, http.MethodGet, "/api/v2/unknown", "", nil)
+	if recorder.Code != http.StatusNotFound || !strings.HasPrefix(recorder.Header().Get("Content-Type"), problemContentType) {
+		t.Fatalf("Expected a 404 problem, got %d %s", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+	var problem map[string]interface{}
+	decodeResponse(t, recorder, &problem)
+	if problem["code"] != codeNotFound || problem["detail"] != "No such API route: GET /api/v2/unknown" {
+		t.Errorf("Expected a not_found problem naming the route, got %s", recorder.Body.String())
+	}
+
+	for _, path := range []string{"/api/v1/unknown", "/unknown"} {
+		recorder := serveAPI(r, http.MethodGet, path, "", nil)
+		if recorder.Code != http.StatusNotFound || recorder.Body.String() != "404 page not found" {
+			t.Errorf("Expected the plain 404 page for %s, got %d: %s", path, recorder.Code, recorder.Body.String())
+		}
+	}
+}
+
+// TestAssignRequestID tests that requests get a new ID unless a proxy forwarded one
+func TestAssignRequestID(t *testing.T) {
+	r := responsesTestRouter(func(c *gin.Context) {
+		c.String(http.StatusOK, requestID(c))
+	})
+
+	recorder := serveAPI(r, http.MethodGet, "/api/v2/items", "", http.Header{"X-Request-ID": {"from-proxy"}})
+	if recorder.Body.String() != "from-proxy" || recorder.Header().Get("X-Request-ID") != "from-proxy" {
+		t.Errorf("Expected the forwarded ID to be reused, got %q and header %q", recorder.Body.String(), recorder.Header().Get("X-Request-ID"))
+	}
+
+	ids := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		recorder := serveAPI(r, http.MethodGet, "/api/v2/items", "", nil)
+		id := recorder.Header().Get("X-Request-ID")
+		if len(id) != 16 || recorder.Body.String() != id {
+			t.Errorf("Expected a new 16-character ID in the header and context, got %q and %q", id, recorder.Body.String())
+		}
+		ids[id] = true
+	}
+	if len(ids) != 2 {
+		t.Errorf("Expected each request to get its own ID, got %v", ids)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"universal_api/internal/models"
+	"universal_api/internal/review"
+	"universal_api/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reviewRequest represents a reviewer's comment on an approval or rejection
+type reviewRequest struct {
+	Comment string `json:"comment"`
+}
+
+// Validate checks the length of the comment
+func (r *reviewRequest) Validate() error {
+	var v validation.Validator
+	v.MaxLength("comment", r.Comment, validation.MaxDescriptionLength)
+	return v.Err()
+}
+
+// Handler to submit a draft for review
+func submitAPIDocForReview(c *gin.Context) {
+	transitionAPIDoc(c, func(doc *models.APIDoc, _ string) error {
+		return review.Submit(doc)
+	})
+}
+
+// Handler to approve a doc in review, publishing it
+func approveAPIDoc(c *gin.Context) {
+	transitionAPIDoc(c, func(doc *models.APIDoc, comment string) error {
+		return review.Approve(doc, currentUserID(c), comment)
+	})
+}
+
+// Handler to reject a doc in review, sending it back to its submitter as a draft
+func rejectAPIDoc(c *gin.Context) {
+	transitionAPIDoc(c, func(doc *models.APIDoc, comment string) error {
+		return review.Reject(doc, currentUserID(c), comment)
+	})
+}
+
+// transitionAPIDoc moves a doc to another status and saves it, responding with 409 for
+// transitions its status doesn't allow
+func transitionAPIDoc(c *gin.Context, transition func(doc *models.APIDoc, comment string) error) {
+	var request reviewRequest
+	if !bindOptionalJSON(c, &request) {
+		return
+	}
+
+	doc, err := docStore(c).GetAPIDoc(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	if err := transition(doc, request.Comment); err != nil {
+		status, code := http.StatusInternalServerError, codeInternal
+		if errors.Is(err, review.ErrInvalidTransition) {
+			status, code = http.StatusConflict, codeConflict
+		}
+		respondProblem(c, status, code, "Failed to change the status of the API doc: "+err.Error())
+		return
+	}
+
+	if err := docStore(c).SaveAPIDoc(doc); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to save API documentation: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusOK, doc)
+}
+
+// Handler to get the docs of the workspace waiting for review
+func getReviewQueue(c *gin.Context) {
+	docs, err := docStore(c).GetAllAPIDocs()
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get API docs: "+err.Error())
+		return
+	}
+
+	queue := []*models.APIDoc{}
+	for _, doc := range docs {
+		if doc.Status == models.StatusInReview {
+			queue = append(queue, doc)
+		}
+	}
+
+	respondList(c, queue)
+}
+
+// currentUserID returns the ID of the request's user, or "" without authentication
+func currentUserID(c *gin.Context) string {
+	if user := currentUser(c); user != nil {
+		return user.ID
+	}
+	return ""
+}
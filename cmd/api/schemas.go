@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+
+	"universal_api/internal/models"
+	"universal_api/internal/schema"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler to search the schema catalog by name, description, or field, optionally within one doc
+func getSchemas(c *gin.Context) {
+	schemas, err := schemaStore.GetAllSchemas()
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get schemas: "+err.Error())
+		return
+	}
+
+	docID := c.Query("doc")
+	filtered := []*models.Schema{}
+	for _, s := range schemas {
+		if inCurrentWorkspace(c, s.Workspace) && (docID == "" || s.DocID == docID) {
+			filtered = append(filtered, s)
+		}
+	}
+
+	respondList(c, schema.Search(filtered, c.Query("q"), c.Query("field")))
+}
+
+// Handler to get a schema of the catalog by ID
+func getSchema(c *gin.Context) {
+	s, err := schemaStore.GetSchema(c.Param("id"))
+	if err != nil || !inCurrentWorkspace(c, s.Workspace) {
+		respondProblem(c, http.StatusNotFound, codeNotFound, "Schema not found")
+		return
+	}
+
+	respond(c, http.StatusOK, s)
+}
+
+// Handler to check if values of a schema can be consumed where a target schema is expected
+func getSchemaCompatibility(c *gin.Context) {
+	source, err := schemaStore.GetSchema(c.Param("id"))
+	if err != nil || !inCurrentWorkspace(c, source.Workspace) {
+		respondProblem(c, http.StatusNotFound, codeNotFound, "Schema not found")
+		return
+	}
+
+	targetID := c.Query("target")
+	if targetID == "" {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "Target schema is required")
+		return
+	}
+	target, err := schemaStore.GetSchema(targetID)
+	if err != nil || !inCurrentWorkspace(c, target.Workspace) {
+		respondProblem(c, http.StatusNotFound, codeNotFound, "Target schema not found")
+		return
+	}
+
+	resolve := func(docID, name string) *models.Schema {
+		s, err := schemaStore.GetSchema(docID + ":" + name)
+		if err != nil {
+			return nil
+		}
+		return s
+	}
+	respond(c, http.StatusOK, schema.CheckCompatibility(source, target, resolve))
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"universal_api/internal/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler to search endpoints across all API docs, with facet counts
+func searchAPIDocs(c *gin.Context) {
+	query := search.ParseQuery(c.Request.URL.Query())
+	query.Workspace = currentWorkspace(c)
+
+	result, err := searchIndex.Search(query)
+	if errors.Is(err, search.ErrSemanticUnavailable) {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "Failed to search: "+err.Error())
+		return
+	}
+	if err != nil {
+		respondProblem(c, http.StatusBadGateway, codeUpstreamFailed, "Failed to search: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusOK, result)
+}
+
+// maxSuggestions caps the limit of a suggest request, which runs on every keystroke
+const maxSuggestions = 20
+
+// Handler to suggest the docs and endpoints best matching a partial query, for quick-open
+func suggestAPIDocs(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	if limit > maxSuggestions {
+		limit = maxSuggestions
+	}
+
+	respond(c, http.StatusOK, searchIndex.Suggest(currentWorkspace(c), c.Query("q"), limit))
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"universal_api/internal/snippet"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler to get a ready-to-run request to an endpoint, as a curl or HTTPie command, a fetch call,
+// or a Postman collection, with ?format= (default curl)
+func getAPIDocEndpointSnippet(c *gin.Context) {
+	doc, err := docStore(c).GetAPIDoc(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	format := c.DefaultQuery("format", snippet.FormatCurl)
+	for _, endpoint := range doc.Endpoints {
+		if endpoint.StableID() != c.Param("endpointId") {
+			continue
+		}
+
+		text, err := snippet.Generate(doc, endpoint, format)
+		if errors.Is(err, snippet.ErrNotCallable) {
+			respondProblem(c, http.StatusUnprocessableEntity, codeUnprocessable, err.Error())
+			return
+		}
+		if err != nil {
+			respondProblem(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+			return
+		}
+
+		contentType := "text/plain; charset=utf-8"
+		if format == snippet.FormatPostman {
+			contentType = "application/json"
+		}
+		c.Data(http.StatusOK, contentType, []byte(text))
+		return
+	}
+
+	respondProblem(c, http.StatusNotFound, codeEndpointNotFound, "Endpoint not found")
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"universal_api/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// takeRawSource detaches the raw source from a freshly scraped doc, so it's archived rather than
+// saved with the doc
+func takeRawSource(doc *models.APIDoc) *models.RawSource {
+	source := doc.RawSource
+	doc.RawSource = nil
+	return source
+}
+
+// archiveRawSource archives the raw source a doc was scraped from. Failures are only logged: the doc
+// is saved either way.
+func archiveRawSource(docID string, source *models.RawSource) {
+	if source == nil {
+		return
+	}
+	source.DocID = docID
+	if err := rawSourceStore.SaveRawSource(source); err != nil {
+		log.Printf("Failed to archive the raw source of %s: %v", docID, err)
+	}
+}
+
+// Handler to list the archived raw sources of an API doc
+func getAPIDocSources(c *gin.Context) {
+	if _, err := docStore(c).GetAPIDoc(c.Param("id")); err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	sources, err := rawSourceStore.GetRawSources(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get raw sources: "+err.Error())
+		return
+	}
+
+	respondList(c, sources)
+}
+
+// Handler to download the raw source an API doc was scraped from, the latest or the one of the
+// version query parameter. The content is served as fetched, in a sandbox so pages can't run scripts.
+func getAPIDocSource(c *gin.Context) {
+	if _, err := docStore(c).GetAPIDoc(c.Param("id")); err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	version := 0
+	if v := c.Query("version"); v != "" {
+		var err error
+		if version, err = strconv.Atoi(v); err != nil || version < 1 {
+			respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "Invalid version: "+v)
+			return
+		}
+	}
+
+	source, err := rawSourceStore.GetRawSource(c.Param("id"), version)
+	if err != nil {
+		respondProblem(c, http.StatusNotFound, codeNotFound, "Raw source not found")
+		return
+	}
+	content, err := rawSourceStore.GetRawSourceContent(source.DocID, source.Version)
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get raw source: "+err.Error())
+		return
+	}
+
+	contentType := source.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.Header("Content-Security-Policy", "sandbox")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("X-Source-URL", source.URL)
+	c.Header("X-Source-Version", strconv.Itoa(source.Version))
+	c.Data(http.StatusOK, contentType, content)
+}
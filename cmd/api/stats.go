@@ -0,0 +1,118 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"universal_api/internal/cluster"
+	"universal_api/internal/models"
+	"universal_api/internal/stats"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler to get catalog analytics
+func getStats(c *gin.Context) {
+	docs, err := docStore(c).GetAllAPIDocs()
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get API docs: "+err.Error())
+		return
+	}
+
+	allScrapes, err := scrapeStore.GetScrapeRecords()
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get scrape records: "+err.Error())
+		return
+	}
+	var scrapes []*models.ScrapeRecord
+	for _, scrape := range allScrapes {
+		if inCurrentWorkspace(c, scrape.Workspace) {
+			scrapes = append(scrapes, scrape)
+		}
+	}
+
+	views, err := viewStore.GetAllViewCounts()
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get view counts: "+err.Error())
+		return
+	}
+
+	result := stats.Compute(docs, scrapes, views)
+	result.Instance = cluster.InstanceID()
+	respond(c, http.StatusOK, result)
+}
+
+// Handler to get the scrape history of a URL or of a domain, newest first
+func getScrapeHistory(c *gin.Context) {
+	url, domain := c.Query("url"), c.Query("domain")
+	if url == "" && domain == "" {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "Either url or domain is required")
+		return
+	}
+
+	allScrapes, err := scrapeStore.GetScrapeRecords()
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get scrape records: "+err.Error())
+		return
+	}
+	var scrapes []*models.ScrapeRecord
+	for _, scrape := range allScrapes {
+		if inCurrentWorkspace(c, scrape.Workspace) {
+			scrapes = append(scrapes, scrape)
+		}
+	}
+
+	respond(c, http.StatusOK, stats.History(scrapes, url, domain))
+}
+
+// Handler to get the aggregated view counts of an API doc
+func getAPIDocViews(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := docStore(c).GetAPIDoc(id); err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	counts, err := viewStore.GetViewCounts(id)
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get view counts: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusOK, counts)
+}
+
+// docViews returns the total views of a doc, for boosting popular docs in search
+func docViews(docID string) int {
+	counts, err := viewStore.GetViewCounts(docID)
+	if err != nil {
+		return 0
+	}
+	return counts.PageViews + counts.APIReads
+}
+
+// recordScrape records the outcome of scraping a doc URL in a workspace, with the number of endpoints
+// and the original encoding of the scraped doc, which is nil when the scrape failed
+func recordScrape(workspace, url, docID string, doc *models.APIDoc, scrapeErr error) {
+	record := &models.ScrapeRecord{
+		Workspace: workspace,
+		URL:       url,
+		DocID:     docID,
+		Success:   scrapeErr == nil,
+		Instance:  cluster.InstanceID(),
+		ScrapedAt: time.Now(),
+	}
+	if doc != nil {
+		record.Endpoints = len(doc.Endpoints)
+		record.Encoding = doc.Encoding
+		record.MetadataSources = doc.MetadataSources
+	}
+	if scrapeErr != nil {
+		record.Error = scrapeErr.Error()
+	}
+
+	if err := scrapeStore.RecordScrape(record); err != nil {
+		log.Printf("Failed to record scrape of %s: %v", url, err)
+	}
+}
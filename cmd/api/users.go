@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"universal_api/internal/auth"
+	"universal_api/internal/models"
+	"universal_api/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userRequest represents a request to create or update a user
+type userRequest struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Role       string   `json:"role" binding:"required"`
+	Workspaces []string `json:"workspaces"`
+}
+
+// apiKeyRequest represents a request to create an API key
+type apiKeyRequest struct {
+	Name string `json:"name"`
+}
+
+// Validate checks the fields of an API key request
+func (r *apiKeyRequest) Validate() error {
+	var v validation.Validator
+	v.MaxLength("name", r.Name, validation.MaxNameLength)
+	return v.Err()
+}
+
+// Validate checks the fields of a user request
+func (r *userRequest) Validate() error {
+	var v validation.Validator
+	v.MaxLength("id", r.ID, validation.MaxIDLength)
+	v.MaxLength("name", r.Name, validation.MaxNameLength)
+	if v.Required("role", r.Role) {
+		v.OneOf("role", r.Role, auth.Roles...)
+	}
+	return v.Err()
+}
+
+// validateUserWorkspaces checks that the workspaces of a user request exist, responding with 400 if not
+func validateUserWorkspaces(c *gin.Context, request *userRequest) bool {
+	for i, id := range request.Workspaces {
+		if _, err := workspaces.GetWorkspace(id); err != nil {
+			respondInvalid(c, validation.Errors{{Field: fmt.Sprintf("workspaces[%d]", i), Code: validation.CodeNotAllowed, Message: "Workspace not found: " + id}})
+			return false
+		}
+	}
+	return true
+}
+
+// isLastAdmin checks if a user is the only admin, who can't be removed or demoted
+func isLastAdmin(id string) (bool, error) {
+	users, err := userStore.GetAllUsers()
+	if err != nil {
+		return false, err
+	}
+
+	admins := 0
+	last := false
+	for _, user := range users {
+		if user.Role == auth.RoleAdmin {
+			admins++
+			last = user.ID == id
+		}
+	}
+	return admins == 1 && last, nil
+}
+
+// Handler to get all users
+func getUsers(c *gin.Context) {
+	users, err := userStore.GetAllUsers()
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get users: "+err.Error())
+		return
+	}
+
+	respondList(c, users)
+}
+
+// Handler to create a user along with a first API key
+func createUser(c *gin.Context) {
+	var request userRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	if request.ID == "" {
+		respondInvalid(c, validation.Errors{{Field: "id", Code: validation.CodeRequired, Message: "is required"}})
+		return
+	}
+	if !validateUserWorkspaces(c, &request) {
+		return
+	}
+
+	// The first user turns authorization on, so it must be able to manage the others
+	if !auth.Enabled(userStore) && request.Role != auth.RoleAdmin {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, "The first user must be an admin")
+		return
+	}
+	if _, err := userStore.GetUser(request.ID); err == nil {
+		respondProblem(c, http.StatusConflict, codeConflict, "User already exists: "+request.ID)
+		return
+	}
+
+	user := &models.User{
+		ID:         request.ID,
+		Name:       request.Name,
+		Role:       request.Role,
+		Workspaces: request.Workspaces,
+		CreatedAt:  time.Now(),
+	}
+	key, apiKey, err := auth.NewAPIKey(user.ID, "initial")
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to generate API key: "+err.Error())
+		return
+	}
+
+	if err := userStore.SaveAPIKey(apiKey); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to save API key: "+err.Error())
+		return
+	}
+	if err := userStore.SaveUser(user); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to save user: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusCreated, gin.H{"user": user, "api_key": key})
+}
+
+// Handler to update a user's name, role, and workspaces
+func updateUser(c *gin.Context) {
+	user, err := userStore.GetUser(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusNotFound, codeNotFound, "User not found: "+c.Param("id"))
+		return
+	}
+
+	var request userRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+	if !validateUserWorkspaces(c, &request) {
+		return
+	}
+
+	if request.Role != auth.RoleAdmin {
+		if last, err := isLastAdmin(user.ID); err != nil || last {
+			respondProblem(c, http.StatusConflict, codeConflict, "The last admin can't be demoted")
+			return
+		}
+	}
+
+	updated := *user
+	updated.Name = request.Name
+	updated.Role = request.Role
+	updated.Workspaces = request.Workspaces
+
+	if err := userStore.SaveUser(&updated); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to save user: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusOK, updated)
+}
+
+// Handler to delete a user and their API keys
+func deleteUser(c *gin.Context) {
+	if last, err := isLastAdmin(c.Param("id")); err != nil || last {
+		respondProblem(c, http.StatusConflict, codeConflict, "The last admin can't be deleted")
+		return
+	}
+
+	if err := userStore.DeleteUser(c.Param("id")); err != nil {
+		respondProblem(c, http.StatusNotFound, codeNotFound, "Failed to delete user: "+err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Handler to list a user's API keys
+func getUserAPIKeys(c *gin.Context) {
+	if _, err := userStore.GetUser(c.Param("id")); err != nil {
+		respondProblem(c, http.StatusNotFound, codeNotFound, "User not found: "+c.Param("id"))
+		return
+	}
+
+	keys, err := userStore.GetAPIKeys(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get API keys: "+err.Error())
+		return
+	}
+	if keys == nil {
+		keys = []*models.APIKey{}
+	}
+
+	respondList(c, keys)
+}
+
+// Handler to create an API key for a user; the key is only returned once
+func createUserAPIKey(c *gin.Context) {
+	if _, err := userStore.GetUser(c.Param("id")); err != nil {
+		respondProblem(c, http.StatusNotFound, codeNotFound, "User not found: "+c.Param("id"))
+		return
+	}
+
+	var request apiKeyRequest
+	if !bindOptionalJSON(c, &request) {
+		return
+	}
+
+	key, apiKey, err := auth.NewAPIKey(c.Param("id"), request.Name)
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to generate API key: "+err.Error())
+		return
+	}
+	if err := userStore.SaveAPIKey(apiKey); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to save API key: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusCreated, gin.H{"api_key": key, "key": apiKey})
+}
+
+// Handler to revoke one of a user's API keys
+func deleteUserAPIKey(c *gin.Context) {
+	keys, err := userStore.GetAPIKeys(c.Param("id"))
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get API keys: "+err.Error())
+		return
+	}
+
+	for _, key := range keys {
+		if key.ID == c.Param("key") {
+			if err := userStore.DeleteAPIKey(key.ID); err != nil {
+				respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to delete API key: "+err.Error())
+				return
+			}
+			c.Status(http.StatusNoContent)
+			return
+		}
+	}
+
+	respondProblem(c, http.StatusNotFound, codeNotFound, "API key not found")
+}
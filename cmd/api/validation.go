@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"universal_api/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// validatable is a request that checks its own fields, returning validation.Errors
+type validatable interface {
+	Validate() error
+}
+
+// bindJSON decodes the JSON body of a request into request and validates it, responding with a problem
+// listing the invalid fields if either fails
+func bindJSON(c *gin.Context, request interface{}) bool {
+	if err := decodeJSON(c, request); err != nil {
+		if !bodyTooLarge(c, err) {
+			respondInvalid(c, jsonError(c, err))
+		}
+		return false
+	}
+	return validateRequest(c, request)
+}
+
+// bindOptionalJSON is bindJSON for requests whose body may be left out, leaving request as it is
+func bindOptionalJSON(c *gin.Context, request interface{}) bool {
+	if err := decodeJSON(c, request); err != nil && !errors.Is(err, io.EOF) {
+		if !bodyTooLarge(c, err) {
+			respondInvalid(c, jsonError(c, err))
+		}
+		return false
+	}
+	return validateRequest(c, request)
+}
+
+// decodeJSON decodes the JSON body of a request into request. v1 binds it with Gin as it always has,
+// checking the fields tagged binding:"required", so its clients keep getting the errors they did.
+func decodeJSON(c *gin.Context, request interface{}) error {
+	if apiVersion(c) == apiV1 {
+		return c.ShouldBindJSON(request)
+	}
+	return json.NewDecoder(c.Request.Body).Decode(request)
+}
+
+// jsonError describes an error decoding a JSON body as field errors; v1 reports the error as it is
+func jsonError(c *gin.Context, err error) error {
+	if apiVersion(c) == apiV1 {
+		return err
+	}
+	return validation.FromJSON(err)
+}
+
+// validateRequest validates a request with a Validate method, responding with a problem if it's invalid
+func validateRequest(c *gin.Context, request interface{}) bool {
+	if v, ok := request.(validatable); ok {
+		if err := v.Validate(); err != nil {
+			respondInvalid(c, err)
+			return false
+		}
+	}
+	return true
+}
+
+// respondInvalid responds to an invalid request with a problem listing its field errors in errors. v1
+// responds with the first error alone, as it only reported one before requests were validated field by field.
+func respondInvalid(c *gin.Context, err error) {
+	var errs validation.Errors
+	if !errors.As(err, &errs) {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return
+	}
+	if apiVersion(c) == apiV1 {
+		respondProblem(c, http.StatusBadRequest, codeInvalidRequest, v1Error(c, errs[0]))
+		return
+	}
+	respondProblemWith(c, http.StatusBadRequest, codeInvalidRequest, "Invalid request: "+errs.Error(), gin.H{"errors": errs})
+}
+
+// v1Error words a field error the way v1 did for the checks it made before requests were validated field
+// by field; errors of the checks added since are worded "field: message"
+func v1Error(c *gin.Context, fieldError validation.FieldError) string {
+	field, _, _ := strings.Cut(fieldError.Field, "[")
+	route := strings.TrimPrefix(c.FullPath(), "/api/"+apiV1)
+	switch {
+	case field == "lifecycle" && fieldError.Code == validation.CodeNotAllowed:
+		return "Lifecycle must be design, beta, ga, deprecated, or retired"
+	case field == "owner.team" && fieldError.Code == validation.CodeRequired:
+		return "Owner team is required"
+	case field == "role" && fieldError.Code == validation.CodeNotAllowed:
+		return "Role must be viewer, editor, reviewer, or admin"
+	case field == "id" && route == "/users" && fieldError.Code == validation.CodeRequired:
+		return "User ID is required"
+	case field == "id" && route == "/workspaces" && fieldError.Code != validation.CodeTooLong:
+		return "Workspace ID must be lowercase letters, digits, and dashes"
+	case field == "min_interval_seconds" || field == "max_concurrency":
+		return "min_interval_seconds and max_concurrency can't be negative"
+	case strings.HasPrefix(field, "headers.") && fieldError.Code == validation.CodeNotAllowed:
+		return "Use stored credentials instead of the " + strings.TrimPrefix(field, "headers.") + " header"
+	case field == "format", field == "rules", field == "credential_id" && fieldError.Code == validation.CodeInvalid,
+		field == "workspaces", field == "channels":
+		// The message is the error v1 reported
+		return fieldError.Message
+	case fieldError.Field == "":
+		return fieldError.Message
+	}
+	return fieldError.Field + ": " + fieldError.Message
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Versions of the API. Both serve the same routes with the same handlers; only the shape of
+// responses differs. v1 is frozen with the responses its clients were built against: bare payloads
+// and {"error": ...} bodies. v2 wraps payloads in the envelope and reports problem+json errors.
+const (
+	apiV1 = "v1"
+	apiV2 = "v2"
+)
+
+// apiV1DeprecatedAt is when v2 superseded v1, sent in the Deprecation header of v1 responses
+var apiV1DeprecatedAt = time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+
+// Date v1 stops being served, from API_V1_SUNSET, sent in the Sunset header of v1 responses; zero if not planned
+var apiV1Sunset time.Time
+
+// apiVersion returns the API version of a request from its path, so middleware running before the
+// version's routes are matched responds in the right shape too
+func apiVersion(c *gin.Context) string {
+	if strings.HasPrefix(c.Request.URL.Path, "/api/"+apiV1+"/") {
+		return apiV1
+	}
+	return apiV2
+}
+
+// configureV1Sunset parses API_V1_SUNSET, a date such as 2027-06-30
+func configureV1Sunset(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	sunset, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid API_V1_SUNSET %q: must be a date like 2027-06-30", value)
+	}
+	return sunset, nil
+}
+
+// Middleware marking v1 responses deprecated (RFC 9745), with the Sunset date once one is planned
+// (RFC 8594) and a link to the same route in v2
+func deprecateV1(c *gin.Context) {
+	c.Header("Deprecation", "@"+strconv.FormatInt(apiV1DeprecatedAt.Unix(), 10))
+	if !apiV1Sunset.IsZero() {
+		c.Header("Sunset", apiV1Sunset.UTC().Format(http.TimeFormat))
+	}
+	successor := "/api/" + apiV2 + strings.TrimPrefix(c.Request.URL.Path, "/api/"+apiV1)
+	c.Header("Link", "<"+successor+`>; rel="successor-version"`)
+	c.Next()
+}
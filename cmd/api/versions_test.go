@@ -0,0 +1,147 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"universal_api/internal/models"
+)
+
+// TestV1Frozen tests that v1 keeps the responses its clients were built against, deprecated in favor of v2
+func TestV1Frozen(t *testing.T) {
+	r, _ := apiTestServer(t)
+	sunset := apiV1Sunset
+	apiV1Sunset = time.Date(2027, time.June, 30, 0, 0, 0, 0, time.UTC)
+	t.Cleanup(func() { apiV1Sunset = sunset })
+
+	for _, id := range []string{"pets", "stores"} {
+		doc := &models.APIDoc{ID: id, Title: id, Endpoints: []models.Endpoint{{Path: "/" + id, Method: "GET"}}}
+		if err := store.SaveAPIDoc(doc); err != nil {
+			t.Fatalf("Failed to save doc: %v", err)
+		}
+	}
+
+	// Lists are bare and whole, ignoring paging
+	recorder := serveAPI(r, http.MethodGet, "/api/v1/docs?limit=1", "", nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var docs []map[string]interface{}
+	decodeResponse(t, recorder, &docs)
+	if len(docs) != 2 || docs[0]["endpoints"] == nil {
+		t.Errorf("Expected both docs whole in a bare list, got %s", recorder.Body.String())
+	}
+	if deprecation := recorder.Header().Get("Deprecation"); deprecation != "@1792108800" {
+		t.Errorf("Expected the Deprecation header, got %q", deprecation)
+	}
+	if sunset := recorder.Header().Get("Sunset"); sunset != "Wed, 30 Jun 2027 00:00:00 GMT" {
+		t.Errorf("Expected the Sunset header, got %q", sunset)
+	}
+	if link := recorder.Header().Get("Link"); link != `</api/v2/docs>; rel="successor-version"` {
+		t.Errorf("Expected a Link to v2, got %q", link)
+	}
+
+	// Payloads are bare
+	recorder = serveAPI(r, http.MethodGet, "/api/v1/docs/pets", "", nil)
+	var doc map[string]interface{}
+	decodeResponse(t, recorder, &doc)
+	if doc["id"] != "pets" {
+		t.Errorf("Expected the bare doc, got %s", recorder.Body.String())
+	}
+
+	// Errors are {"error": ...} and nothing else
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		expected string
+	}{
+		{"unknown doc", http.MethodGet, "/api/v1/docs/missing", "", http.StatusNotFound, "API doc not found: API doc not found"},
+		{"malformed body", http.MethodPost, "/api/v1/docs", "{", http.StatusBadRequest, "unexpected EOF"},
+		{"wrong type", http.MethodPost, "/api/v1/docs", `{"url": 1}`, http.StatusBadRequest, "json: cannot unmarshal number into Go struct field docSubmitRequest.url of type string"},
+		{"missing field", http.MethodPost, "/api/v1/docs", `{}`, http.StatusBadRequest, "Key: 'docSubmitRequest.APIDocRequest.URL' Error:Field validation for 'URL' failed on the 'required' tag"},
+		{"invalid lifecycle", http.MethodPatch, "/api/v1/docs/pets", `{"lifecycle": "alpha"}`, http.StatusBadRequest, "Lifecycle must be design, beta, ga, deprecated, or retired"},
+		{"owner without team", http.MethodPatch, "/api/v1/docs/pets", `{"owner": {"email": "a@example.com"}}`, http.StatusBadRequest, "Owner team is required"},
+		{"invalid workspace ID", http.MethodPost, "/api/v1/workspaces", `{"id": "Team A"}`, http.StatusBadRequest, "Workspace ID must be lowercase letters, digits, and dashes"},
+		{"check by URL without one", http.MethodPost, "/api/v1/docs/pets/check", `{"url": ""}`, http.StatusBadRequest, "Failed to read candidate spec: Key: 'checkRequest.URL' Error:Field validation for 'URL' failed on the 'required' tag"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := serveAPI(r, test.method, test.path, test.body, nil)
+			if recorder.Code != test.status {
+				t.Fatalf("Expected %d, got %d: %s", test.status, recorder.Code, recorder.Body.String())
+			}
+			if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/json") {
+				t.Errorf("Expected application/json, got %s", contentType)
+			}
+			var body map[string]interface{}
+			decodeResponse(t, recorder, &body)
+			if len(body) != 1 || body["error"] != test.expected {
+				t.Errorf("Expected {\"error\": %q}, got %s", test.expected, recorder.Body.String())
+			}
+		})
+	}
+
+	// Unknown routes get the plain 404 page
+	recorder = serveAPI(r, http.MethodGet, "/api/v1/unknown", "", nil)
+	if recorder.Code != http.StatusNotFound || recorder.Body.String() != "404 page not found" {
+		t.Errorf("Expected the plain 404 page, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+// TestV2Envelope tests that v2 wraps payloads in the envelope, pages lists, and reports problems
+func TestV2Envelope(t *testing.T) {
+	r, _ := apiTestServer(t)
+	for _, id := range []string{"pets", "stores"} {
+		if err := store.SaveAPIDoc(&models.APIDoc{ID: id, Title: id}); err != nil {
+			t.Fatalf("Failed to save doc: %v", err)
+		}
+	}
+
+	recorder := serveAPI(r, http.MethodGet, "/api/v2/docs?limit=1", "", http.Header{"X-Request-ID": {"req-1"}})
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var list struct {
+		Data []map[string]interface{} `json:"data"`
+		Meta responseMeta             `json:"meta"`
+	}
+	decodeResponse(t, recorder, &list)
+	if len(list.Data) != 1 || list.Meta.RequestID != "req-1" || list.Meta.Pagination == nil || *list.Meta.Pagination != (pagination{Total: 2, Limit: 1}) {
+		t.Errorf("Expected the first page of 2 docs in the envelope, got %s", recorder.Body.String())
+	}
+	for _, header := range []string{"Deprecation", "Sunset", "Link"} {
+		if value := recorder.Header().Get(header); value != "" {
+			t.Errorf("Expected no %s header in v2, got %q", header, value)
+		}
+	}
+
+	recorder = serveAPI(r, http.MethodGet, "/api/v2/docs/pets", "", nil)
+	var doc struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	decodeResponse(t, recorder, &doc)
+	if doc.Data["id"] != "pets" {
+		t.Errorf("Expected the doc in the envelope, got %s", recorder.Body.String())
+	}
+
+	recorder = serveAPI(r, http.MethodPatch, "/api/v2/docs/pets", `{"lifecycle": "alpha"}`, nil)
+	if recorder.Code != http.StatusBadRequest || !strings.HasPrefix(recorder.Header().Get("Content-Type"), problemContentType) {
+		t.Fatalf("Expected a 400 problem, got %d %s", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+	var problem struct {
+		Code   string `json:"code"`
+		Errors []struct {
+			Field string `json:"field"`
+			Code  string `json:"code"`
+		} `json:"errors"`
+	}
+	decodeResponse(t, recorder, &problem)
+	if problem.Code != codeInvalidRequest || len(problem.Errors) != 1 || problem.Errors[0].Field != "lifecycle" {
+		t.Errorf("Expected an invalid_request problem with the lifecycle's error, got %s", recorder.Body.String())
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"universal_api/internal/auth"
+	"universal_api/internal/models"
+	"universal_api/internal/notify"
+	"universal_api/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// watchRequest represents a request to watch a doc
+type watchRequest struct {
+	DocID    string   `json:"doc_id" binding:"required"`
+	Events   []string `json:"events"`
+	Channels []string `json:"channels"`
+}
+
+// Validate checks the fields of a watch request
+func (r *watchRequest) Validate() error {
+	var v validation.Validator
+	if v.Required("doc_id", r.DocID) {
+		v.MaxLength("doc_id", r.DocID, validation.MaxIDLength)
+	}
+	for i, event := range r.Events {
+		v.OneOf(fmt.Sprintf("events[%d]", i), event, notify.EventTypes...)
+	}
+	return v.Err()
+}
+
+// currentSubscriber identifies the caller by authenticated user, or by API key or user header while no users exist.
+// Keys are identified by their hash, as subscribers are stored with watches and returned with them.
+func currentSubscriber(c *gin.Context) string {
+	if user := currentUser(c); user != nil {
+		return "user:" + user.ID
+	}
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return "key:" + auth.HashKey(key)
+	}
+	if user := c.GetHeader("X-User"); user != "" {
+		return "user:" + user
+	}
+	return ""
+}
+
+// requireSubscriber returns the caller's subscriber ID or aborts with 401
+func requireSubscriber(c *gin.Context) (string, bool) {
+	subscriber := currentSubscriber(c)
+	if subscriber == "" {
+		respondProblem(c, http.StatusUnauthorized, codeUnauthenticated, "X-API-Key or X-User header is required")
+		return "", false
+	}
+	return subscriber, true
+}
+
+// Handler to list the caller's watches
+func getWatches(c *gin.Context) {
+	subscriber, ok := requireSubscriber(c)
+	if !ok {
+		return
+	}
+
+	watches, err := watchStore.GetWatchesBySubscriber(subscriber)
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get watches: "+err.Error())
+		return
+	}
+
+	filtered := []*models.Watch{}
+	for _, watch := range watches {
+		if inCurrentWorkspace(c, watch.Workspace) {
+			filtered = append(filtered, watch)
+		}
+	}
+
+	respondList(c, filtered)
+}
+
+// Handler to watch a doc
+func createWatch(c *gin.Context) {
+	subscriber, ok := requireSubscriber(c)
+	if !ok {
+		return
+	}
+
+	var request watchRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	if _, err := docStore(c).GetAPIDoc(request.DocID); err != nil {
+		respondProblem(c, http.StatusNotFound, codeDocNotFound, "API doc not found: "+err.Error())
+		return
+	}
+
+	for i, name := range request.Channels {
+		if !notifier.HasChannel(name) {
+			respondInvalid(c, validation.Errors{{Field: fmt.Sprintf("channels[%d]", i), Code: validation.CodeNotAllowed, Message: "Unknown notification channel: " + name}})
+			return
+		}
+	}
+
+	watch := &models.Watch{
+		ID:         fmt.Sprintf("watch-%d", time.Now().UnixNano()),
+		Workspace:  currentWorkspace(c),
+		DocID:      request.DocID,
+		Subscriber: subscriber,
+		Events:     request.Events,
+		Channels:   request.Channels,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := watchStore.SaveWatch(watch); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to save watch: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusCreated, watch)
+}
+
+// Handler to stop watching a doc
+func deleteWatch(c *gin.Context) {
+	subscriber, ok := requireSubscriber(c)
+	if !ok {
+		return
+	}
+
+	// Only allow deleting the caller's own watches in the workspace
+	watches, err := watchStore.GetWatchesBySubscriber(subscriber)
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get watches: "+err.Error())
+		return
+	}
+
+	for _, watch := range watches {
+		if watch.ID == c.Param("id") && inCurrentWorkspace(c, watch.Workspace) {
+			if err := watchStore.DeleteWatch(watch.ID); err != nil {
+				respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to delete watch: "+err.Error())
+				return
+			}
+			c.Status(http.StatusNoContent)
+			return
+		}
+	}
+
+	respondProblem(c, http.StatusNotFound, codeNotFound, "Watch not found")
+}
+
+// Handler to list the caller's in-app notifications
+func getNotifications(c *gin.Context) {
+	subscriber, ok := requireSubscriber(c)
+	if !ok {
+		return
+	}
+
+	notifications, err := watchStore.GetNotifications(subscriber)
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get notifications: "+err.Error())
+		return
+	}
+
+	filtered := []*models.Notification{}
+	for _, notification := range notifications {
+		if inCurrentWorkspace(c, notification.Workspace) {
+			filtered = append(filtered, notification)
+		}
+	}
+
+	respondList(c, filtered)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"universal_api/internal/auth"
+	"universal_api/internal/models"
+)
+
+// TestWatchHandlers tests watching docs as an API key, scoped to the key and the workspace
+func TestWatchHandlers(t *testing.T) {
+	r, memoryStore := apiTestServer(t)
+	if err := memoryStore.SaveWorkspace(&models.Workspace{ID: "team-a", Name: "Team A"}); err != nil {
+		t.Fatalf("Failed to save workspace: %v", err)
+	}
+	if err := store.SaveAPIDoc(&models.APIDoc{ID: "pets", Title: "Pets", URL: "https://pets.example.com/openapi.json"}); err != nil {
+		t.Fatalf("Failed to save doc: %v", err)
+	}
+
+	// Without users, callers are told apart by their key
+	key := "uak_watcher-secret"
+	caller := http.Header{"X-Api-Key": {key}}
+	other := http.Header{"X-Api-Key": {"uak_someone-else"}}
+
+	recorder := serveAPI(r, http.MethodPost, "/api/v2/watches", `{"doc_id": "pets", "events": ["breaking_change"]}`, caller)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if strings.Contains(recorder.Body.String(), "watcher-secret") {
+		t.Errorf("Expected the response not to contain the API key, got %s", recorder.Body.String())
+	}
+	var created struct {
+		Data models.Watch `json:"data"`
+	}
+	decodeResponse(t, recorder, &created)
+	if expected := "key:" + auth.HashKey(key); created.Data.Subscriber != expected {
+		t.Errorf("Expected subscriber %q, got %q", expected, created.Data.Subscriber)
+	}
+	stored, err := memoryStore.GetWatchesByDoc("pets")
+	if err != nil || len(stored) != 1 || strings.Contains(stored[0].Subscriber, key) {
+		t.Fatalf("Expected one watch stored without the API key, got %v, %v", stored, err)
+	}
+
+	var listed struct {
+		Data []models.Watch `json:"data"`
+	}
+	decodeResponse(t, serveAPI(r, http.MethodGet, "/api/v2/watches", "", caller), &listed)
+	if len(listed.Data) != 1 || listed.Data[0].ID != created.Data.ID {
+		t.Errorf("Expected the caller's watch, got %v", listed.Data)
+	}
+	decodeResponse(t, serveAPI(r, http.MethodGet, "/api/v2/watches", "", other), &listed)
+	if len(listed.Data) != 0 {
+		t.Errorf("Expected no watches of another key, got %v", listed.Data)
+	}
+	inTeam := http.Header{"X-Api-Key": {key}, "X-Workspace": {"team-a"}}
+	decodeResponse(t, serveAPI(r, http.MethodGet, "/api/v2/watches", "", inTeam), &listed)
+	if len(listed.Data) != 0 {
+		t.Errorf("Expected no watches in another workspace, got %v", listed.Data)
+	}
+
+	if recorder := serveAPI(r, http.MethodDelete, "/api/v2/watches/"+created.Data.ID, "", other); recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 deleting another key's watch, got %d", recorder.Code)
+	}
+	if recorder := serveAPI(r, http.MethodDelete, "/api/v2/watches/"+created.Data.ID, "", inTeam); recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 deleting a watch of another workspace, got %d", recorder.Code)
+	}
+	if recorder := serveAPI(r, http.MethodDelete, "/api/v2/watches/"+created.Data.ID, "", caller); recorder.Code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if stored, _ := memoryStore.GetWatchesByDoc("pets"); len(stored) != 0 {
+		t.Errorf("Expected the watch to be deleted, got %v", stored)
+	}
+}
+
+// TestCreateWatchErrors tests the errors of watching a doc
+func TestCreateWatchErrors(t *testing.T) {
+	r, _ := apiTestServer(t)
+	if err := store.SaveAPIDoc(&models.APIDoc{ID: "pets", Title: "Pets", URL: "https://pets.example.com/openapi.json"}); err != nil {
+		t.Fatalf("Failed to save doc: %v", err)
+	}
+	caller := http.Header{"X-User": {"ada"}}
+
+	recorder := serveAPI(r, http.MethodPost, "/api/v2/watches", `{"doc_id": "pets"}`, nil)
+	if recorder.Code != http.StatusUnauthorized || !hasProblemCode(t, recorder, codeUnauthenticated) {
+		t.Errorf("Expected 401 without a subscriber, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	recorder = serveAPI(r, http.MethodPost, "/api/v2/watches", `{"doc_id": "missing"}`, caller)
+	if recorder.Code != http.StatusNotFound || !hasProblemCode(t, recorder, codeDocNotFound) {
+		t.Errorf("Expected 404 watching an unknown doc, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	recorder = serveAPI(r, http.MethodPost, "/api/v2/watches", `{"doc_id": "pets", "channels": ["pager"]}`, caller)
+	if recorder.Code != http.StatusBadRequest || !hasProblemCode(t, recorder, codeInvalidRequest) {
+		t.Errorf("Expected 400 with an unknown channel, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	recorder = serveAPI(r, http.MethodPost, "/api/v2/watches", `{"doc_id": "pets", "events": ["doc_eaten"]}`, caller)
+	if recorder.Code != http.StatusBadRequest || !hasProblemCode(t, recorder, codeInvalidRequest) {
+		t.Errorf("Expected 400 with an unknown event, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	recorder = serveAPI(r, http.MethodPost, "/api/v2/watches", `{"doc_id": "pets"}`, caller)
+	var created struct {
+		Data models.Watch `json:"data"`
+	}
+	decodeResponse(t, recorder, &created)
+	if created.Data.Subscriber != "user:ada" {
+		t.Errorf("Expected subscriber user:ada, got %q", created.Data.Subscriber)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"universal_api/internal/auth"
+	"universal_api/internal/models"
+	"universal_api/internal/review"
+	"universal_api/internal/storage"
+	"universal_api/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// workspaceHeader names the workspace of requests outside /api/v1/workspaces/:workspace
+const workspaceHeader = "X-Workspace"
+
+// workspaceContextKey is the Gin context key of the request's workspace ID
+const workspaceContextKey = "workspace"
+
+// Middleware resolving the request's workspace from the path or the X-Workspace header
+func requireWorkspace(c *gin.Context) {
+	id := c.Param("workspace")
+	if id == "" {
+		id = c.GetHeader(workspaceHeader)
+	}
+	if id == "" {
+		id = models.DefaultWorkspace
+	}
+
+	if _, err := workspaces.GetWorkspace(id); err != nil {
+		respondProblem(c, http.StatusNotFound, codeWorkspaceNotFound, "Workspace not found: "+id)
+		return
+	}
+
+	c.Set(workspaceContextKey, id)
+	c.Next()
+}
+
+// currentWorkspace returns the ID of the request's workspace
+func currentWorkspace(c *gin.Context) string {
+	if id := c.GetString(workspaceContextKey); id != "" {
+		return id
+	}
+	return models.DefaultWorkspace
+}
+
+// inCurrentWorkspace checks if a record's workspace is the request's; records without one belong to the default workspace
+func inCurrentWorkspace(c *gin.Context, workspace string) bool {
+	if workspace == "" {
+		workspace = models.DefaultWorkspace
+	}
+	return workspace == currentWorkspace(c)
+}
+
+// docStore returns the storage restricted to the request's workspace and the docs the caller can see
+func docStore(c *gin.Context) storage.Storage {
+	return review.NewVisibleStorage(storage.NewWorkspaceStorage(store, currentWorkspace(c)), currentUser(c))
+}
+
+// Handler to get the workspaces the caller can access
+func getWorkspaces(c *gin.Context) {
+	all, err := workspaces.GetAllWorkspaces()
+	if err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to get workspaces: "+err.Error())
+		return
+	}
+
+	user := currentUser(c)
+	accessible := []*models.Workspace{}
+	for _, workspace := range all {
+		if user == nil || auth.CanAccessWorkspace(user, workspace.ID) {
+			accessible = append(accessible, workspace)
+		}
+	}
+
+	respondList(c, accessible)
+}
+
+// Handler to create a workspace
+func createWorkspace(c *gin.Context) {
+	var workspace models.Workspace
+	if !bindJSON(c, &workspace) {
+		return
+	}
+
+	var v validation.Validator
+	if v.Required("id", workspace.ID) && !storage.ValidWorkspaceID(workspace.ID) {
+		v.Add("id", validation.CodeInvalid, "must be lowercase letters, digits, and dashes")
+	}
+	v.MaxLength("name", workspace.Name, validation.MaxNameLength)
+	if err := v.Err(); err != nil {
+		respondInvalid(c, err)
+		return
+	}
+	if _, err := workspaces.GetWorkspace(workspace.ID); err == nil {
+		respondProblem(c, http.StatusConflict, codeConflict, "Workspace already exists: "+workspace.ID)
+		return
+	}
+
+	if workspace.Name == "" {
+		workspace.Name = workspace.ID
+	}
+	workspace.CreatedAt = time.Now()
+
+	if err := workspaces.SaveWorkspace(&workspace); err != nil {
+		respondProblem(c, http.StatusInternalServerError, codeInternal, "Failed to save workspace: "+err.Error())
+		return
+	}
+
+	respond(c, http.StatusCreated, workspace)
+}
@@ -0,0 +1,143 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+
+	"universal_api/internal/models"
+)
+
+// Graph node kinds
+const (
+	NodeEndpoint = "endpoint"
+	NodeSchema   = "schema"
+)
+
+// Graph edge kinds
+const (
+	EdgeLink        = "link"        // a response's values feed the target operation
+	EdgeSchema      = "schema"      // the endpoint returns the schema
+	EdgeSubresource = "subresource" // the target's path is nested under the source's
+)
+
+// Graph relates the endpoints of a doc to each other and to the schemas they return
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is an endpoint or a reusable schema
+type GraphNode struct {
+	ID         string `json:"id"` // "GET /users/{id}" for endpoints, "schema:User" for schemas
+	Kind       string `json:"kind"`
+	Label      string `json:"label"`
+	EndpointID string `json:"endpoint_id,omitempty"` // stable ID of the endpoint, for permalinks
+	Method     string `json:"method,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Summary    string `json:"summary,omitempty"`
+}
+
+// GraphEdge is a relationship between two nodes
+type GraphEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Kind  string `json:"kind"`
+	Label string `json:"label,omitempty"`
+}
+
+// methodOrder ranks methods when picking the endpoint that stands for a path in subresource edges
+var methodOrder = map[string]int{"GET": 0, "POST": 1, "PUT": 2, "PATCH": 3, "DELETE": 4}
+
+// DependencyGraph builds the graph of a doc's endpoints: OpenAPI links between them, the
+// named schemas they return, and subresource edges from each path to the paths nested under it
+func DependencyGraph(doc *models.APIDoc) *Graph {
+	graph := &Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	seen := make(map[string]bool)
+	addNode := func(node GraphNode) {
+		if !seen[node.ID] {
+			seen[node.ID] = true
+			graph.Nodes = append(graph.Nodes, node)
+		}
+	}
+
+	// The endpoint standing for each path: GET if documented, otherwise the first by method order
+	representative := make(map[string]string)
+	for _, endpoint := range doc.Endpoints {
+		id := endpointID(endpoint.Method, endpoint.Path)
+		addNode(GraphNode{ID: id, Kind: NodeEndpoint, Label: id, EndpointID: endpoint.StableID(),
+			Method: strings.ToUpper(endpoint.Method), Path: endpoint.Path, Summary: endpoint.Summary})
+
+		current, ok := representative[endpoint.Path]
+		if !ok || rankMethod(id) < rankMethod(current) {
+			representative[endpoint.Path] = id
+		}
+	}
+
+	for _, endpoint := range doc.Endpoints {
+		id := endpointID(endpoint.Method, endpoint.Path)
+
+		for _, link := range endpoint.Links {
+			target := endpointID(link.Method, link.Path)
+			if link.Path != "" && seen[target] {
+				graph.Edges = append(graph.Edges, GraphEdge{From: id, To: target, Kind: EdgeLink, Label: link.Name})
+			}
+		}
+
+		schemas := make(map[string]bool)
+		for _, response := range endpoint.Responses {
+			if response.SchemaName != "" && !schemas[response.SchemaName] {
+				schemas[response.SchemaName] = true
+				schemaID := "schema:" + response.SchemaName
+				addNode(GraphNode{ID: schemaID, Kind: NodeSchema, Label: response.SchemaName})
+				graph.Edges = append(graph.Edges, GraphEdge{From: id, To: schemaID, Kind: EdgeSchema})
+			}
+		}
+	}
+
+	for path, id := range representative {
+		if parent := parentPath(path, representative); parent != "" {
+			graph.Edges = append(graph.Edges, GraphEdge{From: representative[parent], To: id, Kind: EdgeSubresource})
+		}
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Kind < b.Kind
+	})
+	return graph
+}
+
+// endpointID returns the node ID of an endpoint
+func endpointID(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// rankMethod returns the rank of an endpoint node's method, unknown methods last
+func rankMethod(id string) int {
+	method, _, _ := strings.Cut(id, " ")
+	if rank, ok := methodOrder[method]; ok {
+		return rank
+	}
+	return len(methodOrder)
+}
+
+// parentPath returns the closest documented path that path is nested under, or ""
+func parentPath(path string, paths map[string]string) string {
+	for {
+		i := strings.LastIndex(strings.TrimSuffix(path, "/"), "/")
+		if i <= 0 {
+			return ""
+		}
+		path = path[:i]
+		if _, ok := paths[path]; ok {
+			return path
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package analysis
+
+import (
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestDependencyGraph tests link, schema, and subresource edges
+func TestDependencyGraph(t *testing.T) {
+	doc := &models.APIDoc{Endpoints: []models.Endpoint{
+		{Method: "POST", Path: "/orders", Links: []models.Link{{Name: "GetOrder", Method: "GET", Path: "/orders/{id}"}},
+			Responses: []models.Response{{StatusCode: 201, SchemaName: "Order"}}},
+		{Method: "GET", Path: "/orders", Responses: []models.Response{{StatusCode: 200, SchemaName: "Order"}}},
+		{Method: "GET", Path: "/orders/{id}", Responses: []models.Response{{StatusCode: 200, SchemaName: "Order"}}},
+		{Method: "DELETE", Path: "/orders/{id}/items/{item}"},
+	}}
+
+	graph := DependencyGraph(doc)
+	if len(graph.Nodes) != 5 {
+		t.Errorf("Expected 4 endpoint nodes and 1 schema node, got %+v", graph.Nodes)
+	}
+
+	expected := []GraphEdge{
+		{From: "GET /orders", To: "GET /orders/{id}", Kind: EdgeSubresource},
+		{From: "GET /orders", To: "schema:Order", Kind: EdgeSchema},
+		{From: "GET /orders/{id}", To: "DELETE /orders/{id}/items/{item}", Kind: EdgeSubresource},
+		{From: "GET /orders/{id}", To: "schema:Order", Kind: EdgeSchema},
+		{From: "POST /orders", To: "GET /orders/{id}", Kind: EdgeLink, Label: "GetOrder"},
+		{From: "POST /orders", To: "schema:Order", Kind: EdgeSchema},
+	}
+	if len(graph.Edges) != len(expected) {
+		t.Fatalf("Expected %d edges, got %+v", len(expected), graph.Edges)
+	}
+	for i, edge := range expected {
+		if graph.Edges[i] != edge {
+			t.Errorf("Expected edge %d to be %+v, got %+v", i, edge, graph.Edges[i])
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package analysis
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"universal_api/internal/diff"
+	"universal_api/internal/models"
+)
+
+// DefaultThreshold is the minimum similarity for two docs to be reported as duplicates
+const DefaultThreshold = 0.5
+
+// pathParamPattern matches {param} and :param path segments
+var pathParamPattern = regexp.MustCompile(`^(\{[^}]*\}|:.+)$`)
+
+// Duplicate describes two docs that document largely the same endpoints
+type Duplicate struct {
+	DocA            string             `json:"doc_a"`
+	DocB            string             `json:"doc_b"`
+	Similarity      float64            `json:"similarity"` // shared endpoints over the union of both docs' endpoints
+	SharedEndpoints []diff.EndpointRef `json:"shared_endpoints"`
+	Suggestion      MergeSuggestion    `json:"suggestion"`
+}
+
+// MergeSuggestion proposes which of two duplicate docs to keep
+type MergeSuggestion struct {
+	Keep   string `json:"keep"`
+	Remove string `json:"remove"`
+	// MissingEndpoints are documented only by the doc to remove and would be lost by removing it
+	MissingEndpoints []diff.EndpointRef `json:"missing_endpoints"`
+}
+
+// FindDuplicates compares every pair of docs and returns the pairs whose endpoint
+// similarity is at least threshold, most similar first. Endpoints match on method and
+// path, with path parameters matching regardless of their name.
+func FindDuplicates(docs []*models.APIDoc, threshold float64) []Duplicate {
+	signatures := make([]map[string]diff.EndpointRef, len(docs))
+	for i, doc := range docs {
+		signatures[i] = endpointSignatures(doc)
+	}
+
+	duplicates := []Duplicate{}
+	for i := 0; i < len(docs); i++ {
+		for j := i + 1; j < len(docs); j++ {
+			a, b := signatures[i], signatures[j]
+			if len(a) == 0 || len(b) == 0 {
+				continue
+			}
+
+			var shared []diff.EndpointRef
+			for signature, ref := range a {
+				if _, ok := b[signature]; ok {
+					shared = append(shared, ref)
+				}
+			}
+			similarity := float64(len(shared)) / float64(len(a)+len(b)-len(shared))
+			if len(shared) == 0 || similarity < threshold {
+				continue
+			}
+			sortRefs(shared)
+
+			duplicates = append(duplicates, Duplicate{
+				DocA:            docs[i].ID,
+				DocB:            docs[j].ID,
+				Similarity:      similarity,
+				SharedEndpoints: shared,
+				Suggestion:      suggestMerge(docs[i], docs[j], a, b),
+			})
+		}
+	}
+
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		return duplicates[i].Similarity > duplicates[j].Similarity
+	})
+	return duplicates
+}
+
+// suggestMerge keeps the doc with more endpoints, or the older doc when both have as many
+func suggestMerge(a, b *models.APIDoc, aSignatures, bSignatures map[string]diff.EndpointRef) MergeSuggestion {
+	keep, remove := a, b
+	keepSignatures, removeSignatures := aSignatures, bSignatures
+	if len(bSignatures) > len(aSignatures) ||
+		len(bSignatures) == len(aSignatures) && b.CreatedAt.Before(a.CreatedAt) {
+		keep, remove = b, a
+		keepSignatures, removeSignatures = bSignatures, aSignatures
+	}
+
+	missing := []diff.EndpointRef{}
+	for signature, ref := range removeSignatures {
+		if _, ok := keepSignatures[signature]; !ok {
+			missing = append(missing, ref)
+		}
+	}
+	sortRefs(missing)
+
+	return MergeSuggestion{Keep: keep.ID, Remove: remove.ID, MissingEndpoints: missing}
+}
+
+// endpointSignatures indexes a doc's endpoints by their normalized method and path
+func endpointSignatures(doc *models.APIDoc) map[string]diff.EndpointRef {
+	signatures := make(map[string]diff.EndpointRef)
+	for _, endpoint := range doc.Endpoints {
+		ref := diff.EndpointRef{ID: endpoint.StableID(), Method: endpoint.Method, Path: endpoint.Path}
+		signatures[strings.ToUpper(endpoint.Method)+" "+normalizePath(endpoint.Path)] = ref
+	}
+	return signatures
+}
+
+// normalizePath lowercases a path, drops trailing slashes, and replaces parameter segments with {}
+func normalizePath(path string) string {
+	segments := strings.Split(strings.Trim(strings.ToLower(path), "/"), "/")
+	for i, segment := range segments {
+		if pathParamPattern.MatchString(segment) {
+			segments[i] = "{}"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// sortRefs sorts endpoint refs by path, then method
+func sortRefs(refs []diff.EndpointRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Path != refs[j].Path {
+			return refs[i].Path < refs[j].Path
+		}
+		return refs[i].Method < refs[j].Method
+	})
+}
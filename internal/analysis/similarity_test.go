@@ -0,0 +1,54 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"universal_api/internal/models"
+)
+
+// TestFindDuplicates tests that docs sharing most endpoints are reported with a merge suggestion
+func TestFindDuplicates(t *testing.T) {
+	now := time.Now()
+	docs := []*models.APIDoc{
+		{
+			ID:        "vendor-a",
+			CreatedAt: now,
+			Endpoints: []models.Endpoint{
+				{Method: "GET", Path: "/users"},
+				{Method: "GET", Path: "/users/{id}"},
+			},
+		},
+		{
+			ID:        "vendor-b",
+			CreatedAt: now.Add(time.Hour),
+			Endpoints: []models.Endpoint{
+				{Method: "GET", Path: "/users/"},
+				{Method: "get", Path: "/users/:userId"},
+				{Method: "DELETE", Path: "/users/:userId"},
+			},
+		},
+		{
+			ID:        "unrelated",
+			Endpoints: []models.Endpoint{{Method: "GET", Path: "/orders"}},
+		},
+	}
+
+	duplicates := FindDuplicates(docs, DefaultThreshold)
+	if len(duplicates) != 1 {
+		t.Fatalf("Expected 1 duplicate pair, got %d", len(duplicates))
+	}
+
+	duplicate := duplicates[0]
+	if duplicate.DocA != "vendor-a" || duplicate.DocB != "vendor-b" {
+		t.Errorf("Expected vendor-a and vendor-b to be duplicates, got %s and %s", duplicate.DocA, duplicate.DocB)
+	}
+	if len(duplicate.SharedEndpoints) != 2 || duplicate.Similarity < 0.66 || duplicate.Similarity > 0.67 {
+		t.Errorf("Expected 2 of 3 endpoints shared, got %+v", duplicate)
+	}
+
+	suggestion := duplicate.Suggestion
+	if suggestion.Keep != "vendor-b" || suggestion.Remove != "vendor-a" || len(suggestion.MissingEndpoints) != 0 {
+		t.Errorf("Expected to keep the larger vendor-b doc, got %+v", suggestion)
+	}
+}
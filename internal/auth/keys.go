@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// keyPrefix starts every generated API key, making leaked keys easy to scan for
+const keyPrefix = "uapi_"
+
+// keyPrefixLength is the number of leading characters of a key kept for display
+const keyPrefixLength = 12
+
+// ErrUnauthenticated is returned for missing, unknown, or orphaned API keys
+var ErrUnauthenticated = errors.New("a valid API key is required")
+
+// HashKey returns the hash under which an API key is stored
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewAPIKey generates an API key for a user, returning the key and its stored record.
+// The key itself is never stored and can't be shown again.
+func NewAPIKey(userID, name string) (string, *models.APIKey, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+	key := keyPrefix + hex.EncodeToString(secret)
+
+	now := time.Now()
+	return key, &models.APIKey{
+		ID:        fmt.Sprintf("key-%d", now.UnixNano()),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    key[:keyPrefixLength],
+		Hash:      HashKey(key),
+		CreatedAt: now,
+	}, nil
+}
+
+// Enabled checks if authorization is enforced, which it is once users exist
+func Enabled(users storage.UserStorage) bool {
+	all, err := users.GetAllUsers()
+	return err != nil || len(all) > 0
+}
+
+// Authenticate returns the user an API key belongs to
+func Authenticate(users storage.UserStorage, key string) (*models.User, error) {
+	if key == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	apiKey, err := users.GetAPIKeyByHash(HashKey(key))
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+
+	user, err := users.GetUser(apiKey.UserID)
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+	return user, nil
+}
@@ -0,0 +1,439 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// jwksRefreshInterval limits how often signing keys are refetched for unknown key IDs
+const jwksRefreshInterval = time.Minute
+
+// clockSkew is the leeway allowed when checking token expiry
+const clockSkew = time.Minute
+
+// OIDCConfig configures login through an OpenID Connect provider such as Okta or Azure AD
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Audience     string // expected audience of API tokens, defaults to the client ID
+	UserClaim    string // claim naming the user, defaults to email
+	DefaultRole  string // role of unknown users signing in, who are rejected when empty
+	RedirectURL  string // UI login callback, defaults to /login/oidc/callback on the request's host
+}
+
+// OIDCProvider signs users in with an OpenID Connect provider and validates its JWTs
+type OIDCProvider struct {
+	config        OIDCConfig
+	authURL       string
+	tokenURL      string
+	jwksURL       string
+	client        *http.Client
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+	mutex         sync.Mutex
+}
+
+// discoveryDocument is the part of the provider's OpenID configuration that is used
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// jsonWebKey is an RSA or EC public key of a JWKS
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// NewOIDCProvider creates a provider from the issuer's discovery document
+func NewOIDCProvider(config OIDCConfig) (*OIDCProvider, error) {
+	if config.Issuer == "" || config.ClientID == "" {
+		return nil, errors.New("OIDC issuer and client ID are required")
+	}
+	if config.DefaultRole != "" && !ValidRole(config.DefaultRole) {
+		return nil, errors.New("unknown OIDC default role: " + config.DefaultRole)
+	}
+	if config.Audience == "" {
+		config.Audience = config.ClientID
+	}
+	if config.UserClaim == "" {
+		config.UserClaim = "email"
+	}
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+
+	p := &OIDCProvider{config: config, client: &http.Client{Timeout: 10 * time.Second}}
+
+	var discovery discoveryDocument
+	if err := p.getJSON(config.Issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider: %w", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != config.Issuer {
+		return nil, fmt.Errorf("OIDC discovery issuer %s doesn't match %s", discovery.Issuer, config.Issuer)
+	}
+	p.authURL = discovery.AuthorizationEndpoint
+	p.tokenURL = discovery.TokenEndpoint
+	p.jwksURL = discovery.JWKSURI
+
+	if err := p.refreshKeys(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// RedirectURL returns the configured UI login callback, empty to use the request's host
+func (p *OIDCProvider) RedirectURL() string {
+	return p.config.RedirectURL
+}
+
+// AuthCodeURL returns the provider URL to send a browser to for signing in
+func (p *OIDCProvider) AuthCodeURL(redirectURL, state, nonce string) string {
+	query := url.Values{
+		"response_type": {"code"},
+		"client_id":     {p.config.ClientID},
+		"redirect_uri":  {redirectURL},
+		"scope":         {"openid email profile"},
+		"state":         {state},
+		"nonce":         {nonce},
+	}
+
+	separator := "?"
+	if strings.Contains(p.authURL, "?") {
+		separator = "&"
+	}
+	return p.authURL + separator + query.Encode()
+}
+
+// Exchange redeems an authorization code, returning the ID token
+func (p *OIDCProvider) Exchange(redirectURL, code string) (string, error) {
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {redirectURL},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if token.Error != "" {
+		return "", fmt.Errorf("token exchange failed: %s %s", token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return "", errors.New("token response has no ID token")
+	}
+	return token.IDToken, nil
+}
+
+// Verify checks a JWT's signature, issuer, expiry, and audience, returning its claims.
+// ID tokens are issued to the client ID; API access tokens may carry the configured audience instead.
+func (p *OIDCProvider) Verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed JWT")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed JWT header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed JWT signature: %w", err)
+	}
+	key, err := p.key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed JWT claims: %w", err)
+	}
+
+	if iss, _ := claims["iss"].(string); strings.TrimSuffix(iss, "/") != p.config.Issuer {
+		return nil, errors.New("JWT issued by another issuer")
+	}
+	now := time.Now()
+	if exp, ok := claims["exp"].(float64); !ok || now.After(time.Unix(int64(exp), 0).Add(clockSkew)) {
+		return nil, errors.New("JWT expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(clockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return nil, errors.New("JWT not valid yet")
+	}
+	if !hasAudience(claims["aud"], p.config.ClientID) && (p.config.Audience == "" || !hasAudience(claims["aud"], p.config.Audience)) {
+		return nil, errors.New("JWT issued for another audience")
+	}
+
+	return claims, nil
+}
+
+// User returns the user named by a token's claims, creating it with the default role if unknown
+func (p *OIDCProvider) User(users storage.UserStorage, claims map[string]interface{}) (*models.User, error) {
+	id, _ := claims[p.config.UserClaim].(string)
+	if id == "" {
+		return nil, fmt.Errorf("JWT has no %s claim", p.config.UserClaim)
+	}
+
+	if user, err := users.GetUser(id); err == nil {
+		return user, nil
+	}
+	if p.config.DefaultRole == "" {
+		return nil, fmt.Errorf("no user %s", id)
+	}
+
+	// The first user must be an admin, so users are only created once an admin exists
+	if !Enabled(users) {
+		return nil, errors.New("no users exist yet, create an admin first")
+	}
+
+	name, _ := claims["name"].(string)
+	user := &models.User{ID: id, Name: name, Role: p.config.DefaultRole, CreatedAt: time.Now()}
+	if err := users.SaveUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// AuthenticateToken returns the user of a bearer token: a JWT from the OIDC provider, if
+// configured, or an API key
+func AuthenticateToken(users storage.UserStorage, provider *OIDCProvider, token string) (*models.User, error) {
+	if provider == nil || strings.Count(token, ".") != 2 {
+		return Authenticate(users, token)
+	}
+
+	claims, err := provider.Verify(token)
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+	user, err := provider.User(users, claims)
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+	return user, nil
+}
+
+// key returns the signing key with an ID, refetching the JWKS for unknown IDs to pick up rotated keys
+func (p *OIDCProvider) key(kid string) (crypto.PublicKey, error) {
+	p.mutex.Lock()
+	key, ok := p.lookupKey(kid)
+	stale := time.Since(p.keysFetchedAt) > jwksRefreshInterval
+	p.mutex.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	if stale {
+		if err := p.refreshKeys(); err != nil {
+			return nil, err
+		}
+		p.mutex.Lock()
+		key, ok = p.lookupKey(kid)
+		p.mutex.Unlock()
+		if ok {
+			return key, nil
+		}
+	}
+	return nil, errors.New("unknown JWT signing key: " + kid)
+}
+
+// lookupKey finds a signing key by ID; tokens without one match a provider's single key
+func (p *OIDCProvider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// refreshKeys fetches the provider's signing keys
+func (p *OIDCProvider) refreshKeys() error {
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(p.jwksURL, &jwks); err != nil {
+		return fmt.Errorf("failed to fetch OIDC signing keys: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+
+	p.mutex.Lock()
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	p.mutex.Unlock()
+	return nil
+}
+
+// getJSON fetches and decodes a JSON document
+func (p *OIDCProvider) getJSON(url string, v interface{}) error {
+	resp, err := p.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// publicKey converts a JWK to an RSA or ECDSA public key
+func (jwk jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, errors.New("unsupported curve: " + jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, errors.New("unsupported key type: " + jwk.Kty)
+	}
+}
+
+// verifySignature checks a JWT signature with an RS256/384/512 or ES256/384 key
+func verifySignature(alg string, key crypto.PublicKey, signed string, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512":
+		hash = crypto.SHA512
+	default:
+		return errors.New("unsupported JWT algorithm: " + alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return errors.New("JWT algorithm doesn't match its key")
+		}
+		if err := rsa.VerifyPKCS1v15(key, hash, digest, signature); err != nil {
+			return errors.New("invalid JWT signature")
+		}
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if !strings.HasPrefix(alg, "ES") || len(signature) != 2*size {
+			return errors.New("JWT algorithm doesn't match its key")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return errors.New("invalid JWT signature")
+		}
+	default:
+		return errors.New("unsupported JWT signing key")
+	}
+	return nil
+}
+
+// decodeSegment decodes a base64url JSON segment of a JWT
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// hasAudience checks if a JWT aud claim, a string or a list, contains an audience
+func hasAudience(aud interface{}, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
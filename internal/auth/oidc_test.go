@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// newTestProvider starts an OIDC provider serving discovery and an RSA signing key
+func newTestProvider(t *testing.T, config OIDCConfig) (*OIDCProvider, *rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	var issuer string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                 issuer,
+				"authorization_endpoint": issuer + "/authorize",
+				"token_endpoint":         issuer + "/token",
+				"jwks_uri":               issuer + "/keys",
+			})
+		case "/keys":
+			json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+				"kid": "k1",
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	issuer = server.URL
+
+	config.Issuer = issuer
+	provider, err := NewOIDCProvider(config)
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	return provider, key, issuer
+}
+
+// signToken creates an RS256 JWT with claims
+func signToken(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k1", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// TestOIDCVerify tests JWT signature and claim validation
+func TestOIDCVerify(t *testing.T) {
+	provider, key, issuer := newTestProvider(t, OIDCConfig{ClientID: "catalog", Audience: "api://catalog"})
+	exp := float64(time.Now().Add(time.Hour).Unix())
+
+	valid := map[string]interface{}{"iss": issuer, "aud": "catalog", "exp": exp, "email": "alice@example.com"}
+	if claims, err := provider.Verify(signToken(t, key, valid)); err != nil || claims["email"] != "alice@example.com" {
+		t.Errorf("Expected valid token to verify, got %v, %v", claims, err)
+	}
+
+	apiToken := map[string]interface{}{"iss": issuer, "aud": []interface{}{"api://catalog"}, "exp": exp}
+	if _, err := provider.Verify(signToken(t, key, apiToken)); err != nil {
+		t.Errorf("Expected token for the API audience to verify, got %v", err)
+	}
+
+	invalid := map[string]map[string]interface{}{
+		"expired":        {"iss": issuer, "aud": "catalog", "exp": float64(time.Now().Add(-time.Hour).Unix())},
+		"other audience": {"iss": issuer, "aud": "other", "exp": exp},
+		"other issuer":   {"iss": "https://evil.example.com", "aud": "catalog", "exp": exp},
+	}
+	for name, claims := range invalid {
+		if _, err := provider.Verify(signToken(t, key, claims)); err == nil {
+			t.Errorf("Expected %s token to be rejected", name)
+		}
+	}
+
+	// An empty aud claim mustn't match an empty audience
+	provider.config.Audience = ""
+	for name, aud := range map[string]interface{}{"empty audience": "", "empty audience list": []interface{}{""}} {
+		if _, err := provider.Verify(signToken(t, key, map[string]interface{}{"iss": issuer, "aud": aud, "exp": exp})); err == nil {
+			t.Errorf("Expected %s token to be rejected", name)
+		}
+	}
+
+	token := signToken(t, key, valid)
+	tampered := strings.Replace(token, strings.Split(token, ".")[1], base64.RawURLEncoding.EncodeToString([]byte(`{"email":"admin@example.com"}`)), 1)
+	if _, err := provider.Verify(tampered); err == nil {
+		t.Error("Expected tampered token to be rejected")
+	}
+}
+
+// TestAuthenticateToken tests signing users in with JWTs and API keys
+func TestAuthenticateToken(t *testing.T) {
+	provider, key, issuer := newTestProvider(t, OIDCConfig{ClientID: "catalog", DefaultRole: RoleViewer})
+	users := storage.NewMemoryStorage()
+	users.SaveUser(&models.User{ID: "admin", Role: RoleAdmin})
+	exp := float64(time.Now().Add(time.Hour).Unix())
+
+	token := signToken(t, key, map[string]interface{}{"iss": issuer, "aud": "catalog", "exp": exp, "email": "bob@example.com", "name": "Bob"})
+	user, err := AuthenticateToken(users, provider, token)
+	if err != nil || user.ID != "bob@example.com" || user.Role != RoleViewer {
+		t.Fatalf("Expected unknown user to be created as a viewer, got %+v, %v", user, err)
+	}
+
+	apiKey, record, _ := NewAPIKey("bob@example.com", "")
+	users.SaveAPIKey(record)
+	if user, err := AuthenticateToken(users, provider, apiKey); err != nil || user.ID != "bob@example.com" {
+		t.Errorf("Expected API keys to keep working, got %v, %v", user, err)
+	}
+
+	strict, key, issuer := newTestProvider(t, OIDCConfig{ClientID: "catalog"})
+	token = signToken(t, key, map[string]interface{}{"iss": issuer, "aud": "catalog", "exp": exp, "email": "eve@example.com"})
+	if _, err := AuthenticateToken(users, strict, token); err != ErrUnauthenticated {
+		t.Errorf("Expected unknown user to be rejected without a default role, got %v", err)
+	}
+}
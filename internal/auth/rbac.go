@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"universal_api/internal/models"
+)
+
+// Roles, from least to most privileged
+const (
+	RoleViewer   = "viewer"   // read and export docs
+	RoleEditor   = "editor"   // also scrape, refresh, import, and edit docs
+	RoleReviewer = "reviewer" // also approve or reject docs submitted for review
+	RoleAdmin    = "admin"    // also manage users, API keys, and workspaces
+)
+
+// Roles lists the roles, from least to most privileged
+var Roles = []string{RoleViewer, RoleEditor, RoleReviewer, RoleAdmin}
+
+// Permissions declared by routes
+const (
+	PermissionRead   = "read"
+	PermissionWrite  = "write"
+	PermissionReview = "review"
+	PermissionAdmin  = "admin"
+)
+
+// rolePermissions lists the permissions granted by each role
+var rolePermissions = map[string][]string{
+	RoleViewer:   {PermissionRead},
+	RoleEditor:   {PermissionRead, PermissionWrite},
+	RoleReviewer: {PermissionRead, PermissionWrite, PermissionReview},
+	RoleAdmin:    {PermissionRead, PermissionWrite, PermissionReview, PermissionAdmin},
+}
+
+// ValidRole checks if a role exists
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Allows checks if a role grants a permission
+func Allows(role, permission string) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAccessWorkspace checks if a user can access a workspace. Admins and users
+// without listed workspaces can access every workspace.
+func CanAccessWorkspace(user *models.User, workspace string) bool {
+	if user.Role == RoleAdmin || len(user.Workspaces) == 0 {
+		return true
+	}
+	for _, id := range user.Workspaces {
+		if id == workspace {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize checks if a user may use a permission in a workspace; an empty
+// workspace is for routes that aren't scoped to one
+func Authorize(user *models.User, permission, workspace string) bool {
+	if !Allows(user.Role, permission) {
+		return false
+	}
+	return workspace == "" || CanAccessWorkspace(user, workspace)
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"testing"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// TestAuthorize tests role permissions and workspace access
+func TestAuthorize(t *testing.T) {
+	viewer := &models.User{ID: "v", Role: RoleViewer, Workspaces: []string{"team-a"}}
+	editor := &models.User{ID: "e", Role: RoleEditor}
+	admin := &models.User{ID: "a", Role: RoleAdmin, Workspaces: []string{"team-a"}}
+
+	tests := []struct {
+		user       *models.User
+		permission string
+		workspace  string
+		expected   bool
+	}{
+		{viewer, PermissionRead, "team-a", true},
+		{viewer, PermissionRead, "team-b", false},
+		{viewer, PermissionWrite, "team-a", false},
+		{editor, PermissionWrite, "team-b", true},
+		{editor, PermissionAdmin, "", false},
+		{admin, PermissionAdmin, "", true},
+		{admin, PermissionWrite, "team-b", true},
+	}
+
+	for _, test := range tests {
+		if got := Authorize(test.user, test.permission, test.workspace); got != test.expected {
+			t.Errorf("Authorize(%s, %s, %q) = %v, expected %v", test.user.Role, test.permission, test.workspace, got, test.expected)
+		}
+	}
+}
+
+// TestAuthenticate tests resolving users from generated API keys
+func TestAuthenticate(t *testing.T) {
+	users := storage.NewMemoryStorage()
+	if Enabled(users) {
+		t.Fatal("Expected authorization to be disabled without users")
+	}
+
+	users.SaveUser(&models.User{ID: "alice", Role: RoleEditor})
+	key, apiKey, err := NewAPIKey("alice", "ci")
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	users.SaveAPIKey(apiKey)
+
+	if !Enabled(users) {
+		t.Error("Expected authorization to be enabled once users exist")
+	}
+	if apiKey.Hash == key || apiKey.Prefix != key[:keyPrefixLength] {
+		t.Errorf("Expected only a hash and prefix of the key to be stored, got %+v", apiKey)
+	}
+
+	user, err := Authenticate(users, key)
+	if err != nil || user.ID != "alice" {
+		t.Errorf("Expected key to authenticate alice, got %v, %v", user, err)
+	}
+	if _, err := Authenticate(users, key+"x"); err != ErrUnauthenticated {
+		t.Errorf("Expected unknown key to be rejected, got %v", err)
+	}
+
+	users.DeleteUser("alice")
+	if _, err := Authenticate(users, key); err != ErrUnauthenticated {
+		t.Errorf("Expected keys of deleted users to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,292 @@
+// Package cluster coordinates the instances of the service: which instance starts the scheduled syncs,
+// the shared queue of sync jobs every instance works through, and the settings they share
+package cluster
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"universal_api/internal/redis"
+)
+
+// redisKeyPrefix namespaces the locks, queues, and settings of the instances in a shared Redis
+const redisKeyPrefix = "universal_api:cluster:"
+
+// maxPollInterval is how often an instance checks the queue of a schedule for jobs at most
+const maxPollInterval = 5 * time.Second
+
+// instanceID identifies this instance in scrape records, stats, and response headers
+var instanceID = newInstanceID()
+
+// newInstanceID returns INSTANCE_ID, or the host name and process ID without it
+func newInstanceID() string {
+	if id := os.Getenv("INSTANCE_ID"); id != "" {
+		return id
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+	return host + "-" + strconv.Itoa(os.Getpid())
+}
+
+// InstanceID returns the ID of this instance
+func InstanceID() string {
+	return instanceID
+}
+
+// Coordinator coordinates scheduled work across the instances sharing it
+type Coordinator interface {
+	// TryLock takes the lock of a name for ttl, reporting false while another instance holds it
+	TryLock(name string, ttl time.Duration) (bool, error)
+	// Push adds jobs to the end of a queue
+	Push(queue string, jobs ...string) error
+	// Pop takes the job at the front of a queue, reporting false when the queue is empty
+	Pop(queue string) (string, bool, error)
+	// Get gets a setting shared by the instances, reporting false when it's not set
+	Get(key string) (string, bool, error)
+	// Set sets a setting shared by the instances
+	Set(key, value string) error
+}
+
+// ScheduleState is the progress of a schedule running on this instance
+type ScheduleState struct {
+	Name     string        `json:"name"`
+	Interval time.Duration `json:"-"`
+	LastTick time.Time     `json:"last_tick"` // when the instance last polled the schedule's queue
+}
+
+// Overdue checks if the schedule has missed polls, which happens when its jobs take longer than its
+// interval or it stopped
+func (s ScheduleState) Overdue(now time.Time) bool {
+	return now.Sub(s.LastTick) > s.Interval+pollInterval(s.Interval)
+}
+
+// schedules tracks the schedules running on this instance
+var schedules = struct {
+	sync.Mutex
+	states map[string]*ScheduleState
+}{states: make(map[string]*ScheduleState)}
+
+// Schedules returns the schedules running on this instance, ordered by name
+func Schedules() []ScheduleState {
+	schedules.Lock()
+	defer schedules.Unlock()
+
+	states := make([]ScheduleState, 0, len(schedules.states))
+	for _, state := range schedules.states {
+		states = append(states, *state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+// tick records a poll of a schedule
+func tick(name string, interval time.Duration) {
+	schedules.Lock()
+	defer schedules.Unlock()
+	schedules.states[name] = &ScheduleState{Name: name, Interval: interval, LastTick: time.Now()}
+}
+
+// untrack forgets a stopped schedule
+func untrack(name string) {
+	schedules.Lock()
+	defer schedules.Unlock()
+	delete(schedules.states, name)
+}
+
+// paused is set while the schedules of this instance are paused
+var paused atomic.Bool
+
+// SetPaused pauses or resumes the schedules of this instance. Paused schedules keep polling, so they're
+// not overdue, but neither queue nor run jobs, e.g. while storage is read-only for maintenance.
+func SetPaused(pause bool) {
+	paused.Store(pause)
+}
+
+// pollInterval returns how often the queue of a schedule is polled
+func pollInterval(interval time.Duration) time.Duration {
+	return min(interval, maxPollInterval)
+}
+
+// Schedule runs the jobs of a schedule every interval until stop is closed. Each interval, the instance
+// taking the schedule's lock queues the jobs listed by jobs, and every instance polls the queue and runs
+// the jobs it takes, so the jobs of one interval run once and are spread over the instances.
+func Schedule(coordinator Coordinator, name string, interval time.Duration, jobs func() []string, run func(job string) error, stop <-chan struct{}) {
+	ticker := time.NewTicker(pollInterval(interval))
+	defer ticker.Stop()
+	defer untrack(name)
+
+	for {
+		tick(name, interval)
+		if !paused.Load() {
+			runJobs(coordinator, name, interval, jobs, run)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// runJobs queues the jobs of a schedule if its interval is up and no other instance queued them, then
+// runs the queued jobs until the queue is empty
+func runJobs(coordinator Coordinator, name string, interval time.Duration, jobs func() []string, run func(job string) error) {
+	if locked, err := coordinator.TryLock("schedule:"+name, interval); err != nil {
+		log.Printf("Failed to lock the %s schedule: %v", name, err)
+	} else if locked {
+		if err := coordinator.Push(name, jobs()...); err != nil {
+			log.Printf("Failed to queue %s jobs: %v", name, err)
+		}
+	}
+
+	for {
+		job, ok, err := coordinator.Pop(name)
+		if err != nil {
+			log.Printf("Failed to take a %s job: %v", name, err)
+		}
+		if !ok {
+			break
+		}
+		if err := run(job); err != nil {
+			log.Printf("%s job %s failed: %v", name, job, err)
+		}
+	}
+}
+
+// Memory coordinates the schedules of a single instance
+type Memory struct {
+	mu       sync.Mutex
+	locks    map[string]time.Time // expiry of each lock
+	queues   map[string][]string
+	settings map[string]string
+}
+
+// NewMemory creates a coordinator without locks, jobs, or settings
+func NewMemory() *Memory {
+	return &Memory{locks: make(map[string]time.Time), queues: make(map[string][]string), settings: make(map[string]string)}
+}
+
+// TryLock takes the lock of a name for ttl unless it's held
+func (m *Memory) TryLock(name string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(m.locks[name]) {
+		return false, nil
+	}
+	m.locks[name] = now.Add(ttl)
+	return true, nil
+}
+
+// Push adds jobs to the end of a queue
+func (m *Memory) Push(queue string, jobs ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queues[queue] = append(m.queues[queue], jobs...)
+	return nil
+}
+
+// Pop takes the job at the front of a queue
+func (m *Memory) Pop(queue string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := m.queues[queue]
+	if len(jobs) == 0 {
+		return "", false, nil
+	}
+	m.queues[queue] = jobs[1:]
+	return jobs[0], true, nil
+}
+
+// Get gets a setting
+func (m *Memory) Get(key string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.settings[key]
+	return value, ok, nil
+}
+
+// Set sets a setting
+func (m *Memory) Set(key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.settings[key] = value
+	return nil
+}
+
+// Redis coordinates the schedules of the instances sharing a Redis server. Locks are keys set only
+// if absent, expiring after their TTL, queues are lists, and settings are keys without expiry.
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis creates a coordinator on the Redis server at a redis://[:password@]host:port[/db] URL
+func NewRedis(rawURL string) (*Redis, error) {
+	client, err := redis.Dial(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Redis{client: client}, nil
+}
+
+// TryLock takes the lock of a name for ttl unless another instance holds it
+func (r *Redis) TryLock(name string, ttl time.Duration) (bool, error) {
+	milliseconds := ttl.Milliseconds()
+	if milliseconds < 1 {
+		milliseconds = 1
+	}
+	reply, err := r.client.Do("SET", redisKeyPrefix+"lock:"+name, instanceID, "PX", strconv.FormatInt(milliseconds, 10), "NX")
+	return reply != nil, err
+}
+
+// Push adds jobs to the end of a queue
+func (r *Redis) Push(queue string, jobs ...string) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+	_, err := r.client.Do(append([]string{"RPUSH", redisKeyPrefix + "queue:" + queue}, jobs...)...)
+	return err
+}
+
+// Pop takes the job at the front of a queue
+func (r *Redis) Pop(queue string) (string, bool, error) {
+	reply, err := r.client.Do("LPOP", redisKeyPrefix+"queue:"+queue)
+	if err != nil || reply == nil {
+		return "", false, err
+	}
+	job, ok := reply.(string)
+	if !ok {
+		return "", false, fmt.Errorf("unexpected LPOP reply: %v", reply)
+	}
+	return job, true, nil
+}
+
+// Get gets a setting shared by the instances
+func (r *Redis) Get(key string) (string, bool, error) {
+	reply, err := r.client.Do("GET", redisKeyPrefix+"setting:"+key)
+	if err != nil || reply == nil {
+		return "", false, err
+	}
+	value, ok := reply.(string)
+	if !ok {
+		return "", false, fmt.Errorf("unexpected GET reply: %v", reply)
+	}
+	return value, true, nil
+}
+
+// Set sets a setting shared by the instances
+func (r *Redis) Set(key, value string) error {
+	_, err := r.client.Do("SET", redisKeyPrefix+"setting:"+key, value)
+	return err
+}
@@ -0,0 +1,159 @@
+package cluster
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"universal_api/internal/redis/redistest"
+)
+
+// TestCoordinators tests locks, queues, and settings of the memory and Redis coordinators
+func TestCoordinators(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go redistest.Serve(listener)
+
+	redisCoordinator, err := NewRedis("redis://" + listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to create Redis coordinator: %v", err)
+	}
+
+	for name, coordinator := range map[string]Coordinator{"memory": NewMemory(), "redis": redisCoordinator} {
+		t.Run(name, func(t *testing.T) {
+			if ok, err := coordinator.TryLock("sync", 50*time.Millisecond); !ok || err != nil {
+				t.Fatalf("Expected to take a free lock, got %v, %v", ok, err)
+			}
+			if ok, _ := coordinator.TryLock("sync", 50*time.Millisecond); ok {
+				t.Error("Expected a held lock to be refused")
+			}
+			time.Sleep(100 * time.Millisecond)
+			if ok, _ := coordinator.TryLock("sync", 50*time.Millisecond); !ok {
+				t.Error("Expected an expired lock to be taken again")
+			}
+
+			if err := coordinator.Push("jobs", "a", "b"); err != nil {
+				t.Fatalf("Failed to push jobs: %v", err)
+			}
+			for _, expected := range []string{"a", "b"} {
+				if job, ok, err := coordinator.Pop("jobs"); job != expected || !ok || err != nil {
+					t.Errorf("Expected job %s, got %q, %v, %v", expected, job, ok, err)
+				}
+			}
+			if _, ok, _ := coordinator.Pop("jobs"); ok {
+				t.Error("Expected the queue to be empty")
+			}
+
+			if _, ok, err := coordinator.Get("mode"); ok || err != nil {
+				t.Errorf("Expected an unset setting, got %v, %v", ok, err)
+			}
+			for _, value := range []string{`{"read_only": true}`, `{"read_only": false}`} {
+				if err := coordinator.Set("mode", value); err != nil {
+					t.Fatalf("Failed to set setting: %v", err)
+				}
+				if got, ok, err := coordinator.Get("mode"); got != value || !ok || err != nil {
+					t.Errorf("Expected setting %s, got %q, %v, %v", value, got, ok, err)
+				}
+			}
+		})
+	}
+}
+
+// TestSchedule tests that instances sharing a coordinator run each scheduled job once
+func TestSchedule(t *testing.T) {
+	coordinator := NewMemory()
+	stop := make(chan struct{})
+
+	var mu sync.Mutex
+	var ran []string
+	run := func(job string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		ran = append(ran, job)
+		return nil
+	}
+	jobs := func() []string { return []string{"a", "b", "c"} }
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Schedule(coordinator, "sync", time.Hour, jobs, run, stop)
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+
+	sort.Strings(ran)
+	if len(ran) != 3 || ran[0] != "a" || ran[1] != "b" || ran[2] != "c" {
+		t.Errorf("Expected each job to run once across instances, got %v", ran)
+	}
+}
+
+// TestScheduleStates tests tracking the polls of running schedules and telling overdue ones
+func TestScheduleStates(t *testing.T) {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		Schedule(NewMemory(), "check", time.Hour, func() []string { return nil }, func(string) error { return nil }, stop)
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	states := Schedules()
+	if len(states) != 1 || states[0].Name != "check" || time.Since(states[0].LastTick) > time.Second {
+		t.Fatalf("Expected the running schedule to have polled, got %+v", states)
+	}
+	if states[0].Overdue(time.Now()) {
+		t.Error("Expected a schedule that just polled not to be overdue")
+	}
+	if !states[0].Overdue(time.Now().Add(2 * time.Hour)) {
+		t.Error("Expected a schedule that missed its interval to be overdue")
+	}
+
+	close(stop)
+	<-done
+	if states := Schedules(); len(states) != 0 {
+		t.Errorf("Expected a stopped schedule to be forgotten, got %+v", states)
+	}
+}
+
+// TestSchedulePaused tests paused schedules keep polling without running jobs
+func TestSchedulePaused(t *testing.T) {
+	SetPaused(true)
+	defer SetPaused(false)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	var count int
+	var mu sync.Mutex
+	go func() {
+		Schedule(NewMemory(), "paused", time.Hour, func() []string { return []string{"a"} }, func(string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			count++
+			return nil
+		}, stop)
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	states := Schedules()
+	close(stop)
+	<-done
+	mu.Lock()
+	defer mu.Unlock()
+	if count != 0 {
+		t.Errorf("Expected a paused schedule not to run jobs, ran %d", count)
+	}
+	if len(states) != 1 || states[0].Overdue(time.Now()) {
+		t.Errorf("Expected a paused schedule to keep polling, got %+v", states)
+	}
+}
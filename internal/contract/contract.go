@@ -0,0 +1,148 @@
+// Package contract publishes JSON Schemas of the service's own payloads, generated from the models so
+// they can't drift from what's served, and checks responses against them.
+package contract
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"universal_api/internal/models"
+	"universal_api/internal/schema"
+)
+
+// Version is the version of the contract. Adding optional fields keeps it; removing or retyping fields
+// bumps it.
+const Version = "1"
+
+// JSONSchemaDialect is the JSON Schema version of the published schemas
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// types are the published payloads by name
+var types = map[string]reflect.Type{
+	"APIDoc":    reflect.TypeOf(models.APIDoc{}),
+	"Endpoint":  reflect.TypeOf(models.Endpoint{}),
+	"Parameter": reflect.TypeOf(models.Parameter{}),
+	"Response":  reflect.TypeOf(models.Response{}),
+}
+
+// Names returns the names of the published schemas
+func Names() []string {
+	return []string{"APIDoc", "Endpoint", "Parameter", "Response"}
+}
+
+// Schema returns the standalone JSON Schema of a payload, with nested types inlined, or false when
+// there's no payload of the name
+func Schema(name string) (map[string]interface{}, bool) {
+	t, ok := types[name]
+	if !ok {
+		return nil, false
+	}
+	s := typeSchema(t, map[reflect.Type]bool{})
+	s["$schema"] = JSONSchemaDialect
+	s["$id"] = "/api/v1/schemas/meta/" + name
+	s["title"] = name
+	s["x-contract-version"] = Version
+	return s, true
+}
+
+// Check validates a JSON response body against the schema of a payload, or of a list of them, and
+// returns a description of every mismatch
+func Check(name string, list bool, body []byte) ([]string, error) {
+	s, ok := Schema(name)
+	if !ok {
+		return nil, nil
+	}
+	if list {
+		s = map[string]interface{}{"type": []interface{}{"array", "null"}, "items": s}
+	}
+	schemaJSON, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil, err
+	}
+	return schema.Validate(string(schemaJSON), value)
+}
+
+// typeSchema returns the JSON Schema of the JSON encoding of a Go type. Types already being generated
+// further up, which only recursive types would meet, are left open.
+func typeSchema(t reflect.Type, generating map[reflect.Type]bool) map[string]interface{} {
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return nullable(typeSchema(t.Elem(), generating))
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return nullable(map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), generating)})
+	case reflect.Map:
+		return nullable(map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), generating)})
+	case reflect.Struct:
+		if generating[t] {
+			return map[string]interface{}{}
+		}
+		generating[t] = true
+		defer delete(generating, t)
+
+		properties := map[string]interface{}{}
+		required := []interface{}{}
+		addFields(t, properties, &required, generating)
+		s := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			s["required"] = required
+		}
+		return s
+	}
+	return map[string]interface{}{}
+}
+
+// addFields adds the JSON properties of a struct's fields, flattening embedded structs. Fields
+// without omitempty are always encoded, so they're required.
+func addFields(t reflect.Type, properties map[string]interface{}, required *[]interface{}, generating map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addFields(field.Type, properties, required, generating)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = typeSchema(field.Type, generating)
+		if !strings.Contains(","+options+",", ",omitempty,") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// nullable allows null besides the type of a schema, as nil pointers, slices, and maps encode to null
+func nullable(s map[string]interface{}) map[string]interface{} {
+	if t, ok := s["type"].(string); ok {
+		s["type"] = []interface{}{t, "null"}
+	}
+	return s
+}
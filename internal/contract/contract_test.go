@@ -0,0 +1,91 @@
+package contract
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"universal_api/internal/models"
+)
+
+// TestSchema tests generating schemas from the models' JSON tags
+func TestSchema(t *testing.T) {
+	for _, name := range Names() {
+		if _, ok := Schema(name); !ok {
+			t.Errorf("Expected a schema of %s", name)
+		}
+	}
+	if _, ok := Schema("User"); ok {
+		t.Error("Expected no schema of an unpublished model")
+	}
+
+	s, _ := Schema("APIDoc")
+	if s["$id"] != "/api/v1/schemas/meta/APIDoc" || s["x-contract-version"] != Version {
+		t.Errorf("Expected the schema to be identified and versioned, got %v and %v", s["$id"], s["x-contract-version"])
+	}
+	properties := s["properties"].(map[string]interface{})
+	if _, ok := properties["RawSource"]; ok {
+		t.Error("Expected fields hidden from JSON to be left out")
+	}
+	if _, ok := properties["raw_source"]; ok {
+		t.Error("Expected fields hidden from JSON to be left out")
+	}
+	created := properties["created_at"].(map[string]interface{})
+	if created["type"] != "string" || created["format"] != "date-time" {
+		t.Errorf("Expected times to be date-time strings, got %v", created)
+	}
+	owner := properties["owner"].(map[string]interface{})
+	if types, ok := owner["type"].([]interface{}); !ok || len(types) != 2 || types[1] != "null" {
+		t.Errorf("Expected pointers to be nullable, got %v", owner["type"])
+	}
+
+	required := map[string]bool{}
+	for _, name := range s["required"].([]interface{}) {
+		required[name.(string)] = true
+	}
+	if !required["id"] || !required["endpoints"] || required["workspace"] {
+		t.Errorf("Expected only fields without omitempty to be required, got %v", s["required"])
+	}
+
+	endpoints := properties["endpoints"].(map[string]interface{})
+	items := endpoints["items"].(map[string]interface{})
+	if _, ok := items["properties"].(map[string]interface{})["responses"]; !ok {
+		t.Error("Expected nested types to be inlined")
+	}
+}
+
+// TestCheck tests validating encoded payloads against their schemas
+func TestCheck(t *testing.T) {
+	doc := &models.APIDoc{
+		ID:        "pets",
+		Title:     "Pets",
+		Endpoints: []models.Endpoint{{Method: "GET", Path: "/pets", Responses: []models.Response{{StatusCode: 200}}}},
+		Owner:     &models.Owner{Team: "platform"},
+		CreatedAt: time.Now(),
+	}
+	body, _ := json.Marshal(doc)
+	if errs, err := Check("APIDoc", false, body); err != nil || len(errs) != 0 {
+		t.Errorf("Expected an encoded doc to match its schema, got %v, %v", errs, err)
+	}
+
+	list, _ := json.Marshal([]*models.APIDoc{doc})
+	if errs, err := Check("APIDoc", true, list); err != nil || len(errs) != 0 {
+		t.Errorf("Expected a list of docs to match, got %v, %v", errs, err)
+	}
+
+	errs, err := Check("APIDoc", false, []byte(`{"id": 1, "title": "Pets", "endpoints": [{"path": "/pets"}]}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	joined := strings.Join(errs, "\n")
+	for _, want := range []string{"$.id: expected string", "missing required property url", "$.endpoints[0]: missing required property method"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Expected a mismatch %q, got %v", want, errs)
+		}
+	}
+
+	if _, err := Check("APIDoc", false, []byte("not json")); err == nil {
+		t.Error("Expected an error for a body that isn't JSON")
+	}
+}
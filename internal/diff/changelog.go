@@ -0,0 +1,45 @@
+package diff
+
+import (
+	"time"
+
+	"universal_api/internal/models"
+)
+
+// ChangelogEntry describes the changes introduced by a single version of a doc
+type ChangelogEntry struct {
+	Version  int              `json:"version"`
+	Label    string           `json:"label"` // the doc's own version string at that point
+	Date     time.Time        `json:"date"`
+	Added    []EndpointRef    `json:"added"`
+	Removed  []EndpointRef    `json:"removed"`
+	Changed  []EndpointChange `json:"changed"`
+	Breaking []string         `json:"breaking"`
+}
+
+// Changelog builds a changelog from a doc's version history, newest version first
+func Changelog(versions []*models.APIDocVersion) []ChangelogEntry {
+	entries := make([]ChangelogEntry, 0, len(versions))
+
+	var previous *models.APIDoc
+	for _, version := range versions {
+		d := Compare(previous, version.Doc)
+		entries = append(entries, ChangelogEntry{
+			Version:  version.Number,
+			Label:    version.Doc.Version,
+			Date:     version.CreatedAt,
+			Added:    d.Added,
+			Removed:  d.Removed,
+			Changed:  d.Changed,
+			Breaking: d.BreakingChanges,
+		})
+		previous = version.Doc
+	}
+
+	// Reverse so the newest version comes first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	return entries
+}
@@ -0,0 +1,67 @@
+package diff
+
+import (
+	"testing"
+	"time"
+
+	"universal_api/internal/models"
+)
+
+// TestChangelog tests building a changelog from a version history, newest version first
+func TestChangelog(t *testing.T) {
+	created := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	versions := []*models.APIDocVersion{
+		{Number: 1, CreatedAt: created, Doc: &models.APIDoc{Version: "1.0", Endpoints: []models.Endpoint{
+			{Path: "/pets", Method: "GET"},
+			{Path: "/pets/{id}", Method: "DELETE"},
+		}}},
+		{Number: 2, CreatedAt: created.Add(time.Hour), Doc: &models.APIDoc{Version: "1.1", Endpoints: []models.Endpoint{
+			{Path: "/pets", Method: "GET"},
+			{Path: "/pets/{id}", Method: "DELETE"},
+			{Path: "/pets", Method: "POST"},
+		}}},
+		{Number: 3, CreatedAt: created.Add(2 * time.Hour), Doc: &models.APIDoc{Version: "2.0", Endpoints: []models.Endpoint{
+			{Path: "/pets", Method: "GET", Parameters: []models.Parameter{{Name: "owner", In: "query", Required: true}}},
+			{Path: "/pets", Method: "POST"},
+		}}},
+	}
+
+	entries := Changelog(versions)
+	if len(entries) != 3 {
+		t.Fatalf("Expected an entry per version, got %d", len(entries))
+	}
+	for i, expected := range []int{3, 2, 1} {
+		if entries[i].Version != expected {
+			t.Errorf("Expected entry %d to be version %d, got %d", i, expected, entries[i].Version)
+		}
+	}
+
+	latest := entries[0]
+	if latest.Label != "2.0" || !latest.Date.Equal(created.Add(2*time.Hour)) {
+		t.Errorf("Expected the latest entry to be labeled 2.0 and dated by its version, got %q, %v", latest.Label, latest.Date)
+	}
+	if len(latest.Removed) != 1 || latest.Removed[0].String() != "DELETE /pets/{id}" {
+		t.Errorf("Expected DELETE /pets/{id} to be removed in version 3, got %v", latest.Removed)
+	}
+	if len(latest.Changed) != 1 || latest.Changed[0].String() != "GET /pets" {
+		t.Errorf("Expected GET /pets to be changed in version 3, got %v", latest.Changed)
+	}
+	// The removed endpoint and the new required parameter break clients
+	if len(latest.Breaking) != 2 {
+		t.Errorf("Expected 2 breaking changes in version 3, got %v", latest.Breaking)
+	}
+
+	added := entries[1]
+	if len(added.Added) != 1 || added.Added[0].String() != "POST /pets" || len(added.Breaking) != 0 {
+		t.Errorf("Expected version 2 to add POST /pets without breaking changes, got %+v", added)
+	}
+
+	// The first version adds every endpoint
+	if first := entries[2]; len(first.Added) != 2 || len(first.Removed) != 0 || len(first.Breaking) != 0 {
+		t.Errorf("Expected version 1 to add both endpoints, got %+v", first)
+	}
+
+	if entries := Changelog(nil); entries == nil || len(entries) != 0 {
+		t.Errorf("Expected an empty changelog without versions, got %v", entries)
+	}
+}
@@ -0,0 +1,227 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+
+	"universal_api/internal/models"
+)
+
+// Diff describes the changes between two versions of an API doc
+type Diff struct {
+	Added           []EndpointRef    `json:"added"`
+	Removed         []EndpointRef    `json:"removed"`
+	Changed         []EndpointChange `json:"changed"`
+	BreakingChanges []string         `json:"breaking_changes"`
+}
+
+// EndpointRef identifies an endpoint by method and path, with its stable ID for permalinks
+type EndpointRef struct {
+	ID     string `json:"id"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+// EndpointChange describes the changes to a single endpoint
+type EndpointChange struct {
+	EndpointRef
+	Changes []string `json:"changes"`
+}
+
+// String returns the endpoint as "METHOD /path"
+func (r EndpointRef) String() string {
+	return r.Method + " " + r.Path
+}
+
+// HasChanges reports whether the diff contains any changes
+func (d *Diff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Changed) > 0
+}
+
+// IsBreaking reports whether the diff contains changes that can break existing consumers
+func (d *Diff) IsBreaking() bool {
+	return len(d.BreakingChanges) > 0
+}
+
+// Summary returns a one-line summary of the diff
+func (d *Diff) Summary() string {
+	return fmt.Sprintf("%d added, %d removed, %d changed endpoints (%d breaking changes)",
+		len(d.Added), len(d.Removed), len(d.Changed), len(d.BreakingChanges))
+}
+
+// Compare diffs two versions of an API doc. A nil old doc treats every endpoint as added.
+func Compare(old, new *models.APIDoc) *Diff {
+	d := &Diff{
+		Added:           []EndpointRef{},
+		Removed:         []EndpointRef{},
+		Changed:         []EndpointChange{},
+		BreakingChanges: []string{},
+	}
+
+	oldEndpoints := indexEndpoints(old)
+	newEndpoints := indexEndpoints(new)
+
+	for _, ref := range sortedRefs(newEndpoints) {
+		newEndpoint := newEndpoints[ref]
+		oldEndpoint, ok := oldEndpoints[ref]
+		if !ok {
+			d.Added = append(d.Added, ref)
+			continue
+		}
+
+		changes, breaking := compareEndpoints(oldEndpoint, newEndpoint)
+		if len(changes) > 0 {
+			d.Changed = append(d.Changed, EndpointChange{EndpointRef: ref, Changes: changes})
+		}
+		for _, change := range breaking {
+			d.BreakingChanges = append(d.BreakingChanges, ref.String()+": "+change)
+		}
+	}
+
+	for _, ref := range sortedRefs(oldEndpoints) {
+		if _, ok := newEndpoints[ref]; !ok {
+			d.Removed = append(d.Removed, ref)
+			d.BreakingChanges = append(d.BreakingChanges, ref.String()+": endpoint removed")
+		}
+	}
+
+	return d
+}
+
+// compareEndpoints returns all changes between two versions of an endpoint and the subset that is breaking
+func compareEndpoints(old, new models.Endpoint) ([]string, []string) {
+	var changes, breaking []string
+
+	if old.Summary != new.Summary {
+		changes = append(changes, "summary changed")
+	}
+	if old.Description != new.Description {
+		changes = append(changes, "description changed")
+	}
+
+	oldParams := indexParameters(old.Parameters)
+	newParams := indexParameters(new.Parameters)
+
+	for _, key := range sortedKeys(newParams) {
+		newParam := newParams[key]
+		oldParam, ok := oldParams[key]
+		if !ok {
+			change := fmt.Sprintf("parameter %s added", key)
+			changes = append(changes, change)
+			if newParam.Required {
+				breaking = append(breaking, fmt.Sprintf("required parameter %s added", key))
+			}
+			continue
+		}
+
+		if oldParam.Type != newParam.Type {
+			change := fmt.Sprintf("parameter %s type changed from %s to %s", key, oldParam.Type, newParam.Type)
+			changes = append(changes, change)
+			breaking = append(breaking, change)
+		}
+		if !oldParam.Required && newParam.Required {
+			change := fmt.Sprintf("parameter %s became required", key)
+			changes = append(changes, change)
+			breaking = append(breaking, change)
+		}
+		if oldParam.Required && !newParam.Required {
+			changes = append(changes, fmt.Sprintf("parameter %s became optional", key))
+		}
+	}
+
+	for _, key := range sortedKeys(oldParams) {
+		if _, ok := newParams[key]; !ok {
+			change := fmt.Sprintf("parameter %s removed", key)
+			changes = append(changes, change)
+			breaking = append(breaking, change)
+		}
+	}
+
+	oldResponses := indexResponses(old.Responses)
+	newResponses := indexResponses(new.Responses)
+
+	for _, code := range sortedCodes(newResponses) {
+		if _, ok := oldResponses[code]; !ok {
+			changes = append(changes, fmt.Sprintf("response %d added", code))
+		}
+	}
+	for _, code := range sortedCodes(oldResponses) {
+		if _, ok := newResponses[code]; !ok {
+			change := fmt.Sprintf("response %d removed", code)
+			changes = append(changes, change)
+			// Consumers rely on success responses; removing error responses is not breaking
+			if code >= 200 && code < 300 {
+				breaking = append(breaking, change)
+			}
+		}
+	}
+
+	return changes, breaking
+}
+
+// Helper functions
+
+// indexEndpoints maps a doc's endpoints by method and path
+func indexEndpoints(doc *models.APIDoc) map[EndpointRef]models.Endpoint {
+	index := make(map[EndpointRef]models.Endpoint)
+	if doc == nil {
+		return index
+	}
+	for _, endpoint := range doc.Endpoints {
+		index[EndpointRef{ID: endpoint.StableID(), Method: endpoint.Method, Path: endpoint.Path}] = endpoint
+	}
+	return index
+}
+
+// indexParameters maps parameters by location and name
+func indexParameters(params []models.Parameter) map[string]models.Parameter {
+	index := make(map[string]models.Parameter, len(params))
+	for _, param := range params {
+		index[param.In+":"+param.Name] = param
+	}
+	return index
+}
+
+// indexResponses maps responses by status code
+func indexResponses(responses []models.Response) map[int]models.Response {
+	index := make(map[int]models.Response, len(responses))
+	for _, response := range responses {
+		index[response.StatusCode] = response
+	}
+	return index
+}
+
+// sortedRefs returns the endpoint refs of an index sorted by path, then method
+func sortedRefs(index map[EndpointRef]models.Endpoint) []EndpointRef {
+	refs := make([]EndpointRef, 0, len(index))
+	for ref := range index {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Path != refs[j].Path {
+			return refs[i].Path < refs[j].Path
+		}
+		return refs[i].Method < refs[j].Method
+	})
+	return refs
+}
+
+// sortedKeys returns the keys of a parameter index in order
+func sortedKeys(index map[string]models.Parameter) []string {
+	keys := make([]string, 0, len(index))
+	for key := range index {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedCodes returns the status codes of a response index in order
+func sortedCodes(index map[int]models.Response) []int {
+	codes := make([]int, 0, len(index))
+	for code := range index {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	return codes
+}
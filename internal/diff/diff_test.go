@@ -0,0 +1,96 @@
+package diff
+
+import (
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestCompare tests diffing two versions of a doc
+func TestCompare(t *testing.T) {
+	old := &models.APIDoc{
+		Endpoints: []models.Endpoint{
+			{
+				Path:       "/users",
+				Method:     "GET",
+				Summary:    "List users",
+				Parameters: []models.Parameter{{Name: "limit", In: "query", Type: "integer"}},
+				Responses:  []models.Response{{StatusCode: 200}, {StatusCode: 400}},
+			},
+			{Path: "/users/{id}", Method: "DELETE"},
+		},
+	}
+
+	new := &models.APIDoc{
+		Endpoints: []models.Endpoint{
+			{
+				Path:    "/users",
+				Method:  "GET",
+				Summary: "List users",
+				Parameters: []models.Parameter{
+					{Name: "limit", In: "query", Type: "string"},
+					{Name: "org", In: "query", Type: "string", Required: true},
+				},
+				Responses: []models.Response{{StatusCode: 200}},
+			},
+			{Path: "/users", Method: "POST"},
+		},
+	}
+
+	d := Compare(old, new)
+
+	if len(d.Added) != 1 || d.Added[0].String() != "POST /users" {
+		t.Errorf("Expected POST /users to be added, got %v", d.Added)
+	}
+
+	if len(d.Removed) != 1 || d.Removed[0].String() != "DELETE /users/{id}" {
+		t.Errorf("Expected DELETE /users/{id} to be removed, got %v", d.Removed)
+	}
+
+	if len(d.Changed) != 1 || len(d.Changed[0].Changes) != 3 {
+		t.Fatalf("Expected 3 changes to GET /users, got %v", d.Changed)
+	}
+
+	// Type change, new required parameter, and removed endpoint; removing a 400 response is not breaking
+	if len(d.BreakingChanges) != 3 {
+		t.Errorf("Expected 3 breaking changes, got %v", d.BreakingChanges)
+	}
+
+	if !d.HasChanges() || !d.IsBreaking() {
+		t.Errorf("Expected diff to have breaking changes")
+	}
+}
+
+// TestCompareIdentical tests that identical docs produce an empty diff
+func TestCompareIdentical(t *testing.T) {
+	doc := &models.APIDoc{
+		Endpoints: []models.Endpoint{{Path: "/users", Method: "GET"}},
+	}
+
+	if d := Compare(doc, doc); d.HasChanges() {
+		t.Errorf("Expected no changes, got %s", d.Summary())
+	}
+
+	if d := Compare(nil, doc); len(d.Added) != 1 || d.IsBreaking() {
+		t.Errorf("Expected every endpoint to be added without breaking changes, got %s", d.Summary())
+	}
+}
+
+// TestCompareEndpointIDs tests that diffed endpoints carry their stable IDs, whether or not the docs have them set
+func TestCompareEndpointIDs(t *testing.T) {
+	old := &models.APIDoc{Endpoints: []models.Endpoint{{Path: "/users", Method: "GET"}}}
+	new := &models.APIDoc{Endpoints: []models.Endpoint{{Path: "/users", Method: "GET", Summary: "List users"}, {Path: "/users", Method: "POST"}}}
+	models.AssignEndpointIDs(new)
+
+	d := Compare(old, new)
+
+	if len(d.Changed) != 1 || d.Changed[0].ID != models.EndpointID("GET", "/users") {
+		t.Errorf("Expected GET /users to be changed with its stable ID, got %v", d.Changed)
+	}
+	if len(d.Added) != 1 || d.Added[0].ID != models.EndpointID("post", "/users") {
+		t.Errorf("Expected POST /users to be added with its stable ID, got %v", d.Added)
+	}
+	if models.EndpointID("GET", "/users") == models.EndpointID("POST", "/users") {
+		t.Errorf("Expected endpoints with different methods to have different IDs")
+	}
+}
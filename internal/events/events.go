@@ -0,0 +1,172 @@
+package events
+
+import (
+	"log"
+	"time"
+
+	"universal_api/internal/diff"
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// Doc lifecycle event types
+const (
+	DocCreated = "doc.created"
+	DocUpdated = "doc.updated"
+	DocDeleted = "doc.deleted"
+	DocChanged = "doc.changed"
+)
+
+// Event is a doc lifecycle event published to the bus
+type Event struct {
+	Type      string       `json:"type"`
+	DocID     string       `json:"doc_id"`
+	Title     string       `json:"title,omitempty"`
+	URL       string       `json:"url,omitempty"`
+	Timestamp time.Time    `json:"timestamp"`
+	Diff      *DiffSummary `json:"diff,omitempty"` // set for doc.changed
+}
+
+// DiffSummary summarizes the changes carried by a doc.changed event
+type DiffSummary struct {
+	Added    []string `json:"added"`
+	Removed  []string `json:"removed"`
+	Changed  []string `json:"changed"`
+	Breaking bool     `json:"breaking"`
+	Summary  string   `json:"summary"`
+}
+
+// Publisher delivers events to a message broker
+type Publisher interface {
+	Publish(event Event) error
+	Close() error
+}
+
+// Bus publishes events in the background so storage writes never wait on the broker
+type Bus struct {
+	publisher Publisher
+	queue     chan Event
+	done      chan struct{}
+}
+
+// NewBus creates a Bus and starts its delivery loop
+func NewBus(publisher Publisher) *Bus {
+	b := &Bus{
+		publisher: publisher,
+		queue:     make(chan Event, 1000),
+		done:      make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// Emit queues an event for publishing, dropping it if the queue is full
+func (b *Bus) Emit(event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	select {
+	case b.queue <- event:
+	default:
+		log.Printf("Event queue full, dropping %s event for %s", event.Type, event.DocID)
+	}
+}
+
+// Close stops accepting events, flushes the queue, and closes the publisher
+func (b *Bus) Close() error {
+	close(b.queue)
+	<-b.done
+	return b.publisher.Close()
+}
+
+// run delivers queued events until the queue is closed
+func (b *Bus) run() {
+	defer close(b.done)
+	for event := range b.queue {
+		if err := b.publisher.Publish(event); err != nil {
+			log.Printf("Failed to publish %s event for %s: %v", event.Type, event.DocID, err)
+		}
+	}
+}
+
+// PublishingStorage wraps a Storage and emits lifecycle events for every write
+type PublishingStorage struct {
+	storage.Storage
+	bus *Bus
+}
+
+// NewPublishingStorage creates a PublishingStorage
+func NewPublishingStorage(store storage.Storage, bus *Bus) *PublishingStorage {
+	return &PublishingStorage{Storage: store, bus: bus}
+}
+
+// SaveAPIDoc saves the doc and emits doc.created, or doc.updated plus doc.changed when endpoints changed
+func (s *PublishingStorage) SaveAPIDoc(doc *models.APIDoc) error {
+	existing, _ := s.Storage.GetAPIDoc(doc.ID)
+
+	// Diff before saving, since in-memory storage may hand out the same pointer
+	var changes *diff.Diff
+	if existing != nil && existing != doc {
+		changes = diff.Compare(existing, doc)
+	}
+
+	if err := s.Storage.SaveAPIDoc(doc); err != nil {
+		return err
+	}
+
+	if existing == nil {
+		s.bus.Emit(newEvent(DocCreated, doc))
+		return nil
+	}
+
+	s.bus.Emit(newEvent(DocUpdated, doc))
+	if changes != nil && changes.HasChanges() {
+		event := newEvent(DocChanged, doc)
+		event.Diff = summarize(changes)
+		s.bus.Emit(event)
+	}
+
+	return nil
+}
+
+// DeleteAPIDoc deletes the doc and emits doc.deleted
+func (s *PublishingStorage) DeleteAPIDoc(id string) error {
+	if err := s.Storage.DeleteAPIDoc(id); err != nil {
+		return err
+	}
+
+	s.bus.Emit(Event{Type: DocDeleted, DocID: id})
+	return nil
+}
+
+// newEvent creates an event for a doc
+func newEvent(eventType string, doc *models.APIDoc) Event {
+	return Event{
+		Type:  eventType,
+		DocID: doc.ID,
+		Title: doc.Title,
+		URL:   doc.URL,
+	}
+}
+
+// summarize converts a diff into its event summary
+func summarize(d *diff.Diff) *DiffSummary {
+	summary := &DiffSummary{
+		Added:    []string{},
+		Removed:  []string{},
+		Changed:  []string{},
+		Breaking: d.IsBreaking(),
+		Summary:  d.Summary(),
+	}
+	for _, ref := range d.Added {
+		summary.Added = append(summary.Added, ref.String())
+	}
+	for _, ref := range d.Removed {
+		summary.Removed = append(summary.Removed, ref.String())
+	}
+	for _, change := range d.Changed {
+		summary.Changed = append(summary.Changed, change.String())
+	}
+	return summary
+}
@@ -0,0 +1,54 @@
+package events
+
+import (
+	"sync"
+	"testing"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// recordingPublisher records published events
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (p *recordingPublisher) Publish(event Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *recordingPublisher) Close() error {
+	return nil
+}
+
+// TestPublishingStorage tests that storage writes emit lifecycle events
+func TestPublishingStorage(t *testing.T) {
+	publisher := &recordingPublisher{}
+	bus := NewBus(publisher)
+	store := NewPublishingStorage(storage.NewMemoryStorage(), bus)
+
+	store.SaveAPIDoc(&models.APIDoc{ID: "doc-1", Endpoints: []models.Endpoint{{Method: "GET", Path: "/a"}}})
+	store.SaveAPIDoc(&models.APIDoc{ID: "doc-1", Endpoints: []models.Endpoint{{Method: "GET", Path: "/b"}}})
+	store.DeleteAPIDoc("doc-1")
+	bus.Close()
+
+	expected := []string{DocCreated, DocUpdated, DocChanged, DocDeleted}
+	if len(publisher.events) != len(expected) {
+		t.Fatalf("Expected %d events, got %v", len(expected), publisher.events)
+	}
+
+	for i, eventType := range expected {
+		if publisher.events[i].Type != eventType {
+			t.Errorf("Expected event %d to be %s, got %s", i, eventType, publisher.events[i].Type)
+		}
+	}
+
+	changed := publisher.events[2].Diff
+	if changed == nil || !changed.Breaking || len(changed.Added) != 1 || len(changed.Removed) != 1 {
+		t.Errorf("Expected diff summary with one added and one removed endpoint, got %+v", changed)
+	}
+}
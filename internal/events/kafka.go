@@ -0,0 +1,64 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// KafkaPublisher publishes events to a Kafka topic through a Kafka REST Proxy (v2 API)
+type KafkaPublisher struct {
+	restURL string
+	topic   string
+	client  *http.Client
+}
+
+// kafkaRecords is the body of a REST Proxy produce request
+type kafkaRecords struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+// kafkaRecord is a single record of a produce request
+type kafkaRecord struct {
+	Key   string `json:"key"`
+	Value Event  `json:"value"`
+}
+
+// NewKafkaPublisher creates a KafkaPublisher
+func NewKafkaPublisher(restURL, topic string) *KafkaPublisher {
+	return &KafkaPublisher{
+		restURL: strings.TrimSuffix(restURL, "/"),
+		topic:   topic,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Publish implements the Publisher interface, keying records by doc ID to keep per-doc ordering
+func (p *KafkaPublisher) Publish(event Event) error {
+	body, err := json.Marshal(kafkaRecords{
+		Records: []kafkaRecord{{Key: event.DocID, Value: event}},
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Post(p.restURL+"/topics/"+p.topic, "application/vnd.kafka.json.v2+json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Kafka REST Proxy returned status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Close implements the Publisher interface
+func (p *KafkaPublisher) Close() error {
+	return nil
+}
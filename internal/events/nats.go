@@ -0,0 +1,158 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NATSPublisher publishes events to a NATS subject using the core NATS text protocol
+type NATSPublisher struct {
+	serverURL *url.URL
+	subject   string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// natsConnect is the CONNECT payload sent after the server INFO
+type natsConnect struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Name     string `json:"name"`
+	User     string `json:"user,omitempty"`
+	Pass     string `json:"pass,omitempty"`
+	Token    string `json:"auth_token,omitempty"`
+}
+
+// NewNATSPublisher creates a NATSPublisher for a nats://[user[:pass]@]host:port URL
+func NewNATSPublisher(serverURL, subject string) (*NATSPublisher, error) {
+	parsed, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS URL: %w", err)
+	}
+	if parsed.Host == "" {
+		return nil, errors.New("invalid NATS URL: missing host")
+	}
+
+	p := &NATSPublisher{serverURL: parsed, subject: subject}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.connect(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Publish implements the Publisher interface
+func (p *NATSPublisher) Publish(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Reconnect once if the connection dropped since the last publish
+	if err := p.write(payload); err != nil {
+		if p.conn != nil {
+			p.conn.Close()
+			p.conn = nil
+		}
+		if err := p.connect(); err != nil {
+			return err
+		}
+		return p.write(payload)
+	}
+
+	return nil
+}
+
+// Close implements the Publisher interface
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		return nil
+	}
+	return p.conn.Close()
+}
+
+// write sends a PUB frame; the caller must hold the lock
+func (p *NATSPublisher) write(payload []byte) error {
+	if p.conn == nil {
+		return errors.New("not connected")
+	}
+
+	p.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	_, err := fmt.Fprintf(p.conn, "PUB %s %d\r\n%s\r\n", p.subject, len(payload), payload)
+	return err
+}
+
+// connect dials the server and performs the INFO/CONNECT handshake; the caller must hold the lock
+func (p *NATSPublisher) connect() error {
+	conn, err := net.DialTimeout("tcp", p.serverURL.Host, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	info, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(info, "INFO") {
+		conn.Close()
+		return errors.New("failed to connect to NATS: missing server INFO")
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	connect := natsConnect{Name: "universal_api"}
+	if user := p.serverURL.User; user != nil {
+		if pass, ok := user.Password(); ok {
+			connect.User, connect.Pass = user.Username(), pass
+		} else {
+			connect.Token = user.Username()
+		}
+	}
+
+	payload, err := json.Marshal(connect)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\n", payload); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	p.conn = conn
+	go p.readLoop(conn, reader)
+	return nil
+}
+
+// readLoop answers server PINGs so the server keeps the connection open
+func (p *NATSPublisher) readLoop(conn net.Conn, reader *bufio.Reader) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		if strings.HasPrefix(line, "PING") {
+			p.mu.Lock()
+			if p.conn == conn {
+				fmt.Fprint(conn, "PONG\r\n")
+			}
+			p.mu.Unlock()
+		}
+	}
+}
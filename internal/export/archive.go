@@ -0,0 +1,258 @@
+package export
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// Format of .uapi archives
+const (
+	ArchiveFormat    = "uapi"
+	ArchiveVersion   = 1
+	ArchiveExtension = ".uapi"
+)
+
+// maxArchiveFileSize limits the size of a single file read from an archive
+const maxArchiveFileSize = 64 << 20
+
+// Manifest is the manifest.json of a .uapi archive, listing the files of each doc
+type Manifest struct {
+	Format     string          `json:"format"`
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Docs       []ManifestEntry `json:"docs"`
+}
+
+// ManifestEntry lists the files of a doc in an archive
+type ManifestEntry struct {
+	ID          string               `json:"id"`
+	Title       string               `json:"title"`
+	Doc         string               `json:"doc"`                // docs/<id>.json
+	Versions    string               `json:"versions,omitempty"` // versions/<id>.json, the version history oldest first
+	Attachments []ManifestAttachment `json:"attachments"`
+}
+
+// ManifestAttachment is an attachment of a doc in an archive, with the file of its content
+type ManifestAttachment struct {
+	models.Attachment
+	File string `json:"file"` // attachments/<doc id>/<attachment id>-<name>
+}
+
+// ArchivedDoc is a doc with its version history and attachments, as stored in an archive
+type ArchivedDoc struct {
+	Doc         *models.APIDoc
+	Versions    []*models.APIDocVersion
+	Attachments []ArchivedAttachment
+}
+
+// ArchivedAttachment is an attachment with its content
+type ArchivedAttachment struct {
+	Attachment *models.Attachment
+	Data       []byte
+}
+
+// ArchiveImportResult reports the docs restored from an archive
+type ArchiveImportResult struct {
+	Imported []string          `json:"imported"`
+	Failed   map[string]string `json:"failed"`
+}
+
+// WriteArchive writes docs as a .uapi archive: a zip of manifest.json, a JSON file per doc,
+// a JSON file per doc's version history, and the files attached to the docs
+func WriteArchive(w io.Writer, docs []ArchivedDoc) error {
+	docs = append([]ArchivedDoc(nil), docs...)
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Doc.ID < docs[j].Doc.ID })
+
+	archive := zip.NewWriter(w)
+	write := ZipWriter(archive)
+	manifest := Manifest{Format: ArchiveFormat, Version: ArchiveVersion, ExportedAt: time.Now(), Docs: []ManifestEntry{}}
+
+	for _, archived := range docs {
+		name := unsafePageChars.ReplaceAllString(archived.Doc.ID, "_")
+		entry := ManifestEntry{ID: archived.Doc.ID, Title: archived.Doc.Title, Doc: "docs/" + name + ".json", Attachments: []ManifestAttachment{}}
+		if err := writeJSON(write, entry.Doc, archived.Doc); err != nil {
+			return err
+		}
+		if len(archived.Versions) > 0 {
+			entry.Versions = "versions/" + name + ".json"
+			if err := writeJSON(write, entry.Versions, archived.Versions); err != nil {
+				return err
+			}
+		}
+		for _, attachment := range archived.Attachments {
+			file := "attachments/" + name + "/" + unsafePageChars.ReplaceAllString(attachment.Attachment.ID+"-"+attachment.Attachment.Name, "_")
+			if err := write(file, attachment.Data); err != nil {
+				return err
+			}
+			entry.Attachments = append(entry.Attachments, ManifestAttachment{Attachment: *attachment.Attachment, File: file})
+		}
+		manifest.Docs = append(manifest.Docs, entry)
+	}
+
+	if err := writeJSON(write, "manifest.json", manifest); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// writeJSON writes a value as an indented JSON file
+func writeJSON(write SiteWriter, name string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return write(name, data)
+}
+
+// ReadArchive reads the docs, version histories, and attachments of a .uapi archive
+func ReadArchive(r io.ReaderAt, size int64) ([]ArchivedDoc, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	var manifest Manifest
+	if err := readJSON(files, "manifest.json", &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Format != ArchiveFormat {
+		return nil, errors.New("invalid archive: not a .uapi archive")
+	}
+	if manifest.Version > ArchiveVersion {
+		return nil, fmt.Errorf("unsupported archive version %d", manifest.Version)
+	}
+
+	docs := make([]ArchivedDoc, 0, len(manifest.Docs))
+	for _, entry := range manifest.Docs {
+		var archived ArchivedDoc
+		if err := readJSON(files, entry.Doc, &archived.Doc); err != nil {
+			return nil, err
+		}
+		if archived.Doc == nil || archived.Doc.ID != entry.ID {
+			return nil, fmt.Errorf("invalid archive: %s is not the doc %s", entry.Doc, entry.ID)
+		}
+		if entry.Versions != "" {
+			if err := readJSON(files, entry.Versions, &archived.Versions); err != nil {
+				return nil, err
+			}
+		}
+		for _, listed := range entry.Attachments {
+			data, err := readFile(files, listed.File)
+			if err != nil {
+				return nil, err
+			}
+			if sum := sha256.Sum256(data); listed.SHA256 != "" && hex.EncodeToString(sum[:]) != listed.SHA256 {
+				return nil, fmt.Errorf("invalid archive: %s doesn't match its checksum", listed.File)
+			}
+			attachment := listed.Attachment
+			attachment.DocID = archived.Doc.ID
+			archived.Attachments = append(archived.Attachments, ArchivedAttachment{Attachment: &attachment, Data: data})
+		}
+		docs = append(docs, archived)
+	}
+	return docs, nil
+}
+
+// readFile reads a file of an archive
+func readFile(files map[string]*zip.File, name string) ([]byte, error) {
+	file, ok := files[name]
+	if !ok {
+		return nil, fmt.Errorf("invalid archive: missing %s", name)
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxArchiveFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %s: %w", name, err)
+	}
+	if len(data) > maxArchiveFileSize {
+		return nil, fmt.Errorf("invalid archive: %s is too large", name)
+	}
+	return data, nil
+}
+
+// readJSON decodes a JSON file of an archive
+func readJSON(files map[string]*zip.File, name string, value interface{}) error {
+	file, ok := files[name]
+	if !ok {
+		return fmt.Errorf("invalid archive: missing %s", name)
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if err := json.NewDecoder(io.LimitReader(reader, maxArchiveFileSize)).Decode(value); err != nil {
+		return fmt.Errorf("invalid archive: %s: %w", name, err)
+	}
+	return nil
+}
+
+// RestoreArchive saves the docs of an archive with their attachments and restores their version
+// histories. Docs and attachments that exist are overwritten, so importing the same archive twice
+// is harmless.
+func RestoreArchive(store storage.Storage, versions storage.VersionStorage, attachments storage.AttachmentStorage, docs []ArchivedDoc) *ArchiveImportResult {
+	result := &ArchiveImportResult{Imported: []string{}, Failed: make(map[string]string)}
+	for _, archived := range docs {
+		doc := archived.Doc
+		if err := store.SaveAPIDoc(doc); err != nil {
+			result.Failed[doc.ID] = err.Error()
+			continue
+		}
+
+		// Snapshots belong to the workspace the doc was imported into
+		history := make([]*models.APIDocVersion, 0, len(archived.Versions))
+		for _, version := range archived.Versions {
+			if version.Doc == nil {
+				continue
+			}
+			snapshot := *version.Doc
+			snapshot.Workspace = doc.Workspace
+			history = append(history, &models.APIDocVersion{Doc: &snapshot, CreatedAt: version.CreatedAt})
+		}
+		if err := versions.RestoreAPIDocVersions(doc.ID, history); err != nil {
+			result.Failed[doc.ID] = "failed to restore versions: " + err.Error()
+			continue
+		}
+		if err := restoreAttachments(attachments, doc.ID, archived.Attachments); err != nil {
+			result.Failed[doc.ID] = "failed to restore attachments: " + err.Error()
+			continue
+		}
+		result.Imported = append(result.Imported, doc.ID)
+	}
+	return result
+}
+
+// restoreAttachments saves the attachments of an archived doc, saved under docID, which differs from its
+// archived ID when it's imported into another workspace
+func restoreAttachments(attachments storage.AttachmentStorage, docID string, archived []ArchivedAttachment) error {
+	for _, attachment := range archived {
+		attachment.Attachment.DocID = docID
+		if existing, err := attachments.GetAttachment(attachment.Attachment.ID); err == nil && existing.DocID != attachment.Attachment.DocID {
+			return fmt.Errorf("attachment %s belongs to another doc", existing.ID)
+		}
+		if err := attachments.SaveAttachment(attachment.Attachment, attachment.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
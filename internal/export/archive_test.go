@@ -0,0 +1,76 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+	"time"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// TestArchiveRoundTrip tests exporting docs with their versions and importing them into another store
+func TestArchiveRoundTrip(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	v1 := &models.APIDoc{ID: "pets", Title: "Pets", Endpoints: []models.Endpoint{{Method: "GET", Path: "/pets"}}}
+	v2 := &models.APIDoc{ID: "pets", Title: "Pets", Endpoints: []models.Endpoint{{Method: "GET", Path: "/pets"}, {Method: "POST", Path: "/pets"}}}
+
+	var buf bytes.Buffer
+	err := WriteArchive(&buf, []ArchivedDoc{{
+		Doc: v2,
+		Versions: []*models.APIDocVersion{
+			{Number: 1, Doc: v1, CreatedAt: created},
+			{Number: 2, Doc: v2, CreatedAt: created.Add(time.Hour)},
+		},
+		Attachments: []ArchivedAttachment{{
+			Attachment: &models.Attachment{ID: "att-1", DocID: "pets", Kind: models.AttachmentKindFile, Name: "sla.pdf", ContentType: "application/pdf"},
+			Data:       []byte("%PDF-1.4"),
+		}},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+
+	docs, err := ReadArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to read archive: %v", err)
+	}
+	if len(docs) != 1 || len(docs[0].Versions) != 2 || len(docs[0].Doc.Endpoints) != 2 {
+		t.Fatalf("Unexpected archived docs: %+v", docs)
+	}
+
+	memory := storage.NewMemoryStorage()
+	target := storage.NewWorkspaceStorage(memory, "team")
+	result := RestoreArchive(target, memory, memory, docs)
+	if len(result.Imported) != 1 || len(result.Failed) != 0 {
+		t.Fatalf("Unexpected import result: %+v", result)
+	}
+
+	versions, err := target.GetAPIDocVersions("pets")
+	if err != nil {
+		t.Fatalf("Failed to get versions: %v", err)
+	}
+	if len(versions) != 2 || !versions[0].CreatedAt.Equal(created) || versions[1].Doc.Workspace != "team" {
+		t.Errorf("Expected the archived history in the importing workspace, got %+v", versions)
+	}
+
+	if data, err := memory.GetAttachmentData("att-1"); err != nil || string(data) != "%PDF-1.4" {
+		t.Errorf("Expected the attachment to be restored, got %q, %v", data, err)
+	}
+}
+
+// TestReadArchiveInvalid tests rejecting data that isn't a .uapi archive
+func TestReadArchiveInvalid(t *testing.T) {
+	if _, err := ReadArchive(bytes.NewReader([]byte("not a zip")), 9); err == nil {
+		t.Error("Expected an error for data that isn't a zip")
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	ZipWriter(archive)("manifest.json", []byte(`{"format": "other", "version": 1}`))
+	archive.Close()
+	if _, err := ReadArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err == nil {
+		t.Error("Expected an error for a zip with a foreign manifest")
+	}
+}
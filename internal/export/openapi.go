@@ -0,0 +1,431 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"universal_api/internal/models"
+)
+
+// Ways to keep the paths of merged docs apart
+const (
+	GroupByPrefix = "prefix" // prefix each doc's paths with its slug, e.g. /stripe/charges
+	GroupByTag    = "tag"    // keep paths and tag each doc's operations with its title
+)
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// componentRefPrefixes are the $ref prefixes of reusable schemas in OpenAPI 3 and Swagger 2 docs
+var componentRefPrefixes = []string{"#/components/schemas/", "#/definitions/"}
+
+// MergeOpenAPI merges docs into a single OpenAPI 3 document. Each doc's operations are tagged
+// with its title and its schemas are renamed with its slug, so docs sharing schema names don't
+// clash. Operations also record their source doc in an x-source extension, and the tags of docs
+// with a lifecycle stage record it in an x-lifecycle extension, and list operations their
+// pagination in an x-pagination extension. Streaming operations record their protocol and
+// messages in x-protocol and x-messages extensions. Webhooks, callbacks, and events aren't
+// paths of the API, so they're kept apart in an x-webhooks extension. Each doc's auth schemes
+// become security schemes required by its operations, summarized in an x-authentication tag
+// extension.
+func MergeOpenAPI(docs []*models.APIDoc, group string) map[string]interface{} {
+	paths := make(map[string]interface{})
+	webhooks := make(map[string]interface{})
+	schemas := make(map[string]interface{})
+	securitySchemes := make(map[string]interface{})
+	tags := make([]interface{}, 0, len(docs))
+	slugs := make(map[string]bool)
+
+	for _, doc := range docs {
+		slug := uniqueSlug(doc, slugs)
+		tag := map[string]interface{}{"name": doc.Title, "description": doc.Description}
+		if doc.Lifecycle != "" {
+			tag["x-lifecycle"] = doc.Lifecycle
+		}
+		if len(doc.AuthSchemes) > 0 {
+			summaries := make([]string, len(doc.AuthSchemes))
+			for i, scheme := range doc.AuthSchemes {
+				summaries[i] = scheme.Summary
+				securitySchemes[slug+"_"+scheme.Name] = securityScheme(scheme)
+			}
+			tag["x-authentication"] = summaries
+		}
+		tags = append(tags, tag)
+
+		for name, definition := range doc.Schemas {
+			var parsed interface{}
+			if err := json.Unmarshal([]byte(definition), &parsed); err == nil {
+				schemas[slug+"_"+name] = renameRefs(parsed, slug)
+			}
+		}
+
+		for _, endpoint := range doc.Endpoints {
+			method := strings.ToLower(endpoint.Method)
+			if endpoint.Incoming() {
+				addWebhook(webhooks, doc, endpoint, slug)
+				continue
+			}
+			path := endpoint.Path
+			if !strings.HasPrefix(path, "/") {
+				path = "/" + path
+			}
+
+			// Tag grouping keeps paths as they are, unless another doc already has the operation
+			item, _ := paths[path].(map[string]interface{})
+			if group != GroupByTag || item != nil && item[method] != nil {
+				path = "/" + slug + path
+				item, _ = paths[path].(map[string]interface{})
+			}
+			if item == nil {
+				item = make(map[string]interface{})
+				paths[path] = item
+			}
+			if item[method] != nil {
+				continue
+			}
+			item[method] = operation(doc, endpoint, slug)
+		}
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].(map[string]interface{})["name"].(string) < tags[j].(map[string]interface{})["name"].(string)
+	})
+
+	components := map[string]interface{}{"schemas": schemas}
+	if len(securitySchemes) > 0 {
+		components["securitySchemes"] = securitySchemes
+	}
+
+	merged := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "API Catalog",
+			"version":     "1.0.0",
+			"description": fmt.Sprintf("Merged from %d API docs", len(docs)),
+		},
+		"tags":       tags,
+		"paths":      paths,
+		"components": components,
+	}
+	if len(webhooks) > 0 {
+		merged["x-webhooks"] = webhooks
+	}
+	return merged
+}
+
+// addWebhook adds an endpoint the API calls, like a webhook, callback, or event, to the x-webhooks of
+// a merged document, named with the doc's slug and the endpoint's path and recording its kind in x-kind
+func addWebhook(webhooks map[string]interface{}, doc *models.APIDoc, endpoint models.Endpoint, slug string) {
+	name := slug + "_" + strings.TrimPrefix(endpoint.Path, "/")
+	item, _ := webhooks[name].(map[string]interface{})
+	if item == nil {
+		item = make(map[string]interface{})
+		webhooks[name] = item
+	}
+	method := strings.ToLower(endpoint.Method)
+	if item[method] != nil {
+		return
+	}
+	op := operation(doc, endpoint, slug)
+	op["x-kind"] = endpoint.Kind
+	item[method] = op
+}
+
+// securityScheme converts an auth scheme into an OpenAPI security scheme described by its summary
+func securityScheme(scheme models.AuthScheme) map[string]interface{} {
+	description := scheme.Summary
+	if scheme.Description != "" {
+		description += ". " + scheme.Description
+	}
+	result := map[string]interface{}{"type": scheme.Type, "description": description}
+
+	switch scheme.Type {
+	case "apiKey":
+		in := scheme.In
+		if in == "" {
+			in = "header"
+		}
+		result["in"] = in
+		result["name"] = scheme.Param
+	case "oauth2":
+		flows := make(map[string]interface{})
+		for _, flow := range scheme.Flows {
+			scopes := make(map[string]string)
+			for _, scope := range flow.Scopes {
+				scopes[scope] = ""
+			}
+			oauthFlow := map[string]interface{}{"scopes": scopes}
+			if flow.AuthorizationURL != "" {
+				oauthFlow["authorizationUrl"] = flow.AuthorizationURL
+			}
+			if flow.TokenURL != "" {
+				oauthFlow["tokenUrl"] = flow.TokenURL
+			}
+			if flow.RefreshURL != "" {
+				oauthFlow["refreshUrl"] = flow.RefreshURL
+			}
+			flows[flow.Flow] = oauthFlow
+		}
+		result["flows"] = flows
+	case "openIdConnect":
+		result["openIdConnectUrl"] = scheme.OpenIDConnectURL
+	case "mutualTLS":
+	default:
+		result["type"] = "http"
+		result["scheme"] = scheme.Type
+		if scheme.BearerFormat != "" {
+			result["bearerFormat"] = scheme.BearerFormat
+		}
+	}
+	return result
+}
+
+// operation converts an endpoint into an OpenAPI operation of a merged document
+func operation(doc *models.APIDoc, endpoint models.Endpoint, slug string) map[string]interface{} {
+	op := map[string]interface{}{
+		"tags":    []string{doc.Title},
+		"summary": endpoint.Summary,
+		"x-source": map[string]interface{}{
+			"doc_id":  doc.ID,
+			"title":   doc.Title,
+			"url":     doc.URL,
+			"path":    endpoint.Path,
+			"servers": doc.Servers,
+		},
+	}
+	if endpoint.Description != "" {
+		op["description"] = endpoint.Description
+	}
+	if endpoint.Deprecated || doc.Lifecycle == models.LifecycleDeprecated || doc.Lifecycle == models.LifecycleRetired {
+		op["deprecated"] = true
+	}
+	if endpoint.Pagination != nil {
+		op["x-pagination"] = endpoint.Pagination
+	}
+	if endpoint.Protocol != "" {
+		op["x-protocol"] = endpoint.Protocol
+	}
+	if len(endpoint.Messages) > 0 {
+		op["x-messages"] = endpoint.Messages
+	}
+	if endpoint.OperationID != "" {
+		op["operationId"] = slug + "_" + endpoint.OperationID
+	}
+	if len(doc.AuthSchemes) > 0 {
+		security := make([]interface{}, len(doc.AuthSchemes))
+		for i, scheme := range doc.AuthSchemes {
+			security[i] = map[string][]string{slug + "_" + scheme.Name: {}}
+		}
+		op["security"] = security
+	}
+
+	parameters := []interface{}{}
+	var bodyParams []models.Parameter
+	for _, param := range endpoint.Parameters {
+		if param.In == "body" {
+			bodyParams = append(bodyParams, param)
+			continue
+		}
+		parameter := map[string]interface{}{
+			"name":        param.Name,
+			"in":          param.In,
+			"required":    param.Required || param.In == "path",
+			"description": param.Description,
+			"schema":      map[string]interface{}{"type": parameterType(param.Type)},
+		}
+		if param.Example != "" {
+			parameter["example"] = param.Example
+		}
+		parameters = append(parameters, parameter)
+	}
+	if len(parameters) > 0 {
+		op["parameters"] = parameters
+	}
+	if len(bodyParams) > 0 || len(endpoint.RequestBodies) > 0 {
+		op["requestBody"] = requestBody(bodyParams, endpoint.RequestBodies)
+	}
+
+	responses := make(map[string]interface{})
+	for _, response := range endpoint.Responses {
+		code := "default"
+		if response.StatusCode != 0 {
+			code = strconv.Itoa(response.StatusCode)
+		}
+		description := response.Description
+		if description == "" {
+			description = code
+		}
+		entry := map[string]interface{}{"description": description}
+		if content := responseContent(response, slug); content != nil {
+			entry["content"] = content
+		}
+		responses[code] = entry
+	}
+	if len(responses) == 0 {
+		responses["default"] = map[string]interface{}{"description": "Response"}
+	}
+	op["responses"] = responses
+
+	return op
+}
+
+// requestBody converts an endpoint's body parameters and request bodies into a request body with
+// content in each of the endpoint's content types, or JSON when it has none. A request body's own
+// schema wins over the one described by the body parameters: a single parameter named body stands
+// for the whole body, and other body parameters are the fields of an object.
+func requestBody(params []models.Parameter, bodies []models.RequestBody) map[string]interface{} {
+	result := map[string]interface{}{}
+	var paramSchema map[string]interface{}
+	required := false
+	if len(params) == 1 && params[0].Name == "body" {
+		result["description"] = params[0].Description
+		required = params[0].Required
+		paramSchema = map[string]interface{}{"type": "object"}
+	} else if len(params) > 0 {
+		properties := make(map[string]interface{})
+		requiredFields := []string{}
+		for _, param := range params {
+			property := map[string]interface{}{"type": parameterType(param.Type)}
+			if param.Type == "file" {
+				property["format"] = "binary"
+			}
+			if param.Description != "" {
+				property["description"] = param.Description
+			}
+			if param.Example != "" {
+				property["example"] = param.Example
+			}
+			properties[param.Name] = property
+			if param.Required {
+				requiredFields = append(requiredFields, param.Name)
+			}
+		}
+		paramSchema = map[string]interface{}{"type": "object", "properties": properties}
+		if len(requiredFields) > 0 {
+			paramSchema["required"] = requiredFields
+		}
+		required = len(requiredFields) > 0
+	}
+
+	if len(bodies) == 0 {
+		bodies = []models.RequestBody{{ContentType: "application/json"}}
+	}
+	content := make(map[string]interface{}, len(bodies))
+	for _, body := range bodies {
+		media := make(map[string]interface{})
+		var schema interface{}
+		if json.Unmarshal([]byte(body.Schema), &schema) == nil {
+			media["schema"] = schema
+		} else if paramSchema != nil {
+			media["schema"] = paramSchema
+		}
+		if body.Example != "" {
+			var example interface{}
+			if strings.Contains(body.ContentType, "json") && json.Unmarshal([]byte(body.Example), &example) == nil {
+				media["example"] = example
+			} else {
+				media["example"] = body.Example
+			}
+		}
+		content[body.ContentType] = media
+		required = required || body.Required
+	}
+
+	result["required"] = required
+	result["content"] = content
+	return result
+}
+
+// responseContent returns the content of a response: its schema and example, or nil if it has neither
+func responseContent(response models.Response, slug string) map[string]interface{} {
+	media := make(map[string]interface{})
+	if schema := responseSchema(response, slug); schema != nil {
+		media["schema"] = schema
+	}
+	contentType := "application/json"
+	if response.Example != "" {
+		if response.ContentType != "" {
+			contentType = response.ContentType
+		}
+		var example interface{}
+		if err := json.Unmarshal([]byte(response.Example), &example); err == nil {
+			media["example"] = example
+		} else {
+			media["example"] = response.Example
+		}
+	}
+	if len(media) == 0 {
+		return nil
+	}
+	return map[string]interface{}{contentType: media}
+}
+
+// responseSchema returns the schema of a response with its refs renamed, or nil if it has none
+func responseSchema(response models.Response, slug string) interface{} {
+	if response.SchemaName != "" {
+		return map[string]interface{}{"$ref": componentRefPrefixes[0] + slug + "_" + response.SchemaName}
+	}
+	if response.Schema == "" {
+		return nil
+	}
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(response.Schema), &parsed); err != nil {
+		return nil
+	}
+	return renameRefs(parsed, slug)
+}
+
+// renameRefs points the schema $refs of a doc's JSON schema at the slug-prefixed merged schemas
+func renameRefs(node interface{}, slug string) interface{} {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				for _, prefix := range componentRefPrefixes {
+					if strings.HasPrefix(ref, prefix) {
+						value[key] = componentRefPrefixes[0] + slug + "_" + strings.TrimPrefix(ref, prefix)
+					}
+				}
+				continue
+			}
+			value[key] = renameRefs(child, slug)
+		}
+	case []interface{}:
+		for i, child := range value {
+			value[i] = renameRefs(child, slug)
+		}
+	}
+	return node
+}
+
+// parameterType maps a scraped parameter type to a JSON schema type, defaulting to string
+func parameterType(paramType string) string {
+	switch strings.ToLower(paramType) {
+	case "integer", "int", "int32", "int64":
+		return "integer"
+	case "number", "float", "double":
+		return "number"
+	case "boolean", "bool":
+		return "boolean"
+	case "array", "object":
+		return strings.ToLower(paramType)
+	}
+	return "string"
+}
+
+// uniqueSlug returns a slug of a doc's title, or of its ID for untitled docs, unused by the docs before it
+func uniqueSlug(doc *models.APIDoc, used map[string]bool) string {
+	slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(doc.Title), "-"), "-")
+	if slug == "" {
+		slug = strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(doc.ID), "-"), "-")
+	}
+	candidate := slug
+	for i := 2; used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%d", slug, i)
+	}
+	used[candidate] = true
+	return candidate
+}
@@ -0,0 +1,208 @@
+package export
+
+import (
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// mergeTestDocs returns two docs sharing a path and a schema name
+func mergeTestDocs() []*models.APIDoc {
+	return []*models.APIDoc{
+		{
+			ID: "a", Title: "Stripe API",
+			Schemas: map[string]string{
+				"Charge": `{"type": "object", "properties": {"customer": {"$ref": "#/components/schemas/Customer"}}}`,
+			},
+			Endpoints: []models.Endpoint{{
+				Method: "GET", Path: "/charges/{id}", OperationID: "getCharge",
+				Parameters: []models.Parameter{{Name: "id", In: "path", Type: "int"}},
+				Responses:  []models.Response{{StatusCode: 200, Description: "OK", SchemaName: "Charge"}},
+			}},
+		},
+		{
+			ID: "b", Title: "Billing",
+			Endpoints: []models.Endpoint{
+				{Method: "get", Path: "/charges/{id}"},
+				{Method: "post", Path: "/invoices", Parameters: []models.Parameter{{Name: "body", In: "body", Required: true}}},
+			},
+		},
+	}
+}
+
+// TestMergeOpenAPIPrefix tests merging docs under per-doc path prefixes with renamed schemas
+func TestMergeOpenAPIPrefix(t *testing.T) {
+	spec := MergeOpenAPI(mergeTestDocs(), GroupByPrefix)
+	paths := spec["paths"].(map[string]interface{})
+
+	for _, path := range []string{"/stripe-api/charges/{id}", "/billing/charges/{id}", "/billing/invoices"} {
+		if paths[path] == nil {
+			t.Errorf("Expected path %s, got %v", path, paths)
+		}
+	}
+
+	get := paths["/stripe-api/charges/{id}"].(map[string]interface{})["get"].(map[string]interface{})
+	if get["operationId"] != "stripe-api_getCharge" {
+		t.Errorf("Expected a prefixed operation ID, got %v", get["operationId"])
+	}
+	param := get["parameters"].([]interface{})[0].(map[string]interface{})
+	if param["required"] != true || param["schema"].(map[string]interface{})["type"] != "integer" {
+		t.Errorf("Expected a required integer path parameter, got %v", param)
+	}
+	content := get["responses"].(map[string]interface{})["200"].(map[string]interface{})["content"].(map[string]interface{})
+	if ref := content["application/json"].(map[string]interface{})["schema"].(map[string]interface{})["$ref"]; ref != "#/components/schemas/stripe-api_Charge" {
+		t.Errorf("Expected the response to reference the renamed schema, got %v", ref)
+	}
+
+	schemas := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	customer := schemas["stripe-api_Charge"].(map[string]interface{})["properties"].(map[string]interface{})["customer"].(map[string]interface{})
+	if customer["$ref"] != "#/components/schemas/stripe-api_Customer" {
+		t.Errorf("Expected nested refs to be renamed, got %v", customer["$ref"])
+	}
+
+	post := paths["/billing/invoices"].(map[string]interface{})["post"].(map[string]interface{})
+	if post["requestBody"] == nil || post["parameters"] != nil {
+		t.Errorf("Expected the body parameter to become a request body, got %v", post)
+	}
+}
+
+// TestMergeOpenAPITag tests keeping paths when grouping by tag, prefixing only clashing operations
+func TestMergeOpenAPITag(t *testing.T) {
+	spec := MergeOpenAPI(mergeTestDocs(), GroupByTag)
+	paths := spec["paths"].(map[string]interface{})
+
+	for _, path := range []string{"/charges/{id}", "/billing/charges/{id}", "/invoices"} {
+		if paths[path] == nil {
+			t.Errorf("Expected path %s, got %v", path, paths)
+		}
+	}
+
+	get := paths["/charges/{id}"].(map[string]interface{})["get"].(map[string]interface{})
+	if tags := get["tags"].([]string); len(tags) != 1 || tags[0] != "Stripe API" {
+		t.Errorf("Expected the operation to be tagged with its doc's title, got %v", tags)
+	}
+	if len(spec["tags"].([]interface{})) != 2 {
+		t.Errorf("Expected a tag per doc, got %v", spec["tags"])
+	}
+}
+
+// TestRequestBodyFields tests that body parameters inferred from fields become the properties of the request body
+func TestRequestBodyFields(t *testing.T) {
+	body := requestBody([]models.Parameter{
+		{Name: "amount", In: "body", Type: "integer", Required: true, Example: "100"},
+		{Name: "currency", In: "body", Type: "string"},
+	}, nil)
+
+	schema := body["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"].(map[string]interface{})
+	properties := schema["properties"].(map[string]interface{})
+	amount := properties["amount"].(map[string]interface{})
+	if amount["type"] != "integer" || amount["example"] != "100" || properties["currency"] == nil {
+		t.Errorf("Expected amount and currency properties, got %v", properties)
+	}
+	if required := schema["required"].([]string); len(required) != 1 || required[0] != "amount" || body["required"] != true {
+		t.Errorf("Expected amount to be required, got %v", schema["required"])
+	}
+}
+
+// TestRequestBodyContentTypes tests that request bodies are exported in each of their content types
+func TestRequestBodyContentTypes(t *testing.T) {
+	body := requestBody([]models.Parameter{{Name: "name", In: "body", Type: "string"}}, []models.RequestBody{
+		{ContentType: "application/json", Example: `{"name": "Rex"}`},
+		{ContentType: "application/x-www-form-urlencoded", Example: "name=Rex"},
+	})
+
+	content := body["content"].(map[string]interface{})
+	if len(content) != 2 {
+		t.Fatalf("Expected 2 content types, got %v", content)
+	}
+	json := content["application/json"].(map[string]interface{})
+	if example, ok := json["example"].(map[string]interface{}); !ok || example["name"] != "Rex" || json["schema"] == nil {
+		t.Errorf("Expected the JSON example and the schema of the body parameters, got %v", json)
+	}
+	if form := content["application/x-www-form-urlencoded"].(map[string]interface{}); form["example"] != "name=Rex" {
+		t.Errorf("Expected the form-encoded example, got %v", form)
+	}
+}
+
+// TestResponseContentExample tests that documented response examples are exported with their content type
+func TestResponseContentExample(t *testing.T) {
+	content := responseContent(models.Response{StatusCode: 200, Example: `{"id": 1}`, ContentType: "application/json"}, "a")
+	example := content["application/json"].(map[string]interface{})["example"].(map[string]interface{})
+	if example["id"] != float64(1) {
+		t.Errorf("Expected the JSON example, got %v", content)
+	}
+
+	content = responseContent(models.Response{StatusCode: 200, Example: "<id>1</id>", ContentType: "application/xml"}, "a")
+	if content["application/xml"].(map[string]interface{})["example"] != "<id>1</id>" {
+		t.Errorf("Expected the XML example as text, got %v", content)
+	}
+
+	if content := responseContent(models.Response{StatusCode: 204}, "a"); content != nil {
+		t.Errorf("Expected no content, got %v", content)
+	}
+}
+
+// TestMergeOpenAPILifecycle tests recording the lifecycle of docs and deprecating the operations of deprecated docs
+func TestMergeOpenAPILifecycle(t *testing.T) {
+	docs := mergeTestDocs()
+	docs[1].Lifecycle = models.LifecycleDeprecated
+	spec := MergeOpenAPI(docs, GroupByPrefix)
+
+	for _, tag := range spec["tags"].([]interface{}) {
+		tag := tag.(map[string]interface{})
+		if tag["name"] == "Billing" && tag["x-lifecycle"] != models.LifecycleDeprecated {
+			t.Errorf("Expected the Billing tag to record its lifecycle, got %v", tag)
+		}
+		if tag["name"] == "Stripe API" && tag["x-lifecycle"] != nil {
+			t.Errorf("Expected no lifecycle on the Stripe API tag, got %v", tag)
+		}
+	}
+
+	paths := spec["paths"].(map[string]interface{})
+	if post := paths["/billing/invoices"].(map[string]interface{})["post"].(map[string]interface{}); post["deprecated"] != true {
+		t.Errorf("Expected the operations of a deprecated doc to be deprecated, got %v", post)
+	}
+	if get := paths["/stripe-api/charges/{id}"].(map[string]interface{})["get"].(map[string]interface{}); get["deprecated"] != nil {
+		t.Errorf("Expected the operations of other docs not to be deprecated, got %v", get)
+	}
+}
+
+// TestMergeOpenAPIAuthSchemes tests exporting the auth schemes of docs as security schemes of their operations
+func TestMergeOpenAPIAuthSchemes(t *testing.T) {
+	docs := mergeTestDocs()
+	docs[1].AuthSchemes = []models.AuthScheme{
+		{Name: "apiKey", Type: "apiKey", In: "header", Param: "X-Api-Key", Summary: "Send an API key in the X-Api-Key header"},
+	}
+	spec := MergeOpenAPI(docs, GroupByPrefix)
+
+	schemes := spec["components"].(map[string]interface{})["securitySchemes"].(map[string]interface{})
+	scheme, ok := schemes["billing_apiKey"].(map[string]interface{})
+	if !ok || scheme["in"] != "header" || scheme["name"] != "X-Api-Key" {
+		t.Fatalf("Expected the billing_apiKey security scheme, got %v", schemes)
+	}
+
+	paths := spec["paths"].(map[string]interface{})
+	post := paths["/billing/invoices"].(map[string]interface{})["post"].(map[string]interface{})
+	if security, ok := post["security"].([]interface{}); !ok || len(security) != 1 {
+		t.Errorf("Expected the operation to require the doc's security scheme, got %v", post["security"])
+	}
+	if get := paths["/stripe-api/charges/{id}"].(map[string]interface{})["get"].(map[string]interface{}); get["security"] != nil {
+		t.Errorf("Expected no security on the operations of docs without auth schemes, got %v", get["security"])
+	}
+}
+
+// TestMergeOpenAPIWebhooks tests keeping the webhooks of docs apart from their paths
+func TestMergeOpenAPIWebhooks(t *testing.T) {
+	docs := mergeTestDocs()
+	docs[1].Endpoints = append(docs[1].Endpoints, models.Endpoint{Kind: models.EndpointWebhook, Method: "POST", Path: "invoicePaid"})
+	spec := MergeOpenAPI(docs, GroupByPrefix)
+
+	if _, ok := spec["paths"].(map[string]interface{})["/billing/invoicePaid"]; ok {
+		t.Errorf("Expected the webhook not to be a path")
+	}
+	webhooks, _ := spec["x-webhooks"].(map[string]interface{})
+	item, _ := webhooks["billing_invoicePaid"].(map[string]interface{})
+	if post, _ := item["post"].(map[string]interface{}); post["x-kind"] != models.EndpointWebhook {
+		t.Errorf("Expected the webhook in x-webhooks, got %v", webhooks)
+	}
+}
@@ -0,0 +1,347 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"universal_api/internal/models"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// A4 pages, in points
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 50
+)
+
+// Fonts of PDF exports: the standard fonts every PDF reader has, so none are embedded
+const (
+	pdfRegular = "F1" // Helvetica
+	pdfBold    = "F2" // Helvetica-Bold
+	pdfMono    = "F3" // Courier
+)
+
+// maxPDFSchemaLines limits the lines of each schema in PDF exports, so one huge schema doesn't take over the reference
+const maxPDFSchemaLines = 40
+
+// helveticaWidths are the widths of the printable ASCII characters in Helvetica, in thousandths of the font size
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 to ?
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ to O
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P to _
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` to o
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p to ~
+}
+
+// PDFName returns the file name of a doc's PDF export, from its title
+func PDFName(doc *models.APIDoc) string {
+	return uniqueSlug(doc, map[string]bool{}) + ".pdf"
+}
+
+// WritePDF writes a doc as a printable PDF reference: its overview, servers, authentication, and every
+// endpoint with its parameters, request bodies, and responses. Descriptions are printed as their
+// markdown source, and characters outside Windows-1252 are replaced, as the standard fonts lack them.
+func WritePDF(w io.Writer, doc *models.APIDoc) error {
+	p := &pdfLayout{}
+	p.newPage()
+
+	p.text(pdfBold, 20, doc.Title, 0)
+	var meta []string
+	if doc.Version != "" {
+		meta = append(meta, "Version "+doc.Version)
+	}
+	if doc.Provider != "" {
+		meta = append(meta, doc.Provider)
+	}
+	if doc.Lifecycle != "" {
+		meta = append(meta, strings.ToUpper(doc.Lifecycle[:1])+doc.Lifecycle[1:])
+	}
+	if len(meta) > 0 {
+		p.text(pdfRegular, 10, strings.Join(meta, " | "), 0)
+	}
+	if doc.URL != "" {
+		p.text(pdfRegular, 9, doc.URL, 0)
+	}
+	p.space(6)
+	p.paragraphs(doc.Description, 0)
+
+	if len(doc.Servers) > 0 {
+		p.heading("Servers")
+		for _, server := range doc.Servers {
+			p.text(pdfMono, 9, server, 0)
+		}
+	}
+
+	if len(doc.AuthSchemes) > 0 {
+		p.heading("Authentication")
+		for _, scheme := range doc.AuthSchemes {
+			summary := scheme.Summary
+			if summary == "" {
+				summary = scheme.Type
+			}
+			p.text(pdfRegular, 10, scheme.Name+": "+summary, 0)
+		}
+	}
+
+	p.heading(fmt.Sprintf("Endpoints (%d)", len(doc.Endpoints)))
+	for _, endpoint := range doc.Endpoints {
+		p.endpoint(endpoint)
+	}
+
+	return p.write(w, doc.Title)
+}
+
+// endpoint lays out an endpoint, starting it on a new page unless its heading and first lines fit
+func (p *pdfLayout) endpoint(endpoint models.Endpoint) {
+	if p.y-80 < pdfMargin {
+		p.newPage()
+	}
+	p.space(8)
+	title := strings.ToUpper(endpoint.Method) + " " + endpoint.Path
+	if endpoint.Incoming() {
+		title += " (" + endpoint.EndpointKind() + ")"
+	}
+	if endpoint.Deprecated {
+		title += " - deprecated"
+	}
+	p.text(pdfBold, 12, title, 0)
+	if endpoint.Summary != "" {
+		p.text(pdfRegular, 10, endpoint.Summary, 0)
+	}
+	p.paragraphs(endpoint.Description, 0)
+
+	if len(endpoint.Parameters) > 0 {
+		p.subheading("Parameters")
+		for _, param := range endpoint.Parameters {
+			details := []string{param.In}
+			if param.Type != "" {
+				details = append(details, param.Type)
+			}
+			if param.Required {
+				details = append(details, "required")
+			}
+			line := param.Name + " (" + strings.Join(details, ", ") + ")"
+			if param.Description != "" {
+				line += ": " + param.Description
+			}
+			p.text(pdfRegular, 9, line, 10)
+		}
+	}
+
+	for _, body := range endpoint.RequestBodies {
+		label := "Request body: " + body.ContentType
+		if body.Required {
+			label += " (required)"
+		}
+		p.subheading(label)
+		p.code(body.Schema, 10)
+	}
+
+	if len(endpoint.Responses) > 0 {
+		p.subheading("Responses")
+		for _, response := range endpoint.Responses {
+			line := strconv.Itoa(response.StatusCode)
+			if response.Description != "" {
+				line += ": " + response.Description
+			}
+			if response.SchemaName != "" {
+				line += " (" + response.SchemaName + ")"
+			}
+			p.text(pdfRegular, 9, line, 10)
+		}
+	}
+}
+
+// pdfLayout lays out text top to bottom onto pages, breaking lines and pages as needed
+type pdfLayout struct {
+	pages []*bytes.Buffer // content stream of each page
+	y     float64         // baseline of the next line on the current page
+}
+
+// newPage starts a new page
+func (p *pdfLayout) newPage() {
+	p.pages = append(p.pages, &bytes.Buffer{})
+	p.y = pdfPageHeight - pdfMargin
+}
+
+// space adds vertical space
+func (p *pdfLayout) space(points float64) {
+	p.y -= points
+}
+
+// heading lays out a section heading
+func (p *pdfLayout) heading(text string) {
+	if p.y-60 < pdfMargin {
+		p.newPage()
+	}
+	p.space(10)
+	p.text(pdfBold, 14, text, 0)
+	p.space(2)
+}
+
+// subheading lays out the heading of a part of an endpoint
+func (p *pdfLayout) subheading(text string) {
+	p.space(3)
+	p.text(pdfBold, 9, text, 0)
+}
+
+// paragraphs lays out text as paragraphs separated by blank lines, joining the lines within each
+func (p *pdfLayout) paragraphs(text string, indent float64) {
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		if paragraph = strings.Join(strings.Fields(paragraph), " "); paragraph != "" {
+			p.text(pdfRegular, 10, paragraph, indent)
+			p.space(4)
+		}
+	}
+}
+
+// code lays out text in a monospaced font, keeping its lines and cutting it off after maxPDFSchemaLines
+func (p *pdfLayout) code(text string, indent float64) {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if len(lines) > maxPDFSchemaLines {
+		lines = append(lines[:maxPDFSchemaLines], fmt.Sprintf("... %d more lines", len(lines)-maxPDFSchemaLines))
+	}
+	for _, line := range lines {
+		if line != "" {
+			p.text(pdfMono, 8, line, indent)
+		}
+	}
+}
+
+// text lays out text, wrapped at word boundaries to the width of the page, moving on to a new page at the bottom margin
+func (p *pdfLayout) text(font string, size float64, text string, indent float64) {
+	leading := size * 1.3
+	for _, line := range wrap(font, size, text, pdfPageWidth-2*pdfMargin-indent) {
+		if p.y-leading < pdfMargin {
+			p.newPage()
+		}
+		p.y -= leading
+		fmt.Fprintf(p.pages[len(p.pages)-1], "BT /%s %g Tf %.2f %.2f Td (%s) Tj ET\n", font, size, pdfMargin+indent, p.y, pdfString(line))
+	}
+}
+
+// write writes the laid out pages as a PDF file, numbering them in their footers
+func (p *pdfLayout) write(w io.Writer, title string) error {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 to 5 are the catalog, the page tree, and the fonts, followed by each page and its contents
+	const firstPage = 6
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	for i, content := range p.pages {
+		footer := fmt.Sprintf("%s - page %d of %d", title, i+1, len(p.pages))
+		fmt.Fprintf(content, "BT /%s 8 Tf %d %d Td (%s) Tj ET\n", pdfRegular, pdfMargin, pdfMargin/2, pdfString(footer))
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Contents %d 0 R "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R /F3 5 0 R >> >> >>", pdfPageWidth, pdfPageHeight, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+	object(fmt.Sprintf("<< /Title (%s) /Producer (Universal API) >>", pdfString(title)))
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, len(offsets), xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// wrap breaks text into lines no wider than width, breaking words longer than a line
+func wrap(font string, size float64, text string, width float64) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if textWidth(font, size, candidate) <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		// Break words wider than a whole line, such as long URLs
+		runes := []rune(word)
+		for textWidth(font, size, string(runes)) > width {
+			cut := len(runes) - 1
+			for cut > 1 && textWidth(font, size, string(runes[:cut])) > width {
+				cut--
+			}
+			lines = append(lines, string(runes[:cut]))
+			runes = runes[cut:]
+		}
+		line = string(runes)
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// textWidth estimates the width of text in a font, in points. Bold text is estimated slightly wide,
+// as bold Helvetica is wider than regular.
+func textWidth(font string, size float64, text string) float64 {
+	total := 0
+	for _, r := range text {
+		switch {
+		case font == pdfMono:
+			total += 600
+		case r >= ' ' && r <= '~':
+			total += helveticaWidths[r-' ']
+		default:
+			total += 556
+		}
+	}
+	width := float64(total) * size / 1000
+	if font == pdfBold {
+		width *= 1.08
+	}
+	return width
+}
+
+// pdfString encodes text as the contents of a PDF string literal in Windows-1252, replacing
+// characters it lacks with question marks
+func pdfString(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch r {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n', '\r', '\t':
+			b.WriteByte(' ')
+		default:
+			if c, ok := charmap.Windows1252.EncodeRune(r); ok {
+				b.WriteByte(c)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,72 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestWritePDF tests that a long doc is laid out over several pages of a well-formed PDF
+func TestWritePDF(t *testing.T) {
+	doc := &models.APIDoc{ID: "pets", Title: "Pets (v2)", Version: "2.0", Description: "Manage pets.\n\nCafé owners welcome ✓"}
+	for i := 0; i < 60; i++ {
+		doc.Endpoints = append(doc.Endpoints, models.Endpoint{
+			Method:     "GET",
+			Path:       fmt.Sprintf("/pets/%d", i),
+			Summary:    "Get a pet",
+			Parameters: []models.Parameter{{Name: "id", In: "path", Type: "string", Required: true}},
+			Responses:  []models.Response{{StatusCode: 200, Description: "The pet", SchemaName: "Pet"}},
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := WritePDF(&buf, doc); err != nil {
+		t.Fatalf("Failed to write PDF: %v", err)
+	}
+	pdf := buf.String()
+
+	if !strings.HasPrefix(pdf, "%PDF-1.4") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatalf("Expected a PDF header and trailer")
+	}
+	if pages := strings.Count(pdf, "/Type /Page "); pages < 2 {
+		t.Errorf("Expected 60 endpoints to take several pages, got %d", pages)
+	}
+	if !strings.Contains(pdf, `(Pets \(v2\))`) {
+		t.Errorf("Expected the title with escaped parentheses")
+	}
+	if !strings.Contains(pdf, "Caf\xe9 owners welcome ?") {
+		t.Errorf("Expected Windows-1252 text with unsupported characters replaced")
+	}
+
+	// The cross-reference table must point at the xref keyword and each object
+	match := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(pdf)
+	xref, _ := strconv.Atoi(match[1])
+	if !strings.HasPrefix(pdf[xref:], "xref") {
+		t.Fatalf("Expected startxref to point at the xref table")
+	}
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n`).FindAllStringSubmatch(pdf[xref:], -1)
+	for i, offset := range offsets {
+		at, _ := strconv.Atoi(offset[1])
+		if !strings.HasPrefix(pdf[at:], fmt.Sprintf("%d 0 obj", i+1)) {
+			t.Errorf("Expected object %d at offset %d", i+1, at)
+		}
+	}
+}
+
+// TestWrap tests breaking text at words and breaking words wider than a line
+func TestWrap(t *testing.T) {
+	lines := wrap(pdfRegular, 10, "one two three", textWidth(pdfRegular, 10, "one two"))
+	if len(lines) != 2 || lines[0] != "one two" || lines[1] != "three" {
+		t.Errorf("Expected two lines, got %q", lines)
+	}
+
+	lines = wrap(pdfMono, 10, strings.Repeat("x", 25), 60)
+	if len(lines) != 3 || lines[0] != strings.Repeat("x", 10) {
+		t.Errorf("Expected a long word broken into lines of 10, got %q", lines)
+	}
+}
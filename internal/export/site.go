@@ -0,0 +1,127 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"embed"
+	"encoding/json"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"universal_api/internal/markdown"
+	"universal_api/internal/models"
+)
+
+//go:embed templates
+var siteFiles embed.FS
+
+// siteTemplates renders the pages of the static site
+var siteTemplates = template.Must(template.New("").Funcs(template.FuncMap{
+	"lower":    strings.ToLower,
+	"pageName": pageName,
+	"markdown": markdown.Render,
+}).ParseFS(siteFiles, "templates/site.tmpl"))
+
+var unsafePageChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SiteWriter writes a file of the static site at a slash-separated path
+type SiteWriter func(name string, data []byte) error
+
+// SearchEntry is an endpoint in the static site's search-index.json
+type SearchEntry struct {
+	DocID    string `json:"doc_id"`
+	DocTitle string `json:"doc_title"`
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Summary  string `json:"summary,omitempty"`
+	URL      string `json:"url"`  // page of the endpoint, relative to the site root
+	Text     string `json:"text"` // lowercased text the search page matches
+}
+
+// BuildSite renders docs into a static HTML site: index.html listing the docs, a page per
+// doc under docs/, and search.html searching search-index.json in the browser. Links are
+// relative, so the site can be hosted under any path.
+func BuildSite(docs []*models.APIDoc, write SiteWriter) error {
+	exportedAt := time.Now()
+	render := func(name, page string, data map[string]interface{}) error {
+		data["ExportedAt"] = exportedAt
+		var buf bytes.Buffer
+		if err := siteTemplates.ExecuteTemplate(&buf, page, data); err != nil {
+			return err
+		}
+		return write(name, buf.Bytes())
+	}
+
+	if err := render("index.html", "index", map[string]interface{}{"Title": "API Documentation", "Root": "", "APIDocs": docs}); err != nil {
+		return err
+	}
+	if err := render("search.html", "search", map[string]interface{}{"Title": "Search", "Root": ""}); err != nil {
+		return err
+	}
+
+	entries := []SearchEntry{}
+	for _, doc := range docs {
+		page := "docs/" + pageName(doc.ID)
+		if err := render(page, "doc", map[string]interface{}{"Title": doc.Title, "Root": "../", "APIDoc": doc}); err != nil {
+			return err
+		}
+
+		for _, endpoint := range doc.Endpoints {
+			entries = append(entries, SearchEntry{
+				DocID:    doc.ID,
+				DocTitle: doc.Title,
+				Method:   endpoint.Method,
+				Path:     endpoint.Path,
+				Summary:  endpoint.Summary,
+				URL:      page + "#endpoint-" + endpoint.StableID(),
+				Text:     strings.ToLower(strings.Join([]string{doc.Title, endpoint.Method, endpoint.Path, endpoint.Summary, strings.Join(endpoint.Tags, " ")}, " ")),
+			})
+		}
+	}
+
+	index, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	if err := write("search-index.json", index); err != nil {
+		return err
+	}
+
+	css, err := siteFiles.ReadFile("templates/site.css")
+	if err != nil {
+		return err
+	}
+	return write("assets/site.css", css)
+}
+
+// pageName returns the file name of a doc's page, keeping only characters safe in file names and URLs
+func pageName(id string) string {
+	return unsafePageChars.ReplaceAllString(id, "_") + ".html"
+}
+
+// DirWriter writes the files of a static site under a directory, creating it as needed
+func DirWriter(dir string) SiteWriter {
+	return func(name string, data []byte) error {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(path, data, 0o644)
+	}
+}
+
+// ZipWriter writes the files of a static site into a zip archive
+func ZipWriter(archive *zip.Writer) SiteWriter {
+	return func(name string, data []byte) error {
+		file, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return err
+		}
+		_, err = file.Write(data)
+		return err
+	}
+}
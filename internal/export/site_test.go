@@ -0,0 +1,54 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestBuildSite tests rendering the pages and search index of a static site
+func TestBuildSite(t *testing.T) {
+	files := make(map[string]string)
+	write := func(name string, data []byte) error {
+		files[name] = string(data)
+		return nil
+	}
+
+	docs := []*models.APIDoc{{
+		ID:          "git:payments/openapi",
+		Title:       "Payments <API>",
+		Description: "Charge **cards**",
+		Endpoints: []models.Endpoint{
+			{Method: "GET", Path: "/charges/{id}", Summary: "Get a charge", Tags: []string{"charges"}},
+		},
+	}}
+	if err := BuildSite(docs, write); err != nil {
+		t.Fatalf("Failed to build site: %v", err)
+	}
+
+	for _, name := range []string{"index.html", "search.html", "docs/git_payments_openapi.html", "search-index.json", "assets/site.css"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("Expected file %s, got %d files", name, len(files))
+		}
+	}
+
+	if !strings.Contains(files["index.html"], `href="docs/git_payments_openapi.html"`) || !strings.Contains(files["index.html"], "Payments &lt;API&gt;") {
+		t.Error("Expected the index to link to the escaped doc page")
+	}
+	if page := files["docs/git_payments_openapi.html"]; !strings.Contains(page, `href="../assets/site.css"`) || !strings.Contains(page, "/charges/{id}") {
+		t.Error("Expected the doc page to use relative links and list the endpoint")
+	}
+	if !strings.Contains(files["docs/git_payments_openapi.html"], "<p>Charge <strong>cards</strong></p>") {
+		t.Error("Expected the doc page to render the description's markdown")
+	}
+
+	var entries []SearchEntry
+	if err := json.Unmarshal([]byte(files["search-index.json"]), &entries); err != nil {
+		t.Fatalf("Failed to parse search index: %v", err)
+	}
+	if len(entries) != 1 || entries[0].URL != "docs/git_payments_openapi.html#endpoint-"+models.EndpointID("GET", "/charges/{id}") || !strings.Contains(entries[0].Text, "get a charge charges") {
+		t.Errorf("Unexpected search index: %+v", entries)
+	}
+}
@@ -0,0 +1,157 @@
+package governance
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"universal_api/internal/models"
+)
+
+// Severities of policy violations. Only errors block ingestion in strict mode.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// maxListed bounds the endpoints named in a violation's message
+const maxListed = 5
+
+// Policy is an organization policy that docs are checked against when they're saved
+type Policy struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Rule        string `yaml:"rule" json:"rule"`                             // one of Rules
+	Severity    string `yaml:"severity,omitempty" json:"severity,omitempty"` // error (default) or warning
+	Match       Match  `yaml:"match,omitempty" json:"match,omitempty"`
+}
+
+// Match scopes a policy to some docs; an empty match applies to all
+type Match struct {
+	URL    string `yaml:"url,omitempty" json:"url,omitempty"`       // doc URLs, with * matching anything, e.g. https://*.internal.example.com/*
+	Source string `yaml:"source,omitempty" json:"source,omitempty"` // source type: git, kubernetes, or gateway
+}
+
+// Rule checks a doc, returning the message of its violation or "" when the doc complies
+type Rule func(doc *models.APIDoc) string
+
+// semver matches semantic versions like 1.2.3, v2.0.0-beta.1, or 1.0.0+build.5
+var semver = regexp.MustCompile(`^v?(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// Rules are the built-in rules policies can use, by name
+var Rules = map[string]Rule{
+	"require_auth": func(doc *models.APIDoc) string {
+		if len(doc.AuthTypes) == 0 {
+			return "The doc doesn't define how to authenticate"
+		}
+		return ""
+	},
+	"endpoint_descriptions": func(doc *models.APIDoc) string {
+		return endpointsWithout(doc, "description", func(endpoint models.Endpoint) bool {
+			return strings.TrimSpace(endpoint.Description) != ""
+		})
+	},
+	"semver_version": func(doc *models.APIDoc) string {
+		if !semver.MatchString(doc.Version) {
+			return fmt.Sprintf("Version %q isn't a semantic version", doc.Version)
+		}
+		return ""
+	},
+	"require_endpoints": func(doc *models.APIDoc) string {
+		if len(doc.Endpoints) == 0 {
+			return "The doc has no endpoints"
+		}
+		return ""
+	},
+	"require_servers": func(doc *models.APIDoc) string {
+		if len(doc.Servers) == 0 {
+			return "The doc doesn't list the servers the API is served from"
+		}
+		return ""
+	},
+}
+
+// endpointsWithout returns the message of a violation naming the endpoints that lack something, or
+// "" when they all have it
+func endpointsWithout(doc *models.APIDoc, what string, has func(models.Endpoint) bool) string {
+	var missing []string
+	for _, endpoint := range doc.Endpoints {
+		if !has(endpoint) {
+			missing = append(missing, endpoint.Method+" "+endpoint.Path)
+		}
+	}
+	if len(missing) == 0 {
+		return ""
+	}
+	listed := strings.Join(missing[:min(len(missing), maxListed)], ", ")
+	if len(missing) > maxListed {
+		listed += fmt.Sprintf(", and %d more", len(missing)-maxListed)
+	}
+	return fmt.Sprintf("%d endpoints have no %s: %s", len(missing), what, listed)
+}
+
+// Engine checks docs against the configured policies
+type Engine struct {
+	Policies []Policy
+	Strict   bool // block saving docs that violate a policy with error severity
+}
+
+// ParsePolicies parses a YAML or JSON policies file with a list of policies under "policies"
+func ParsePolicies(data []byte) ([]Policy, error) {
+	var file struct {
+		Policies []Policy `yaml:"policies"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse policies: %w", err)
+	}
+	for i := range file.Policies {
+		policy := &file.Policies[i]
+		if policy.Name == "" {
+			return nil, fmt.Errorf("policy %d has no name", i+1)
+		}
+		if _, ok := Rules[policy.Rule]; !ok {
+			return nil, fmt.Errorf("policy %s has an unknown rule: %s", policy.Name, policy.Rule)
+		}
+		switch policy.Severity {
+		case "":
+			policy.Severity = SeverityError
+		case SeverityError, SeverityWarning:
+		default:
+			return nil, fmt.Errorf("policy %s has an unknown severity: %s", policy.Name, policy.Severity)
+		}
+	}
+	return file.Policies, nil
+}
+
+// Evaluate returns the violations of the policies that apply to a doc
+func (e *Engine) Evaluate(doc *models.APIDoc) []models.PolicyViolation {
+	var violations []models.PolicyViolation
+	for _, policy := range e.Policies {
+		if !policy.Match.matches(doc) {
+			continue
+		}
+		if message := Rules[policy.Rule](doc); message != "" {
+			violations = append(violations, models.PolicyViolation{Policy: policy.Name, Severity: policy.Severity, Message: message})
+		}
+	}
+	return violations
+}
+
+// matches checks if a doc is in a policy's scope
+func (m Match) matches(doc *models.APIDoc) bool {
+	if m.URL != "" && !globMatch(m.URL, doc.URL) {
+		return false
+	}
+	if m.Source != "" && (doc.Source == nil || doc.Source.Type != m.Source) {
+		return false
+	}
+	return true
+}
+
+// globMatch checks if text matches a pattern in which * matches any text, including slashes
+func globMatch(pattern, text string) bool {
+	expression := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	return regexp.MustCompile("^" + expression + "$").MatchString(text)
+}
@@ -0,0 +1,97 @@
+package governance
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+const testPolicies = `
+policies:
+  - name: internal-auth
+    rule: require_auth
+    match:
+      url: "https://*.internal.example.com/*"
+  - name: described-endpoints
+    rule: endpoint_descriptions
+    severity: warning
+  - name: semver
+    rule: semver_version
+`
+
+// TestEvaluate tests checking docs against policies scoped by URL
+func TestEvaluate(t *testing.T) {
+	policies, err := ParsePolicies([]byte(testPolicies))
+	if err != nil {
+		t.Fatalf("Failed to parse policies: %v", err)
+	}
+	engine := &Engine{Policies: policies}
+
+	doc := &models.APIDoc{
+		URL:     "https://billing.internal.example.com/docs/v1",
+		Version: "1.2",
+		Endpoints: []models.Endpoint{
+			{Method: "GET", Path: "/invoices", Description: "Lists invoices"},
+			{Method: "POST", Path: "/invoices"},
+		},
+	}
+	violations := engine.Evaluate(doc)
+	if len(violations) != 3 {
+		t.Fatalf("Expected 3 violations, got %+v", violations)
+	}
+	if violations[0].Policy != "internal-auth" || violations[0].Severity != SeverityError {
+		t.Errorf("Expected an internal-auth error, got %+v", violations[0])
+	}
+	if violations[1].Severity != SeverityWarning || !strings.Contains(violations[1].Message, "POST /invoices") {
+		t.Errorf("Expected a warning naming POST /invoices, got %+v", violations[1])
+	}
+
+	doc.URL = "https://api.example.com/docs"
+	doc.Version = "v1.2.0"
+	if violations := engine.Evaluate(doc); len(violations) != 1 || violations[0].Policy != "described-endpoints" {
+		t.Errorf("Expected only the described-endpoints violation, got %+v", violations)
+	}
+}
+
+// TestParsePoliciesInvalid tests rejecting policies with unknown rules or severities
+func TestParsePoliciesInvalid(t *testing.T) {
+	for _, data := range []string{
+		"policies:\n  - name: x\n    rule: nope\n",
+		"policies:\n  - name: x\n    rule: require_auth\n    severity: fatal\n",
+		"policies:\n  - rule: require_auth\n",
+	} {
+		if _, err := ParsePolicies([]byte(data)); err == nil {
+			t.Errorf("Expected an error parsing %q", data)
+		}
+	}
+}
+
+// TestEnforcingStorageStrict tests that strict mode blocks docs with errors but saves those with warnings
+func TestEnforcingStorageStrict(t *testing.T) {
+	policies, err := ParsePolicies([]byte(testPolicies))
+	if err != nil {
+		t.Fatalf("Failed to parse policies: %v", err)
+	}
+	store := NewEnforcingStorage(storage.NewMemoryStorage(), &Engine{Policies: policies, Strict: true})
+
+	blocked := &models.APIDoc{ID: "blocked", URL: "https://api.example.com", Version: "latest"}
+	var violation *ViolationError
+	if err := store.SaveAPIDoc(blocked); !errors.As(err, &violation) || len(violation.Violations) != 1 {
+		t.Fatalf("Expected a violation error, got %v", err)
+	}
+	if _, err := store.GetAPIDoc("blocked"); err == nil {
+		t.Error("Expected the blocked doc not to be saved")
+	}
+
+	warned := &models.APIDoc{ID: "warned", URL: "https://api.example.com", Version: "2.0.0", Endpoints: []models.Endpoint{{Method: "GET", Path: "/x"}}}
+	if err := store.SaveAPIDoc(warned); err != nil {
+		t.Fatalf("Expected the doc with warnings to be saved, got %v", err)
+	}
+	saved, err := store.GetAPIDoc("warned")
+	if err != nil || len(saved.Violations) != 1 {
+		t.Errorf("Expected the saved doc to keep its warning, got %+v, %v", saved, err)
+	}
+}
@@ -0,0 +1,51 @@
+package governance
+
+import (
+	"fmt"
+	"strings"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// ViolationError is returned by saves that strict mode blocks, listing the violations with error severity
+type ViolationError struct {
+	Violations []models.PolicyViolation
+}
+
+// Error implements the error interface
+func (e *ViolationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Policy + ": " + violation.Message
+	}
+	return fmt.Sprintf("violates %d policies: %s", len(e.Violations), strings.Join(messages, "; "))
+}
+
+// EnforcingStorage wraps a Storage, checking docs against the policies before saving them
+type EnforcingStorage struct {
+	storage.Storage
+	engine *Engine
+}
+
+// NewEnforcingStorage wraps store
+func NewEnforcingStorage(store storage.Storage, engine *Engine) *EnforcingStorage {
+	return &EnforcingStorage{Storage: store, engine: engine}
+}
+
+// SaveAPIDoc records the doc's violations and saves it, unless strict mode blocks a doc with errors
+func (s *EnforcingStorage) SaveAPIDoc(doc *models.APIDoc) error {
+	doc.Violations = s.engine.Evaluate(doc)
+	if s.engine.Strict {
+		var blocking []models.PolicyViolation
+		for _, violation := range doc.Violations {
+			if violation.Severity == SeverityError {
+				blocking = append(blocking, violation)
+			}
+		}
+		if len(blocking) > 0 {
+			return &ViolationError{Violations: blocking}
+		}
+	}
+	return s.Storage.SaveAPIDoc(doc)
+}
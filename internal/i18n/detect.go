@@ -0,0 +1,141 @@
+package i18n
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"universal_api/internal/models"
+)
+
+// minStopwords is how many function words of a language Latin-script text needs to be detected as it
+const minStopwords = 2
+
+// minScriptShare is the share of letters a non-Latin script needs to decide the language on its own
+const minScriptShare = 0.15
+
+// stopwords lists frequent function words of languages written in the Latin script, in tie-breaking order
+var stopwords = []struct {
+	language string
+	words    []string
+}{
+	{"en", []string{"the", "and", "of", "to", "is", "in", "for", "with", "this", "that", "are", "be", "by", "an", "a", "returns", "from", "or", "will", "if", "you"}},
+	{"de", []string{"der", "die", "das", "und", "ist", "nicht", "mit", "für", "von", "den", "ein", "eine", "zu", "wird", "werden", "auf", "des", "dem", "oder", "sie"}},
+	{"fr", []string{"le", "la", "les", "et", "est", "des", "une", "un", "pour", "dans", "du", "sur", "avec", "ce", "qui", "pas", "sont", "par", "vous", "au"}},
+	{"es", []string{"el", "la", "los", "las", "y", "es", "de", "que", "en", "un", "una", "para", "con", "por", "del", "se", "al", "este", "son", "como"}},
+	{"pt", []string{"o", "a", "os", "as", "e", "é", "de", "que", "em", "um", "uma", "para", "com", "não", "do", "da", "dos", "das", "por", "são"}},
+	{"it", []string{"il", "lo", "la", "gli", "le", "e", "è", "di", "che", "un", "una", "per", "con", "non", "del", "della", "sono", "da", "in", "nel"}},
+	{"nl", []string{"de", "het", "een", "en", "van", "is", "dat", "op", "te", "in", "voor", "met", "niet", "zijn", "wordt", "worden", "deze", "door", "bij", "als"}},
+}
+
+// scripts maps non-Latin scripts to the language they're most likely written in
+var scripts = []struct {
+	language string
+	table    *unicode.RangeTable
+}{
+	{"ja", unicode.Hiragana},
+	{"ja", unicode.Katakana},
+	{"ko", unicode.Hangul},
+	{"zh", unicode.Han},
+	{"ru", unicode.Cyrillic},
+	{"ar", unicode.Arabic},
+	{"el", unicode.Greek},
+	{"he", unicode.Hebrew},
+	{"th", unicode.Thai},
+	{"hi", unicode.Devanagari},
+}
+
+// Detect returns the ISO 639-1 code of the language text is written in, or "" when it can't tell
+func Detect(text string) string {
+	letters := 0
+	counts := make(map[string]int)
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, script := range scripts {
+			if unicode.Is(script.table, r) {
+				counts[script.language]++
+				break
+			}
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+
+	// Japanese mixes kana with Han characters, so any kana decides it over Chinese
+	if counts["ja"] > 0 {
+		counts["ja"] += counts["zh"]
+		delete(counts, "zh")
+	}
+	language, best := "", 0
+	for _, script := range scripts {
+		if counts[script.language] > best {
+			language, best = script.language, counts[script.language]
+		}
+	}
+	if float64(best) >= minScriptShare*float64(letters) {
+		return language
+	}
+
+	return detectLatin(text)
+}
+
+// detectLatin detects the language of Latin-script text by counting its function words
+func detectLatin(text string) string {
+	words := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		words[word]++
+	}
+
+	language, best := "", minStopwords-1
+	for _, candidate := range stopwords {
+		score := 0
+		for _, word := range candidate.words {
+			score += words[word]
+		}
+		if score > best {
+			language, best = candidate.language, score
+		}
+	}
+	return language
+}
+
+// DocLanguage detects the language of a doc's prose: its title, description, and endpoint summaries and descriptions
+func DocLanguage(doc *models.APIDoc) string {
+	texts := []string{doc.Title, doc.Description}
+	for _, endpoint := range doc.Endpoints {
+		texts = append(texts, endpoint.Summary, endpoint.Description)
+		for _, param := range endpoint.Parameters {
+			texts = append(texts, param.Description)
+		}
+	}
+	return Detect(strings.Join(texts, "\n"))
+}
+
+// FilterByLanguage returns the docs written in a language
+func FilterByLanguage(docs []*models.APIDoc, language string) []*models.APIDoc {
+	filtered := []*models.APIDoc{}
+	for _, doc := range docs {
+		if strings.EqualFold(doc.Language, language) {
+			filtered = append(filtered, doc)
+		}
+	}
+	return filtered
+}
+
+// Languages returns the distinct languages of docs, sorted
+func Languages(docs []*models.APIDoc) []string {
+	seen := make(map[string]bool)
+	var languages []string
+	for _, doc := range docs {
+		if doc.Language != "" && !seen[doc.Language] {
+			seen[doc.Language] = true
+			languages = append(languages, doc.Language)
+		}
+	}
+	sort.Strings(languages)
+	return languages
+}
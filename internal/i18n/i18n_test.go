@@ -0,0 +1,86 @@
+package i18n
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// TestDetect tests detecting languages by script and by function words
+func TestDetect(t *testing.T) {
+	tests := map[string]string{
+		"Returns the list of users that are visible to the caller":              "en",
+		"Gibt die Liste der Benutzer zurück, die für den Aufrufer sichtbar ist": "de",
+		"Renvoie la liste des utilisateurs visibles pour l'appelant":            "fr",
+		"Devuelve la lista de los usuarios que son visibles para el llamante":   "es",
+		"ユーザーの一覧を取得します (GET /users)":                                            "ja",
+		"获取用户列表": "zh",
+		"Получить список пользователей": "ru",
+		"GET /users": "",
+	}
+
+	for text, want := range tests {
+		if got := Detect(text); got != want {
+			t.Errorf("Detect(%q) = %q, expected %q", text, got, want)
+		}
+	}
+}
+
+// TestDetectingStorage tests detecting and translating saved docs
+func TestDetectingStorage(t *testing.T) {
+	var requests []libreTranslateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request libreTranslateRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		requests = append(requests, request)
+
+		translated := make([]string, len(request.Q))
+		for i, text := range request.Q {
+			translated[i] = "[" + request.Target + "] " + text
+		}
+		json.NewEncoder(w).Encode(map[string][]string{"translatedText": translated})
+	}))
+	defer server.Close()
+
+	translator, err := NewTranslator("libretranslate", server.URL, "")
+	if err != nil {
+		t.Fatalf("Failed to create translator: %v", err)
+	}
+	store := NewDetectingStorage(storage.NewMemoryStorage(), translator, "en")
+
+	doc := &models.APIDoc{
+		ID:          "de-doc",
+		Title:       "Benutzer-API",
+		Description: "Die API für die Verwaltung der Benutzer",
+		Endpoints:   []models.Endpoint{{Method: "GET", Path: "/users", Summary: "Liste der Benutzer"}},
+	}
+	if err := store.SaveAPIDoc(doc); err != nil {
+		t.Fatalf("Failed to save doc: %v", err)
+	}
+
+	saved, _ := store.GetAPIDoc("de-doc")
+	if saved.Language != "de" || saved.Endpoints[0].TranslatedSummary != "[en] Liste der Benutzer" ||
+		!strings.HasPrefix(saved.TranslatedDescription, "[en] ") {
+		t.Errorf("Expected a translated German doc, got %+v", saved)
+	}
+	if len(requests) != 1 || requests[0].Source != "de" {
+		t.Errorf("Expected one batched translation from German, got %+v", requests)
+	}
+
+	// Translations are kept when the doc is saved again
+	store.SaveAPIDoc(saved)
+	if len(requests) != 1 {
+		t.Errorf("Expected translated docs not to be translated again, got %d requests", len(requests))
+	}
+
+	english := &models.APIDoc{ID: "en-doc", Description: "The API for managing the users of an account"}
+	store.SaveAPIDoc(english)
+	if english.Language != "en" || english.TranslatedDescription != "" {
+		t.Errorf("Expected English docs to be left untranslated, got %+v", english)
+	}
+}
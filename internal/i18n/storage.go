@@ -0,0 +1,68 @@
+package i18n
+
+import (
+	"log"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// maxTranslationBatch is the number of texts sent to the translation provider at once
+const maxTranslationBatch = 50
+
+// DetectingStorage wraps a Storage, detecting the language of saved docs and translating
+// their descriptions and endpoint summaries when a translator is configured
+type DetectingStorage struct {
+	storage.Storage
+	translator Translator
+	target     string
+}
+
+// NewDetectingStorage wraps store; translator may be nil to only detect languages
+func NewDetectingStorage(store storage.Storage, translator Translator, target string) *DetectingStorage {
+	if target == "" {
+		target = "en"
+	}
+	return &DetectingStorage{Storage: store, translator: translator, target: target}
+}
+
+// SaveAPIDoc detects the doc's language and translates it before saving it.
+// Failed translations are logged and the doc is saved untranslated.
+func (s *DetectingStorage) SaveAPIDoc(doc *models.APIDoc) error {
+	doc.Language = DocLanguage(doc)
+	if s.translator != nil && doc.Language != "" && doc.Language != s.target {
+		if err := Translate(doc, s.translator, s.target); err != nil {
+			log.Printf("Failed to translate API doc %s: %v", doc.ID, err)
+		}
+	}
+	return s.Storage.SaveAPIDoc(doc)
+}
+
+// Translate fills in the translations of a doc's description and endpoint summaries that aren't translated yet
+func Translate(doc *models.APIDoc, translator Translator, target string) error {
+	var texts []string
+	var targets []*string
+	if doc.Description != "" && doc.TranslatedDescription == "" {
+		texts = append(texts, doc.Description)
+		targets = append(targets, &doc.TranslatedDescription)
+	}
+	for i := range doc.Endpoints {
+		endpoint := &doc.Endpoints[i]
+		if endpoint.Summary != "" && endpoint.TranslatedSummary == "" {
+			texts = append(texts, endpoint.Summary)
+			targets = append(targets, &endpoint.TranslatedSummary)
+		}
+	}
+
+	for start := 0; start < len(texts); start += maxTranslationBatch {
+		end := min(start+maxTranslationBatch, len(texts))
+		translated, err := translator.Translate(texts[start:end], doc.Language, target)
+		if err != nil {
+			return err
+		}
+		for i, text := range translated {
+			*targets[start+i] = text
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package i18n
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Translator translates texts from one language into another
+type Translator interface {
+	Translate(texts []string, source, target string) ([]string, error)
+}
+
+// NewTranslator creates the translation provider with the given name: "libretranslate"
+// for a LibreTranslate server, or "deepl" for the DeepL API
+func NewTranslator(provider, baseURL, apiKey string) (Translator, error) {
+	switch provider {
+	case "libretranslate":
+		if baseURL == "" {
+			return nil, fmt.Errorf("the libretranslate provider needs a server URL")
+		}
+		return &LibreTranslate{baseURL: strings.TrimSuffix(baseURL, "/"), apiKey: apiKey, client: newClient()}, nil
+	case "deepl":
+		if baseURL == "" {
+			baseURL = "https://api-free.deepl.com"
+		}
+		return &DeepL{baseURL: strings.TrimSuffix(baseURL, "/"), apiKey: apiKey, client: newClient()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported translation provider: %s", provider)
+	}
+}
+
+// newClient creates the HTTP client of a translation provider
+func newClient() *http.Client {
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+// LibreTranslate translates with a LibreTranslate server's /translate API
+type LibreTranslate struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// libreTranslateRequest is the body of a LibreTranslate request
+type libreTranslateRequest struct {
+	Q      []string `json:"q"`
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Format string   `json:"format"`
+	APIKey string   `json:"api_key,omitempty"`
+}
+
+// Translate implements the Translator interface
+func (t *LibreTranslate) Translate(texts []string, source, target string) ([]string, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	var parsed struct {
+		TranslatedText []string `json:"translatedText"`
+	}
+	request := libreTranslateRequest{Q: texts, Source: source, Target: target, Format: "text", APIKey: t.apiKey}
+	if err := postJSON(t.client, t.baseURL+"/translate", nil, request, &parsed); err != nil {
+		return nil, err
+	}
+	if len(parsed.TranslatedText) != len(texts) {
+		return nil, fmt.Errorf("expected %d translations, got %d", len(texts), len(parsed.TranslatedText))
+	}
+	return parsed.TranslatedText, nil
+}
+
+// DeepL translates with the DeepL API
+type DeepL struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// deepLRequest is the body of a DeepL request
+type deepLRequest struct {
+	Text       []string `json:"text"`
+	SourceLang string   `json:"source_lang,omitempty"`
+	TargetLang string   `json:"target_lang"`
+}
+
+// Translate implements the Translator interface
+func (t *DeepL) Translate(texts []string, source, target string) ([]string, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	var parsed struct {
+		Translations []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+	}
+	request := deepLRequest{Text: texts, SourceLang: strings.ToUpper(source), TargetLang: strings.ToUpper(target)}
+	header := http.Header{"Authorization": {"DeepL-Auth-Key " + t.apiKey}}
+	if err := postJSON(t.client, t.baseURL+"/v2/translate", header, request, &parsed); err != nil {
+		return nil, err
+	}
+	if len(parsed.Translations) != len(texts) {
+		return nil, fmt.Errorf("expected %d translations, got %d", len(texts), len(parsed.Translations))
+	}
+
+	translated := make([]string, len(texts))
+	for i, translation := range parsed.Translations {
+		translated[i] = translation.Text
+	}
+	return translated, nil
+}
+
+// postJSON posts a JSON request and decodes the JSON response
+func postJSON(client *http.Client, url string, header http.Header, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("translation API returned status code: %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return fmt.Errorf("failed to parse translation response: %w", err)
+	}
+	return nil
+}
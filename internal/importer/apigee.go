@@ -0,0 +1,191 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"universal_api/internal/models"
+)
+
+// apigeeBaseURL is the Apigee management API
+const apigeeBaseURL = "https://apigee.googleapis.com/v1"
+
+// ApigeeGateway imports API proxies from Apigee by reading their proxy bundles
+type ApigeeGateway struct {
+	baseURL      string // management API, apigeeBaseURL
+	organization string
+	token        string
+	client       *http.Client
+}
+
+// apigeeProxies is the response of GET /organizations/{org}/apis
+type apigeeProxies struct {
+	Proxies []struct {
+		Name string `json:"name"`
+	} `json:"proxies"`
+}
+
+// apigeeProxy is the response of GET /organizations/{org}/apis/{api}
+type apigeeProxy struct {
+	Revision []string `json:"revision"`
+}
+
+// apigeeProxyEndpoint is the subset of a ProxyEndpoint bundle file used for import
+type apigeeProxyEndpoint struct {
+	Description string `xml:"Description"`
+	BasePath    string `xml:"HTTPProxyConnection>BasePath"`
+	Flows       []struct {
+		Name        string `xml:"name,attr"`
+		Description string `xml:"Description"`
+		Condition   string `xml:"Condition"`
+	} `xml:"Flows>Flow"`
+}
+
+var (
+	apigeePathPattern = regexp.MustCompile(`proxy\.pathsuffix\s+(?:MatchesPath|Matches|=|==)\s+"([^"]+)"`)
+	apigeeVerbPattern = regexp.MustCompile(`request\.verb\s+(?:=|==)\s+"([A-Za-z]+)"`)
+)
+
+// Name implements the Gateway interface
+func (a *ApigeeGateway) Name() string {
+	return "apigee"
+}
+
+// Fetch implements the Gateway interface, creating one doc per API proxy from its latest revision
+func (a *ApigeeGateway) Fetch() ([]*models.APIDoc, error) {
+	orgURL := a.baseURL + "/organizations/" + a.organization
+
+	var proxies apigeeProxies
+	if err := a.get(orgURL+"/apis", &proxies); err != nil {
+		return nil, err
+	}
+
+	var docs []*models.APIDoc
+	for _, proxy := range proxies.Proxies {
+		var detail apigeeProxy
+		if err := a.get(orgURL+"/apis/"+proxy.Name, &detail); err != nil {
+			return nil, err
+		}
+
+		revision := latestRevision(detail.Revision)
+		if revision == "" {
+			continue
+		}
+
+		bundle, err := a.download(orgURL + "/apis/" + proxy.Name + "/revisions/" + revision + "?format=bundle")
+		if err != nil {
+			return nil, err
+		}
+
+		doc := newGatewayDoc(a.Name(), proxy.Name, proxy.Name)
+		doc.Version = revision
+		if err := addBundleEndpoints(doc, bundle); err != nil {
+			return nil, fmt.Errorf("failed to read bundle of %s: %w", proxy.Name, err)
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+// get performs an authorized GET request and decodes the JSON response into v
+func (a *ApigeeGateway) get(url string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return getJSON(a.client, req, v)
+}
+
+// download performs an authorized GET request and returns the raw response body
+func (a *ApigeeGateway) download(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP request failed with status code: %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// addBundleEndpoints reads the proxy endpoints of a proxy bundle and adds their conditional flows as endpoints
+func addBundleEndpoints(doc *models.APIDoc, bundle []byte) error {
+	archive, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range archive.File {
+		if path.Dir(file.Name) != "apiproxy/proxies" || path.Ext(file.Name) != ".xml" {
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return err
+		}
+		var endpoint apigeeProxyEndpoint
+		err = xml.NewDecoder(reader).Decode(&endpoint)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+
+		if doc.Description == "" {
+			doc.Description = endpoint.Description
+		}
+
+		for _, flow := range endpoint.Flows {
+			pathMatch := apigeePathPattern.FindStringSubmatch(flow.Condition)
+			if pathMatch == nil {
+				continue
+			}
+
+			method := "ANY"
+			if verbMatch := apigeeVerbPattern.FindStringSubmatch(flow.Condition); verbMatch != nil {
+				method = strings.ToUpper(verbMatch[1])
+			}
+
+			doc.Endpoints = append(doc.Endpoints, models.Endpoint{
+				Path:        strings.TrimSuffix(endpoint.BasePath, "/") + pathMatch[1],
+				Method:      method,
+				Summary:     flow.Name,
+				Description: flow.Description,
+				Parameters:  []models.Parameter{},
+				Responses:   []models.Response{},
+			})
+		}
+	}
+
+	return nil
+}
+
+// latestRevision returns the highest numbered revision
+func latestRevision(revisions []string) string {
+	latest, latestNum := "", -1
+	for _, revision := range revisions {
+		if num, err := strconv.Atoi(revision); err == nil && num > latestNum {
+			latest, latestNum = revision, num
+		}
+	}
+	return latest
+}
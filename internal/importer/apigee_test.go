@@ -0,0 +1,118 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// apigeeTestBundle zips files into a proxy bundle
+func apigeeTestBundle(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range files {
+		file, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s to bundle: %v", name, err)
+		}
+		file.Write([]byte(content))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Failed to zip bundle: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// TestApigeeFetch tests importing the conditional flows of the latest revision of each API proxy
+func TestApigeeFetch(t *testing.T) {
+	bundle := apigeeTestBundle(t, map[string]string{
+		"apiproxy/proxies/default.xml": `<ProxyEndpoint name="default">
+			<Description>Order API</Description>
+			<HTTPProxyConnection><BasePath>/orders/</BasePath></HTTPProxyConnection>
+			<Flows>
+				<Flow name="List orders">
+					<Description>Lists the orders</Description>
+					<Condition>(proxy.pathsuffix MatchesPath "/") and (request.verb = "GET")</Condition>
+				</Flow>
+				<Flow name="Get order">
+					<Condition>(proxy.pathsuffix MatchesPath "/{id}") and (request.verb == "get")</Condition>
+				</Flow>
+				<Flow name="Any order action">
+					<Condition>proxy.pathsuffix Matches "/{id}/actions/*"</Condition>
+				</Flow>
+				<Flow name="Verb only">
+					<Condition>request.verb = "DELETE"</Condition>
+				</Flow>
+			</Flows>
+		</ProxyEndpoint>`,
+		"apiproxy/targets/default.xml": `<TargetEndpoint name="default"><Flows><Flow name="Target"><Condition>proxy.pathsuffix MatchesPath "/target"</Condition></Flow></Flows></TargetEndpoint>`,
+	})
+
+	var downloaded string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer apigee-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/organizations/shop/apis":
+			w.Write([]byte(`{"proxies": [{"name": "orders"}, {"name": "undeployed"}]}`))
+		case "/organizations/shop/apis/orders":
+			w.Write([]byte(`{"revision": ["1", "10", "2"]}`))
+		case "/organizations/shop/apis/undeployed":
+			w.Write([]byte(`{"revision": []}`))
+		case "/organizations/shop/apis/orders/revisions/10":
+			downloaded = r.URL.RawQuery
+			w.Write(bundle)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	gateway := &ApigeeGateway{baseURL: server.URL, organization: "shop", token: "apigee-token", client: server.Client()}
+	docs, err := gateway.Fetch()
+	if err != nil {
+		t.Fatalf("Failed to fetch API proxies: %v", err)
+	}
+
+	if downloaded != "format=bundle" {
+		t.Errorf("Expected the bundle of the latest revision to be downloaded, got query %q", downloaded)
+	}
+	if len(docs) != 1 {
+		t.Fatalf("Expected 1 doc for the proxy with revisions, got %d", len(docs))
+	}
+	doc := docs[0]
+	if doc.Title != "orders" || doc.Version != "10" || doc.Description != "Order API" {
+		t.Errorf("Expected the orders proxy at revision 10, got %+v", doc)
+	}
+
+	// Flows without a path condition, and target endpoints, aren't endpoints of the API
+	expected := []struct{ method, path, summary string }{
+		{"GET", "/orders/", "List orders"},
+		{"GET", "/orders/{id}", "Get order"},
+		{"ANY", "/orders/{id}/actions/*", "Any order action"},
+	}
+	if len(doc.Endpoints) != len(expected) {
+		t.Fatalf("Expected %d endpoints, got %v", len(expected), doc.Endpoints)
+	}
+	for i, endpoint := range doc.Endpoints {
+		if endpoint.Method != expected[i].method || endpoint.Path != expected[i].path || endpoint.Summary != expected[i].summary {
+			t.Errorf("Expected %s %s (%s), got %s %s (%s)", expected[i].method, expected[i].path, expected[i].summary, endpoint.Method, endpoint.Path, endpoint.Summary)
+		}
+	}
+	if doc.Endpoints[0].Description != "Lists the orders" {
+		t.Errorf("Expected the flow's description, got %q", doc.Endpoints[0].Description)
+	}
+}
+
+// TestApigeeBundleNotZip tests that a bundle that isn't a zip archive is an error
+func TestApigeeBundleNotZip(t *testing.T) {
+	doc := newGatewayDoc("apigee", "orders", "orders")
+	if err := addBundleEndpoints(doc, []byte("<html>Not found</html>")); err == nil {
+		t.Errorf("Expected an error for a bundle that isn't a zip archive")
+	}
+}
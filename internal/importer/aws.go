@@ -0,0 +1,229 @@
+package importer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"universal_api/internal/models"
+	"universal_api/pkg/parser"
+)
+
+// awsPageSize is the number of REST APIs listed per page, the most API Gateway allows
+const awsPageSize = 500
+
+// AWSGateway imports REST APIs from AWS API Gateway using its OpenAPI export
+type AWSGateway struct {
+	endpoint        string // management API of the region, e.g. https://apigateway.us-east-1.amazonaws.com
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	client          *http.Client
+}
+
+// awsRestAPI is a REST API in the response of GET /restapis
+type awsRestAPI struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// awsRestAPIs is the response of GET /restapis, a page of the REST APIs
+type awsRestAPIs struct {
+	Item     []awsRestAPI `json:"item"`
+	Position string       `json:"position"` // token of the next page; empty on the last page
+}
+
+// awsStages is the response of GET /restapis/{id}/stages
+type awsStages struct {
+	Item []struct {
+		StageName string `json:"stageName"`
+	} `json:"item"`
+}
+
+// Name implements the Gateway interface
+func (a *AWSGateway) Name() string {
+	return "aws"
+}
+
+// Fetch implements the Gateway interface, exporting the first stage of each REST API as OpenAPI
+func (a *AWSGateway) Fetch() ([]*models.APIDoc, error) {
+	apis, err := a.listRestAPIs()
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []*models.APIDoc
+	for _, api := range apis {
+		var stages awsStages
+		if err := a.get("/restapis/"+api.ID+"/stages", &stages); err != nil {
+			return nil, err
+		}
+
+		// APIs that were never deployed have nothing to export
+		if len(stages.Item) == 0 {
+			continue
+		}
+		stage := stages.Item[0].StageName
+
+		content, err := a.do("/restapis/" + api.ID + "/stages/" + stage + "/exports/oas30")
+		if err != nil {
+			return nil, err
+		}
+
+		exported, err := (&parser.JSONParser{}).Parse(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse export of %s: %w", api.Name, err)
+		}
+
+		doc := newGatewayDoc(a.Name(), api.ID, api.Name)
+		doc.URL = fmt.Sprintf("https://%s.execute-api.%s.amazonaws.com/%s", api.ID, a.region, stage)
+		doc.Description = api.Description
+		doc.Version = exported.Version
+		doc.Endpoints = exported.Endpoints
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+// listRestAPIs lists the REST APIs of the region, page by page
+func (a *AWSGateway) listRestAPIs() ([]awsRestAPI, error) {
+	var apis []awsRestAPI
+	query := url.Values{"limit": {strconv.Itoa(awsPageSize)}}
+	for {
+		var page awsRestAPIs
+		if err := a.get("/restapis?"+query.Encode(), &page); err != nil {
+			return nil, err
+		}
+		apis = append(apis, page.Item...)
+
+		if page.Position == "" {
+			return apis, nil
+		}
+		query.Set("position", page.Position)
+	}
+}
+
+// get performs a signed GET request and decodes the JSON response into v
+func (a *AWSGateway) get(path string, v interface{}) error {
+	req, err := a.newRequest(path)
+	if err != nil {
+		return err
+	}
+	return getJSON(a.client, req, v)
+}
+
+// do performs a signed GET request and returns the raw response body
+func (a *AWSGateway) do(path string) ([]byte, error) {
+	req, err := a.newRequest(path)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP request failed with status code: %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// newRequest creates a GET request to the API Gateway management API signed with SigV4
+func (a *AWSGateway) newRequest(path string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, a.endpoint+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	a.sign(req, time.Now().UTC())
+	return req, nil
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to a bodiless request
+func (a *AWSGateway) sign(req *http.Request, now time.Time) {
+	const service = "apigateway"
+
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(nil)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if a.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.sessionToken)
+	}
+
+	headers := map[string]string{
+		"host":       req.URL.Host,
+		"x-amz-date": amzDate,
+	}
+	if a.sessionToken != "" {
+		headers["x-amz-security-token"] = a.sessionToken
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + a.region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+a.secretAccessKey), date)
+	key = hmacSHA256(key, a.region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		a.accessKeyID, scope, signedHeaders, signature))
+}
+
+// sha256Hex returns the hex-encoded SHA-256 digest of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data using key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,104 @@
+package importer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAWSSign tests signing requests with AWS Signature Version 4, against signatures worked out by hand
+func TestAWSSign(t *testing.T) {
+	now := time.Date(2015, time.August, 30, 12, 36, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		sessionToken  string
+		signedHeaders string
+		signature     string
+	}{
+		{"long-term credentials", "", "host;x-amz-date", "d1be16af22f3e71736e0d87571fe91502eed78bd59b26cb5e03b799ab49dbc5f"},
+		{"temporary credentials", "session-token", "host;x-amz-date;x-amz-security-token", "56314dcb8839a2a361cb4aa698ad66fd5ea0c2fd355fef1f4a5da758f475ec38"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gateway := &AWSGateway{
+				region:          "us-east-1",
+				accessKeyID:     "AKIDEXAMPLE",
+				secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+				sessionToken:    test.sessionToken,
+			}
+			req := httptest.NewRequest(http.MethodGet, "https://apigateway.us-east-1.amazonaws.com/restapis?position=abc&limit=500", nil)
+			gateway.sign(req, now)
+
+			expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/apigateway/aws4_request, SignedHeaders=" +
+				test.signedHeaders + ", Signature=" + test.signature
+			if authorization := req.Header.Get("Authorization"); authorization != expected {
+				t.Errorf("Expected Authorization %q, got %q", expected, authorization)
+			}
+			if date := req.Header.Get("X-Amz-Date"); date != "20150830T123600Z" {
+				t.Errorf("Expected X-Amz-Date 20150830T123600Z, got %q", date)
+			}
+			if token := req.Header.Get("X-Amz-Security-Token"); token != test.sessionToken {
+				t.Errorf("Expected X-Amz-Security-Token %q, got %q", test.sessionToken, token)
+			}
+		})
+	}
+}
+
+// TestAWSFetch tests exporting the deployed REST APIs across pages of the REST API list
+func TestAWSFetch(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") {
+			http.Error(w, "unsigned", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/restapis":
+			pages = append(pages, r.URL.RawQuery)
+			if r.URL.Query().Get("position") == "" {
+				w.Write([]byte(`{"item": [{"id": "a1", "name": "Orders", "description": "Order API"}], "position": "page-2"}`))
+				return
+			}
+			w.Write([]byte(`{"item": [{"id": "a2", "name": "Drafts"}, {"id": "a3", "name": "Payments"}]}`))
+		case "/restapis/a1/stages":
+			w.Write([]byte(`{"item": [{"stageName": "prod"}]}`))
+		case "/restapis/a2/stages":
+			w.Write([]byte(`{"item": []}`))
+		case "/restapis/a3/stages":
+			w.Write([]byte(`{"item": [{"stageName": "live"}, {"stageName": "test"}]}`))
+		case "/restapis/a1/stages/prod/exports/oas30", "/restapis/a3/stages/live/exports/oas30":
+			w.Write([]byte(specTestData))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	gateway := &AWSGateway{endpoint: server.URL, region: "eu-west-1", accessKeyID: "AKIDEXAMPLE", secretAccessKey: "secret", client: server.Client()}
+	docs, err := gateway.Fetch()
+	if err != nil {
+		t.Fatalf("Failed to fetch REST APIs: %v", err)
+	}
+
+	if len(pages) != 2 || pages[0] != "limit=500" || pages[1] != "limit=500&position=page-2" {
+		t.Errorf("Expected the REST API list to be followed to its second page, got %v", pages)
+	}
+
+	// The API never deployed has nothing to export
+	if len(docs) != 2 {
+		t.Fatalf("Expected 2 docs, got %d", len(docs))
+	}
+	orders, payments := docs[0], docs[1]
+	if orders.Title != "Orders" || orders.Description != "Order API" || orders.URL != "https://a1.execute-api.eu-west-1.amazonaws.com/prod" {
+		t.Errorf("Expected the Orders API's doc at its prod stage, got %+v", orders)
+	}
+	if orders.Version != "1.0.0" || len(orders.Endpoints) != 1 || orders.Endpoints[0].Path != "/pets" {
+		t.Errorf("Expected the endpoints of the export, got version %q and %v", orders.Version, orders.Endpoints)
+	}
+	if payments.Title != "Payments" || payments.URL != "https://a3.execute-api.eu-west-1.amazonaws.com/live" {
+		t.Errorf("Expected the Payments API's doc at its first stage, got %+v", payments)
+	}
+}
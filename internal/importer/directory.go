@@ -0,0 +1,246 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"universal_api/internal/storage"
+	"universal_api/pkg/scraper"
+)
+
+// DefaultDirectoryURL is the APIs.guru directory listing
+const DefaultDirectoryURL = "https://api.apis.guru/v2/list.json"
+
+// DirectoryEntry represents a single API listed in an OpenAPI directory
+type DirectoryEntry struct {
+	Name    string `json:"name"`
+	Title   string `json:"title"`
+	Version string `json:"version"`
+	SpecURL string `json:"spec_url"`
+}
+
+// ImportRequest represents a request to import APIs from a directory
+type ImportRequest struct {
+	URL   string   `json:"url"`
+	APIs  []string `json:"apis"`  // names or name prefixes to import; empty imports all
+	Limit int      `json:"limit"` // maximum number of APIs to import; 0 means no limit
+}
+
+// ImportResult summarizes a directory import
+type ImportResult struct {
+	Listed   int               `json:"listed"`
+	Imported []string          `json:"imported"`
+	Failed   map[string]string `json:"failed"`
+}
+
+// apisGuruAPI is a single entry of the APIs.guru list.json format
+type apisGuruAPI struct {
+	Preferred string                     `json:"preferred"`
+	Versions  map[string]apisGuruVersion `json:"versions"`
+}
+
+// apisGuruVersion is a single version of an APIs.guru entry
+type apisGuruVersion struct {
+	SwaggerURL string `json:"swaggerUrl"`
+	Info       struct {
+		Title string `json:"title"`
+	} `json:"info"`
+}
+
+// pagedDirectory is a generic paginated directory format
+type pagedDirectory struct {
+	Items []DirectoryEntry `json:"items"`
+	Next  string           `json:"next"`
+}
+
+// Importer bulk-ingests APIs listed in an OpenAPI directory
+type Importer struct {
+	client *http.Client
+	store  storage.Storage
+}
+
+// NewImporter creates a new directory Importer
+func NewImporter(store storage.Storage) *Importer {
+	return &Importer{
+		client: &http.Client{Timeout: 30 * time.Second},
+		store:  store,
+	}
+}
+
+// Import lists the directory, scrapes the selected APIs and saves them
+func (im *Importer) Import(req ImportRequest) (*ImportResult, error) {
+	directoryURL := req.URL
+	if directoryURL == "" {
+		directoryURL = DefaultDirectoryURL
+	}
+
+	entries, err := im.List(directoryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{
+		Listed:   len(entries),
+		Imported: []string{},
+		Failed:   make(map[string]string),
+	}
+
+	for _, entry := range entries {
+		if req.Limit > 0 && len(result.Imported)+len(result.Failed) >= req.Limit {
+			break
+		}
+		if !matchesSelection(entry.Name, req.APIs) {
+			continue
+		}
+
+		apiDoc, err := scraper.ScrapeAPIDoc(entry.SpecURL)
+		if err != nil {
+			result.Failed[entry.Name] = err.Error()
+			continue
+		}
+
+		// Use a stable ID so re-importing updates the existing doc
+		apiDoc.ID = "directory-" + slugify(entry.Name)
+		if apiDoc.Title == "" {
+			apiDoc.Title = entry.Title
+		}
+
+		if err := im.store.SaveAPIDoc(apiDoc); err != nil {
+			result.Failed[entry.Name] = err.Error()
+			continue
+		}
+		result.Imported = append(result.Imported, apiDoc.ID)
+	}
+
+	return result, nil
+}
+
+// List fetches every entry of the directory, following pagination
+func (im *Importer) List(directoryURL string) ([]DirectoryEntry, error) {
+	var entries []DirectoryEntry
+
+	next := directoryURL
+	seen := make(map[string]bool)
+	for next != "" && !seen[next] {
+		seen[next] = true
+
+		page, nextURL, err := im.fetchPage(next)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, page...)
+		next = resolveURL(next, nextURL)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+
+	return entries, nil
+}
+
+// fetchPage fetches a single directory page and returns the URL of the next page, if any
+func (im *Importer) fetchPage(pageURL string) ([]DirectoryEntry, string, error) {
+	resp, err := im.client.Get(pageURL)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("HTTP request failed with status code: %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	next := nextLink(resp.Header.Get("Link"))
+
+	// Try the generic paginated format first
+	var paged pagedDirectory
+	if err := json.Unmarshal(content, &paged); err == nil && paged.Items != nil {
+		if paged.Next != "" {
+			next = paged.Next
+		}
+		return paged.Items, next, nil
+	}
+
+	// Fall back to the APIs.guru list.json format
+	var listing map[string]apisGuruAPI
+	if err := json.Unmarshal(content, &listing); err != nil {
+		return nil, "", fmt.Errorf("failed to parse directory: %w", err)
+	}
+
+	entries := make([]DirectoryEntry, 0, len(listing))
+	for name, api := range listing {
+		version, ok := api.Versions[api.Preferred]
+		if !ok || version.SwaggerURL == "" {
+			continue
+		}
+		entries = append(entries, DirectoryEntry{
+			Name:    name,
+			Title:   version.Info.Title,
+			Version: api.Preferred,
+			SpecURL: version.SwaggerURL,
+		})
+	}
+
+	return entries, next, nil
+}
+
+// Helper functions
+
+var linkNextPattern = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+
+// nextLink extracts the rel="next" URL from a Link header
+func nextLink(header string) string {
+	if match := linkNextPattern.FindStringSubmatch(header); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// matchesSelection checks if the API name was selected for import
+func matchesSelection(name string, selection []string) bool {
+	if len(selection) == 0 {
+		return true
+	}
+	for _, selected := range selection {
+		if name == selected || strings.HasPrefix(name, selected) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveURL resolves a possibly relative link against the page it was found on
+func resolveURL(base, link string) string {
+	if link == "" {
+		return ""
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return link
+	}
+	linkURL, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	return baseURL.ResolveReference(linkURL).String()
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify converts a directory name into an ID-safe slug
+func slugify(name string) string {
+	return strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"universal_api/internal/storage"
+)
+
+const specTestData = `{
+	"openapi": "3.0.0",
+	"info": {"title": "Pet API", "version": "1.0.0"},
+	"paths": {"/pets": {"get": {"summary": "List pets"}}}
+}`
+
+// TestImportAPIsGuru tests importing a directory in the APIs.guru format
+func TestImportAPIsGuru(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/list.json":
+			w.Write([]byte(`{
+				"pets.example.com": {
+					"preferred": "1.0.0",
+					"versions": {"1.0.0": {"swaggerUrl": "` + server.URL + `/specs/pets/openapi.json", "info": {"title": "Pet API"}}}
+				},
+				"broken.example.com": {
+					"preferred": "1.0.0",
+					"versions": {"1.0.0": {"swaggerUrl": "` + server.URL + `/specs/missing/openapi.json"}}
+				}
+			}`))
+		case "/specs/pets/openapi.json":
+			w.Write([]byte(specTestData))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	store := storage.NewMemoryStorage()
+	result, err := NewImporter(store).Import(ImportRequest{URL: server.URL + "/list.json"})
+	if err != nil {
+		t.Fatalf("Failed to import directory: %v", err)
+	}
+
+	if result.Listed != 2 {
+		t.Errorf("Expected 2 listed APIs, got %d", result.Listed)
+	}
+
+	if len(result.Imported) != 1 || result.Imported[0] != "directory-pets-example-com" {
+		t.Errorf("Expected pets API to be imported, got %v", result.Imported)
+	}
+
+	if _, ok := result.Failed["broken.example.com"]; !ok {
+		t.Errorf("Expected broken API to be reported as failed, got %v", result.Failed)
+	}
+
+	doc, err := store.GetAPIDoc("directory-pets-example-com")
+	if err != nil {
+		t.Fatalf("Imported doc not stored: %v", err)
+	}
+
+	if len(doc.Endpoints) != 1 {
+		t.Errorf("Expected 1 endpoint, got %d", len(doc.Endpoints))
+	}
+}
+
+// TestListPaginated tests following pagination in the generic directory format
+func TestListPaginated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(`{"items": [{"name": "b", "spec_url": "http://example.com/b/openapi.json"}]}`))
+			return
+		}
+		w.Header().Set("Link", `</list?page=2>; rel="next"`)
+		w.Write([]byte(`{"items": [{"name": "a", "spec_url": "http://example.com/a/openapi.json"}]}`))
+	}))
+	defer server.Close()
+
+	entries, err := NewImporter(storage.NewMemoryStorage()).List(server.URL + "/list")
+	if err != nil {
+		t.Fatalf("Failed to list directory: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+
+	if entries[0].Name != "a" || entries[1].Name != "b" {
+		t.Errorf("Expected entries sorted by name, got %v", entries)
+	}
+}
@@ -0,0 +1,147 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// Gateway pulls API definitions from an API gateway's admin API
+type Gateway interface {
+	// Name returns the gateway type, e.g. "kong"
+	Name() string
+	// Fetch returns one APIDoc per API managed by the gateway
+	Fetch() ([]*models.APIDoc, error)
+}
+
+// GatewayImportRequest represents a request to import APIs from a gateway
+type GatewayImportRequest struct {
+	Type string `json:"type" binding:"required"` // one of GatewayTypes
+
+	// Kong
+	AdminURL string `json:"admin_url"`
+
+	// Kong (optional) and Apigee
+	Token string `json:"token"`
+
+	// AWS API Gateway
+	Region          string `json:"region"`
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+	SessionToken    string `json:"session_token"`
+
+	// Apigee
+	Organization string `json:"organization"`
+}
+
+// GatewayTypes are the types of gateway APIs can be imported from
+var GatewayTypes = []string{"kong", "aws", "apigee"}
+
+// NewGateway creates the Gateway described by the request
+func NewGateway(req GatewayImportRequest) (Gateway, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	switch req.Type {
+	case "kong":
+		if req.AdminURL == "" {
+			return nil, fmt.Errorf("admin_url is required for Kong")
+		}
+		return &KongGateway{adminURL: req.AdminURL, token: req.Token, client: client}, nil
+	case "aws":
+		if req.Region == "" || req.AccessKeyID == "" || req.SecretAccessKey == "" {
+			return nil, fmt.Errorf("region, access_key_id and secret_access_key are required for AWS API Gateway")
+		}
+		return &AWSGateway{
+			endpoint:        fmt.Sprintf("https://apigateway.%s.amazonaws.com", req.Region),
+			region:          req.Region,
+			accessKeyID:     req.AccessKeyID,
+			secretAccessKey: req.SecretAccessKey,
+			sessionToken:    req.SessionToken,
+			client:          client,
+		}, nil
+	case "apigee":
+		if req.Organization == "" || req.Token == "" {
+			return nil, fmt.Errorf("organization and token are required for Apigee")
+		}
+		return &ApigeeGateway{baseURL: apigeeBaseURL, organization: req.Organization, token: req.Token, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unsupported gateway type: %s", req.Type)
+	}
+}
+
+// ImportGateway fetches every API from the gateway and saves it
+func ImportGateway(store storage.Storage, gateway Gateway) (*ImportResult, error) {
+	docs, err := gateway.Fetch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch APIs from %s: %w", gateway.Name(), err)
+	}
+
+	result := &ImportResult{
+		Listed:   len(docs),
+		Imported: []string{},
+		Failed:   make(map[string]string),
+	}
+
+	for _, apiDoc := range docs {
+		// Keep the original creation time when updating an existing doc
+		if existing, err := store.GetAPIDoc(apiDoc.ID); err == nil {
+			apiDoc.CreatedAt = existing.CreatedAt
+		}
+
+		if err := store.SaveAPIDoc(apiDoc); err != nil {
+			result.Failed[apiDoc.Title] = err.Error()
+			continue
+		}
+		result.Imported = append(result.Imported, apiDoc.ID)
+	}
+
+	return result, nil
+}
+
+// newGatewayDoc creates an empty APIDoc for an API managed by a gateway
+func newGatewayDoc(gateway, id, title string) *models.APIDoc {
+	return &models.APIDoc{
+		ID:        "gateway-" + gateway + "-" + slugify(id),
+		Title:     title,
+		Version:   "Unknown",
+		Endpoints: []models.Endpoint{},
+		Source: &models.Source{
+			Type:    "gateway",
+			Gateway: gateway,
+			Service: id,
+		},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// getJSON performs an authorized GET request and decodes the JSON response into v
+func getJSON(client *http.Client, req *http.Request, v interface{}) error {
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP request failed with status code: %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if err := json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return nil
+}
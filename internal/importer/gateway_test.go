@@ -0,0 +1,66 @@
+package importer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"universal_api/internal/storage"
+)
+
+// TestImportKong tests importing services and routes from the Kong Admin API
+func TestImportKong(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/services":
+			w.Write([]byte(`{"data": [{"id": "svc-1", "name": "users", "protocol": "http", "host": "users.internal", "path": "/"}], "next": null}`))
+		case r.URL.Path == "/routes" && r.URL.Query().Get("offset") == "":
+			w.Write([]byte(`{"data": [{"name": "list-users", "paths": ["/users"], "methods": ["GET", "POST"], "service": {"id": "svc-1"}}], "next": "/routes?offset=abc"}`))
+		case r.URL.Path == "/routes":
+			w.Write([]byte(`{"data": [{"name": "user", "paths": ["/users/\\d+"], "methods": null, "service": {"id": "svc-1"}}], "next": null}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	gateway, err := NewGateway(GatewayImportRequest{Type: "kong", AdminURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create gateway: %v", err)
+	}
+
+	store := storage.NewMemoryStorage()
+	result, err := ImportGateway(store, gateway)
+	if err != nil {
+		t.Fatalf("Failed to import gateway: %v", err)
+	}
+
+	if len(result.Imported) != 1 {
+		t.Fatalf("Expected 1 imported doc, got %v", result.Imported)
+	}
+
+	doc, err := store.GetAPIDoc(result.Imported[0])
+	if err != nil {
+		t.Fatalf("Imported doc not stored: %v", err)
+	}
+
+	if doc.Title != "users" {
+		t.Errorf("Expected title 'users', got '%s'", doc.Title)
+	}
+
+	if len(doc.Endpoints) != 3 {
+		t.Fatalf("Expected 3 endpoints, got %d", len(doc.Endpoints))
+	}
+
+	if doc.Endpoints[2].Method != "ANY" {
+		t.Errorf("Expected route without methods to accept ANY, got '%s'", doc.Endpoints[2].Method)
+	}
+}
+
+// TestUnsupportedGateway tests that unknown gateway types are rejected
+func TestUnsupportedGateway(t *testing.T) {
+	if _, err := NewGateway(GatewayImportRequest{Type: "nginx"}); err == nil {
+		t.Errorf("Expected error for unsupported gateway type")
+	}
+}
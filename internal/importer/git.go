@@ -0,0 +1,273 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"universal_api/internal/cluster"
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+	"universal_api/pkg/parser"
+)
+
+// GitSource keeps docs in sync with OpenAPI/AsyncAPI files committed to git repositories
+type GitSource struct {
+	repositories []string
+	workDir      string
+	store        storage.Storage
+	mu           sync.Mutex
+}
+
+// NewGitSource creates a new GitSource that clones repositories into workDir.
+// Repositories may pin a branch with a "#branch" suffix.
+func NewGitSource(store storage.Storage, workDir string, repositories []string) *GitSource {
+	return &GitSource{
+		repositories: repositories,
+		workDir:      workDir,
+		store:        store,
+	}
+}
+
+// Repositories returns the configured repositories
+func (g *GitSource) Repositories() []string {
+	return g.repositories
+}
+
+// WorkDir returns the directory repositories are cloned into
+func (g *GitSource) WorkDir() string {
+	return g.workDir
+}
+
+// Run syncs all repositories every interval until stop is closed. Instances sharing a coordinator
+// queue each repository once per interval and sync them from the shared queue.
+func (g *GitSource) Run(coordinator cluster.Coordinator, interval time.Duration, stop <-chan struct{}) {
+	cluster.Schedule(coordinator, "git-sync", interval, g.Repositories, func(repository string) error {
+		_, err := g.SyncRepository(repository)
+		return err
+	}, stop)
+}
+
+// Sync pulls every configured repository and updates the corresponding docs
+func (g *GitSource) Sync() (*ImportResult, error) {
+	result := &ImportResult{
+		Imported: []string{},
+		Failed:   make(map[string]string),
+	}
+
+	for _, repository := range g.repositories {
+		repoResult, err := g.SyncRepository(repository)
+		if err != nil {
+			result.Failed[repository] = err.Error()
+			continue
+		}
+		result.Listed += repoResult.Listed
+		result.Imported = append(result.Imported, repoResult.Imported...)
+		for name, reason := range repoResult.Failed {
+			result.Failed[name] = reason
+		}
+	}
+
+	return result, nil
+}
+
+// SyncRepository pulls a single repository and updates the docs found in it, removing the docs of spec
+// files that were deleted from it
+func (g *GitSource) SyncRepository(repository string) (*ImportResult, error) {
+	// Serialize syncs so scheduled runs and webhooks don't race on the checkout
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	repoURL, branch := splitBranch(repository)
+	checkout := filepath.Join(g.workDir, slugify(repoURL))
+
+	if err := g.pull(repoURL, branch, checkout); err != nil {
+		return nil, err
+	}
+
+	revision, err := git(checkout, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	specs, err := findSpecFiles(checkout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan repository: %w", err)
+	}
+
+	result := &ImportResult{
+		Listed:   len(specs),
+		Imported: []string{},
+		Failed:   make(map[string]string),
+	}
+	discovered := make(map[string]bool)
+
+	for _, specPath := range specs {
+		relPath, _ := filepath.Rel(checkout, specPath)
+		relPath = filepath.ToSlash(relPath)
+		id := "git-" + slugify(repoURL+"-"+relPath)
+		discovered[id] = true
+
+		apiDoc, err := parseSpecFile(specPath)
+		if err != nil {
+			result.Failed[relPath] = err.Error()
+			continue
+		}
+
+		apiDoc.ID = id
+		apiDoc.URL = repoURL
+		apiDoc.Source = &models.Source{
+			Type:       "git",
+			Repository: repoURL,
+			Path:       relPath,
+			Revision:   revision,
+		}
+
+		// Keep the original creation time when updating an existing doc
+		if existing, err := g.store.GetAPIDoc(apiDoc.ID); err == nil {
+			apiDoc.CreatedAt = existing.CreatedAt
+		}
+
+		if err := g.store.SaveAPIDoc(apiDoc); err != nil {
+			result.Failed[relPath] = err.Error()
+			continue
+		}
+		result.Imported = append(result.Imported, apiDoc.ID)
+	}
+
+	// Drop docs for spec files that were deleted or renamed in the repository
+	docs, err := g.store.GetAllAPIDocs()
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range docs {
+		if doc.Source != nil && doc.Source.Type == "git" && doc.Source.Repository == repoURL && !discovered[doc.ID] {
+			if err := g.store.DeleteAPIDoc(doc.ID); err != nil {
+				log.Printf("Failed to remove stale git doc %s: %v", doc.ID, err)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// pull clones the repository or fast-forwards an existing checkout
+func (g *GitSource) pull(repoURL, branch, checkout string) error {
+	if _, err := os.Stat(filepath.Join(checkout, ".git")); err == nil {
+		ref := "HEAD"
+		if branch != "" {
+			ref = branch
+		}
+		if _, err := git(checkout, "fetch", "--depth", "1", "origin", ref); err != nil {
+			return err
+		}
+		_, err := git(checkout, "reset", "--hard", "FETCH_HEAD")
+		return err
+	}
+
+	if err := os.MkdirAll(g.workDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create work directory: %w", err)
+	}
+
+	args := []string{"clone", "--depth", "1"}
+	if branch != "" {
+		args = append(args, "--branch", branch)
+	}
+	args = append(args, repoURL, checkout)
+
+	_, err := git(g.workDir, args...)
+	return err
+}
+
+// Helper functions
+
+// git runs a git command in dir and returns its trimmed output
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// splitBranch splits a "url#branch" repository spec
+func splitBranch(repository string) (string, string) {
+	if idx := strings.LastIndex(repository, "#"); idx > 0 {
+		return repository[:idx], repository[idx+1:]
+	}
+	return repository, ""
+}
+
+// findSpecFiles walks a checkout looking for OpenAPI/Swagger/AsyncAPI files
+func findSpecFiles(root string) ([]string, error) {
+	var specs []string
+
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" || d.Name() == "node_modules" || d.Name() == "vendor" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if isSpecFileName(d.Name()) {
+			specs = append(specs, path)
+		}
+		return nil
+	})
+
+	return specs, err
+}
+
+// isSpecFileName checks if the file name looks like an API spec
+func isSpecFileName(name string) bool {
+	lower := strings.ToLower(name)
+	ext := filepath.Ext(lower)
+	if ext != ".json" && ext != ".yaml" && ext != ".yml" {
+		return false
+	}
+	base := strings.TrimSuffix(lower, ext)
+	for _, prefix := range []string{"openapi", "swagger", "asyncapi"} {
+		if strings.HasPrefix(base, prefix) || strings.HasSuffix(base, "."+prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+var asyncAPIKeyPattern = regexp.MustCompile(`(?m)^[\s{]*"?asyncapi"?\s*:`)
+
+// parseSpecFile picks a parser for the file and parses it
+func parseSpecFile(path string) (*models.APIDoc, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var p parser.Parser
+	if asyncAPIKeyPattern.Match(content) {
+		p = &parser.AsyncAPIParser{}
+	} else if strings.EqualFold(filepath.Ext(path), ".json") {
+		p = &parser.JSONParser{}
+	} else {
+		p = &parser.YAMLParser{}
+	}
+
+	return p.Parse(content)
+}
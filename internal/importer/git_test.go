@@ -0,0 +1,118 @@
+package importer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// gitTestCommit writes files into a working copy, deleting those without content, and pushes them as a commit
+func gitTestCommit(t *testing.T, work string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(work, name)
+		if content == "" {
+			if err := os.Remove(path); err != nil {
+				t.Fatalf("Failed to delete %s: %v", name, err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory of %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "Update specs"},
+		{"push", "-q", "origin", "HEAD:main"},
+	} {
+		if _, err := git(work, args...); err != nil {
+			t.Fatalf("Failed to commit: %v", err)
+		}
+	}
+}
+
+// TestGitSync tests syncing docs with the spec files of a repository, removing docs of deleted files
+func TestGitSync(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "specs.git")
+	work := filepath.Join(dir, "work")
+	if _, err := git(dir, "init", "-q", "--bare", "--initial-branch=main", remote); err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	if _, err := git(dir, "clone", "-q", remote, work); err != nil {
+		t.Fatalf("Failed to clone repository: %v", err)
+	}
+	gitTestCommit(t, work, map[string]string{
+		"openapi.json":         specTestData,
+		"billing/openapi.json": specTestData,
+		"README.md":            "Specs",
+	})
+
+	store := storage.NewMemoryStorage()
+	others := []*models.APIDoc{
+		{ID: "git-other", Title: "Other repository", Source: &models.Source{Type: "git", Repository: "file:///elsewhere.git", Path: "openapi.json"}},
+		{ID: "scraped", Title: "Scraped"},
+	}
+	for _, doc := range others {
+		if err := store.SaveAPIDoc(doc); err != nil {
+			t.Fatalf("Failed to save doc: %v", err)
+		}
+	}
+
+	repository := "file://" + remote
+	source := NewGitSource(store, filepath.Join(dir, "checkouts"), []string{repository})
+	result, err := source.SyncRepository(repository)
+	if err != nil {
+		t.Fatalf("Failed to sync repository: %v", err)
+	}
+	if result.Listed != 2 || len(result.Imported) != 2 || len(result.Failed) != 0 {
+		t.Fatalf("Expected both specs to be imported, got %+v", result)
+	}
+
+	rootID := "git-" + slugify(repository+"-openapi.json")
+	billingID := "git-" + slugify(repository+"-billing/openapi.json")
+	doc, err := store.GetAPIDoc(billingID)
+	if err != nil {
+		t.Fatalf("Expected a doc of billing/openapi.json: %v", err)
+	}
+	revision, _ := git(work, "rev-parse", "HEAD")
+	if doc.Source == nil || doc.Source.Repository != repository || doc.Source.Path != "billing/openapi.json" || doc.Source.Revision != revision {
+		t.Errorf("Expected the doc's source to be the file at %s, got %+v", revision, doc.Source)
+	}
+
+	// Deleting a spec removes its doc, while a spec that no longer parses keeps its doc as it was
+	gitTestCommit(t, work, map[string]string{
+		"billing/openapi.json": "",
+		"openapi.json":         "{ not json",
+	})
+	result, err = source.SyncRepository(repository)
+	if err != nil {
+		t.Fatalf("Failed to sync repository: %v", err)
+	}
+	if result.Listed != 1 || len(result.Imported) != 0 || result.Failed["openapi.json"] == "" {
+		t.Errorf("Expected the broken spec to fail, got %+v", result)
+	}
+	if _, err := store.GetAPIDoc(billingID); err == nil {
+		t.Errorf("Expected the doc of the deleted spec to be removed")
+	}
+	if _, err := store.GetAPIDoc(rootID); err != nil {
+		t.Errorf("Expected the doc of the broken spec to be kept: %v", err)
+	}
+	for _, other := range others {
+		if _, err := store.GetAPIDoc(other.ID); err != nil {
+			t.Errorf("Expected %s of another source to be kept: %v", other.ID, err)
+		}
+	}
+}
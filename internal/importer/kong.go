@@ -0,0 +1,134 @@
+package importer
+
+import (
+	"net/http"
+	"strings"
+
+	"universal_api/internal/models"
+)
+
+// KongGateway imports services and routes from the Kong Admin API
+type KongGateway struct {
+	adminURL string
+	token    string
+	client   *http.Client
+}
+
+// kongPage is a paginated Kong Admin API response
+type kongPage[T any] struct {
+	Data []T    `json:"data"`
+	Next string `json:"next"`
+}
+
+// kongService is the subset of a Kong service used for import
+type kongService struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Protocol string `json:"protocol"`
+	Host     string `json:"host"`
+	Path     string `json:"path"`
+}
+
+// kongRoute is the subset of a Kong route used for import
+type kongRoute struct {
+	Name    string   `json:"name"`
+	Paths   []string `json:"paths"`
+	Methods []string `json:"methods"`
+	Service *struct {
+		ID string `json:"id"`
+	} `json:"service"`
+}
+
+// Name implements the Gateway interface
+func (k *KongGateway) Name() string {
+	return "kong"
+}
+
+// Fetch implements the Gateway interface, creating one doc per Kong service
+func (k *KongGateway) Fetch() ([]*models.APIDoc, error) {
+	services, err := kongList[kongService](k, "/services")
+	if err != nil {
+		return nil, err
+	}
+
+	routes, err := kongList[kongRoute](k, "/routes")
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make(map[string]*models.APIDoc, len(services))
+	var result []*models.APIDoc
+	for _, service := range services {
+		title := service.Name
+		if title == "" {
+			title = service.Host
+		}
+
+		doc := newGatewayDoc(k.Name(), service.ID, title)
+		doc.URL = service.Protocol + "://" + service.Host + service.Path
+		docs[service.ID] = doc
+		result = append(result, doc)
+	}
+
+	for _, route := range routes {
+		if route.Service == nil {
+			continue
+		}
+		doc, ok := docs[route.Service.ID]
+		if !ok {
+			continue
+		}
+
+		// Routes without methods accept any method
+		methods := route.Methods
+		if len(methods) == 0 {
+			methods = []string{"ANY"}
+		}
+
+		for _, path := range route.Paths {
+			for _, method := range methods {
+				doc.Endpoints = append(doc.Endpoints, models.Endpoint{
+					Path:       path,
+					Method:     strings.ToUpper(method),
+					Summary:    route.Name,
+					Parameters: []models.Parameter{},
+					Responses:  []models.Response{},
+				})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// kongList fetches every page of a Kong Admin API collection
+func kongList[T any](k *KongGateway, path string) ([]T, error) {
+	var items []T
+
+	baseURL := strings.TrimSuffix(k.adminURL, "/")
+	next := path
+	for next != "" {
+		// Older Kong versions return absolute next links
+		pageURL := next
+		if !strings.HasPrefix(next, "http") {
+			pageURL = baseURL + next
+		}
+
+		req, err := http.NewRequest(http.MethodGet, pageURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		if k.token != "" {
+			req.Header.Set("Kong-Admin-Token", k.token)
+		}
+
+		var page kongPage[T]
+		if err := getJSON(k.client, req, &page); err != nil {
+			return nil, err
+		}
+		items = append(items, page.Data...)
+		next = page.Next
+	}
+
+	return items, nil
+}
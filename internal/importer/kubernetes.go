@@ -0,0 +1,266 @@
+package importer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"universal_api/internal/cluster"
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+	"universal_api/pkg/scraper"
+)
+
+// Service annotations recognized by the Kubernetes discovery source
+const (
+	AnnotationSpecPath = "universal-api/spec-path"
+	AnnotationPort     = "universal-api/port"
+	AnnotationScheme   = "universal-api/scheme"
+)
+
+// In-cluster service account credentials
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// kubePageSize is the number of services listed per request, as kubectl does
+const kubePageSize = 500
+
+// kubeServiceList is the subset of a Kubernetes ServiceList used for discovery
+type kubeServiceList struct {
+	Metadata struct {
+		Continue string `json:"continue"` // the token of the next page, empty on the last one
+	} `json:"metadata"`
+	Items []kubeService `json:"items"`
+}
+
+// kubeService is the subset of a Kubernetes Service used for discovery
+type kubeService struct {
+	Metadata struct {
+		Name        string            `json:"name"`
+		Namespace   string            `json:"namespace"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Spec struct {
+		Ports []struct {
+			Port int `json:"port"`
+		} `json:"ports"`
+	} `json:"spec"`
+}
+
+// KubernetesSource discovers annotated services in a cluster and keeps their docs in sync
+type KubernetesSource struct {
+	apiServer string
+	token     string
+	namespace string // empty lists services in all namespaces
+	client    *http.Client
+	scraper   *scraper.Scraper // scrapes the specs of services
+	store     storage.Storage
+	mu        sync.Mutex
+}
+
+// NewInClusterKubernetesSource creates a KubernetesSource using the pod's service account
+func NewInClusterKubernetesSource(store storage.Storage, namespace string) (*KubernetesSource, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running inside a Kubernetes cluster")
+	}
+
+	token, err := os.ReadFile(serviceAccountDir + "/token")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account token: %w", err)
+	}
+
+	caCert, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster CA certificate: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, errors.New("failed to load cluster CA certificate")
+	}
+
+	return &KubernetesSource{
+		apiServer: "https://" + host + ":" + port,
+		token:     strings.TrimSpace(string(token)),
+		namespace: namespace,
+		client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+		},
+		scraper: scraper.New(),
+		store:   store,
+	}, nil
+}
+
+// Run syncs the cluster every interval until stop is closed. Instances sharing a coordinator take
+// turns, so only one of them lists and scrapes the services each interval.
+func (k *KubernetesSource) Run(coordinator cluster.Coordinator, interval time.Duration, stop <-chan struct{}) {
+	cluster.Schedule(coordinator, "kubernetes-discovery", interval, func() []string {
+		return []string{"cluster"}
+	}, func(string) error {
+		_, err := k.Sync()
+		return err
+	}, stop)
+}
+
+// Sync lists annotated services, scrapes their specs, and removes docs for services that are gone
+func (k *KubernetesSource) Sync() (*ImportResult, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	services, err := k.listServices()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{
+		Imported: []string{},
+		Failed:   make(map[string]string),
+	}
+	discovered := make(map[string]bool)
+
+	for _, service := range services {
+		specPath, ok := service.Metadata.Annotations[AnnotationSpecPath]
+		if !ok {
+			continue
+		}
+		result.Listed++
+
+		name := service.Metadata.Namespace + "/" + service.Metadata.Name
+		id := "k8s-" + slugify(name)
+		discovered[id] = true
+
+		specURL, err := serviceSpecURL(service, specPath)
+		if err != nil {
+			result.Failed[name] = err.Error()
+			continue
+		}
+
+		apiDoc, err := k.scraper.Scrape(specURL)
+		if err != nil {
+			result.Failed[name] = err.Error()
+			continue
+		}
+
+		apiDoc.ID = id
+		apiDoc.Source = &models.Source{
+			Type:    "kubernetes",
+			Path:    specPath,
+			Service: name,
+		}
+
+		// Keep the original creation time when updating an existing doc
+		if existing, err := k.store.GetAPIDoc(apiDoc.ID); err == nil {
+			apiDoc.CreatedAt = existing.CreatedAt
+		}
+
+		if err := k.store.SaveAPIDoc(apiDoc); err != nil {
+			result.Failed[name] = err.Error()
+			continue
+		}
+		result.Imported = append(result.Imported, apiDoc.ID)
+	}
+
+	// Drop docs for services that were removed or lost their annotation
+	docs, err := k.store.GetAllAPIDocs()
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range docs {
+		if doc.Source != nil && doc.Source.Type == "kubernetes" && !discovered[doc.ID] {
+			if err := k.store.DeleteAPIDoc(doc.ID); err != nil {
+				log.Printf("Failed to remove stale Kubernetes doc %s: %v", doc.ID, err)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// listServices lists the services visible to the source, page by page
+func (k *KubernetesSource) listServices() ([]kubeService, error) {
+	path := "/api/v1/services"
+	if k.namespace != "" {
+		path = "/api/v1/namespaces/" + url.PathEscape(k.namespace) + "/services"
+	}
+
+	var services []kubeService
+	query := url.Values{"limit": {strconv.Itoa(kubePageSize)}}
+	for {
+		list, err := k.listServicePage(path + "?" + query.Encode())
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, list.Items...)
+		if list.Metadata.Continue == "" {
+			return services, nil
+		}
+		query.Set("continue", list.Metadata.Continue)
+	}
+}
+
+// listServicePage lists a page of services
+func (k *KubernetesSource) listServicePage(path string) (*kubeServiceList, error) {
+	req, err := http.NewRequest(http.MethodGet, k.apiServer+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+k.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP request failed with status code: %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var list kubeServiceList
+	if err := json.Unmarshal(content, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse service list: %w", err)
+	}
+
+	return &list, nil
+}
+
+// serviceSpecURL builds the in-cluster URL of a service's spec
+func serviceSpecURL(service kubeService, specPath string) (string, error) {
+	port := service.Metadata.Annotations[AnnotationPort]
+	if port == "" {
+		if len(service.Spec.Ports) == 0 {
+			return "", errors.New("service exposes no ports")
+		}
+		port = fmt.Sprintf("%d", service.Spec.Ports[0].Port)
+	}
+
+	scheme := service.Metadata.Annotations[AnnotationScheme]
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	if !strings.HasPrefix(specPath, "/") {
+		specPath = "/" + specPath
+	}
+
+	return fmt.Sprintf("%s://%s.%s.svc:%s%s",
+		scheme, service.Metadata.Name, service.Metadata.Namespace, port, specPath), nil
+}
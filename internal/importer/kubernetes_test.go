@@ -0,0 +1,117 @@
+package importer
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+	"universal_api/pkg/scraper"
+)
+
+// TestKubernetesSync tests discovering annotated services across pages of the service list
+func TestKubernetesSync(t *testing.T) {
+	var specHosts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/v1/namespaces/shop/services" && r.URL.Query().Get("continue") == "":
+			if r.Header.Get("Authorization") != "Bearer test-token" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Query().Get("limit") == "" {
+				t.Errorf("Expected the service list to be paginated")
+			}
+			w.Write([]byte(`{"metadata": {"continue": "page-2"}, "items": [
+				{"metadata": {"name": "orders", "namespace": "shop", "annotations": {"universal-api/spec-path": "openapi.json", "universal-api/port": "8080"}}, "spec": {"ports": [{"port": 80}]}},
+				{"metadata": {"name": "cart", "namespace": "shop"}, "spec": {"ports": [{"port": 80}]}}
+			]}`))
+		case r.URL.Path == "/api/v1/namespaces/shop/services" && r.URL.Query().Get("continue") == "page-2":
+			w.Write([]byte(`{"metadata": {}, "items": [
+				{"metadata": {"name": "Pay.Ments", "namespace": "shop", "annotations": {"universal-api/spec-path": "/openapi.json"}}, "spec": {"ports": [{"port": 9000}]}},
+				{"metadata": {"name": "headless", "namespace": "shop", "annotations": {"universal-api/spec-path": "/openapi.json"}}, "spec": {"ports": []}}
+			]}`))
+		case r.URL.Path == "/openapi.json":
+			specHosts = append(specHosts, r.Host)
+			w.Write([]byte(specTestData))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	// Services' in-cluster hosts all resolve to the test server
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}
+
+	store := storage.NewMemoryStorage()
+	stale := &models.APIDoc{ID: "k8s-shop-removed", Title: "Removed", Source: &models.Source{Type: "kubernetes", Service: "shop/removed"}}
+	if err := store.SaveAPIDoc(stale); err != nil {
+		t.Fatalf("Failed to save doc: %v", err)
+	}
+
+	source := &KubernetesSource{
+		apiServer: server.URL,
+		token:     "test-token",
+		namespace: "shop",
+		client:    server.Client(),
+		scraper:   scraper.New(scraper.WithClient(&http.Client{Transport: transport})),
+		store:     store,
+	}
+	result, err := source.Sync()
+	if err != nil {
+		t.Fatalf("Failed to sync: %v", err)
+	}
+
+	if result.Listed != 3 {
+		t.Errorf("Expected 3 annotated services across both pages, got %d", result.Listed)
+	}
+	if len(result.Imported) != 2 || result.Imported[0] != "k8s-shop-orders" || result.Imported[1] != "k8s-shop-pay-ments" {
+		t.Errorf("Expected orders and payments to be imported, got %v", result.Imported)
+	}
+	if _, ok := result.Failed["shop/headless"]; !ok {
+		t.Errorf("Expected the service without ports to fail, got %v", result.Failed)
+	}
+
+	if len(specHosts) != 2 || specHosts[0] != "orders.shop.svc:8080" || specHosts[1] != "Pay.Ments.shop.svc:9000" {
+		t.Errorf("Expected specs fetched from the annotated port and the first port, got %v", specHosts)
+	}
+
+	doc, err := store.GetAPIDoc("k8s-shop-orders")
+	if err != nil {
+		t.Fatalf("Imported doc not stored: %v", err)
+	}
+	if doc.Source == nil || doc.Source.Type != "kubernetes" || doc.Source.Service != "shop/orders" || doc.Source.Path != "openapi.json" {
+		t.Errorf("Expected the doc's source to be the orders service, got %+v", doc.Source)
+	}
+
+	if _, err := store.GetAPIDoc(stale.ID); err == nil {
+		t.Errorf("Expected the doc of the removed service to be deleted")
+	}
+}
+
+// TestServiceSpecURL tests building the URL of a service's spec from its annotations
+func TestServiceSpecURL(t *testing.T) {
+	var service kubeService
+	service.Metadata.Name = "users"
+	service.Metadata.Namespace = "accounts"
+	service.Metadata.Annotations = map[string]string{AnnotationScheme: "https"}
+	service.Spec.Ports = append(service.Spec.Ports, struct {
+		Port int `json:"port"`
+	}{Port: 8443})
+
+	specURL, err := serviceSpecURL(service, "docs/openapi.yaml")
+	if err != nil {
+		t.Fatalf("Failed to build spec URL: %v", err)
+	}
+	if specURL != "https://users.accounts.svc:8443/docs/openapi.yaml" {
+		t.Errorf("Unexpected spec URL %s", specURL)
+	}
+}
@@ -0,0 +1,153 @@
+// Package linkcheck verifies that the source URLs of docs are still reachable, flagging docs whose
+// source is gone so the catalog doesn't silently accumulate dead references.
+package linkcheck
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"universal_api/internal/cluster"
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+	"universal_api/pkg/scraper"
+)
+
+// DefaultInterval is how often sources are checked when no interval is configured
+const DefaultInterval = 24 * time.Hour
+
+// checkTimeout bounds a single check, so an unresponsive host doesn't hold up the others
+const checkTimeout = 15 * time.Second
+
+// Checker checks the source URLs of docs and records the outcome of each
+type Checker struct {
+	docs   storage.Storage
+	checks storage.SourceCheckStorage
+	client *http.Client
+}
+
+// NewChecker creates a Checker of the docs of store, recording checks in checks
+func NewChecker(docs storage.Storage, checks storage.SourceCheckStorage) *Checker {
+	return &Checker{
+		docs:   docs,
+		checks: checks,
+		client: &http.Client{Timeout: checkTimeout},
+	}
+}
+
+// Run checks every doc's source every interval until stop is closed. Instances sharing a coordinator
+// queue each doc once per interval and check them from the shared queue.
+func (c *Checker) Run(coordinator cluster.Coordinator, interval time.Duration, stop <-chan struct{}) {
+	cluster.Schedule(coordinator, "source-check", interval, c.docIDs, func(id string) error {
+		_, err := c.CheckDoc(id)
+		return err
+	}, stop)
+}
+
+// docIDs returns the IDs of the docs with a source to check
+func (c *Checker) docIDs() []string {
+	docs, err := c.docs.GetAllAPIDocs()
+	if err != nil {
+		return nil
+	}
+	var ids []string
+	for _, doc := range docs {
+		if checkable(doc.URL) {
+			ids = append(ids, doc.ID)
+		}
+	}
+	return ids
+}
+
+// CheckDoc checks the source URL of a doc and records the outcome. A source found gone keeps
+// the time it was first found gone until it's reachable again.
+func (c *Checker) CheckDoc(id string) (*models.SourceCheck, error) {
+	doc, err := c.docs.GetAPIDoc(id)
+	if err != nil {
+		return nil, err
+	}
+	if !checkable(doc.URL) {
+		return nil, fmt.Errorf("doc %s has no HTTP source URL", id)
+	}
+
+	check := &models.SourceCheck{
+		DocID:     doc.ID,
+		Workspace: doc.Workspace,
+		Title:     doc.Title,
+		URL:       doc.URL,
+		CheckedAt: time.Now(),
+	}
+	check.StatusCode, err = c.status(doc.URL)
+	switch {
+	case err != nil:
+		check.Error = err.Error()
+	case Gone(check.StatusCode):
+		check.Stale = true
+		check.Error = http.StatusText(check.StatusCode)
+		check.StaleSince = &check.CheckedAt
+		if previous, err := c.checks.GetSourceCheck(id); err == nil && previous.StaleSince != nil {
+			check.StaleSince = previous.StaleSince
+		}
+	case check.StatusCode >= 400:
+		check.Error = http.StatusText(check.StatusCode)
+	}
+
+	if err := c.checks.SaveSourceCheck(check); err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// status requests a URL with HEAD, falling back to GET for hosts that don't allow HEAD, and returns
+// the status code after redirects
+func (c *Checker) status(rawURL string) (int, error) {
+	code, err := c.request(http.MethodHead, rawURL)
+	if err == nil && (code == http.StatusMethodNotAllowed || code == http.StatusNotImplemented || code == http.StatusForbidden) {
+		code, err = c.request(http.MethodGet, rawURL)
+	}
+	return code, err
+}
+
+// request sends a request without reading the body, identifying itself like the scraper
+func (c *Checker) request(method, rawURL string) (int, error) {
+	req, err := http.NewRequest(method, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", scraper.DefaultUserAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// Gone checks if a status code means the source no longer exists. Other failures, such as
+// server errors and timeouts, may be temporary and don't make a source stale.
+func Gone(statusCode int) bool {
+	return statusCode == http.StatusNotFound || statusCode == http.StatusGone
+}
+
+// Stale returns the checks that found the source gone, longest gone first
+func Stale(checks []*models.SourceCheck) []*models.SourceCheck {
+	stale := []*models.SourceCheck{}
+	for _, check := range checks {
+		if check.Stale {
+			stale = append(stale, check)
+		}
+	}
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i].StaleSince.Before(*stale[j].StaleSince)
+	})
+	return stale
+}
+
+// checkable checks if a source URL is an HTTP URL; docs imported from files and repositories have no URL to check
+func checkable(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -0,0 +1,62 @@
+package linkcheck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// TestCheckDoc tests flagging gone sources, keeping when they were first found gone, and falling back to GET
+func TestCheckDoc(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gone.json":
+			w.WriteHeader(http.StatusGone)
+		case "/no-head.json":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/broken.json":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	store := storage.NewMemoryStorage()
+	for id, path := range map[string]string{"gone": "/gone.json", "no-head": "/no-head.json", "broken": "/broken.json"} {
+		store.SaveAPIDoc(&models.APIDoc{ID: id, Title: id, URL: server.URL + path})
+	}
+	store.SaveAPIDoc(&models.APIDoc{ID: "git", URL: "git@example.com:apis.git"})
+	checker := NewChecker(store, store)
+
+	if ids := checker.docIDs(); len(ids) != 3 {
+		t.Errorf("Expected the 3 docs with HTTP sources to be checked, got %v", ids)
+	}
+
+	first, err := checker.CheckDoc("gone")
+	if err != nil || !first.Stale || first.StatusCode != http.StatusGone || first.StaleSince == nil {
+		t.Fatalf("Expected the source to be gone, got %+v, %v", first, err)
+	}
+	second, _ := checker.CheckDoc("gone")
+	if !second.StaleSince.Equal(*first.StaleSince) {
+		t.Errorf("Expected the source to stay gone since the first check, got %v", second.StaleSince)
+	}
+
+	if check, _ := checker.CheckDoc("no-head"); check.Stale || check.StatusCode != http.StatusOK {
+		t.Errorf("Expected GET to find the source, got %+v", check)
+	}
+	if check, _ := checker.CheckDoc("broken"); check.Stale || check.Error == "" {
+		t.Errorf("Expected a server error to be reported without flagging the source, got %+v", check)
+	}
+	if _, err := checker.CheckDoc("git"); err == nil {
+		t.Errorf("Expected docs without an HTTP source to be refused")
+	}
+
+	checks, _ := store.GetSourceChecks()
+	if stale := Stale(checks); len(stale) != 1 || stale[0].DocID != "gone" {
+		t.Errorf("Expected only the gone doc to be stale, got %+v", stale)
+	}
+}
@@ -0,0 +1,173 @@
+package markdown
+
+import (
+	"html"
+	"html/template"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	bulletPattern  = regexp.MustCompile(`^\s{0,3}[-*+]\s+(.*)$`)
+	orderedPattern = regexp.MustCompile(`^\s{0,3}\d{1,9}[.)]\s+(.*)$`)
+	quotePattern   = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	fencePattern   = regexp.MustCompile("^\\s{0,3}(```|~~~)")
+	linkPattern    = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	boldPattern    = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	italicPattern  = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+)
+
+// headingLevelOffset shifts headings below the page's own, so # renders as h4
+const headingLevelOffset = 3
+
+// Render renders markdown as HTML: paragraphs, headings, lists, block quotes, fenced code,
+// code spans, links, bold, and italics. The text is escaped before any markup is added, raw
+// HTML is shown as text, and only http, https, mailto, and relative links are kept, so the
+// result is safe to include in a page.
+func Render(text string) template.HTML {
+	var b strings.Builder
+	var paragraph, items []string
+	list := ""
+	inQuote := false
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			b.WriteString("<p>" + inline(strings.Join(paragraph, "\n")) + "</p>\n")
+			paragraph = nil
+		}
+	}
+	flushList := func() {
+		if list != "" {
+			b.WriteString("<" + list + ">\n")
+			for _, item := range items {
+				b.WriteString("<li>" + inline(item) + "</li>\n")
+			}
+			b.WriteString("</" + list + ">\n")
+			list, items = "", nil
+		}
+	}
+	flush := func() {
+		flushParagraph()
+		flushList()
+		if inQuote {
+			b.WriteString("</blockquote>\n")
+			inQuote = false
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if fence := fencePattern.FindStringSubmatch(line); fence != nil {
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence[1]); i++ {
+				code = append(code, lines[i])
+			}
+			b.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+
+		quoted := quotePattern.FindStringSubmatch(line)
+		if quoted != nil {
+			if !inQuote {
+				flush()
+				b.WriteString("<blockquote>\n")
+				inQuote = true
+			}
+			line = quoted[1]
+		} else if inQuote {
+			flush()
+		}
+
+		if heading := headingPattern.FindStringSubmatch(line); heading != nil {
+			flushParagraph()
+			flushList()
+			level := strconv.Itoa(min(len(heading[1])+headingLevelOffset, 6))
+			b.WriteString("<h" + level + ">" + inline(heading[2]) + "</h" + level + ">\n")
+			continue
+		}
+
+		if item := bulletPattern.FindStringSubmatch(line); item != nil {
+			startListItem(&list, &items, "ul", item[1], flushParagraph, flushList)
+			continue
+		}
+		if item := orderedPattern.FindStringSubmatch(line); item != nil {
+			startListItem(&list, &items, "ol", item[1], flushParagraph, flushList)
+			continue
+		}
+
+		// Lines following a list item continue it; others continue the paragraph
+		if list != "" && len(paragraph) == 0 {
+			items[len(items)-1] += "\n" + strings.TrimSpace(line)
+			continue
+		}
+		paragraph = append(paragraph, strings.TrimSpace(line))
+	}
+	flush()
+
+	return template.HTML(strings.TrimSuffix(b.String(), "\n"))
+}
+
+// startListItem adds an item to the current list, starting a new list if the kind changes
+func startListItem(list *string, items *[]string, kind, text string, flushParagraph, flushList func()) {
+	flushParagraph()
+	if *list != kind {
+		flushList()
+		*list = kind
+	}
+	*items = append(*items, text)
+}
+
+// inline renders the code spans, links, bold, and italics of escaped text
+func inline(text string) string {
+	// Code spans are split off first so their content is never formatted
+	parts := strings.Split(text, "`")
+	if len(parts)%2 == 0 {
+		// An unmatched backtick is literal
+		parts[len(parts)-2] += "`" + parts[len(parts)-1]
+		parts = parts[:len(parts)-1]
+	}
+
+	var b strings.Builder
+	for i, part := range parts {
+		if i%2 == 1 {
+			b.WriteString("<code>" + html.EscapeString(part) + "</code>")
+			continue
+		}
+		escaped := html.EscapeString(part)
+		escaped = linkPattern.ReplaceAllStringFunc(escaped, func(match string) string {
+			groups := linkPattern.FindStringSubmatch(match)
+			if !safeURL(html.UnescapeString(groups[2])) {
+				return groups[1]
+			}
+			return `<a href="` + groups[2] + `" rel="nofollow noopener" target="_blank">` + groups[1] + "</a>"
+		})
+		escaped = boldPattern.ReplaceAllString(escaped, "<strong>$1</strong>")
+		escaped = italicPattern.ReplaceAllString(escaped, "<em>$1</em>")
+		b.WriteString(escaped)
+	}
+	return b.String()
+}
+
+// safeURL checks if a link URL is http, https, mailto, or relative, rather than e.g. javascript:
+func safeURL(href string) bool {
+	u, err := url.Parse(href)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
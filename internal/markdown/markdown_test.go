@@ -0,0 +1,30 @@
+package markdown
+
+import (
+	"testing"
+)
+
+// TestRender tests rendering markdown as safe HTML
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"paragraphs", "First line\nsame paragraph\n\nSecond", "<p>First line\nsame paragraph</p>\n<p>Second</p>"},
+		{"inline", "Use **bold**, *italics*, and `a < b`", "<p>Use <strong>bold</strong>, <em>italics</em>, and <code>a &lt; b</code></p>"},
+		{"heading", "## Errors", "<h5>Errors</h5>"},
+		{"lists", "- one\n- two\n\n1. first", "<ul>\n<li>one</li>\n<li>two</li>\n</ul>\n<ol>\n<li>first</li>\n</ol>"},
+		{"code block", "```json\n{\"a\": \"<b>\"}\n```", "<pre><code>{&#34;a&#34;: &#34;&lt;b&gt;&#34;}</code></pre>"},
+		{"quote", "> Note", "<blockquote>\n<p>Note</p>\n</blockquote>"},
+		{"link", "[docs](https://example.com?a=1&b=2)", `<p><a href="https://example.com?a=1&amp;b=2" rel="nofollow noopener" target="_blank">docs</a></p>`},
+		{"unsafe link", "[click](javascript:alert(1))", "<p>click)</p>"},
+		{"raw html", `<img src=x onerror="alert(1)">`, "<p>&lt;img src=x onerror=&#34;alert(1)&#34;&gt;</p>"},
+	}
+
+	for _, test := range tests {
+		if got := string(Render(test.text)); got != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, got)
+		}
+	}
+}
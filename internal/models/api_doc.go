@@ -1,35 +1,445 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
 	"time"
 )
 
 // APIDocRequest represents a request to scrape an API documentation
 type APIDocRequest struct {
-	URL         string `json:"url" binding:"required"`
-	Description string `json:"description"`
+	URL          string `json:"url" binding:"required"`
+	Description  string `json:"description"`
+	CredentialID string `json:"credential_id"` // stored credential to scrape with
+	Format       string `json:"format"`        // parse as openapi, swagger, asyncapi, html, json, or yaml instead of detecting the format
+}
+
+// DefaultWorkspace is the workspace of requests that don't name one
+const DefaultWorkspace = "default"
+
+// Workspace isolates the docs, watches, and notifications of a team or client
+type Workspace struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // APIDoc represents a scraped API documentation
 type APIDoc struct {
 	ID          string    `json:"id"`
+	Workspace   string    `json:"workspace,omitempty"`
 	URL         string    `json:"url"`
+	SpecURL     string    `json:"spec_url,omitempty"` // machine-readable spec discovered on the doc's HTML page and parsed instead
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
+	Summary     string    `json:"summary,omitempty"` // short description for listings, when the description is long
 	Version     string    `json:"version"`
+	Provider    string    `json:"provider,omitempty"` // organization that provides the API
+	IconURL     string    `json:"icon_url,omitempty"` // provider's logo or the site's icon, found when the doc was scraped
+	MetadataSources map[string]string `json:"metadata_sources,omitempty"` // where in an HTML page the title, description, provider, and version were found, e.g. og:title
+	Servers     []string   `json:"servers,omitempty"` // base URLs the API is served from
+	AuthTypes   []string   `json:"auth_types,omitempty"` // apiKey, basic, bearer, oauth2, openIdConnect
+	AuthSchemes []AuthScheme `json:"auth_schemes,omitempty"` // how to authenticate, from the spec's security schemes or the headers carrying credentials
 	Endpoints   []Endpoint `json:"endpoints"`
+	Schemas     map[string]string `json:"schemas,omitempty"` // reusable schemas by name, as JSON with $refs kept
+	Source      *Source    `json:"source,omitempty"`
+	CredentialID string    `json:"credential_id,omitempty"` // stored credential used to scrape the doc
+	Format      string    `json:"format,omitempty"` // format the doc was forced to be parsed as, kept when it's refreshed
+	RawSource   *RawSource `json:"-"` // content the doc was just scraped from, handed to the source archive; never stored with the doc
+	Encoding    string    `json:"encoding,omitempty"` // original character encoding of the scraped content, e.g. shift_jis
+	Language    string    `json:"language,omitempty"` // ISO 639-1 code of the language the doc is written in
+	TranslatedDescription string `json:"translated_description,omitempty"` // description in the catalog's language
+	Redactions  []Redaction `json:"redactions,omitempty"` // secrets and personal data redacted from the doc's examples
+	Violations  []PolicyViolation `json:"violations,omitempty"` // governance policies the doc violates, checked when it's saved
+	Lifecycle   string    `json:"lifecycle,omitempty"` // design, beta, ga, deprecated, or retired
+	Owner       *Owner    `json:"owner,omitempty"` // team responsible for the API
+	RateLimits  []RateLimit `json:"rate_limits,omitempty"` // documented limits on the rate of requests
+	SLAs        []SLA     `json:"slas,omitempty"` // documented service level commitments
+	Pricing     *Pricing  `json:"pricing,omitempty"` // pricing tiers of vendor APIs, when the docs mention them
+	Status      string    `json:"status,omitempty"` // draft, in_review, or published; empty for docs published before the approval workflow
+	SubmittedBy string    `json:"submitted_by,omitempty"` // ID of the user who scraped the doc
+	Review      *Review   `json:"review,omitempty"` // latest approval or rejection
+	Revision    int       `json:"revision,omitempty"` // counts the saves that changed the doc; set by storage
+	Digest      string    `json:"-"` // hash of the doc's content at its revision; set by storage
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// AuthScheme is a way of authenticating requests to an API
+type AuthScheme struct {
+	Name         string      `json:"name"`                    // name of the security scheme in the spec, or of the header carrying credentials
+	Type         string      `json:"type"`                    // apiKey, basic, bearer, oauth2, openIdConnect, mutualTLS, or another HTTP scheme
+	In           string      `json:"in,omitempty"`            // header, query, or cookie, for API keys
+	Param        string      `json:"param,omitempty"`         // header, query parameter, or cookie carrying the API key
+	BearerFormat string      `json:"bearer_format,omitempty"` // e.g. JWT
+	OpenIDConnectURL string  `json:"openid_connect_url,omitempty"`
+	Flows        []OAuthFlow `json:"flows,omitempty"`         // OAuth2 flows
+	Description  string      `json:"description,omitempty"`
+	Summary      string      `json:"summary"`                 // how to authenticate, e.g. "Send an API key in the X-Api-Key header"
+}
+
+// OAuthFlow is an OAuth2 flow of obtaining access tokens
+type OAuthFlow struct {
+	Flow             string   `json:"flow"`                        // authorizationCode, clientCredentials, implicit, or password
+	AuthorizationURL string   `json:"authorization_url,omitempty"` // for the authorizationCode and implicit flows
+	TokenURL         string   `json:"token_url,omitempty"`         // for the authorizationCode, clientCredentials, and password flows
+	RefreshURL       string   `json:"refresh_url,omitempty"`
+	Scopes           []string `json:"scopes,omitempty"`
+}
+
+// RateLimit is a documented limit on the rate of requests to an API or one of its endpoints
+type RateLimit struct {
+	Requests int    `json:"requests"`
+	Period   string `json:"period,omitempty"`   // second, minute, hour, day, or month; empty when not documented
+	Endpoint string `json:"endpoint,omitempty"` // method and path the limit applies to; empty for the whole API
+	Source   string `json:"source"`             // spec for x-ratelimit extensions, text for statements in the docs
+	Text     string `json:"text,omitempty"`     // statement the limit was read from
+}
+
+// SLA is a documented service level commitment, such as an uptime percentage
+type SLA struct {
+	Uptime float64 `json:"uptime,omitempty"` // committed availability in percent, e.g. 99.9
+	Text   string  `json:"text"`             // statement the commitment was read from
+}
+
+// Pricing is the pricing information found in the docs of a vendor API
+type Pricing struct {
+	FreeTier       bool     `json:"free_tier"`
+	FreeTierLimits []string `json:"free_tier_limits,omitempty"` // statements of what the free tier includes, e.g. "1,000 requests per month free"
+	Plans          []Plan   `json:"plans,omitempty"`
+}
+
+// Plan is a pricing plan of a vendor API
+type Plan struct {
+	Name  string `json:"name"`
+	Price string `json:"price,omitempty"` // e.g. $29/month, when the docs state it
+}
+
+// HasFreeTier checks if the docs of an API mention a free tier
+func (d *APIDoc) HasFreeTier() bool {
+	return d.Pricing != nil && d.Pricing.FreeTier
+}
+
+// Owner is the team responsible for an API and how to reach it
+type Owner struct {
+	Team       string `json:"team"`
+	Email      string `json:"email,omitempty"`
+	Slack      string `json:"slack,omitempty"`      // channel, e.g. #payments
+	Escalation string `json:"escalation,omitempty"` // who to page, e.g. a PagerDuty service or on-call rotation
+}
+
+// Lifecycle stages of APIs, from first design to retirement
+const (
+	LifecycleDesign     = "design"
+	LifecycleBeta       = "beta"
+	LifecycleGA         = "ga"
+	LifecycleDeprecated = "deprecated"
+	LifecycleRetired    = "retired"
+)
+
+// Lifecycles lists the lifecycle stages in order
+var Lifecycles = []string{LifecycleDesign, LifecycleBeta, LifecycleGA, LifecycleDeprecated, LifecycleRetired}
+
+// ValidLifecycle checks if a lifecycle stage exists; the empty stage is for docs without one
+func ValidLifecycle(lifecycle string) bool {
+	if lifecycle == "" {
+		return true
+	}
+	for _, stage := range Lifecycles {
+		if stage == lifecycle {
+			return true
+		}
+	}
+	return false
+}
+
+// Publication statuses of docs in the approval workflow
+const (
+	StatusDraft     = "draft"
+	StatusInReview  = "in_review"
+	StatusPublished = "published"
+)
+
+// Published checks if a doc appears in the catalog; docs without a status are published
+func (d *APIDoc) Published() bool {
+	return d.Status == "" || d.Status == StatusPublished
+}
+
+// Review records a reviewer's decision on a doc submitted for review
+type Review struct {
+	Reviewer   string    `json:"reviewer,omitempty"` // ID of the reviewing user
+	Approved   bool      `json:"approved"`
+	Comment    string    `json:"comment,omitempty"`
+	ReviewedAt time.Time `json:"reviewed_at"`
+}
+
+// Redaction reports a kind of secret or personal data redacted from a doc, without the redacted value
+type Redaction struct {
+	Kind     string `json:"kind"`     // bearer_token, aws_access_key, email, header, or a configured pattern's kind
+	Location string `json:"location"` // where in the doc, e.g. "POST /users example 1 request body"
+	Count    int    `json:"count"`
+}
+
+// PolicyViolation is a governance policy a doc violates
+type PolicyViolation struct {
+	Policy   string `json:"policy"`
+	Severity string `json:"severity"` // error or warning
+	Message  string `json:"message"`
+}
+
+// APIDocVersion is a snapshot of an API doc recorded whenever its endpoints change
+type APIDocVersion struct {
+	Number    int       `json:"number"`
+	Doc       *APIDoc   `json:"doc"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Source records where a synced API doc came from
+type Source struct {
+	Type       string `json:"type"` // git, kubernetes, gateway
+	Repository string `json:"repository,omitempty"`
+	Service    string `json:"service,omitempty"` // namespace/name for kubernetes sources, API ID for gateways
+	Gateway    string `json:"gateway,omitempty"` // kong, aws, apigee
+	Path       string `json:"path,omitempty"`
+	Revision   string `json:"revision,omitempty"` // commit SHA for git sources
+}
+
 // Endpoint represents an API endpoint
 type Endpoint struct {
-	Path        string      `json:"path"`
+	ID          string      `json:"id,omitempty"` // stable ID derived from the method and path, see EndpointID
+	Kind        string      `json:"kind,omitempty"` // request, webhook, callback, or event; empty for endpoints parsed before kinds
+	Path        string      `json:"path"` // path, or the name of a webhook or the channel of an event
 	Method      string      `json:"method"`
 	Summary     string      `json:"summary"`
+	TranslatedSummary string `json:"translated_summary,omitempty"` // summary in the catalog's language
 	Description string      `json:"description"`
+	Tags        []string    `json:"tags,omitempty"`
+	Deprecated  bool        `json:"deprecated,omitempty"`
 	Parameters  []Parameter `json:"parameters"`
+	RequestBodies []RequestBody `json:"request_bodies,omitempty"` // the request body in each content type the endpoint accepts
 	Responses   []Response  `json:"responses"`
+	Examples    []Example   `json:"examples,omitempty"`
+	OperationID string      `json:"operation_id,omitempty"`
+	Links       []Link      `json:"links,omitempty"`     // operations fed by values of this endpoint's responses
+	Callbacks   []Callback  `json:"callbacks,omitempty"` // requests the API makes back to the client
+	Pagination  *Pagination `json:"pagination,omitempty"` // how to iterate over the pages of a list endpoint
+	Safety      string      `json:"safety,omitempty"` // safe, idempotent, or unsafe to call, see the Safety constants
+	IdempotencyKey string   `json:"idempotency_key,omitempty"` // header making retries of the endpoint idempotent, e.g. Idempotency-Key
+	Protocol    string      `json:"protocol,omitempty"` // websocket or sse for streaming endpoints; empty for plain HTTP
+	Messages    []Message   `json:"messages,omitempty"` // messages exchanged over a WebSocket, SSE stream, or channel
+}
+
+// Protocols of streaming endpoints
+const (
+	ProtocolWebSocket = "websocket" // a WebSocket opened at a ws:// or wss:// URL
+	ProtocolSSE       = "sse"       // a stream of server-sent events, served as text/event-stream
+)
+
+// Directions of messages, from the client's side
+const (
+	MessageSend    = "send"    // the client sends the message to the API
+	MessageReceive = "receive" // the API sends the message to the client
+)
+
+// Message is a message exchanged over a streaming endpoint or channel
+type Message struct {
+	Direction string `json:"direction"`         // send or receive
+	Name      string `json:"name,omitempty"`    // e.g. the event type of an SSE stream
+	Summary   string `json:"summary,omitempty"`
+	Schema    string `json:"schema,omitempty"`  // JSON schema of the payload as string
+	Example   string `json:"example,omitempty"` // example payload
+}
+
+// Safety classes of endpoints, telling tools what they may call automatically
+const (
+	SafetySafe       = "safe"       // doesn't change anything, e.g. GET; can be called and retried freely
+	SafetyIdempotent = "idempotent" // repeating the call has the effect of calling once, e.g. PUT and DELETE; can be retried
+	SafetyUnsafe     = "unsafe"     // may have a new effect each call, e.g. POST without an idempotency key
+)
+
+// Kinds of endpoints
+const (
+	EndpointRequest  = "request"  // a request the client sends to the API
+	EndpointWebhook  = "webhook"  // a request the API sends to a URL the client registered, e.g. OpenAPI 3.1 webhooks
+	EndpointCallback = "callback" // a request the API sends to a URL the client passed in an earlier request
+	EndpointEvent    = "event"    // a message published or subscribed to on a channel, e.g. AsyncAPI operations
+)
+
+// EndpointKind returns the kind of the endpoint, a request for endpoints parsed before kinds
+func (e Endpoint) EndpointKind() string {
+	if e.Kind == "" {
+		return EndpointRequest
+	}
+	return e.Kind
+}
+
+// Incoming checks if the API sends the endpoint's requests or messages to the client, rather than the client to the API
+func (e Endpoint) Incoming() bool {
+	return e.Kind != "" && e.Kind != EndpointRequest
+}
+
+// Pagination styles of list endpoints
+const (
+	PaginationPage   = "page"   // page number and size, e.g. page and per_page
+	PaginationOffset = "offset" // offset and limit
+	PaginationCursor = "cursor" // cursor from the previous page, e.g. starting_after or page_token
+	PaginationLink   = "link"   // URL of the next page in the Link response header
+)
+
+// Pagination describes how to iterate over the pages of a list endpoint
+type Pagination struct {
+	Style     string `json:"style"`                // page, offset, cursor, or link
+	PageParam string `json:"page_param,omitempty"` // parameter selecting the page: its number, offset, or cursor
+	SizeParam string `json:"size_param,omitempty"` // parameter setting the page size, e.g. per_page or limit
+	NextField string `json:"next_field,omitempty"` // response field with the next cursor or page URL, e.g. meta.next_cursor
+}
+
+// EndpointID returns the stable ID of the endpoint with a method and path: a hash of both, so
+// the ID survives rescrapes and reordering and can be used in permalinks
+func EndpointID(method, path string) string {
+	sum := sha256.Sum256([]byte(strings.ToUpper(method) + " " + path))
+	return hex.EncodeToString(sum[:6])
+}
+
+// StableID returns the endpoint's ID, deriving it from its method and path when it isn't set
+func (e Endpoint) StableID() string {
+	if e.ID != "" {
+		return e.ID
+	}
+	return EndpointID(e.Method, e.Path)
+}
+
+// ContentDigest returns a hash of what a doc says, leaving out when it was updated and its revision,
+// so saving an unchanged doc again can be told apart from changing it
+func ContentDigest(doc *APIDoc) string {
+	content := *doc
+	content.UpdatedAt, content.Revision, content.Digest = time.Time{}, 0, ""
+	// Docs are plain data, which always encodes
+	data, _ := json.Marshal(content)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// AssignEndpointIDs sets the stable IDs of a doc's endpoints
+func AssignEndpointIDs(doc *APIDoc) {
+	for i := range doc.Endpoints {
+		doc.Endpoints[i].ID = EndpointID(doc.Endpoints[i].Method, doc.Endpoints[i].Path)
+	}
+}
+
+// methodOrder is the order of the methods of a path's endpoints; other methods follow, alphabetically
+var methodOrder = map[string]int{"GET": 1, "HEAD": 2, "POST": 3, "PUT": 4, "PATCH": 5, "DELETE": 6, "OPTIONS": 7, "TRACE": 8}
+
+// parameterOrder is the order of parameters by location; other locations follow, alphabetically
+var parameterOrder = map[string]int{"path": 1, "query": 2, "header": 3, "cookie": 4, "body": 5}
+
+// Canonicalize puts a doc's endpoints in a deterministic order, by path, then method, then kind, and
+// their parameters, request bodies, and responses too. Parsers may list them in map iteration order,
+// so without this two scrapes of the same spec could differ in order alone.
+func Canonicalize(doc *APIDoc) {
+	sort.SliceStable(doc.Endpoints, func(i, j int) bool {
+		a, b := doc.Endpoints[i], doc.Endpoints[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Method != b.Method {
+			return rankedBefore(methodOrder, strings.ToUpper(a.Method), strings.ToUpper(b.Method))
+		}
+		return a.Kind < b.Kind
+	})
+	for i := range doc.Endpoints {
+		canonicalizeEndpoint(&doc.Endpoints[i])
+	}
+}
+
+// canonicalizeEndpoint orders the parameters of an endpoint by location, path parameters as they appear
+// in the path and others by name; its request bodies by content type; and its responses by status code,
+// with the default response last
+func canonicalizeEndpoint(endpoint *Endpoint) {
+	sort.SliceStable(endpoint.Parameters, func(i, j int) bool {
+		a, b := endpoint.Parameters[i], endpoint.Parameters[j]
+		if a.In != b.In {
+			return rankedBefore(parameterOrder, a.In, b.In)
+		}
+		if a.In == "path" {
+			ai, bi := strings.Index(endpoint.Path, "{"+a.Name+"}"), strings.Index(endpoint.Path, "{"+b.Name+"}")
+			if ai != bi {
+				return ai < bi
+			}
+		}
+		return a.Name < b.Name
+	})
+	sort.SliceStable(endpoint.RequestBodies, func(i, j int) bool {
+		return endpoint.RequestBodies[i].ContentType < endpoint.RequestBodies[j].ContentType
+	})
+	sort.SliceStable(endpoint.Responses, func(i, j int) bool {
+		a, b := endpoint.Responses[i], endpoint.Responses[j]
+		if a.StatusCode != b.StatusCode {
+			return a.StatusCode != 0 && (b.StatusCode == 0 || a.StatusCode < b.StatusCode)
+		}
+		return a.ContentType < b.ContentType
+	})
+}
+
+// rankedBefore checks if a comes before b in an order of ranks, unranked values following alphabetically
+func rankedBefore(ranks map[string]int, a, b string) bool {
+	ra, aRanked := ranks[a]
+	rb, bRanked := ranks[b]
+	switch {
+	case aRanked && bRanked:
+		return ra < rb
+	case aRanked != bRanked:
+		return aRanked
+	}
+	return a < b
+}
+
+// Link describes how values from an endpoint's response feed another operation (an OpenAPI 3 link)
+type Link struct {
+	Name         string            `json:"name"`
+	StatusCode   int               `json:"status_code"` // response the link starts from; 0 for default
+	OperationID  string            `json:"operation_id,omitempty"`
+	OperationRef string            `json:"operation_ref,omitempty"`
+	Method       string            `json:"method,omitempty"` // target endpoint, when found in the doc
+	Path         string            `json:"path,omitempty"`
+	Parameters   map[string]string `json:"parameters,omitempty"` // target parameter to runtime expression, e.g. $response.body#/id
+	Description  string            `json:"description,omitempty"`
+}
+
+// Callback is a request the API makes to a URL supplied by the client (an OpenAPI 3 callback)
+type Callback struct {
+	Name        string `json:"name"`
+	Expression  string `json:"expression"` // runtime expression of the URL, e.g. {$request.body#/callbackUrl}
+	Method      string `json:"method"`
+	Summary     string `json:"summary,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Example is a recorded request/response pair for an endpoint
+type Example struct {
+	Source     string          `json:"source"` // proxy, or docs for the code samples of scraped docs
+	Request    ExampleRequest  `json:"request"`
+	Response   ExampleResponse `json:"response"`
+	RecordedAt time.Time       `json:"recorded_at"`
+}
+
+// ExampleRequest is the request half of a recorded example
+type ExampleRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// ExampleResponse is the response half of a recorded example
+type ExampleResponse struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
 }
 
 // Parameter represents an API endpoint parameter
@@ -39,13 +449,242 @@ type Parameter struct {
 	Required    bool   `json:"required"`
 	Type        string `json:"type"`
 	Description string `json:"description"`
+	Example     string `json:"example,omitempty"` // example value, e.g. from a documented URL
+	Auth        string `json:"auth,omitempty"`    // for headers carrying credentials, the auth type: apiKey, basic, or bearer
+}
+
+// RequestBody is the request body of an endpoint in one of the content types it accepts
+type RequestBody struct {
+	ContentType string `json:"content_type"`      // e.g. application/json, application/x-www-form-urlencoded, or multipart/form-data
+	Required    bool   `json:"required,omitempty"`
+	Schema      string `json:"schema,omitempty"`  // JSON schema as string
+	Example     string `json:"example,omitempty"` // example body in the content type, documented or generated from the schema
 }
 
 // Response represents an API endpoint response
 type Response struct {
-	StatusCode  int    `json:"status_code"`
-	Description string `json:"description"`
-	Schema      string `json:"schema,omitempty"` // JSON schema as string
+	StatusCode     int    `json:"status_code"`
+	Description    string `json:"description"`
+	Schema         string `json:"schema,omitempty"`          // JSON schema as string
+	SchemaName     string `json:"schema_name,omitempty"`     // name of the reusable schema returned, e.g. Order
+	SchemaInferred bool   `json:"schema_inferred,omitempty"` // schema was inferred from recorded examples
+	Example        string `json:"example,omitempty"`         // documented example body
+	ContentType    string `json:"content_type,omitempty"`    // content type of the example, e.g. application/json
+}
+
+// SourceCheck records whether the source URL of a doc was still reachable when last checked
+type SourceCheck struct {
+	DocID      string     `json:"doc_id"`
+	Workspace  string     `json:"workspace,omitempty"`
+	Title      string     `json:"title"`
+	URL        string     `json:"url"`
+	StatusCode int        `json:"status_code,omitempty"` // 0 when the request failed
+	Error      string     `json:"error,omitempty"`       // why the request failed or the status isn't OK
+	Stale      bool       `json:"stale"`                 // the source is gone: it answered 404 or 410
+	StaleSince *time.Time `json:"stale_since,omitempty"` // first check that found the source gone, in the current run
+	CheckedAt  time.Time  `json:"checked_at"`
+}
+
+// DriftFinding records a live response that did not match the documented response schema
+type DriftFinding struct {
+	ID         string    `json:"id"`
+	DocID      string    `json:"doc_id"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"` // documented path template
+	StatusCode int       `json:"status_code"`
+	Errors     []string  `json:"errors"`
+	ObservedAt time.Time `json:"observed_at"`
 }
 
+// ScrapeRecord records the outcome of a single scrape of a doc URL
+type ScrapeRecord struct {
+	Workspace       string            `json:"workspace,omitempty"`
+	URL             string            `json:"url"`
+	DocID           string            `json:"doc_id,omitempty"` // empty when a first scrape failed
+	Success         bool              `json:"success"`
+	Error           string            `json:"error,omitempty"`
+	Endpoints       int               `json:"endpoints"`                  // number of endpoints parsed; 0 for failures
+	Encoding        string            `json:"encoding,omitempty"`         // original character encoding of the scraped content
+	MetadataSources map[string]string `json:"metadata_sources,omitempty"` // where the doc's metadata was found, see APIDoc
+	Instance        string            `json:"instance,omitempty"`         // instance of the service that ran the scrape
+	ScrapedAt       time.Time         `json:"scraped_at"`
+}
+
+// View kinds
+const (
+	ViewKindPage = "page" // a UI page view
+	ViewKindAPI  = "api"  // an API read
+)
+
+// ViewCounts aggregates the views of a doc. Only counts are kept, never who viewed the doc.
+type ViewCounts struct {
+	DocID     string         `json:"doc_id"`
+	PageViews int            `json:"page_views"`
+	APIReads  int            `json:"api_reads"`
+	Endpoints map[string]int `json:"endpoints,omitempty"` // "METHOD /path" to page views
+	Daily     map[string]int `json:"daily,omitempty"`     // YYYY-MM-DD to views, for recent days
+}
+
+// Watch represents a subscription to changes of a single API doc
+type Watch struct {
+	ID         string    `json:"id"`
+	Workspace  string    `json:"workspace,omitempty"`
+	DocID      string    `json:"doc_id"`
+	Subscriber string    `json:"subscriber"`
+	Events     []string  `json:"events"`             // notification event types, defaults to changed and scrape_failure
+	Channels   []string  `json:"channels,omitempty"` // configured notification channels to deliver to
+	CreatedAt  time.Time `json:"created_at"`
+}
 
+// Notification represents an in-app notification for a subscriber
+type Notification struct {
+	ID         string    `json:"id"`
+	Workspace  string    `json:"workspace,omitempty"`
+	Subscriber string    `json:"subscriber"`
+	DocID      string    `json:"doc_id"`
+	Event      string    `json:"event"`
+	Subject    string    `json:"subject"`
+	Body       string    `json:"body"`
+	Read       bool      `json:"read"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// User is a person or service allowed to use the catalog once users exist
+type User struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Role       string    `json:"role"`                 // viewer, editor, reviewer, admin
+	Workspaces []string  `json:"workspaces,omitempty"` // workspaces the user can access, all when empty
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// APIKey authenticates API requests as a user. Only a hash of the key is stored.
+type APIKey struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name,omitempty"`
+	Prefix    string    `json:"prefix"` // first characters of the key, to tell keys apart
+	Hash      string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Credential types
+const (
+	CredentialBearer = "bearer" // Authorization: Bearer token
+	CredentialBasic  = "basic"  // Authorization: Basic username/password
+	CredentialHeader = "header" // custom header, e.g. X-API-Key
+	CredentialQuery  = "query"  // query parameter, e.g. ?api_key=
+	CredentialCookie = "cookie" // session cookie
+)
+
+// CredentialTypes lists the credential types
+var CredentialTypes = []string{CredentialBearer, CredentialBasic, CredentialHeader, CredentialQuery, CredentialCookie}
+
+// Credential is a stored login for scraping docs behind authentication. The secret
+// is only kept encrypted and never returned.
+type Credential struct {
+	ID         string    `json:"id"`
+	Workspace  string    `json:"workspace,omitempty"`
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	Key        string    `json:"key,omitempty"` // header, query parameter, or cookie name
+	Ciphertext []byte    `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CredentialSecret is the decrypted secret of a credential
+type CredentialSecret struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"` // bearer token, or header, query parameter, or cookie value
+}
+
+// DefaultPolicyDomain is the domain of the scrape policy applying to domains without their own
+const DefaultPolicyDomain = "*"
+
+// ScrapePolicy controls how politely the scraper fetches from a domain and its subdomains
+type ScrapePolicy struct {
+	Domain             string            `json:"domain"`
+	MinIntervalSeconds float64           `json:"min_interval_seconds"` // minimum time between request starts, 0 for none
+	MaxConcurrency     int               `json:"max_concurrency"`      // concurrent requests, 0 for no cap
+	UserAgent          string            `json:"user_agent,omitempty"`
+	Headers            map[string]string `json:"headers,omitempty"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+}
+
+// Schema is a reusable schema of a doc in the catalog-wide schema catalog
+type Schema struct {
+	ID          string        `json:"id"` // doc ID and schema name, e.g. openapi-1700000000:Invoice
+	Workspace   string        `json:"workspace,omitempty"`
+	DocID       string        `json:"doc_id"`
+	DocTitle    string        `json:"doc_title"`
+	Name        string        `json:"name"`
+	Type        string        `json:"type,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Fields      []SchemaField `json:"fields"`
+	Definition  string        `json:"definition"` // JSON schema, with $refs kept
+	UsedBy      []SchemaUse   `json:"used_by"`
+}
+
+// SchemaField is a property of a schema
+type SchemaField struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"` // JSON schema type, a referenced schema's name, or array of either, e.g. array<LineItem>
+	Required    bool   `json:"required,omitempty"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema,omitempty"` // name of the referenced schema
+}
+
+// SchemaUse is an endpoint response returning a schema, or another schema containing it
+type SchemaUse struct {
+	Method     string `json:"method,omitempty"`
+	Path       string `json:"path,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Schema     string `json:"schema,omitempty"` // containing schema, for nested uses
+}
+
+// Attachment kinds
+const (
+	AttachmentKindLogo = "logo" // provider logo shown with the doc
+	AttachmentKindFile = "file" // supplementary file such as an SLA PDF or architecture diagram
+)
+
+// Attachment is a file attached to a doc. Its content is stored apart from this metadata.
+type Attachment struct {
+	ID          string    `json:"id"`
+	DocID       string    `json:"doc_id"`
+	Kind        string    `json:"kind"`
+	Name        string    `json:"name"` // file name
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RawSource is the content a doc was parsed from, archived as fetched so it can be re-parsed or
+// checked against the doc later. Its content is stored compressed, apart from this metadata.
+type RawSource struct {
+	DocID          string    `json:"doc_id"`
+	Version        int       `json:"version"`                // numbered per doc, oldest first
+	URL            string    `json:"url"`                    // where the content was fetched: the doc's URL, or the spec discovered on its page
+	ContentType    string    `json:"content_type,omitempty"` // as served, with its charset
+	Format         string    `json:"format"`                 // format the content was parsed as, e.g. html or json
+	Size           int64     `json:"size"`                   // bytes as fetched
+	CompressedSize int64     `json:"compressed_size"`        // bytes as stored
+	SHA256         string    `json:"sha256"`
+	FetchedAt      time.Time `json:"fetched_at"`
+	Content        []byte    `json:"-"` // as fetched, before transcoding; only set on the way from the scraper to storage
+}
+
+// IdempotencyRecord remembers the response to a request sent with an Idempotency-Key, so a retry of the
+// request gets the same response instead of repeating it
+type IdempotencyRecord struct {
+	Key         string    `json:"key"`         // scoped to the workspace and caller
+	Fingerprint string    `json:"fingerprint"` // hash of the method, path, and body, to tell a retry from another request reusing the key
+	Status      int       `json:"status"`      // 0 while the request is in progress
+	ContentType string    `json:"content_type"`
+	Body        []byte    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
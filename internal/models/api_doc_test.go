@@ -0,0 +1,75 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestCanonicalize tests that endpoints listed in different orders end up in the same order
+func TestCanonicalize(t *testing.T) {
+	endpoints := func() []Endpoint {
+		return []Endpoint{
+			{Path: "/users/{id}", Method: "DELETE"},
+			{Path: "/users", Method: "POST"},
+			{Path: "/users/{id}", Method: "get", Parameters: []Parameter{
+				{Name: "X-Trace", In: "header"},
+				{Name: "id", In: "path"},
+				{Name: "fields", In: "query"},
+				{Name: "expand", In: "query"},
+			}},
+			{Path: "/users", Method: "GET", Responses: []Response{{StatusCode: 0}, {StatusCode: 404}, {StatusCode: 200}}},
+			{Path: "/users", Method: "PURGE"},
+		}
+	}
+	doc := &APIDoc{Endpoints: endpoints()}
+	reversed := &APIDoc{Endpoints: endpoints()}
+	for i, j := 0, len(reversed.Endpoints)-1; i < j; i, j = i+1, j-1 {
+		reversed.Endpoints[i], reversed.Endpoints[j] = reversed.Endpoints[j], reversed.Endpoints[i]
+	}
+
+	Canonicalize(doc)
+	Canonicalize(reversed)
+	if !reflect.DeepEqual(doc, reversed) {
+		t.Errorf("Expected the same order whatever the parsed order, got %+v and %+v", doc.Endpoints, reversed.Endpoints)
+	}
+
+	var order []string
+	for _, endpoint := range doc.Endpoints {
+		order = append(order, endpoint.Method+" "+endpoint.Path)
+	}
+	expected := []string{"GET /users", "POST /users", "PURGE /users", "get /users/{id}", "DELETE /users/{id}"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected endpoints %v, got %v", expected, order)
+	}
+
+	var parameters []string
+	for _, parameter := range doc.Endpoints[3].Parameters {
+		parameters = append(parameters, parameter.Name)
+	}
+	if expected := []string{"id", "expand", "fields", "X-Trace"}; !reflect.DeepEqual(parameters, expected) {
+		t.Errorf("Expected parameters %v, got %v", expected, parameters)
+	}
+
+	responses := doc.Endpoints[0].Responses
+	if responses[0].StatusCode != 200 || responses[1].StatusCode != 404 || responses[2].StatusCode != 0 {
+		t.Errorf("Expected responses by status code with the default last, got %+v", responses)
+	}
+}
+
+// TestContentDigest tests that the digest changes with what a doc says, but not with its update time or revision
+func TestContentDigest(t *testing.T) {
+	doc := &APIDoc{ID: "pets", Title: "Pets", Endpoints: []Endpoint{{Path: "/pets", Method: "GET"}}}
+	digest := ContentDigest(doc)
+
+	saved := *doc
+	saved.UpdatedAt, saved.Revision, saved.Digest = time.Now(), 3, digest
+	if ContentDigest(&saved) != digest {
+		t.Error("Expected the update time and revision to leave the digest unchanged")
+	}
+
+	saved.Lifecycle = "ga"
+	if ContentDigest(&saved) == digest {
+		t.Error("Expected a changed lifecycle to change the digest")
+	}
+}
@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// SlackChannel posts notifications to a Slack incoming webhook
+type SlackChannel struct {
+	WebhookURL string
+}
+
+// Send implements the Channel interface
+func (c *SlackChannel) Send(msg Message) error {
+	return postJSON(c.WebhookURL, map[string]string{
+		"text": "*" + msg.Subject + "*\n" + msg.Body,
+	})
+}
+
+// TeamsChannel posts notifications to a Microsoft Teams incoming webhook
+type TeamsChannel struct {
+	WebhookURL string
+}
+
+// Send implements the Channel interface
+func (c *TeamsChannel) Send(msg Message) error {
+	return postJSON(c.WebhookURL, map[string]string{
+		"title": msg.Subject,
+		"text":  strings.ReplaceAll(msg.Body, "\n", "<br>"),
+	})
+}
+
+// EmailChannel sends notifications over SMTP
+type EmailChannel struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// Send implements the Channel interface
+func (c *EmailChannel) Send(msg Message) error {
+	var auth smtp.Auth
+	if c.Username != "" {
+		auth = smtp.PlainAuth("", c.Username, c.Password, c.Host)
+	}
+
+	port := c.Port
+	if port == 0 {
+		port = 587
+	}
+
+	return smtp.SendMail(fmt.Sprintf("%s:%d", c.Host, port), auth, c.From, c.To, c.message(msg))
+}
+
+// message formats a notification as an email. The subject comes from doc titles, so line breaks in it are
+// replaced to keep it from adding headers, and it's encoded as an RFC 2047 word if it isn't plain ASCII.
+func (c *EmailChannel) message(msg Message) []byte {
+	subject := strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(msg.Subject)
+	return []byte(fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s",
+		c.From, strings.Join(c.To, ", "), mime.QEncoding.Encode("utf-8", subject), msg.Body))
+}
+
+// postJSON posts a JSON payload to a webhook
+func postJSON(url string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := webhookClient.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"bytes"
+	"mime"
+	"net/mail"
+	"testing"
+)
+
+// TestEmailMessage tests that the subject of an email can't add headers and is encoded when it isn't ASCII
+func TestEmailMessage(t *testing.T) {
+	channel := &EmailChannel{From: "catalog@example.com", To: []string{"team@example.com", "lead@example.com"}}
+
+	tests := []struct {
+		subject  string
+		expected string
+	}{
+		{"Breaking changes in Pets", "Breaking changes in Pets"},
+		{"Pets\r\nBcc: victim@example.com", "Pets Bcc: victim@example.com"},
+		{"Pets\nBcc: victim@example.com\rX-Spam: no", "Pets Bcc: victim@example.com X-Spam: no"},
+		{"Zahlungs-API geändert", "Zahlungs-API geändert"},
+	}
+	for _, test := range tests {
+		message, err := mail.ReadMessage(bytes.NewReader(channel.message(Message{Subject: test.subject, Body: "Details"})))
+		if err != nil {
+			t.Fatalf("Failed to read email for %q: %v", test.subject, err)
+		}
+		if len(message.Header) != 4 || message.Header.Get("Bcc") != "" || message.Header.Get("X-Spam") != "" {
+			t.Errorf("Expected only the From, To, Subject, and Content-Type headers for %q, got %v", test.subject, message.Header)
+		}
+		subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+		if err != nil || subject != test.expected {
+			t.Errorf("Expected subject %q, got %q, %v", test.expected, subject, err)
+		}
+		if to := message.Header.Get("To"); to != "team@example.com, lead@example.com" {
+			t.Errorf("Expected both recipients, got %q", to)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"universal_api/internal/diff"
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// Event types that rules can match
+const (
+	EventBreakingChange = "breaking_change"
+	EventNewEndpoints   = "new_endpoints"
+	EventChanged        = "changed"
+	EventScrapeFailure  = "scrape_failure"
+)
+
+// Event describes something that happened to a doc during refresh
+type Event struct {
+	Type  string
+	Doc   *models.APIDoc
+	Diff  *diff.Diff // set for change events
+	Error error      // set for scrape failures
+}
+
+// Message is a rendered notification
+type Message struct {
+	Subject string
+	Body    string
+}
+
+// Channel delivers notifications to a destination
+type Channel interface {
+	Send(msg Message) error
+}
+
+// Config holds the configured channels and rules
+type Config struct {
+	Channels []ChannelConfig `json:"channels"`
+	Rules    []Rule          `json:"rules"`
+}
+
+// ChannelConfig configures a single notification channel
+type ChannelConfig struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // slack, teams, email
+
+	// Slack and Teams
+	WebhookURL string `json:"webhook_url,omitempty"`
+
+	// Email
+	SMTPHost string   `json:"smtp_host,omitempty"`
+	SMTPPort int      `json:"smtp_port,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       []string `json:"to,omitempty"`
+}
+
+// Rule sends events of a type to channels, optionally restricted to specific docs
+type Rule struct {
+	Event    string   `json:"event"`
+	Channels []string `json:"channels"`
+	Docs     []string `json:"docs,omitempty"` // empty matches every doc
+}
+
+// EventTypes lists the event types
+var EventTypes = []string{EventBreakingChange, EventNewEndpoints, EventChanged, EventScrapeFailure}
+
+// DefaultWatchEvents are the events a watch receives when it doesn't list any
+var DefaultWatchEvents = []string{EventChanged, EventScrapeFailure}
+
+// Notifier evaluates rules and watches against events and delivers matching notifications
+type Notifier struct {
+	channels map[string]Channel
+	rules    []Rule
+	watches  storage.WatchStorage
+}
+
+// LoadConfig reads a notifications config file
+func LoadConfig(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notifications config: %w", err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse notifications config: %w", err)
+	}
+
+	return &config, nil
+}
+
+// NewNotifier creates a Notifier from a config. Watches of the affected doc are
+// notified in addition to the configured rules.
+func NewNotifier(config *Config, watches storage.WatchStorage) (*Notifier, error) {
+	n := &Notifier{
+		channels: make(map[string]Channel),
+		rules:    config.Rules,
+		watches:  watches,
+	}
+
+	for _, channelConfig := range config.Channels {
+		channel, err := newChannel(channelConfig)
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: %w", channelConfig.Name, err)
+		}
+		n.channels[channelConfig.Name] = channel
+	}
+
+	for _, rule := range config.Rules {
+		for _, name := range rule.Channels {
+			if _, ok := n.channels[name]; !ok {
+				return nil, fmt.Errorf("rule for %s references unknown channel %s", rule.Event, name)
+			}
+		}
+	}
+
+	return n, nil
+}
+
+// newChannel creates the channel described by the config
+func newChannel(config ChannelConfig) (Channel, error) {
+	switch config.Type {
+	case "slack":
+		return &SlackChannel{WebhookURL: config.WebhookURL}, nil
+	case "teams":
+		return &TeamsChannel{WebhookURL: config.WebhookURL}, nil
+	case "email":
+		return &EmailChannel{
+			Host:     config.SMTPHost,
+			Port:     config.SMTPPort,
+			Username: config.Username,
+			Password: config.Password,
+			From:     config.From,
+			To:       config.To,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported channel type: %s", config.Type)
+	}
+}
+
+// HasChannel checks if a channel with the given name is configured
+func (n *Notifier) HasChannel(name string) bool {
+	_, ok := n.channels[name]
+	return ok
+}
+
+// Notify delivers the event to every channel of every matching rule and watch,
+// and records in-app notifications for the doc's watchers.
+// Delivery happens in the background so refreshes aren't slowed down by slow channels.
+func (n *Notifier) Notify(event Event) {
+	if n == nil {
+		return
+	}
+
+	msg := Render(event)
+
+	targets := make(map[string]bool)
+	for _, rule := range n.rules {
+		if rule.matches(event) {
+			for _, name := range rule.Channels {
+				targets[name] = true
+			}
+		}
+	}
+
+	for _, watch := range n.matchingWatches(event) {
+		notification := &models.Notification{
+			ID:         fmt.Sprintf("notification-%d-%s", time.Now().UnixNano(), watch.ID),
+			Workspace:  watch.Workspace,
+			Subscriber: watch.Subscriber,
+			DocID:      watch.DocID,
+			Event:      event.Type,
+			Subject:    msg.Subject,
+			Body:       msg.Body,
+			CreatedAt:  time.Now(),
+		}
+		if err := n.watches.SaveNotification(notification); err != nil {
+			log.Printf("Failed to save notification for %s: %v", watch.Subscriber, err)
+		}
+
+		for _, name := range watch.Channels {
+			if n.HasChannel(name) {
+				targets[name] = true
+			}
+		}
+	}
+
+	for name := range targets {
+		go func(name string, channel Channel) {
+			if err := channel.Send(msg); err != nil {
+				log.Printf("Failed to send notification to %s: %v", name, err)
+			}
+		}(name, n.channels[name])
+	}
+}
+
+// matchingWatches returns the watches of the event's doc that subscribe to the event type
+func (n *Notifier) matchingWatches(event Event) []*models.Watch {
+	if n.watches == nil || event.Doc == nil {
+		return nil
+	}
+
+	watches, err := n.watches.GetWatchesByDoc(event.Doc.ID)
+	if err != nil {
+		log.Printf("Failed to get watches for %s: %v", event.Doc.ID, err)
+		return nil
+	}
+
+	var matching []*models.Watch
+	for _, watch := range watches {
+		events := watch.Events
+		if len(events) == 0 {
+			events = DefaultWatchEvents
+		}
+		for _, eventType := range events {
+			if eventType == event.Type {
+				matching = append(matching, watch)
+				break
+			}
+		}
+	}
+	return matching
+}
+
+// matches checks if the rule applies to the event
+func (r Rule) matches(event Event) bool {
+	if r.Event != event.Type {
+		return false
+	}
+	if len(r.Docs) == 0 {
+		return true
+	}
+	for _, id := range r.Docs {
+		if event.Doc != nil && event.Doc.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Events returns the events raised by a refresh that produced the given diff
+func Events(doc *models.APIDoc, d *diff.Diff) []Event {
+	var events []Event
+	if !d.HasChanges() {
+		return events
+	}
+
+	events = append(events, Event{Type: EventChanged, Doc: doc, Diff: d})
+	if len(d.Added) > 0 {
+		events = append(events, Event{Type: EventNewEndpoints, Doc: doc, Diff: d})
+	}
+	if d.IsBreaking() {
+		events = append(events, Event{Type: EventBreakingChange, Doc: doc, Diff: d})
+	}
+	return events
+}
+
+// Render formats an event as a notification message
+func Render(event Event) Message {
+	title := "Unknown API"
+	if event.Doc != nil {
+		title = event.Doc.Title
+	}
+
+	var body strings.Builder
+	switch event.Type {
+	case EventScrapeFailure:
+		body.WriteString(fmt.Sprintf("Refreshing %s failed: %v\n", title, event.Error))
+		return Message{Subject: "Scrape failed: " + title, Body: body.String()}
+	case EventBreakingChange:
+		body.WriteString(fmt.Sprintf("%s has breaking changes:\n", title))
+		for _, change := range event.Diff.BreakingChanges {
+			body.WriteString("- " + change + "\n")
+		}
+		return Message{Subject: "Breaking changes: " + title, Body: body.String()}
+	case EventNewEndpoints:
+		body.WriteString(fmt.Sprintf("%s has new endpoints:\n", title))
+		for _, ref := range event.Diff.Added {
+			body.WriteString("- " + ref.String() + " " + permalink(event.Doc, ref) + "\n")
+		}
+		return Message{Subject: "New endpoints: " + title, Body: body.String()}
+	default:
+		body.WriteString(fmt.Sprintf("%s changed: %s\n", title, event.Diff.Summary()))
+		for _, change := range event.Diff.Changed {
+			body.WriteString("- " + change.String() + ": " + strings.Join(change.Changes, ", ") + " " + permalink(event.Doc, change.EndpointRef) + "\n")
+		}
+		return Message{Subject: "API changed: " + title, Body: body.String()}
+	}
+}
+
+// permalink returns the API path of an endpoint of a doc, addressed by its stable ID
+func permalink(doc *models.APIDoc, ref diff.EndpointRef) string {
+	if doc == nil {
+		return ""
+	}
+	return "(/api/v1/docs/" + doc.ID + "/endpoints/" + ref.ID + ")"
+}
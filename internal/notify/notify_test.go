@@ -0,0 +1,156 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"universal_api/internal/diff"
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// recordingChannel records the messages sent to it
+type recordingChannel struct {
+	sent chan Message
+}
+
+func (c *recordingChannel) Send(msg Message) error {
+	c.sent <- msg
+	return nil
+}
+
+// received waits for a message sent to the channel, as delivery happens in the background
+func (c *recordingChannel) received(t *testing.T) Message {
+	t.Helper()
+	select {
+	case msg := <-c.sent:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatalf("Expected a message to be sent")
+		return Message{}
+	}
+}
+
+// TestNotifyWatchers tests that the watchers of a doc subscribed to an event are notified in-app and on their channels
+func TestNotifyWatchers(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	now := time.Now()
+	for _, watch := range []*models.Watch{
+		{ID: "watch-1", DocID: "doc-1", Subscriber: "alice", Channels: []string{"team"}, CreatedAt: now},
+		{ID: "watch-2", DocID: "doc-1", Subscriber: "bob", Events: []string{EventBreakingChange}, CreatedAt: now},
+		{ID: "watch-3", DocID: "doc-1", Subscriber: "carol", Events: []string{EventChanged}, CreatedAt: now},
+		{ID: "watch-4", DocID: "doc-2", Subscriber: "dave", CreatedAt: now},
+	} {
+		if err := store.SaveWatch(watch); err != nil {
+			t.Fatalf("Failed to save watch: %v", err)
+		}
+	}
+
+	team := &recordingChannel{sent: make(chan Message, 1)}
+	n := &Notifier{channels: map[string]Channel{"team": team}, watches: store}
+
+	doc := &models.APIDoc{ID: "doc-1", Title: "Pets"}
+	d := &diff.Diff{Added: []diff.EndpointRef{{ID: "ep-1", Method: "GET", Path: "/pets"}}}
+	n.Notify(Event{Type: EventChanged, Doc: doc, Diff: d})
+
+	for subscriber, expected := range map[string]int{"alice": 1, "bob": 0, "carol": 1, "dave": 0} {
+		notifications, err := store.GetNotifications(subscriber)
+		if err != nil {
+			t.Fatalf("Failed to get notifications: %v", err)
+		}
+		if len(notifications) != expected {
+			t.Errorf("Expected %d notifications for %s, got %d", expected, subscriber, len(notifications))
+		}
+		for _, notification := range notifications {
+			if notification.DocID != "doc-1" || notification.Event != EventChanged || notification.Subject != "API changed: Pets" {
+				t.Errorf("Unexpected notification for %s: %+v", subscriber, notification)
+			}
+		}
+	}
+
+	if msg := team.received(t); msg.Subject != "API changed: Pets" {
+		t.Errorf("Expected the change to be sent to the watch's channel, got %q", msg.Subject)
+	}
+}
+
+// TestNotifyRules tests that rules send events of their type to their channels, restricted to their docs
+func TestNotifyRules(t *testing.T) {
+	breaking := &recordingChannel{sent: make(chan Message, 2)}
+	n := &Notifier{
+		channels: map[string]Channel{"breaking": breaking},
+		rules:    []Rule{{Event: EventBreakingChange, Channels: []string{"breaking"}, Docs: []string{"doc-1"}}},
+	}
+
+	d := &diff.Diff{
+		Removed:         []diff.EndpointRef{{Method: "DELETE", Path: "/pets/{id}"}},
+		BreakingChanges: []string{"removed DELETE /pets/{id}"},
+	}
+	other := &models.APIDoc{ID: "doc-2", Title: "Stores"}
+	for _, event := range Events(other, d) {
+		n.Notify(event)
+	}
+	n.Notify(Event{Type: EventChanged, Doc: &models.APIDoc{ID: "doc-1", Title: "Pets"}, Diff: d})
+	n.Notify(Event{Type: EventBreakingChange, Doc: &models.APIDoc{ID: "doc-1", Title: "Pets"}, Diff: d})
+
+	msg := breaking.received(t)
+	if msg.Subject != "Breaking changes: Pets" || !strings.Contains(msg.Body, "removed DELETE /pets/{id}") {
+		t.Errorf("Unexpected message %+v", msg)
+	}
+	select {
+	case msg := <-breaking.sent:
+		t.Errorf("Expected only the breaking change of doc-1 to be sent, got %q", msg.Subject)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestNewNotifierUnknownChannel tests that rules referencing unconfigured channels are rejected
+func TestNewNotifierUnknownChannel(t *testing.T) {
+	config := &Config{
+		Channels: []ChannelConfig{{Name: "ops", Type: "slack", WebhookURL: "http://localhost/hook"}},
+		Rules:    []Rule{{Event: EventChanged, Channels: []string{"ops", "missing"}}},
+	}
+	if _, err := NewNotifier(config, nil); err == nil {
+		t.Errorf("Expected an error for a rule referencing an unknown channel")
+	}
+}
+
+// TestWebhookChannels tests delivering messages to Slack and Teams webhooks
+func TestWebhookChannels(t *testing.T) {
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/failing" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected a JSON payload, got %s", r.Header.Get("Content-Type"))
+		}
+		payload = nil
+		json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	msg := Message{Subject: "API changed: Pets", Body: "Pets changed\n- GET /pets\n"}
+
+	if err := (&SlackChannel{WebhookURL: server.URL}).Send(msg); err != nil {
+		t.Fatalf("Failed to send to Slack: %v", err)
+	}
+	if payload["text"] != "*API changed: Pets*\nPets changed\n- GET /pets\n" {
+		t.Errorf("Unexpected Slack payload %v", payload)
+	}
+
+	if err := (&TeamsChannel{WebhookURL: server.URL}).Send(msg); err != nil {
+		t.Fatalf("Failed to send to Teams: %v", err)
+	}
+	if payload["title"] != "API changed: Pets" || payload["text"] != "Pets changed<br>- GET /pets<br>" {
+		t.Errorf("Unexpected Teams payload %v", payload)
+	}
+
+	if err := (&SlackChannel{WebhookURL: server.URL + "/failing"}).Send(msg); err == nil {
+		t.Errorf("Expected an error when the webhook fails")
+	}
+}
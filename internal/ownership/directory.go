@@ -0,0 +1,136 @@
+package ownership
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"universal_api/internal/models"
+)
+
+// directoryTTL is how long the teams fetched from an external directory are reused
+const directoryTTL = 5 * time.Minute
+
+// Team is a team of the directory with the docs it owns
+type Team struct {
+	Name       string   `json:"name" yaml:"name"`
+	Email      string   `json:"email,omitempty" yaml:"email,omitempty"`
+	Slack      string   `json:"slack,omitempty" yaml:"slack,omitempty"`
+	Escalation string   `json:"escalation,omitempty" yaml:"escalation,omitempty"`
+	URLs       []string `json:"urls,omitempty" yaml:"urls,omitempty"` // URL prefixes of the docs the team owns
+}
+
+// Owner returns the team as the owner of a doc
+func (t *Team) Owner() *models.Owner {
+	return &models.Owner{Team: t.Name, Email: t.Email, Slack: t.Slack, Escalation: t.Escalation}
+}
+
+// Directory lists the teams of an organization
+type Directory interface {
+	Teams() ([]Team, error)
+}
+
+// FileDirectory is a directory of teams loaded from a teams.yaml file
+type FileDirectory struct {
+	teams []Team
+}
+
+// ParseTeams parses a teams.yaml file with a list of teams under "teams"
+func ParseTeams(data []byte) (*FileDirectory, error) {
+	var file struct {
+		Teams []Team `yaml:"teams"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse teams: %w", err)
+	}
+	for i, team := range file.Teams {
+		if team.Name == "" {
+			return nil, fmt.Errorf("team %d has no name", i+1)
+		}
+	}
+	return &FileDirectory{teams: file.Teams}, nil
+}
+
+// Teams returns the teams of the file
+func (d *FileDirectory) Teams() ([]Team, error) {
+	return d.teams, nil
+}
+
+// HTTPDirectory is an external team directory serving its teams as a JSON array at a URL
+type HTTPDirectory struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	teams     []Team
+	fetchedAt time.Time
+}
+
+// NewHTTPDirectory creates a directory fetching its teams from url
+func NewHTTPDirectory(url string) *HTTPDirectory {
+	return &HTTPDirectory{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Teams returns the teams of the directory, fetching them again once they're older than directoryTTL
+func (d *HTTPDirectory) Teams() ([]Team, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.teams != nil && time.Since(d.fetchedAt) < directoryTTL {
+		return d.teams, nil
+	}
+
+	resp, err := d.client.Get(d.url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch teams: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch teams: status code %d", resp.StatusCode)
+	}
+
+	var teams []Team
+	if err := json.NewDecoder(resp.Body).Decode(&teams); err != nil {
+		return nil, fmt.Errorf("failed to parse teams: %w", err)
+	}
+	d.teams = teams
+	d.fetchedAt = time.Now()
+	return teams, nil
+}
+
+// Find returns the team of a directory with a name, ignoring case, or nil if there's none
+func Find(directory Directory, name string) (*Team, error) {
+	teams, err := directory.Teams()
+	if err != nil {
+		return nil, err
+	}
+	for i := range teams {
+		if strings.EqualFold(teams[i].Name, name) {
+			return &teams[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Owning returns the team owning a doc, the one with the longest URL prefix of the doc's URL, or nil if there's none
+func Owning(directory Directory, doc *models.APIDoc) (*Team, error) {
+	teams, err := directory.Teams()
+	if err != nil {
+		return nil, err
+	}
+	var owner *Team
+	longest := 0
+	for i := range teams {
+		for _, prefix := range teams[i].URLs {
+			if strings.HasPrefix(doc.URL, prefix) && len(prefix) > longest {
+				owner = &teams[i]
+				longest = len(prefix)
+			}
+		}
+	}
+	return owner, nil
+}
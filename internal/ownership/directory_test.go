@@ -0,0 +1,57 @@
+package ownership
+
+import (
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+const testTeams = `
+teams:
+  - name: payments
+    email: payments@example.com
+    slack: "#payments"
+    escalation: pagerduty:payments-oncall
+    urls:
+      - https://api.example.com/payments
+  - name: platform
+    slack: "#platform"
+    urls:
+      - https://api.example.com/
+`
+
+// TestAssign tests assigning docs to the team with the longest matching URL prefix and completing owners
+func TestAssign(t *testing.T) {
+	directory, err := ParseTeams([]byte(testTeams))
+	if err != nil {
+		t.Fatalf("Failed to parse teams: %v", err)
+	}
+
+	doc := &models.APIDoc{URL: "https://api.example.com/payments/openapi.json"}
+	if err := Assign(directory, doc); err != nil {
+		t.Fatalf("Failed to assign owner: %v", err)
+	}
+	if doc.Owner == nil || doc.Owner.Team != "payments" || doc.Owner.Escalation != "pagerduty:payments-oncall" {
+		t.Errorf("Expected the payments team to own the doc, got %+v", doc.Owner)
+	}
+
+	named := &models.APIDoc{URL: "https://other.example.org", Owner: &models.Owner{Team: "Platform", Email: "platform-leads@example.com"}}
+	if err := Assign(directory, named); err != nil {
+		t.Fatalf("Failed to assign owner: %v", err)
+	}
+	if named.Owner.Email != "platform-leads@example.com" || named.Owner.Slack != "#platform" {
+		t.Errorf("Expected the owner to keep its email and gain the team's Slack channel, got %+v", named.Owner)
+	}
+
+	unowned := &models.APIDoc{URL: "https://other.example.org"}
+	if err := Assign(directory, unowned); err != nil || unowned.Owner != nil {
+		t.Errorf("Expected no owner for a doc outside every team's URLs, got %+v, %v", unowned.Owner, err)
+	}
+}
+
+// TestParseTeamsInvalid tests rejecting teams without a name
+func TestParseTeamsInvalid(t *testing.T) {
+	if _, err := ParseTeams([]byte("teams:\n  - email: x@example.com\n")); err == nil {
+		t.Error("Expected an error for a team without a name")
+	}
+}
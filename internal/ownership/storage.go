@@ -0,0 +1,58 @@
+package ownership
+
+import (
+	"log"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// AssigningStorage wraps a Storage, assigning saved docs without an owner to the team owning their
+// URL in a directory, and completing owners that only name their team with the team's contacts
+type AssigningStorage struct {
+	storage.Storage
+	directory Directory
+}
+
+// NewAssigningStorage wraps store
+func NewAssigningStorage(store storage.Storage, directory Directory) *AssigningStorage {
+	return &AssigningStorage{Storage: store, directory: directory}
+}
+
+// SaveAPIDoc assigns the doc's owner and saves it. A directory that can't be reached doesn't prevent saving.
+func (s *AssigningStorage) SaveAPIDoc(doc *models.APIDoc) error {
+	if err := Assign(s.directory, doc); err != nil {
+		log.Printf("Failed to assign an owner to %s: %v", doc.ID, err)
+	}
+	return s.Storage.SaveAPIDoc(doc)
+}
+
+// Assign sets a doc's owner from a directory: the team owning its URL if it has no owner, or the
+// contacts of its team that its owner leaves out
+func Assign(directory Directory, doc *models.APIDoc) error {
+	var team *Team
+	var err error
+	if doc.Owner == nil {
+		team, err = Owning(directory, doc)
+	} else {
+		team, err = Find(directory, doc.Owner.Team)
+	}
+	if err != nil || team == nil {
+		return err
+	}
+
+	if doc.Owner == nil {
+		doc.Owner = team.Owner()
+		return nil
+	}
+	if doc.Owner.Email == "" {
+		doc.Owner.Email = team.Email
+	}
+	if doc.Owner.Slack == "" {
+		doc.Owner.Slack = team.Slack
+	}
+	if doc.Owner.Escalation == "" {
+		doc.Owner.Escalation = team.Escalation
+	}
+	return nil
+}
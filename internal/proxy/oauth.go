@@ -0,0 +1,119 @@
+package proxy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"universal_api/internal/models"
+)
+
+// maxTokenResponse bounds the size of token responses read from authorization servers
+const maxTokenResponse = 1 << 20
+
+// tokenClient requests access tokens from the authorization servers of documented APIs
+var tokenClient = &http.Client{Timeout: 30 * time.Second}
+
+// ErrNoClientCredentialsFlow is returned for docs without an OAuth2 client credentials flow
+var ErrNoClientCredentialsFlow = errors.New("API doc has no OAuth2 client credentials flow with a token URL")
+
+// Token is an access token issued by the authorization server of a documented API
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type,omitempty"`
+	ExpiresIn   int    `json:"expires_in,omitempty"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// ClientCredentialsFlow returns the client credentials flow of the doc's OAuth2 scheme with a name,
+// or of its first OAuth2 scheme with one when the name is empty
+func ClientCredentialsFlow(doc *models.APIDoc, scheme string) (*models.OAuthFlow, error) {
+	for _, authScheme := range doc.AuthSchemes {
+		if authScheme.Type != "oauth2" || scheme != "" && authScheme.Name != scheme {
+			continue
+		}
+		for _, flow := range authScheme.Flows {
+			if flow.Flow == "clientCredentials" && flow.TokenURL != "" {
+				return &flow, nil
+			}
+		}
+	}
+	return nil, ErrNoClientCredentialsFlow
+}
+
+// ClientCredentialsToken requests an access token with client credentials from the token URL of a
+// doc's flow. Relative token URLs are resolved against the doc's first server, or its URL.
+func ClientCredentialsToken(doc *models.APIDoc, flow *models.OAuthFlow, clientID, clientSecret string, scopes []string) (*Token, error) {
+	tokenURL, err := resolveTokenURL(doc, flow.TokenURL)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(scopes) > 0 {
+		form.Set("scope", strings.Join(scopes, " "))
+	}
+	req, err := http.NewRequest(http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+
+	resp, err := tokenClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTokenResponse))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var oauthErr struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Error != "" {
+			return nil, fmt.Errorf("token request failed with status %d: %s %s", resp.StatusCode, oauthErr.Error, oauthErr.Description)
+		}
+		return nil, fmt.Errorf("token request failed with status %d", resp.StatusCode)
+	}
+
+	var token Token
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("token response has no access token")
+	}
+	return &token, nil
+}
+
+// resolveTokenURL resolves a token URL against the doc's first server, or its URL
+func resolveTokenURL(doc *models.APIDoc, tokenURL string) (string, error) {
+	ref, err := url.Parse(tokenURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid token URL: %s", tokenURL)
+	}
+	if ref.IsAbs() {
+		return tokenURL, nil
+	}
+
+	base := doc.URL
+	if len(doc.Servers) > 0 {
+		base = doc.Servers[0]
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil || !baseURL.IsAbs() {
+		return "", fmt.Errorf("can't resolve relative token URL: %s", tokenURL)
+	}
+	return baseURL.ResolveReference(ref).String(), nil
+}
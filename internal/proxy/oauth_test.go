@@ -0,0 +1,104 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestClientCredentialsToken tests getting an access token from a doc's client credentials flow
+func TestClientCredentialsToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if r.URL.Path != "/oauth/token" || r.FormValue("grant_type") != "client_credentials" || id != "app" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": "invalid_client"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token": "tok_123", "token_type": "Bearer", "expires_in": 3600, "scope": "` + r.FormValue("scope") + `"}`))
+	}))
+	defer server.Close()
+
+	doc := &models.APIDoc{
+		Servers: []string{server.URL + "/v1"},
+		AuthSchemes: []models.AuthScheme{
+			{Name: "key", Type: "apiKey"},
+			{Name: "oauth", Type: "oauth2", Flows: []models.OAuthFlow{
+				{Flow: "authorizationCode", AuthorizationURL: "/oauth/authorize", TokenURL: "/oauth/token"},
+				{Flow: "clientCredentials", TokenURL: "/oauth/token"},
+			}},
+		},
+	}
+
+	flow, err := ClientCredentialsFlow(doc, "")
+	if err != nil {
+		t.Fatalf("Expected a client credentials flow, got %v", err)
+	}
+
+	token, err := ClientCredentialsToken(doc, flow, "app", "s3cret", []string{"orders:read", "orders:write"})
+	if err != nil {
+		t.Fatalf("Failed to get token: %v", err)
+	}
+	if token.AccessToken != "tok_123" || token.Scope != "orders:read orders:write" {
+		t.Errorf("Unexpected token: %+v", token)
+	}
+
+	if _, err := ClientCredentialsToken(doc, flow, "app", "wrong", nil); err == nil {
+		t.Error("Expected an error for invalid client credentials")
+	}
+	if _, err := ClientCredentialsFlow(doc, "key"); err != ErrNoClientCredentialsFlow {
+		t.Errorf("Expected ErrNoClientCredentialsFlow for an API key scheme, got %v", err)
+	}
+}
+
+// TestProxyWithClientCredentialsToken tests calling the proxied API with its access token while the
+// caller authenticates to this service with its own Authorization header
+func TestProxyWithClientCredentialsToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/token":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token": "tok_123", "token_type": "Bearer"}`))
+		case "/v1/orders":
+			if r.Header.Get("Authorization") != "Bearer tok_123" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`[]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	doc := &models.APIDoc{
+		Servers: []string{server.URL + "/v1"},
+		AuthSchemes: []models.AuthScheme{
+			{Name: "oauth", Type: "oauth2", Flows: []models.OAuthFlow{{Flow: "clientCredentials", TokenURL: "/oauth/token"}}},
+		},
+	}
+
+	flow, err := ClientCredentialsFlow(doc, "")
+	if err != nil {
+		t.Fatalf("Expected a client credentials flow, got %v", err)
+	}
+	token, err := ClientCredentialsToken(doc, flow, "app", "s3cret", nil)
+	if err != nil {
+		t.Fatalf("Failed to get token: %v", err)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/proxy/orders", nil)
+	request.Header.Set("Authorization", "Bearer service-api-key")
+	request.Header.Set(UpstreamAuthorizationHeader, token.TokenType+" "+token.AccessToken)
+
+	recorder := httptest.NewRecorder()
+	if err := NewProxy(nil, nil).ServeHTTP(recorder, request, doc, "/orders"); err != nil {
+		t.Fatalf("Failed to proxy: %v", err)
+	}
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Expected the access token to authenticate the proxied request, got status %d", recorder.Code)
+	}
+}
@@ -0,0 +1,294 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"universal_api/internal/models"
+	"universal_api/internal/schema"
+	"universal_api/internal/storage"
+	"universal_api/pkg/parser"
+)
+
+// maxValidatedBody bounds the size of bodies buffered for validation and recording; larger bodies are
+// still proxied whole
+const maxValidatedBody = 10 << 20
+
+// maxExamplesPerEndpoint bounds the number of recorded examples kept per endpoint
+const maxExamplesPerEndpoint = 5
+
+// RecordExampleHeader opts a proxied request into being recorded as an endpoint example
+const RecordExampleHeader = "X-Record-Example"
+
+// UpstreamAuthorizationHeader carries the Authorization header of the proxied API, as Authorization
+// carries the caller's credentials to this service
+const UpstreamAuthorizationHeader = "X-Upstream-Authorization"
+
+// serviceHeaders are the request headers meant for this service, never forwarded to proxied APIs
+var serviceHeaders = []string{"Authorization", "X-API-Key", "X-Workspace", RecordExampleHeader}
+
+// serviceCookies are the cookies the UI sets, never forwarded to proxied APIs
+var serviceCookies = map[string]bool{"api_key": true, "id_token": true, "oidc_state": true, "workspace": true, "theme": true}
+
+// Proxy forwards requests to a documented API and records responses that drift from the doc
+type Proxy struct {
+	store storage.Storage
+	drift storage.DriftStorage
+
+	// recordMu serializes the read-modify-write of docs when recording examples
+	recordMu sync.Mutex
+}
+
+// NewProxy creates a new validation Proxy. Recorded examples are saved to store.
+func NewProxy(store storage.Storage, drift storage.DriftStorage) *Proxy {
+	return &Proxy{store: store, drift: drift}
+}
+
+// ServeHTTP forwards the request to the doc's first server at the given path
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request, doc *models.APIDoc, path string) error {
+	if len(doc.Servers) == 0 {
+		return errors.New("API doc does not declare a server URL")
+	}
+
+	target, err := url.Parse(doc.Servers[0])
+	if err != nil || target.Host == "" {
+		return fmt.Errorf("invalid server URL: %s", doc.Servers[0])
+	}
+
+	// path is relative to the server, whose base path the Director prepends
+	endpoint := MatchEndpoint(doc, r.Method, path)
+
+	// Buffer the request body so it can be recorded alongside the response
+	record := endpoint != nil && r.Header.Get(RecordExampleHeader) == "true"
+	var requestBody []byte
+	if record && r.Body != nil {
+		requestBody, r.Body, err = bufferBody(r.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	reverseProxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = target.Scheme
+			req.URL.Host = target.Host
+			req.URL.Path = strings.TrimSuffix(target.Path, "/") + path
+			req.Host = target.Host
+			forwardHeaders(req.Header)
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			if endpoint != nil {
+				p.check(doc, endpoint, resp)
+			}
+			if record {
+				p.record(doc, endpoint, r, requestBody, resp)
+			}
+			return nil
+		},
+	}
+
+	reverseProxy.ServeHTTP(w, r)
+	return nil
+}
+
+// forwardHeaders removes the credentials of this service from the headers of a proxied request, so the
+// proxied API never sees the caller's API key or session. The Authorization header sent is the one of
+// UpstreamAuthorizationHeader.
+func forwardHeaders(header http.Header) {
+	upstreamAuthorization := header.Get(UpstreamAuthorizationHeader)
+	for _, name := range append(serviceHeaders, UpstreamAuthorizationHeader) {
+		header.Del(name)
+	}
+	if upstreamAuthorization != "" {
+		header.Set("Authorization", upstreamAuthorization)
+	}
+
+	var forwarded []string
+	for _, cookie := range (&http.Request{Header: header}).Cookies() {
+		if !serviceCookies[cookie.Name] {
+			forwarded = append(forwarded, cookie.String())
+		}
+	}
+	header.Del("Cookie")
+	if len(forwarded) > 0 {
+		header.Set("Cookie", strings.Join(forwarded, "; "))
+	}
+}
+
+// check validates a live response against the endpoint's documented schema and records drift
+func (p *Proxy) check(doc *models.APIDoc, endpoint *models.Endpoint, resp *http.Response) {
+	var errs []string
+
+	documented := findResponse(endpoint, resp.StatusCode)
+	switch {
+	case documented == nil:
+		errs = append(errs, fmt.Sprintf("undocumented status code %d", resp.StatusCode))
+	case documented.Schema != "" && strings.Contains(resp.Header.Get("Content-Type"), "json"):
+		body, replaced, err := bufferBody(resp.Body)
+		resp.Body = replaced
+		// Bodies too large to buffer whole are passed through without being validated
+		if err != nil || len(body) > maxValidatedBody {
+			return
+		}
+
+		var value interface{}
+		if err := json.Unmarshal(body, &value); err != nil {
+			errs = append(errs, "response body is not valid JSON")
+			break
+		}
+
+		schemaErrs, err := schema.Validate(documented.Schema, value)
+		if err != nil {
+			return
+		}
+		errs = append(errs, schemaErrs...)
+	}
+
+	if len(errs) == 0 {
+		return
+	}
+
+	finding := &models.DriftFinding{
+		ID:         fmt.Sprintf("drift-%d", time.Now().UnixNano()),
+		DocID:      doc.ID,
+		Method:     endpoint.Method,
+		Path:       endpoint.Path,
+		StatusCode: resp.StatusCode,
+		Errors:     errs,
+		ObservedAt: time.Now(),
+	}
+	if err := p.drift.SaveDriftFinding(finding); err != nil {
+		log.Printf("Failed to save drift finding for %s: %v", doc.ID, err)
+	}
+}
+
+// record saves the sanitized request/response pair as an example of the endpoint
+func (p *Proxy) record(doc *models.APIDoc, endpoint *models.Endpoint, r *http.Request, requestBody []byte, resp *http.Response) {
+	body, replaced, err := bufferBody(resp.Body)
+	resp.Body = replaced
+	if err != nil {
+		return
+	}
+
+	example := models.Example{
+		Source: "proxy",
+		Request: models.ExampleRequest{
+			Method:  r.Method,
+			Path:    resp.Request.URL.Path,
+			Query:   sanitizeQuery(r.URL.RawQuery),
+			Headers: sanitizeHeaders(resp.Request.Header),
+			Body:    sanitizeBody(requestBody, r.Header.Get("Content-Type")),
+		},
+		Response: models.ExampleResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    sanitizeHeaders(resp.Header),
+			Body:       sanitizeBody(body, resp.Header.Get("Content-Type")),
+		},
+		RecordedAt: time.Now(),
+	}
+
+	p.recordMu.Lock()
+	defer p.recordMu.Unlock()
+
+	// Reload the doc so examples recorded concurrently or a refresh in between aren't lost
+	current, err := p.store.GetAPIDoc(doc.ID)
+	if err != nil {
+		log.Printf("Failed to record example for %s: %v", doc.ID, err)
+		return
+	}
+
+	updated := *current
+	updated.Endpoints = make([]models.Endpoint, len(current.Endpoints))
+	copy(updated.Endpoints, current.Endpoints)
+
+	for i := range updated.Endpoints {
+		target := &updated.Endpoints[i]
+		if target.Method != endpoint.Method || target.Path != endpoint.Path {
+			continue
+		}
+
+		// The stored doc and its versions share the endpoint's slices, which inference rewrites in place
+		target.Responses = append([]models.Response(nil), target.Responses...)
+		target.Parameters = append([]models.Parameter(nil), target.Parameters...)
+		examples := append(append([]models.Example(nil), target.Examples...), example)
+		if len(examples) > maxExamplesPerEndpoint {
+			examples = examples[len(examples)-maxExamplesPerEndpoint:]
+		}
+		target.Examples = examples
+		schema.InferResponses(target)
+		parser.AnnotatePagination(target)
+		parser.ClassifySafety(target)
+
+		if err := p.store.SaveAPIDoc(&updated); err != nil {
+			log.Printf("Failed to record example for %s: %v", doc.ID, err)
+		}
+		return
+	}
+}
+
+// bufferBody reads the start of a body, up to one byte past maxValidatedBody so larger bodies can be told
+// apart, returning it and a body reading the same bytes followed by the rest, to hand on whole
+func bufferBody(body io.ReadCloser) ([]byte, io.ReadCloser, error) {
+	buffered, err := io.ReadAll(io.LimitReader(body, maxValidatedBody+1))
+	return buffered, struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buffered), body), body}, err
+}
+
+// MatchEndpoint finds the documented endpoint for a concrete request path,
+// matching {param} and :param path segments against any value
+func MatchEndpoint(doc *models.APIDoc, method, path string) *models.Endpoint {
+	requestSegments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, endpoint := range doc.Endpoints {
+		// Webhooks, callbacks, and events are sent by the API, not to it
+		if endpoint.Incoming() || !strings.EqualFold(endpoint.Method, method) {
+			continue
+		}
+
+		segments := strings.Split(strings.Trim(endpoint.Path, "/"), "/")
+		if len(segments) != len(requestSegments) {
+			continue
+		}
+
+		matched := true
+		for j, segment := range segments {
+			isParam := strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") ||
+				strings.HasPrefix(segment, ":")
+			if !isParam && segment != requestSegments[j] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return &doc.Endpoints[i]
+		}
+	}
+
+	return nil
+}
+
+// findResponse finds the documented response for a status code, falling back to the default response
+func findResponse(endpoint *models.Endpoint, statusCode int) *models.Response {
+	var fallback *models.Response
+	for i, response := range endpoint.Responses {
+		if response.StatusCode == statusCode {
+			return &endpoint.Responses[i]
+		}
+		if response.StatusCode == 0 {
+			fallback = &endpoint.Responses[i]
+		}
+	}
+	return fallback
+}
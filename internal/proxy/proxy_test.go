@@ -0,0 +1,315 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// TestProxyRecordsDrift tests that responses not matching the documented schema are recorded
+func TestProxyRecordsDrift(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/users/42":
+			w.Write([]byte(`{"id": "42", "name": "Ada"}`))
+		case "/v1/users/7":
+			w.Write([]byte(`{"id": 7, "name": "Grace"}`))
+		default:
+			w.WriteHeader(http.StatusTeapot)
+		}
+	}))
+	defer upstream.Close()
+
+	doc := &models.APIDoc{
+		ID:      "doc-1",
+		Servers: []string{upstream.URL + "/v1"},
+		Endpoints: []models.Endpoint{
+			{
+				Method: "GET",
+				Path:   "/users/{id}",
+				Responses: []models.Response{{
+					StatusCode: 200,
+					Schema:     `{"type": "object", "required": ["id", "name"], "properties": {"id": {"type": "integer"}, "name": {"type": "string"}}}`,
+				}},
+			},
+		},
+	}
+
+	store := storage.NewMemoryStorage()
+	p := NewProxy(store, store)
+
+	for _, path := range []string{"/users/42", "/users/7", "/users/0/teapot"} {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/proxy"+path, nil)
+		if err := p.ServeHTTP(recorder, request, doc, path); err != nil {
+			t.Fatalf("Failed to proxy %s: %v", path, err)
+		}
+		if path == "/users/42" && recorder.Body.String() != `{"id": "42", "name": "Ada"}` {
+			t.Errorf("Expected upstream body to be passed through, got %s", recorder.Body.String())
+		}
+	}
+
+	findings, _ := store.GetDriftFindings("doc-1")
+	if len(findings) != 1 {
+		t.Fatalf("Expected 1 drift finding, got %d", len(findings))
+	}
+
+	if findings[0].Path != "/users/{id}" || len(findings[0].Errors) != 1 {
+		t.Errorf("Expected a single type mismatch on /users/{id}, got %+v", findings[0])
+	}
+}
+
+// TestProxyRecordsExamples tests that opted-in requests are saved as sanitized endpoint examples
+func TestProxyRecordsExamples(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(RecordExampleHeader) != "" {
+			t.Errorf("Expected %s not to be forwarded", RecordExampleHeader)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 1, "token": "abc123"}`))
+	}))
+	defer upstream.Close()
+
+	store := storage.NewMemoryStorage()
+	doc := &models.APIDoc{
+		ID:        "doc-1",
+		Servers:   []string{upstream.URL},
+		Endpoints: []models.Endpoint{{Method: "POST", Path: "/sessions"}},
+	}
+	store.SaveAPIDoc(doc)
+
+	p := NewProxy(store, store)
+
+	request := httptest.NewRequest(http.MethodPost, "/proxy/sessions?api_key=secret&page=2", strings.NewReader(`{"user": "ada", "password": "hunter2"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(UpstreamAuthorizationHeader, "Bearer secret")
+	request.Header.Set(RecordExampleHeader, "true")
+
+	recorder := httptest.NewRecorder()
+	if err := p.ServeHTTP(recorder, request, doc, "/sessions"); err != nil {
+		t.Fatalf("Failed to proxy: %v", err)
+	}
+	if recorder.Body.String() != `{"id": 1, "token": "abc123"}` {
+		t.Errorf("Expected upstream body to be passed through, got %s", recorder.Body.String())
+	}
+
+	saved, _ := store.GetAPIDoc("doc-1")
+	examples := saved.Endpoints[0].Examples
+	if len(examples) != 1 {
+		t.Fatalf("Expected 1 recorded example, got %d", len(examples))
+	}
+
+	example := examples[0]
+	if example.Response.StatusCode != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", example.Response.StatusCode)
+	}
+	if example.Request.Headers["Authorization"] != redacted {
+		t.Errorf("Expected Authorization to be redacted, got %q", example.Request.Headers["Authorization"])
+	}
+	if example.Request.Query != "api_key=REDACTED&page=2" {
+		t.Errorf("Expected api_key to be redacted, got %q", example.Request.Query)
+	}
+	for _, secret := range []string{"hunter2", "abc123"} {
+		if strings.Contains(example.Request.Body+example.Response.Body, secret) {
+			t.Errorf("Expected %s to be redacted from the example bodies", secret)
+		}
+	}
+}
+
+// TestProxyRecordingKeepsVersions tests that recording an example leaves the saved doc and its versions as they were
+func TestProxyRecordingKeepsVersions(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "name": "Ada"}`))
+	}))
+	defer upstream.Close()
+
+	store := storage.NewMemoryStorage()
+	doc := &models.APIDoc{
+		ID:      "doc-1",
+		Servers: []string{upstream.URL},
+		Endpoints: []models.Endpoint{{
+			Method:     "GET",
+			Path:       "/users/{id}",
+			Parameters: []models.Parameter{{Name: "id", In: "path"}},
+			Responses:  []models.Response{{StatusCode: 200, Description: "The user"}},
+		}},
+	}
+	store.SaveAPIDoc(doc)
+
+	request := httptest.NewRequest(http.MethodGet, "/proxy/users/1", nil)
+	request.Header.Set(RecordExampleHeader, "true")
+	if err := NewProxy(store, store).ServeHTTP(httptest.NewRecorder(), request, doc, "/users/1"); err != nil {
+		t.Fatalf("Failed to proxy: %v", err)
+	}
+
+	saved, _ := store.GetAPIDoc("doc-1")
+	if saved.Endpoints[0].Responses[0].Schema == "" {
+		t.Fatalf("Expected the response schema to be inferred from the example")
+	}
+	if doc.Endpoints[0].Responses[0].Schema != "" || len(doc.Endpoints[0].Examples) != 0 {
+		t.Errorf("Expected the doc saved before the recording to be left as it was, got %+v", doc.Endpoints[0])
+	}
+
+	versions, err := store.GetAPIDocVersions("doc-1")
+	if err != nil {
+		t.Fatalf("Failed to get versions: %v", err)
+	}
+	if first := versions[0].Doc.Endpoints[0]; first.Responses[0].Schema != "" || len(first.Examples) != 0 {
+		t.Errorf("Expected version 1 to be left as it was, got %+v", first)
+	}
+}
+
+// TestProxyLargeBodies tests that bodies too large to validate or record are still proxied whole
+func TestProxyLargeBodies(t *testing.T) {
+	padding := strings.Repeat("x", maxValidatedBody)
+	var received int
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = len(body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "padding": "` + padding + `"}`))
+	}))
+	defer upstream.Close()
+
+	store := storage.NewMemoryStorage()
+	doc := &models.APIDoc{
+		ID:      "doc-1",
+		Servers: []string{upstream.URL},
+		Endpoints: []models.Endpoint{{
+			Method:    "POST",
+			Path:      "/uploads",
+			Responses: []models.Response{{StatusCode: 200, Schema: `{"type": "object"}`}},
+		}},
+	}
+	store.SaveAPIDoc(doc)
+
+	requestBody := `{"data": "` + padding + `"}`
+	request := httptest.NewRequest(http.MethodPost, "/proxy/uploads", strings.NewReader(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(RecordExampleHeader, "true")
+	recorder := httptest.NewRecorder()
+	if err := NewProxy(store, store).ServeHTTP(recorder, request, doc, "/uploads"); err != nil {
+		t.Fatalf("Failed to proxy: %v", err)
+	}
+
+	if received != len(requestBody) {
+		t.Errorf("Expected the whole request body of %d bytes upstream, got %d", len(requestBody), received)
+	}
+	if expected := len(padding) + len(`{"id": 1, "padding": ""}`); recorder.Body.Len() != expected {
+		t.Errorf("Expected the whole response body of %d bytes, got %d", expected, recorder.Body.Len())
+	}
+	if findings, _ := store.GetDriftFindings("doc-1"); len(findings) != 0 {
+		t.Errorf("Expected the large response to go unvalidated, got %+v", findings)
+	}
+	if saved, _ := store.GetAPIDoc("doc-1"); len(saved.Endpoints[0].Examples) != 1 {
+		t.Errorf("Expected the example to be recorded from the start of the bodies")
+	}
+}
+
+// TestProxyServerBasePath tests that endpoints are matched on the path relative to a server with a base path
+func TestProxyServerBasePath(t *testing.T) {
+	var upstreamPath string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamPath = r.URL.Path
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer upstream.Close()
+
+	doc := &models.APIDoc{
+		ID:      "doc-1",
+		Servers: []string{upstream.URL + "/api"},
+		Endpoints: []models.Endpoint{
+			{Method: "GET", Path: "/api-keys", Responses: []models.Response{{StatusCode: 200}}},
+		},
+	}
+
+	store := storage.NewMemoryStorage()
+	p := NewProxy(store, store)
+	if err := p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/proxy/api-keys", nil), doc, "/api-keys"); err != nil {
+		t.Fatalf("Failed to proxy: %v", err)
+	}
+
+	if upstreamPath != "/api/api-keys" {
+		t.Errorf("Expected the request to be forwarded to /api/api-keys, got %s", upstreamPath)
+	}
+	findings, _ := store.GetDriftFindings("doc-1")
+	if len(findings) != 1 || findings[0].Path != "/api-keys" {
+		t.Errorf("Expected the undocumented status of /api-keys to be found, got %+v", findings)
+	}
+}
+
+// TestProxyServiceCredentials tests that the caller's credentials to this service aren't forwarded
+func TestProxyServiceCredentials(t *testing.T) {
+	var forwarded http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r.Header.Clone()
+	}))
+	defer upstream.Close()
+
+	doc := &models.APIDoc{ID: "doc-1", Servers: []string{upstream.URL}}
+	store := storage.NewMemoryStorage()
+	p := NewProxy(store, store)
+
+	for _, upstreamAuthorization := range []string{"", "Bearer upstream-token"} {
+		request := httptest.NewRequest(http.MethodGet, "/proxy/users", nil)
+		request.Header.Set("Authorization", "Bearer caller-key")
+		request.Header.Set("X-API-Key", "caller-key")
+		request.Header.Set("X-Workspace", "team-a")
+		request.Header.Set("Cookie", "api_key=caller-key; id_token=caller-jwt; upstream_session=abc")
+		request.Header.Set("Accept", "application/json")
+		if upstreamAuthorization != "" {
+			request.Header.Set(UpstreamAuthorizationHeader, upstreamAuthorization)
+		}
+
+		if err := p.ServeHTTP(httptest.NewRecorder(), request, doc, "/users"); err != nil {
+			t.Fatalf("Failed to proxy: %v", err)
+		}
+
+		for name, values := range forwarded {
+			for _, value := range values {
+				if strings.Contains(value, "caller-") || strings.Contains(value, "team-a") {
+					t.Errorf("Expected the caller's credentials not to be forwarded, got %s: %s", name, value)
+				}
+			}
+		}
+		if forwarded.Get("Authorization") != upstreamAuthorization {
+			t.Errorf("Expected Authorization %q upstream, got %q", upstreamAuthorization, forwarded.Get("Authorization"))
+		}
+		if forwarded.Get(UpstreamAuthorizationHeader) != "" {
+			t.Errorf("Expected %s not to be forwarded", UpstreamAuthorizationHeader)
+		}
+		if forwarded.Get("Cookie") != "upstream_session=abc" || forwarded.Get("Accept") != "application/json" {
+			t.Errorf("Expected other headers and cookies to be forwarded, got %v", forwarded)
+		}
+	}
+}
+
+// TestMatchEndpoint tests matching concrete paths against path templates
+func TestMatchEndpoint(t *testing.T) {
+	doc := &models.APIDoc{
+		Endpoints: []models.Endpoint{
+			{Method: "GET", Path: "/users"},
+			{Method: "GET", Path: "/users/:id/posts"},
+		},
+	}
+
+	if endpoint := MatchEndpoint(doc, "get", "/users/"); endpoint == nil || endpoint.Path != "/users" {
+		t.Errorf("Expected /users to match, got %v", endpoint)
+	}
+
+	if endpoint := MatchEndpoint(doc, "GET", "/users/3/posts"); endpoint == nil || endpoint.Path != "/users/:id/posts" {
+		t.Errorf("Expected /users/:id/posts to match, got %v", endpoint)
+	}
+
+	if endpoint := MatchEndpoint(doc, "POST", "/users"); endpoint != nil {
+		t.Errorf("Expected no match for POST /users, got %v", endpoint)
+	}
+}
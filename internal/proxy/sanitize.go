@@ -0,0 +1,142 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxExampleBody bounds the size of recorded example bodies
+const maxExampleBody = 64 << 10
+
+// redacted replaces sensitive values in recorded examples
+const redacted = "REDACTED"
+
+// sensitiveNames are header, query, and JSON field name fragments whose values are redacted
+var sensitiveNames = []string{"authorization", "cookie", "token", "secret", "password", "api-key", "api_key", "apikey", "session"}
+
+// sensitiveJSONField matches a JSON field with a string value, possibly cut at the end of the body,
+// capturing its name
+var sensitiveJSONField = regexp.MustCompile(`"([^"\\]+)"\s*:\s*"(?:[^"\\]|\\.)*("|$)`)
+
+// recordedHeaders are the only headers kept in recorded examples
+var recordedHeaders = []string{"Accept", "Authorization", "Content-Type", "X-Api-Key", "Location", "Link"}
+
+// isSensitive checks if a header, parameter, or field name holds a credential
+func isSensitive(name string) bool {
+	lower := strings.ToLower(name)
+	for _, fragment := range sensitiveNames {
+		if strings.Contains(lower, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeHeaders keeps the recorded headers, redacting credentials
+func sanitizeHeaders(headers http.Header) map[string]string {
+	sanitized := make(map[string]string)
+	for _, name := range recordedHeaders {
+		value := headers.Get(name)
+		if value == "" {
+			continue
+		}
+		if isSensitive(name) {
+			value = redacted
+		}
+		sanitized[name] = value
+	}
+	return sanitized
+}
+
+// sanitizeQuery redacts credential query parameters
+func sanitizeQuery(rawQuery string) string {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return ""
+	}
+	for name := range values {
+		if isSensitive(name) {
+			values[name] = []string{redacted}
+		}
+	}
+	return values.Encode()
+}
+
+// sanitizeBody redacts credential fields from JSON and form bodies, then truncates large bodies, so
+// credentials past the cut are redacted too
+func sanitizeBody(body []byte, contentType string) string {
+	sanitized, truncated := string(body), false
+	switch {
+	case strings.Contains(contentType, "json"):
+		sanitized, truncated = sanitizeJSON(body)
+	case strings.Contains(contentType, "application/x-www-form-urlencoded"):
+		sanitized = sanitizeQuery(sanitized)
+	}
+
+	if len(sanitized) > maxExampleBody {
+		sanitized, truncated = cutExampleBody(sanitized), true
+	}
+	if truncated {
+		sanitized += "...(truncated)"
+	}
+	return sanitized
+}
+
+// cutExampleBody cuts a body to maxExampleBody bytes, between runes, never inside one
+func cutExampleBody(body string) string {
+	cut := maxExampleBody
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return body[:cut]
+}
+
+// sanitizeJSON redacts credential fields from a JSON body, reporting if it was truncated. Bodies that
+// don't parse, such as those cut at maxValidatedBody, have the string values of credential fields
+// redacted in place, which is slow, so they're first truncated to the part that's kept. A value cut
+// short at the end is still redacted.
+func sanitizeJSON(body []byte) (string, bool) {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		text, truncated := string(body), false
+		if len(text) > maxExampleBody {
+			text, truncated = cutExampleBody(text), true
+		}
+		return sensitiveJSONField.ReplaceAllStringFunc(text, func(field string) string {
+			name := sensitiveJSONField.FindStringSubmatch(field)[1]
+			if !isSensitive(name) {
+				return field
+			}
+			return field[:strings.Index(field, ":")] + `: "` + redacted + `"`
+		}), truncated
+	}
+
+	sanitized, err := json.MarshalIndent(redactFields(value), "", "  ")
+	if err != nil {
+		return "", false
+	}
+	return string(sanitized), false
+}
+
+// redactFields replaces the values of sensitive fields in a decoded JSON value
+func redactFields(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if isSensitive(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactFields(child)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactFields(child)
+		}
+	}
+	return value
+}
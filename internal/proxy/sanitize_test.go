@@ -0,0 +1,83 @@
+package proxy
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestSanitizeBody tests that credentials are redacted from recorded bodies
+func TestSanitizeBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		secrets     []string
+		kept        []string
+	}{
+		{
+			name:        "JSON",
+			body:        `{"user": "ada", "credentials": {"password": "hunter2"}, "tokens": ["abc"]}`,
+			contentType: "application/json",
+			secrets:     []string{"hunter2", "abc"},
+			kept:        []string{"ada"},
+		},
+		{
+			name:        "form",
+			body:        "grant_type=password&username=ada&password=hunter2&client_secret=s3cret",
+			contentType: "application/x-www-form-urlencoded",
+			secrets:     []string{"hunter2", "s3cret"},
+			kept:        []string{"grant_type=password", "username=ada"},
+		},
+		{
+			name:        "cut JSON",
+			body:        `{"user": "ada", "session_id": "xyz789", "refresh_token": "def4`,
+			contentType: "application/json",
+			secrets:     []string{"xyz789", "def4"},
+			kept:        []string{"ada"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sanitized := sanitizeBody([]byte(test.body), test.contentType)
+			for _, secret := range test.secrets {
+				if strings.Contains(sanitized, secret) {
+					t.Errorf("Expected %s to be redacted, got %s", secret, sanitized)
+				}
+			}
+			for _, value := range test.kept {
+				if !strings.Contains(sanitized, value) {
+					t.Errorf("Expected %s to be kept, got %s", value, sanitized)
+				}
+			}
+		})
+	}
+}
+
+// TestSanitizeLargeBody tests that bodies over the example size are redacted before being truncated
+func TestSanitizeLargeBody(t *testing.T) {
+	body := `{"password": "hunter2", "padding": "` + strings.Repeat("x", maxExampleBody) + `"}`
+
+	sanitized := sanitizeBody([]byte(body), "application/json")
+	if strings.Contains(sanitized, "hunter2") {
+		t.Errorf("Expected the password of a large body to be redacted")
+	}
+	if !strings.HasSuffix(sanitized, "...(truncated)") || len(sanitized) > maxExampleBody+len("...(truncated)") {
+		t.Errorf("Expected the body to be truncated to %d bytes, got %d", maxExampleBody, len(sanitized))
+	}
+
+	// Credentials cut short in large bodies that don't parse are still redacted
+	cut := `{"padding": "` + strings.Repeat("x", maxExampleBody-40) + `", "password": "hunter2hunter2hunter2hunter2hunter2hunter2`
+	sanitized = sanitizeBody([]byte(cut), "application/json")
+	if strings.Contains(sanitized, "hunter2") || !strings.HasSuffix(sanitized, "...(truncated)") {
+		t.Errorf("Expected the cut password to be redacted and the body truncated, got ...%q", sanitized[len(sanitized)-60:])
+	}
+
+	// Multi-byte runes straddling the limit are dropped whole
+	text := strings.Repeat("x", maxExampleBody-1) + strings.Repeat("é", 10)
+	truncated := sanitizeBody([]byte(text), "text/plain")
+	if !utf8.ValidString(truncated) || truncated != strings.Repeat("x", maxExampleBody-1)+"...(truncated)" {
+		t.Errorf("Expected the body to be cut before the rune straddling the limit, got ...%q", truncated[len(truncated)-20:])
+	}
+}
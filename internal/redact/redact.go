@@ -0,0 +1,183 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"universal_api/internal/models"
+)
+
+// Placeholder replaces redacted values
+const Placeholder = "[REDACTED]"
+
+// Pattern finds one kind of secret or personal data. When the expression has a group, only the
+// group is redacted, so "Bearer abc123" becomes "Bearer [REDACTED]".
+type Pattern struct {
+	Kind   string
+	Regexp *regexp.Regexp
+}
+
+// DefaultPatterns find the secrets and personal data common in code samples
+var DefaultPatterns = []Pattern{
+	{Kind: "bearer_token", Regexp: regexp.MustCompile(`(?i)\bBearer\s+([A-Za-z0-9\-._~+/]{8,}=*)`)},
+	{Kind: "basic_auth", Regexp: regexp.MustCompile(`(?i)\bBasic\s+([A-Za-z0-9+/]{8,}=*)`)},
+	{Kind: "jwt", Regexp: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)},
+	{Kind: "aws_access_key", Regexp: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Kind: "aws_secret_key", Regexp: regexp.MustCompile(`(?i)aws_secret_access_key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})`)},
+	{Kind: "url_credentials", Regexp: regexp.MustCompile(`[a-z][a-z0-9+.-]*://[^/\s:@]+:([^/\s@]+)@`)},
+	{Kind: "email", Regexp: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)},
+}
+
+// secretHeaders are headers whose values are always credentials, whatever they look like
+var secretHeaders = []string{"authorization", "proxy-authorization", "cookie", "set-cookie", "x-api-key", "api-key", "x-auth-token"}
+
+// Redactor redacts secrets and personal data from the examples of docs
+type Redactor struct {
+	patterns []Pattern
+}
+
+// NewRedactor returns a redactor finding the default patterns and extra ones
+func NewRedactor(extra ...Pattern) *Redactor {
+	return &Redactor{patterns: append(append([]Pattern{}, DefaultPatterns...), extra...)}
+}
+
+// ParsePatterns compiles extra patterns given as a map of kinds to regular expressions
+func ParsePatterns(expressions map[string]string) ([]Pattern, error) {
+	kinds := make([]string, 0, len(expressions))
+	for kind := range expressions {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	patterns := make([]Pattern, 0, len(kinds))
+	for _, kind := range kinds {
+		re, err := regexp.Compile(expressions[kind])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", kind, err)
+		}
+		patterns = append(patterns, Pattern{Kind: kind, Regexp: re})
+	}
+	return patterns, nil
+}
+
+// Doc redacts the examples of a doc in place: the request and response examples of its endpoints
+// and the examples of their parameters, responses, and messages. What was redacted is added to the
+// doc's redaction report; the redacted values themselves are never kept.
+func (r *Redactor) Doc(doc *models.APIDoc) {
+	for i := range doc.Endpoints {
+		endpoint := &doc.Endpoints[i]
+		at := endpoint.Method + " " + endpoint.Path
+
+		for j := range endpoint.Parameters {
+			param := &endpoint.Parameters[j]
+			location := fmt.Sprintf("%s parameter %s", at, param.Name)
+			if param.In == "header" && isSecretHeader(param.Name) && param.Example != "" && param.Example != Placeholder {
+				param.Example = Placeholder
+				report(doc, "header", location)
+				continue
+			}
+			param.Example = r.text(doc, param.Example, location)
+		}
+		for j := range endpoint.Responses {
+			response := &endpoint.Responses[j]
+			response.Example = r.text(doc, response.Example, fmt.Sprintf("%s response %d example", at, response.StatusCode))
+		}
+		for j := range endpoint.Messages {
+			message := &endpoint.Messages[j]
+			message.Example = r.text(doc, message.Example, fmt.Sprintf("%s message %d example", at, j+1))
+		}
+		for j := range endpoint.Examples {
+			example := &endpoint.Examples[j]
+			location := fmt.Sprintf("%s example %d", at, j+1)
+			example.Request.Query = r.text(doc, example.Request.Query, location+" request query")
+			example.Request.Body = r.text(doc, example.Request.Body, location+" request body")
+			r.headers(doc, example.Request.Headers, location+" request header")
+			example.Response.Body = r.text(doc, example.Response.Body, location+" response body")
+			r.headers(doc, example.Response.Headers, location+" response header")
+		}
+	}
+}
+
+// text returns text with the matches of the patterns redacted, reporting each kind found
+func (r *Redactor) text(doc *models.APIDoc, text, location string) string {
+	if text == "" {
+		return text
+	}
+	for _, pattern := range r.patterns {
+		count := 0
+		text = pattern.Regexp.ReplaceAllStringFunc(text, func(match string) string {
+			redacted := redactMatch(pattern.Regexp, match)
+			if redacted != match {
+				count++
+			}
+			return redacted
+		})
+		for ; count > 0; count-- {
+			report(doc, pattern.Kind, location)
+		}
+	}
+	return text
+}
+
+// headers redacts the values of headers in place: those of credential headers entirely, others by the patterns
+func (r *Redactor) headers(doc *models.APIDoc, headers map[string]string, location string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := headers[name]
+		if isSecretHeader(name) {
+			if value != "" && value != Placeholder && !strings.HasSuffix(value, " "+Placeholder) {
+				headers[name] = r.text(doc, value, location+" "+name)
+				if headers[name] == value {
+					headers[name] = Placeholder
+					report(doc, "header", location+" "+name)
+				}
+			}
+			continue
+		}
+		headers[name] = r.text(doc, value, location+" "+name)
+	}
+}
+
+// redactMatch redacts a match: its first group when the pattern has one, or else all of it
+func redactMatch(re *regexp.Regexp, match string) string {
+	if re.NumSubexp() == 0 {
+		if match == Placeholder {
+			return match
+		}
+		return Placeholder
+	}
+	groups := re.FindStringSubmatchIndex(match)
+	if groups == nil || groups[2] < 0 || match[groups[2]:groups[3]] == Placeholder {
+		return match
+	}
+	return match[:groups[2]] + Placeholder + match[groups[3]:]
+}
+
+// isSecretHeader checks if a header always carries credentials
+func isSecretHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, secret := range secretHeaders {
+		if name == secret {
+			return true
+		}
+	}
+	return false
+}
+
+// report adds a redaction to a doc's report, counting repeats of a kind at a location
+func report(doc *models.APIDoc, kind, location string) {
+	for i := range doc.Redactions {
+		if doc.Redactions[i].Kind == kind && doc.Redactions[i].Location == location {
+			doc.Redactions[i].Count++
+			return
+		}
+	}
+	doc.Redactions = append(doc.Redactions, models.Redaction{Kind: kind, Location: location, Count: 1})
+}
@@ -0,0 +1,95 @@
+package redact
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// secretFields are fields and query parameters whose values are credentials, matched case-insensitively
+// and ignoring "-" and "_", so "client_secret" and "clientSecret" are both found
+var secretFields = []string{"password", "passwd", "secret", "clientsecret", "token", "accesstoken", "refreshtoken", "idtoken", "apikey", "authorization", "credentials", "sessionid"}
+
+// Headers returns a copy of request headers for logging, with the values of credential headers redacted
+func Headers(header http.Header) map[string]string {
+	redacted := make(map[string]string, len(header))
+	for name, values := range header {
+		if isSecretHeader(name) {
+			redacted[name] = Placeholder
+			continue
+		}
+		redacted[name] = strings.Join(values, ", ")
+	}
+	return redacted
+}
+
+// URL returns a URL, or a request URI, with its password and the values of credential query parameters redacted
+func URL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+
+	changed := false
+	if _, ok := parsed.User.Password(); ok {
+		parsed.User = url.UserPassword(parsed.User.Username(), Placeholder)
+		changed = true
+	}
+	if parsed.RawQuery != "" {
+		query := parsed.Query()
+		for name := range query {
+			if isSecretField(name) {
+				query[name] = []string{Placeholder}
+				changed = true
+			}
+		}
+		if changed {
+			parsed.RawQuery = query.Encode()
+		}
+	}
+
+	if !changed {
+		return raw
+	}
+	redacted := parsed.String()
+	// Keep the placeholder readable rather than escaped
+	redacted = strings.ReplaceAll(redacted, url.QueryEscape(Placeholder), Placeholder)
+	return strings.ReplaceAll(redacted, url.PathEscape(Placeholder), Placeholder)
+}
+
+// Fields redacts a decoded JSON value or form in place for logging: the values of credential fields
+// entirely, and the passwords and credential query parameters of URLs in other string fields
+func Fields(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for name, field := range value {
+			if isSecretField(name) {
+				if field != nil && field != "" {
+					value[name] = Placeholder
+				}
+				continue
+			}
+			value[name] = Fields(field)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = Fields(item)
+		}
+	case string:
+		if strings.Contains(value, "://") {
+			return URL(value)
+		}
+	}
+	return value
+}
+
+// isSecretField checks if a field or query parameter carries credentials
+func isSecretField(name string) bool {
+	name = strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(name))
+	for _, secret := range secretFields {
+		if name == secret {
+			return true
+		}
+	}
+	return false
+}
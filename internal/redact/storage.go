@@ -0,0 +1,23 @@
+package redact
+
+import (
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// RedactingStorage wraps a Storage, redacting secrets and personal data from docs before saving them
+type RedactingStorage struct {
+	storage.Storage
+	redactor *Redactor
+}
+
+// NewRedactingStorage wraps store
+func NewRedactingStorage(store storage.Storage, redactor *Redactor) *RedactingStorage {
+	return &RedactingStorage{Storage: store, redactor: redactor}
+}
+
+// SaveAPIDoc redacts the doc before saving it
+func (s *RedactingStorage) SaveAPIDoc(doc *models.APIDoc) error {
+	s.redactor.Doc(doc)
+	return s.Storage.SaveAPIDoc(doc)
+}
@@ -0,0 +1,169 @@
+// Package redis is a minimal Redis client speaking RESP over a single connection, shared by the parts
+// of the service keeping state in Redis
+package redis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client is a connection to a Redis server, reconnecting when the connection is lost
+type Client struct {
+	addr     string
+	password string
+	db       int
+	mu       sync.Mutex
+	conn     net.Conn
+	reader   *bufio.Reader
+}
+
+// Dial connects to the Redis server at a redis://[:password@]host:port[/db] URL
+func Dial(rawURL string) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "redis" || u.Host == "" {
+		return nil, fmt.Errorf("invalid Redis URL: %s", rawURL)
+	}
+
+	r := &Client{addr: u.Host}
+	if !strings.Contains(r.addr, ":") {
+		r.addr += ":6379"
+	}
+	if password, ok := u.User.Password(); ok {
+		r.password = password
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if r.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid Redis database: %s", db)
+		}
+	}
+
+	if _, err := r.Do("PING"); err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	return r, nil
+}
+
+// Do sends a command and reads its reply, reconnecting if the connection was lost
+func (r *Client) Do(args ...string) (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.conn == nil {
+		if err := r.connect(); err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := r.command(args...)
+	if err != nil {
+		var redisErr Error
+		if !errors.As(err, &redisErr) {
+			r.conn.Close()
+			r.conn = nil
+		}
+		return nil, err
+	}
+	return reply, nil
+}
+
+// connect dials Redis, authenticating and selecting the database
+func (r *Client) connect() error {
+	conn, err := net.DialTimeout("tcp", r.addr, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	r.conn = conn
+	r.reader = bufio.NewReader(conn)
+
+	if r.password != "" {
+		if _, err := r.command("AUTH", r.password); err != nil {
+			conn.Close()
+			r.conn = nil
+			return err
+		}
+	}
+	if r.db != 0 {
+		if _, err := r.command("SELECT", strconv.Itoa(r.db)); err != nil {
+			conn.Close()
+			r.conn = nil
+			return err
+		}
+	}
+	return nil
+}
+
+// command writes a command as a RESP array of bulk strings and reads the reply
+func (r *Client) command(args ...string) (interface{}, error) {
+	r.conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(r.conn, b.String()); err != nil {
+		return nil, err
+	}
+	return ReadReply(r.reader)
+}
+
+// Error is an error reply from Redis, after which the connection is still usable
+type Error string
+
+// Error returns the Redis error message
+func (e Error) Error() string {
+	return "redis: " + string(e)
+}
+
+// ReadReply reads a RESP reply: strings, integers, and nil as Go values, arrays as []interface{}
+func ReadReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty Redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil || count < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = ReadReply(reader); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected Redis reply: %q", line)
+	}
+}
@@ -0,0 +1,120 @@
+// Package redistest serves a minimal in-memory Redis for tests of the parts of the service keeping state in Redis
+package redistest
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"universal_api/internal/redis"
+)
+
+// Serve answers PING, GET, SET (with NX and PX), DEL, SCAN, INCR, DECR, PEXPIRE, PTTL, RPUSH, and LPOP
+// on the connections of a listener from maps, until the listener is closed
+func Serve(listener net.Listener) {
+	var mu sync.Mutex
+	data := make(map[string]string)
+	lists := make(map[string][]string)
+	expiries := make(map[string]time.Time)
+	expire := func(key string) {
+		if expiry, ok := expiries[key]; ok && time.Now().After(expiry) {
+			delete(data, key)
+			delete(expiries, key)
+		}
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			reader := bufio.NewReader(conn)
+			for {
+				reply, err := redis.ReadReply(reader)
+				if err != nil {
+					return
+				}
+				var args []string
+				for _, arg := range reply.([]interface{}) {
+					args = append(args, arg.(string))
+				}
+
+				mu.Lock()
+				if len(args) > 1 {
+					expire(args[1])
+				}
+				switch strings.ToUpper(args[0]) {
+				case "PING":
+					io.WriteString(conn, "+OK\r\n")
+				case "SET":
+					options := strings.ToUpper(strings.Join(args[3:], " "))
+					if _, exists := data[args[1]]; exists && strings.Contains(options, "NX") {
+						io.WriteString(conn, "$-1\r\n")
+						break
+					}
+					data[args[1]] = args[2]
+					for i := 3; i+1 < len(args); i++ {
+						if strings.EqualFold(args[i], "PX") {
+							ms, _ := strconv.Atoi(args[i+1])
+							expiries[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+						}
+					}
+					io.WriteString(conn, "+OK\r\n")
+				case "GET":
+					if value, ok := data[args[1]]; ok {
+						io.WriteString(conn, "$"+strconv.Itoa(len(value))+"\r\n"+value+"\r\n")
+					} else {
+						io.WriteString(conn, "$-1\r\n")
+					}
+				case "DEL":
+					for _, key := range args[1:] {
+						delete(data, key)
+					}
+					io.WriteString(conn, ":1\r\n")
+				case "SCAN":
+					out := "*2\r\n$1\r\n0\r\n*" + strconv.Itoa(len(data)) + "\r\n"
+					for key := range data {
+						out += "$" + strconv.Itoa(len(key)) + "\r\n" + key + "\r\n"
+					}
+					io.WriteString(conn, out)
+				case "INCR", "DECR":
+					value, _ := strconv.Atoi(data[args[1]])
+					if strings.EqualFold(args[0], "INCR") {
+						value++
+					} else {
+						value--
+					}
+					data[args[1]] = strconv.Itoa(value)
+					io.WriteString(conn, ":"+strconv.Itoa(value)+"\r\n")
+				case "PEXPIRE":
+					ms, _ := strconv.Atoi(args[2])
+					expiries[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+					io.WriteString(conn, ":1\r\n")
+				case "RPUSH":
+					lists[args[1]] = append(lists[args[1]], args[2:]...)
+					io.WriteString(conn, ":"+strconv.Itoa(len(lists[args[1]]))+"\r\n")
+				case "LPOP":
+					if list := lists[args[1]]; len(list) > 0 {
+						lists[args[1]] = list[1:]
+						io.WriteString(conn, "$"+strconv.Itoa(len(list[0]))+"\r\n"+list[0]+"\r\n")
+					} else {
+						io.WriteString(conn, "$-1\r\n")
+					}
+				case "PTTL":
+					remaining := -2
+					if expiry, ok := expiries[args[1]]; ok {
+						remaining = int(time.Until(expiry).Milliseconds())
+					}
+					io.WriteString(conn, ":"+strconv.Itoa(remaining)+"\r\n")
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+}
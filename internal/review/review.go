@@ -0,0 +1,74 @@
+package review
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"universal_api/internal/auth"
+	"universal_api/internal/models"
+)
+
+// ErrInvalidTransition is returned for actions a doc's status doesn't allow, like approving a draft
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// Visible checks if a user can see a doc. Published docs are visible to everyone, drafts only to
+// their submitter, and docs in review also to reviewers. Without authentication, user is nil and
+// every doc is visible; admins see every doc as well.
+func Visible(doc *models.APIDoc, user *models.User) bool {
+	if doc.Published() || user == nil || user.Role == auth.RoleAdmin || doc.SubmittedBy == user.ID {
+		return true
+	}
+	return doc.Status == models.StatusInReview && auth.Allows(user.Role, auth.PermissionReview)
+}
+
+// Submit moves a draft into review
+func Submit(doc *models.APIDoc) error {
+	if doc.Status != models.StatusDraft {
+		return fmt.Errorf("%w: only drafts can be submitted for review, the doc is %s", ErrInvalidTransition, status(doc))
+	}
+	doc.Status = models.StatusInReview
+	return nil
+}
+
+// Approve publishes a doc in review
+func Approve(doc *models.APIDoc, reviewer, comment string) error {
+	return decide(doc, reviewer, comment, true)
+}
+
+// Reject sends a doc in review back to its submitter as a draft
+func Reject(doc *models.APIDoc, reviewer, comment string) error {
+	return decide(doc, reviewer, comment, false)
+}
+
+// decide records a reviewer's decision on a doc in review
+func decide(doc *models.APIDoc, reviewer, comment string, approved bool) error {
+	if doc.Status != models.StatusInReview {
+		return fmt.Errorf("%w: only docs in review can be approved or rejected, the doc is %s", ErrInvalidTransition, status(doc))
+	}
+	doc.Status = models.StatusDraft
+	if approved {
+		doc.Status = models.StatusPublished
+	}
+	doc.Review = &models.Review{Reviewer: reviewer, Approved: approved, Comment: comment, ReviewedAt: time.Now()}
+	return nil
+}
+
+// status returns a doc's status for messages, naming the empty status of legacy docs published
+func status(doc *models.APIDoc) string {
+	if doc.Status == "" {
+		return models.StatusPublished
+	}
+	return doc.Status
+}
+
+// Published returns the published docs among docs
+func Published(docs []*models.APIDoc) []*models.APIDoc {
+	published := make([]*models.APIDoc, 0, len(docs))
+	for _, doc := range docs {
+		if doc.Published() {
+			published = append(published, doc)
+		}
+	}
+	return published
+}
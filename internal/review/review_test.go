@@ -0,0 +1,74 @@
+package review
+
+import (
+	"errors"
+	"testing"
+
+	"universal_api/internal/auth"
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// TestWorkflow tests moving a doc from draft through review to published
+func TestWorkflow(t *testing.T) {
+	store := NewWorkflowStorage(storage.NewMemoryStorage())
+	alice := &models.User{ID: "alice", Role: auth.RoleEditor}
+	bob := &models.User{ID: "bob", Role: auth.RoleEditor}
+	carol := &models.User{ID: "carol", Role: auth.RoleReviewer}
+
+	doc := &models.APIDoc{ID: "doc", Title: "Payments"}
+	if err := NewVisibleStorage(store, alice).SaveAPIDoc(doc); err != nil {
+		t.Fatalf("Failed to save doc: %v", err)
+	}
+	if doc.Status != models.StatusDraft || doc.SubmittedBy != "alice" {
+		t.Fatalf("Expected a draft submitted by alice, got %q by %q", doc.Status, doc.SubmittedBy)
+	}
+	if _, err := NewVisibleStorage(store, carol).GetAPIDoc("doc"); err == nil {
+		t.Error("Expected the draft to be hidden from reviewers")
+	}
+
+	if err := Approve(doc, "carol", ""); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected approving a draft to fail, got %v", err)
+	}
+	if err := Submit(doc); err != nil {
+		t.Fatalf("Failed to submit doc: %v", err)
+	}
+	store.SaveAPIDoc(doc)
+	if _, err := NewVisibleStorage(store, carol).GetAPIDoc("doc"); err != nil {
+		t.Errorf("Expected the doc in review to be visible to reviewers, got %v", err)
+	}
+	if docs, _ := NewVisibleStorage(store, bob).GetAllAPIDocs(); len(docs) != 0 {
+		t.Errorf("Expected the doc in review to be hidden from other editors, got %d docs", len(docs))
+	}
+	if docs, _ := NewVisibleStorage(store, bob).GetAllAPIDocsPartial(); len(docs) != 0 {
+		t.Errorf("Expected the doc in review to be hidden from other editors when partially loaded, got %d docs", len(docs))
+	}
+
+	if err := Approve(doc, "carol", "Looks good"); err != nil {
+		t.Fatalf("Failed to approve doc: %v", err)
+	}
+	store.SaveAPIDoc(doc)
+	if docs, _ := NewVisibleStorage(store, bob).GetAllAPIDocs(); len(docs) != 1 {
+		t.Errorf("Expected the published doc to be visible to everyone, got %d docs", len(docs))
+	}
+
+	// Refreshing the doc replaces it without a status, which must keep it published
+	refreshed := &models.APIDoc{ID: "doc", Title: "Payments v2"}
+	if err := store.SaveAPIDoc(refreshed); err != nil {
+		t.Fatalf("Failed to save refreshed doc: %v", err)
+	}
+	if refreshed.Status != models.StatusPublished || refreshed.SubmittedBy != "alice" || refreshed.Review == nil {
+		t.Errorf("Expected the refreshed doc to stay published, got %+v", refreshed)
+	}
+}
+
+// TestReject tests that rejecting a doc in review sends it back as a draft with the reviewer's comment
+func TestReject(t *testing.T) {
+	doc := &models.APIDoc{Status: models.StatusInReview}
+	if err := Reject(doc, "carol", "Add auth details"); err != nil {
+		t.Fatalf("Failed to reject doc: %v", err)
+	}
+	if doc.Status != models.StatusDraft || doc.Review.Approved || doc.Review.Comment != "Add auth details" {
+		t.Errorf("Expected a rejected draft, got %+v, %+v", doc, doc.Review)
+	}
+}
@@ -0,0 +1,120 @@
+package review
+
+import (
+	"errors"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// WorkflowStorage wraps a Storage, saving new docs as drafts and keeping the status of saved docs
+// when they're refreshed or re-imported
+type WorkflowStorage struct {
+	storage.Storage
+}
+
+// NewWorkflowStorage wraps store
+func NewWorkflowStorage(store storage.Storage) *WorkflowStorage {
+	return &WorkflowStorage{Storage: store}
+}
+
+// SaveAPIDoc saves the doc, as a draft if it's new and has no status
+func (s *WorkflowStorage) SaveAPIDoc(doc *models.APIDoc) error {
+	if doc.Status == "" {
+		if existing, err := s.Storage.GetAPIDoc(doc.ID); err == nil {
+			doc.Status = existing.Status
+			doc.Review = existing.Review
+			if doc.SubmittedBy == "" {
+				doc.SubmittedBy = existing.SubmittedBy
+			}
+		} else {
+			doc.Status = models.StatusDraft
+		}
+	}
+	return s.Storage.SaveAPIDoc(doc)
+}
+
+// VisibleStorage restricts a Storage to the docs a user can see. Docs hidden from the user are
+// reported as not found.
+type VisibleStorage struct {
+	storage.Storage
+	user *models.User
+}
+
+// NewVisibleStorage restricts store to the docs user can see
+func NewVisibleStorage(store storage.Storage, user *models.User) *VisibleStorage {
+	return &VisibleStorage{Storage: store, user: user}
+}
+
+// SaveAPIDoc saves the doc, refusing to overwrite docs hidden from the user. New docs are
+// recorded as submitted by the user.
+func (s *VisibleStorage) SaveAPIDoc(doc *models.APIDoc) error {
+	existing, err := s.Storage.GetAPIDoc(doc.ID)
+	switch {
+	case err == nil && !Visible(existing, s.user):
+		return errors.New("API doc not found")
+	case err != nil && doc.SubmittedBy == "" && s.user != nil:
+		doc.SubmittedBy = s.user.ID
+	}
+	return s.Storage.SaveAPIDoc(doc)
+}
+
+// GetAPIDoc gets an API doc the user can see
+func (s *VisibleStorage) GetAPIDoc(id string) (*models.APIDoc, error) {
+	doc, err := s.Storage.GetAPIDoc(id)
+	if err != nil {
+		return nil, err
+	}
+	if !Visible(doc, s.user) {
+		return nil, errors.New("API doc not found")
+	}
+	return doc, nil
+}
+
+// GetAllAPIDocs gets all API docs the user can see
+func (s *VisibleStorage) GetAllAPIDocs() ([]*models.APIDoc, error) {
+	docs, err := s.Storage.GetAllAPIDocs()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*models.APIDoc, 0, len(docs))
+	for _, doc := range docs {
+		if Visible(doc, s.user) {
+			filtered = append(filtered, doc)
+		}
+	}
+	return filtered, nil
+}
+
+// GetAllAPIDocsPartial gets all API docs the user can see with only the named parts loaded
+func (s *VisibleStorage) GetAllAPIDocsPartial(parts ...string) ([]*models.APIDoc, error) {
+	docs, err := s.Storage.GetAllAPIDocsPartial(parts...)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*models.APIDoc, 0, len(docs))
+	for _, doc := range docs {
+		if Visible(doc, s.user) {
+			filtered = append(filtered, doc)
+		}
+	}
+	return filtered, nil
+}
+
+// DeleteAPIDoc deletes an API doc the user can see
+func (s *VisibleStorage) DeleteAPIDoc(id string) error {
+	if _, err := s.GetAPIDoc(id); err != nil {
+		return err
+	}
+	return s.Storage.DeleteAPIDoc(id)
+}
+
+// GetAPIDocVersions gets the versions of an API doc the user can see
+func (s *VisibleStorage) GetAPIDocVersions(id string) ([]*models.APIDocVersion, error) {
+	if _, err := s.GetAPIDoc(id); err != nil {
+		return nil, err
+	}
+	return s.Storage.GetAPIDocVersions(id)
+}
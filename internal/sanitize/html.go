@@ -0,0 +1,171 @@
+package sanitize
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// markupPattern matches the HTML tags and comments that make a text markup. Only known tags count,
+// so plain text mentioning types like List<Item> is left alone.
+var markupPattern = regexp.MustCompile(`(?i)<!--|</?(a|abbr|b|blockquote|br|code|dd|div|dl|dt|em|embed|form|h[1-6]|hr|i|iframe|img|input|li|link|meta|noscript|object|ol|p|pre|script|section|small|span|strong|style|sub|sup|svg|table|tbody|td|template|th|thead|tr|u|ul)(\s[^>]*)?/?>`)
+
+// droppedElements are removed with their content: scripts, styles, embedded content, and forms
+var droppedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Iframe: true, atom.Object: true, atom.Embed: true,
+	atom.Noscript: true, atom.Template: true, atom.Head: true, atom.Svg: true, atom.Form: true,
+	atom.Button: true, atom.Input: true, atom.Select: true, atom.Textarea: true, atom.Link: true, atom.Meta: true,
+}
+
+// blockElements start a new paragraph
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Blockquote: true,
+	atom.Table: true, atom.Tr: true, atom.Ul: true, atom.Ol: true, atom.Dl: true, atom.Dt: true, atom.Dd: true, atom.Hr: true,
+}
+
+var (
+	blankLines = regexp.MustCompile(`\n{3,}`)
+	spaces     = regexp.MustCompile(`[ \t\r\n\f]+`)
+)
+
+// Text sanitizes scraped text that may contain HTML. Scripts, styles, and embedded content are
+// removed with their content; formatting is kept as markdown (links with safe URLs, bold, italics,
+// code, lists, headings) and all other tags are stripped, keeping their text. Text without HTML
+// markup is returned unchanged.
+func Text(text string) string {
+	if !markupPattern.MatchString(text) {
+		return text
+	}
+
+	nodes, err := html.ParseFragment(strings.NewReader(text), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return text
+	}
+
+	var b strings.Builder
+	for _, node := range nodes {
+		writeMarkdown(&b, node, false)
+	}
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// writeMarkdown writes the sanitized markdown of a node and its children
+func writeMarkdown(b *strings.Builder, node *html.Node, pre bool) {
+	switch node.Type {
+	case html.TextNode:
+		if pre {
+			b.WriteString(node.Data)
+		} else {
+			b.WriteString(spaces.ReplaceAllString(node.Data, " "))
+		}
+		return
+	case html.ElementNode:
+	default:
+		// Comments and doctypes carry no text
+		if node.Type != html.DocumentNode {
+			return
+		}
+	}
+
+	if droppedElements[node.DataAtom] {
+		return
+	}
+
+	children := func(pre bool) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			writeMarkdown(b, child, pre)
+		}
+	}
+
+	switch node.DataAtom {
+	case atom.Br:
+		b.WriteString("\n")
+	case atom.Img:
+		b.WriteString(attribute(node, "alt"))
+	case atom.A:
+		href := attribute(node, "href")
+		if !safeURL(href) {
+			children(pre)
+			return
+		}
+		b.WriteString("[")
+		children(pre)
+		b.WriteString("](" + href + ")")
+	case atom.B, atom.Strong:
+		b.WriteString("**")
+		children(pre)
+		b.WriteString("**")
+	case atom.I, atom.Em:
+		b.WriteString("*")
+		children(pre)
+		b.WriteString("*")
+	case atom.Code:
+		if pre {
+			children(true)
+			return
+		}
+		b.WriteString("`")
+		children(false)
+		b.WriteString("`")
+	case atom.Pre:
+		b.WriteString("\n\n```\n")
+		children(true)
+		b.WriteString("\n```\n\n")
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		b.WriteString("\n\n" + strings.Repeat("#", int(node.Data[1]-'0')) + " ")
+		children(pre)
+		b.WriteString("\n\n")
+	case atom.Li:
+		marker := "- "
+		if node.Parent != nil && node.Parent.DataAtom == atom.Ol {
+			marker = "1. "
+		}
+		b.WriteString("\n" + marker)
+		children(pre)
+	case atom.Td, atom.Th:
+		children(pre)
+		b.WriteString(" ")
+	default:
+		if blockElements[node.DataAtom] {
+			b.WriteString("\n\n")
+			children(pre)
+			b.WriteString("\n\n")
+			return
+		}
+		children(pre)
+	}
+}
+
+// attribute returns the value of an attribute of an element
+func attribute(node *html.Node, name string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == name {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// safeURL checks if a link URL is http, https, mailto, or relative, rather than e.g. javascript:
+func safeURL(href string) bool {
+	if href == "" || strings.ContainsAny(href, " ()") {
+		return false
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
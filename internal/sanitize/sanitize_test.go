@@ -0,0 +1,52 @@
+package sanitize
+
+import (
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestText tests stripping scripts and markup from scraped text while keeping its formatting as markdown
+func TestText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"plain text", "Returns a List<Item> of 2 < 3 items", "Returns a List<Item> of 2 < 3 items"},
+		{"script", `Lists pets<script>alert("x")</script>.`, "Lists pets."},
+		{"event handler", `<span onclick="steal()">Get a pet</span>`, "Get a pet"},
+		{"formatting", "<p>Use <b>GET</b> with <code>id</code></p><p>Or <em>not</em>.</p>", "Use **GET** with `id`\n\nOr *not*."},
+		{"links", `See <a href="https://example.com/docs">the docs</a> or <a href="javascript:alert(1)">this</a>`, "See [the docs](https://example.com/docs) or this"},
+		{"lists", "<ul><li>One</li><li>Two</li></ul>", "- One\n- Two"},
+		{"code block", "<pre><code>curl -X GET\n  /pets</code></pre>", "```\ncurl -X GET\n  /pets\n```"},
+		{"comment and iframe", `Hi<!-- secret --><iframe src="https://evil.example"></iframe>`, "Hi"},
+	}
+
+	for _, test := range tests {
+		if got := Text(test.text); got != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, got)
+		}
+	}
+}
+
+// TestDoc tests sanitizing every scraped text of a doc
+func TestDoc(t *testing.T) {
+	doc := &models.APIDoc{
+		Title:       "<b>Pets</b>",
+		Description: "<script>x()</script>Pet store",
+		Endpoints: []models.Endpoint{{
+			Summary:    "<i>List</i>",
+			Parameters: []models.Parameter{{Description: "<style>p{}</style>Page"}},
+			Responses:  []models.Response{{Description: "<div>OK</div>"}},
+		}},
+	}
+
+	Doc(doc)
+
+	endpoint := doc.Endpoints[0]
+	if doc.Title != "**Pets**" || doc.Description != "Pet store" || endpoint.Summary != "*List*" ||
+		endpoint.Parameters[0].Description != "Page" || endpoint.Responses[0].Description != "OK" {
+		t.Errorf("Unexpected sanitized doc: %+v", doc)
+	}
+}
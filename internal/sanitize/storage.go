@@ -0,0 +1,67 @@
+package sanitize
+
+import (
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// SanitizingStorage wraps a Storage, sanitizing the scraped text of docs before saving them
+type SanitizingStorage struct {
+	storage.Storage
+}
+
+// NewSanitizingStorage wraps store
+func NewSanitizingStorage(store storage.Storage) *SanitizingStorage {
+	return &SanitizingStorage{Storage: store}
+}
+
+// SaveAPIDoc sanitizes the doc before saving it
+func (s *SanitizingStorage) SaveAPIDoc(doc *models.APIDoc) error {
+	Doc(doc)
+	return s.Storage.SaveAPIDoc(doc)
+}
+
+// Doc sanitizes the title, provider, descriptions, summaries, auth scheme descriptions, and rate limit,
+// SLA, and free tier statements of a doc and its endpoints in place
+func Doc(doc *models.APIDoc) {
+	doc.Title = Text(doc.Title)
+	doc.Description = Text(doc.Description)
+	doc.Summary = Text(doc.Summary)
+	doc.Provider = Text(doc.Provider)
+	for i := range doc.AuthSchemes {
+		doc.AuthSchemes[i].Description = Text(doc.AuthSchemes[i].Description)
+	}
+	for i := range doc.RateLimits {
+		doc.RateLimits[i].Text = Text(doc.RateLimits[i].Text)
+	}
+	for i := range doc.SLAs {
+		doc.SLAs[i].Text = Text(doc.SLAs[i].Text)
+	}
+	if doc.Pricing != nil {
+		for i := range doc.Pricing.FreeTierLimits {
+			doc.Pricing.FreeTierLimits[i] = Text(doc.Pricing.FreeTierLimits[i])
+		}
+	}
+
+	for i := range doc.Endpoints {
+		endpoint := &doc.Endpoints[i]
+		endpoint.Summary = Text(endpoint.Summary)
+		endpoint.Description = Text(endpoint.Description)
+		for j := range endpoint.Parameters {
+			endpoint.Parameters[j].Description = Text(endpoint.Parameters[j].Description)
+		}
+		for j := range endpoint.Responses {
+			endpoint.Responses[j].Description = Text(endpoint.Responses[j].Description)
+		}
+		for j := range endpoint.Links {
+			endpoint.Links[j].Description = Text(endpoint.Links[j].Description)
+		}
+		for j := range endpoint.Callbacks {
+			endpoint.Callbacks[j].Summary = Text(endpoint.Callbacks[j].Summary)
+			endpoint.Callbacks[j].Description = Text(endpoint.Callbacks[j].Description)
+		}
+		for j := range endpoint.Messages {
+			endpoint.Messages[j].Summary = Text(endpoint.Messages[j].Summary)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package schema
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+	"strings"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// Extract builds the catalog entries of a doc's reusable schemas, with their fields and the
+// endpoint responses and other schemas using them
+func Extract(doc *models.APIDoc) []*models.Schema {
+	definitions := make(map[string]map[string]interface{}, len(doc.Schemas))
+	names := make([]string, 0, len(doc.Schemas))
+	for name, definition := range doc.Schemas {
+		var parsed map[string]interface{}
+		if err := json.Unmarshal([]byte(definition), &parsed); err != nil {
+			continue
+		}
+		definitions[name] = parsed
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	uses := make(map[string][]models.SchemaUse)
+	for _, endpoint := range doc.Endpoints {
+		for _, response := range endpoint.Responses {
+			if response.SchemaName != "" {
+				uses[response.SchemaName] = append(uses[response.SchemaName], models.SchemaUse{
+					Method: strings.ToUpper(endpoint.Method), Path: endpoint.Path, StatusCode: response.StatusCode,
+				})
+			}
+		}
+	}
+
+	schemas := make([]*models.Schema, 0, len(names))
+	for _, name := range names {
+		definition := definitions[name]
+		fields := schemaFields(definition, definitions)
+
+		nested := make(map[string]bool)
+		for _, field := range fields {
+			if field.Schema != "" && field.Schema != name && !nested[field.Schema] {
+				nested[field.Schema] = true
+				uses[field.Schema] = append(uses[field.Schema], models.SchemaUse{Schema: name})
+			}
+		}
+
+		description, _ := definition["description"].(string)
+		schemas = append(schemas, &models.Schema{
+			ID:          doc.ID + ":" + name,
+			Workspace:   storage.DocWorkspace(doc),
+			DocID:       doc.ID,
+			DocTitle:    doc.Title,
+			Name:        name,
+			Type:        schemaType(definition),
+			Description: description,
+			Fields:      fields,
+			Definition:  doc.Schemas[name],
+		})
+	}
+
+	for _, schema := range schemas {
+		schema.UsedBy = uses[schema.Name]
+		if schema.UsedBy == nil {
+			schema.UsedBy = []models.SchemaUse{}
+		}
+	}
+	return schemas
+}
+
+// schemaFields returns the properties of a schema, including those of the schemas it combines with allOf
+func schemaFields(definition map[string]interface{}, definitions map[string]map[string]interface{}) []models.SchemaField {
+	fields := []models.SchemaField{}
+	parts := []map[string]interface{}{definition}
+	if allOf, ok := definition["allOf"].([]interface{}); ok {
+		for _, part := range allOf {
+			partMap, _ := part.(map[string]interface{})
+			if ref := refName(partMap); ref != "" {
+				partMap = definitions[ref]
+			}
+			if partMap != nil {
+				parts = append(parts, partMap)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, part := range parts {
+		required := make(map[string]bool)
+		if list, ok := part["required"].([]interface{}); ok {
+			for _, name := range list {
+				if name, ok := name.(string); ok {
+					required[name] = true
+				}
+			}
+		}
+
+		properties, _ := part["properties"].(map[string]interface{})
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			property, _ := properties[name].(map[string]interface{})
+			fieldType, ref := propertyType(property)
+			description, _ := property["description"].(string)
+			fields = append(fields, models.SchemaField{
+				Name:        name,
+				Type:        fieldType,
+				Required:    required[name],
+				Description: description,
+				Schema:      ref,
+			})
+		}
+	}
+	return fields
+}
+
+// propertyType returns the type of a property and the name of the schema it references, if any
+func propertyType(property map[string]interface{}) (string, string) {
+	if ref := refName(property); ref != "" {
+		return ref, ref
+	}
+	for _, key := range []string{"allOf", "oneOf", "anyOf"} {
+		if variants, ok := property[key].([]interface{}); ok && len(variants) == 1 {
+			if variant, ok := variants[0].(map[string]interface{}); ok {
+				return propertyType(variant)
+			}
+		}
+	}
+
+	typeName := schemaType(property)
+	if typeName == "array" {
+		items, _ := property["items"].(map[string]interface{})
+		if itemType, ref := propertyType(items); itemType != "" {
+			return "array<" + itemType + ">", ref
+		}
+	}
+	return typeName, ""
+}
+
+// schemaType returns the JSON schema type of a schema, the first non-null one of a type list,
+// or object for schemas with properties
+func schemaType(definition map[string]interface{}) string {
+	switch value := definition["type"].(type) {
+	case string:
+		return value
+	case []interface{}:
+		for _, item := range value {
+			if name, ok := item.(string); ok && name != "null" {
+				return name
+			}
+		}
+	}
+	if _, ok := definition["properties"]; ok {
+		return "object"
+	}
+	return ""
+}
+
+// refName returns the name of the schema a $ref to #/components/schemas or #/definitions points to
+func refName(node map[string]interface{}) string {
+	ref, _ := node["$ref"].(string)
+	for _, prefix := range []string{"#/components/schemas/", "#/definitions/"} {
+		if strings.HasPrefix(ref, prefix) {
+			return strings.TrimPrefix(ref, prefix)
+		}
+	}
+	return ""
+}
+
+// Search returns the schemas matching a query and having a field, best matches first. The query
+// matches schema names, descriptions, and field names, case-insensitively; either may be empty.
+func Search(schemas []*models.Schema, query, field string) []*models.Schema {
+	query = strings.ToLower(strings.TrimSpace(query))
+	type match struct {
+		schema *models.Schema
+		score  int
+	}
+
+	var matches []match
+	for _, schema := range schemas {
+		if field != "" && !hasField(schema, field) {
+			continue
+		}
+
+		score := 1
+		if query != "" {
+			score = 0
+			name := strings.ToLower(schema.Name)
+			switch {
+			case name == query:
+				score = 4
+			case strings.Contains(name, query):
+				score = 3
+			case strings.Contains(strings.ToLower(schema.Description), query):
+				score = 2
+			default:
+				for _, f := range schema.Fields {
+					if strings.Contains(strings.ToLower(f.Name), query) {
+						score = 1
+						break
+					}
+				}
+			}
+		}
+		if score > 0 {
+			matches = append(matches, match{schema, score})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+	results := make([]*models.Schema, len(matches))
+	for i, m := range matches {
+		results[i] = m.schema
+	}
+	return results
+}
+
+// hasField checks if a schema has a field with the given name, case-insensitively
+func hasField(schema *models.Schema, name string) bool {
+	for _, field := range schema.Fields {
+		if strings.EqualFold(field.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// CatalogingStorage wraps a Storage and keeps the schema catalog in sync with saved and deleted docs
+type CatalogingStorage struct {
+	storage.Storage
+	schemas storage.SchemaStorage
+}
+
+// NewCatalogingStorage wraps store, cataloging the schemas of the docs it already holds
+func NewCatalogingStorage(store storage.Storage, schemas storage.SchemaStorage) *CatalogingStorage {
+	docs, err := store.GetAllAPIDocs()
+	if err != nil {
+		log.Printf("Failed to catalog schemas of existing API docs: %v", err)
+	}
+	for _, doc := range docs {
+		if err := schemas.SaveDocSchemas(doc.ID, Extract(doc)); err != nil {
+			log.Printf("Failed to catalog schemas of %s: %v", doc.ID, err)
+		}
+	}
+
+	return &CatalogingStorage{Storage: store, schemas: schemas}
+}
+
+// SaveAPIDoc saves the doc and replaces its schemas in the catalog
+func (s *CatalogingStorage) SaveAPIDoc(doc *models.APIDoc) error {
+	if err := s.Storage.SaveAPIDoc(doc); err != nil {
+		return err
+	}
+	return s.schemas.SaveDocSchemas(doc.ID, Extract(doc))
+}
+
+// DeleteAPIDoc deletes the doc and removes its schemas from the catalog
+func (s *CatalogingStorage) DeleteAPIDoc(id string) error {
+	if err := s.Storage.DeleteAPIDoc(id); err != nil {
+		return err
+	}
+	return s.schemas.SaveDocSchemas(id, nil)
+}
@@ -0,0 +1,81 @@
+package schema
+
+import (
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestExtract tests building catalog entries with fields, allOf, array references and uses
+func TestExtract(t *testing.T) {
+	doc := &models.APIDoc{
+		ID:    "billing",
+		Title: "Billing API",
+		Schemas: map[string]string{
+			"Base":     `{"type": "object", "properties": {"id": {"type": "string"}}, "required": ["id"]}`,
+			"Invoice":  `{"description": "A bill", "allOf": [{"$ref": "#/components/schemas/Base"}, {"type": "object", "properties": {"amount": {"type": "number"}, "lines": {"type": "array", "items": {"$ref": "#/components/schemas/LineItem"}}}}]}`,
+			"LineItem": `{"type": "object", "properties": {"sku": {"type": "string"}}}`,
+		},
+		Endpoints: []models.Endpoint{{
+			Method:    "get",
+			Path:      "/invoices/{id}",
+			Responses: []models.Response{{StatusCode: 200, SchemaName: "Invoice"}},
+		}},
+	}
+
+	schemas := Extract(doc)
+	if len(schemas) != 3 {
+		t.Fatalf("Expected 3 schemas, got %d", len(schemas))
+	}
+
+	invoice := schemas[1]
+	if invoice.ID != "billing:Invoice" || invoice.Description != "A bill" {
+		t.Errorf("Unexpected invoice schema: %+v", invoice)
+	}
+
+	types := make(map[string]models.SchemaField)
+	for _, field := range invoice.Fields {
+		types[field.Name] = field
+	}
+	if !types["id"].Required {
+		t.Errorf("Expected the id field inherited via allOf to be required, got %+v", types["id"])
+	}
+	if lines := types["lines"]; lines.Type != "array<LineItem>" || lines.Schema != "LineItem" {
+		t.Errorf("Expected lines to be array<LineItem>, got %+v", lines)
+	}
+
+	if uses := invoice.UsedBy; len(uses) != 1 || uses[0].Method != "GET" || uses[0].StatusCode != 200 {
+		t.Errorf("Expected Invoice to be used by GET 200, got %+v", uses)
+	}
+	if uses := schemas[2].UsedBy; len(uses) != 1 || uses[0].Schema != "Invoice" {
+		t.Errorf("Expected LineItem to be used by Invoice, got %+v", uses)
+	}
+}
+
+// TestSearch tests ranking schemas by name, description and field matches
+func TestSearch(t *testing.T) {
+	schemas := []*models.Schema{
+		{Name: "Customer", Fields: []models.SchemaField{{Name: "invoice_email"}}},
+		{Name: "InvoiceLine", Fields: []models.SchemaField{{Name: "amount"}}},
+		{Name: "Invoice", Fields: []models.SchemaField{{Name: "amount"}}},
+		{Name: "Payment", Description: "Settles an invoice"},
+	}
+
+	var names []string
+	for _, schema := range Search(schemas, "invoice", "") {
+		names = append(names, schema.Name)
+	}
+	expected := []string{"Invoice", "InvoiceLine", "Payment", "Customer"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, names)
+		}
+	}
+
+	if results := Search(schemas, "invoice", "AMOUNT"); len(results) != 2 {
+		t.Errorf("Expected 2 schemas with an amount field, got %d", len(results))
+	}
+}
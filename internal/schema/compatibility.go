@@ -0,0 +1,153 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"universal_api/internal/models"
+)
+
+// Compatibility reports whether values of a source schema can be consumed where a target schema is expected
+type Compatibility struct {
+	Source     string         `json:"source"` // schema IDs
+	Target     string         `json:"target"`
+	Compatible bool           `json:"compatible"`
+	Errors     []string       `json:"errors"`   // mismatches that break consumers of the target
+	Warnings   []string       `json:"warnings"` // mismatches that may break them for some values
+	Mappings   []FieldMapping `json:"mappings"`
+	Unmapped   []string       `json:"unmapped"` // source fields no target field maps to
+}
+
+// FieldMapping maps a source field to the target field it fills, as dotted paths such as lines[].sku
+type FieldMapping struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Exact  bool   `json:"exact"` // false for fields matched by a similar name, e.g. customer_id and customerId
+}
+
+// Resolver looks up a schema of the catalog by its doc's ID and its name
+type Resolver func(docID, name string) *models.Schema
+
+// CheckCompatibility compares a source schema's fields with a target schema's, following the
+// schemas nested fields reference through resolve
+func CheckCompatibility(source, target *models.Schema, resolve Resolver) *Compatibility {
+	result := &Compatibility{
+		Source:   source.ID,
+		Target:   target.ID,
+		Errors:   []string{},
+		Warnings: []string{},
+		Mappings: []FieldMapping{},
+		Unmapped: []string{},
+	}
+	result.compareSchemas(source, target, "", resolve, make(map[string]bool))
+	result.Compatible = len(result.Errors) == 0
+	return result
+}
+
+// compareSchemas compares the fields of two schemas nested at path
+func (r *Compatibility) compareSchemas(source, target *models.Schema, path string, resolve Resolver, visited map[string]bool) {
+	// Recursive schemas compare the same pair again; once is enough
+	key := source.ID + ">" + target.ID
+	if visited[key] {
+		return
+	}
+	visited[key] = true
+
+	mapped := make(map[string]bool)
+	for _, targetField := range target.Fields {
+		targetPath := path + targetField.Name
+		sourceField, exact := matchField(source.Fields, targetField.Name)
+		if sourceField == nil {
+			if targetField.Required {
+				r.Errors = append(r.Errors, fmt.Sprintf("%s: required field is missing", targetPath))
+			} else {
+				r.Warnings = append(r.Warnings, fmt.Sprintf("%s: optional field is not provided", targetPath))
+			}
+			continue
+		}
+
+		mapped[sourceField.Name] = true
+		r.Mappings = append(r.Mappings, FieldMapping{Source: path + sourceField.Name, Target: targetPath, Exact: exact})
+		if targetField.Required && !sourceField.Required {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("%s: required field is optional in the source", targetPath))
+		}
+		r.compareFields(source, target, *sourceField, targetField, targetPath, resolve, visited)
+	}
+
+	for _, field := range source.Fields {
+		if !mapped[field.Name] {
+			r.Unmapped = append(r.Unmapped, path+field.Name)
+		}
+	}
+}
+
+// compareFields compares the types of a source field and the target field it fills
+func (r *Compatibility) compareFields(source, target *models.Schema, sourceField, targetField models.SchemaField, path string, resolve Resolver, visited map[string]bool) {
+	sourceType, sourceDepth := arrayItemType(sourceField.Type)
+	targetType, targetDepth := arrayItemType(targetField.Type)
+	if sourceDepth != targetDepth {
+		r.Errors = append(r.Errors, fmt.Sprintf("%s: type %s cannot be used as %s", path, sourceField.Type, targetField.Type))
+		return
+	}
+	path += strings.Repeat("[]", targetDepth)
+
+	var sourceSchema, targetSchema *models.Schema
+	if sourceField.Schema != "" {
+		if sourceSchema = resolve(source.DocID, sourceField.Schema); sourceSchema != nil {
+			sourceType = sourceSchema.Type
+		}
+	}
+	if targetField.Schema != "" {
+		if targetSchema = resolve(target.DocID, targetField.Schema); targetSchema != nil {
+			targetType = targetSchema.Type
+		}
+	}
+
+	switch {
+	case targetType == "" || sourceType == targetType:
+	case sourceType == "integer" && targetType == "number":
+	case sourceType == "number" && targetType == "integer":
+		r.Warnings = append(r.Warnings, fmt.Sprintf("%s: number may not be an integer", path))
+	default:
+		r.Errors = append(r.Errors, fmt.Sprintf("%s: type %s cannot be used as %s", path, sourceField.Type, targetField.Type))
+		return
+	}
+
+	switch {
+	case sourceSchema != nil && targetSchema != nil:
+		r.compareSchemas(sourceSchema, targetSchema, path+".", resolve, visited)
+	case targetSchema != nil && len(targetSchema.Fields) > 0:
+		r.Warnings = append(r.Warnings, fmt.Sprintf("%s: nested fields of %s cannot be checked", path, targetField.Schema))
+	}
+}
+
+// matchField finds the source field filling a target field: the one with the same name, or else
+// one whose name only differs in case and separators
+func matchField(fields []models.SchemaField, name string) (*models.SchemaField, bool) {
+	for i := range fields {
+		if fields[i].Name == name {
+			return &fields[i], true
+		}
+	}
+	for i := range fields {
+		if normalizeFieldName(fields[i].Name) == normalizeFieldName(name) {
+			return &fields[i], false
+		}
+	}
+	return nil, false
+}
+
+// normalizeFieldName lowercases a field name and drops its separators, so customer_id matches customerId
+func normalizeFieldName(name string) string {
+	return strings.NewReplacer("_", "", "-", "", ".", "", " ", "").Replace(strings.ToLower(name))
+}
+
+// arrayItemType strips the array<...> wrappers of a field type, returning the item type and the nesting depth
+func arrayItemType(fieldType string) (string, int) {
+	depth := 0
+	for strings.HasPrefix(fieldType, "array<") && strings.HasSuffix(fieldType, ">") {
+		fieldType = strings.TrimSuffix(strings.TrimPrefix(fieldType, "array<"), ">")
+		depth++
+	}
+	return fieldType, depth
+}
@@ -0,0 +1,76 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestCheckCompatibility tests comparing a vendor schema with an expected one, including nested schemas
+func TestCheckCompatibility(t *testing.T) {
+	catalog := map[string]*models.Schema{
+		"a:Order": {ID: "a:Order", DocID: "a", Name: "Order", Type: "object", Fields: []models.SchemaField{
+			{Name: "id", Type: "integer", Required: true},
+			{Name: "customer_id", Type: "string"},
+			{Name: "lines", Type: "array<Line>", Schema: "Line", Required: true},
+			{Name: "note", Type: "string"},
+		}},
+		"a:Line": {ID: "a:Line", DocID: "a", Name: "Line", Type: "object", Fields: []models.SchemaField{
+			{Name: "qty", Type: "number"},
+		}},
+		"b:Order": {ID: "b:Order", DocID: "b", Name: "Order", Type: "object", Fields: []models.SchemaField{
+			{Name: "id", Type: "number", Required: true},
+			{Name: "customerId", Type: "string", Required: true},
+			{Name: "lines", Type: "array<Item>", Schema: "Item"},
+			{Name: "total", Type: "number", Required: true},
+			{Name: "currency", Type: "string"},
+		}},
+		"b:Item": {ID: "b:Item", DocID: "b", Name: "Item", Type: "object", Fields: []models.SchemaField{
+			{Name: "qty", Type: "integer"},
+			{Name: "sku", Type: "boolean"},
+		}},
+	}
+	resolve := func(docID, name string) *models.Schema { return catalog[docID+":"+name] }
+
+	result := CheckCompatibility(catalog["a:Order"], catalog["b:Order"], resolve)
+
+	if result.Compatible {
+		t.Error("Expected the schemas to be incompatible")
+	}
+	if expected := []string{"total: required field is missing"}; !reflect.DeepEqual(result.Errors, expected) {
+		t.Errorf("Expected errors %v, got %v", expected, result.Errors)
+	}
+	expectedWarnings := []string{
+		"customerId: required field is optional in the source",
+		"lines[].qty: number may not be an integer",
+		"lines[].sku: optional field is not provided",
+		"currency: optional field is not provided",
+	}
+	if !reflect.DeepEqual(result.Warnings, expectedWarnings) {
+		t.Errorf("Expected warnings %v, got %v", expectedWarnings, result.Warnings)
+	}
+	if mapping := result.Mappings[1]; mapping.Source != "customer_id" || mapping.Target != "customerId" || mapping.Exact {
+		t.Errorf("Expected customer_id to map to customerId by similar name, got %+v", mapping)
+	}
+	if !reflect.DeepEqual(result.Unmapped, []string{"note"}) {
+		t.Errorf("Expected note to be unmapped, got %v", result.Unmapped)
+	}
+
+	if result := CheckCompatibility(catalog["a:Line"], catalog["a:Order"], resolve); len(result.Errors) != 2 {
+		t.Errorf("Expected missing id and lines errors, got %v", result.Errors)
+	}
+}
+
+// TestCheckCompatibilityTypeMismatch tests reporting fields whose types cannot be converted
+func TestCheckCompatibilityTypeMismatch(t *testing.T) {
+	source := &models.Schema{ID: "a:S", Fields: []models.SchemaField{{Name: "tags", Type: "string"}, {Name: "count", Type: "string"}}}
+	target := &models.Schema{ID: "b:T", Fields: []models.SchemaField{{Name: "tags", Type: "array<string>"}, {Name: "count", Type: "integer"}}}
+
+	result := CheckCompatibility(source, target, func(string, string) *models.Schema { return nil })
+
+	expected := []string{"tags: type string cannot be used as array<string>", "count: type string cannot be used as integer"}
+	if !reflect.DeepEqual(result.Errors, expected) {
+		t.Errorf("Expected errors %v, got %v", expected, result.Errors)
+	}
+}
@@ -0,0 +1,167 @@
+package schema
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+
+	"universal_api/internal/models"
+)
+
+// inferredDescription describes responses added from recorded examples
+const inferredDescription = "Inferred from recorded examples"
+
+// Infer derives a JSON Schema from sample values: object properties are the union of
+// the observed fields, fields present in every sample are required, and nulls make a schema nullable
+func Infer(samples []interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+
+	var types []string
+	seen := make(map[string]bool)
+	var objects []map[string]interface{}
+	var items []interface{}
+	nullable := false
+
+	for _, sample := range samples {
+		name := typeOf(sample)
+		switch v := sample.(type) {
+		case nil:
+			nullable = true
+			continue
+		case float64:
+			if v == math.Trunc(v) {
+				name = "integer"
+			}
+		case map[string]interface{}:
+			objects = append(objects, v)
+		case []interface{}:
+			items = append(items, v...)
+		}
+		if !seen[name] {
+			seen[name] = true
+			types = append(types, name)
+		}
+	}
+
+	// A fractional sample widens integer to number
+	if seen["integer"] && seen["number"] {
+		types = removeType(types, "integer")
+	}
+
+	switch len(types) {
+	case 0:
+		return result
+	case 1:
+		result["type"] = types[0]
+	default:
+		sort.Strings(types)
+		list := make([]interface{}, len(types))
+		for i, name := range types {
+			list[i] = name
+		}
+		result["type"] = list
+	}
+	if nullable {
+		result["nullable"] = true
+	}
+
+	if len(objects) > 0 {
+		inferObject(result, objects)
+	}
+	if seen["array"] {
+		result["items"] = Infer(items)
+	}
+
+	return result
+}
+
+// inferObject fills in the properties and required fields of object samples
+func inferObject(result map[string]interface{}, objects []map[string]interface{}) {
+	values := make(map[string][]interface{})
+	counts := make(map[string]int)
+	for _, object := range objects {
+		for key, value := range object {
+			values[key] = append(values[key], value)
+			counts[key]++
+		}
+	}
+
+	properties := make(map[string]interface{})
+	var required []string
+	for key, samples := range values {
+		properties[key] = Infer(samples)
+		if counts[key] == len(objects) {
+			required = append(required, key)
+		}
+	}
+	result["properties"] = properties
+
+	if len(required) > 0 {
+		sort.Strings(required)
+		list := make([]interface{}, len(required))
+		for i, key := range required {
+			list[i] = key
+		}
+		result["required"] = list
+	}
+}
+
+// removeType removes a type name from a list of type names
+func removeType(types []string, name string) []string {
+	var kept []string
+	for _, t := range types {
+		if t != name {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+// InferResponses fills in missing response schemas of an endpoint from the JSON bodies
+// of its recorded examples. Documented schemas are never overwritten; previously
+// inferred schemas are recomputed, and status codes only seen in examples are added.
+func InferResponses(endpoint *models.Endpoint) {
+	samples := make(map[int][]interface{})
+	var codes []int
+	for _, example := range endpoint.Examples {
+		var value interface{}
+		if err := json.Unmarshal([]byte(example.Response.Body), &value); err != nil {
+			continue
+		}
+		code := example.Response.StatusCode
+		if _, ok := samples[code]; !ok {
+			codes = append(codes, code)
+		}
+		samples[code] = append(samples[code], value)
+	}
+	sort.Ints(codes)
+
+	for _, code := range codes {
+		inferred, err := json.Marshal(Infer(samples[code]))
+		if err != nil {
+			continue
+		}
+
+		found := false
+		for i := range endpoint.Responses {
+			response := &endpoint.Responses[i]
+			if response.StatusCode != code {
+				continue
+			}
+			found = true
+			if response.Schema == "" || response.SchemaInferred {
+				response.Schema = string(inferred)
+				response.SchemaInferred = true
+			}
+		}
+
+		if !found {
+			endpoint.Responses = append(endpoint.Responses, models.Response{
+				StatusCode:     code,
+				Description:    inferredDescription,
+				Schema:         string(inferred),
+				SchemaInferred: true,
+			})
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestInfer tests inferring a schema from the union of observed samples
+func TestInfer(t *testing.T) {
+	var samples []interface{}
+	for _, body := range []string{
+		`{"id": 1, "name": "Ada", "tags": ["a"], "score": 1}`,
+		`{"id": 2, "name": null, "score": 2.5, "email": "grace@example.com"}`,
+	} {
+		var value interface{}
+		json.Unmarshal([]byte(body), &value)
+		samples = append(samples, value)
+	}
+
+	inferred := Infer(samples)
+
+	if inferred["type"] != "object" {
+		t.Fatalf("Expected an object schema, got %v", inferred["type"])
+	}
+
+	if required := inferred["required"]; !reflect.DeepEqual(required, []interface{}{"id", "name", "score"}) {
+		t.Errorf("Expected id, name and score to be required, got %v", required)
+	}
+
+	properties := inferred["properties"].(map[string]interface{})
+	expected := map[string]map[string]interface{}{
+		"id":    {"type": "integer"},
+		"name":  {"type": "string", "nullable": true},
+		"score": {"type": "number"},
+		"email": {"type": "string"},
+		"tags":  {"type": "array", "items": map[string]interface{}{"type": "string"}},
+	}
+	for name, want := range expected {
+		if got := properties[name]; !reflect.DeepEqual(got, map[string]interface{}(want)) {
+			t.Errorf("Expected %s schema %v, got %v", name, want, got)
+		}
+	}
+
+	// Every sample must validate against the inferred schema
+	schemaJSON, _ := json.Marshal(inferred)
+	for _, sample := range samples {
+		if errs, _ := Validate(string(schemaJSON), sample); len(errs) > 0 {
+			t.Errorf("Expected sample to validate against inferred schema, got %v", errs)
+		}
+	}
+}
+
+// TestInferResponses tests that only missing or inferred response schemas are filled in
+func TestInferResponses(t *testing.T) {
+	endpoint := &models.Endpoint{
+		Responses: []models.Response{
+			{StatusCode: 200, Description: "OK"},
+			{StatusCode: 404, Schema: `{"type": "object"}`},
+		},
+		Examples: []models.Example{
+			{Response: models.ExampleResponse{StatusCode: 200, Body: `{"id": 1}`}},
+			{Response: models.ExampleResponse{StatusCode: 404, Body: `{"error": "missing"}`}},
+			{Response: models.ExampleResponse{StatusCode: 500, Body: `not json`}},
+			{Response: models.ExampleResponse{StatusCode: 201, Body: `[]`}},
+		},
+	}
+
+	InferResponses(endpoint)
+
+	if len(endpoint.Responses) != 3 {
+		t.Fatalf("Expected a 201 response to be added, got %+v", endpoint.Responses)
+	}
+
+	if !endpoint.Responses[0].SchemaInferred || endpoint.Responses[0].Schema == "" {
+		t.Errorf("Expected the 200 schema to be inferred, got %+v", endpoint.Responses[0])
+	}
+
+	if endpoint.Responses[1].SchemaInferred || endpoint.Responses[1].Schema != `{"type": "object"}` {
+		t.Errorf("Expected the documented 404 schema to be kept, got %+v", endpoint.Responses[1])
+	}
+
+	if added := endpoint.Responses[2]; added.StatusCode != 201 || added.Schema != `{"items":{},"type":"array"}` {
+		t.Errorf("Expected an inferred 201 response, got %+v", added)
+	}
+}
@@ -0,0 +1,177 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// Validate checks a decoded JSON value against a JSON Schema (the OpenAPI subset:
+// type, nullable, properties, required, additionalProperties, items, enum, allOf, anyOf, oneOf)
+// and returns a description of every mismatch
+func Validate(schemaJSON string, value interface{}) ([]string, error) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(schemaJSON), &schema); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	var errs []string
+	validate(schema, value, "$", &errs)
+	return errs, nil
+}
+
+// validate appends the mismatches between value and schema at path to errs
+func validate(schema map[string]interface{}, value interface{}, path string, errs *[]string) {
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable || allowsType(schema, "null") {
+			return
+		}
+		if _, hasType := schema["type"]; hasType {
+			*errs = append(*errs, fmt.Sprintf("%s: expected %v, got null", path, schema["type"]))
+		}
+		return
+	}
+
+	if _, hasType := schema["type"]; hasType && !matchesType(schema, value) {
+		*errs = append(*errs, fmt.Sprintf("%s: expected %v, got %s", path, schema["type"], typeOf(value)))
+		return
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && !inEnum(enum, value) {
+		*errs = append(*errs, fmt.Sprintf("%s: value %v is not one of %v", path, value, enum))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		validateObject(schema, v, path, errs)
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				validate(items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	}
+
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			if subSchema, ok := sub.(map[string]interface{}); ok {
+				validate(subSchema, value, path, errs)
+			}
+		}
+	}
+
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		alternatives, ok := schema[keyword].([]interface{})
+		if !ok {
+			continue
+		}
+		matched := false
+		for _, sub := range alternatives {
+			subSchema, ok := sub.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			var subErrs []string
+			validate(subSchema, value, path, &subErrs)
+			if len(subErrs) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			*errs = append(*errs, fmt.Sprintf("%s: value does not match any %s schema", path, keyword))
+		}
+	}
+}
+
+// validateObject validates an object's required fields and properties
+func validateObject(schema map[string]interface{}, value map[string]interface{}, path string, errs *[]string) {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if key, ok := name.(string); ok {
+				if _, present := value[key]; !present {
+					*errs = append(*errs, fmt.Sprintf("%s: missing required property %s", path, key))
+				}
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if propSchema, ok := properties[key].(map[string]interface{}); ok {
+			validate(propSchema, value[key], path+"."+key, errs)
+			continue
+		}
+		if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+			*errs = append(*errs, fmt.Sprintf("%s: unexpected property %s", path, key))
+		}
+	}
+}
+
+// matchesType checks the value against the schema's type (a string or a list of strings)
+func matchesType(schema map[string]interface{}, value interface{}) bool {
+	actual := typeOf(value)
+	if allowsType(schema, actual) {
+		return true
+	}
+	// Integral numbers also satisfy "integer"
+	if actual == "number" && allowsType(schema, "integer") {
+		f, _ := value.(float64)
+		return f == math.Trunc(f)
+	}
+	return false
+}
+
+// allowsType checks if the schema's type includes the named type
+func allowsType(schema map[string]interface{}, name string) bool {
+	switch t := schema["type"].(type) {
+	case string:
+		return t == name
+	case []interface{}:
+		for _, item := range t {
+			if item == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// typeOf returns the JSON Schema type name of a decoded JSON value
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64, json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "unknown"
+	}
+}
+
+// inEnum checks if the value is one of the enum values
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if reflect.DeepEqual(allowed, value) {
+			return true
+		}
+	}
+	return false
+}
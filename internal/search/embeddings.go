@@ -0,0 +1,178 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Embedder computes embedding vectors for texts
+type Embedder interface {
+	Embed(texts []string) ([][]float32, error)
+}
+
+// NewEmbedder creates the embedding provider with the given name: "hash" for the
+// built-in local embedder, or "openai" for any OpenAI-compatible embeddings API
+func NewEmbedder(provider, baseURL, model, apiKey string) (Embedder, error) {
+	switch provider {
+	case "hash":
+		return &HashEmbedder{Dimensions: DefaultHashDimensions}, nil
+	case "openai":
+		return NewOpenAIEmbedder(baseURL, model, apiKey), nil
+	default:
+		return nil, fmt.Errorf("unsupported embedding provider: %s", provider)
+	}
+}
+
+// DefaultHashDimensions is the vector size of the HashEmbedder
+const DefaultHashDimensions = 512
+
+// HashEmbedder embeds texts locally by hashing their words and word stems into a
+// fixed-size vector. It needs no external service but only captures word overlap,
+// not meaning; use a model-backed provider for real semantic matches.
+type HashEmbedder struct {
+	Dimensions int
+}
+
+// Embed implements the Embedder interface
+func (e *HashEmbedder) Embed(texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, e.Dimensions)
+		for _, token := range Tokenize(text) {
+			e.add(vector, token, 1)
+			// Crude stemming so "subscription" and "subscriptions" land close together
+			if len(token) > 5 {
+				e.add(vector, token[:5], 0.5)
+			}
+		}
+		vectors[i] = normalize(vector)
+	}
+	return vectors, nil
+}
+
+// add adds a signed weight for the token to its hashed dimension
+func (e *HashEmbedder) add(vector []float32, token string, weight float32) {
+	h := fnv.New32a()
+	h.Write([]byte(token))
+	sum := h.Sum32()
+	if sum&1 == 1 {
+		weight = -weight
+	}
+	vector[(sum>>1)%uint32(len(vector))] += weight
+}
+
+// OpenAIEmbedder computes embeddings with an OpenAI-compatible /embeddings API
+type OpenAIEmbedder struct {
+	baseURL string
+	model   string
+	apiKey  string
+	client  *http.Client
+}
+
+// openAIEmbeddingRequest is the body of an embeddings request
+type openAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// openAIEmbeddingResponse is the body of an embeddings response
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// NewOpenAIEmbedder creates an OpenAIEmbedder, defaulting to the OpenAI API and text-embedding-3-small
+func NewOpenAIEmbedder(baseURL, model, apiKey string) *OpenAIEmbedder {
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+	if model == "" {
+		model = "text-embedding-3-small"
+	}
+	return &OpenAIEmbedder{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		model:   model,
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Embed implements the Embedder interface
+func (e *OpenAIEmbedder) Embed(texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	body, err := json.Marshal(openAIEmbeddingRequest{Model: e.model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embeddings API returned status code: %d", resp.StatusCode)
+	}
+
+	var parsed openAIEmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse embeddings response: %w", err)
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range parsed.Data {
+		if item.Index >= 0 && item.Index < len(vectors) {
+			vectors[item.Index] = normalize(item.Embedding)
+		}
+	}
+	return vectors, nil
+}
+
+// normalize scales a vector to unit length so cosine similarity is a dot product
+func normalize(vector []float32) []float32 {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return vector
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+	return vector
+}
+
+// cosine returns the cosine similarity of two unit vectors
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
@@ -0,0 +1,235 @@
+package search
+
+import (
+	"log"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// Facet names
+const (
+	FacetMethod      = "method"
+	FacetAuth        = "auth"
+	FacetTag         = "tag"
+	FacetDomain      = "domain"
+	FacetDeprecated  = "deprecated"
+	FacetHasExamples = "has_examples"
+	FacetLanguage    = "language"
+	FacetLifecycle   = "lifecycle"
+	FacetKind        = "kind"
+)
+
+// Facets lists every facet in display order
+var Facets = []string{FacetMethod, FacetAuth, FacetTag, FacetDomain, FacetDeprecated, FacetHasExamples, FacetLanguage, FacetLifecycle, FacetKind}
+
+// Entry is a single indexed endpoint
+type Entry struct {
+	Workspace  string   `json:"workspace"`
+	DocID      string   `json:"doc_id"`
+	DocTitle   string   `json:"doc_title"`
+	DocIcon    string   `json:"doc_icon,omitempty"` // URL of the provider's logo or the site's icon
+	EndpointID string   `json:"endpoint_id"`
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Summary    string   `json:"summary"`
+	Tags       []string `json:"tags,omitempty"`
+	Deprecated bool     `json:"deprecated,omitempty"`
+	Lifecycle  string   `json:"lifecycle,omitempty"` // lifecycle stage of the doc
+	Kind       string   `json:"kind"`                // request, webhook, callback, or event
+
+	// facets maps each facet to the entry's values for it
+	facets map[string][]string
+	// terms maps each token to its weight in the entry
+	terms map[string]int
+	// vector is the embedding of the entry's text, when an embedder is configured
+	vector []float32
+}
+
+// Index is an in-memory, catalog-wide index of endpoints
+type Index struct {
+	mu       sync.RWMutex
+	entries  map[string][]*Entry // by doc ID
+	embedder Embedder
+	// popularity returns the number of views of a doc, to boost popular docs
+	popularity func(docID string) int
+}
+
+// NewIndex creates an empty Index
+func NewIndex() *Index {
+	return &Index{entries: make(map[string][]*Entry)}
+}
+
+// SetEmbedder enables semantic search, embedding every doc indexed from now on
+func (idx *Index) SetEmbedder(embedder Embedder) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.embedder = embedder
+}
+
+// SetPopularity sets the view counts source used to boost popular docs in search ranking
+func (idx *Index) SetPopularity(popularity func(docID string) int) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.popularity = popularity
+}
+
+// SemanticEnabled reports whether an embedder is configured
+func (idx *Index) SemanticEnabled() bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.embedder != nil
+}
+
+// Update indexes a doc's endpoints, replacing any previously indexed version of the doc. Docs that
+// aren't published are removed from the index instead.
+func (idx *Index) Update(doc *models.APIDoc) {
+	if !doc.Published() {
+		idx.Remove(doc.ID)
+		return
+	}
+	domain := docDomain(doc)
+
+	entries := make([]*Entry, 0, len(doc.Endpoints))
+	texts := make([]string, 0, len(doc.Endpoints))
+	for _, endpoint := range doc.Endpoints {
+		entry := &Entry{
+			Workspace:  storage.DocWorkspace(doc),
+			DocID:      doc.ID,
+			DocTitle:   doc.Title,
+			DocIcon:    doc.IconURL,
+			EndpointID: endpoint.StableID(),
+			Method:     strings.ToUpper(endpoint.Method),
+			Path:       endpoint.Path,
+			Summary:    endpoint.Summary,
+			Tags:       endpoint.Tags,
+			Deprecated: endpoint.Deprecated,
+			Lifecycle:  doc.Lifecycle,
+			Kind:       endpoint.EndpointKind(),
+			facets: map[string][]string{
+				FacetMethod:      {strings.ToUpper(endpoint.Method)},
+				FacetAuth:        doc.AuthTypes,
+				FacetTag:         endpoint.Tags,
+				FacetDeprecated:  {strconv.FormatBool(endpoint.Deprecated)},
+				FacetHasExamples: {strconv.FormatBool(len(endpoint.Examples) > 0)},
+				FacetKind:        {endpoint.EndpointKind()},
+			},
+			terms: make(map[string]int),
+		}
+		if domain != "" {
+			entry.facets[FacetDomain] = []string{domain}
+		}
+		if doc.Language != "" {
+			entry.facets[FacetLanguage] = []string{doc.Language}
+		}
+		if doc.Lifecycle != "" {
+			entry.facets[FacetLifecycle] = []string{doc.Lifecycle}
+		}
+
+		// Matches in the path, summary, and tags rank above matches in descriptions
+		addTerms(entry.terms, endpoint.Path, 3)
+		addTerms(entry.terms, endpoint.Summary, 3)
+		addTerms(entry.terms, endpoint.TranslatedSummary, 3)
+		addTerms(entry.terms, strings.Join(endpoint.Tags, " "), 2)
+		addTerms(entry.terms, doc.Title, 1)
+		addTerms(entry.terms, endpoint.Description, 1)
+		for _, param := range endpoint.Parameters {
+			addTerms(entry.terms, param.Name, 1)
+		}
+
+		entries = append(entries, entry)
+		texts = append(texts, strings.Join([]string{
+			endpoint.Method, endpoint.Path, endpoint.Summary, endpoint.TranslatedSummary, endpoint.Description,
+			strings.Join(endpoint.Tags, " "),
+		}, " "))
+	}
+
+	idx.mu.RLock()
+	embedder := idx.embedder
+	idx.mu.RUnlock()
+	if embedder != nil && len(texts) > 0 {
+		vectors, err := embedder.Embed(texts)
+		if err != nil {
+			// The doc stays searchable by keyword
+			log.Printf("Failed to embed endpoints of %s: %v", doc.ID, err)
+		}
+		for i := range vectors {
+			entries[i].vector = vectors[i]
+		}
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.entries[doc.ID] = entries
+}
+
+// Remove removes a doc's endpoints from the index
+func (idx *Index) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	delete(idx.entries, id)
+}
+
+// Rebuild replaces the index contents with the given docs
+func (idx *Index) Rebuild(docs []*models.APIDoc) {
+	idx.mu.Lock()
+	idx.entries = make(map[string][]*Entry)
+	idx.mu.Unlock()
+
+	for _, doc := range docs {
+		idx.Update(doc)
+	}
+}
+
+// all returns every indexed entry of the workspace in a stable order, or of every workspace when it's empty
+func (idx *Index) all(workspace string) []*Entry {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	ids := make([]string, 0, len(idx.entries))
+	for id := range idx.entries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var entries []*Entry
+	for _, id := range ids {
+		for _, entry := range idx.entries[id] {
+			if workspace == "" || entry.Workspace == workspace {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries
+}
+
+// docDomain returns the host of the doc's URL, or of its first server
+func docDomain(doc *models.APIDoc) string {
+	candidates := append([]string{doc.URL}, doc.Servers...)
+	for _, candidate := range candidates {
+		if parsed, err := url.Parse(candidate); err == nil && parsed.Hostname() != "" {
+			return strings.ToLower(parsed.Hostname())
+		}
+	}
+	return ""
+}
+
+// addTerms adds the tokens of text to terms with the given weight
+func addTerms(terms map[string]int, text string, weight int) {
+	for _, token := range Tokenize(text) {
+		terms[token] += weight
+	}
+}
+
+// Tokenize splits text into lowercase alphanumeric tokens
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
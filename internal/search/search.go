@@ -0,0 +1,276 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLimit is the number of results returned when the query doesn't set a limit
+const DefaultLimit = 50
+
+// SemanticNeighbors is the number of nearest neighbors a semantic search adds to the keyword matches
+const SemanticNeighbors = 20
+
+// ErrSemanticUnavailable is returned for semantic searches when no embedder is configured
+var ErrSemanticUnavailable = errors.New("semantic search is not enabled")
+
+// Query is a keyword search narrowed by facet filters
+type Query struct {
+	// Workspace restricts the search to a workspace's docs; empty searches every workspace
+	Workspace string
+	Text      string
+	// Semantic also matches the entries whose embeddings are nearest to the query's
+	Semantic bool
+	// Filters maps facet names to accepted values; an entry matches a facet if it has any of the values
+	Filters map[string][]string
+	Limit   int
+	Offset  int
+}
+
+// Result is a page of matching entries with facet counts
+type Result struct {
+	Total   int                       `json:"total"`
+	Results []Hit                     `json:"results"`
+	Facets  map[string]map[string]int `json:"facets"`
+}
+
+// Hit is a matching entry with its relevance score
+type Hit struct {
+	*Entry
+	Score      float64 `json:"score"`
+	Similarity float64 `json:"similarity,omitempty"` // cosine similarity to the query, for semantic searches
+	Views      int     `json:"views,omitempty"`      // views of the doc
+}
+
+// Search returns the entries matching every query term and every facet filter, best matches first.
+// The counts of each facet are computed with the filters of all other facets applied, so
+// selecting a value doesn't hide the alternatives of the same facet.
+func (idx *Index) Search(query Query) (*Result, error) {
+	var matches []Hit
+	if query.Semantic && strings.TrimSpace(query.Text) != "" {
+		semanticMatches, err := idx.semanticMatches(query.Workspace, query.Text)
+		if err != nil {
+			return nil, err
+		}
+		matches = semanticMatches
+	} else {
+		matches = idx.keywordMatches(query.Workspace, query.Text)
+	}
+
+	result := &Result{Results: []Hit{}, Facets: make(map[string]map[string]int)}
+	for _, facet := range Facets {
+		result.Facets[facet] = make(map[string]int)
+	}
+
+	var hits []Hit
+	for _, hit := range matches {
+		failed := ""
+		failures := 0
+		for _, facet := range Facets {
+			if !hit.matchesFilter(facet, query.Filters[facet]) {
+				failed = facet
+				failures++
+			}
+		}
+
+		// Count the entry for every facet whose other filters it satisfies
+		for _, facet := range Facets {
+			if failures == 0 || failures == 1 && failed == facet {
+				for _, value := range hit.facets[facet] {
+					result.Facets[facet][value]++
+				}
+			}
+		}
+
+		if failures == 0 {
+			hits = append(hits, hit)
+		}
+	}
+
+	idx.boostPopular(hits)
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Views != hits[j].Views {
+			return hits[i].Views > hits[j].Views
+		}
+		if hits[i].DocTitle != hits[j].DocTitle {
+			return hits[i].DocTitle < hits[j].DocTitle
+		}
+		if hits[i].Path != hits[j].Path {
+			return hits[i].Path < hits[j].Path
+		}
+		return hits[i].Method < hits[j].Method
+	})
+
+	result.Total = len(hits)
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if query.Offset < len(hits) {
+		hits = hits[query.Offset:]
+		if len(hits) > limit {
+			hits = hits[:limit]
+		}
+		result.Results = hits
+	}
+
+	return result, nil
+}
+
+// boostPopular sets the views of each hit's doc and boosts scores logarithmically by them,
+// so popularity breaks near-ties without drowning out relevance
+func (idx *Index) boostPopular(hits []Hit) {
+	idx.mu.RLock()
+	popularity := idx.popularity
+	idx.mu.RUnlock()
+	if popularity == nil {
+		return
+	}
+
+	views := make(map[string]int)
+	for i := range hits {
+		count, ok := views[hits[i].DocID]
+		if !ok {
+			count = popularity(hits[i].DocID)
+			views[hits[i].DocID] = count
+		}
+		hits[i].Views = count
+		hits[i].Score *= 1 + math.Log1p(float64(count))/10
+	}
+}
+
+// keywordMatches returns the workspace's entries matching every term of the text
+func (idx *Index) keywordMatches(workspace, text string) []Hit {
+	terms := Tokenize(text)
+
+	var matches []Hit
+	for _, entry := range idx.all(workspace) {
+		if score, ok := entry.score(terms); ok {
+			matches = append(matches, Hit{Entry: entry, Score: score})
+		}
+	}
+	return matches
+}
+
+// semanticMatches returns the workspace's keyword matches plus the entries nearest to the text's embedding.
+// Scores add the similarity to the keyword score scaled to [0, 1], so entries matching both rank first.
+func (idx *Index) semanticMatches(workspace, text string) ([]Hit, error) {
+	idx.mu.RLock()
+	embedder := idx.embedder
+	idx.mu.RUnlock()
+	if embedder == nil {
+		return nil, ErrSemanticUnavailable
+	}
+
+	vectors, err := embedder.Embed([]string{text})
+	if err != nil || len(vectors) == 0 {
+		return nil, fmt.Errorf("failed to embed query: %v", err)
+	}
+	queryVector := vectors[0]
+
+	terms := Tokenize(text)
+	var all []Hit
+	maxScore := 0.0
+	for _, entry := range idx.all(workspace) {
+		hit := Hit{Entry: entry}
+		if score, ok := entry.score(terms); ok {
+			hit.Score = score
+			if score > maxScore {
+				maxScore = score
+			}
+		}
+		if entry.vector != nil {
+			hit.Similarity = cosine(entry.vector, queryVector)
+		}
+		all = append(all, hit)
+	}
+
+	// Keep the nearest neighbors, plus every keyword match
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Similarity > all[j].Similarity
+	})
+	var matches []Hit
+	for i, hit := range all {
+		neighbor := i < SemanticNeighbors && hit.Similarity > 0
+		if !neighbor && hit.Score == 0 {
+			continue
+		}
+		if maxScore > 0 {
+			hit.Score /= maxScore
+		}
+		hit.Score += hit.Similarity
+		matches = append(matches, hit)
+	}
+	return matches, nil
+}
+
+// score sums the weights of the entry's tokens prefixed by each term. Every term must match.
+func (e *Entry) score(terms []string) (float64, bool) {
+	total := 0
+	for _, term := range terms {
+		matched := 0
+		for token, weight := range e.terms {
+			if strings.HasPrefix(token, term) {
+				matched += weight
+			}
+		}
+		if matched == 0 {
+			return 0, false
+		}
+		total += matched
+	}
+	return float64(total), true
+}
+
+// matchesFilter checks if the entry has any of the accepted values of a facet
+func (e *Entry) matchesFilter(facet string, accepted []string) bool {
+	if len(accepted) == 0 {
+		return true
+	}
+	for _, value := range e.facets[facet] {
+		for _, want := range accepted {
+			if strings.EqualFold(value, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ParseQuery builds a Query from URL query parameters: q for the keywords, mode=semantic
+// for a semantic search, one parameter
+// per facet (repeatable, or comma-separated), and limit/offset for paging
+func ParseQuery(values url.Values) Query {
+	query := Query{
+		Text:     values.Get("q"),
+		Semantic: values.Get("mode") == "semantic",
+		Filters:  make(map[string][]string),
+	}
+
+	for _, facet := range Facets {
+		for _, value := range values[facet] {
+			for _, part := range strings.Split(value, ",") {
+				if part = strings.TrimSpace(part); part != "" {
+					query.Filters[facet] = append(query.Filters[facet], part)
+				}
+			}
+		}
+	}
+
+	query.Limit, _ = strconv.Atoi(values.Get("limit"))
+	query.Offset, _ = strconv.Atoi(values.Get("offset"))
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	return query
+}
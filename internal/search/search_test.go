@@ -0,0 +1,188 @@
+package search
+
+import (
+	"net/url"
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// testIndex returns an index of two small docs
+func testIndex() *Index {
+	index := NewIndex()
+	index.Update(&models.APIDoc{
+		ID:        "billing",
+		Title:     "Billing API",
+		URL:       "https://billing.example.com/openapi.json",
+		AuthTypes: []string{"bearer"},
+		Endpoints: []models.Endpoint{
+			{Method: "GET", Path: "/subscriptions", Summary: "List subscriptions", Tags: []string{"subscriptions"}},
+			{Method: "DELETE", Path: "/subscriptions/{id}", Summary: "Cancel a subscription", Tags: []string{"subscriptions"}},
+			{Method: "GET", Path: "/invoices", Summary: "List invoices", Deprecated: true},
+		},
+	})
+	index.Update(&models.APIDoc{
+		ID:      "users",
+		Title:   "Users API",
+		Servers: []string{"https://users.example.com/v1"},
+		Endpoints: []models.Endpoint{
+			{Method: "GET", Path: "/users", Summary: "List users", Examples: []models.Example{{Source: "proxy"}}},
+		},
+	})
+	return index
+}
+
+// TestSearch tests keyword matching with prefix terms and ranking
+func TestSearch(t *testing.T) {
+	result, _ := testIndex().Search(Query{Text: "cancel subscr"})
+	if result.Total != 1 || result.Results[0].Path != "/subscriptions/{id}" {
+		t.Fatalf("Expected only the cancel endpoint to match, got %+v", result.Results)
+	}
+
+	result, _ = testIndex().Search(Query{Text: "list"})
+	if result.Total != 3 {
+		t.Errorf("Expected 3 list endpoints, got %d", result.Total)
+	}
+}
+
+// TestSearchFacets tests filtering by facets and disjunctive facet counts
+func TestSearchFacets(t *testing.T) {
+	query := ParseQuery(url.Values{"method": {"GET"}, "domain": {"billing.example.com,other.example.com"}})
+	result, _ := testIndex().Search(query)
+
+	if result.Total != 2 {
+		t.Fatalf("Expected 2 GET endpoints on billing.example.com, got %d", result.Total)
+	}
+
+	// The method counts ignore the method filter but apply the domain filter
+	if methods := result.Facets[FacetMethod]; methods["GET"] != 2 || methods["DELETE"] != 1 {
+		t.Errorf("Expected GET: 2 and DELETE: 1, got %v", methods)
+	}
+
+	// The domain counts ignore the domain filter but apply the method filter
+	if domains := result.Facets[FacetDomain]; domains["billing.example.com"] != 2 || domains["users.example.com"] != 1 {
+		t.Errorf("Expected billing.example.com: 2 and users.example.com: 1, got %v", domains)
+	}
+
+	if deprecated := result.Facets[FacetDeprecated]; deprecated["true"] != 1 || deprecated["false"] != 1 {
+		t.Errorf("Expected one deprecated and one current endpoint, got %v", deprecated)
+	}
+
+	result, _ = testIndex().Search(ParseQuery(url.Values{"has_examples": {"true"}}))
+	if result.Total != 1 || result.Results[0].DocID != "users" {
+		t.Errorf("Expected only the users endpoint to have examples, got %+v", result.Results)
+	}
+}
+
+// TestIndexRemove tests that removed docs no longer match
+func TestIndexRemove(t *testing.T) {
+	index := testIndex()
+	index.Remove("billing")
+
+	if result, _ := index.Search(Query{}); result.Total != 1 {
+		t.Errorf("Expected 1 endpoint after removing billing, got %d", result.Total)
+	}
+}
+
+// conceptEmbedder embeds texts by the concepts of their words, so synonyms share dimensions
+type conceptEmbedder struct{}
+
+// concepts maps words to a concept dimension
+var concepts = map[string]int{"cancel": 0, "delete": 0, "remove": 0, "subscription": 1, "subscriptions": 1, "plan": 1, "list": 2, "invoices": 3}
+
+// Embed implements the Embedder interface
+func (conceptEmbedder) Embed(texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, 4)
+		for _, token := range Tokenize(text) {
+			if dimension, ok := concepts[token]; ok {
+				vector[dimension]++
+			}
+		}
+		vectors[i] = normalize(vector)
+	}
+	return vectors, nil
+}
+
+// TestSemanticSearch tests that nearest neighbors match without sharing keywords with the query
+func TestSemanticSearch(t *testing.T) {
+	index := NewIndex()
+	if _, err := index.Search(Query{Text: "remove plan", Semantic: true}); err != ErrSemanticUnavailable {
+		t.Errorf("Expected ErrSemanticUnavailable without an embedder, got %v", err)
+	}
+
+	index.SetEmbedder(conceptEmbedder{})
+	index.Update(&models.APIDoc{
+		ID: "billing",
+		Endpoints: []models.Endpoint{
+			{Method: "GET", Path: "/invoices", Summary: "List invoices"},
+			{Method: "DELETE", Path: "/subscriptions/{id}", Summary: "Cancel a subscription"},
+		},
+	})
+
+	result, err := index.Search(Query{Text: "remove plan", Semantic: true})
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+
+	if result.Total != 1 || result.Results[0].Path != "/subscriptions/{id}" {
+		t.Fatalf("Expected the cancel endpoint to be the only neighbor, got %+v", result.Results)
+	}
+
+	if result.Results[0].Similarity < 0.99 {
+		t.Errorf("Expected a near-perfect similarity, got %f", result.Results[0].Similarity)
+	}
+}
+
+// TestHashEmbedder tests that the local embedder relates texts sharing words
+func TestHashEmbedder(t *testing.T) {
+	embedder := &HashEmbedder{Dimensions: DefaultHashDimensions}
+	vectors, _ := embedder.Embed([]string{"cancel subscriptions", "Cancel a subscription", "list invoices"})
+
+	if related, unrelated := cosine(vectors[0], vectors[1]), cosine(vectors[0], vectors[2]); related <= unrelated {
+		t.Errorf("Expected related texts to be more similar (%f) than unrelated ones (%f)", related, unrelated)
+	}
+}
+
+// TestSearchPopularity tests that views break ties between equally relevant docs
+func TestSearchPopularity(t *testing.T) {
+	index := NewIndex()
+	index.Update(&models.APIDoc{ID: "a", Title: "A", Endpoints: []models.Endpoint{{Method: "GET", Path: "/users"}}})
+	index.Update(&models.APIDoc{ID: "b", Title: "B", Endpoints: []models.Endpoint{{Method: "GET", Path: "/users"}}})
+	index.SetPopularity(func(docID string) int {
+		if docID == "b" {
+			return 100
+		}
+		return 0
+	})
+
+	result, _ := index.Search(Query{Text: "users"})
+	if result.Results[0].DocID != "b" || result.Results[0].Views != 100 {
+		t.Errorf("Expected the popular doc first, got %+v", result.Results[0])
+	}
+}
+
+// TestSuggest tests doc title and endpoint suggestions, narrowed by a leading method
+func TestSuggest(t *testing.T) {
+	suggestions := testIndex().Suggest("", "bill", 0)
+	if len(suggestions.Docs) != 1 || suggestions.Docs[0].DocID != "billing" || suggestions.Docs[0].Endpoints != 3 {
+		t.Errorf("Expected the billing doc with 3 endpoints, got %+v", suggestions.Docs)
+	}
+
+	suggestions = testIndex().Suggest("", "delete subscr", 0)
+	if len(suggestions.Docs) != 0 {
+		t.Errorf("Expected no doc titles to match, got %+v", suggestions.Docs)
+	}
+	if len(suggestions.Endpoints) != 1 || suggestions.Endpoints[0].URL != "/docs/billing#endpoint-"+suggestions.Endpoints[0].EndpointID {
+		t.Fatalf("Expected only the cancel endpoint, got %+v", suggestions.Endpoints)
+	}
+
+	suggestions = testIndex().Suggest("", "list", 2)
+	if len(suggestions.Endpoints) != 2 {
+		t.Errorf("Expected the limit to cap endpoints at 2, got %d", len(suggestions.Endpoints))
+	}
+	if empty := testIndex().Suggest("", " ", 0); len(empty.Docs) != 0 || len(empty.Endpoints) != 0 {
+		t.Errorf("Expected no suggestions for an empty query, got %+v", empty)
+	}
+}
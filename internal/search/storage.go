@@ -0,0 +1,43 @@
+package search
+
+import (
+	"log"
+
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// IndexingStorage wraps a Storage and keeps an Index in sync with saved and deleted docs
+type IndexingStorage struct {
+	storage.Storage
+	index *Index
+}
+
+// NewIndexingStorage wraps store, indexing the docs it already holds
+func NewIndexingStorage(store storage.Storage, index *Index) *IndexingStorage {
+	docs, err := store.GetAllAPIDocs()
+	if err != nil {
+		log.Printf("Failed to index existing API docs: %v", err)
+	}
+	index.Rebuild(docs)
+
+	return &IndexingStorage{Storage: store, index: index}
+}
+
+// SaveAPIDoc saves the doc and re-indexes it
+func (s *IndexingStorage) SaveAPIDoc(doc *models.APIDoc) error {
+	if err := s.Storage.SaveAPIDoc(doc); err != nil {
+		return err
+	}
+	s.index.Update(doc)
+	return nil
+}
+
+// DeleteAPIDoc deletes the doc and removes it from the index
+func (s *IndexingStorage) DeleteAPIDoc(id string) error {
+	if err := s.Storage.DeleteAPIDoc(id); err != nil {
+		return err
+	}
+	s.index.Remove(id)
+	return nil
+}
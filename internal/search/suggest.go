@@ -0,0 +1,143 @@
+package search
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SuggestLimit is the number of docs and of endpoints suggested when no limit is set
+const SuggestLimit = 5
+
+// httpMethods are the methods a suggestion query may start with to narrow endpoints
+var httpMethods = map[string]bool{
+	"get": true, "post": true, "put": true, "patch": true, "delete": true, "head": true, "options": true,
+}
+
+// Suggestions are the best doc and endpoint matches of a partial query, for quick-open
+type Suggestions struct {
+	Docs      []DocSuggestion      `json:"docs"`
+	Endpoints []EndpointSuggestion `json:"endpoints"`
+}
+
+// DocSuggestion is a doc whose title matches the query
+type DocSuggestion struct {
+	DocID     string `json:"doc_id"`
+	Title     string `json:"title"`
+	Endpoints int    `json:"endpoints"`
+	URL       string `json:"url"` // UI page of the doc
+}
+
+// EndpointSuggestion is an endpoint matching the query
+type EndpointSuggestion struct {
+	DocID      string `json:"doc_id"`
+	DocTitle   string `json:"doc_title"`
+	EndpointID string `json:"endpoint_id"`
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Summary    string `json:"summary,omitempty"`
+	URL        string `json:"url"` // UI page of the doc, scrolled to the endpoint
+}
+
+// Suggest returns the docs whose titles match every term of the text and the best matching endpoints,
+// at most limit of each. A leading HTTP method, as in "get users", only narrows the endpoints.
+func (idx *Index) Suggest(workspace, text string, limit int) *Suggestions {
+	if limit <= 0 {
+		limit = SuggestLimit
+	}
+	suggestions := &Suggestions{Docs: []DocSuggestion{}, Endpoints: []EndpointSuggestion{}}
+	terms := Tokenize(text)
+	if len(terms) == 0 {
+		return suggestions
+	}
+
+	// Docs, with every endpoint's entry carrying the title
+	type docMatch struct {
+		entry *Entry
+		count int
+	}
+	var docs []*docMatch
+	byID := make(map[string]*docMatch)
+	for _, entry := range idx.all(workspace) {
+		if match, ok := byID[entry.DocID]; ok {
+			match.count++
+			continue
+		}
+		if titleMatches(entry.DocTitle, terms) {
+			byID[entry.DocID] = &docMatch{entry: entry, count: 1}
+			docs = append(docs, byID[entry.DocID])
+		} else {
+			byID[entry.DocID] = &docMatch{}
+		}
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].entry.DocTitle < docs[j].entry.DocTitle
+	})
+	for _, match := range docs {
+		if len(suggestions.Docs) == limit {
+			break
+		}
+		suggestions.Docs = append(suggestions.Docs, DocSuggestion{
+			DocID:     match.entry.DocID,
+			Title:     match.entry.DocTitle,
+			Endpoints: match.count,
+			URL:       "/docs/" + url.PathEscape(match.entry.DocID),
+		})
+	}
+
+	// Endpoints, ranked like a search
+	method := ""
+	if httpMethods[terms[0]] {
+		method = strings.ToUpper(terms[0])
+		terms = terms[1:]
+	}
+	var hits []Hit
+	for _, entry := range idx.all(workspace) {
+		if method != "" && entry.Method != method {
+			continue
+		}
+		if score, ok := entry.score(terms); ok {
+			hits = append(hits, Hit{Entry: entry, Score: score})
+		}
+	}
+	idx.boostPopular(hits)
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return len(hits[i].Path) < len(hits[j].Path)
+	})
+	for _, hit := range hits {
+		if len(suggestions.Endpoints) == limit {
+			break
+		}
+		suggestions.Endpoints = append(suggestions.Endpoints, EndpointSuggestion{
+			DocID:      hit.DocID,
+			DocTitle:   hit.DocTitle,
+			EndpointID: hit.EndpointID,
+			Method:     hit.Method,
+			Path:       hit.Path,
+			Summary:    hit.Summary,
+			URL:        "/docs/" + url.PathEscape(hit.DocID) + "#endpoint-" + hit.EndpointID,
+		})
+	}
+	return suggestions
+}
+
+// titleMatches checks if every term prefixes a token of the title
+func titleMatches(title string, terms []string) bool {
+	tokens := Tokenize(title)
+	for _, term := range terms {
+		matched := false
+		for _, token := range tokens {
+			if strings.HasPrefix(token, term) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,136 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Cipher encrypts and decrypts credential secrets
+type Cipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// AESGCM encrypts with AES-GCM under a local key, prefixing each ciphertext with its nonce
+type AESGCM struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCM creates an AES-GCM cipher from a base64-encoded 16, 24, or 32 byte key
+func NewAESGCM(encodedKey string) (*AESGCM, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedKey))
+	if err != nil {
+		return nil, fmt.Errorf("secrets key must be base64: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("secrets key must be 16, 24, or 32 bytes: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AESGCM{aead: aead}, nil
+}
+
+// Encrypt encrypts plaintext under a random nonce
+func (c *AESGCM) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt decrypts and authenticates a ciphertext
+func (c *AESGCM) Decrypt(ciphertext []byte) ([]byte, error) {
+	size := c.aead.NonceSize()
+	if len(ciphertext) < size {
+		return nil, errors.New("ciphertext too short")
+	}
+	plaintext, err := c.aead.Open(nil, ciphertext[:size], ciphertext[size:], nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt secret, was the key changed?")
+	}
+	return plaintext, nil
+}
+
+// VaultTransit encrypts with a HashiCorp Vault transit key, so the key never leaves Vault
+type VaultTransit struct {
+	addr   string
+	token  string
+	key    string
+	client *http.Client
+}
+
+// NewVaultTransit creates a cipher using the transit key named key on the Vault server at addr
+func NewVaultTransit(addr, token, key string) *VaultTransit {
+	return &VaultTransit{
+		addr:   strings.TrimSuffix(addr, "/"),
+		token:  token,
+		key:    key,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Encrypt encrypts plaintext with Vault, returning Vault's ciphertext string
+func (v *VaultTransit) Encrypt(plaintext []byte) ([]byte, error) {
+	var result struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	if err := v.post("encrypt", map[string]string{"plaintext": base64.StdEncoding.EncodeToString(plaintext)}, &result); err != nil {
+		return nil, err
+	}
+	return []byte(result.Data.Ciphertext), nil
+}
+
+// Decrypt decrypts a ciphertext string with Vault
+func (v *VaultTransit) Decrypt(ciphertext []byte) ([]byte, error) {
+	var result struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	if err := v.post("decrypt", map[string]string{"ciphertext": string(ciphertext)}, &result); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(result.Data.Plaintext)
+}
+
+// post calls a transit operation on the key
+func (v *VaultTransit) post(operation string, body interface{}, result interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, v.addr+"/v1/transit/"+operation+"/"+v.key, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault %s failed: %w", operation, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault %s failed with status code: %d", operation, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
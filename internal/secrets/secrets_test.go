@@ -0,0 +1,132 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// testKey is a base64 32 byte AES key
+var testKey = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))
+
+// TestAESGCM tests encrypting and decrypting secrets under a local key
+func TestAESGCM(t *testing.T) {
+	c, err := NewAESGCM(testKey)
+	if err != nil {
+		t.Fatalf("Failed to create cipher: %v", err)
+	}
+
+	ciphertext, err := c.Encrypt([]byte("hunter2"))
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if bytes.Contains(ciphertext, []byte("hunter2")) {
+		t.Error("Expected ciphertext not to contain the plaintext")
+	}
+
+	plaintext, err := c.Decrypt(ciphertext)
+	if err != nil || string(plaintext) != "hunter2" {
+		t.Errorf("Expected hunter2, got %q, %v", plaintext, err)
+	}
+
+	ciphertext[len(ciphertext)-1] ^= 1
+	if _, err := c.Decrypt(ciphertext); err == nil {
+		t.Error("Expected tampered ciphertext to be rejected")
+	}
+
+	if _, err := NewAESGCM(base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+		t.Error("Expected a short key to be rejected")
+	}
+}
+
+// TestVaultTransit tests encrypting through a Vault transit key
+func TestVaultTransit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/scrapes":
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"ciphertext": "vault:v1:" + body["plaintext"]}})
+		case "/v1/transit/decrypt/scrapes":
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"plaintext": strings.TrimPrefix(body["ciphertext"], "vault:v1:")}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	store := NewStore(NewVaultTransit(server.URL, "root", "scrapes"))
+	credential := &models.Credential{Type: models.CredentialBearer}
+	if err := store.Seal(credential, &models.CredentialSecret{Token: "abc"}); err != nil {
+		t.Fatalf("Failed to seal: %v", err)
+	}
+	if !strings.HasPrefix(string(credential.Ciphertext), "vault:v1:") {
+		t.Errorf("Expected Vault ciphertext, got %q", credential.Ciphertext)
+	}
+
+	secret, err := store.Open(credential)
+	if err != nil || secret.Token != "abc" {
+		t.Errorf("Expected token abc, got %+v, %v", secret, err)
+	}
+}
+
+// TestRequestAuth tests applying each credential type to scrape requests
+func TestRequestAuth(t *testing.T) {
+	c, _ := NewAESGCM(testKey)
+	store := NewStore(c)
+
+	tests := []struct {
+		credential models.Credential
+		secret     models.CredentialSecret
+		check      func(req *http.Request) bool
+	}{
+		{models.Credential{Type: models.CredentialBearer}, models.CredentialSecret{Token: "t"},
+			func(req *http.Request) bool { return req.Header.Get("Authorization") == "Bearer t" }},
+		{models.Credential{Type: models.CredentialBasic}, models.CredentialSecret{Username: "u", Password: "p"},
+			func(req *http.Request) bool { u, p, ok := req.BasicAuth(); return ok && u == "u" && p == "p" }},
+		{models.Credential{Type: models.CredentialHeader, Key: "X-API-Key"}, models.CredentialSecret{Token: "k"},
+			func(req *http.Request) bool { return req.Header.Get("X-API-Key") == "k" }},
+		{models.Credential{Type: models.CredentialQuery, Key: "api_key"}, models.CredentialSecret{Token: "q"},
+			func(req *http.Request) bool {
+				return req.URL.Query().Get("api_key") == "q" && req.URL.Query().Get("v") == "1"
+			}},
+		{models.Credential{Type: models.CredentialCookie, Key: "session"}, models.CredentialSecret{Token: "s"},
+			func(req *http.Request) bool {
+				cookie, err := req.Cookie("session")
+				return err == nil && cookie.Value == "s"
+			}},
+	}
+
+	for _, test := range tests {
+		if err := Validate(&test.credential, &test.secret); err != nil {
+			t.Errorf("Expected %s credential to be valid, got %v", test.credential.Type, err)
+			continue
+		}
+		store.Seal(&test.credential, &test.secret)
+
+		auth, err := store.RequestAuth(&test.credential)
+		if err != nil {
+			t.Fatalf("Failed to open %s credential: %v", test.credential.Type, err)
+		}
+		req, _ := http.NewRequest(http.MethodGet, "https://docs.example.com/api?v=1", nil)
+		auth(req)
+		if !test.check(req) {
+			t.Errorf("Expected %s credential to be applied, got %v %v", test.credential.Type, req.URL, req.Header)
+		}
+	}
+
+	if err := Validate(&models.Credential{Type: models.CredentialHeader}, &models.CredentialSecret{Token: "k"}); err == nil {
+		t.Error("Expected header credential without a key to be rejected")
+	}
+}
@@ -0,0 +1,95 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"universal_api/internal/models"
+	"universal_api/pkg/scraper"
+)
+
+// Store seals credential secrets before they're stored and opens them for scraping
+type Store struct {
+	cipher Cipher
+}
+
+// NewStore creates a store encrypting secrets with cipher
+func NewStore(cipher Cipher) *Store {
+	return &Store{cipher: cipher}
+}
+
+// Validate checks that a credential has the fields its type needs
+func Validate(credential *models.Credential, secret *models.CredentialSecret) error {
+	switch credential.Type {
+	case models.CredentialBearer:
+		if secret.Token == "" {
+			return errors.New("bearer credentials need a token")
+		}
+	case models.CredentialBasic:
+		if secret.Username == "" {
+			return errors.New("basic credentials need a username")
+		}
+	case models.CredentialHeader, models.CredentialQuery, models.CredentialCookie:
+		if credential.Key == "" || secret.Token == "" {
+			return errors.New(credential.Type + " credentials need a key and a token")
+		}
+	default:
+		return errors.New("credential type must be bearer, basic, header, query, or cookie")
+	}
+	return nil
+}
+
+// Seal encrypts a secret into the credential's ciphertext
+func (s *Store) Seal(credential *models.Credential, secret *models.CredentialSecret) error {
+	plaintext, err := json.Marshal(secret)
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := s.cipher.Encrypt(plaintext)
+	if err != nil {
+		return err
+	}
+	credential.Ciphertext = ciphertext
+	return nil
+}
+
+// Open decrypts the secret of a credential
+func (s *Store) Open(credential *models.Credential) (*models.CredentialSecret, error) {
+	plaintext, err := s.cipher.Decrypt(credential.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	var secret models.CredentialSecret
+	if err := json.Unmarshal(plaintext, &secret); err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+// RequestAuth returns scraper authentication with a credential's decrypted secret
+func (s *Store) RequestAuth(credential *models.Credential) (scraper.RequestAuth, error) {
+	secret, err := s.Open(credential)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(req *http.Request) {
+		switch credential.Type {
+		case models.CredentialBearer:
+			req.Header.Set("Authorization", "Bearer "+secret.Token)
+		case models.CredentialBasic:
+			req.SetBasicAuth(secret.Username, secret.Password)
+		case models.CredentialHeader:
+			req.Header.Set(credential.Key, secret.Token)
+		case models.CredentialQuery:
+			query := req.URL.Query()
+			query.Set(credential.Key, secret.Token)
+			req.URL.RawQuery = query.Encode()
+		case models.CredentialCookie:
+			req.AddCookie(&http.Cookie{Name: credential.Key, Value: secret.Token})
+		}
+	}, nil
+}
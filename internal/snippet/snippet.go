@@ -0,0 +1,337 @@
+package snippet
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"universal_api/internal/models"
+)
+
+// Snippet formats
+const (
+	FormatCurl    = "curl"    // a curl command
+	FormatHTTPie  = "httpie"  // an HTTPie command
+	FormatFetch   = "fetch"   // a JavaScript fetch call
+	FormatPostman = "postman" // a Postman collection with the request
+)
+
+// Formats are the snippet formats, in the order offered
+var Formats = []string{FormatCurl, FormatHTTPie, FormatFetch, FormatPostman}
+
+// ErrNotCallable is returned for endpoints a client doesn't call with an HTTP request: webhooks,
+// callbacks, and events the API sends, and WebSockets
+var ErrNotCallable = errors.New("endpoint isn't called with an HTTP request")
+
+// Request is the request a snippet sends to an endpoint. Values without examples are placeholders like <id>.
+type Request struct {
+	Method    string
+	URL       string
+	Headers   []Header
+	Body      string  // raw body, sent with a Content-Type header
+	Form      []Field // multipart form fields, sent instead of a body
+	Multipart bool
+	Stream    bool // the response is a stream of server-sent events
+}
+
+// Header is a request header
+type Header struct {
+	Name  string
+	Value string
+}
+
+// Field is a multipart form field
+type Field struct {
+	Name  string
+	Value string // the file path for files
+	File  bool
+}
+
+// Generate returns a snippet in a format sending a request to an endpoint of a doc
+func Generate(doc *models.APIDoc, endpoint models.Endpoint, format string) (string, error) {
+	if endpoint.Incoming() || endpoint.Protocol == models.ProtocolWebSocket {
+		return "", ErrNotCallable
+	}
+
+	request := NewRequest(doc, endpoint)
+	switch format {
+	case FormatCurl:
+		return curl(request), nil
+	case FormatHTTPie:
+		return httpie(request), nil
+	case FormatFetch:
+		return fetch(request), nil
+	case FormatPostman:
+		return postman(doc, endpoint, request)
+	}
+	return "", fmt.Errorf("unknown snippet format %q, expected one of %s", format, strings.Join(Formats, ", "))
+}
+
+// NewRequest builds the request to an endpoint from its parameter examples and the doc's first server.
+// Required parameters without examples get placeholders; optional ones without examples are left out.
+func NewRequest(doc *models.APIDoc, endpoint models.Endpoint) Request {
+	request := Request{Method: strings.ToUpper(endpoint.Method), Stream: endpoint.Protocol == models.ProtocolSSE}
+
+	base := placeholder("base-url")
+	if len(doc.Servers) > 0 {
+		base = strings.TrimSuffix(doc.Servers[0], "/")
+	}
+	path := endpoint.Path
+	var query []string
+	authorized := false
+
+	for _, param := range endpoint.Parameters {
+		value := param.Example
+		switch param.In {
+		case "path":
+			if value == "" {
+				path = strings.ReplaceAll(path, "{"+param.Name+"}", placeholder(param.Name))
+			} else {
+				path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(value))
+			}
+		case "query":
+			if value != "" {
+				query = append(query, url.QueryEscape(param.Name)+"="+url.QueryEscape(value))
+			} else if param.Required {
+				query = append(query, url.QueryEscape(param.Name)+"="+placeholder(param.Name))
+			}
+		case "header":
+			if param.Auth != "" {
+				request.Headers = append(request.Headers, Header{param.Name, credential(param.Auth)})
+				authorized = true
+			} else if value != "" || param.Required {
+				if value == "" {
+					value = placeholder(param.Name)
+				}
+				request.Headers = append(request.Headers, Header{param.Name, value})
+			}
+		}
+	}
+
+	// Without a header carrying credentials, authenticate as the doc's first scheme says
+	if !authorized && len(doc.AuthSchemes) > 0 {
+		scheme := doc.AuthSchemes[0]
+		switch {
+		case scheme.Type == "apiKey" && scheme.In == "query" && scheme.Param != "":
+			query = append(query, url.QueryEscape(scheme.Param)+"="+placeholder("api-key"))
+		case scheme.Type == "apiKey" && scheme.In == "header" && scheme.Param != "":
+			request.Headers = append(request.Headers, Header{scheme.Param, credential(scheme.Type)})
+		case scheme.Type == "bearer" || scheme.Type == "oauth2" || scheme.Type == "openIdConnect":
+			request.Headers = append(request.Headers, Header{"Authorization", credential("bearer")})
+		case scheme.Type == "basic":
+			request.Headers = append(request.Headers, Header{"Authorization", credential(scheme.Type)})
+		}
+	}
+
+	request.URL = base + path
+	if len(query) > 0 {
+		request.URL += "?" + strings.Join(query, "&")
+	}
+	if request.Stream {
+		request.Headers = append(request.Headers, Header{"Accept", "text/event-stream"})
+	}
+
+	if len(endpoint.RequestBodies) > 0 {
+		body := endpoint.RequestBodies[0]
+		if strings.HasPrefix(body.ContentType, "multipart/form-data") {
+			request.Multipart = true
+			for _, param := range endpoint.Parameters {
+				if param.In != "body" {
+					continue
+				}
+				field := Field{Name: param.Name, Value: param.Example, File: param.Type == "file"}
+				if field.Value == "" {
+					field.Value = placeholder(param.Name)
+				}
+				request.Form = append(request.Form, field)
+			}
+		} else {
+			request.Headers = append(request.Headers, Header{"Content-Type", body.ContentType})
+			request.Body = body.Example
+			if request.Body == "" {
+				request.Body = placeholder("body")
+			}
+		}
+	}
+
+	return request
+}
+
+// placeholder returns the placeholder of a value to fill in
+func placeholder(name string) string {
+	return "<" + name + ">"
+}
+
+// credential returns the placeholder of a credential header's value for an auth type
+func credential(authType string) string {
+	switch authType {
+	case "bearer":
+		return "Bearer " + placeholder("token")
+	case "basic":
+		return "Basic " + placeholder("credentials")
+	}
+	return placeholder("api-key")
+}
+
+// curl returns a curl command sending a request
+func curl(request Request) string {
+	lines := []string{"curl"}
+	if request.Stream {
+		lines[0] += " -N"
+	}
+	if request.Method != "GET" {
+		lines[0] += " -X " + request.Method
+	}
+	lines[0] += " " + shellQuote(request.URL)
+
+	for _, header := range request.Headers {
+		lines = append(lines, "-H "+shellQuote(header.Name+": "+header.Value))
+	}
+	for _, field := range request.Form {
+		value := field.Value
+		if field.File {
+			value = "@" + value
+		}
+		lines = append(lines, "-F "+shellQuote(field.Name+"="+value))
+	}
+	if request.Body != "" {
+		lines = append(lines, "--data-raw "+shellQuote(request.Body))
+	}
+	return strings.Join(lines, " \\\n  ")
+}
+
+// httpie returns an HTTPie command sending a request
+func httpie(request Request) string {
+	lines := []string{"http"}
+	if request.Stream {
+		lines[0] += " --stream"
+	}
+	if request.Multipart {
+		lines[0] += " --multipart"
+	}
+	lines[0] += " " + request.Method + " " + shellQuote(request.URL)
+
+	for _, header := range request.Headers {
+		lines = append(lines, shellQuote(header.Name+":"+header.Value))
+	}
+	for _, field := range request.Form {
+		separator := "="
+		if field.File {
+			separator = "@"
+		}
+		lines = append(lines, shellQuote(field.Name+separator+field.Value))
+	}
+	if request.Body != "" {
+		lines = append(lines, "--raw "+shellQuote(request.Body))
+	}
+	return strings.Join(lines, " \\\n  ")
+}
+
+// fetch returns JavaScript sending a request with fetch
+func fetch(request Request) string {
+	var snippet strings.Builder
+	if request.Multipart {
+		snippet.WriteString("const body = new FormData();\n")
+		for _, field := range request.Form {
+			if field.File {
+				fmt.Fprintf(&snippet, "body.append(%s, fileInput.files[0]); // %s\n", jsString(field.Name), field.Value)
+			} else {
+				fmt.Fprintf(&snippet, "body.append(%s, %s);\n", jsString(field.Name), jsString(field.Value))
+			}
+		}
+		snippet.WriteString("\n")
+	}
+
+	fmt.Fprintf(&snippet, "const response = await fetch(%s, {\n  method: %s", jsString(request.URL), jsString(request.Method))
+	if len(request.Headers) > 0 {
+		snippet.WriteString(",\n  headers: {\n")
+		for i, header := range request.Headers {
+			fmt.Fprintf(&snippet, "    %s: %s", jsString(header.Name), jsString(header.Value))
+			if i < len(request.Headers)-1 {
+				snippet.WriteString(",")
+			}
+			snippet.WriteString("\n")
+		}
+		snippet.WriteString("  }")
+	}
+	if request.Multipart {
+		snippet.WriteString(",\n  body")
+	} else if request.Body != "" {
+		fmt.Fprintf(&snippet, ",\n  body: %s", jsString(request.Body))
+	}
+	snippet.WriteString("\n});\nconsole.log(response.status, await response.text());")
+	return snippet.String()
+}
+
+// postman returns a Postman collection (format v2.1) with the request
+func postman(doc *models.APIDoc, endpoint models.Endpoint, request Request) (string, error) {
+	type keyValue struct {
+		Key   string `json:"key"`
+		Value string `json:"value,omitempty"`
+		Type  string `json:"type,omitempty"` // text or file, for form fields
+		Src   string `json:"src,omitempty"`  // the file path, for file fields
+	}
+	type body struct {
+		Mode     string     `json:"mode"`
+		Raw      string     `json:"raw,omitempty"`
+		FormData []keyValue `json:"formdata,omitempty"`
+	}
+	type postmanRequest struct {
+		Method string     `json:"method"`
+		Header []keyValue `json:"header"`
+		URL    string     `json:"url"`
+		Body   *body      `json:"body,omitempty"`
+	}
+
+	item := postmanRequest{Method: request.Method, Header: []keyValue{}, URL: request.URL}
+	for _, header := range request.Headers {
+		item.Header = append(item.Header, keyValue{Key: header.Name, Value: header.Value})
+	}
+	if request.Multipart {
+		item.Body = &body{Mode: "formdata"}
+		for _, field := range request.Form {
+			if field.File {
+				item.Body.FormData = append(item.Body.FormData, keyValue{Key: field.Name, Type: "file", Src: field.Value})
+			} else {
+				item.Body.FormData = append(item.Body.FormData, keyValue{Key: field.Name, Value: field.Value, Type: "text"})
+			}
+		}
+	} else if request.Body != "" {
+		item.Body = &body{Mode: "raw", Raw: request.Body}
+	}
+
+	name := request.Method + " " + endpoint.Path
+	collection := map[string]interface{}{
+		"info": map[string]string{
+			"name":   doc.Title,
+			"schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json",
+		},
+		"item": []map[string]interface{}{{"name": name, "request": item}},
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(collection); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
+}
+
+// shellQuote quotes a value for POSIX shells
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// jsString returns a JavaScript string literal of a value
+func jsString(value string) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
@@ -0,0 +1,120 @@
+package snippet
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestGenerate tests snippets of a request with path, query, and header parameters and a JSON body
+func TestGenerate(t *testing.T) {
+	doc := &models.APIDoc{
+		Title:       "Pets",
+		Servers:     []string{"https://api.example.com/v1/"},
+		AuthSchemes: []models.AuthScheme{{Type: "bearer"}},
+	}
+	endpoint := models.Endpoint{
+		Method: "post",
+		Path:   "/owners/{ownerId}/pets",
+		Parameters: []models.Parameter{
+			{Name: "ownerId", In: "path", Required: true},
+			{Name: "notify", In: "query", Example: "true"},
+			{Name: "page", In: "query"},
+			{Name: "X-Trace", In: "header", Example: "abc"},
+		},
+		RequestBodies: []models.RequestBody{{ContentType: "application/json", Example: `{"name": "Rex's"}`}},
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{FormatCurl, `curl -X POST 'https://api.example.com/v1/owners/<ownerId>/pets?notify=true' \
+  -H 'X-Trace: abc' \
+  -H 'Authorization: Bearer <token>' \
+  -H 'Content-Type: application/json' \
+  --data-raw '{"name": "Rex'\''s"}'`},
+		{FormatHTTPie, `http POST 'https://api.example.com/v1/owners/<ownerId>/pets?notify=true' \
+  'X-Trace:abc' \
+  'Authorization:Bearer <token>' \
+  'Content-Type:application/json' \
+  --raw '{"name": "Rex'\''s"}'`},
+		{FormatFetch, `const response = await fetch("https://api.example.com/v1/owners/<ownerId>/pets?notify=true", {
+  method: "POST",
+  headers: {
+    "X-Trace": "abc",
+    "Authorization": "Bearer <token>",
+    "Content-Type": "application/json"
+  },
+  body: "{\"name\": \"Rex's\"}"
+});
+console.log(response.status, await response.text());`},
+	}
+
+	for _, test := range tests {
+		snippet, err := Generate(doc, endpoint, test.format)
+		if err != nil {
+			t.Fatalf("Failed to generate %s snippet: %v", test.format, err)
+		}
+		if snippet != test.expected {
+			t.Errorf("Expected %s snippet:\n%s\ngot:\n%s", test.format, test.expected, snippet)
+		}
+	}
+}
+
+// TestGeneratePostman tests a Postman collection of a multipart upload
+func TestGeneratePostman(t *testing.T) {
+	doc := &models.APIDoc{Title: "Files"}
+	endpoint := models.Endpoint{
+		Method: "POST",
+		Path:   "/files",
+		Parameters: []models.Parameter{
+			{Name: "file", In: "body", Type: "file"},
+			{Name: "title", In: "body", Example: "Report"},
+		},
+		RequestBodies: []models.RequestBody{{ContentType: "multipart/form-data"}},
+	}
+
+	snippet, err := Generate(doc, endpoint, FormatPostman)
+	if err != nil {
+		t.Fatalf("Failed to generate Postman collection: %v", err)
+	}
+
+	var collection struct {
+		Item []struct {
+			Name    string
+			Request struct {
+				Method string
+				URL    string
+				Body   struct {
+					Mode     string
+					FormData []struct{ Key, Value, Type, Src string }
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(snippet), &collection); err != nil {
+		t.Fatalf("Expected a JSON collection, got %v:\n%s", err, snippet)
+	}
+	request := collection.Item[0].Request
+	if collection.Item[0].Name != "POST /files" || request.URL != "<base-url>/files" {
+		t.Errorf("Expected POST /files on a base URL placeholder, got %s %s", collection.Item[0].Name, request.URL)
+	}
+	if request.Body.Mode != "formdata" || len(request.Body.FormData) != 2 || request.Body.FormData[0].Type != "file" || request.Body.FormData[1].Value != "Report" {
+		t.Errorf("Expected a file and a text field, got %+v", request.Body)
+	}
+}
+
+// TestGenerateNotCallable tests that endpoints the API calls have no snippets
+func TestGenerateNotCallable(t *testing.T) {
+	webhook := models.Endpoint{Method: "POST", Path: "newPet", Kind: models.EndpointWebhook}
+	if _, err := Generate(&models.APIDoc{}, webhook, FormatCurl); err != ErrNotCallable {
+		t.Errorf("Expected a webhook not to be callable, got %v", err)
+	}
+
+	if _, err := Generate(&models.APIDoc{}, models.Endpoint{Method: "GET", Path: "/"}, "wget"); err == nil || !strings.Contains(err.Error(), "unknown snippet format") {
+		t.Errorf("Expected an unknown format to fail, got %v", err)
+	}
+}
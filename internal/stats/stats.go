@@ -0,0 +1,265 @@
+package stats
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"universal_api/internal/models"
+)
+
+// topDomainsLimit is the number of domains reported in TopDomains
+const topDomainsLimit = 10
+
+// mostViewedLimit is the number of docs reported in MostViewed
+const mostViewedLimit = 10
+
+// botPatterns are User-Agent fragments of crawlers, whose views aren't counted
+var botPatterns = []string{"bot", "crawler", "spider", "slurp", "curl", "wget", "python-requests", "headless"}
+
+// endpointBuckets are the upper bounds of the endpoints-per-doc histogram buckets
+var endpointBuckets = []struct {
+	Label string
+	Max   int
+}{
+	{"0", 0},
+	{"1-5", 5},
+	{"6-20", 20},
+	{"21-50", 50},
+	{"51-100", 100},
+	{"100+", -1},
+}
+
+// Stats holds catalog analytics
+type Stats struct {
+	TotalDocs       int            `json:"total_docs"`
+	TotalEndpoints  int            `json:"total_endpoints"`
+	DocsOverTime    []DateCount    `json:"docs_over_time"`
+	EndpointsPerDoc []BucketCount  `json:"endpoints_per_doc"`
+	Methods         map[string]int `json:"methods"`
+	TopDomains      []DomainCount  `json:"top_domains"`
+	MostViewed      []DocViews     `json:"most_viewed"`
+	Scrapes         ScrapeStats    `json:"scrapes"`
+	Instance        string         `json:"instance,omitempty"` // instance of the service that computed the stats
+}
+
+// DateCount is the cumulative number of docs at the end of a day
+type DateCount struct {
+	Date  string `json:"date"` // YYYY-MM-DD
+	Count int    `json:"count"`
+}
+
+// BucketCount is the number of docs in a histogram bucket
+type BucketCount struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// DomainCount is the number of docs hosted on a domain
+type DomainCount struct {
+	Domain string `json:"domain"`
+	Count  int    `json:"count"`
+}
+
+// DocViews is the number of views of a doc
+type DocViews struct {
+	DocID string `json:"doc_id"`
+	Title string `json:"title"`
+	Views int    `json:"views"`
+}
+
+// ScrapeStats summarizes scrape outcomes
+type ScrapeStats struct {
+	Total       int            `json:"total"`
+	Failed      int            `json:"failed"`
+	FailureRate float64        `json:"failure_rate"`
+	ByInstance  map[string]int `json:"by_instance,omitempty"` // scrapes run by each instance of the service
+}
+
+// ScrapeHistory is every recorded scrape of a URL, or of the URLs of a domain, newest first
+type ScrapeHistory struct {
+	URL          string                 `json:"url,omitempty"`
+	Domain       string                 `json:"domain,omitempty"`
+	Total        int                    `json:"total"`
+	Failed       int                    `json:"failed"`
+	LastSuccess  *time.Time             `json:"last_success,omitempty"`
+	LastFailure  *time.Time             `json:"last_failure,omitempty"`
+	FailingSince *time.Time             `json:"failing_since,omitempty"` // first failure of the current run of failures, if the latest scrape failed
+	Attempts     []*models.ScrapeRecord `json:"attempts"`
+}
+
+// Compute computes catalog analytics from the stored docs, scrape records, and view counts
+func Compute(docs []*models.APIDoc, scrapes []*models.ScrapeRecord, views []*models.ViewCounts) *Stats {
+	stats := &Stats{
+		TotalDocs:       len(docs),
+		DocsOverTime:    []DateCount{},
+		EndpointsPerDoc: make([]BucketCount, len(endpointBuckets)),
+		Methods:         make(map[string]int),
+		TopDomains:      []DomainCount{},
+		MostViewed:      MostViewed(docs, views, mostViewedLimit),
+	}
+	for i, bucket := range endpointBuckets {
+		stats.EndpointsPerDoc[i].Label = bucket.Label
+	}
+
+	perDay := make(map[string]int)
+	domains := make(map[string]int)
+	for _, doc := range docs {
+		stats.TotalEndpoints += len(doc.Endpoints)
+		perDay[doc.CreatedAt.Format("2006-01-02")]++
+
+		for i, bucket := range endpointBuckets {
+			if bucket.Max < 0 || len(doc.Endpoints) <= bucket.Max {
+				stats.EndpointsPerDoc[i].Count++
+				break
+			}
+		}
+
+		for _, endpoint := range doc.Endpoints {
+			stats.Methods[strings.ToUpper(endpoint.Method)]++
+		}
+
+		if domain := docDomain(doc); domain != "" {
+			domains[domain]++
+		}
+	}
+
+	// Cumulative doc count per day
+	days := make([]string, 0, len(perDay))
+	for day := range perDay {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+	total := 0
+	for _, day := range days {
+		total += perDay[day]
+		stats.DocsOverTime = append(stats.DocsOverTime, DateCount{Date: day, Count: total})
+	}
+
+	for domain, count := range domains {
+		stats.TopDomains = append(stats.TopDomains, DomainCount{Domain: domain, Count: count})
+	}
+	sort.Slice(stats.TopDomains, func(i, j int) bool {
+		if stats.TopDomains[i].Count != stats.TopDomains[j].Count {
+			return stats.TopDomains[i].Count > stats.TopDomains[j].Count
+		}
+		return stats.TopDomains[i].Domain < stats.TopDomains[j].Domain
+	})
+	if len(stats.TopDomains) > topDomainsLimit {
+		stats.TopDomains = stats.TopDomains[:topDomainsLimit]
+	}
+
+	for _, scrape := range scrapes {
+		stats.Scrapes.Total++
+		if !scrape.Success {
+			stats.Scrapes.Failed++
+		}
+		if scrape.Instance != "" {
+			if stats.Scrapes.ByInstance == nil {
+				stats.Scrapes.ByInstance = make(map[string]int)
+			}
+			stats.Scrapes.ByInstance[scrape.Instance]++
+		}
+	}
+	if stats.Scrapes.Total > 0 {
+		stats.Scrapes.FailureRate = float64(stats.Scrapes.Failed) / float64(stats.Scrapes.Total)
+	}
+
+	return stats
+}
+
+// History returns the scrapes of a URL, or of every URL on a domain when the URL is empty, from scrape
+// records oldest first. Domains match the URLs' hosts exactly, ignoring case.
+func History(scrapes []*models.ScrapeRecord, scrapedURL, domain string) *ScrapeHistory {
+	history := &ScrapeHistory{URL: scrapedURL, Domain: strings.ToLower(domain), Attempts: []*models.ScrapeRecord{}}
+	for _, scrape := range scrapes {
+		if scrapedURL != "" && scrape.URL != scrapedURL {
+			continue
+		}
+		if scrapedURL == "" && urlDomain(scrape.URL) != history.Domain {
+			continue
+		}
+
+		history.Total++
+		scrapedAt := scrape.ScrapedAt
+		if scrape.Success {
+			history.LastSuccess = &scrapedAt
+			history.FailingSince = nil
+		} else {
+			history.Failed++
+			history.LastFailure = &scrapedAt
+			if history.FailingSince == nil {
+				history.FailingSince = &scrapedAt
+			}
+		}
+		history.Attempts = append(history.Attempts, scrape)
+	}
+
+	// Newest first
+	for i, j := 0, len(history.Attempts)-1; i < j; i, j = i+1, j-1 {
+		history.Attempts[i], history.Attempts[j] = history.Attempts[j], history.Attempts[i]
+	}
+	return history
+}
+
+// MostViewed returns up to limit existing docs ordered by their page views and API reads
+func MostViewed(docs []*models.APIDoc, views []*models.ViewCounts, limit int) []DocViews {
+	titles := make(map[string]string, len(docs))
+	for _, doc := range docs {
+		titles[doc.ID] = doc.Title
+	}
+
+	mostViewed := []DocViews{}
+	for _, counts := range views {
+		title, ok := titles[counts.DocID]
+		total := counts.PageViews + counts.APIReads
+		if !ok || total == 0 {
+			continue
+		}
+		mostViewed = append(mostViewed, DocViews{DocID: counts.DocID, Title: title, Views: total})
+	}
+
+	sort.Slice(mostViewed, func(i, j int) bool {
+		if mostViewed[i].Views != mostViewed[j].Views {
+			return mostViewed[i].Views > mostViewed[j].Views
+		}
+		return mostViewed[i].DocID < mostViewed[j].DocID
+	})
+	if len(mostViewed) > limit {
+		mostViewed = mostViewed[:limit]
+	}
+	return mostViewed
+}
+
+// IsBot checks if a User-Agent belongs to a crawler or script, whose views shouldn't count
+func IsBot(userAgent string) bool {
+	lower := strings.ToLower(userAgent)
+	if lower == "" {
+		return true
+	}
+	for _, pattern := range botPatterns {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// docDomain returns the host of the doc's URL, or of its first server
+func docDomain(doc *models.APIDoc) string {
+	for _, candidate := range append([]string{doc.URL}, doc.Servers...) {
+		if domain := urlDomain(candidate); domain != "" {
+			return domain
+		}
+	}
+	return ""
+}
+
+// urlDomain returns the lowercase host of a URL, or an empty string if it has none
+func urlDomain(rawURL string) string {
+	if parsed, err := url.Parse(rawURL); err == nil {
+		return strings.ToLower(parsed.Hostname())
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"universal_api/internal/models"
+)
+
+// TestCompute tests the catalog analytics of a few docs and scrapes
+func TestCompute(t *testing.T) {
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	docs := []*models.APIDoc{
+		{URL: "https://api.example.com/openapi.json", CreatedAt: day, Endpoints: []models.Endpoint{{Method: "get"}, {Method: "POST"}}},
+		{URL: "https://api.example.com/v2.json", CreatedAt: day, Endpoints: []models.Endpoint{{Method: "GET"}}},
+		{Servers: []string{"https://other.example.com"}, CreatedAt: day.AddDate(0, 0, 2)},
+	}
+	scrapes := []*models.ScrapeRecord{{Success: true, Instance: "api-1"}, {Success: true, Instance: "api-2"}, {Success: false, Instance: "api-1"}, {Success: true}}
+
+	views := []*models.ViewCounts{
+		{DocID: "missing", PageViews: 50},
+		{DocID: "b", PageViews: 2, APIReads: 3},
+		{DocID: "a", PageViews: 4},
+	}
+	docs[0].ID, docs[1].ID, docs[2].ID = "a", "b", "c"
+
+	stats := Compute(docs, scrapes, views)
+
+	if stats.TotalDocs != 3 || stats.TotalEndpoints != 3 {
+		t.Errorf("Expected 3 docs and 3 endpoints, got %d and %d", stats.TotalDocs, stats.TotalEndpoints)
+	}
+
+	if len(stats.DocsOverTime) != 2 || stats.DocsOverTime[0].Count != 2 || stats.DocsOverTime[1] != (DateCount{"2024-05-03", 3}) {
+		t.Errorf("Expected cumulative counts of 2 then 3, got %v", stats.DocsOverTime)
+	}
+
+	if stats.EndpointsPerDoc[0].Count != 1 || stats.EndpointsPerDoc[1].Count != 2 {
+		t.Errorf("Expected 1 doc without endpoints and 2 with 1-5, got %v", stats.EndpointsPerDoc)
+	}
+
+	if stats.Methods["GET"] != 2 || stats.Methods["POST"] != 1 {
+		t.Errorf("Expected GET: 2 and POST: 1, got %v", stats.Methods)
+	}
+
+	if stats.TopDomains[0] != (DomainCount{"api.example.com", 2}) {
+		t.Errorf("Expected api.example.com to be the top domain, got %v", stats.TopDomains)
+	}
+
+	if len(stats.MostViewed) != 2 || stats.MostViewed[0].DocID != "b" || stats.MostViewed[0].Views != 5 {
+		t.Errorf("Expected b then a as most viewed, skipping deleted docs, got %v", stats.MostViewed)
+	}
+
+	if stats.Scrapes.FailureRate != 0.25 {
+		t.Errorf("Expected a 25%% failure rate, got %f", stats.Scrapes.FailureRate)
+	}
+	if stats.Scrapes.ByInstance["api-1"] != 2 || stats.Scrapes.ByInstance["api-2"] != 1 {
+		t.Errorf("Expected 2 scrapes by api-1 and 1 by api-2, got %v", stats.Scrapes.ByInstance)
+	}
+}
+
+// TestHistory tests the scrape history of a URL and of a domain, and when the current failures began
+func TestHistory(t *testing.T) {
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	scrapes := []*models.ScrapeRecord{
+		{URL: "https://api.example.com/openapi.json", Success: false, ScrapedAt: day},
+		{URL: "https://api.example.com/openapi.json", Success: true, Endpoints: 12, ScrapedAt: day.AddDate(0, 0, 1)},
+		{URL: "https://API.example.com/v2.json", Success: true, Endpoints: 3, ScrapedAt: day.AddDate(0, 0, 2)},
+		{URL: "https://api.example.com/openapi.json", Success: false, ScrapedAt: day.AddDate(0, 0, 3)},
+		{URL: "https://api.example.com/openapi.json", Success: false, ScrapedAt: day.AddDate(0, 0, 4)},
+		{URL: "https://other.example.com/openapi.json", Success: true, ScrapedAt: day.AddDate(0, 0, 5)},
+	}
+
+	history := History(scrapes, "https://api.example.com/openapi.json", "")
+	if history.Total != 4 || history.Failed != 3 {
+		t.Errorf("Expected 4 scrapes with 3 failures, got %d and %d", history.Total, history.Failed)
+	}
+	if !history.Attempts[0].ScrapedAt.Equal(day.AddDate(0, 0, 4)) {
+		t.Errorf("Expected the newest scrape first, got %v", history.Attempts[0].ScrapedAt)
+	}
+	if history.FailingSince == nil || !history.FailingSince.Equal(day.AddDate(0, 0, 3)) {
+		t.Errorf("Expected failures since day 3, got %v", history.FailingSince)
+	}
+	if history.LastSuccess == nil || !history.LastSuccess.Equal(day.AddDate(0, 0, 1)) {
+		t.Errorf("Expected the last success on day 1, got %v", history.LastSuccess)
+	}
+
+	history = History(scrapes, "", "api.EXAMPLE.com")
+	if history.Total != 5 || history.Attempts[2].Endpoints != 3 {
+		t.Errorf("Expected the 5 scrapes of api.example.com, got %d", history.Total)
+	}
+
+	history = History(scrapes, "", "other.example.com")
+	if history.FailingSince != nil || history.LastFailure != nil {
+		t.Errorf("Expected no failures, got %+v", history)
+	}
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+
+	"universal_api/internal/models"
+)
+
+// AttachmentStorage interface for storing files attached to docs. Listing and getting
+// attachments returns their metadata only, so content is only loaded when downloaded.
+type AttachmentStorage interface {
+	SaveAttachment(attachment *models.Attachment, data []byte) error
+	GetAttachment(id string) (*models.Attachment, error)
+	GetAttachmentData(id string) ([]byte, error)
+	GetDocAttachments(docID string) ([]*models.Attachment, error)
+	DeleteAttachment(id string) error
+}
+
+// storedAttachment is an attachment with its content, as kept in memory
+type storedAttachment struct {
+	attachment *models.Attachment
+	data       []byte
+}
+
+// SaveAttachment saves an attachment and its content to memory
+func (s *MemoryStorage) SaveAttachment(attachment *models.Attachment, data []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if attachment.ID == "" {
+		return errors.New("attachment ID cannot be empty")
+	}
+	if _, ok := s.docs[attachment.DocID]; !ok {
+		return errors.New("API doc not found")
+	}
+
+	s.attachments[attachment.ID] = &storedAttachment{attachment: attachment, data: data}
+	return nil
+}
+
+// GetAttachment gets the metadata of an attachment from memory
+func (s *MemoryStorage) GetAttachment(id string) (*models.Attachment, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	stored, ok := s.attachments[id]
+	if !ok {
+		return nil, errors.New("attachment not found")
+	}
+	return stored.attachment, nil
+}
+
+// GetAttachmentData gets the content of an attachment from memory
+func (s *MemoryStorage) GetAttachmentData(id string) ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	stored, ok := s.attachments[id]
+	if !ok {
+		return nil, errors.New("attachment not found")
+	}
+	return stored.data, nil
+}
+
+// GetDocAttachments gets the attachments of a doc from memory, oldest first
+func (s *MemoryStorage) GetDocAttachments(docID string) ([]*models.Attachment, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	attachments := []*models.Attachment{}
+	for _, stored := range s.attachments {
+		if stored.attachment.DocID == docID {
+			attachments = append(attachments, stored.attachment)
+		}
+	}
+	sort.Slice(attachments, func(i, j int) bool {
+		if !attachments[i].CreatedAt.Equal(attachments[j].CreatedAt) {
+			return attachments[i].CreatedAt.Before(attachments[j].CreatedAt)
+		}
+		return attachments[i].ID < attachments[j].ID
+	})
+	return attachments, nil
+}
+
+// DeleteAttachment deletes an attachment from memory
+func (s *MemoryStorage) DeleteAttachment(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.attachments[id]; !ok {
+		return errors.New("attachment not found")
+	}
+
+	delete(s.attachments, id)
+	return nil
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+
+	"universal_api/internal/models"
+)
+
+// CredentialStorage interface for storing encrypted scrape credentials
+type CredentialStorage interface {
+	SaveCredential(credential *models.Credential) error
+	GetCredential(id string) (*models.Credential, error)
+	GetAllCredentials() ([]*models.Credential, error)
+	DeleteCredential(id string) error
+}
+
+// SaveCredential saves a credential to memory
+func (s *MemoryStorage) SaveCredential(credential *models.Credential) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if credential.ID == "" {
+		return errors.New("credential ID cannot be empty")
+	}
+
+	s.credentials[credential.ID] = credential
+	return nil
+}
+
+// GetCredential gets a credential from memory
+func (s *MemoryStorage) GetCredential(id string) (*models.Credential, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	credential, ok := s.credentials[id]
+	if !ok {
+		return nil, errors.New("credential not found")
+	}
+	return credential, nil
+}
+
+// GetAllCredentials gets all credentials from memory, ordered by ID
+func (s *MemoryStorage) GetAllCredentials() ([]*models.Credential, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	credentials := make([]*models.Credential, 0, len(s.credentials))
+	for _, credential := range s.credentials {
+		credentials = append(credentials, credential)
+	}
+	sort.Slice(credentials, func(i, j int) bool { return credentials[i].ID < credentials[j].ID })
+	return credentials, nil
+}
+
+// DeleteCredential deletes a credential from memory
+func (s *MemoryStorage) DeleteCredential(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.credentials[id]; !ok {
+		return errors.New("credential not found")
+	}
+
+	delete(s.credentials, id)
+	return nil
+}
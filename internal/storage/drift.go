@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+	"universal_api/internal/models"
+)
+
+// DriftStorage interface for storing doc drift findings
+type DriftStorage interface {
+	SaveDriftFinding(finding *models.DriftFinding) error
+	GetDriftFindings(docID string) ([]*models.DriftFinding, error)
+}
+
+// SaveDriftFinding saves a drift finding to memory
+func (s *MemoryStorage) SaveDriftFinding(finding *models.DriftFinding) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if finding.ID == "" {
+		return errors.New("drift finding ID cannot be empty")
+	}
+
+	s.driftFindings[finding.DocID] = append(s.driftFindings[finding.DocID], finding)
+	return nil
+}
+
+// GetDriftFindings gets all drift findings of a doc from memory, newest first
+func (s *MemoryStorage) GetDriftFindings(docID string) ([]*models.DriftFinding, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	findings := append([]*models.DriftFinding{}, s.driftFindings[docID]...)
+	sort.Slice(findings, func(i, j int) bool {
+		return findings[i].ObservedAt.After(findings[j].ObservedAt)
+	})
+
+	return findings, nil
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"errors"
+	"time"
+
+	"universal_api/internal/models"
+)
+
+// idempotencySweepInterval is how often expired idempotency records are dropped from memory
+const idempotencySweepInterval = time.Minute
+
+// IdempotencyStorage interface for storing the responses to requests by idempotency key until they expire.
+// Claiming a key is atomic, so of concurrent requests with the same key only one is carried out.
+type IdempotencyStorage interface {
+	ClaimIdempotencyKey(record *models.IdempotencyRecord) (*models.IdempotencyRecord, error)
+	SaveIdempotencyRecord(record *models.IdempotencyRecord) error
+	DeleteIdempotencyRecord(key string) error
+}
+
+// ClaimIdempotencyKey saves a copy of the record of a request in progress to memory and returns nil,
+// unless an unexpired record of its key is saved already, a copy of which is returned instead
+func (s *MemoryStorage) ClaimIdempotencyKey(record *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if record.Key == "" {
+		return nil, errors.New("idempotency key cannot be empty")
+	}
+
+	now := time.Now()
+	s.sweepIdempotencyRecords(now)
+	if existing, ok := s.idempotency[record.Key]; ok && now.Before(existing.ExpiresAt) {
+		return copyIdempotencyRecord(existing), nil
+	}
+	s.idempotency[record.Key] = copyIdempotencyRecord(record)
+	return nil, nil
+}
+
+// SaveIdempotencyRecord saves a copy of the record of a finished request to memory, replacing its claim
+func (s *MemoryStorage) SaveIdempotencyRecord(record *models.IdempotencyRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if record.Key == "" {
+		return errors.New("idempotency key cannot be empty")
+	}
+
+	s.idempotency[record.Key] = copyIdempotencyRecord(record)
+	return nil
+}
+
+// copyIdempotencyRecord copies a record, so callers and concurrent retries never share one
+func copyIdempotencyRecord(record *models.IdempotencyRecord) *models.IdempotencyRecord {
+	copied := *record
+	copied.Body = append([]byte(nil), record.Body...)
+	return &copied
+}
+
+// DeleteIdempotencyRecord deletes the record of a key from memory, so the request can be retried
+func (s *MemoryStorage) DeleteIdempotencyRecord(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.idempotency, key)
+	return nil
+}
+
+// sweepIdempotencyRecords drops expired idempotency records, at most once per sweep interval.
+// The caller must hold the write lock.
+func (s *MemoryStorage) sweepIdempotencyRecords(now time.Time) {
+	if now.Sub(s.idempotencySweptAt) < idempotencySweepInterval {
+		return
+	}
+	s.idempotencySweptAt = now
+	for key, record := range s.idempotency {
+		if !now.Before(record.ExpiresAt) {
+			delete(s.idempotency, key)
+		}
+	}
+}
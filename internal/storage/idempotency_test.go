@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"universal_api/internal/models"
+)
+
+// TestClaimIdempotencyKey tests that a key is claimed once until its record expires or is deleted
+func TestClaimIdempotencyKey(t *testing.T) {
+	store := NewMemoryStorage()
+	now := time.Now()
+	claim := &models.IdempotencyRecord{Key: "key-1", Fingerprint: "a", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+
+	if existing, err := store.ClaimIdempotencyKey(claim); existing != nil || err != nil {
+		t.Fatalf("Expected to claim a new key, got %v, %v", existing, err)
+	}
+	existing, _ := store.ClaimIdempotencyKey(&models.IdempotencyRecord{Key: "key-1", Fingerprint: "b", ExpiresAt: now.Add(time.Hour)})
+	if existing == nil || existing == claim || existing.Fingerprint != "a" || existing.Status != 0 {
+		t.Errorf("Expected a copy of the claim in progress to be returned, got %v", existing)
+	}
+
+	// Changes to the records handed in or out don't reach the saved ones
+	claim.Status = 500
+	existing.Status = 500
+
+	finished := *claim
+	finished.Status = 201
+	finished.Body = []byte(`{"id": "doc-1"}`)
+	store.SaveIdempotencyRecord(&finished)
+	finished.Body[0] = '['
+	if existing, _ := store.ClaimIdempotencyKey(claim); existing == nil || existing.Status != 201 || string(existing.Body) != `{"id": "doc-1"}` {
+		t.Errorf("Expected the finished record to be returned as saved, got %v", existing)
+	}
+
+	store.DeleteIdempotencyRecord("key-1")
+	if existing, _ := store.ClaimIdempotencyKey(claim); existing != nil {
+		t.Errorf("Expected a deleted key to be claimed again, got %v", existing)
+	}
+
+	expired := &models.IdempotencyRecord{Key: "key-2", ExpiresAt: now.Add(-time.Second)}
+	store.SaveIdempotencyRecord(expired)
+	if existing, _ := store.ClaimIdempotencyKey(&models.IdempotencyRecord{Key: "key-2", ExpiresAt: now.Add(time.Hour)}); existing != nil {
+		t.Errorf("Expected an expired key to be claimed again, got %v", existing)
+	}
+
+	if _, err := store.ClaimIdempotencyKey(&models.IdempotencyRecord{}); err == nil {
+		t.Errorf("Expected an error for an empty key")
+	}
+}
+
+// TestSweepIdempotencyRecords tests that expired records are dropped from memory
+func TestSweepIdempotencyRecords(t *testing.T) {
+	store := NewMemoryStorage()
+	now := time.Now()
+	store.SaveIdempotencyRecord(&models.IdempotencyRecord{Key: "expired", ExpiresAt: now.Add(-time.Second)})
+	store.SaveIdempotencyRecord(&models.IdempotencyRecord{Key: "live", ExpiresAt: now.Add(time.Hour)})
+
+	store.sweepIdempotencyRecords(now)
+	if _, ok := store.idempotency["expired"]; ok {
+		t.Errorf("Expected the expired record to be dropped")
+	}
+	if _, ok := store.idempotency["live"]; !ok {
+		t.Errorf("Expected the live record to be kept")
+	}
+}
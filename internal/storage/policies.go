@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+
+	"universal_api/internal/models"
+)
+
+// PolicyStorage interface for storing per-domain scrape policies
+type PolicyStorage interface {
+	SaveScrapePolicy(policy *models.ScrapePolicy) error
+	GetScrapePolicy(domain string) (*models.ScrapePolicy, error)
+	GetAllScrapePolicies() ([]*models.ScrapePolicy, error)
+	DeleteScrapePolicy(domain string) error
+}
+
+// SaveScrapePolicy saves a scrape policy to memory
+func (s *MemoryStorage) SaveScrapePolicy(policy *models.ScrapePolicy) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if policy.Domain == "" {
+		return errors.New("scrape policy domain cannot be empty")
+	}
+
+	s.policies[policy.Domain] = policy
+	return nil
+}
+
+// GetScrapePolicy gets the scrape policy of a domain from memory
+func (s *MemoryStorage) GetScrapePolicy(domain string) (*models.ScrapePolicy, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	policy, ok := s.policies[domain]
+	if !ok {
+		return nil, errors.New("scrape policy not found")
+	}
+	return policy, nil
+}
+
+// GetAllScrapePolicies gets all scrape policies from memory, ordered by domain
+func (s *MemoryStorage) GetAllScrapePolicies() ([]*models.ScrapePolicy, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	policies := make([]*models.ScrapePolicy, 0, len(s.policies))
+	for _, policy := range s.policies {
+		policies = append(policies, policy)
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Domain < policies[j].Domain })
+	return policies, nil
+}
+
+// DeleteScrapePolicy deletes the scrape policy of a domain from memory
+func (s *MemoryStorage) DeleteScrapePolicy(domain string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.policies[domain]; !ok {
+		return errors.New("scrape policy not found")
+	}
+
+	delete(s.policies, domain)
+	return nil
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"time"
+
+	"universal_api/internal/models"
+)
+
+// MaxRawSources is the number of raw sources kept per doc; older ones are dropped
+const MaxRawSources = 10
+
+// RawSourceStorage interface for archiving the raw content docs were scraped from. Listing and getting
+// raw sources returns their metadata only, so content is only decompressed when it's read.
+type RawSourceStorage interface {
+	SaveRawSource(source *models.RawSource) error
+	GetRawSource(docID string, version int) (*models.RawSource, error)
+	GetRawSourceContent(docID string, version int) ([]byte, error)
+	GetRawSources(docID string) ([]*models.RawSource, error)
+}
+
+// storedRawSource is a raw source with its gzipped content, as kept in memory
+type storedRawSource struct {
+	source     *models.RawSource
+	compressed []byte
+}
+
+// SaveRawSource archives the content of a raw source compressed, numbering it after the doc's latest
+// version. Content identical to the latest version's only updates when it was fetched.
+func (s *MemoryStorage) SaveRawSource(source *models.RawSource) error {
+	if source.DocID == "" {
+		return errors.New("raw source doc ID cannot be empty")
+	}
+	if source.FetchedAt.IsZero() {
+		source.FetchedAt = time.Now()
+	}
+	sum := sha256.Sum256(source.Content)
+	compressed, err := gzipContent(source.Content)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored := s.rawSources[source.DocID]
+	if len(stored) > 0 && stored[len(stored)-1].source.SHA256 == hex.EncodeToString(sum[:]) {
+		latest := *stored[len(stored)-1].source
+		latest.FetchedAt = source.FetchedAt
+		stored[len(stored)-1].source = &latest
+		source.Version = latest.Version
+		return nil
+	}
+
+	archived := *source
+	archived.Content = nil
+	archived.Version = 1
+	if len(stored) > 0 {
+		archived.Version = stored[len(stored)-1].source.Version + 1
+	}
+	archived.Size = int64(len(source.Content))
+	archived.CompressedSize = int64(len(compressed))
+	archived.SHA256 = hex.EncodeToString(sum[:])
+
+	stored = append(stored, &storedRawSource{source: &archived, compressed: compressed})
+	if len(stored) > MaxRawSources {
+		stored = stored[len(stored)-MaxRawSources:]
+	}
+	s.rawSources[source.DocID] = stored
+	source.Version = archived.Version
+	return nil
+}
+
+// GetRawSource gets the metadata of a version of a doc's raw source from memory, the latest when version is 0
+func (s *MemoryStorage) GetRawSource(docID string, version int) (*models.RawSource, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	stored, err := s.rawSource(docID, version)
+	if err != nil {
+		return nil, err
+	}
+	return stored.source, nil
+}
+
+// GetRawSourceContent gets the decompressed content of a version of a doc's raw source from memory,
+// the latest when version is 0
+func (s *MemoryStorage) GetRawSourceContent(docID string, version int) ([]byte, error) {
+	s.mutex.RLock()
+	stored, err := s.rawSource(docID, version)
+	s.mutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(stored.compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// GetRawSources gets the metadata of the archived raw sources of a doc from memory, oldest first
+func (s *MemoryStorage) GetRawSources(docID string) ([]*models.RawSource, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	sources := []*models.RawSource{}
+	for _, stored := range s.rawSources[docID] {
+		sources = append(sources, stored.source)
+	}
+	return sources, nil
+}
+
+// rawSource finds a version of a doc's raw source, the latest when version is 0. The caller must hold the lock.
+func (s *MemoryStorage) rawSource(docID string, version int) (*storedRawSource, error) {
+	stored := s.rawSources[docID]
+	if len(stored) == 0 {
+		return nil, errors.New("raw source not found")
+	}
+	if version == 0 {
+		return stored[len(stored)-1], nil
+	}
+	for _, candidate := range stored {
+		if candidate.source.Version == version {
+			return candidate, nil
+		}
+	}
+	return nil, errors.New("raw source not found")
+}
+
+// gzipContent compresses content for storage
+func gzipContent(content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(content); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+
+	"universal_api/internal/models"
+)
+
+// SchemaStorage interface for storing the schema catalog
+type SchemaStorage interface {
+	// SaveDocSchemas replaces the schemas of a doc; no schemas deletes them
+	SaveDocSchemas(docID string, schemas []*models.Schema) error
+	GetSchema(id string) (*models.Schema, error)
+	GetAllSchemas() ([]*models.Schema, error)
+}
+
+// SaveDocSchemas replaces the schemas of a doc in memory
+func (s *MemoryStorage) SaveDocSchemas(docID string, schemas []*models.Schema) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for id, schema := range s.schemas {
+		if schema.DocID == docID {
+			delete(s.schemas, id)
+		}
+	}
+	for _, schema := range schemas {
+		s.schemas[schema.ID] = schema
+	}
+	return nil
+}
+
+// GetSchema gets a schema from memory
+func (s *MemoryStorage) GetSchema(id string) (*models.Schema, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	schema, ok := s.schemas[id]
+	if !ok {
+		return nil, errors.New("schema not found")
+	}
+	return schema, nil
+}
+
+// GetAllSchemas gets all schemas from memory, ordered by ID
+func (s *MemoryStorage) GetAllSchemas() ([]*models.Schema, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	schemas := make([]*models.Schema, 0, len(s.schemas))
+	for _, schema := range s.schemas {
+		schemas = append(schemas, schema)
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].ID < schemas[j].ID })
+	return schemas, nil
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"universal_api/internal/models"
+)
+
+// maxScrapeRecords bounds the number of scrape records kept in memory
+const maxScrapeRecords = 10000
+
+// ScrapeStorage interface for storing scrape outcomes
+type ScrapeStorage interface {
+	RecordScrape(record *models.ScrapeRecord) error
+	GetScrapeRecords() ([]*models.ScrapeRecord, error)
+}
+
+// RecordScrape saves a scrape record to memory, dropping the oldest records beyond the limit
+func (s *MemoryStorage) RecordScrape(record *models.ScrapeRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.scrapes = append(s.scrapes, record)
+	if len(s.scrapes) > maxScrapeRecords {
+		s.scrapes = append([]*models.ScrapeRecord{}, s.scrapes[len(s.scrapes)-maxScrapeRecords:]...)
+	}
+	return nil
+}
+
+// GetScrapeRecords gets all scrape records from memory, oldest first
+func (s *MemoryStorage) GetScrapeRecords() ([]*models.ScrapeRecord, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return append([]*models.ScrapeRecord{}, s.scrapes...), nil
+}
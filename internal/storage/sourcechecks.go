@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+
+	"universal_api/internal/models"
+)
+
+// SourceCheckStorage interface for storing the latest check of each doc's source URL
+type SourceCheckStorage interface {
+	SaveSourceCheck(check *models.SourceCheck) error
+	GetSourceCheck(docID string) (*models.SourceCheck, error)
+	GetSourceChecks() ([]*models.SourceCheck, error)
+}
+
+// SaveSourceCheck saves the latest source check of a doc to memory, replacing the previous one
+func (s *MemoryStorage) SaveSourceCheck(check *models.SourceCheck) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if check.DocID == "" {
+		return errors.New("source check doc ID cannot be empty")
+	}
+
+	s.sourceChecks[check.DocID] = check
+	return nil
+}
+
+// GetSourceCheck gets the latest source check of a doc from memory
+func (s *MemoryStorage) GetSourceCheck(docID string) (*models.SourceCheck, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	check, ok := s.sourceChecks[docID]
+	if !ok {
+		return nil, errors.New("source check not found")
+	}
+	return check, nil
+}
+
+// GetSourceChecks gets the latest source check of every doc from memory, ordered by doc ID
+func (s *MemoryStorage) GetSourceChecks() ([]*models.SourceCheck, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	checks := make([]*models.SourceCheck, 0, len(s.sourceChecks))
+	for _, check := range s.sourceChecks {
+		checks = append(checks, check)
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].DocID < checks[j].DocID
+	})
+	return checks, nil
+}
@@ -1,28 +1,83 @@
 package storage
 
 import (
+	"encoding/json"
 	"errors"
+	"slices"
 	"sync"
+	"time"
+	"universal_api/internal/diff"
 	"universal_api/internal/models"
 )
 
-// Storage interface for storing API docs
+// Parts of docs that partial loads leave out unless they're named, as they make up most of a doc's size
+const (
+	PartEndpoints = "endpoints"
+	PartSchemas   = "schemas"
+)
+
+// Parts lists the parts of docs partial loads leave out unless they're named
+var Parts = []string{PartEndpoints, PartSchemas}
+
+// Storage interface for storing API docs. Partially loaded docs are copies lacking parts, so they must
+// not be saved back.
 type Storage interface {
 	SaveAPIDoc(doc *models.APIDoc) error
 	GetAPIDoc(id string) (*models.APIDoc, error)
 	GetAllAPIDocs() ([]*models.APIDoc, error)
+	GetAllAPIDocsPartial(parts ...string) ([]*models.APIDoc, error)
+	DeleteAPIDoc(id string) error
+	GetAPIDocVersions(id string) ([]*models.APIDocVersion, error)
+}
+
+// Pinger is a storage backend that can check it's reachable and responding, for health checks
+type Pinger interface {
+	Ping() error
 }
 
 // MemoryStorage implements Storage using in-memory storage
 type MemoryStorage struct {
-	docs  map[string]*models.APIDoc
-	mutex sync.RWMutex
+	docs          map[string]*models.APIDoc
+	versions      map[string][]*models.APIDocVersion
+	watches       map[string]*models.Watch
+	notifications map[string]*models.Notification
+	driftFindings map[string][]*models.DriftFinding
+	sourceChecks  map[string]*models.SourceCheck // by doc ID
+	rawSources    map[string][]*storedRawSource  // by doc ID, oldest first
+	scrapes       []*models.ScrapeRecord
+	views         map[string]*models.ViewCounts
+	workspaces    map[string]*models.Workspace
+	users         map[string]*models.User
+	apiKeys       map[string]*models.APIKey // by key hash
+	credentials   map[string]*models.Credential
+	policies      map[string]*models.ScrapePolicy // by domain
+	schemas       map[string]*models.Schema
+	attachments   map[string]*storedAttachment
+	idempotency   map[string]*models.IdempotencyRecord // by scoped key
+	mutex         sync.RWMutex
+
+	idempotencySweptAt time.Time // when expired idempotency records were last dropped
 }
 
 // NewMemoryStorage creates a new MemoryStorage
 func NewMemoryStorage() *MemoryStorage {
 	return &MemoryStorage{
-		docs: make(map[string]*models.APIDoc),
+		docs:          make(map[string]*models.APIDoc),
+		versions:      make(map[string][]*models.APIDocVersion),
+		watches:       make(map[string]*models.Watch),
+		notifications: make(map[string]*models.Notification),
+		driftFindings: make(map[string][]*models.DriftFinding),
+		sourceChecks:  make(map[string]*models.SourceCheck),
+		rawSources:    make(map[string][]*storedRawSource),
+		views:         make(map[string]*models.ViewCounts),
+		workspaces:    map[string]*models.Workspace{models.DefaultWorkspace: defaultWorkspace()},
+		users:         make(map[string]*models.User),
+		apiKeys:       make(map[string]*models.APIKey),
+		credentials:   make(map[string]*models.Credential),
+		policies:      make(map[string]*models.ScrapePolicy),
+		schemas:       make(map[string]*models.Schema),
+		attachments:   make(map[string]*storedAttachment),
+		idempotency:   make(map[string]*models.IdempotencyRecord),
 	}
 }
 
@@ -35,7 +90,84 @@ func (s *MemoryStorage) SaveAPIDoc(doc *models.APIDoc) error {
 		return errors.New("API doc ID cannot be empty")
 	}
 
+	models.Canonicalize(doc)
+	models.AssignEndpointIDs(doc)
+	reviseDoc(doc, s.docs[doc.ID])
 	s.docs[doc.ID] = doc
+	s.recordVersion(doc)
+	return nil
+}
+
+// reviseDoc sets the revision of a doc being saved over the saved one, if any. Unchanged content keeps
+// the saved revision and update time, so the doc's ETag and Last-Modified only change with it, and
+// changed content gets the next revision.
+func reviseDoc(doc, saved *models.APIDoc) {
+	digest := models.ContentDigest(doc)
+	switch {
+	case saved == nil:
+		// Imported docs keep their revision
+		if doc.Revision == 0 {
+			doc.Revision = 1
+		}
+		if doc.UpdatedAt.IsZero() {
+			doc.UpdatedAt = time.Now()
+		}
+	case saved.Digest == digest:
+		doc.Revision, doc.UpdatedAt = saved.Revision, saved.UpdatedAt
+	default:
+		doc.Revision, doc.UpdatedAt = saved.Revision+1, time.Now()
+	}
+	doc.Digest = digest
+}
+
+// recordVersion snapshots the doc if its endpoints changed since the last version.
+// The caller must hold the write lock.
+func (s *MemoryStorage) recordVersion(doc *models.APIDoc) {
+	versions := s.versions[doc.ID]
+	if len(versions) > 0 && !diff.Compare(versions[len(versions)-1].Doc, doc).HasChanges() {
+		return
+	}
+
+	s.versions[doc.ID] = append(versions, &models.APIDocVersion{
+		Number:    len(versions) + 1,
+		Doc:       snapshotDoc(doc),
+		CreatedAt: time.Now(),
+	})
+}
+
+// snapshotDoc deeply copies a doc for its version history, so a saved doc edited in place and saved
+// again doesn't rewrite its past versions through the endpoints and slices they'd share
+func snapshotDoc(doc *models.APIDoc) *models.APIDoc {
+	snapshot := &models.APIDoc{}
+	data, err := json.Marshal(doc)
+	if err == nil {
+		err = json.Unmarshal(data, snapshot)
+	}
+	if err != nil {
+		copied := *doc
+		return &copied
+	}
+	snapshot.Digest = doc.Digest
+	return snapshot
+}
+
+// GetAPIDocVersions gets the version history of an API doc from memory, oldest first
+func (s *MemoryStorage) GetAPIDocVersions(id string) ([]*models.APIDocVersion, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	versions, ok := s.versions[id]
+	if !ok {
+		return nil, errors.New("API doc not found")
+	}
+
+	return append([]*models.APIDocVersion(nil), versions...), nil
+}
+
+// Ping checks the storage responds, which it does unless its lock is held for too long
+func (s *MemoryStorage) Ping() error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 	return nil
 }
 
@@ -65,6 +197,52 @@ func (s *MemoryStorage) GetAllAPIDocs() ([]*models.APIDoc, error) {
 	return docs, nil
 }
 
+// GetAllAPIDocsPartial gets all API docs from memory with only the named parts loaded
+func (s *MemoryStorage) GetAllAPIDocsPartial(parts ...string) ([]*models.APIDoc, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	docs := make([]*models.APIDoc, 0, len(s.docs))
+	for _, doc := range s.docs {
+		docs = append(docs, partialDoc(doc, parts))
+	}
+
+	return docs, nil
+}
+
+// partialDoc copies a doc without the parts that aren't named
+func partialDoc(doc *models.APIDoc, parts []string) *models.APIDoc {
+	partial := *doc
+	if !slices.Contains(parts, PartEndpoints) {
+		partial.Endpoints = nil
+	}
+	if !slices.Contains(parts, PartSchemas) {
+		partial.Schemas = nil
+	}
+	return &partial
+}
+
+// DeleteAPIDoc deletes an API doc from memory
+func (s *MemoryStorage) DeleteAPIDoc(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.docs[id]; !ok {
+		return errors.New("API doc not found")
+	}
+
+	delete(s.docs, id)
+	delete(s.versions, id)
+	delete(s.sourceChecks, id)
+	delete(s.rawSources, id)
+	for attachmentID, stored := range s.attachments {
+		if stored.attachment.DocID == id {
+			delete(s.attachments, attachmentID)
+		}
+	}
+	return nil
+}
+
 // SQLiteStorage implements Storage using SQLite
 // This is a placeholder for future implementation
 type SQLiteStorage struct {
@@ -76,6 +254,12 @@ func NewSQLiteStorage() *SQLiteStorage {
 	return &SQLiteStorage{}
 }
 
+// Ping checks the SQLite database is reachable
+func (s *SQLiteStorage) Ping() error {
+	// This would be implemented to ping the database
+	return errors.New("SQLite storage not implemented yet")
+}
+
 // SaveAPIDoc saves an API doc to SQLite
 func (s *SQLiteStorage) SaveAPIDoc(doc *models.APIDoc) error {
 	// This would be implemented to save to SQLite
@@ -93,3 +277,21 @@ func (s *SQLiteStorage) GetAllAPIDocs() ([]*models.APIDoc, error) {
 	// This would be implemented to get all from SQLite
 	return nil, errors.New("SQLite storage not implemented yet")
 }
+
+// GetAllAPIDocsPartial gets all API docs from SQLite with only the named parts loaded
+func (s *SQLiteStorage) GetAllAPIDocsPartial(parts ...string) ([]*models.APIDoc, error) {
+	// This would be implemented to select only the named parts' columns
+	return nil, errors.New("SQLite storage not implemented yet")
+}
+
+// DeleteAPIDoc deletes an API doc from SQLite
+func (s *SQLiteStorage) DeleteAPIDoc(id string) error {
+	// This would be implemented to delete from SQLite
+	return errors.New("SQLite storage not implemented yet")
+}
+
+// GetAPIDocVersions gets the version history of an API doc from SQLite
+func (s *SQLiteStorage) GetAPIDocVersions(id string) ([]*models.APIDocVersion, error) {
+	// This would be implemented to get versions from SQLite
+	return nil, errors.New("SQLite storage not implemented yet")
+}
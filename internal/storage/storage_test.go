@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestVersionHistory tests that saving a doc records a version only when its endpoints change, and that
+// editing the saved doc in place doesn't rewrite its past versions
+func TestVersionHistory(t *testing.T) {
+	store := NewMemoryStorage()
+	doc := &models.APIDoc{
+		ID:      "pets",
+		Version: "1.0",
+		Endpoints: []models.Endpoint{{
+			Path:       "/pets",
+			Method:     "GET",
+			Parameters: []models.Parameter{{Name: "limit", In: "query", Type: "integer"}},
+		}},
+	}
+	if err := store.SaveAPIDoc(doc); err != nil {
+		t.Fatalf("Failed to save doc: %v", err)
+	}
+
+	// Saving the doc unchanged records no version
+	if err := store.SaveAPIDoc(doc); err != nil {
+		t.Fatalf("Failed to save doc: %v", err)
+	}
+	versions, err := store.GetAPIDocVersions("pets")
+	if err != nil || len(versions) != 1 {
+		t.Fatalf("Expected 1 version, got %d, %v", len(versions), err)
+	}
+
+	saved, err := store.GetAPIDoc("pets")
+	if err != nil {
+		t.Fatalf("Failed to get doc: %v", err)
+	}
+	saved.Version = "1.1"
+	saved.Endpoints[0].Parameters[0].Type = "string"
+	saved.Endpoints[0].Summary = "List pets"
+	if err := store.SaveAPIDoc(saved); err != nil {
+		t.Fatalf("Failed to save doc: %v", err)
+	}
+
+	versions, err = store.GetAPIDocVersions("pets")
+	if err != nil || len(versions) != 2 {
+		t.Fatalf("Expected 2 versions, got %d, %v", len(versions), err)
+	}
+	first := versions[0].Doc
+	if versions[0].Number != 1 || first.Version != "1.0" || first.Endpoints[0].Parameters[0].Type != "integer" || first.Endpoints[0].Summary != "" {
+		t.Errorf("Expected version 1 to keep the doc as first saved, got %+v", first)
+	}
+	if versions[1].Number != 2 || versions[1].Doc.Endpoints[0].Parameters[0].Type != "string" {
+		t.Errorf("Expected version 2 to have the edited doc, got %+v", versions[1].Doc)
+	}
+	if first.Digest == "" {
+		t.Errorf("Expected the snapshot to keep the doc's digest")
+	}
+
+	if _, err := store.GetAPIDocVersions("missing"); err == nil {
+		t.Errorf("Expected the versions of an unknown doc to be an error")
+	}
+}
+
+// TestGetAllAPIDocsPartial tests that docs are loaded with only the named parts, without changing the stored docs
+func TestGetAllAPIDocsPartial(t *testing.T) {
+	store := NewMemoryStorage()
+	doc := &models.APIDoc{
+		ID:        "pets",
+		Title:     "Pets",
+		Endpoints: []models.Endpoint{{Path: "/pets", Method: "GET"}},
+		Schemas:   map[string]string{"Pet": "{}"},
+	}
+	if err := store.SaveAPIDoc(doc); err != nil {
+		t.Fatalf("Failed to save doc: %v", err)
+	}
+
+	tests := []struct {
+		parts     []string
+		endpoints bool
+		schemas   bool
+	}{
+		{nil, false, false},
+		{[]string{PartEndpoints}, true, false},
+		{[]string{PartSchemas}, false, true},
+		{Parts, true, true},
+	}
+	for _, test := range tests {
+		docs, err := store.GetAllAPIDocsPartial(test.parts...)
+		if err != nil || len(docs) != 1 {
+			t.Fatalf("Expected 1 doc, got %d, %v", len(docs), err)
+		}
+		partial := docs[0]
+		if partial.Title != "Pets" {
+			t.Errorf("Expected the doc's fields with parts %v, got %+v", test.parts, partial)
+		}
+		if (partial.Endpoints != nil) != test.endpoints || (partial.Schemas != nil) != test.schemas {
+			t.Errorf("Expected endpoints %v and schemas %v with parts %v, got %+v", test.endpoints, test.schemas, test.parts, partial)
+		}
+		if partial == doc {
+			t.Errorf("Expected a copy of the stored doc")
+		}
+	}
+
+	stored, err := store.GetAPIDoc("pets")
+	if err != nil || len(stored.Endpoints) != 1 || len(stored.Schemas) != 1 {
+		t.Errorf("Expected the stored doc to keep its endpoints and schemas, got %+v, %v", stored, err)
+	}
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+
+	"universal_api/internal/models"
+)
+
+// UserStorage interface for storing users and their API keys
+type UserStorage interface {
+	SaveUser(user *models.User) error
+	GetUser(id string) (*models.User, error)
+	GetAllUsers() ([]*models.User, error)
+	DeleteUser(id string) error
+	SaveAPIKey(key *models.APIKey) error
+	GetAPIKeyByHash(hash string) (*models.APIKey, error)
+	GetAPIKeys(userID string) ([]*models.APIKey, error)
+	DeleteAPIKey(id string) error
+}
+
+// SaveUser saves a user to memory
+func (s *MemoryStorage) SaveUser(user *models.User) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if user.ID == "" {
+		return errors.New("user ID cannot be empty")
+	}
+
+	s.users[user.ID] = user
+	return nil
+}
+
+// GetUser gets a user from memory
+func (s *MemoryStorage) GetUser(id string) (*models.User, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+// GetAllUsers gets all users from memory, ordered by ID
+func (s *MemoryStorage) GetAllUsers() ([]*models.User, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	users := make([]*models.User, 0, len(s.users))
+	for _, user := range s.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+// DeleteUser deletes a user and their API keys from memory
+func (s *MemoryStorage) DeleteUser(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.users[id]; !ok {
+		return errors.New("user not found")
+	}
+
+	delete(s.users, id)
+	for hash, key := range s.apiKeys {
+		if key.UserID == id {
+			delete(s.apiKeys, hash)
+		}
+	}
+	return nil
+}
+
+// SaveAPIKey saves an API key to memory
+func (s *MemoryStorage) SaveAPIKey(key *models.APIKey) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if key.ID == "" || key.Hash == "" {
+		return errors.New("API key ID and hash cannot be empty")
+	}
+
+	s.apiKeys[key.Hash] = key
+	return nil
+}
+
+// GetAPIKeyByHash gets the API key with a hash from memory
+func (s *MemoryStorage) GetAPIKeyByHash(hash string) (*models.APIKey, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	key, ok := s.apiKeys[hash]
+	if !ok {
+		return nil, errors.New("API key not found")
+	}
+	return key, nil
+}
+
+// GetAPIKeys gets the API keys of a user from memory, oldest first
+func (s *MemoryStorage) GetAPIKeys(userID string) ([]*models.APIKey, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var keys []*models.APIKey
+	for _, key := range s.apiKeys {
+		if key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+// DeleteAPIKey deletes an API key from memory
+func (s *MemoryStorage) DeleteAPIKey(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for hash, key := range s.apiKeys {
+		if key.ID == id {
+			delete(s.apiKeys, hash)
+			return nil
+		}
+	}
+	return errors.New("API key not found")
+}
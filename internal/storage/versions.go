@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"errors"
+
+	"universal_api/internal/models"
+)
+
+// VersionStorage interface for restoring the version history of API docs, e.g. from an archive
+type VersionStorage interface {
+	RestoreAPIDocVersions(id string, versions []*models.APIDocVersion) error
+}
+
+// RestoreAPIDocVersions replaces the version history of a saved API doc, renumbering the versions oldest first
+func (s *MemoryStorage) RestoreAPIDocVersions(id string, versions []*models.APIDocVersion) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.docs[id]; !ok {
+		return errors.New("API doc not found")
+	}
+	if len(versions) == 0 {
+		return nil
+	}
+
+	restored := make([]*models.APIDocVersion, len(versions))
+	for i, version := range versions {
+		restored[i] = &models.APIDocVersion{Number: i + 1, Doc: version.Doc, CreatedAt: version.CreatedAt}
+	}
+	s.versions[id] = restored
+	return nil
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"time"
+
+	"universal_api/internal/models"
+)
+
+// viewRetentionDays is the number of days of daily view counts kept per doc
+const viewRetentionDays = 90
+
+// ViewStorage interface for storing aggregated doc views
+type ViewStorage interface {
+	RecordView(docID, endpoint, kind string) error
+	GetViewCounts(docID string) (*models.ViewCounts, error)
+	GetAllViewCounts() ([]*models.ViewCounts, error)
+}
+
+// RecordView counts a view of a doc, and of one of its endpoints when endpoint is set
+func (s *MemoryStorage) RecordView(docID, endpoint, kind string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	counts, ok := s.views[docID]
+	if !ok {
+		counts = &models.ViewCounts{DocID: docID, Endpoints: make(map[string]int), Daily: make(map[string]int)}
+		s.views[docID] = counts
+	}
+
+	if kind == models.ViewKindAPI {
+		counts.APIReads++
+	} else {
+		counts.PageViews++
+	}
+	if endpoint != "" {
+		counts.Endpoints[endpoint]++
+	}
+
+	today := time.Now().Format("2006-01-02")
+	counts.Daily[today]++
+
+	// Drop daily counts past the retention period
+	cutoff := time.Now().AddDate(0, 0, -viewRetentionDays).Format("2006-01-02")
+	for day := range counts.Daily {
+		if day < cutoff {
+			delete(counts.Daily, day)
+		}
+	}
+
+	return nil
+}
+
+// GetViewCounts gets the view counts of a doc from memory
+func (s *MemoryStorage) GetViewCounts(docID string) (*models.ViewCounts, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	counts, ok := s.views[docID]
+	if !ok {
+		return &models.ViewCounts{DocID: docID}, nil
+	}
+	return copyViewCounts(counts), nil
+}
+
+// GetAllViewCounts gets the view counts of every viewed doc from memory
+func (s *MemoryStorage) GetAllViewCounts() ([]*models.ViewCounts, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	all := make([]*models.ViewCounts, 0, len(s.views))
+	for _, counts := range s.views {
+		all = append(all, copyViewCounts(counts))
+	}
+	return all, nil
+}
+
+// copyViewCounts copies view counts so callers can't race with RecordView
+func copyViewCounts(counts *models.ViewCounts) *models.ViewCounts {
+	copied := *counts
+	copied.Endpoints = make(map[string]int, len(counts.Endpoints))
+	for key, value := range counts.Endpoints {
+		copied.Endpoints[key] = value
+	}
+	copied.Daily = make(map[string]int, len(counts.Daily))
+	for key, value := range counts.Daily {
+		copied.Daily[key] = value
+	}
+	return &copied
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+	"universal_api/internal/models"
+)
+
+// WatchStorage interface for storing doc watches and in-app notifications
+type WatchStorage interface {
+	SaveWatch(watch *models.Watch) error
+	DeleteWatch(id string) error
+	GetWatchesBySubscriber(subscriber string) ([]*models.Watch, error)
+	GetWatchesByDoc(docID string) ([]*models.Watch, error)
+	SaveNotification(notification *models.Notification) error
+	GetNotifications(subscriber string) ([]*models.Notification, error)
+}
+
+// SaveWatch saves a watch to memory
+func (s *MemoryStorage) SaveWatch(watch *models.Watch) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if watch.ID == "" {
+		return errors.New("watch ID cannot be empty")
+	}
+
+	s.watches[watch.ID] = watch
+	return nil
+}
+
+// DeleteWatch deletes a watch from memory
+func (s *MemoryStorage) DeleteWatch(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.watches[id]; !ok {
+		return errors.New("watch not found")
+	}
+
+	delete(s.watches, id)
+	return nil
+}
+
+// GetWatchesBySubscriber gets all watches of a subscriber from memory
+func (s *MemoryStorage) GetWatchesBySubscriber(subscriber string) ([]*models.Watch, error) {
+	return s.filterWatches(func(watch *models.Watch) bool {
+		return watch.Subscriber == subscriber
+	}), nil
+}
+
+// GetWatchesByDoc gets all watches of a doc from memory
+func (s *MemoryStorage) GetWatchesByDoc(docID string) ([]*models.Watch, error) {
+	return s.filterWatches(func(watch *models.Watch) bool {
+		return watch.DocID == docID
+	}), nil
+}
+
+// SaveNotification saves an in-app notification to memory
+func (s *MemoryStorage) SaveNotification(notification *models.Notification) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if notification.ID == "" {
+		return errors.New("notification ID cannot be empty")
+	}
+
+	s.notifications[notification.ID] = notification
+	return nil
+}
+
+// GetNotifications gets all notifications of a subscriber from memory, newest first
+func (s *MemoryStorage) GetNotifications(subscriber string) ([]*models.Notification, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	notifications := []*models.Notification{}
+	for _, notification := range s.notifications {
+		if notification.Subscriber == subscriber {
+			notifications = append(notifications, notification)
+		}
+	}
+
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+	})
+
+	return notifications, nil
+}
+
+// filterWatches returns the watches matching the predicate, oldest first
+func (s *MemoryStorage) filterWatches(match func(*models.Watch) bool) []*models.Watch {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	watches := []*models.Watch{}
+	for _, watch := range s.watches {
+		if match(watch) {
+			watches = append(watches, watch)
+		}
+	}
+
+	sort.Slice(watches, func(i, j int) bool {
+		return watches[i].CreatedAt.Before(watches[j].CreatedAt)
+	})
+
+	return watches
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"universal_api/internal/models"
+)
+
+// TestWatches tests saving, finding, and deleting watches
+func TestWatches(t *testing.T) {
+	store := NewMemoryStorage()
+	created := time.Now()
+	watches := []*models.Watch{
+		{ID: "watch-2", DocID: "pets", Subscriber: "user:ada", CreatedAt: created.Add(time.Minute)},
+		{ID: "watch-1", DocID: "stores", Subscriber: "user:ada", CreatedAt: created},
+		{ID: "watch-3", DocID: "pets", Subscriber: "user:bob", CreatedAt: created},
+	}
+	for _, watch := range watches {
+		if err := store.SaveWatch(watch); err != nil {
+			t.Fatalf("Failed to save watch: %v", err)
+		}
+	}
+	if err := store.SaveWatch(&models.Watch{DocID: "pets"}); err == nil {
+		t.Errorf("Expected a watch without an ID to be an error")
+	}
+
+	found, err := store.GetWatchesBySubscriber("user:ada")
+	if err != nil || len(found) != 2 || found[0].ID != "watch-1" || found[1].ID != "watch-2" {
+		t.Errorf("Expected ada's watches oldest first, got %v, %v", found, err)
+	}
+	if found, _ := store.GetWatchesBySubscriber("user:eve"); found == nil || len(found) != 0 {
+		t.Errorf("Expected no watches of an unknown subscriber, got %v", found)
+	}
+	found, err = store.GetWatchesByDoc("pets")
+	if err != nil || len(found) != 2 || found[0].ID != "watch-3" {
+		t.Errorf("Expected the watches of pets oldest first, got %v, %v", found, err)
+	}
+
+	if err := store.DeleteWatch("watch-2"); err != nil {
+		t.Fatalf("Failed to delete watch: %v", err)
+	}
+	if err := store.DeleteWatch("watch-2"); err == nil {
+		t.Errorf("Expected deleting a deleted watch to be an error")
+	}
+	if found, _ := store.GetWatchesByDoc("pets"); len(found) != 1 || found[0].ID != "watch-3" {
+		t.Errorf("Expected the deleted watch to be gone, got %v", found)
+	}
+}
+
+// TestNotifications tests listing a subscriber's notifications newest first
+func TestNotifications(t *testing.T) {
+	store := NewMemoryStorage()
+	created := time.Now()
+	notifications := []*models.Notification{
+		{ID: "n-1", Subscriber: "user:ada", Subject: "First", CreatedAt: created},
+		{ID: "n-2", Subscriber: "user:ada", Subject: "Second", CreatedAt: created.Add(time.Minute)},
+		{ID: "n-3", Subscriber: "user:bob", Subject: "Other", CreatedAt: created},
+	}
+	for _, notification := range notifications {
+		if err := store.SaveNotification(notification); err != nil {
+			t.Fatalf("Failed to save notification: %v", err)
+		}
+	}
+	if err := store.SaveNotification(&models.Notification{Subscriber: "user:ada"}); err == nil {
+		t.Errorf("Expected a notification without an ID to be an error")
+	}
+
+	found, err := store.GetNotifications("user:ada")
+	if err != nil || len(found) != 2 || found[0].ID != "n-2" || found[1].ID != "n-1" {
+		t.Errorf("Expected ada's notifications newest first, got %v, %v", found, err)
+	}
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"universal_api/internal/models"
+)
+
+// workspaceIDPattern matches valid workspace IDs
+var workspaceIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// workspaceDocIDSeparator separates the workspace of a doc from the rest of its ID
+const workspaceDocIDSeparator = "~"
+
+// WorkspaceRegistry interface for storing workspaces
+type WorkspaceRegistry interface {
+	SaveWorkspace(workspace *models.Workspace) error
+	GetWorkspace(id string) (*models.Workspace, error)
+	GetAllWorkspaces() ([]*models.Workspace, error)
+}
+
+// ValidWorkspaceID checks if an ID can name a workspace: lowercase letters, digits, and dashes
+func ValidWorkspaceID(id string) bool {
+	return workspaceIDPattern.MatchString(id)
+}
+
+// DocWorkspace returns the workspace of a doc; docs saved without one belong to the default workspace
+func DocWorkspace(doc *models.APIDoc) string {
+	if doc.Workspace == "" {
+		return models.DefaultWorkspace
+	}
+	return doc.Workspace
+}
+
+// WorkspaceDocID returns the ID of a doc in a workspace. Doc IDs are shared by all workspaces, while
+// importers derive them from their source, so the docs of workspaces other than the default one are
+// prefixed with their workspace, e.g. team-a~directory-pets. Two workspaces importing the same source
+// then get docs of their own. IDs already prefixed with the workspace are kept.
+func WorkspaceDocID(workspace, id string) string {
+	if workspace == "" || workspace == models.DefaultWorkspace || strings.HasPrefix(id, workspace+workspaceDocIDSeparator) {
+		return id
+	}
+	return workspace + workspaceDocIDSeparator + id
+}
+
+// SaveWorkspace saves a workspace to memory
+func (s *MemoryStorage) SaveWorkspace(workspace *models.Workspace) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !ValidWorkspaceID(workspace.ID) {
+		return errors.New("workspace ID must be lowercase letters, digits, and dashes")
+	}
+
+	s.workspaces[workspace.ID] = workspace
+	return nil
+}
+
+// GetWorkspace gets a workspace from memory
+func (s *MemoryStorage) GetWorkspace(id string) (*models.Workspace, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	workspace, ok := s.workspaces[id]
+	if !ok {
+		return nil, errors.New("workspace not found")
+	}
+	return workspace, nil
+}
+
+// GetAllWorkspaces gets all workspaces from memory, ordered by ID
+func (s *MemoryStorage) GetAllWorkspaces() ([]*models.Workspace, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	workspaces := make([]*models.Workspace, 0, len(s.workspaces))
+	for _, workspace := range s.workspaces {
+		workspaces = append(workspaces, workspace)
+	}
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].ID < workspaces[j].ID
+	})
+	return workspaces, nil
+}
+
+// defaultWorkspace returns the workspace every storage starts with
+func defaultWorkspace() *models.Workspace {
+	return &models.Workspace{ID: models.DefaultWorkspace, Name: "Default", CreatedAt: time.Now()}
+}
+
+// WorkspaceStorage restricts a Storage to the docs of a single workspace. Docs of other
+// workspaces are reported as not found, and saved docs are assigned to the workspace, under IDs
+// prefixed with it by WorkspaceDocID. Importers looking up the docs they saved may leave the
+// prefix out.
+type WorkspaceStorage struct {
+	store     Storage
+	workspace string
+}
+
+// NewWorkspaceStorage creates a WorkspaceStorage for the workspace
+func NewWorkspaceStorage(store Storage, workspace string) *WorkspaceStorage {
+	return &WorkspaceStorage{store: store, workspace: workspace}
+}
+
+// Workspace returns the ID of the workspace the storage is restricted to
+func (s *WorkspaceStorage) Workspace() string {
+	return s.workspace
+}
+
+// SaveAPIDoc saves the doc to the workspace, prefixing its ID with the workspace. As only the docs of
+// the workspace have IDs with its prefix, and docs of the default workspace have IDs without any, no
+// other workspace's doc can be overwritten.
+func (s *WorkspaceStorage) SaveAPIDoc(doc *models.APIDoc) error {
+	if s.workspace == models.DefaultWorkspace {
+		doc.ID = strings.ReplaceAll(doc.ID, workspaceDocIDSeparator, "-")
+	} else {
+		doc.ID = WorkspaceDocID(s.workspace, doc.ID)
+	}
+
+	doc.Workspace = s.workspace
+	return s.store.SaveAPIDoc(doc)
+}
+
+// GetAPIDoc gets an API doc of the workspace
+func (s *WorkspaceStorage) GetAPIDoc(id string) (*models.APIDoc, error) {
+	doc, err := s.store.GetAPIDoc(WorkspaceDocID(s.workspace, id))
+	if err != nil {
+		return nil, err
+	}
+	if DocWorkspace(doc) != s.workspace {
+		return nil, errors.New("API doc not found")
+	}
+	return doc, nil
+}
+
+// GetAllAPIDocs gets all API docs of the workspace
+func (s *WorkspaceStorage) GetAllAPIDocs() ([]*models.APIDoc, error) {
+	docs, err := s.store.GetAllAPIDocs()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*models.APIDoc, 0, len(docs))
+	for _, doc := range docs {
+		if DocWorkspace(doc) == s.workspace {
+			filtered = append(filtered, doc)
+		}
+	}
+	return filtered, nil
+}
+
+// GetAllAPIDocsPartial gets all API docs of the workspace with only the named parts loaded
+func (s *WorkspaceStorage) GetAllAPIDocsPartial(parts ...string) ([]*models.APIDoc, error) {
+	docs, err := s.store.GetAllAPIDocsPartial(parts...)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*models.APIDoc, 0, len(docs))
+	for _, doc := range docs {
+		if DocWorkspace(doc) == s.workspace {
+			filtered = append(filtered, doc)
+		}
+	}
+	return filtered, nil
+}
+
+// DeleteAPIDoc deletes an API doc of the workspace
+func (s *WorkspaceStorage) DeleteAPIDoc(id string) error {
+	doc, err := s.GetAPIDoc(id)
+	if err != nil {
+		return err
+	}
+	return s.store.DeleteAPIDoc(doc.ID)
+}
+
+// GetAPIDocVersions gets the versions of an API doc of the workspace
+func (s *WorkspaceStorage) GetAPIDocVersions(id string) ([]*models.APIDocVersion, error) {
+	doc, err := s.GetAPIDoc(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.GetAPIDocVersions(doc.ID)
+}
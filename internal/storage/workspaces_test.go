@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestWorkspaceDocIDs tests that workspaces saving docs of the same ID get docs of their own
+func TestWorkspaceDocIDs(t *testing.T) {
+	store := NewMemoryStorage()
+	defaultDocs := NewWorkspaceStorage(store, models.DefaultWorkspace)
+	teamA := NewWorkspaceStorage(store, "team-a")
+	teamB := NewWorkspaceStorage(store, "team-b")
+
+	for _, docs := range []*WorkspaceStorage{defaultDocs, teamA, teamB} {
+		doc := &models.APIDoc{ID: "directory-pets", Title: "Pets of " + docs.Workspace()}
+		if err := docs.SaveAPIDoc(doc); err != nil {
+			t.Fatalf("Failed to save doc to %s: %v", docs.Workspace(), err)
+		}
+		if expected := WorkspaceDocID(docs.Workspace(), "directory-pets"); doc.ID != expected {
+			t.Errorf("Expected the doc of %s to be saved as %s, got %s", docs.Workspace(), expected, doc.ID)
+		}
+	}
+
+	if ids := docIDs(t, store); len(ids) != 3 || !ids["directory-pets"] || !ids["team-a~directory-pets"] || !ids["team-b~directory-pets"] {
+		t.Errorf("Expected a doc per workspace, got %v", ids)
+	}
+
+	// A workspace's docs are read by their ID with or without the workspace prefix
+	for _, id := range []string{"directory-pets", "team-a~directory-pets"} {
+		doc, err := teamA.GetAPIDoc(id)
+		if err != nil || doc.Title != "Pets of team-a" {
+			t.Errorf("Expected %s to get the doc of team-a, got %v, %v", id, doc, err)
+		}
+	}
+
+	// IDs prefixed with another workspace can't reach its docs, for reads or writes
+	if _, err := defaultDocs.GetAPIDoc("team-a~directory-pets"); err == nil {
+		t.Errorf("Expected the default workspace not to get the doc of team-a")
+	}
+	if _, err := teamB.GetAPIDoc("team-a~directory-pets"); err == nil {
+		t.Errorf("Expected team-b not to get the doc of team-a")
+	}
+	impostor := &models.APIDoc{ID: "team-a~directory-pets", Title: "Impostor"}
+	if err := defaultDocs.SaveAPIDoc(impostor); err != nil {
+		t.Fatalf("Failed to save doc: %v", err)
+	}
+	if doc, _ := teamA.GetAPIDoc("directory-pets"); doc.Title != "Pets of team-a" {
+		t.Errorf("Expected the doc of team-a not to be overwritten, got %q", doc.Title)
+	}
+	if impostor.ID != "team-a-directory-pets" {
+		t.Errorf("Expected the prefix to be dropped from the default workspace's ID, got %s", impostor.ID)
+	}
+}
+
+// TestWorkspaceIsolation tests that listing, deleting, and version histories only reach the workspace's docs
+func TestWorkspaceIsolation(t *testing.T) {
+	store := NewMemoryStorage()
+	teamA := NewWorkspaceStorage(store, "team-a")
+	teamB := NewWorkspaceStorage(store, "team-b")
+
+	teamA.SaveAPIDoc(&models.APIDoc{ID: "orders", Endpoints: []models.Endpoint{{Method: "GET", Path: "/orders"}}})
+	teamA.SaveAPIDoc(&models.APIDoc{ID: "orders", Endpoints: []models.Endpoint{{Method: "GET", Path: "/orders/{id}"}}})
+	teamB.SaveAPIDoc(&models.APIDoc{ID: "orders", Endpoints: []models.Endpoint{{Method: "GET", Path: "/carts"}}})
+	teamB.SaveAPIDoc(&models.APIDoc{ID: "users"})
+
+	for docs, expected := range map[*WorkspaceStorage]int{teamA: 1, teamB: 2} {
+		all, err := docs.GetAllAPIDocs()
+		if err != nil {
+			t.Fatalf("Failed to list docs: %v", err)
+		}
+		partial, err := docs.GetAllAPIDocsPartial()
+		if err != nil {
+			t.Fatalf("Failed to list partial docs: %v", err)
+		}
+		if len(all) != expected || len(partial) != expected {
+			t.Errorf("Expected %d docs in %s, got %d and %d partial", expected, docs.Workspace(), len(all), len(partial))
+		}
+		for _, doc := range append(all, partial...) {
+			if doc.Workspace != docs.Workspace() {
+				t.Errorf("Expected only docs of %s, got %s of %s", docs.Workspace(), doc.ID, doc.Workspace)
+			}
+		}
+	}
+
+	versions, err := teamA.GetAPIDocVersions("orders")
+	if err != nil || len(versions) != 2 {
+		t.Fatalf("Expected 2 versions of the doc of team-a, got %d, %v", len(versions), err)
+	}
+	if versions[0].Doc.Endpoints[0].Path != "/orders" {
+		t.Errorf("Expected the versions of team-a's doc, got %s", versions[0].Doc.Endpoints[0].Path)
+	}
+	if _, err := teamA.GetAPIDocVersions("team-b~users"); err == nil {
+		t.Errorf("Expected the versions of team-b's doc not to be reachable from team-a")
+	}
+
+	if err := teamA.DeleteAPIDoc("team-b~users"); err == nil {
+		t.Errorf("Expected team-a not to delete the doc of team-b")
+	}
+	if err := teamB.DeleteAPIDoc("orders"); err != nil {
+		t.Fatalf("Failed to delete doc: %v", err)
+	}
+	if _, err := teamA.GetAPIDoc("orders"); err != nil {
+		t.Errorf("Expected the doc of team-a to survive team-b deleting its doc of the same ID: %v", err)
+	}
+	if _, err := teamB.GetAPIDoc("users"); err != nil {
+		t.Errorf("Expected the other doc of team-b to survive: %v", err)
+	}
+}
+
+// docIDs returns the IDs of every doc of a storage
+func docIDs(t *testing.T, store Storage) map[string]bool {
+	t.Helper()
+	docs, err := store.GetAllAPIDocs()
+	if err != nil {
+		t.Fatalf("Failed to list docs: %v", err)
+	}
+	ids := make(map[string]bool)
+	for _, doc := range docs {
+		ids[doc.ID] = true
+	}
+	return ids
+}
@@ -1,60 +1,219 @@
 package ui
 
 import (
+	"bytes"
 	"html/template"
 	"net/http"
-	"path/filepath"
 	"strings"
-	"universal_api/internal/scraper"
+
+	"universal_api/internal/analysis"
+	"universal_api/internal/auth"
+	"universal_api/internal/diff"
+	"universal_api/internal/export"
+	"universal_api/internal/i18n"
+	"universal_api/internal/markdown"
+	"universal_api/internal/review"
+	"universal_api/internal/schema"
+	"universal_api/internal/search"
+	"universal_api/internal/snippet"
+	"universal_api/internal/stats"
 	"universal_api/internal/storage"
+	"universal_api/pkg/scraper"
+
+	"github.com/gin-gonic/gin"
 )
 
 // Handler handles UI requests
 type Handler struct {
-	templates *template.Template
-	store     storage.Storage
-	limiter   *RateLimiter
+	store        storage.Storage
+	index        *search.Index
+	schemas      storage.SchemaStorage
+	attachments  storage.AttachmentStorage
+	scrapes      storage.ScrapeStorage
+	views        storage.ViewStorage
+	sourceChecks storage.SourceCheckStorage
+	rawSources   storage.RawSourceStorage
+	workspaces   storage.WorkspaceRegistry
+	users        storage.UserStorage
+	oidc         *auth.OIDCProvider
+	branding     Branding
+	themeDir     string // overrides of the templates and static files, if any
 }
 
 // NewHandler creates a new UI handler
-func NewHandler(store storage.Storage) *Handler {
-	// Parse templates
-	templates := template.New("").Funcs(template.FuncMap{
-		"lower": strings.ToLower,
-	})
+func NewHandler(store storage.Storage, index *search.Index, schemas storage.SchemaStorage, attachments storage.AttachmentStorage, scrapes storage.ScrapeStorage, views storage.ViewStorage, sourceChecks storage.SourceCheckStorage, rawSources storage.RawSourceStorage, workspaces storage.WorkspaceRegistry, users storage.UserStorage, oidc *auth.OIDCProvider) *Handler {
+	return &Handler{
+		store:        store,
+		index:        index,
+		schemas:      schemas,
+		attachments:  attachments,
+		scrapes:      scrapes,
+		views:        views,
+		sourceChecks: sourceChecks,
+		rawSources:   rawSources,
+		workspaces:   workspaces,
+		users:        users,
+		oidc:         oidc,
+		branding:     DefaultBranding,
+	}
+}
 
-	templatePath := filepath.Join("internal", "ui", "templates")
-	templates, err := templates.ParseGlob(filepath.Join(templatePath, "*.html"))
+// RegisterRoutes registers UI routes with Gin
+func (h *Handler) RegisterRoutes(r *gin.Engine) {
+	// Serve static files, the theme's first
+	r.StaticFS("/static", h.staticFiles())
+
+	// Load HTML templates, the theme's replacing built-in ones
+	templates, err := h.loadTemplates(template.FuncMap{
+		"lower":     strings.ToLower,
+		"hasPrefix": strings.HasPrefix,
+		"humanize":  humanize,
+		"percent":   percent,
+		"fileSize":  fileSize,
+		"markdown":  markdown.Render,
+		"brand":     func() Branding { return h.branding },
+	})
 	if err != nil {
 		panic(err)
 	}
+	r.SetHTMLTemplate(templates)
 
-	return &Handler{
-		templates: templates,
-		store:     store,
-		limiter:   NewRateLimiter(1, 5), // 1 request per domain every 5 seconds
+	// UI routes
+	r.GET("/", h.authorize(auth.PermissionRead), h.handleIndex)
+	r.GET("/docs", h.authorize(auth.PermissionRead), h.handleDocsList)
+	r.GET("/docs/:id", h.authorize(auth.PermissionRead), h.handleDocDetail)
+	r.GET("/docs/:id/graph", h.authorize(auth.PermissionRead), h.handleDocGraph)
+	r.GET("/docs/:id/print", h.authorize(auth.PermissionRead), h.handleDocPrint)
+	r.GET("/docs/:id/pdf", h.authorize(auth.PermissionRead), h.handleDocPDF)
+	r.GET("/docs/:id/endpoints/:endpoint", h.authorize(auth.PermissionRead), h.handleEndpoint)
+	r.GET("/docs/:id/endpoints/:endpoint/snippet", h.authorize(auth.PermissionRead), h.handleSnippet)
+	r.GET("/docs/:id/attachments/:attachment", h.authorize(auth.PermissionRead), h.handleAttachment)
+	r.GET("/docs/:id/source", h.authorize(auth.PermissionRead), h.handleDocSource)
+	r.POST("/docs/:id/review", h.authorize(auth.PermissionRead), h.handleReview)
+	r.POST("/docs/:id/token", h.authorize(auth.PermissionWrite), h.handleToken)
+	r.GET("/search", h.authorize(auth.PermissionRead), h.handleSearch)
+	r.GET("/suggest", h.authorize(auth.PermissionRead), h.handleSuggest)
+	r.GET("/schemas", h.authorize(auth.PermissionRead), h.handleSchemas)
+	r.GET("/schemas/:id", h.authorize(auth.PermissionRead), h.handleSchemaDetail)
+	r.GET("/stats", h.authorize(auth.PermissionRead), h.handleStats)
+	r.GET("/scrapes", h.authorize(auth.PermissionRead), h.handleScrapeHistory)
+	r.POST("/scrape", h.authorize(auth.PermissionWrite), h.handleScrape)
+	r.GET("/admin", h.authorize(auth.PermissionAdmin), h.handleAdmin)
+	r.GET("/workspaces/:id", h.handleSwitchWorkspace)
+	r.GET("/login", h.handleLogin)
+	r.POST("/login", h.handleLogin)
+	r.POST("/logout", h.handleLogout)
+	r.POST("/theme", h.handleTheme)
+	r.GET("/login/oidc", h.handleOIDCLogin)
+	r.GET("/login/oidc/callback", h.handleOIDCCallback)
+}
+
+// authorize returns middleware requiring a permission in the browsed workspace
+func (h *Handler) authorize(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h.checkAccess(c, permission, requestWorkspace(c.Request, h.workspaces))
 	}
 }
 
-// RegisterRoutes registers UI routes
-func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("/", h.handleIndex)
-	mux.HandleFunc("/docs", h.handleDocsList)
-	mux.HandleFunc("/docs/", h.handleDocDetail)
-	mux.HandleFunc("/scrape", h.handleScrape)
+// checkAccess aborts UI requests that may not use a permission in a workspace
+func (h *Handler) checkAccess(c *gin.Context, permission, workspace string) {
+	switch authorizeRequest(c.Request, h.users, h.oidc, permission, workspace) {
+	case http.StatusUnauthorized:
+		// HTMX would swap the sign in page into the fragment, so have it load the page instead
+		if isPartial(c.Request) {
+			c.Header("HX-Redirect", "/login")
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Redirect(http.StatusSeeOther, "/login")
+		c.Abort()
+	case http.StatusForbidden:
+		h.renderPage(c, http.StatusForbidden, "error.tmpl", gin.H{
+			"Title": "Error",
+			"Error": "Your role doesn't allow this in workspace " + workspace,
+		})
+		c.Abort()
+	}
 }
 
-// handleIndex handles the index page
-func (h *Handler) handleIndex(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/" {
-		http.NotFound(w, r)
+// handleLogin shows the sign in page and signs in with an API key
+func (h *Handler) handleLogin(c *gin.Context) {
+	if c.Request.Method == http.MethodPost {
+		if _, err := auth.Authenticate(h.users, c.PostForm("key")); err != nil {
+			h.renderPage(c, http.StatusUnauthorized, "login.tmpl", gin.H{"Title": "Sign In", "Error": "Invalid API key", "SSO": h.oidc != nil})
+			return
+		}
+
+		http.SetCookie(c.Writer, apiKeyCookie(c.Request, c.PostForm("key")))
+		c.Redirect(http.StatusSeeOther, "/")
 		return
 	}
 
+	h.renderPage(c, http.StatusOK, "login.tmpl", gin.H{
+		"Title": "Sign In",
+		"User":  requestUser(c.Request, h.users, h.oidc),
+		"SSO":   h.oidc != nil,
+	})
+}
+
+// handleOIDCLogin sends the browser to the OIDC provider to sign in
+func (h *Handler) handleOIDCLogin(c *gin.Context) {
+	if h.oidc == nil {
+		h.renderError(c, "Single sign-on isn't configured")
+		return
+	}
+
+	if err := startOIDCLogin(c.Writer, c.Request, h.oidc); err != nil {
+		h.renderError(c, "Failed to start sign in: "+err.Error())
+	}
+}
+
+// handleOIDCCallback signs in with the ID token issued by the OIDC provider
+func (h *Handler) handleOIDCCallback(c *gin.Context) {
+	if h.oidc == nil {
+		h.renderError(c, "Single sign-on isn't configured")
+		return
+	}
+
+	if err := finishOIDCLogin(c.Writer, c.Request, h.users, h.oidc); err != nil {
+		h.renderPage(c, http.StatusUnauthorized, "login.tmpl", gin.H{"Title": "Sign In", "Error": "Sign in failed: " + err.Error(), "SSO": true})
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/")
+}
+
+// handleLogout signs out by clearing the API key cookie
+func (h *Handler) handleLogout(c *gin.Context) {
+	http.SetCookie(c.Writer, apiKeyCookie(c.Request, ""))
+	http.SetCookie(c.Writer, sessionCookie(c.Request, idTokenCookieName, "", 0))
+	c.Redirect(http.StatusSeeOther, "/login")
+}
+
+// docs returns the storage restricted to the request's workspace and the docs the user can see
+func (h *Handler) docs(c *gin.Context) storage.Storage {
+	return review.NewVisibleStorage(storage.NewWorkspaceStorage(h.store, requestWorkspace(c.Request, h.workspaces)), requestUser(c.Request, h.users, h.oidc))
+}
+
+// handleSwitchWorkspace remembers the workspace to browse in a cookie
+func (h *Handler) handleSwitchWorkspace(c *gin.Context) {
+	if _, err := h.workspaces.GetWorkspace(c.Param("id")); err != nil {
+		h.renderError(c, "Workspace not found: "+c.Param("id"))
+		return
+	}
+	if h.checkAccess(c, auth.PermissionRead, c.Param("id")); c.IsAborted() {
+		return
+	}
+
+	http.SetCookie(c.Writer, workspaceCookie(c.Param("id")))
+	c.Redirect(http.StatusSeeOther, "/docs")
+}
+
+// handleIndex handles the index page
+func (h *Handler) handleIndex(c *gin.Context) {
 	// Get the most recent API docs (up to 5)
-	docs, err := h.store.GetAllAPIDocs()
+	docs, err := h.docs(c).GetAllAPIDocs()
 	if err != nil {
-		h.renderError(w, "Failed to get API docs: "+err.Error())
+		h.renderError(c, "Failed to get API docs: "+err.Error())
 		return
 	}
 
@@ -64,103 +223,435 @@ func (h *Handler) handleIndex(w http.ResponseWriter, r *http.Request) {
 		recentDocs = docs[len(docs)-5:]
 	}
 
-	data := map[string]interface{}{
-		"Title":   "Home",
-		"APIDocs": recentDocs,
-	}
-
-	h.renderTemplate(w, "index", data)
+	h.renderPage(c, http.StatusOK, "index.tmpl", gin.H{
+		"Title":       "Home",
+		"APIDocs":     recentDocs,
+		"PopularAPIs": popularDocs(docs, h.views),
+		"Formats":     scraper.Formats,
+	})
 }
 
 // handleDocsList handles the docs list page
-func (h *Handler) handleDocsList(w http.ResponseWriter, r *http.Request) {
-	docs, err := h.store.GetAllAPIDocs()
+func (h *Handler) handleDocsList(c *gin.Context) {
+	docs, err := h.docs(c).GetAllAPIDocs()
 	if err != nil {
-		h.renderError(w, "Failed to get API docs: "+err.Error())
+		h.renderError(c, "Failed to get API docs: "+err.Error())
 		return
 	}
 
-	data := map[string]interface{}{
-		"Title":   "API Documentation",
-		"APIDocs": docs,
+	languages := i18n.Languages(docs)
+	language := c.Query("language")
+	if language != "" {
+		docs = i18n.FilterByLanguage(docs, language)
+	}
+	freeTier := c.Query("free_tier") == "true"
+	if freeTier {
+		docs = withFreeTier(docs)
+	}
+	stale := staleDocs(h.sourceChecks)
+	staleOnly := c.Query("stale") == "true"
+	if staleOnly {
+		docs = withStaleSource(docs, stale)
+	}
+	query := c.Query("q")
+	if query != "" {
+		docs = matchingDocs(docs, query)
 	}
 
-	h.renderTemplate(w, "docs_list", data)
+	data := gin.H{
+		"Title":     "API Documentation",
+		"APIDocs":   docs,
+		"Languages": languages,
+		"Language":  language,
+		"FreeTier":  freeTier,
+		"Icons":     docIcons(h.attachments, docs),
+		"Stale":     stale,
+		"StaleOnly": staleOnly,
+		"Query":     query,
+	}
+
+	// Searching as you type only replaces the results
+	c.Header("Vary", "HX-Request")
+	if isPartial(c.Request) {
+		c.HTML(http.StatusOK, "doc_results", data)
+		return
+	}
+	h.renderPage(c, http.StatusOK, "docs_list.tmpl", data)
+}
+
+// handleEndpoint handles expanding an endpoint in place: its details for HTMX, or else its place on the doc page
+func (h *Handler) handleEndpoint(c *gin.Context) {
+	id, endpointID := c.Param("id"), c.Param("endpoint")
+	if !isPartial(c.Request) {
+		c.Redirect(http.StatusSeeOther, "/docs/"+id+"#endpoint-"+endpointID)
+		return
+	}
+
+	doc, err := h.docs(c).GetAPIDoc(id)
+	if err != nil {
+		c.String(http.StatusNotFound, "API doc not found")
+		return
+	}
+	for _, endpoint := range doc.Endpoints {
+		if endpoint.StableID() == endpointID {
+			c.Header("Vary", "HX-Request")
+			c.HTML(http.StatusOK, "endpoint_details", endpoint)
+			return
+		}
+	}
+	c.String(http.StatusNotFound, "Endpoint not found")
 }
 
 // handleDocDetail handles the doc detail page
-func (h *Handler) handleDocDetail(w http.ResponseWriter, r *http.Request) {
-	id := strings.TrimPrefix(r.URL.Path, "/docs/")
+func (h *Handler) handleDocDetail(c *gin.Context) {
+	id := c.Param("id")
 	if id == "" {
-		http.Redirect(w, r, "/docs", http.StatusSeeOther)
+		c.Redirect(http.StatusSeeOther, "/docs")
 		return
 	}
 
-	doc, err := h.store.GetAPIDoc(id)
+	doc, err := h.docs(c).GetAPIDoc(id)
 	if err != nil {
-		h.renderError(w, "API doc not found: "+err.Error())
+		h.renderError(c, "API doc not found: "+err.Error())
 		return
 	}
+	recordPageView(h.views, c.Request, id)
+
+	// A missing history shouldn't prevent showing the doc itself
+	var changelog []diff.ChangelogEntry
+	if versions, err := h.docs(c).GetAPIDocVersions(id); err == nil {
+		changelog = diff.Changelog(versions)
+	}
+
+	// Only a gone source is worth a warning; other failures may be temporary
+	sourceCheck, err := h.sourceChecks.GetSourceCheck(id)
+	if err != nil || !sourceCheck.Stale {
+		sourceCheck = nil
+	}
+
+	// Docs scraped before sources were archived have none to link to
+	rawSource, err := h.rawSources.GetRawSource(id, 0)
+	if err != nil {
+		rawSource = nil
+	}
 
-	data := map[string]interface{}{
+	attachments, logo := docAttachments(h.attachments, id)
+	h.renderPage(c, http.StatusOK, "doc_detail.tmpl", gin.H{
+		"Title":             doc.Title,
+		"APIDoc":            doc,
+		"StaleSource":       sourceCheck,
+		"RawSource":         rawSource,
+		"Changelog":         changelog,
+		"RelationshipGraph": relationshipGraph(doc),
+		"Attachments":       attachments,
+		"Logo":              logo,
+		"CanReview":         canReview(requestUser(c.Request, h.users, h.oidc)),
+		"Workspace":         requestWorkspace(c.Request, h.workspaces),
+	})
+}
+
+// handleDocPrint handles the print view of a doc, with every endpoint expanded and no navigation
+func (h *Handler) handleDocPrint(c *gin.Context) {
+	doc, err := h.docs(c).GetAPIDoc(c.Param("id"))
+	if err != nil {
+		h.renderError(c, "API doc not found: "+err.Error())
+		return
+	}
+
+	h.renderPage(c, http.StatusOK, "doc_print.tmpl", gin.H{
 		"Title":  doc.Title,
 		"APIDoc": doc,
+	})
+}
+
+// handleDocPDF handles the PDF download of a doc
+func (h *Handler) handleDocPDF(c *gin.Context) {
+	doc, err := h.docs(c).GetAPIDoc(c.Param("id"))
+	if err != nil {
+		h.renderError(c, "API doc not found: "+err.Error())
+		return
 	}
 
-	h.renderTemplate(w, "doc_detail", data)
+	var buf bytes.Buffer
+	if err := export.WritePDF(&buf, doc); err != nil {
+		h.renderError(c, "Failed to export PDF: "+err.Error())
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+export.PDFName(doc)+`"`)
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
 }
 
-// handleScrape handles the scrape action
-func (h *Handler) handleScrape(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// handleSnippet handles the "Copy as" menu of an endpoint, answering with the snippet in a format
+func (h *Handler) handleSnippet(c *gin.Context) {
+	doc, err := h.docs(c).GetAPIDoc(c.Param("id"))
+	if err != nil {
+		c.String(http.StatusNotFound, "API doc not found")
+		return
+	}
+	for _, endpoint := range doc.Endpoints {
+		if endpoint.StableID() == c.Param("endpoint") {
+			text, err := snippet.Generate(doc, endpoint, c.DefaultQuery("format", snippet.FormatCurl))
+			if err != nil {
+				c.String(http.StatusBadRequest, err.Error())
+				return
+			}
+			c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(text))
+			return
+		}
+	}
+	c.String(http.StatusNotFound, "Endpoint not found")
+}
+
+// handleReview handles the review form of the doc page, submitting, approving, or rejecting the doc
+func (h *Handler) handleReview(c *gin.Context) {
+	user := requestUser(c.Request, h.users, h.oidc)
+	if err := reviewDoc(h.docs(c), user, c.Param("id"), c.PostForm("action"), c.PostForm("comment")); err != nil {
+		h.renderError(c, "Failed to review API doc: "+err.Error())
+		return
+	}
+	c.Redirect(http.StatusSeeOther, "/docs/"+c.Param("id"))
+}
+
+// handleToken handles the token form of the doc page, getting an access token to the doc's API
+// with the OAuth2 client credentials flow
+func (h *Handler) handleToken(c *gin.Context) {
+	user := requestUser(c.Request, h.users, h.oidc)
+	doc, token, err := docToken(h.docs(c), user, c.Param("id"), c.PostForm("scheme"), c.PostForm("client_id"), c.PostForm("client_secret"), c.PostForm("scopes"))
+	if err != nil {
+		h.renderError(c, "Failed to get access token: "+err.Error())
+		return
+	}
+
+	h.renderPage(c, http.StatusOK, "token.tmpl", gin.H{
+		"Title":  doc.Title,
+		"APIDoc": doc,
+		"Token":  token,
+	})
+}
+
+// handleAttachment handles downloading an attachment of a doc
+func (h *Handler) handleAttachment(c *gin.Context) {
+	if _, err := h.docs(c).GetAPIDoc(c.Param("id")); err != nil {
+		h.renderError(c, "API doc not found: "+err.Error())
+		return
+	}
+
+	if err := writeAttachment(c.Writer, h.attachments, c.Param("id"), c.Param("attachment")); err != nil {
+		h.renderError(c, "Failed to get attachment: "+err.Error())
+	}
+}
+
+// handleDocSource serves the latest raw content a doc was scraped from
+func (h *Handler) handleDocSource(c *gin.Context) {
+	if _, err := h.docs(c).GetAPIDoc(c.Param("id")); err != nil {
+		h.renderError(c, "API doc not found: "+err.Error())
+		return
+	}
+
+	if err := writeRawSource(c.Writer, h.rawSources, c.Param("id")); err != nil {
+		h.renderError(c, "Failed to get raw source: "+err.Error())
+	}
+}
+
+// handleDocGraph handles the endpoint graph page of a doc
+func (h *Handler) handleDocGraph(c *gin.Context) {
+	doc, err := h.docs(c).GetAPIDoc(c.Param("id"))
+	if err != nil {
+		h.renderError(c, "API doc not found: "+err.Error())
+		return
+	}
+
+	h.renderPage(c, http.StatusOK, "graph.tmpl", gin.H{
+		"Title":  doc.Title + " Graph",
+		"APIDoc": doc,
+		"Graph":  analysis.DependencyGraph(doc),
+	})
+}
+
+// handleSearch handles the faceted search page
+func (h *Handler) handleSearch(c *gin.Context) {
+	query := search.ParseQuery(c.Request.URL.Query())
+	query.Workspace = requestWorkspace(c.Request, h.workspaces)
+
+	result, err := h.index.Search(query)
+	if err != nil {
+		h.renderError(c, "Failed to search: "+err.Error())
+		return
+	}
+
+	h.renderPage(c, http.StatusOK, "search.tmpl", gin.H{
+		"Title":           "Search",
+		"Query":           query.Text,
+		"Semantic":        query.Semantic,
+		"SemanticEnabled": h.index.SemanticEnabled(),
+		"Selected":        selectedFacets(query),
+		"Facets":          search.Facets,
+		"Result":          result,
+		"Icons":           hitIcons(h.attachments, result.Results),
+	})
+}
+
+// handleSuggest returns the docs and endpoints matching a partial query to the quick-open overlay
+func (h *Handler) handleSuggest(c *gin.Context) {
+	workspace := requestWorkspace(c.Request, h.workspaces)
+	c.JSON(http.StatusOK, h.index.Suggest(workspace, c.Query("q"), search.SuggestLimit))
+}
+
+// handleSchemas handles the schema browser page
+func (h *Handler) handleSchemas(c *gin.Context) {
+	schemas, err := workspaceSchemas(h.schemas, requestWorkspace(c.Request, h.workspaces))
+	if err != nil {
+		h.renderError(c, "Failed to get schemas: "+err.Error())
+		return
+	}
+
+	h.renderPage(c, http.StatusOK, "schemas.tmpl", gin.H{
+		"Title":   "Schemas",
+		"Query":   c.Query("q"),
+		"Field":   c.Query("field"),
+		"Schemas": schema.Search(schemas, c.Query("q"), c.Query("field")),
+	})
+}
+
+// handleSchemaDetail handles the schema detail page
+func (h *Handler) handleSchemaDetail(c *gin.Context) {
+	s, err := h.schemas.GetSchema(c.Param("id"))
+	if err != nil || !inWorkspace(s.Workspace, requestWorkspace(c.Request, h.workspaces)) {
+		h.renderError(c, "Schema not found")
+		return
+	}
+
+	h.renderPage(c, http.StatusOK, "schema_detail.tmpl", gin.H{
+		"Title":  s.Name,
+		"Schema": s,
+	})
+}
+
+// handleStats handles the catalog analytics page
+func (h *Handler) handleStats(c *gin.Context) {
+	docs, err := h.docs(c).GetAllAPIDocs()
+	if err != nil {
+		h.renderError(c, "Failed to get API docs: "+err.Error())
 		return
 	}
 
-	url := r.FormValue("url")
+	scrapes, err := workspaceScrapes(h.scrapes, requestWorkspace(c.Request, h.workspaces))
+	if err != nil {
+		h.renderError(c, "Failed to get scrape records: "+err.Error())
+		return
+	}
+
+	views, err := h.views.GetAllViewCounts()
+	if err != nil {
+		h.renderError(c, "Failed to get view counts: "+err.Error())
+		return
+	}
+
+	h.renderPage(c, http.StatusOK, "stats.tmpl", gin.H{
+		"Title": "Statistics",
+		"Stats": stats.Compute(docs, scrapes, views),
+	})
+}
+
+// handleScrapeHistory handles the scrape history page of a URL or domain
+func (h *Handler) handleScrapeHistory(c *gin.Context) {
+	var history *stats.ScrapeHistory
+	if c.Query("url") != "" || c.Query("domain") != "" {
+		scrapes, err := workspaceScrapes(h.scrapes, requestWorkspace(c.Request, h.workspaces))
+		if err != nil {
+			h.renderError(c, "Failed to get scrape records: "+err.Error())
+			return
+		}
+		history = stats.History(scrapes, c.Query("url"), c.Query("domain"))
+	}
+
+	h.renderPage(c, http.StatusOK, "scrapes.tmpl", gin.H{
+		"Title":   "Scrape History",
+		"URL":     c.Query("url"),
+		"Domain":  c.Query("domain"),
+		"History": history,
+	})
+}
+
+// handleAdmin handles the admin dashboard
+func (h *Handler) handleAdmin(c *gin.Context) {
+	h.renderPage(c, http.StatusOK, "admin.tmpl", gin.H{
+		"Title":    "Admin",
+		"Breakers": scraper.BreakerStates(),
+	})
+}
+
+// handleScrape handles the scrape action
+func (h *Handler) handleScrape(c *gin.Context) {
+	url := c.PostForm("url")
+
 	if url == "" {
-		h.renderError(w, "URL is required")
+		h.renderError(c, "URL is required")
 		return
 	}
 
-	// Check rate limit
-	if !h.limiter.Allow(url) {
-		h.renderError(w, "Rate limit exceeded for this domain. Please try again later.")
+	format, err := scraper.ParseFormat(c.PostForm("format"))
+	if err != nil {
+		h.renderScrapeError(c, err.Error())
 		return
 	}
 
 	// Scrape the API documentation
-	apiDoc, err := scraper.ScrapeAPIDoc(url)
+	apiDoc, err := scraper.ScrapeAPIDocWithOptions(url, scraper.Options{Cache: true, Format: format, Context: c.Request.Context()})
+	recordScrape(h.scrapes, requestWorkspace(c.Request, h.workspaces), url, apiDoc, err)
 	if err != nil {
-		h.renderError(w, "Failed to scrape API documentation: "+err.Error())
+		h.renderScrapeError(c, "Failed to scrape API documentation: "+err.Error())
 		return
 	}
+	apiDoc.Format = string(format)
+	source := apiDoc.RawSource
+	apiDoc.RawSource = nil
 
 	// Save the API doc
-	if err := h.store.SaveAPIDoc(apiDoc); err != nil {
-		h.renderError(w, "Failed to save API documentation: "+err.Error())
+	if err := h.docs(c).SaveAPIDoc(apiDoc); err != nil {
+		h.renderScrapeError(c, "Failed to save API documentation: "+err.Error())
+		return
+	}
+	archiveRawSource(h.rawSources, apiDoc.ID, source)
+
+	// Show the outcome in place of the form's status, or else redirect to the doc detail page
+	if isPartial(c.Request) {
+		c.HTML(http.StatusOK, "scrape_status", gin.H{"APIDoc": apiDoc})
 		return
 	}
+	c.Redirect(http.StatusSeeOther, "/docs/"+apiDoc.ID)
+}
 
-	// Redirect to the doc detail page
-	http.Redirect(w, r, "/docs/"+apiDoc.ID, http.StatusSeeOther)
+// renderScrapeError renders a failed scrape: in place of the form's status for HTMX, or else on an error page
+func (h *Handler) renderScrapeError(c *gin.Context, message string) {
+	if isPartial(c.Request) {
+		c.HTML(http.StatusOK, "scrape_status", gin.H{"Error": message})
+		return
+	}
+	h.renderError(c, message)
 }
 
-// renderTemplate renders a template with the given data
-func (h *Handler) renderTemplate(w http.ResponseWriter, name string, data interface{}) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	err := h.templates.ExecuteTemplate(w, name+".html", data)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+// renderPage renders a page with the request's color theme and path, which the layout needs
+func (h *Handler) renderPage(c *gin.Context, status int, name string, data gin.H) {
+	data["Theme"] = requestTheme(c.Request)
+	data["Path"] = c.Request.URL.Path
+	c.HTML(status, name, data)
+}
+
+// handleTheme remembers the color theme chosen with the header's toggle and goes back to the page
+func (h *Handler) handleTheme(c *gin.Context) {
+	theme := c.PostForm("theme")
+	if theme != themeLight && theme != themeDark {
+		theme = ""
 	}
+	http.SetCookie(c.Writer, themeCookie(theme))
+	c.Redirect(http.StatusSeeOther, localReferer(c.Request, "/"))
 }
 
 // renderError renders an error page
-func (h *Handler) renderError(w http.ResponseWriter, message string) {
-	data := map[string]interface{}{
+func (h *Handler) renderError(c *gin.Context, message string) {
+	h.renderPage(c, http.StatusOK, "error.tmpl", gin.H{
 		"Title": "Error",
 		"Error": message,
-	}
-
-	h.renderTemplate(w, "error", data)
+	})
 }
@@ -0,0 +1,390 @@
+package ui
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"universal_api/internal/auth"
+	"universal_api/internal/models"
+	"universal_api/internal/search"
+	"universal_api/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// uiTestServer serves the UI from memory storage, configuring the handler first with configure. Templates and
+// static files are loaded from the module's root, as the server does.
+func uiTestServer(t *testing.T, provider *auth.OIDCProvider, configure ...func(*Handler)) (*gin.Engine, *storage.MemoryStorage) {
+	t.Helper()
+	t.Chdir("../..")
+	gin.SetMode(gin.TestMode)
+
+	memoryStore := storage.NewMemoryStorage()
+	h := NewHandler(memoryStore, search.NewIndex(), memoryStore, memoryStore, memoryStore, memoryStore, memoryStore, memoryStore, memoryStore, memoryStore, provider)
+	for _, configure := range configure {
+		configure(h)
+	}
+	r := gin.New()
+	h.RegisterRoutes(r)
+	return r, memoryStore
+}
+
+// uiTestUser creates a user of a role, limited to workspaces if any, returning an API key of theirs
+func uiTestUser(t *testing.T, memoryStore *storage.MemoryStorage, id, role string, workspaces ...string) string {
+	t.Helper()
+	if err := memoryStore.SaveUser(&models.User{ID: id, Name: id, Role: role, Workspaces: workspaces}); err != nil {
+		t.Fatalf("Failed to save user: %v", err)
+	}
+	key, apiKey, err := auth.NewAPIKey(id, "test")
+	if err != nil {
+		t.Fatalf("Failed to create API key: %v", err)
+	}
+	if err := memoryStore.SaveAPIKey(apiKey); err != nil {
+		t.Fatalf("Failed to save API key: %v", err)
+	}
+	return key
+}
+
+// serveUI serves a UI request with headers and cookies, posting form values if any
+func serveUI(r *gin.Engine, method, path string, form url.Values, header http.Header, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// responseCookie returns the cookie of a name set by a response, or nil
+func responseCookie(recorder *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// TestUIAuthorization tests which pages signed out users and each role may see, in which workspaces
+func TestUIAuthorization(t *testing.T) {
+	r, memoryStore := uiTestServer(t, nil)
+	if err := memoryStore.SaveWorkspace(&models.Workspace{ID: "team-a", Name: "Team A"}); err != nil {
+		t.Fatalf("Failed to save workspace: %v", err)
+	}
+
+	// Every page is open until users exist
+	if recorder := serveUI(r, http.MethodGet, "/admin", nil, nil); recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200 before users exist, got %d", recorder.Code)
+	}
+
+	admin := uiTestUser(t, memoryStore, "admin-1", auth.RoleAdmin)
+	viewer := uiTestUser(t, memoryStore, "viewer-1", auth.RoleViewer)
+	teamViewer := uiTestUser(t, memoryStore, "viewer-2", auth.RoleViewer, "team-a")
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		header   http.Header
+		cookie   *http.Cookie
+		expected int
+	}{
+		{name: "signed out", method: http.MethodGet, path: "/docs", expected: http.StatusSeeOther},
+		{name: "signed out partial", method: http.MethodGet, path: "/docs", header: http.Header{"HX-Request": {"true"}}, expected: http.StatusUnauthorized},
+		{name: "invalid key", method: http.MethodGet, path: "/docs", header: http.Header{"X-API-Key": {"uak_invalid"}}, expected: http.StatusSeeOther},
+		{name: "key header", method: http.MethodGet, path: "/docs", header: http.Header{"X-API-Key": {viewer}}, expected: http.StatusOK},
+		{name: "key cookie", method: http.MethodGet, path: "/docs", cookie: &http.Cookie{Name: apiKeyCookieName, Value: viewer}, expected: http.StatusOK},
+		{name: "viewer admin page", method: http.MethodGet, path: "/admin", header: http.Header{"X-API-Key": {viewer}}, expected: http.StatusForbidden},
+		{name: "viewer scrape", method: http.MethodPost, path: "/scrape", header: http.Header{"X-API-Key": {viewer}}, expected: http.StatusForbidden},
+		{name: "viewer token", method: http.MethodPost, path: "/docs/pets/token", header: http.Header{"X-API-Key": {viewer}}, expected: http.StatusForbidden},
+		{name: "admin admin page", method: http.MethodGet, path: "/admin", header: http.Header{"X-API-Key": {admin}}, expected: http.StatusOK},
+		{name: "outside own workspace", method: http.MethodGet, path: "/docs", header: http.Header{"X-API-Key": {teamViewer}}, expected: http.StatusForbidden},
+		{name: "own workspace header", method: http.MethodGet, path: "/docs", header: http.Header{"X-API-Key": {teamViewer}, "X-Workspace": {"team-a"}}, expected: http.StatusOK},
+		{name: "own workspace cookie", method: http.MethodGet, path: "/docs", header: http.Header{"X-API-Key": {teamViewer}}, cookie: workspaceCookie("team-a"), expected: http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var cookies []*http.Cookie
+			if test.cookie != nil {
+				cookies = append(cookies, test.cookie)
+			}
+			recorder := serveUI(r, test.method, test.path, nil, test.header, cookies...)
+			if recorder.Code != test.expected {
+				t.Fatalf("Expected %d, got %d", test.expected, recorder.Code)
+			}
+			switch test.expected {
+			case http.StatusSeeOther:
+				if location := recorder.Header().Get("Location"); location != "/login" {
+					t.Errorf("Expected a redirect to /login, got %q", location)
+				}
+			case http.StatusUnauthorized:
+				if redirect := recorder.Header().Get("HX-Redirect"); redirect != "/login" {
+					t.Errorf("Expected HX-Redirect to /login, got %q", redirect)
+				}
+			}
+		})
+	}
+}
+
+// TestUISwitchWorkspace tests remembering the browsed workspace only when the user may read it
+func TestUISwitchWorkspace(t *testing.T) {
+	r, memoryStore := uiTestServer(t, nil)
+	for _, id := range []string{"team-a", "team-b"} {
+		if err := memoryStore.SaveWorkspace(&models.Workspace{ID: id, Name: id}); err != nil {
+			t.Fatalf("Failed to save workspace: %v", err)
+		}
+	}
+	uiTestUser(t, memoryStore, "admin-1", auth.RoleAdmin)
+	teamViewer := http.Header{"X-API-Key": {uiTestUser(t, memoryStore, "viewer-2", auth.RoleViewer, "team-a")}}
+
+	recorder := serveUI(r, http.MethodGet, "/workspaces/team-a", nil, teamViewer)
+	if recorder.Code != http.StatusSeeOther || recorder.Header().Get("Location") != "/docs" {
+		t.Fatalf("Expected a redirect to /docs, got %d to %q", recorder.Code, recorder.Header().Get("Location"))
+	}
+	if cookie := responseCookie(recorder, workspaceCookieName); cookie == nil || cookie.Value != "team-a" {
+		t.Errorf("Expected the workspace cookie to be team-a, got %v", cookie)
+	}
+
+	recorder = serveUI(r, http.MethodGet, "/workspaces/team-b", nil, teamViewer)
+	if recorder.Code != http.StatusForbidden || responseCookie(recorder, workspaceCookieName) != nil {
+		t.Errorf("Expected 403 without a cookie switching to another team's workspace, got %d", recorder.Code)
+	}
+	recorder = serveUI(r, http.MethodGet, "/workspaces/missing", nil, teamViewer)
+	if !strings.Contains(recorder.Body.String(), "Workspace not found") || responseCookie(recorder, workspaceCookieName) != nil {
+		t.Errorf("Expected an error without a cookie switching to an unknown workspace, got %d", recorder.Code)
+	}
+
+	// A cookie naming an unknown workspace browses the default one
+	req := httptest.NewRequest(http.MethodGet, "/docs", nil)
+	req.AddCookie(workspaceCookie("missing"))
+	if workspace := requestWorkspace(req, memoryStore); workspace != models.DefaultWorkspace {
+		t.Errorf("Expected the default workspace, got %q", workspace)
+	}
+}
+
+// TestUIReview tests the review form allowing each action only to the roles with its permission
+func TestUIReview(t *testing.T) {
+	r, memoryStore := uiTestServer(t, nil)
+	uiTestUser(t, memoryStore, "admin-1", auth.RoleAdmin)
+	editor := http.Header{"X-API-Key": {uiTestUser(t, memoryStore, "editor-1", auth.RoleEditor)}}
+	viewer := http.Header{"X-API-Key": {uiTestUser(t, memoryStore, "viewer-1", auth.RoleViewer)}}
+	reviewer := http.Header{"X-API-Key": {uiTestUser(t, memoryStore, "reviewer-1", auth.RoleReviewer)}}
+
+	// Drafts are only visible to their submitter, so the viewer's own draft checks their role
+	docs := []*models.APIDoc{
+		{ID: "pets", Title: "Pets", URL: "https://pets.example.com/openapi.json", Status: models.StatusDraft, SubmittedBy: "editor-1"},
+		{ID: "stores", Title: "Stores", URL: "https://stores.example.com/openapi.json", Status: models.StatusDraft, SubmittedBy: "viewer-1"},
+	}
+	for _, doc := range docs {
+		if err := memoryStore.SaveAPIDoc(doc); err != nil {
+			t.Fatalf("Failed to save doc: %v", err)
+		}
+	}
+
+	steps := []struct {
+		header   http.Header
+		id       string
+		action   string
+		expected string
+		err      string
+	}{
+		{header: viewer, id: "stores", action: "submit", expected: models.StatusDraft, err: "your role doesn&#39;t allow this"},
+		{header: editor, id: "pets", action: "approve", expected: models.StatusDraft, err: "your role doesn&#39;t allow this"},
+		{header: editor, id: "pets", action: "submit", expected: models.StatusInReview},
+		{header: viewer, id: "pets", action: "approve", expected: models.StatusInReview, err: "your role doesn&#39;t allow this"},
+		{header: editor, id: "pets", action: "reject", expected: models.StatusInReview, err: "your role doesn&#39;t allow this"},
+		{header: reviewer, id: "pets", action: "publish", expected: models.StatusInReview, err: "unknown review action"},
+		{header: reviewer, id: "pets", action: "approve", expected: models.StatusPublished},
+	}
+	for i, step := range steps {
+		recorder := serveUI(r, http.MethodPost, "/docs/"+step.id+"/review", url.Values{"action": {step.action}}, step.header)
+		if step.err != "" && !strings.Contains(recorder.Body.String(), step.err) {
+			t.Errorf("Step %d: expected %s to fail with %q, got %d: %s", i, step.action, step.err, recorder.Code, recorder.Body.String())
+		}
+		if step.err == "" && recorder.Code != http.StatusSeeOther {
+			t.Errorf("Step %d: expected %s to redirect to the doc, got %d", i, step.action, recorder.Code)
+		}
+		saved, err := memoryStore.GetAPIDoc(step.id)
+		if err != nil || saved.Status != step.expected {
+			t.Fatalf("Step %d: expected %s to leave the doc %s, got %v, %v", i, step.action, step.expected, saved, err)
+		}
+	}
+	if saved, _ := memoryStore.GetAPIDoc("pets"); saved.Review == nil || saved.Review.Reviewer != "reviewer-1" {
+		t.Errorf("Expected the review to be recorded as reviewer-1's, got %+v", saved.Review)
+	}
+}
+
+// TestDocToken tests the token form's checks before any request to the doc's API
+func TestDocToken(t *testing.T) {
+	memoryStore := storage.NewMemoryStorage()
+	if err := memoryStore.SaveAPIDoc(&models.APIDoc{ID: "pets", Title: "Pets", URL: "https://pets.example.com/openapi.json"}); err != nil {
+		t.Fatalf("Failed to save doc: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		user     *models.User
+		id       string
+		clientID string
+		expected string
+	}{
+		{name: "viewer", user: &models.User{ID: "viewer-1", Role: auth.RoleViewer}, id: "pets", clientID: "client", expected: "your role doesn't allow this"},
+		{name: "no client ID", user: &models.User{ID: "editor-1", Role: auth.RoleEditor}, id: "pets", expected: "client ID and secret are required"},
+		{name: "unknown doc", id: "missing", clientID: "client", expected: "API doc not found"},
+		{name: "no OAuth2", user: &models.User{ID: "editor-1", Role: auth.RoleEditor}, id: "pets", clientID: "client", expected: "client credentials"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := docToken(memoryStore, test.user, test.id, "", test.clientID, "secret", "")
+			if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("Expected an error containing %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+// testOIDCProvider starts an OIDC provider whose token endpoint issues an ID token with the claims
+// returned by claims, signed by an RSA key it serves
+func testOIDCProvider(t *testing.T, claims func(issuer string) map[string]interface{}) *auth.OIDCProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	var issuer string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                 issuer,
+				"authorization_endpoint": issuer + "/authorize",
+				"token_endpoint":         issuer + "/token",
+				"jwks_uri":               issuer + "/keys",
+			})
+		case "/keys":
+			json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+				"kid": "k1",
+				"kty": "RSA",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}}})
+		case "/token":
+			header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k1", "typ": "JWT"})
+			payload, _ := json.Marshal(claims(issuer))
+			signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+			digest := sha256.Sum256([]byte(signed))
+			signature, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+			json.NewEncoder(w).Encode(map[string]string{"id_token": signed + "." + base64.RawURLEncoding.EncodeToString(signature)})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	issuer = server.URL
+
+	provider, err := auth.NewOIDCProvider(auth.OIDCConfig{Issuer: issuer, ClientID: "catalog", ClientSecret: "secret"})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	return provider
+}
+
+// TestOIDCCallback tests signing in through OIDC, which must return with the state and ID token nonce of its start
+func TestOIDCCallback(t *testing.T) {
+	var nonce string
+	provider := testOIDCProvider(t, func(issuer string) map[string]interface{} {
+		return map[string]interface{}{"iss": issuer, "aud": "catalog", "exp": float64(time.Now().Add(time.Hour).Unix()), "email": "alice@example.com", "nonce": nonce}
+	})
+	r, memoryStore := uiTestServer(t, provider)
+	uiTestUser(t, memoryStore, "admin-1", auth.RoleAdmin)
+	uiTestUser(t, memoryStore, "alice@example.com", auth.RoleViewer)
+
+	// start signs in, returning the state cookie and the state and nonce sent to the provider
+	start := func() (*http.Cookie, string, string) {
+		recorder := serveUI(r, http.MethodGet, "/login/oidc", nil, nil)
+		location, err := url.Parse(recorder.Header().Get("Location"))
+		if recorder.Code != http.StatusFound || err != nil {
+			t.Fatalf("Expected a redirect to the provider, got %d: %v", recorder.Code, err)
+		}
+		cookie := responseCookie(recorder, oidcStateCookieName)
+		if cookie == nil {
+			t.Fatalf("Expected the state cookie to be set")
+		}
+		return cookie, location.Query().Get("state"), location.Query().Get("nonce")
+	}
+
+	stateCookie, state, sentNonce := start()
+	nonce = sentNonce
+	recorder := serveUI(r, http.MethodGet, "/login/oidc/callback?code=abc&state="+state, nil, nil, stateCookie)
+	if recorder.Code != http.StatusSeeOther || recorder.Header().Get("Location") != "/" {
+		t.Fatalf("Expected a redirect to /, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if cookie := responseCookie(recorder, oidcStateCookieName); cookie == nil || cookie.MaxAge >= 0 {
+		t.Errorf("Expected the state cookie to be deleted, got %v", cookie)
+	}
+	idToken := responseCookie(recorder, idTokenCookieName)
+	if idToken == nil || idToken.Value == "" || !idToken.HttpOnly {
+		t.Fatalf("Expected an HttpOnly ID token cookie, got %v", idToken)
+	}
+	if recorder := serveUI(r, http.MethodGet, "/docs", nil, nil, idToken); recorder.Code != http.StatusOK {
+		t.Errorf("Expected the ID token to sign in, got %d", recorder.Code)
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		nonce    string
+		noCookie bool
+		expected string
+	}{
+		{name: "no state cookie", query: "code=abc&state={state}", noCookie: true, expected: "sign in expired"},
+		{name: "other state", query: "code=abc&state=forged", expected: "state mismatch"},
+		{name: "no state", query: "code=abc", expected: "state mismatch"},
+		{name: "replayed state", query: "code=abc&state=" + state, expected: "state mismatch"},
+		{name: "other nonce", query: "code=abc&state={state}", nonce: "replayed", expected: "nonce mismatch"},
+		{name: "provider error", query: "error=access_denied&state={state}", expected: "access_denied"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stateCookie, state, sentNonce := start()
+			nonce = sentNonce
+			if test.nonce != "" {
+				nonce = test.nonce
+			}
+			var cookies []*http.Cookie
+			if !test.noCookie {
+				cookies = append(cookies, stateCookie)
+			}
+
+			query := strings.ReplaceAll(test.query, "{state}", state)
+			recorder := serveUI(r, http.MethodGet, "/login/oidc/callback?"+query, nil, nil, cookies...)
+			if recorder.Code != http.StatusUnauthorized || !strings.Contains(recorder.Body.String(), test.expected) {
+				t.Errorf("Expected 401 with %q, got %d: %s", test.expected, recorder.Code, recorder.Body.String())
+			}
+			if cookie := responseCookie(recorder, idTokenCookieName); cookie != nil {
+				t.Errorf("Expected no ID token cookie, got %v", cookie)
+			}
+		})
+	}
+}
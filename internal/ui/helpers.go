@@ -0,0 +1,426 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"universal_api/internal/auth"
+	"universal_api/internal/cluster"
+	"universal_api/internal/models"
+	"universal_api/internal/proxy"
+	"universal_api/internal/review"
+	"universal_api/internal/search"
+	"universal_api/internal/stats"
+	"universal_api/internal/storage"
+)
+
+// reviewDoc applies an action of the doc page's review form to a doc: submit, approve, or reject.
+// A nil user is allowed every action, as there's no authentication until users exist.
+func reviewDoc(docs storage.Storage, user *models.User, id, action, comment string) error {
+	permission := auth.PermissionReview
+	if action == "submit" {
+		permission = auth.PermissionWrite
+	}
+	if user != nil && !auth.Allows(user.Role, permission) {
+		return errors.New("your role doesn't allow this")
+	}
+
+	doc, err := docs.GetAPIDoc(id)
+	if err != nil {
+		return err
+	}
+
+	reviewer := ""
+	if user != nil {
+		reviewer = user.ID
+	}
+	switch action {
+	case "submit":
+		err = review.Submit(doc)
+	case "approve":
+		err = review.Approve(doc, reviewer, comment)
+	case "reject":
+		err = review.Reject(doc, reviewer, comment)
+	default:
+		err = fmt.Errorf("unknown review action: %s", action)
+	}
+	if err != nil {
+		return err
+	}
+	return docs.SaveAPIDoc(doc)
+}
+
+// docToken gets an access token to a doc's API with the client credentials of the doc page's token form.
+// Scopes are separated by spaces. A nil user is allowed, as there's no authentication until users exist.
+func docToken(docs storage.Storage, user *models.User, id, scheme, clientID, clientSecret, scopes string) (*models.APIDoc, *proxy.Token, error) {
+	if user != nil && !auth.Allows(user.Role, auth.PermissionWrite) {
+		return nil, nil, errors.New("your role doesn't allow this")
+	}
+	if clientID == "" || clientSecret == "" {
+		return nil, nil, errors.New("client ID and secret are required")
+	}
+
+	doc, err := docs.GetAPIDoc(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	flow, err := proxy.ClientCredentialsFlow(doc, scheme)
+	if err != nil {
+		return nil, nil, err
+	}
+	token, err := proxy.ClientCredentialsToken(doc, flow, clientID, clientSecret, strings.Fields(scopes))
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, token, nil
+}
+
+// withFreeTier returns the docs of APIs with a free tier
+func withFreeTier(docs []*models.APIDoc) []*models.APIDoc {
+	filtered := make([]*models.APIDoc, 0, len(docs))
+	for _, doc := range docs {
+		if doc.HasFreeTier() {
+			filtered = append(filtered, doc)
+		}
+	}
+	return filtered
+}
+
+// matchingDocs returns the docs whose title, description, or URL contains a query, ignoring case
+func matchingDocs(docs []*models.APIDoc, query string) []*models.APIDoc {
+	query = strings.ToLower(strings.TrimSpace(query))
+	filtered := make([]*models.APIDoc, 0, len(docs))
+	for _, doc := range docs {
+		if strings.Contains(strings.ToLower(doc.Title), query) || strings.Contains(strings.ToLower(doc.Description), query) ||
+			strings.Contains(strings.ToLower(doc.URL), query) {
+			filtered = append(filtered, doc)
+		}
+	}
+	return filtered
+}
+
+// staleDocs returns the IDs of the docs whose source was found gone
+func staleDocs(checks storage.SourceCheckStorage) map[string]bool {
+	all, err := checks.GetSourceChecks()
+	if err != nil {
+		log.Printf("Failed to get source checks: %v", err)
+		return nil
+	}
+
+	stale := make(map[string]bool)
+	for _, check := range all {
+		if check.Stale {
+			stale[check.DocID] = true
+		}
+	}
+	return stale
+}
+
+// withStaleSource returns the docs whose source was found gone
+func withStaleSource(docs []*models.APIDoc, stale map[string]bool) []*models.APIDoc {
+	filtered := make([]*models.APIDoc, 0, len(docs))
+	for _, doc := range docs {
+		if stale[doc.ID] {
+			filtered = append(filtered, doc)
+		}
+	}
+	return filtered
+}
+
+// isPartial checks if a request comes from HTMX, which swaps the response into part of the page,
+// so handlers answer with that fragment instead of the whole page
+func isPartial(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true"
+}
+
+// workspaceScrapes returns the scrape records of a workspace
+func workspaceScrapes(scrapes storage.ScrapeStorage, workspace string) ([]*models.ScrapeRecord, error) {
+	all, err := scrapes.GetScrapeRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []*models.ScrapeRecord
+	for _, scrape := range all {
+		if inWorkspace(scrape.Workspace, workspace) {
+			filtered = append(filtered, scrape)
+		}
+	}
+	return filtered, nil
+}
+
+// inlineAttachmentTypes are the attachment types safe to show in the browser; others are always downloaded
+var inlineAttachmentTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+// docAttachments returns the attachments of a doc and its logo, if it has one
+func docAttachments(attachments storage.AttachmentStorage, docID string) ([]*models.Attachment, *models.Attachment) {
+	all, err := attachments.GetDocAttachments(docID)
+	if err != nil {
+		return nil, nil
+	}
+
+	var files []*models.Attachment
+	var logo *models.Attachment
+	for _, attachment := range all {
+		if attachment.Kind == models.AttachmentKindLogo {
+			logo = attachment
+		} else {
+			files = append(files, attachment)
+		}
+	}
+	return files, logo
+}
+
+// docIcon returns the source of the icon shown beside a doc in listings: its uploaded logo, or else
+// the icon found when it was scraped
+func docIcon(attachments storage.AttachmentStorage, docID, iconURL string) string {
+	if _, logo := docAttachments(attachments, docID); logo != nil {
+		return "/docs/" + url.PathEscape(docID) + "/attachments/" + url.PathEscape(logo.ID)
+	}
+	return iconURL
+}
+
+// docIcons maps the IDs of docs to their icons, leaving out the docs without one
+func docIcons(attachments storage.AttachmentStorage, docs []*models.APIDoc) map[string]string {
+	icons := make(map[string]string)
+	for _, doc := range docs {
+		if icon := docIcon(attachments, doc.ID, doc.IconURL); icon != "" {
+			icons[doc.ID] = icon
+		}
+	}
+	return icons
+}
+
+// hitIcons maps the IDs of the docs of search hits to their icons, leaving out the docs without one
+func hitIcons(attachments storage.AttachmentStorage, hits []search.Hit) map[string]string {
+	icons := make(map[string]string)
+	for _, hit := range hits {
+		if _, ok := icons[hit.DocID]; ok {
+			continue
+		}
+		icons[hit.DocID] = docIcon(attachments, hit.DocID, hit.DocIcon)
+	}
+	for id, icon := range icons {
+		if icon == "" {
+			delete(icons, id)
+		}
+	}
+	return icons
+}
+
+// writeAttachment writes the content of an attachment of a doc
+func writeAttachment(w http.ResponseWriter, attachments storage.AttachmentStorage, docID, id string) error {
+	attachment, err := attachments.GetAttachment(id)
+	if err != nil || attachment.DocID != docID {
+		return errors.New("attachment not found")
+	}
+	data, err := attachments.GetAttachmentData(id)
+	if err != nil {
+		return err
+	}
+
+	disposition := "attachment"
+	if inlineAttachmentTypes[attachment.ContentType] {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, attachment.Name))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_, err = w.Write(data)
+	return err
+}
+
+// writeRawSource writes the latest raw content a doc was scraped from as it was served, in a sandbox
+// so pages can't run scripts
+func writeRawSource(w http.ResponseWriter, rawSources storage.RawSourceStorage, docID string) error {
+	source, err := rawSources.GetRawSource(docID, 0)
+	if err != nil {
+		return err
+	}
+	content, err := rawSources.GetRawSourceContent(docID, source.Version)
+	if err != nil {
+		return err
+	}
+
+	contentType := source.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_, err = w.Write(content)
+	return err
+}
+
+// archiveRawSource archives the raw source a doc was scraped from, logging failures: the doc is saved either way
+func archiveRawSource(rawSources storage.RawSourceStorage, docID string, source *models.RawSource) {
+	if source == nil {
+		return
+	}
+	source.DocID = docID
+	if err := rawSources.SaveRawSource(source); err != nil {
+		log.Printf("Failed to archive the raw source of %s: %v", docID, err)
+	}
+}
+
+// workspaceSchemas returns the catalog schemas of a workspace
+func workspaceSchemas(schemas storage.SchemaStorage, workspace string) ([]*models.Schema, error) {
+	all, err := schemas.GetAllSchemas()
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []*models.Schema
+	for _, s := range all {
+		if inWorkspace(s.Workspace, workspace) {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered, nil
+}
+
+// inWorkspace checks if a record's workspace, empty for the default workspace, is the browsed workspace
+func inWorkspace(recordWorkspace, workspace string) bool {
+	return recordWorkspace == workspace || recordWorkspace == "" && workspace == models.DefaultWorkspace
+}
+
+// recordScrape records the outcome of a scrape from the UI
+func recordScrape(scrapes storage.ScrapeStorage, workspace, url string, doc *models.APIDoc, scrapeErr error) {
+	record := &models.ScrapeRecord{Workspace: workspace, URL: url, Success: scrapeErr == nil, Instance: cluster.InstanceID(), ScrapedAt: time.Now()}
+	if doc != nil {
+		record.DocID = storage.WorkspaceDocID(workspace, doc.ID)
+		record.Endpoints = len(doc.Endpoints)
+		record.Encoding = doc.Encoding
+		record.MetadataSources = doc.MetadataSources
+	}
+	if scrapeErr != nil {
+		record.Error = scrapeErr.Error()
+	}
+
+	if err := scrapes.RecordScrape(record); err != nil {
+		log.Printf("Failed to record scrape of %s: %v", url, err)
+	}
+}
+
+// popularAPIsLimit is the number of docs shown under popular APIs on the index page
+const popularAPIsLimit = 5
+
+// popularDocs returns the most viewed docs for the index page
+func popularDocs(docs []*models.APIDoc, views storage.ViewStorage) []stats.DocViews {
+	counts, err := views.GetAllViewCounts()
+	if err != nil {
+		log.Printf("Failed to get view counts: %v", err)
+		return nil
+	}
+	return stats.MostViewed(docs, counts, popularAPIsLimit)
+}
+
+// recordPageView counts a doc page view, and an endpoint view when the endpoint query
+// parameter names one ("METHOD /path"). Crawlers aren't counted.
+func recordPageView(views storage.ViewStorage, r *http.Request, docID string) {
+	if stats.IsBot(r.UserAgent()) {
+		return
+	}
+	if err := views.RecordView(docID, r.URL.Query().Get("endpoint"), models.ViewKindPage); err != nil {
+		log.Printf("Failed to record view of %s: %v", docID, err)
+	}
+}
+
+// relationshipGraph returns a Mermaid flowchart of a doc's links between endpoints and its callbacks,
+// or "" when the doc has neither
+func relationshipGraph(doc *models.APIDoc) string {
+	var b strings.Builder
+	nodes := make(map[string]string)
+	node := func(method, path string) string {
+		key := strings.ToUpper(method) + " " + path
+		if id, ok := nodes[key]; ok {
+			return id
+		}
+		id := fmt.Sprintf("e%d", len(nodes))
+		nodes[key] = id
+		fmt.Fprintf(&b, "    %s[\"%s\"]\n", id, mermaidText(key))
+		return id
+	}
+
+	callbacks := 0
+	for _, endpoint := range doc.Endpoints {
+		for _, link := range endpoint.Links {
+			if link.Path == "" {
+				continue
+			}
+			from, to := node(endpoint.Method, endpoint.Path), node(link.Method, link.Path)
+			fmt.Fprintf(&b, "    %s -- \"%s\" --> %s\n", from, mermaidText(statusLabel(link.StatusCode)+" "+link.Name), to)
+		}
+		for _, callback := range endpoint.Callbacks {
+			from := node(endpoint.Method, endpoint.Path)
+			id := fmt.Sprintf("c%d", callbacks)
+			callbacks++
+			fmt.Fprintf(&b, "    %s([\"%s\"])\n", id, mermaidText(callback.Method+" "+callback.Expression))
+			fmt.Fprintf(&b, "    %s -. \"%s\" .-> %s\n", from, mermaidText(callback.Name), id)
+		}
+	}
+
+	if b.Len() == 0 {
+		return ""
+	}
+	return "flowchart LR\n" + b.String()
+}
+
+// statusLabel returns the status code of a response, or "default" for the default response
+func statusLabel(code int) string {
+	if code == 0 {
+		return "default"
+	}
+	return strconv.Itoa(code)
+}
+
+// mermaidText escapes quotes in Mermaid labels
+func mermaidText(text string) string {
+	return strings.ReplaceAll(text, `"`, "#quot;")
+}
+
+// humanize turns an identifier such as has_examples into words
+func humanize(name string) string {
+	return strings.ReplaceAll(name, "_", " ")
+}
+
+// percent converts a ratio to a percentage
+func percent(ratio float64) float64 {
+	return ratio * 100
+}
+
+// fileSize formats a size in bytes for display, e.g. 1.5 MB
+func fileSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
+
+// selectedFacets returns the facet values selected in the query, for checking their boxes
+func selectedFacets(query search.Query) map[string]map[string]bool {
+	selected := make(map[string]map[string]bool)
+	for facet, values := range query.Filters {
+		selected[facet] = make(map[string]bool)
+		for _, value := range values {
+			selected[facet][value] = true
+		}
+	}
+	return selected
+}
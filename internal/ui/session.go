@@ -0,0 +1,205 @@
+package ui
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"universal_api/internal/auth"
+	"universal_api/internal/models"
+	"universal_api/internal/storage"
+)
+
+// workspaceCookieName is the cookie remembering the workspace browsed in the UI
+const workspaceCookieName = "workspace"
+
+// workspaceCookie creates the cookie remembering the browsed workspace
+func workspaceCookie(id string) *http.Cookie {
+	return &http.Cookie{Name: workspaceCookieName, Value: id, Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode}
+}
+
+// requestWorkspace returns the workspace a UI request browses: the X-Workspace header
+// (e.g. set by a reverse proxy per team hostname), then the workspace cookie, then the default workspace
+func requestWorkspace(r *http.Request, workspaces storage.WorkspaceRegistry) string {
+	id := r.Header.Get("X-Workspace")
+	if id == "" {
+		if cookie, err := r.Cookie(workspaceCookieName); err == nil {
+			id = cookie.Value
+		}
+	}
+	if id == "" {
+		return models.DefaultWorkspace
+	}
+	if _, err := workspaces.GetWorkspace(id); err != nil {
+		return models.DefaultWorkspace
+	}
+	return id
+}
+
+// themeCookieName is the cookie remembering the UI's color theme. Scripts read and set it too, so it isn't HttpOnly.
+const themeCookieName = "theme"
+
+// Color themes of the UI; without a chosen theme, the UI follows the system's
+const (
+	themeLight = "light"
+	themeDark  = "dark"
+)
+
+// themeCookie creates the cookie remembering a color theme; an empty theme forgets the choice
+func themeCookie(theme string) *http.Cookie {
+	cookie := &http.Cookie{Name: themeCookieName, Value: theme, Path: "/", MaxAge: 365 * 24 * 60 * 60, SameSite: http.SameSiteLaxMode}
+	if theme == "" {
+		cookie.MaxAge = -1
+	}
+	return cookie
+}
+
+// requestTheme returns the color theme chosen for a UI request, or empty to follow the system's
+func requestTheme(r *http.Request) string {
+	if cookie, err := r.Cookie(themeCookieName); err == nil && (cookie.Value == themeLight || cookie.Value == themeDark) {
+		return cookie.Value
+	}
+	return ""
+}
+
+// localReferer returns the path of the page a request came from on this site, or fallback
+func localReferer(r *http.Request, fallback string) string {
+	referer, err := url.Parse(r.Referer())
+	if err != nil || (referer.Host != "" && referer.Host != r.Host) ||
+		!strings.HasPrefix(referer.Path, "/") || strings.HasPrefix(referer.Path, "//") || strings.HasPrefix(referer.Path, "/\\") {
+		return fallback
+	}
+	if referer.RawQuery != "" {
+		return referer.Path + "?" + referer.RawQuery
+	}
+	return referer.Path
+}
+
+// apiKeyCookieName is the cookie holding the API key the UI is signed in with
+const apiKeyCookieName = "api_key"
+
+// idTokenCookieName is the cookie holding the ID token the UI is signed in with through OIDC
+const idTokenCookieName = "id_token"
+
+// oidcStateCookieName is the cookie holding the state and nonce of an OIDC sign in in progress
+const oidcStateCookieName = "oidc_state"
+
+// sessionCookie creates an HttpOnly cookie; an empty value deletes it
+func sessionCookie(r *http.Request, name, value string, maxAge int) *http.Cookie {
+	cookie := &http.Cookie{Name: name, Value: value, Path: "/", MaxAge: maxAge, HttpOnly: true, Secure: r.TLS != nil, SameSite: http.SameSiteLaxMode}
+	if value == "" {
+		cookie.MaxAge = -1
+	}
+	return cookie
+}
+
+// apiKeyCookie creates the cookie signing the UI in with an API key; an empty key signs out
+func apiKeyCookie(r *http.Request, key string) *http.Cookie {
+	return sessionCookie(r, apiKeyCookieName, key, 0)
+}
+
+// requestUser returns the user a UI request is signed in as, from the X-API-Key header,
+// the API key cookie, or the OIDC ID token cookie
+func requestUser(r *http.Request, users storage.UserStorage, provider *auth.OIDCProvider) *models.User {
+	key := r.Header.Get("X-API-Key")
+	if cookie, err := r.Cookie(apiKeyCookieName); key == "" && err == nil {
+		key = cookie.Value
+	}
+	if cookie, err := r.Cookie(idTokenCookieName); key == "" && err == nil {
+		key = cookie.Value
+	}
+
+	user, err := auth.AuthenticateToken(users, provider, key)
+	if err != nil {
+		return nil
+	}
+	return user
+}
+
+// oidcRedirectURL returns the URL the OIDC provider sends the browser back to after signing in
+func oidcRedirectURL(r *http.Request, provider *auth.OIDCProvider) string {
+	if redirectURL := provider.RedirectURL(); redirectURL != "" {
+		return redirectURL
+	}
+
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + "/login/oidc/callback"
+}
+
+// startOIDCLogin redirects to the OIDC provider, remembering the state and nonce checked on return
+func startOIDCLogin(w http.ResponseWriter, r *http.Request, provider *auth.OIDCProvider) error {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return err
+	}
+	state := hex.EncodeToString(random[:16])
+	nonce := hex.EncodeToString(random[16:])
+
+	http.SetCookie(w, sessionCookie(r, oidcStateCookieName, state+"."+nonce, 600))
+	http.Redirect(w, r, provider.AuthCodeURL(oidcRedirectURL(r, provider), state, nonce), http.StatusFound)
+	return nil
+}
+
+// finishOIDCLogin redeems the provider's authorization code and signs the UI in with the ID token
+func finishOIDCLogin(w http.ResponseWriter, r *http.Request, users storage.UserStorage, provider *auth.OIDCProvider) error {
+	cookie, err := r.Cookie(oidcStateCookieName)
+	if err != nil {
+		return errors.New("sign in expired, please try again")
+	}
+	http.SetCookie(w, sessionCookie(r, oidcStateCookieName, "", 0))
+
+	state, nonce, _ := strings.Cut(cookie.Value, ".")
+	if state == "" || r.URL.Query().Get("state") != state {
+		return errors.New("state mismatch")
+	}
+	if message := r.URL.Query().Get("error"); message != "" {
+		return errors.New(message + " " + r.URL.Query().Get("error_description"))
+	}
+
+	idToken, err := provider.Exchange(oidcRedirectURL(r, provider), r.URL.Query().Get("code"))
+	if err != nil {
+		return err
+	}
+	claims, err := provider.Verify(idToken)
+	if err != nil {
+		return err
+	}
+	if claims["nonce"] != nonce {
+		return errors.New("nonce mismatch")
+	}
+	if _, err := provider.User(users, claims); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, sessionCookie(r, idTokenCookieName, idToken, 0))
+	return nil
+}
+
+// authorizeRequest checks if a UI request may use a permission in a workspace, returning
+// http.StatusOK, or http.StatusUnauthorized when not signed in, or http.StatusForbidden.
+// Every request is allowed until users exist.
+func authorizeRequest(r *http.Request, users storage.UserStorage, provider *auth.OIDCProvider, permission, workspace string) int {
+	if !auth.Enabled(users) {
+		return http.StatusOK
+	}
+
+	user := requestUser(r, users, provider)
+	if user == nil {
+		return http.StatusUnauthorized
+	}
+	if !auth.Authorize(user, permission, workspace) {
+		return http.StatusForbidden
+	}
+	return http.StatusOK
+}
+
+// canReview checks if a user may approve or reject docs; everyone may until users exist
+func canReview(user *models.User) bool {
+	return user == nil || auth.Allows(user.Role, auth.PermissionReview)
+}
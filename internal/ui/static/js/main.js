@@ -7,5 +7,235 @@ document.addEventListener('DOMContentLoaded', function() {
         form.addEventListener('submit', function() {
             document.getElementById('loading').style.display = 'block';
         });
+        // HTMX scrapes in place, so hide it again once the status is shown
+        form.addEventListener('htmx:afterRequest', function() {
+            document.getElementById('loading').style.display = 'none';
+        });
+    }
+
+    // Switch descriptions between rendered and raw markdown, remembering the choice
+    const rawToggle = document.getElementById('rawMarkdown');
+    if (rawToggle) {
+        rawToggle.checked = localStorage.getItem('rawMarkdown') === 'true';
+        document.body.classList.toggle('show-raw-markdown', rawToggle.checked);
+        rawToggle.addEventListener('change', function() {
+            localStorage.setItem('rawMarkdown', rawToggle.checked);
+            document.body.classList.toggle('show-raw-markdown', rawToggle.checked);
+        });
+    }
+
+    // Switch the color theme in place, remembering the choice in the cookie the server reads
+    const themeToggle = document.getElementById('themeToggle');
+    if (themeToggle) {
+        const showTheme = function() {
+            const dark = document.documentElement.dataset.bsTheme === 'dark';
+            themeToggle.value = dark ? 'light' : 'dark';
+            themeToggle.textContent = dark ? 'Light mode' : 'Dark mode';
+        };
+        showTheme();
+        themeToggle.addEventListener('click', function(event) {
+            event.preventDefault();
+            document.documentElement.dataset.bsTheme = themeToggle.value;
+            document.cookie = 'theme=' + themeToggle.value + '; path=/; max-age=31536000; samesite=lax';
+            showTheme();
+        });
+    }
+
+    // Drop doc icons that fail to load, rather than showing a broken image, whether they failed
+    // before this ran or fail later, as in results loaded by HTMX
+    document.querySelectorAll('img.doc-icon').forEach(function(icon) {
+        if (icon.complete && icon.naturalWidth === 0) {
+            icon.remove();
+        }
+    });
+    document.addEventListener('error', function(event) {
+        if (event.target.matches && event.target.matches('img.doc-icon')) {
+            event.target.remove();
+        }
+    }, true);
+
+    // Collapse and expand endpoints on the doc page
+    const endpointControls = document.querySelector('.endpoint-controls');
+    if (endpointControls) {
+        endpointControls.hidden = false;
     }
+    document.addEventListener('click', function(event) {
+        const toggle = event.target.closest('.endpoint-toggle');
+        if (toggle) {
+            setExpanded(toggle, toggle.getAttribute('aria-expanded') !== 'true');
+        }
+        const all = event.target.closest('[data-expand-all]');
+        if (all) {
+            document.querySelectorAll('.endpoint-toggle').forEach(function(toggle) {
+                setExpanded(toggle, all.dataset.expandAll === 'true');
+            });
+        }
+    });
+
+    // Copy an endpoint's snippet to the clipboard instead of opening it
+    document.addEventListener('click', async function(event) {
+        const link = event.target.closest('[data-copy-snippet]');
+        if (!link || !navigator.clipboard) {
+            return;
+        }
+        event.preventDefault();
+        const status = link.closest('.dropdown').querySelector('.copy-status');
+        try {
+            const response = await fetch(link.href);
+            if (!response.ok) {
+                throw new Error(await response.text());
+            }
+            await navigator.clipboard.writeText(await response.text());
+            status.textContent = 'Copied as ' + link.textContent;
+        } catch (error) {
+            status.textContent = 'Copy failed: ' + error.message;
+        }
+    });
+
+    setupQuickOpen();
+
+    // Keyboard navigation: "/" focuses the page's search box, and j and k move between the items of
+    // the docs list, search results, or endpoints, as do the arrow keys once on an item
+    document.addEventListener('keydown', function(event) {
+        if (event.ctrlKey || event.metaKey || event.altKey || event.target.closest('input, textarea, select, [contenteditable]')) {
+            return;
+        }
+        if (event.key === '/') {
+            const search = document.querySelector('[data-search-shortcut]');
+            if (search) {
+                event.preventDefault();
+                search.focus();
+            }
+            return;
+        }
+
+        const items = Array.from(document.querySelectorAll('[data-nav-list] [data-nav-item]'));
+        const current = items.indexOf(document.activeElement);
+        let step = 0;
+        if (event.key === 'j' || (event.key === 'ArrowDown' && current >= 0)) {
+            step = 1;
+        } else if (event.key === 'k' || (event.key === 'ArrowUp' && current >= 0)) {
+            step = -1;
+        }
+        if (step === 0 || items.length === 0) {
+            return;
+        }
+        event.preventDefault();
+        const next = current < 0 ? (step > 0 ? 0 : items.length - 1) : Math.min(Math.max(current + step, 0), items.length - 1);
+        items[next].focus();
+    });
 });
+
+// setupQuickOpen opens the quick-open overlay on Ctrl+K (Cmd+K on macOS), suggesting docs and
+// endpoints as the query is typed
+function setupQuickOpen() {
+    const dialog = document.getElementById('quickOpen');
+    if (!dialog || !dialog.showModal) {
+        return;
+    }
+    const input = dialog.querySelector('input');
+    const results = dialog.querySelector('[role=listbox]');
+    let active = -1;
+    let pending = null;
+
+    const open = function() {
+        input.value = '';
+        results.replaceChildren();
+        active = -1;
+        dialog.showModal();
+        input.focus();
+    };
+    const choose = function(index) {
+        const options = results.querySelectorAll('[role=option]');
+        if (options.length === 0) {
+            return;
+        }
+        active = (index + options.length) % options.length;
+        options.forEach(function(option, i) {
+            option.setAttribute('aria-selected', i === active);
+            option.classList.toggle('active', i === active);
+        });
+        input.setAttribute('aria-activedescendant', options[active].id);
+        options[active].scrollIntoView({block: 'nearest'});
+    };
+    const option = function(href, label, detail) {
+        const item = document.createElement('li');
+        const link = document.createElement('a');
+        link.href = href;
+        link.id = 'quickOpen-' + results.children.length;
+        link.setAttribute('role', 'option');
+        link.className = 'd-block px-2 py-1 text-reset text-decoration-none';
+        link.textContent = label;
+        const small = document.createElement('small');
+        small.className = 'text-muted ms-2';
+        small.textContent = detail;
+        link.appendChild(small);
+        item.appendChild(link);
+        results.appendChild(item);
+    };
+    const suggest = async function() {
+        const query = input.value;
+        try {
+            const response = await fetch('/suggest?q=' + encodeURIComponent(query));
+            const suggestions = await response.json();
+            if (query !== input.value) {
+                return;
+            }
+            results.replaceChildren();
+            suggestions.docs.forEach(function(doc) {
+                option(doc.url, doc.title, doc.endpoints + ' endpoints');
+            });
+            suggestions.endpoints.forEach(function(endpoint) {
+                option(endpoint.url, endpoint.method + ' ' + endpoint.path, endpoint.doc_title);
+            });
+            active = -1;
+            choose(0);
+        } catch (error) {
+            results.replaceChildren();
+        }
+    };
+
+    document.querySelectorAll('[data-quick-open]').forEach(function(button) {
+        button.hidden = false;
+        button.addEventListener('click', open);
+    });
+    document.addEventListener('keydown', function(event) {
+        if ((event.ctrlKey || event.metaKey) && event.key.toLowerCase() === 'k') {
+            event.preventDefault();
+            if (dialog.open) {
+                dialog.close();
+            } else {
+                open();
+            }
+        }
+    });
+    input.addEventListener('input', function() {
+        clearTimeout(pending);
+        pending = setTimeout(suggest, 100);
+    });
+    input.addEventListener('keydown', function(event) {
+        if (event.key === 'ArrowDown' || event.key === 'ArrowUp') {
+            event.preventDefault();
+            choose(active + (event.key === 'ArrowDown' ? 1 : -1));
+        } else if (event.key === 'Enter') {
+            const chosen = results.querySelectorAll('[role=option]')[active];
+            if (chosen) {
+                event.preventDefault();
+                dialog.close();
+                window.location.href = chosen.href;
+            }
+        }
+    });
+    // Close when clicking the backdrop
+    dialog.addEventListener('click', function(event) {
+        if (event.target === dialog) {
+            dialog.close();
+        }
+    });
+}
+
+// setExpanded shows or hides the body of an endpoint, keeping its toggle's state in step for screen readers
+function setExpanded(toggle, expanded) {
+    toggle.setAttribute('aria-expanded', expanded);
+    document.getElementById(toggle.getAttribute('aria-controls')).hidden = !expanded;
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Branding gives the catalog its own identity in the UI
+type Branding struct {
+	Name         string // shown in the header, page titles, and footer
+	LogoURL      string // shown beside the name in the header
+	PrimaryColor string // CSS color of links, primary buttons, and the active navigation item
+	Footer       string // footer text, replacing the copyright line
+	FooterLinks  []Link
+}
+
+// Link is a link in the footer
+type Link struct {
+	Label string
+	URL   string
+}
+
+// DefaultBranding is the catalog's own identity
+var DefaultBranding = Branding{Name: "Universal API"}
+
+// ParseLinks parses footer links given as comma-separated Label=URL pairs
+func ParseLinks(value string) ([]Link, error) {
+	var links []Link
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		label, url, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(label) == "" || strings.TrimSpace(url) == "" {
+			return nil, fmt.Errorf("invalid link %q: expected Label=URL", pair)
+		}
+		links = append(links, Link{Label: strings.TrimSpace(label), URL: strings.TrimSpace(url)})
+	}
+	return links, nil
+}
+
+// SetBranding sets the name, logo, colors, and footer of the UI. Empty fields keep the default.
+func (h *Handler) SetBranding(branding Branding) {
+	if branding.Name == "" {
+		branding.Name = DefaultBranding.Name
+	}
+	h.branding = branding
+}
+
+// SetThemeDir overrides the built-in templates and static files with those of a directory: files in its
+// templates directory replace the templates of the same name, and files in its static directory are
+// served in place of the built-in ones at the same path
+func (h *Handler) SetThemeDir(dir string) error {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("theme directory not found: %s", dir)
+	}
+	h.themeDir = dir
+	return nil
+}
+
+// loadTemplates parses the built-in templates, then those of the theme directory
+func (h *Handler) loadTemplates(funcs template.FuncMap) (*template.Template, error) {
+	templates, err := template.New("").Funcs(funcs).ParseGlob(filepath.Join("internal", "ui", "templates", "*"))
+	if err != nil || h.themeDir == "" {
+		return templates, err
+	}
+
+	overrides, err := filepath.Glob(filepath.Join(h.themeDir, "templates", "*.tmpl"))
+	if err != nil || len(overrides) == 0 {
+		return templates, err
+	}
+	return templates.ParseFiles(overrides...)
+}
+
+// staticFiles returns the static files, those of the theme directory first
+func (h *Handler) staticFiles() http.FileSystem {
+	builtIn := gin.Dir(filepath.Join("internal", "ui", "static"), false)
+	if h.themeDir == "" {
+		return builtIn
+	}
+	return overlayFileSystem{gin.Dir(filepath.Join(h.themeDir, "static"), false), builtIn}
+}
+
+// overlayFileSystem opens a file from the first file system that has it
+type overlayFileSystem []http.FileSystem
+
+// Open opens a file from the first file system that has it
+func (o overlayFileSystem) Open(name string) (http.File, error) {
+	for _, files := range o {
+		if file, err := files.Open(name); err == nil {
+			return file, nil
+		}
+	}
+	return nil, os.ErrNotExist
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestThemeDir tests a theme directory replacing a template and a static file, with the built-in ones serving the rest
+func TestThemeDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"templates/error.tmpl":  `{{ define "error.tmpl" }}<p class="themed">{{.Error}}</p>{{ end }}`,
+		"static/css/custom.css": ".themed { color: teal; }",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	r, _ := uiTestServer(t, nil, func(h *Handler) {
+		if err := h.SetThemeDir(dir); err != nil {
+			t.Fatalf("Failed to set theme directory: %v", err)
+		}
+		h.SetBranding(Branding{Name: "Acme APIs"})
+	})
+
+	recorder := serveUI(r, http.MethodGet, "/workspaces/missing", nil, nil)
+	if body := recorder.Body.String(); body != `<p class="themed">Workspace not found: missing</p>` {
+		t.Errorf("Expected the theme's error template, got %q", body)
+	}
+	recorder = serveUI(r, http.MethodGet, "/login", nil, nil)
+	if body := recorder.Body.String(); recorder.Code != http.StatusOK || !strings.Contains(body, "Acme APIs") {
+		t.Errorf("Expected the built-in sign in template with the brand, got %d", recorder.Code)
+	}
+
+	recorder = serveUI(r, http.MethodGet, "/static/css/custom.css", nil, nil)
+	if body := recorder.Body.String(); body != files["static/css/custom.css"] {
+		t.Errorf("Expected the theme's stylesheet, got %q", body)
+	}
+	builtIn, err := os.ReadFile(filepath.Join("internal", "ui", "static", "js", "main.js"))
+	if err != nil {
+		t.Fatalf("Failed to read built-in script: %v", err)
+	}
+	recorder = serveUI(r, http.MethodGet, "/static/js/main.js", nil, nil)
+	if recorder.Code != http.StatusOK || recorder.Body.String() != string(builtIn) {
+		t.Errorf("Expected the built-in script, got %d", recorder.Code)
+	}
+	if recorder := serveUI(r, http.MethodGet, "/static/js/missing.js", nil, nil); recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a file in neither directory, got %d", recorder.Code)
+	}
+
+	handler := &Handler{}
+	if err := handler.SetThemeDir(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("Expected a missing theme directory to be an error")
+	}
+}
@@ -0,0 +1,166 @@
+// Package validation checks the fields of inbound requests, collecting an error per invalid field so a
+// client learns about every problem of a request at once, each tied to the field to fix.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// Codes of field errors
+const (
+	CodeRequired    = "required"     // the field is missing or empty
+	CodeTooLong     = "too_long"     // the value is longer than allowed
+	CodeInvalidURL  = "invalid_url"  // the value isn't an absolute URL of an allowed scheme
+	CodeNotAllowed  = "not_allowed"  // the value isn't one of the allowed values
+	CodeInvalidType = "invalid_type" // the JSON value has the wrong type
+	CodeInvalid     = "invalid"      // the value is malformed in another way
+	CodeMalformed   = "malformed"    // the body isn't valid JSON; the field is empty
+)
+
+// Limits of the lengths of common fields, in characters
+const (
+	MaxURLLength         = 2048
+	MaxIDLength          = 128
+	MaxNameLength        = 200
+	MaxDescriptionLength = 10000
+)
+
+// FieldError is what's wrong with one field of a request
+type FieldError struct {
+	Field   string `json:"field"` // path of the field in the JSON body, e.g. owner.team or events[1]; empty for the whole body
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Errors are the field errors of a request
+type Errors []FieldError
+
+// Error lists the field errors
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldError := range e {
+		messages[i] = fieldError.Message
+		if fieldError.Field != "" {
+			messages[i] = fieldError.Field + ": " + fieldError.Message
+		}
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validator collects the field errors of a request
+type Validator struct {
+	errs Errors
+}
+
+// Add records an error of a field
+func (v *Validator) Add(field, code, message string) {
+	v.errs = append(v.errs, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Required checks that a field is set, and returns whether it is, so further checks can be skipped
+func (v *Validator) Required(field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.Add(field, CodeRequired, "is required")
+		return false
+	}
+	return true
+}
+
+// MaxLength checks that a field has at most max characters
+func (v *Validator) MaxLength(field, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		v.Add(field, CodeTooLong, fmt.Sprintf("must be at most %d characters", max))
+	}
+}
+
+// URL checks that a field, if set, is an absolute URL with a host and one of the schemes, and not too long
+func (v *Validator) URL(field, value string, schemes ...string) {
+	if value == "" {
+		return
+	}
+	if utf8.RuneCountInString(value) > MaxURLLength {
+		v.Add(field, CodeTooLong, fmt.Sprintf("must be at most %d characters", MaxURLLength))
+		return
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Host == "" || !contains(schemes, strings.ToLower(parsed.Scheme)) {
+		v.Add(field, CodeInvalidURL, "must be an absolute "+list(schemes, "or")+" URL")
+	}
+}
+
+// OneOf checks that a field, if set, is one of the allowed values
+func (v *Validator) OneOf(field, value string, allowed ...string) {
+	if value != "" && !contains(allowed, value) {
+		v.Add(field, CodeNotAllowed, "must be "+list(allowed, "or"))
+	}
+}
+
+// Err returns the collected field errors, or nil if the request is valid
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// FromJSON describes an error decoding a JSON body as field errors: values of the wrong type are
+// errors of their fields, and other errors are errors of the whole body
+func FromJSON(err error) Errors {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		return Errors{{Field: typeErr.Field, Code: CodeInvalidType, Message: "must be " + jsonType(typeErr.Type.Kind().String())}}
+	case errors.As(err, &syntaxErr):
+		return Errors{{Code: CodeMalformed, Message: fmt.Sprintf("invalid JSON at offset %d: %v", syntaxErr.Offset, syntaxErr)}}
+	case errors.Is(err, io.EOF):
+		return Errors{{Code: CodeRequired, Message: "a JSON body is required"}}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return Errors{{Code: CodeMalformed, Message: "the JSON body is incomplete"}}
+	}
+	return Errors{{Code: CodeMalformed, Message: err.Error()}}
+}
+
+// jsonType names the JSON type of a Go kind
+func jsonType(kind string) string {
+	switch {
+	case kind == "string":
+		return "a string"
+	case kind == "bool":
+		return "a boolean"
+	case strings.HasPrefix(kind, "int"), strings.HasPrefix(kind, "uint"), strings.HasPrefix(kind, "float"):
+		return "a number"
+	case kind == "slice", kind == "array":
+		return "an array"
+	}
+	return "an object"
+}
+
+// contains checks if values contains value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// list joins values for a message, e.g. "a, b, or c"
+func list(values []string, conjunction string) string {
+	switch len(values) {
+	case 0:
+		return ""
+	case 1:
+		return values[0]
+	case 2:
+		return values[0] + " " + conjunction + " " + values[1]
+	}
+	return strings.Join(values[:len(values)-1], ", ") + ", " + conjunction + " " + values[len(values)-1]
+}
@@ -0,0 +1,81 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestValidator tests collecting an error per invalid field
+func TestValidator(t *testing.T) {
+	var v Validator
+	v.Required("url", " ")
+	v.URL("spec_url", "ftp://example.com/openapi.json", "http", "https")
+	v.URL("site", "/relative", "http", "https")
+	v.URL("docs", "HTTPS://example.com/docs", "http", "https")
+	v.MaxLength("name", strings.Repeat("é", 11), 10)
+	v.MaxLength("title", strings.Repeat("é", 10), 10)
+	v.OneOf("format", "postman", "openapi", "html", "json")
+	v.OneOf("lifecycle", "", "beta", "ga")
+
+	err := v.Err()
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected field errors, got %v", err)
+	}
+	want := []FieldError{
+		{Field: "url", Code: CodeRequired, Message: "is required"},
+		{Field: "spec_url", Code: CodeInvalidURL, Message: "must be an absolute http or https URL"},
+		{Field: "site", Code: CodeInvalidURL, Message: "must be an absolute http or https URL"},
+		{Field: "name", Code: CodeTooLong, Message: "must be at most 10 characters"},
+		{Field: "format", Code: CodeNotAllowed, Message: "must be openapi, html, or json"},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("Expected %d errors, got %v", len(want), errs)
+	}
+	for i := range want {
+		if errs[i] != want[i] {
+			t.Errorf("Expected %+v, got %+v", want[i], errs[i])
+		}
+	}
+	if !strings.HasPrefix(err.Error(), "url: is required; spec_url: ") {
+		t.Errorf("Expected the message to list the fields, got %q", err.Error())
+	}
+
+	var valid Validator
+	valid.Required("url", "https://example.com")
+	if err := valid.Err(); err != nil {
+		t.Errorf("Expected no error for a valid request, got %v", err)
+	}
+}
+
+// TestFromJSON tests describing JSON decoding errors as field errors
+func TestFromJSON(t *testing.T) {
+	var request struct {
+		URL   string `json:"url"`
+		Owner struct {
+			Team string `json:"team"`
+		} `json:"owner"`
+		IDs []string `json:"ids"`
+	}
+	tests := []struct {
+		body  string
+		field string
+		code  string
+	}{
+		{`{"url": 1}`, "url", CodeInvalidType},
+		{`{"owner": {"team": true}}`, "owner.team", CodeInvalidType},
+		{`{"ids": "a"}`, "ids", CodeInvalidType},
+		{`{"url": }`, "", CodeMalformed},
+		{`{"url": "a"`, "", CodeMalformed},
+		{``, "", CodeRequired},
+	}
+	for _, tt := range tests {
+		err := json.NewDecoder(strings.NewReader(tt.body)).Decode(&request)
+		errs := FromJSON(err)
+		if len(errs) != 1 || errs[0].Field != tt.field || errs[0].Code != tt.code {
+			t.Errorf("Body %q: expected a %s error of %q, got %+v", tt.body, tt.code, tt.field, errs)
+		}
+	}
+}
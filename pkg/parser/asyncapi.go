@@ -0,0 +1,162 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"universal_api/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AsyncAPIParser parses AsyncAPI documentation (JSON or YAML)
+type AsyncAPIParser struct{}
+
+// AsyncAPIDoc represents a simplified AsyncAPI document structure
+type AsyncAPIDoc struct {
+	AsyncAPI string                     `json:"asyncapi"`
+	Info     OpenAPIInfo                `json:"info"`
+	Channels map[string]AsyncAPIChannel `json:"channels"`
+	Servers  map[string]AsyncAPIServer  `json:"servers,omitempty"`
+}
+
+// AsyncAPIServer describes a server hosting the channels
+type AsyncAPIServer struct {
+	URL      string `json:"url"`
+	Protocol string `json:"protocol"` // e.g. ws, wss, kafka, or mqtt
+}
+
+// AsyncAPIChannel describes the operations available on a single channel
+type AsyncAPIChannel struct {
+	Description string             `json:"description,omitempty"`
+	Subscribe   *AsyncAPIOperation `json:"subscribe,omitempty"`
+	Publish     *AsyncAPIOperation `json:"publish,omitempty"`
+}
+
+// AsyncAPIOperation describes a single channel operation
+type AsyncAPIOperation struct {
+	Summary     string           `json:"summary,omitempty"`
+	Description string           `json:"description,omitempty"`
+	OperationID string           `json:"operationId,omitempty"`
+	Message     *AsyncAPIMessage `json:"message,omitempty"`
+}
+
+// AsyncAPIMessage describes the message of a channel operation
+type AsyncAPIMessage struct {
+	Name     string        `json:"name,omitempty"`
+	Summary  string        `json:"summary,omitempty"`
+	Payload  interface{}   `json:"payload,omitempty"`  // JSON schema of the payload
+	Examples []interface{} `json:"examples,omitempty"` // payloads, or objects with a payload (AsyncAPI 2.1+)
+}
+
+// Parse implements the Parser interface for AsyncAPI
+func (p *AsyncAPIParser) Parse(content []byte) (*models.APIDoc, error) {
+	// YAML is a superset of JSON, so convert through YAML first
+	var yamlObj interface{}
+	if err := yaml.Unmarshal(content, &yamlObj); err != nil {
+		return nil, fmt.Errorf("failed to parse AsyncAPI: %w", err)
+	}
+
+	jsonData, err := json.Marshal(yamlObj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert AsyncAPI to JSON: %w", err)
+	}
+
+	var asyncAPIDoc AsyncAPIDoc
+	if err := json.Unmarshal(jsonData, &asyncAPIDoc); err != nil {
+		return nil, fmt.Errorf("failed to parse AsyncAPI: %w", err)
+	}
+
+	if asyncAPIDoc.AsyncAPI == "" {
+		return nil, errors.New("content does not appear to be an AsyncAPI document")
+	}
+
+	apiDoc := &models.APIDoc{
+		ID:          fmt.Sprintf("asyncapi-%d", time.Now().Unix()),
+		Title:       asyncAPIDoc.Info.Title,
+		Description: asyncAPIDoc.Info.Description,
+		IconURL:     asyncAPIDoc.Info.LogoURL(),
+		Version:     asyncAPIDoc.Info.Version,
+		Endpoints:   []models.Endpoint{},
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	// Channels of APIs served over WebSockets are WebSockets of their own
+	protocol := ""
+	for _, server := range asyncAPIDoc.Servers {
+		if server.Protocol == "ws" || server.Protocol == "wss" {
+			protocol = models.ProtocolWebSocket
+		}
+	}
+
+	// Each channel operation becomes an endpoint, using the operation as the method. Clients
+	// publish the messages of publish operations and receive those of subscribe operations.
+	for name, channel := range asyncAPIDoc.Channels {
+		for method, operation := range map[string]*AsyncAPIOperation{
+			"PUBLISH":   channel.Publish,
+			"SUBSCRIBE": channel.Subscribe,
+		} {
+			if operation == nil {
+				continue
+			}
+
+			description := operation.Description
+			if description == "" {
+				description = channel.Description
+			}
+
+			endpoint := models.Endpoint{
+				Kind:        models.EndpointEvent,
+				Protocol:    protocol,
+				Path:        name,
+				Method:      method,
+				Summary:     operation.Summary,
+				Description: description,
+				Parameters:  []models.Parameter{},
+				Responses:   []models.Response{},
+			}
+			if operation.Message != nil {
+				direction := models.MessageReceive
+				if method == "PUBLISH" {
+					direction = models.MessageSend
+				}
+				endpoint.Messages = []models.Message{asyncAPIMessage(operation.Message, direction)}
+			}
+			apiDoc.Endpoints = append(apiDoc.Endpoints, endpoint)
+		}
+	}
+
+	// Map iteration above is unordered, so sort by channel then operation
+	sort.Slice(apiDoc.Endpoints, func(i, j int) bool {
+		a, b := apiDoc.Endpoints[i], apiDoc.Endpoints[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Method < b.Method
+	})
+
+	return apiDoc, nil
+}
+
+// asyncAPIMessage converts the message of a channel operation, with its payload schema and first example
+func asyncAPIMessage(message *AsyncAPIMessage, direction string) models.Message {
+	result := models.Message{Direction: direction, Name: message.Name, Summary: message.Summary}
+	if message.Payload != nil {
+		if data, err := json.Marshal(message.Payload); err == nil {
+			result.Schema = string(data)
+		}
+	}
+	if len(message.Examples) > 0 {
+		example := message.Examples[0]
+		if object, ok := example.(map[string]interface{}); ok && object["payload"] != nil {
+			example = object["payload"]
+		}
+		if data, err := json.Marshal(example); err == nil {
+			result.Example = string(data)
+		}
+	}
+	return result
+}
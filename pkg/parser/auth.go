@@ -0,0 +1,181 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"universal_api/internal/models"
+)
+
+// swaggerFlows maps the OAuth2 flow names of Swagger 2.0 to those of OpenAPI 3
+var swaggerFlows = map[string]string{
+	"implicit":    "implicit",
+	"password":    "password",
+	"application": "clientCredentials",
+	"accessCode":  "authorizationCode",
+}
+
+// flowNames are the readable names of OAuth2 flows
+var flowNames = map[string]string{
+	"authorizationCode": "authorization code",
+	"clientCredentials": "client credentials",
+	"implicit":          "implicit",
+	"password":          "password",
+}
+
+// authSchemes returns how to authenticate with the security schemes of a spec, sorted by name
+func authSchemes(schemes map[string]SecurityScheme) []models.AuthScheme {
+	names := make([]string, 0, len(schemes))
+	for name := range schemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result []models.AuthScheme
+	for _, name := range names {
+		scheme := schemes[name]
+		authScheme := models.AuthScheme{
+			Name:             name,
+			Type:             scheme.Type,
+			BearerFormat:     scheme.BearerFormat,
+			OpenIDConnectURL: scheme.OpenIDConnectURL,
+			Description:      scheme.Description,
+		}
+
+		switch scheme.Type {
+		case "http":
+			authScheme.Type = strings.ToLower(scheme.Scheme)
+		case "apiKey":
+			authScheme.In = scheme.In
+			authScheme.Param = scheme.Name
+		case "oauth2":
+			authScheme.Flows = oauthFlows(scheme)
+		}
+		if authScheme.Type == "" {
+			continue
+		}
+
+		authScheme.Summary = authSummary(authScheme)
+		result = append(result, authScheme)
+	}
+	return result
+}
+
+// oauthFlows returns the OAuth2 flows of a security scheme, sorted by name, with their URLs and sorted scopes
+func oauthFlows(scheme SecurityScheme) []models.OAuthFlow {
+	flows := scheme.Flows
+	if flow, ok := swaggerFlows[scheme.Flow]; ok && len(flows) == 0 {
+		flows = map[string]OAuthFlow{flow: {AuthorizationURL: scheme.AuthorizationURL, TokenURL: scheme.TokenURL, Scopes: scheme.Scopes}}
+	}
+
+	var result []models.OAuthFlow
+	for name, flow := range flows {
+		oauthFlow := models.OAuthFlow{
+			Flow:             name,
+			AuthorizationURL: flow.AuthorizationURL,
+			TokenURL:         flow.TokenURL,
+			RefreshURL:       flow.RefreshURL,
+		}
+		for scope := range flow.Scopes {
+			oauthFlow.Scopes = append(oauthFlow.Scopes, scope)
+		}
+		sort.Strings(oauthFlow.Scopes)
+		result = append(result, oauthFlow)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Flow < result[j].Flow })
+	return result
+}
+
+// headerAuthSchemes returns how to authenticate with the headers carrying credentials of a doc without
+// security schemes, one scheme per header
+func headerAuthSchemes(doc *models.APIDoc) []models.AuthScheme {
+	if len(doc.AuthSchemes) > 0 {
+		return doc.AuthSchemes
+	}
+
+	seen := make(map[string]bool)
+	var result []models.AuthScheme
+	for _, endpoint := range doc.Endpoints {
+		// Headers of requests the API sends, like webhook signatures, don't tell how to call it
+		if endpoint.Incoming() {
+			continue
+		}
+		for _, param := range endpoint.Parameters {
+			if param.In != "header" || param.Auth == "" || seen[param.Name] {
+				continue
+			}
+			seen[param.Name] = true
+
+			authScheme := models.AuthScheme{Name: param.Name, Type: param.Auth}
+			if param.Auth == "apiKey" {
+				authScheme.In = "header"
+				authScheme.Param = param.Name
+			}
+			authScheme.Summary = authSummary(authScheme)
+			result = append(result, authScheme)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// authSummary describes how to authenticate with a scheme in a sentence,
+// like "Send an API key in the X-Api-Key header"
+func authSummary(scheme models.AuthScheme) string {
+	switch scheme.Type {
+	case "apiKey":
+		return "Send an API key " + apiKeyLocation(scheme)
+	case "basic":
+		return "Use HTTP Basic authentication: send a username and password in the Authorization header"
+	case "bearer":
+		token := "a bearer token"
+		if scheme.BearerFormat != "" {
+			token = fmt.Sprintf("a bearer token (%s)", scheme.BearerFormat)
+		}
+		return "Send " + token + " in the Authorization header: Authorization: Bearer <token>"
+	case "oauth2":
+		if len(scheme.Flows) == 0 {
+			return "Get an OAuth2 access token and send it as a bearer token in the Authorization header"
+		}
+		flows := make([]string, len(scheme.Flows))
+		for i, flow := range scheme.Flows {
+			flows[i] = flowName(flow.Flow) + " flow"
+			if len(flow.Scopes) > 0 {
+				flows[i] += " (scopes: " + strings.Join(flow.Scopes, ", ") + ")"
+			}
+		}
+		return "Get an OAuth2 access token with the " + strings.Join(flows, " or the ") +
+			" and send it as a bearer token in the Authorization header"
+	case "openIdConnect":
+		summary := "Sign in with OpenID Connect"
+		if scheme.OpenIDConnectURL != "" {
+			summary += " (discovery at " + scheme.OpenIDConnectURL + ")"
+		}
+		return summary + " and send the token as a bearer token in the Authorization header"
+	case "mutualTLS":
+		return "Present a client TLS certificate when connecting"
+	}
+	return fmt.Sprintf("Use HTTP %s authentication in the Authorization header", strings.ToUpper(scheme.Type[:1])+scheme.Type[1:])
+}
+
+// apiKeyLocation describes where an API key goes, like "in the X-Api-Key header"
+func apiKeyLocation(scheme models.AuthScheme) string {
+	switch {
+	case scheme.Param == "":
+		return "with each request"
+	case scheme.In == "query":
+		return "in the " + scheme.Param + " query parameter"
+	case scheme.In == "cookie":
+		return "in the " + scheme.Param + " cookie"
+	}
+	return "in the " + scheme.Param + " header"
+}
+
+// flowName returns the readable name of an OAuth2 flow
+func flowName(flow string) string {
+	if name, ok := flowNames[flow]; ok {
+		return name
+	}
+	return flow
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"testing"
+)
+
+// TestJSONParserAuthSchemes tests summarizing how to authenticate with the security schemes of a spec
+func TestJSONParserAuthSchemes(t *testing.T) {
+	spec := `{
+		"openapi": "3.0.0",
+		"info": {"title": "Orders API", "version": "1.0"},
+		"paths": {},
+		"components": {"securitySchemes": {
+			"apiKey": {"type": "apiKey", "in": "header", "name": "X-Api-Key"},
+			"bearer": {"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			"oauth": {"type": "oauth2", "flows": {"clientCredentials": {"tokenUrl": "https://auth.example.com/token", "scopes": {"orders:write": "", "orders:read": ""}}}}
+		}}
+	}`
+
+	apiDoc, err := (&JSONParser{}).Parse([]byte(spec))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+
+	expected := []string{
+		"Send an API key in the X-Api-Key header",
+		"Send a bearer token (JWT) in the Authorization header: Authorization: Bearer <token>",
+		"Get an OAuth2 access token with the client credentials flow (scopes: orders:read, orders:write) and send it as a bearer token in the Authorization header",
+	}
+	if len(apiDoc.AuthSchemes) != len(expected) {
+		t.Fatalf("Expected %d auth schemes, got %+v", len(expected), apiDoc.AuthSchemes)
+	}
+	for i, summary := range expected {
+		if apiDoc.AuthSchemes[i].Summary != summary {
+			t.Errorf("Expected summary %q, got %q", summary, apiDoc.AuthSchemes[i].Summary)
+		}
+	}
+	if flows := apiDoc.AuthSchemes[2].Flows; len(flows) != 1 || flows[0].TokenURL != "https://auth.example.com/token" {
+		t.Errorf("Expected the token URL of the client credentials flow, got %+v", flows)
+	}
+}
+
+// TestSwaggerAuthSchemes tests reading the OAuth2 flows, with their token URLs, and API keys of Swagger 2.0 specs
+func TestSwaggerAuthSchemes(t *testing.T) {
+	schemes := authSchemes(map[string]SecurityScheme{
+		"key":   {Type: "apiKey", In: "query", Name: "api_key"},
+		"oauth": {Type: "oauth2", Flow: "application", TokenURL: "https://auth.example.com/token", Scopes: map[string]string{"read": "Read access"}},
+	})
+
+	if len(schemes) != 2 || schemes[0].Summary != "Send an API key in the api_key query parameter" {
+		t.Fatalf("Expected the API key scheme first, got %+v", schemes)
+	}
+	if flows := schemes[1].Flows; len(flows) != 1 || flows[0].Flow != "clientCredentials" || flows[0].TokenURL != "https://auth.example.com/token" || len(flows[0].Scopes) != 1 {
+		t.Errorf("Expected the application flow as clientCredentials, got %+v", flows)
+	}
+}
+
+// TestHTMLParserAuthSchemes tests summarizing how to authenticate with the headers carrying credentials of a page
+func TestHTMLParserAuthSchemes(t *testing.T) {
+	page := `<html><head><title>Orders API</title></head><body>
+<h2>GET /v1/orders</h2>
+<pre>curl https://api.example.com/v1/orders -H "Authorization: Bearer sk_test_123"</pre>
+</body></html>`
+
+	apiDoc, err := (&HTMLParser{}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse page: %v", err)
+	}
+	if len(apiDoc.AuthSchemes) != 1 || apiDoc.AuthSchemes[0].Type != "bearer" {
+		t.Fatalf("Expected a bearer auth scheme, got %+v", apiDoc.AuthSchemes)
+	}
+}
@@ -0,0 +1,406 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"universal_api/internal/models"
+)
+
+// ExampleSourceDocs marks examples taken from the code samples of scraped documentation
+const ExampleSourceDocs = "docs"
+
+// curlRequest is a request described by a curl command
+type curlRequest struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    string // multipart/form-data body of -F fields, when the command has them
+}
+
+// parseCurl parses a curl command, as found in code samples. It reports false for text that isn't one.
+func parseCurl(text string) (*curlRequest, bool) {
+	text = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), "$"))
+	if !strings.HasPrefix(text, "curl ") {
+		return nil, false
+	}
+
+	args := shellWords(text)
+	request := &curlRequest{Headers: make(map[string]string)}
+	form := make(map[string]interface{})
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		value := func() string {
+			if i+1 < len(args) {
+				i++
+				return args[i]
+			}
+			return ""
+		}
+
+		switch {
+		case arg == "-X" || arg == "--request":
+			request.Method = strings.ToUpper(value())
+		case strings.HasPrefix(arg, "-X") && len(arg) > 2:
+			request.Method = strings.ToUpper(arg[2:])
+		case arg == "-H" || arg == "--header":
+			if name, headerValue, ok := strings.Cut(value(), ":"); ok {
+				request.Headers[strings.TrimSpace(name)] = strings.TrimSpace(headerValue)
+			}
+		case arg == "-d" || arg == "--data" || arg == "--data-raw" || arg == "--data-binary" || arg == "--data-urlencode":
+			// Bodies read from files (-d @body.json) aren't in the sample
+			if data := value(); !strings.HasPrefix(data, "@") {
+				if request.Body != "" {
+					request.Body += "&"
+				}
+				request.Body += data
+			}
+		case arg == "--json":
+			request.Body = value()
+			request.Headers["Content-Type"] = "application/json"
+		case arg == "-F" || arg == "--form":
+			if name, fieldValue, ok := strings.Cut(value(), "="); ok {
+				form[name] = fieldValue
+			}
+		case arg == "-u" || arg == "--user" || arg == "-o" || arg == "--output" || arg == "-A" || arg == "--user-agent" ||
+			arg == "-b" || arg == "--cookie" || arg == "-e" || arg == "--referer":
+			value()
+		case strings.HasPrefix(arg, "-"):
+			// Flags without values, like -s, -i, or -L
+		case request.URL == "":
+			request.URL = arg
+		}
+	}
+
+	if request.URL == "" {
+		return nil, false
+	}
+	if len(form) > 0 && request.Body == "" {
+		request.Body = curlFormBody(form)
+		if !strings.HasPrefix(requestContentType(request.Body, request.Headers), contentTypeMultipart) {
+			request.Headers["Content-Type"] = contentTypeMultipart
+		}
+	}
+	if request.Method == "" {
+		request.Method = "GET"
+		if request.Body != "" {
+			request.Method = "POST"
+		}
+	}
+	return request, true
+}
+
+// curlFormBody writes the -F fields of a curl command as a multipart/form-data body. Fields read from
+// files (-F file=@photo.jpg) are file parts, and fields read from files as text (-F note=<note.txt) get a placeholder.
+func curlFormBody(form map[string]interface{}) string {
+	filenames := make(map[string]string)
+	for name, value := range form {
+		switch text := value.(string); {
+		case strings.HasPrefix(text, "@"):
+			filename, _, _ := strings.Cut(text[1:], ";")
+			filenames[name] = filename
+		case strings.HasPrefix(text, "<"):
+			form[name] = filePlaceholder
+		}
+	}
+	return multipartExample(form, filenames)
+}
+
+// shellWords splits a command into words, honoring quotes, escapes, and line continuations
+func shellWords(command string) []string {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	quote := rune(0)
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == '\'':
+			word.WriteRune(r)
+		case r == '\\' && i+1 < len(runes):
+			i++
+			if runes[i] != '\n' && runes[i] != '\r' {
+				word.WriteRune(runes[i])
+				inWord = true
+			}
+		case quote != 0:
+			word.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// curlPath returns the path and query of a curl request's URL, which may be absolute or relative
+func curlPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Path == "" && u.Host == "" {
+		return rawURL
+	}
+	path := u.EscapedPath()
+	if decoded, err := url.PathUnescape(path); err == nil {
+		path = decoded
+	}
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return path
+}
+
+// curlEndpoint creates an endpoint from a curl command, with the request as an example
+func curlEndpoint(request *curlRequest) models.Endpoint {
+	endpoint := models.Endpoint{
+		Kind:       models.EndpointRequest,
+		Path:       curlPath(request.URL),
+		Method:     request.Method,
+		Summary:    request.Method + " " + curlPath(request.URL),
+		Parameters: []models.Parameter{},
+		Responses:  []models.Response{{StatusCode: 200, Description: "OK"}},
+	}
+	addRequestExample(&endpoint, compactJSON(request.Body), request.Headers)
+	addHeaderParameters(&endpoint, request.Headers)
+	for name, value := range request.Headers {
+		if strings.EqualFold(name, "Accept") && strings.Contains(value, eventStreamType) {
+			endpoint.Protocol = models.ProtocolSSE
+		}
+	}
+	return endpoint
+}
+
+// addRequestExample records a documented request body as an example of an endpoint and infers the
+// body parameters from the fields of a JSON object body
+func addRequestExample(endpoint *models.Endpoint, body string, headers map[string]string) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return
+	}
+	for _, example := range endpoint.Examples {
+		if example.Source == ExampleSourceDocs && example.Request.Body == body {
+			return
+		}
+	}
+
+	path := endpoint.Path
+	query := ""
+	if i := strings.Index(path, "?"); i >= 0 {
+		path, query = path[:i], path[i+1:]
+	}
+	if len(headers) == 0 {
+		headers = nil
+	}
+	endpoint.Examples = append(endpoint.Examples, models.Example{
+		Source:  ExampleSourceDocs,
+		Request: models.ExampleRequest{Method: endpoint.Method, Path: path, Query: query, Headers: headers, Body: body},
+	})
+	addRequestBody(endpoint, models.RequestBody{ContentType: requestContentType(body, headers), Example: body})
+
+	if strings.HasPrefix(requestContentType(body, headers), contentTypeMultipart) {
+		addMultipartParameters(endpoint, body)
+		return
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &fields); err != nil {
+		return
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if findParameter(endpoint.Parameters, models.Parameter{Name: name, In: "body"}) >= 0 {
+			continue
+		}
+		endpoint.Parameters = append(endpoint.Parameters, models.Parameter{
+			Name:    name,
+			In:      "body",
+			Type:    jsonType(fields[name]),
+			Example: jsonExample(fields[name]),
+		})
+	}
+}
+
+// addMultipartParameters infers the body parameters of an endpoint from the parts of a documented
+// multipart/form-data body: file parts are files, and other parts strings with their value as example
+func addMultipartParameters(endpoint *models.Endpoint, body string) {
+	reader := multipart.NewReader(strings.NewReader(body), exampleBoundary)
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return
+		}
+		param := models.Parameter{Name: part.FormName(), In: "body", Type: "string"}
+		if part.FileName() != "" {
+			param.Type = "file"
+		} else if value, err := io.ReadAll(part); err == nil {
+			param.Example = string(value)
+		}
+		if param.Name != "" && findParameter(endpoint.Parameters, param) < 0 {
+			endpoint.Parameters = append(endpoint.Parameters, param)
+		}
+	}
+}
+
+// requestContentType returns the content type of a documented request body: that of its Content-Type header,
+// or the one inferred from the body, where bodies of name=value fields are form-encoded
+func requestContentType(body string, headers map[string]string) string {
+	for name, value := range headers {
+		if strings.EqualFold(name, "Content-Type") && value != "" {
+			return value
+		}
+	}
+	if contentType := exampleContentType(body); contentType != "" {
+		return contentType
+	}
+	if strings.Contains(body, "=") && !strings.ContainsAny(body, " \n") {
+		return contentTypeForm
+	}
+	return "text/plain"
+}
+
+// addRequestBody adds a request body to an endpoint, unless it has one in the content type already
+func addRequestBody(endpoint *models.Endpoint, body models.RequestBody) {
+	if findRequestBody(endpoint.RequestBodies, body.ContentType) < 0 {
+		endpoint.RequestBodies = append(endpoint.RequestBodies, body)
+	}
+}
+
+// findRequestBody returns the index of the request body in a content type, or -1
+func findRequestBody(bodies []models.RequestBody, contentType string) int {
+	for i, body := range bodies {
+		if strings.EqualFold(body.ContentType, contentType) {
+			return i
+		}
+	}
+	return -1
+}
+
+// jsonType returns the JSON schema type of a decoded JSON value
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "string"
+}
+
+// jsonExample returns a decoded JSON value as an example: strings as they are, other values as compact JSON
+func jsonExample(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// isJSONBody checks if a code block holds a JSON object or array
+func isJSONBody(text string) bool {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "{") && !strings.HasPrefix(text, "[") {
+		return false
+	}
+	return json.Valid([]byte(text))
+}
+
+// compactJSON removes the insignificant whitespace of a JSON body, leaving other bodies as they are
+func compactJSON(body string) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(strings.TrimSpace(body))); err != nil {
+		return strings.TrimSpace(body)
+	}
+	return buf.String()
+}
+
+// addSectionRequestExamples captures the request bodies documented in the code blocks of an
+// endpoint's section: curl commands for the endpoint and, for methods with a body, JSON blocks
+// that aren't labelled as responses
+func addSectionRequestExamples(endpoint *models.Endpoint, section *goquery.Selection, rules *Rules) {
+	blocks := section.Filter("pre, code").AddSelection(section.Find("pre, code"))
+	blocks.Each(func(i int, block *goquery.Selection) {
+		// Code inside pre is part of the pre block
+		if goquery.NodeName(block) == "code" && block.ParentsFiltered("pre").Length() > 0 {
+			return
+		}
+
+		text := block.Text()
+		if request, ok := parseCurl(text); ok {
+			if request.Method == strings.ToUpper(endpoint.Method) {
+				addRequestExample(endpoint, compactJSON(request.Body), request.Headers)
+				addHeaderParameters(endpoint, request.Headers)
+			}
+			return
+		}
+
+		switch strings.ToUpper(endpoint.Method) {
+		case "POST", "PUT", "PATCH":
+			if isJSONBody(text) && !rules.isResponseLabel(label(block)) {
+				addRequestExample(endpoint, compactJSON(text), nil)
+			}
+		}
+	})
+}
+
+// maxLabelLength bounds the text before an element that counts as its label, so paragraphs don't
+const maxLabelLength = 80
+
+// label returns the lowercased text of the heading or caption before an element, or before its
+// parent when it's the first child, or "" if that text is too long to be a label
+func label(element *goquery.Selection) string {
+	before := element.Prev()
+	if before.Length() == 0 && element.Parent().Length() > 0 {
+		before = element.Parent().Prev()
+	}
+	text := strings.TrimSpace(before.Text())
+	if len([]rune(text)) > maxLabelLength {
+		return ""
+	}
+	return strings.ToLower(text)
+}
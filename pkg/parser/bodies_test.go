@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestParseCurl tests parsing curl commands from code samples
+func TestParseCurl(t *testing.T) {
+	request, ok := parseCurl(`$ curl -s https://api.example.com/v1/orders \
+  -H "Authorization: Bearer sk_test_123" \
+  -H 'Content-Type: application/json' \
+  -d '{"amount": 100, "currency": "usd"}'`)
+	if !ok {
+		t.Fatalf("Expected a curl command")
+	}
+	if request.Method != "POST" || request.URL != "https://api.example.com/v1/orders" {
+		t.Errorf("Expected POST https://api.example.com/v1/orders, got %s %s", request.Method, request.URL)
+	}
+	if request.Headers["Authorization"] != "Bearer sk_test_123" || request.Headers["Content-Type"] != "application/json" {
+		t.Errorf("Expected the headers of the command, got %v", request.Headers)
+	}
+	if request.Body != `{"amount": 100, "currency": "usd"}` {
+		t.Errorf("Expected the body of the command, got %q", request.Body)
+	}
+
+	request, ok = parseCurl(`curl -XDELETE "https://api.example.com/v1/orders/42"`)
+	if !ok || request.Method != "DELETE" || curlPath(request.URL) != "/v1/orders/42" {
+		t.Errorf("Expected DELETE /v1/orders/42, got %+v", request)
+	}
+
+	if _, ok := parseCurl("GET /v1/orders"); ok {
+		t.Errorf("Expected a request line not to be a curl command")
+	}
+}
+
+// TestHTMLParserRequestExamples tests capturing request bodies from curl samples and JSON blocks
+func TestHTMLParserRequestExamples(t *testing.T) {
+	page := `<html><head><title>Orders API</title></head><body>
+<h2>POST /v1/orders</h2>
+<p>Create an order</p>
+<pre>{
+  "amount": 100,
+  "currency": "usd",
+  "capture": true
+}</pre>
+<p>Response</p>
+<pre>{"id": "ord_1", "status": "pending"}</pre>
+<h2>PUT /v1/orders/:id</h2>
+<p>Update an order</p>
+<pre>curl -X PUT https://api.example.com/v1/orders/ord_1 -d '{"amount": 250.5, "metadata": {"ref": "A1"}}'</pre>
+</body></html>`
+
+	apiDoc, err := (&HTMLParser{}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	create := findEndpoint(apiDoc.Endpoints, "POST", "/v1/orders")
+	if create == nil {
+		t.Fatalf("POST /v1/orders endpoint not found in %v", apiDoc.Endpoints)
+	}
+	if len(create.Examples) != 1 || create.Examples[0].Source != ExampleSourceDocs ||
+		create.Examples[0].Request.Body != `{"amount":100,"currency":"usd","capture":true}` {
+		t.Errorf("Expected the request body example only, got %+v", create.Examples)
+	}
+	expected := map[string]string{"amount": "integer", "capture": "boolean", "currency": "string"}
+	if len(create.Parameters) != len(expected) {
+		t.Fatalf("Expected %d body parameters, got %v", len(expected), create.Parameters)
+	}
+	for _, param := range create.Parameters {
+		if param.In != "body" || expected[param.Name] != param.Type {
+			t.Errorf("Unexpected body parameter %+v", param)
+		}
+	}
+
+	update := findEndpoint(apiDoc.Endpoints, "PUT", "/v1/orders/{id}")
+	if update == nil {
+		t.Fatalf("PUT /v1/orders/{id} endpoint not found in %v", apiDoc.Endpoints)
+	}
+	if len(update.Examples) != 1 || update.Examples[0].Request.Body != `{"amount":250.5,"metadata":{"ref":"A1"}}` {
+		t.Errorf("Expected the curl body example, got %+v", update.Examples)
+	}
+	if i := findParameter(update.Parameters, models.Parameter{Name: "amount", In: "body"}); i < 0 || update.Parameters[i].Type != "number" {
+		t.Errorf("Expected a number amount body parameter, got %v", update.Parameters)
+	}
+}
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"universal_api/internal/models"
+)
+
+// describedHeaders are headers described by other parts of an endpoint, like its content types,
+// rather than by header parameters
+var describedHeaders = map[string]bool{
+	"Accept": true, "Content-Type": true, "Content-Length": true, "Host": true, "User-Agent": true,
+}
+
+// authHeaderHints are the parts of header names that carry credentials, like X-Api-Key or X-Auth-Token
+var authHeaderHints = []string{"api-key", "apikey", "api_key", "auth", "token", "secret", "access-key", "signature"}
+
+// headerAuth returns the auth type of a header that carries credentials (bearer, basic, or apiKey),
+// or "" for other headers
+func headerAuth(name, value string) string {
+	name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+	if name == "Authorization" || name == "Proxy-Authorization" {
+		scheme, _, _ := strings.Cut(strings.TrimSpace(value), " ")
+		switch strings.ToLower(scheme) {
+		case "bearer":
+			return "bearer"
+		case "basic":
+			return "basic"
+		}
+		return "apiKey"
+	}
+
+	lower := strings.ToLower(name)
+	for _, hint := range authHeaderHints {
+		if strings.Contains(lower, hint) {
+			return "apiKey"
+		}
+	}
+	return ""
+}
+
+// addHeaderParameters adds the headers of a documented request to an endpoint's header parameters.
+// Headers carrying credentials are marked with their auth type and their values aren't kept.
+func addHeaderParameters(endpoint *models.Endpoint, headers map[string]string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		canonical := http.CanonicalHeaderKey(name)
+		if describedHeaders[canonical] || findHeaderParameter(endpoint.Parameters, canonical) >= 0 {
+			continue
+		}
+
+		param := models.Parameter{Name: canonical, In: "header", Type: "string", Auth: headerAuth(canonical, headers[name])}
+		if param.Auth == "" {
+			param.Example = headers[name]
+		} else {
+			param.Required = true
+		}
+		endpoint.Parameters = append(endpoint.Parameters, param)
+	}
+}
+
+// findHeaderParameter returns the index of the header parameter with a name, which is case-insensitive, or -1
+func findHeaderParameter(params []models.Parameter, name string) int {
+	for i, param := range params {
+		if param.In == "header" && strings.EqualFold(param.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isHeaderTable checks if a table lists headers: it's labelled as headers, or its first column is titled Header
+func isHeaderTable(table *goquery.Selection) bool {
+	if strings.Contains(label(table), "header") {
+		return true
+	}
+	first := strings.ToLower(strings.TrimSpace(table.Find("tr").First().Find("th, td").First().Text()))
+	return strings.HasPrefix(first, "header")
+}
+
+// docAuthTypes returns the sorted, distinct auth types of a doc: those it declares and those of its header parameters
+func docAuthTypes(doc *models.APIDoc) []string {
+	seen := make(map[string]bool)
+	for _, authType := range doc.AuthTypes {
+		seen[authType] = true
+	}
+	for _, endpoint := range doc.Endpoints {
+		// Headers of requests the API sends, like webhook signatures, don't tell how to call it
+		if endpoint.Incoming() {
+			continue
+		}
+		for _, param := range endpoint.Parameters {
+			if param.Auth != "" {
+				seen[param.Auth] = true
+			}
+		}
+	}
+
+	if len(seen) == 0 {
+		return nil
+	}
+	types := make([]string, 0, len(seen))
+	for authType := range seen {
+		types = append(types, authType)
+	}
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestHeaderAuth tests recognizing headers that carry credentials
+func TestHeaderAuth(t *testing.T) {
+	tests := []struct {
+		name, value, expected string
+	}{
+		{"Authorization", "Bearer sk_test_123", "bearer"},
+		{"authorization", "Basic dXNlcjpwYXNz", "basic"},
+		{"Authorization", "", "apiKey"},
+		{"X-Api-Key", "abc", "apiKey"},
+		{"X-Auth-Token", "", "apiKey"},
+		{"Idempotency-Key", "key-1", ""},
+		{"X-Request-Id", "42", ""},
+	}
+
+	for _, test := range tests {
+		if got := headerAuth(test.name, test.value); got != test.expected {
+			t.Errorf("headerAuth(%q, %q): expected %q, got %q", test.name, test.value, test.expected, got)
+		}
+	}
+}
+
+// TestHTMLParserHeaderParameters tests extracting header parameters from curl samples and header tables
+func TestHTMLParserHeaderParameters(t *testing.T) {
+	page := `<html><head><title>Orders API</title></head><body>
+<h2>GET /v1/orders</h2>
+<p>List orders</p>
+<p><strong>Headers</strong></p>
+<table><tr><th>Name</th><th>Description</th></tr>
+<tr><td>x-api-key</td><td>Your API key</td></tr>
+<tr><td>X-Request-Id</td><td>Traces the request</td></tr></table>
+<pre>curl https://api.example.com/v1/orders -H "X-Api-Key: sk_live_secret" -H "Accept: application/json" -H "Stripe-Version: 2024-01-01"</pre>
+</body></html>`
+
+	apiDoc, err := (&HTMLParser{}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	endpoint := findEndpoint(apiDoc.Endpoints, "GET", "/v1/orders")
+	if endpoint == nil {
+		t.Fatalf("GET /v1/orders endpoint not found in %v", apiDoc.Endpoints)
+	}
+
+	expected := []models.Parameter{
+		{Name: "x-api-key", In: "header", Required: true, Type: "string", Description: "Your API key", Auth: "apiKey"},
+		{Name: "X-Request-Id", In: "header", Type: "string", Description: "Traces the request"},
+		{Name: "Stripe-Version", In: "header", Type: "string", Example: "2024-01-01"},
+	}
+	if len(endpoint.Parameters) != len(expected) {
+		t.Fatalf("Expected %d header parameters, got %+v", len(expected), endpoint.Parameters)
+	}
+	for i, param := range expected {
+		if endpoint.Parameters[i] != param {
+			t.Errorf("Expected parameter %+v, got %+v", param, endpoint.Parameters[i])
+		}
+	}
+
+	if len(apiDoc.AuthTypes) != 1 || apiDoc.AuthTypes[0] != "apiKey" {
+		t.Errorf("Expected the apiKey auth type, got %v", apiDoc.AuthTypes)
+	}
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// iconLinkRels are the rel values of <link> elements pointing to a site's icon, best first: touch
+// icons are larger than favicons and look better in listings
+var iconLinkRels = []string{"apple-touch-icon", "apple-touch-icon-precomposed", "icon"}
+
+// PageIcon returns the href of an HTML page's icon, as written in the page, or "" when the page
+// declares none
+func PageIcon(content []byte) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(content)))
+	if err != nil {
+		return ""
+	}
+	return pageIcon(doc)
+}
+
+// pageIcon returns the href of a page's touch icon or favicon, falling back to its og:image. Inline
+// data: icons are skipped; they'd bloat every listing of the doc.
+func pageIcon(doc *goquery.Document) string {
+	for _, rel := range iconLinkRels {
+		icon := ""
+		doc.Find("link[rel][href]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+			if !containsRel(s.AttrOr("rel", ""), rel) {
+				return true
+			}
+			icon = usableIcon(s.AttrOr("href", ""))
+			return icon == ""
+		})
+		if icon != "" {
+			return icon
+		}
+	}
+	icon := ""
+	doc.Find(`meta[property="og:image"], meta[name="og:image"]`).EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		icon = usableIcon(s.AttrOr("content", ""))
+		return icon == ""
+	})
+	return icon
+}
+
+// containsRel checks if a space-separated rel attribute contains a value, ignoring case
+func containsRel(rels, rel string) bool {
+	for _, value := range strings.Fields(strings.ToLower(rels)) {
+		if value == rel {
+			return true
+		}
+	}
+	return false
+}
+
+// usableIcon trims an icon reference, returning "" for inline data: icons
+func usableIcon(href string) string {
+	href = strings.TrimSpace(href)
+	if strings.HasPrefix(strings.ToLower(href), "data:") {
+		return ""
+	}
+	return href
+}
@@ -0,0 +1,37 @@
+package parser
+
+import "testing"
+
+// TestPageIcon tests preferring touch icons to favicons and favicons to og:image
+func TestPageIcon(t *testing.T) {
+	tests := []struct {
+		name     string
+		head     string
+		expected string
+	}{
+		{"touch icon", `<link rel="shortcut icon" href="/favicon.ico"><link rel="apple-touch-icon" href="/touch.png">`, "/touch.png"},
+		{"favicon", `<meta property="og:image" content="/card.png"><link rel="Shortcut Icon" href=" /favicon.ico ">`, "/favicon.ico"},
+		{"og:image", `<link rel="icon" href="data:image/png;base64,AAAA"><meta property="og:image" content="https://cdn.example.com/card.png">`, "https://cdn.example.com/card.png"},
+		{"none", `<link rel="stylesheet" href="/style.css">`, ""},
+	}
+	for _, test := range tests {
+		page := "<html><head>" + test.head + "</head><body></body></html>"
+		if icon := PageIcon([]byte(page)); icon != test.expected {
+			t.Errorf("%s: expected icon %q, got %q", test.name, test.expected, icon)
+		}
+	}
+}
+
+// TestJSONParserLogo tests reading the provider's logo from the x-logo extension
+func TestJSONParserLogo(t *testing.T) {
+	spec := `{"openapi": "3.0.0", "info": {"title": "Pets", "version": "1.0.0",
+		"x-logo": {"url": "https://example.com/logo.svg", "altText": "Pets"}}, "paths": {}}`
+
+	apiDoc, err := (&JSONParser{}).Parse([]byte(spec))
+	if err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if apiDoc.IconURL != "https://example.com/logo.svg" {
+		t.Errorf("Expected the x-logo URL, got %q", apiDoc.IconURL)
+	}
+}
@@ -0,0 +1,72 @@
+package parser
+
+import "testing"
+
+// TestContainsEndpointIndicators tests telling endpoint headings from other headings of API docs
+func TestContainsEndpointIndicators(t *testing.T) {
+	tests := map[string]bool{
+		"GET /users":                              true,
+		"Create a user: `/users`":                 true,
+		"DELETE https://api.example.com/v1/items": true,
+		"POST":                     true,
+		"API Overview":             false,
+		"Making a request":         false,
+		"GET Parameters":           false,
+		"Request Body":             false,
+		"Get started with the API": false,
+		"Input and OUTPUT":         false,
+		"Dates and/or times":       false,
+	}
+
+	for text, expected := range tests {
+		if got := DefaultRules().containsEndpointIndicators(text); got != expected {
+			t.Errorf("containsEndpointIndicators(%q): expected %v, got %v", text, expected, got)
+		}
+	}
+}
+
+// TestExtractMethodAndPath tests finding the method and path of a heading by whole words
+func TestExtractMethodAndPath(t *testing.T) {
+	tests := map[string][2]string{
+		"GET /users/{id}":                     {"GET", "/users/{id}"},
+		"Update an item (`PATCH /items/:id`)": {"PATCH", "/items/:id"},
+		"OUTPUT of /reports":                  {"GET", "/reports"},
+		"POST https://api.example.com/orders": {"POST", "/orders"},
+		"DELETE":                              {"DELETE", "Unknown"},
+	}
+
+	for text, expected := range tests {
+		method, path := DefaultRules().extractMethodAndPath(text)
+		if method != expected[0] || path != expected[1] {
+			t.Errorf("extractMethodAndPath(%q): expected %s %s, got %s %s", text, expected[0], expected[1], method, path)
+		}
+	}
+}
+
+// TestHTMLParserDropsUnknownPaths tests that endpoints without a path are dropped unless configured otherwise
+func TestHTMLParserDropsUnknownPaths(t *testing.T) {
+	page := `<html><head><title>Items API</title></head><body>
+<h2>API Overview</h2>
+<p>Make a request to the API.</p>
+<h2>DELETE</h2>
+<p>Deletes everything</p>
+<h2>GET /items</h2>
+<p>List items</p>
+</body></html>`
+
+	apiDoc, err := (&HTMLParser{}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	if len(apiDoc.Endpoints) != 1 || apiDoc.Endpoints[0].Path != "/items" {
+		t.Errorf("Expected only GET /items, got %v", apiDoc.Endpoints)
+	}
+
+	apiDoc, err = (&HTMLParser{KeepUnknownPaths: true}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	if len(apiDoc.Endpoints) != 2 || findEndpoint(apiDoc.Endpoints, "DELETE", "Unknown") == nil {
+		t.Errorf("Expected GET /items and DELETE Unknown, got %v", apiDoc.Endpoints)
+	}
+}
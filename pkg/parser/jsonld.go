@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// structuredDataTypes are the schema.org types of JSON-LD blocks that describe an API
+var structuredDataTypes = []string{"WebAPI", "APIReference"}
+
+// structuredData is the metadata of an API given by the JSON-LD blocks of its doc page
+type structuredData struct {
+	Title       string
+	Description string
+	Provider    string
+	Version     string
+}
+
+// pageStructuredData reads the metadata of the schema.org WebAPI and APIReference items in a page's
+// JSON-LD blocks. Items found first win; later ones fill in what they leave out. Blocks that
+// aren't valid JSON are skipped.
+func pageStructuredData(doc *goquery.Document) structuredData {
+	var data structuredData
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, script *goquery.Selection) {
+		var value interface{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(script.Text())), &value); err != nil {
+			return
+		}
+		for _, item := range jsonLDItems(value) {
+			if !isStructuredDataType(item["@type"]) {
+				continue
+			}
+			fill(&data.Title, jsonLDText(item, "name", "headline"))
+			fill(&data.Description, jsonLDText(item, "description", "abstract"))
+			fill(&data.Provider, jsonLDText(item, "provider", "publisher", "author"))
+			fill(&data.Version, jsonLDText(item, "version", "softwareVersion", "assemblyVersion"))
+		}
+	})
+	return data
+}
+
+// jsonLDItems returns the objects of a JSON-LD value: the value itself, the items of an array, and
+// the items of an @graph, in order
+func jsonLDItems(value interface{}) []map[string]interface{} {
+	var items []map[string]interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		for _, element := range v {
+			items = append(items, jsonLDItems(element)...)
+		}
+	case map[string]interface{}:
+		items = append(items, v)
+		if graph, ok := v["@graph"]; ok {
+			items = append(items, jsonLDItems(graph)...)
+		}
+	}
+	return items
+}
+
+// isStructuredDataType checks if an @type, a name or a list of names, is a type that describes an
+// API. Types may be prefixed, like schema:WebAPI or https://schema.org/WebAPI.
+func isStructuredDataType(value interface{}) bool {
+	var types []string
+	switch v := value.(type) {
+	case string:
+		types = []string{v}
+	case []interface{}:
+		for _, element := range v {
+			if name, ok := element.(string); ok {
+				types = append(types, name)
+			}
+		}
+	}
+	for _, name := range types {
+		if i := strings.LastIndexAny(name, "/:#"); i >= 0 {
+			name = name[i+1:]
+		}
+		if containsString(structuredDataTypes, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonLDText returns the text of the first of an item's properties that has one. A property can be
+// text, a number, an object with a name, like an Organization, or a list of those.
+func jsonLDText(item map[string]interface{}, properties ...string) string {
+	for _, property := range properties {
+		if text := jsonLDValueText(item[property]); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// jsonLDValueText returns the text of a JSON-LD value, or "" if it has none
+func jsonLDValueText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return jsonExample(v)
+	case map[string]interface{}:
+		if text := jsonLDValueText(v["name"]); text != "" {
+			return text
+		}
+		return jsonLDValueText(v["@value"])
+	case []interface{}:
+		for _, element := range v {
+			if text := jsonLDValueText(element); text != "" {
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+// fill sets a field that is still empty
+func fill(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
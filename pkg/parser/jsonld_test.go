@@ -0,0 +1,45 @@
+package parser
+
+import "testing"
+
+// TestHTMLParserStructuredData tests that JSON-LD metadata wins over the title tag and first paragraph
+func TestHTMLParserStructuredData(t *testing.T) {
+	page := `<html><head><title>Docs | Example</title>
+<meta name="description" content="Example docs">
+<script type="application/ld+json">{"@context": "https://schema.org", "@type": "BreadcrumbList", "name": "Home"}</script>
+<script type="application/ld+json">not json</script>
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@graph": [
+  {"@type": "WebPage", "name": "Docs"},
+  {"@type": ["Thing", "schema:WebAPI"], "name": "Payments API", "description": "Take payments",
+   "provider": {"@type": "Organization", "name": "Example Inc"}},
+  {"@type": "APIReference", "name": "Payments reference", "softwareVersion": 2.1}
+]}
+</script>
+</head><body><p>Welcome to the docs</p></body></html>`
+
+	apiDoc, err := (&HTMLParser{}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	if apiDoc.Title != "Payments API" || apiDoc.Description != "Take payments" {
+		t.Errorf("Expected the WebAPI's title and description, got %q and %q", apiDoc.Title, apiDoc.Description)
+	}
+	if apiDoc.Provider != "Example Inc" || apiDoc.Version != "2.1" {
+		t.Errorf("Expected the provider's name and the reference's version, got %q and %q", apiDoc.Provider, apiDoc.Version)
+	}
+}
+
+// TestHTMLParserWithoutStructuredData tests falling back to the title tag and meta description
+func TestHTMLParserWithoutStructuredData(t *testing.T) {
+	page := `<html><head><title>Docs</title><meta name="description" content="Example docs"></head><body></body></html>`
+
+	apiDoc, err := (&HTMLParser{}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	if apiDoc.Title != "Docs" || apiDoc.Description != "Example docs" || apiDoc.Version != "Unknown" || apiDoc.Provider != "" {
+		t.Errorf("Expected the title tag and meta description, got %+v", apiDoc)
+	}
+}
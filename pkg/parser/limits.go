@@ -0,0 +1,274 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"universal_api/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Sources of rate limits
+const (
+	LimitSourceSpec = "spec" // x-ratelimit extensions of a spec
+	LimitSourceText = "text" // statements in the docs, like "1000 requests per hour"
+)
+
+// maxStatementLength bounds the statements rate limits and SLAs are read from
+const maxStatementLength = 200
+
+// rateStatement matches rate limits like "1,000 requests per hour" or "rate limited to 100 per minute"
+var rateStatement = regexp.MustCompile(`(?i)(?:\b(\d{1,3}(?:,\d{3})+|\d+)\s*(?:api\s+)?(?:requests?|calls?|reqs?)|\blimit(?:ed)?\s+(?:of|to)\s+(\d{1,3}(?:,\d{3})+|\d+)(?:\s*(?:requests?|calls?))?)(?:\s*/\s*|\s+(?:per|an?|every|each)\s+)(second|sec|s|minute|min|m|hour|hr|h|day|d|month)\b`)
+
+// uptimeStatement matches uptime commitments like "99.9% uptime" or "an availability SLA of 99.95%"
+var uptimeStatement = regexp.MustCompile(`(?i)\b(\d{2,3}(?:\.\d+)?)\s?%\s*(?:monthly\s+)?(?:uptime|availability)|\b(?:uptime|availability|SLA)\b[^.%\d]{0,40}?(\d{2,3}(?:\.\d+)?)\s?%`)
+
+// rateLimitExtensions are the spec extensions documenting rate limits
+var rateLimitExtensions = []string{"x-ratelimit", "x-rate-limit", "x-ratelimit-limit", "x-rate-limits", "x-ratelimits"}
+
+// periods maps the units of rate limits to their periods
+var periods = map[string]string{
+	"s": "second", "sec": "second", "second": "second",
+	"m": "minute", "min": "minute", "minute": "minute",
+	"h": "hour", "hr": "hour", "hour": "hour",
+	"d": "day", "day": "day",
+	"month": "month",
+}
+
+// TextRateLimits returns the rate limits stated in text, applying to an endpoint, or to the whole API when it's ""
+func TextRateLimits(text, endpoint string) []models.RateLimit {
+	var limits []models.RateLimit
+	for _, match := range rateStatement.FindAllStringSubmatchIndex(text, -1) {
+		number := submatch(text, match, 1)
+		if number == "" {
+			number = submatch(text, match, 2)
+		}
+		requests, err := strconv.Atoi(strings.ReplaceAll(number, ",", ""))
+		if err != nil || requests == 0 {
+			continue
+		}
+		limits = append(limits, models.RateLimit{
+			Requests: requests,
+			Period:   periods[strings.ToLower(submatch(text, match, 3))],
+			Endpoint: endpoint,
+			Source:   LimitSourceText,
+			Text:     statement(text, match[0], match[1]),
+		})
+	}
+	return limits
+}
+
+// TextSLAs returns the uptime commitments stated in text
+func TextSLAs(text string) []models.SLA {
+	var slas []models.SLA
+	for _, match := range uptimeStatement.FindAllStringSubmatchIndex(text, -1) {
+		number := submatch(text, match, 1)
+		if number == "" {
+			number = submatch(text, match, 2)
+		}
+		uptime, err := strconv.ParseFloat(number, 64)
+		if err != nil || uptime < 90 || uptime > 100 {
+			continue
+		}
+		slas = append(slas, models.SLA{Uptime: uptime, Text: statement(text, match[0], match[1])})
+	}
+	return slas
+}
+
+// specRateLimits returns the rate limits of a spec's x-ratelimit extensions: those at its root and
+// in its info apply to the whole API, those of path items and operations to their endpoints
+func specRateLimits(root map[string]interface{}) []models.RateLimit {
+	limits := extensionRateLimits(root, "")
+	if info, ok := root["info"].(map[string]interface{}); ok {
+		limits = append(limits, extensionRateLimits(info, "")...)
+	}
+
+	paths, _ := root["paths"].(map[string]interface{})
+	for path, item := range paths {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for key, operation := range itemMap {
+			operationMap, ok := operation.(map[string]interface{})
+			if !ok || !isHTTPMethod(key) {
+				continue
+			}
+			endpoint := strings.ToUpper(key) + " " + path
+			limits = append(limits, extensionRateLimits(itemMap, endpoint)...)
+			limits = append(limits, extensionRateLimits(operationMap, endpoint)...)
+		}
+	}
+	return limits
+}
+
+// extensionRateLimits returns the rate limits of the x-ratelimit extensions of an object. A limit is
+// a number of requests, a string like "100/minute", an object with the number under limit, requests,
+// or rate and the period under period, window, per, or interval, or a list of these.
+func extensionRateLimits(object map[string]interface{}, endpoint string) []models.RateLimit {
+	var limits []models.RateLimit
+	for key, value := range object {
+		if !containsString(rateLimitExtensions, strings.ToLower(key)) {
+			continue
+		}
+		values, ok := value.([]interface{})
+		if !ok {
+			values = []interface{}{value}
+		}
+		for _, value := range values {
+			if limit, ok := extensionRateLimit(value); ok {
+				limit.Endpoint = endpoint
+				limits = append(limits, limit)
+			}
+		}
+	}
+	return limits
+}
+
+// extensionRateLimit converts the value of an x-ratelimit extension to a rate limit
+func extensionRateLimit(value interface{}) (models.RateLimit, bool) {
+	limit := models.RateLimit{Source: LimitSourceSpec}
+	switch value := value.(type) {
+	case float64:
+		limit.Requests = int(value)
+	case string:
+		number, unit, _ := strings.Cut(value, "/")
+		limit.Requests, _ = strconv.Atoi(strings.TrimSpace(number))
+		limit.Period = periods[strings.ToLower(strings.TrimSpace(unit))]
+		if limit.Requests == 0 {
+			if parsed := TextRateLimits(value, ""); len(parsed) > 0 {
+				limit.Requests, limit.Period = parsed[0].Requests, parsed[0].Period
+			}
+		}
+	case map[string]interface{}:
+		for _, key := range []string{"limit", "requests", "rate"} {
+			if requests, ok := value[key].(float64); ok {
+				limit.Requests = int(requests)
+				break
+			}
+		}
+		for _, key := range []string{"period", "window", "per", "interval"} {
+			if period, ok := value[key].(string); ok {
+				limit.Period = periods[strings.ToLower(strings.TrimPrefix(strings.TrimSpace(period), "1"))]
+				break
+			}
+		}
+	}
+	if limit.Requests <= 0 {
+		return limit, false
+	}
+	limit.Text = fmt.Sprintf("%d requests", limit.Requests)
+	if limit.Period != "" {
+		limit.Text += " per " + limit.Period
+	}
+	return limit, true
+}
+
+// docLimits sets the rate limits and SLAs of a doc from its spec extensions, when it has a spec,
+// and from the statements of text and its endpoint descriptions. Statements of text that are also
+// in an endpoint's description apply to the endpoint only.
+func docLimits(apiDoc *models.APIDoc, root map[string]interface{}, text string) {
+	limits := specRateLimits(root)
+	var slas []models.SLA
+	endpointStatements := make(map[string]bool)
+	for _, endpoint := range apiDoc.Endpoints {
+		description := endpoint.Summary + "\n" + endpoint.Description
+		for _, limit := range TextRateLimits(description, strings.ToUpper(endpoint.Method)+" "+endpoint.Path) {
+			limits = append(limits, limit)
+			endpointStatements[limit.Text] = true
+		}
+		slas = append(slas, TextSLAs(description)...)
+	}
+	for _, limit := range TextRateLimits(text, "") {
+		if !endpointStatements[limit.Text] {
+			limits = append(limits, limit)
+		}
+	}
+	apiDoc.RateLimits = uniqueRateLimits(limits)
+	apiDoc.SLAs = uniqueSLAs(append(TextSLAs(text), slas...))
+}
+
+// uniqueRateLimits returns the rate limits without repeats of a limit on the same endpoint, sorted by endpoint
+func uniqueRateLimits(limits []models.RateLimit) []models.RateLimit {
+	seen := make(map[string]bool)
+	var unique []models.RateLimit
+	for _, limit := range limits {
+		key := fmt.Sprintf("%s %d/%s", limit.Endpoint, limit.Requests, limit.Period)
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, limit)
+		}
+	}
+	sort.SliceStable(unique, func(i, j int) bool { return unique[i].Endpoint < unique[j].Endpoint })
+	return unique
+}
+
+// uniqueSLAs returns the SLAs without repeats of an uptime
+func uniqueSLAs(slas []models.SLA) []models.SLA {
+	seen := make(map[float64]bool)
+	var unique []models.SLA
+	for _, sla := range slas {
+		if !seen[sla.Uptime] {
+			seen[sla.Uptime] = true
+			unique = append(unique, sla)
+		}
+	}
+	return unique
+}
+
+// pageText returns the text of the paragraphs, list items, and table cells of a page, a line each
+func pageText(doc *goquery.Document) string {
+	var b strings.Builder
+	doc.Find("p, li, td, dd, blockquote").Each(func(i int, s *goquery.Selection) {
+		b.WriteString(strings.TrimSpace(s.Text()))
+		b.WriteString("\n")
+	})
+	return b.String()
+}
+
+// submatch returns a group of a match, or "" if the group didn't match
+func submatch(text string, match []int, group int) string {
+	if match[2*group] < 0 {
+		return ""
+	}
+	return text[match[2*group]:match[2*group+1]]
+}
+
+// statement returns the sentence of text around a match, shortened to maxStatementLength
+func statement(text string, start, end int) string {
+	from := 0
+	for i := start - 1; i >= 0; i-- {
+		if sentenceEnd(text, i) {
+			from = i + 1
+			break
+		}
+	}
+	to := len(text)
+	for i := end; i < len(text); i++ {
+		if sentenceEnd(text, i) {
+			to = i + 1
+			break
+		}
+	}
+	sentence := strings.Join(strings.Fields(text[from:to]), " ")
+	if len(sentence) > maxStatementLength {
+		sentence = strings.TrimSpace(text[start:end])
+	}
+	return sentence
+}
+
+// sentenceEnd checks if the byte of text at i ends a sentence: a line break, or a full stop,
+// exclamation, or question mark followed by a space, unlike the point of 99.9
+func sentenceEnd(text string, i int) bool {
+	switch text[i] {
+	case '\n':
+		return true
+	case '.', '!', '?':
+		return i+1 == len(text) || text[i+1] == ' ' || text[i+1] == '\n'
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"testing"
+)
+
+// TestTextRateLimits tests reading rate limits from statements in text
+func TestTextRateLimits(t *testing.T) {
+	tests := []struct {
+		text     string
+		requests int
+		period   string
+	}{
+		{"Each key is limited to 1,000 requests per hour.", 1000, "hour"},
+		{"You can make 60 calls a minute.", 60, "minute"},
+		{"The API is rate limited to 10 per second.", 10, "second"},
+		{"Bursts of up to 500 requests/day are allowed", 500, "day"},
+	}
+	for _, test := range tests {
+		limits := TextRateLimits(test.text, "")
+		if len(limits) != 1 || limits[0].Requests != test.requests || limits[0].Period != test.period {
+			t.Errorf("TextRateLimits(%q) = %+v, expected %d per %s", test.text, limits, test.requests, test.period)
+		}
+	}
+
+	if limits := TextRateLimits("Send 2 requests and wait for both responses.", ""); len(limits) != 0 {
+		t.Errorf("Expected no rate limit, got %+v", limits)
+	}
+}
+
+// TestTextSLAs tests reading uptime commitments from text
+func TestTextSLAs(t *testing.T) {
+	slas := TextSLAs("Version 2.0 is stable. We guarantee 99.95% uptime for paid plans. Discounts of 50% apply.")
+	if len(slas) != 1 || slas[0].Uptime != 99.95 || slas[0].Text != "We guarantee 99.95% uptime for paid plans." {
+		t.Errorf("Expected a 99.95%% uptime SLA, got %+v", slas)
+	}
+}
+
+// TestSpecRateLimits tests reading rate limits from x-ratelimit extensions of a spec
+func TestSpecRateLimits(t *testing.T) {
+	spec := `{
+		"openapi": "3.0.0",
+		"info": {"title": "Limited", "version": "1.0", "x-ratelimit": {"limit": 5000, "window": "1h"}},
+		"paths": {
+			"/search": {"get": {"x-rate-limit": "10/second", "responses": {"200": {"description": "OK"}}}},
+			"/users": {"get": {"description": "Limited to 100 requests per minute.", "responses": {"200": {"description": "OK"}}}}
+		}
+	}`
+	doc, err := (&JSONParser{}).Parse([]byte(spec))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+	if len(doc.RateLimits) != 3 {
+		t.Fatalf("Expected 3 rate limits, got %+v", doc.RateLimits)
+	}
+	global, search, users := doc.RateLimits[0], doc.RateLimits[1], doc.RateLimits[2]
+	if global.Endpoint != "" || global.Requests != 5000 || global.Period != "hour" || global.Source != LimitSourceSpec {
+		t.Errorf("Expected 5000 requests per hour for the API, got %+v", global)
+	}
+	if search.Endpoint != "GET /search" || search.Requests != 10 || search.Period != "second" {
+		t.Errorf("Expected 10 requests per second for GET /search, got %+v", search)
+	}
+	if users.Endpoint != "GET /users" || users.Requests != 100 || users.Source != LimitSourceText {
+		t.Errorf("Expected 100 requests per minute for GET /users from its description, got %+v", users)
+	}
+}
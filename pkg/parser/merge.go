@@ -0,0 +1,162 @@
+package parser
+
+import (
+	"strings"
+
+	"universal_api/internal/models"
+)
+
+// mergeDuplicateEndpoints merges endpoints with the same method and path, keeping the first one's
+// position. Headings and code blocks often document the same endpoint with different detail, so
+// the merged endpoint combines their summaries, descriptions, parameters, responses, and examples.
+func mergeDuplicateEndpoints(endpoints []models.Endpoint) []models.Endpoint {
+	merged := make([]models.Endpoint, 0, len(endpoints))
+	index := make(map[string]int)
+	for _, endpoint := range endpoints {
+		key := strings.ToUpper(endpoint.Method) + " " + endpoint.Path
+		if i, ok := index[key]; ok {
+			mergeEndpoint(&merged[i], endpoint)
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, endpoint)
+	}
+	return merged
+}
+
+// mergeEndpoint adds the details of a duplicate to an endpoint, keeping the endpoint's own where both have them
+func mergeEndpoint(endpoint *models.Endpoint, duplicate models.Endpoint) {
+	if endpoint.Summary == "" {
+		endpoint.Summary = duplicate.Summary
+	}
+	switch {
+	case endpoint.Description == "":
+		endpoint.Description = duplicate.Description
+	case duplicate.Description != "" && !strings.Contains(endpoint.Description, duplicate.Description):
+		endpoint.Description += "\n\n" + duplicate.Description
+	}
+	if endpoint.OperationID == "" {
+		endpoint.OperationID = duplicate.OperationID
+	}
+	endpoint.Deprecated = endpoint.Deprecated || duplicate.Deprecated
+	if !endpoint.Incoming() && duplicate.Incoming() {
+		endpoint.Kind = duplicate.Kind
+	}
+	if endpoint.Protocol == "" {
+		endpoint.Protocol = duplicate.Protocol
+	}
+	endpoint.Messages = append(endpoint.Messages, duplicate.Messages...)
+
+	for _, tag := range duplicate.Tags {
+		if !containsString(endpoint.Tags, tag) {
+			endpoint.Tags = append(endpoint.Tags, tag)
+		}
+	}
+
+	for _, param := range duplicate.Parameters {
+		i := findParameter(endpoint.Parameters, param)
+		if i < 0 {
+			endpoint.Parameters = append(endpoint.Parameters, param)
+			continue
+		}
+		existing := &endpoint.Parameters[i]
+		existing.Required = existing.Required || param.Required
+		if existing.Description == "" {
+			existing.Description = param.Description
+		}
+		if existing.Example == "" {
+			existing.Example = param.Example
+		}
+		if existing.Auth == "" {
+			existing.Auth = param.Auth
+		}
+		if existing.Type == "" || existing.Type == "string" && param.Type != "" {
+			existing.Type = param.Type
+		}
+	}
+
+	for _, body := range duplicate.RequestBodies {
+		i := findRequestBody(endpoint.RequestBodies, body.ContentType)
+		if i < 0 {
+			endpoint.RequestBodies = append(endpoint.RequestBodies, body)
+			continue
+		}
+		existing := &endpoint.RequestBodies[i]
+		existing.Required = existing.Required || body.Required
+		if existing.Schema == "" {
+			existing.Schema = body.Schema
+		}
+		if existing.Example == "" {
+			existing.Example = body.Example
+		}
+	}
+
+	for _, response := range duplicate.Responses {
+		i := findResponse(endpoint.Responses, response.StatusCode)
+		if i < 0 {
+			endpoint.Responses = append(endpoint.Responses, response)
+			continue
+		}
+		existing := &endpoint.Responses[i]
+		if existing.Description == "" || existing.Description == getStatusCodeDescription(existing.StatusCode) && response.Description != "" {
+			existing.Description = response.Description
+		}
+		if existing.Example == "" {
+			existing.Example, existing.ContentType = response.Example, response.ContentType
+		}
+		if existing.Schema == "" && existing.SchemaName == "" {
+			existing.Schema, existing.SchemaName, existing.SchemaInferred = response.Schema, response.SchemaName, response.SchemaInferred
+		}
+	}
+
+	for _, example := range duplicate.Examples {
+		if !containsExample(endpoint.Examples, example) {
+			endpoint.Examples = append(endpoint.Examples, example)
+		}
+	}
+}
+
+// containsExample checks if examples include one with the same source, request, and response bodies
+func containsExample(examples []models.Example, example models.Example) bool {
+	for _, existing := range examples {
+		if existing.Source == example.Source && existing.Request.Method == example.Request.Method &&
+			existing.Request.Body == example.Request.Body && existing.Response.Body == example.Response.Body {
+			return true
+		}
+	}
+	return false
+}
+
+// findParameter returns the index of the parameter with the same name and location, or -1.
+// Header names are case-insensitive.
+func findParameter(params []models.Parameter, param models.Parameter) int {
+	if param.In == "header" {
+		return findHeaderParameter(params, param.Name)
+	}
+	for i, existing := range params {
+		if existing.Name == param.Name && existing.In == param.In {
+			return i
+		}
+	}
+	return -1
+}
+
+// findResponse returns the index of the response with a status code, or -1
+func findResponse(responses []models.Response, statusCode int) int {
+	for i, response := range responses {
+		if response.StatusCode == statusCode {
+			return i
+		}
+	}
+	return -1
+}
+
+// containsString checks if a slice contains a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestHTMLParserMergesDuplicates tests that endpoints found by headings and code blocks are merged
+func TestHTMLParserMergesDuplicates(t *testing.T) {
+	page := `<html><head><title>Orders API</title></head><body>
+<h2>GET /orders/:id</h2>
+<p>Get an order</p>
+<table><tr><th>Name</th><th>Description</th></tr><tr><td>id</td><td>The order ID</td></tr></table>
+<h3>Responses</h3>
+<ul><li>404 when the order doesn't exist</li></ul>
+<div><pre>GET /orders/{id}/</pre></div>
+<pre>GET //orders/{id}</pre>
+</body></html>`
+
+	apiDoc, err := (&HTMLParser{}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	if len(apiDoc.Endpoints) != 1 {
+		t.Fatalf("Expected 1 merged endpoint, got %v", apiDoc.Endpoints)
+	}
+
+	endpoint := apiDoc.Endpoints[0]
+	if endpoint.Method != "GET" || endpoint.Path != "/orders/{id}" || endpoint.Summary != "GET /orders/:id" {
+		t.Errorf("Expected the heading's GET /orders/{id}, got %s %s (%s)", endpoint.Method, endpoint.Path, endpoint.Summary)
+	}
+	if len(endpoint.Parameters) != 1 || endpoint.Parameters[0].Description != "The order ID" {
+		t.Errorf("Expected the documented id parameter once, got %v", endpoint.Parameters)
+	}
+	if findResponse(endpoint.Responses, 404) < 0 || findResponse(endpoint.Responses, 200) < 0 || len(endpoint.Responses) != 2 {
+		t.Errorf("Expected the 200 and 404 responses of both, got %v", endpoint.Responses)
+	}
+}
+
+// TestMergeDuplicateEndpoints tests combining the details of duplicate endpoints
+func TestMergeDuplicateEndpoints(t *testing.T) {
+	endpoints := mergeDuplicateEndpoints([]models.Endpoint{
+		{
+			Method:     "GET",
+			Path:       "/items",
+			Summary:    "List items",
+			Parameters: []models.Parameter{{Name: "limit", In: "query", Type: "string"}},
+			Responses:  []models.Response{{StatusCode: 200, Description: "OK"}},
+		},
+		{Method: "POST", Path: "/items", Summary: "Create an item"},
+		{
+			Method:      "get",
+			Path:        "/items",
+			Description: "Lists all items",
+			Tags:        []string{"items"},
+			Parameters:  []models.Parameter{{Name: "limit", In: "query", Type: "integer", Required: true, Description: "Page size"}},
+			Responses:   []models.Response{{StatusCode: 200, Description: "The items"}},
+		},
+	})
+
+	if len(endpoints) != 2 || endpoints[0].Method != "GET" || endpoints[1].Method != "POST" {
+		t.Fatalf("Expected GET and POST /items in order, got %v", endpoints)
+	}
+
+	list := endpoints[0]
+	if list.Summary != "List items" || list.Description != "Lists all items" || len(list.Tags) != 1 {
+		t.Errorf("Expected the summary of one and the description and tags of the other, got %+v", list)
+	}
+	if len(list.Parameters) != 1 || !list.Parameters[0].Required || list.Parameters[0].Type != "integer" || list.Parameters[0].Description != "Page size" {
+		t.Errorf("Expected the limit parameter combined, got %+v", list.Parameters)
+	}
+	if len(list.Responses) != 1 || list.Responses[0].Description != "The items" {
+		t.Errorf("Expected the 200 response's specific description, got %+v", list.Responses)
+	}
+}
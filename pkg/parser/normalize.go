@@ -0,0 +1,33 @@
+package parser
+
+import "universal_api/internal/models"
+
+// UnknownPath is the path of endpoints whose path couldn't be found
+const UnknownPath = "Unknown"
+
+// NormalizeEndpoints unifies the path syntax of a doc's endpoints and lists their path parameters
+func NormalizeEndpoints(apiDoc *models.APIDoc) error {
+	for i := range apiDoc.Endpoints {
+		normalizeEndpoint(&apiDoc.Endpoints[i])
+	}
+	return nil
+}
+
+// MergeDuplicates merges the endpoints of a doc that are documented more than once
+func MergeDuplicates(apiDoc *models.APIDoc) error {
+	apiDoc.Endpoints = mergeDuplicateEndpoints(apiDoc.Endpoints)
+	return nil
+}
+
+// DropUnknownPaths removes the endpoints of a doc whose path couldn't be found. They can't be
+// called, so they're usually headings that aren't endpoints.
+func DropUnknownPaths(apiDoc *models.APIDoc) error {
+	endpoints := apiDoc.Endpoints[:0]
+	for _, endpoint := range apiDoc.Endpoints {
+		if endpoint.Path != UnknownPath {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	apiDoc.Endpoints = endpoints
+	return nil
+}
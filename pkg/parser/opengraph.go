@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Metadata sources, recorded in a doc's MetadataSources for each field taken from the page
+const (
+	MetadataJSONLD          = "json-ld"
+	MetadataOGTitle         = "og:title"
+	MetadataOGDescription   = "og:description"
+	MetadataOGSiteName      = "og:site_name"
+	MetadataTitleTag        = "title"
+	MetadataMetaDescription = "meta description"
+	MetadataFirstParagraph  = "first paragraph"
+)
+
+// openGraph is the social metadata of a page, given by its OpenGraph meta tags
+type openGraph struct {
+	Title       string
+	Description string
+	SiteName    string
+}
+
+// pageOpenGraph reads a page's og:title, og:description, and og:site_name. Tags found first win.
+// Pages setting them with name instead of property are read too.
+func pageOpenGraph(doc *goquery.Document) openGraph {
+	var og openGraph
+	doc.Find("meta[content]").Each(func(_ int, s *goquery.Selection) {
+		property := s.AttrOr("property", s.AttrOr("name", ""))
+		content := strings.TrimSpace(s.AttrOr("content", ""))
+		switch strings.ToLower(strings.TrimSpace(property)) {
+		case MetadataOGTitle:
+			fill(&og.Title, content)
+		case MetadataOGDescription:
+			fill(&og.Description, content)
+		case MetadataOGSiteName:
+			fill(&og.SiteName, content)
+		}
+	})
+	return og
+}
+
+// metadataCandidate is a value of a doc's metadata and where in the page it was found
+type metadataCandidate struct {
+	source string
+	value  string
+}
+
+// pickMetadata returns the first candidate with a value, recording its source for the field. It
+// returns "" and records nothing when no candidate has a value.
+func pickMetadata(sources map[string]string, field string, candidates ...metadataCandidate) string {
+	for _, candidate := range candidates {
+		if candidate.value != "" {
+			sources[field] = candidate.source
+			return candidate.value
+		}
+	}
+	return ""
+}
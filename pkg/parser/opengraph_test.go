@@ -0,0 +1,57 @@
+package parser
+
+import "testing"
+
+// TestHTMLParserOpenGraph tests that OpenGraph tags win over the title tag and first paragraph,
+// recording where each field was found
+func TestHTMLParserOpenGraph(t *testing.T) {
+	page := `<html><head><title>Reference | Docs | Example</title>
+<meta property="og:title" content=" Payments API ">
+<meta property="og:description" content="Take payments online">
+<meta name="og:site_name" content="Example Inc">
+<meta property="og:title" content="Ignored">
+</head><body><p>Skip to content</p></body></html>`
+
+	apiDoc, err := (&HTMLParser{}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	if apiDoc.Title != "Payments API" || apiDoc.Description != "Take payments online" || apiDoc.Provider != "Example Inc" {
+		t.Errorf("Expected the OpenGraph title, description, and site name, got %q, %q, and %q", apiDoc.Title, apiDoc.Description, apiDoc.Provider)
+	}
+	expected := map[string]string{"title": MetadataOGTitle, "description": MetadataOGDescription, "provider": MetadataOGSiteName}
+	if len(apiDoc.MetadataSources) != len(expected) {
+		t.Errorf("Expected metadata sources %v, got %v", expected, apiDoc.MetadataSources)
+	}
+	for field, source := range expected {
+		if apiDoc.MetadataSources[field] != source {
+			t.Errorf("Expected the %s from %s, got %q", field, source, apiDoc.MetadataSources[field])
+		}
+	}
+}
+
+// TestHTMLParserMetadataPrecedence tests that JSON-LD wins over OpenGraph, and the page's own tags
+// and text are used for what neither gives
+func TestHTMLParserMetadataPrecedence(t *testing.T) {
+	page := `<html><head><title>Docs</title>
+<meta property="og:title" content="Social title">
+<script type="application/ld+json">{"@type": "WebAPI", "name": "Payments API", "provider": "Example Inc"}</script>
+</head><body><p>Take payments</p></body></html>`
+
+	apiDoc, err := (&HTMLParser{}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	if apiDoc.Title != "Payments API" || apiDoc.Description != "Take payments" {
+		t.Errorf("Expected the JSON-LD title and first paragraph, got %q and %q", apiDoc.Title, apiDoc.Description)
+	}
+	sources := apiDoc.MetadataSources
+	if sources["title"] != MetadataJSONLD || sources["description"] != MetadataFirstParagraph || sources["provider"] != MetadataJSONLD {
+		t.Errorf("Expected JSON-LD title and provider and a first paragraph description, got %v", sources)
+	}
+	if _, ok := sources["version"]; ok {
+		t.Errorf("Expected no source for the default version, got %q", sources["version"])
+	}
+}
@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"universal_api/internal/models"
+)
+
+// Names of the query parameters of the pagination styles, compared case-insensitively without _ and -
+var (
+	cursorParams = []string{"cursor", "after", "before", "startingafter", "endingbefore", "pagetoken", "nexttoken", "continuationtoken", "continuation", "marker", "since"}
+	offsetParams = []string{"offset", "skip", "start"}
+	pageParams   = []string{"page", "pagenumber", "pagenum", "p"}
+	sizeParams   = []string{"perpage", "pagesize", "limit", "size", "count", "maxresults", "top", "take"}
+)
+
+// nextFields are the names of the response fields holding the next cursor or page URL, compared like parameters
+var nextFields = []string{"nextcursor", "nextpagetoken", "nexttoken", "cursor", "next", "nextpage", "nextpageurl", "nexturl", "continuationtoken"}
+
+// AnnotatePagination sets the pagination of an endpoint detected from its parameters, responses, and recorded
+// examples, keeping a Link header its spec documents
+func AnnotatePagination(endpoint *models.Endpoint) {
+	linkHeader := endpoint.Pagination != nil && endpoint.Pagination.Style == models.PaginationLink
+	endpoint.Pagination = detectPagination(endpoint, linkHeader)
+}
+
+// detectPagination returns how to iterate over the pages of a GET endpoint, detected from its query parameters,
+// its response fields, and a Link header in its spec's or recorded responses, or nil for endpoints without pages
+func detectPagination(endpoint *models.Endpoint, linkHeader bool) *models.Pagination {
+	if !strings.EqualFold(endpoint.Method, http.MethodGet) {
+		return nil
+	}
+
+	pagination := &models.Pagination{
+		SizeParam: queryParam(endpoint.Parameters, sizeParams),
+		NextField: responseNextField(endpoint.Responses),
+	}
+	cursor := queryParam(endpoint.Parameters, cursorParams)
+	offset := queryParam(endpoint.Parameters, offsetParams)
+	page := queryParam(endpoint.Parameters, pageParams)
+
+	switch {
+	case linkHeader || recordsLinkHeader(endpoint.Examples):
+		pagination.Style = models.PaginationLink
+		pagination.PageParam = firstNonEmpty(cursor, page, offset)
+	case cursor != "":
+		pagination.Style, pagination.PageParam = models.PaginationCursor, cursor
+	case offset != "":
+		pagination.Style, pagination.PageParam = models.PaginationOffset, offset
+	case page != "":
+		pagination.Style, pagination.PageParam = models.PaginationPage, page
+	case pagination.NextField != "" && pagination.SizeParam != "":
+		// A next cursor in the response without a cursor parameter is usually sent back in the same one
+		pagination.Style = models.PaginationCursor
+	default:
+		return nil
+	}
+	return pagination
+}
+
+// paginationName normalizes a parameter or field name for matching, e.g. per_page and perPage to perpage
+func paginationName(name string) string {
+	return strings.NewReplacer("_", "", "-", "", "[", "", "]", "", "$", "").Replace(strings.ToLower(name))
+}
+
+// queryParam returns the name of the first query parameter with one of the names, in the order of the names
+func queryParam(params []models.Parameter, names []string) string {
+	for _, name := range names {
+		for _, param := range params {
+			if param.In == "query" && paginationName(param.Name) == name {
+				return param.Name
+			}
+		}
+	}
+	return ""
+}
+
+// responseNextField returns the field of a successful response's schema holding the next cursor or page URL,
+// at the top level or in an object one level down, like meta.next_cursor, or ""
+func responseNextField(responses []models.Response) string {
+	for _, response := range responses {
+		if response.StatusCode < 200 || response.StatusCode >= 300 || response.Schema == "" {
+			continue
+		}
+		var schema map[string]interface{}
+		if json.Unmarshal([]byte(response.Schema), &schema) != nil {
+			continue
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		if field := nextField(properties); field != "" {
+			return field
+		}
+
+		// Pagination metadata is often grouped, e.g. in meta, links, or pagination
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, _ := properties[name].(map[string]interface{})
+			nested, _ := property["properties"].(map[string]interface{})
+			if field := nextField(nested); field != "" {
+				return name + "." + field
+			}
+		}
+	}
+	return ""
+}
+
+// nextField returns the name of the property holding the next cursor or page URL, in the order of nextFields, or ""
+func nextField(properties map[string]interface{}) string {
+	for _, next := range nextFields {
+		for name := range properties {
+			if paginationName(name) == next {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// recordsLinkHeader checks if recorded responses have a Link header to a next page
+func recordsLinkHeader(examples []models.Example) bool {
+	for _, example := range examples {
+		for name, value := range example.Response.Headers {
+			if strings.EqualFold(name, "Link") && strings.Contains(value, `rel="next"`) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// documentsLinkHeader checks if a spec's response object documents a Link header
+func documentsLinkHeader(respMap map[string]interface{}) bool {
+	headers, _ := respMap["headers"].(map[string]interface{})
+	for name := range headers {
+		if strings.EqualFold(name, "Link") {
+			return true
+		}
+	}
+	return false
+}
+
+// firstNonEmpty returns the first of values that isn't empty, or ""
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestDetectPagination tests detecting the pagination style of list endpoints
+func TestDetectPagination(t *testing.T) {
+	query := func(names ...string) []models.Parameter {
+		var params []models.Parameter
+		for _, name := range names {
+			params = append(params, models.Parameter{Name: name, In: "query"})
+		}
+		return params
+	}
+	tests := []struct {
+		name       string
+		endpoint   models.Endpoint
+		linkHeader bool
+		expected   *models.Pagination
+	}{
+		{"page", models.Endpoint{Method: "GET", Parameters: query("page", "per_page")},
+			false, &models.Pagination{Style: models.PaginationPage, PageParam: "page", SizeParam: "per_page"}},
+		{"offset", models.Endpoint{Method: "GET", Parameters: query("limit", "offset")},
+			false, &models.Pagination{Style: models.PaginationOffset, PageParam: "offset", SizeParam: "limit"}},
+		{"cursor", models.Endpoint{Method: "GET", Parameters: query("starting_after", "limit")},
+			false, &models.Pagination{Style: models.PaginationCursor, PageParam: "starting_after", SizeParam: "limit"}},
+		{"link", models.Endpoint{Method: "GET", Parameters: query("page", "per_page")},
+			true, &models.Pagination{Style: models.PaginationLink, PageParam: "page", SizeParam: "per_page"}},
+		{"next cursor", models.Endpoint{Method: "GET", Parameters: query("pageSize"), Responses: []models.Response{
+			{StatusCode: 200, Schema: `{"type":"object","properties":{"items":{"type":"array"},"meta":{"type":"object","properties":{"next_cursor":{"type":"string"}}}}}`},
+		}}, false, &models.Pagination{Style: models.PaginationCursor, SizeParam: "pageSize", NextField: "meta.next_cursor"}},
+		{"no pages", models.Endpoint{Method: "GET", Parameters: query("q")}, false, nil},
+		{"not a list", models.Endpoint{Method: "POST", Parameters: query("page")}, false, nil},
+	}
+	for _, test := range tests {
+		pagination := detectPagination(&test.endpoint, test.linkHeader)
+		if (pagination == nil) != (test.expected == nil) || pagination != nil && *pagination != *test.expected {
+			t.Errorf("%s: expected %+v, got %+v", test.name, test.expected, pagination)
+		}
+	}
+}
+
+// TestAnnotatePaginationRecordedLink tests detecting Link header pagination from recorded responses
+func TestAnnotatePaginationRecordedLink(t *testing.T) {
+	endpoint := models.Endpoint{Method: "GET", Parameters: []models.Parameter{{Name: "page", In: "query"}}}
+	AnnotatePagination(&endpoint)
+	if endpoint.Pagination == nil || endpoint.Pagination.Style != models.PaginationPage {
+		t.Fatalf("Expected page pagination, got %+v", endpoint.Pagination)
+	}
+
+	endpoint.Examples = []models.Example{{Response: models.ExampleResponse{Headers: map[string]string{
+		"Link": `<https://api.example.com/users?page=2>; rel="next"`,
+	}}}}
+	AnnotatePagination(&endpoint)
+	if endpoint.Pagination == nil || endpoint.Pagination.Style != models.PaginationLink {
+		t.Errorf("Expected link pagination, got %+v", endpoint.Pagination)
+	}
+}
+
+// TestParseSpecLinkHeaderPagination tests detecting Link header pagination from a spec's response headers
+func TestParseSpecLinkHeaderPagination(t *testing.T) {
+	spec := `{
+		"openapi": "3.0.0",
+		"info": {"title": "Repos", "version": "1.0"},
+		"paths": {"/repos": {"get": {
+			"parameters": [{"name": "per_page", "in": "query", "schema": {"type": "integer"}}],
+			"responses": {"200": {"description": "OK", "headers": {"Link": {"schema": {"type": "string"}}}}}
+		}}}
+	}`
+	doc, err := (&JSONParser{}).Parse([]byte(spec))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	pagination := doc.Endpoints[0].Pagination
+	if pagination == nil || pagination.Style != models.PaginationLink || pagination.SizeParam != "per_page" {
+		t.Errorf("Expected link pagination with per_page, got %+v", pagination)
+	}
+}
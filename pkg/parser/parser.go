@@ -4,11 +4,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"sort"
 	"strings"
 	"time"
 	"universal_api/internal/models"
 
 	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
 	"gopkg.in/yaml.v3"
 )
 
@@ -36,24 +39,74 @@ type JSONParser struct{}
 
 // OpenAPIDoc represents a simplified OpenAPI/Swagger document structure
 type OpenAPIDoc struct {
-	Openapi     string                 `json:"openapi,omitempty"`
-	Swagger     string                 `json:"swagger,omitempty"`
-	Info        OpenAPIInfo            `json:"info"`
-	Paths       map[string]PathItem    `json:"paths"`
-	Components  *OpenAPIComponents     `json:"components,omitempty"`
-	Definitions map[string]interface{} `json:"definitions,omitempty"` // For Swagger 2.0
+	Openapi             string                    `json:"openapi,omitempty"`
+	Swagger             string                    `json:"swagger,omitempty"`
+	Info                OpenAPIInfo               `json:"info"`
+	Paths               map[string]PathItem       `json:"paths"`
+	Webhooks            map[string]PathItem       `json:"webhooks,omitempty"` // For OpenAPI 3.1: requests the API sends, by name
+	Servers             []OpenAPIServer           `json:"servers,omitempty"`
+	Components          *OpenAPIComponents        `json:"components,omitempty"`
+	Definitions         map[string]interface{}    `json:"definitions,omitempty"`         // For Swagger 2.0
+	SecurityDefinitions map[string]SecurityScheme `json:"securityDefinitions,omitempty"` // For Swagger 2.0
+	Host                string                    `json:"host,omitempty"`                // For Swagger 2.0
+	BasePath            string                    `json:"basePath,omitempty"`            // For Swagger 2.0
+	Schemes             []string                  `json:"schemes,omitempty"`             // For Swagger 2.0
+}
+
+// OpenAPIServer describes a server hosting the API
+type OpenAPIServer struct {
+	URL string `json:"url"`
 }
 
 // OpenAPIInfo contains metadata about the API
 type OpenAPIInfo struct {
-	Title       string `json:"title"`
-	Description string `json:"description,omitempty"`
-	Version     string `json:"version"`
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	Version     string       `json:"version"`
+	Logo        *OpenAPILogo `json:"x-logo,omitempty"` // the provider's logo, as in Redoc's vendor extension
+}
+
+// OpenAPILogo is the x-logo extension of the Info Object
+type OpenAPILogo struct {
+	URL string `json:"url"`
+}
+
+// LogoURL returns the URL of the provider's logo, or "" when the spec has none
+func (i OpenAPIInfo) LogoURL() string {
+	if i.Logo == nil {
+		return ""
+	}
+	return strings.TrimSpace(i.Logo.URL)
 }
 
 // OpenAPIComponents contains reusable objects for different aspects of the OAS
 type OpenAPIComponents struct {
-	Schemas map[string]interface{} `json:"schemas,omitempty"`
+	Schemas         map[string]interface{}    `json:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes an authentication method of the API
+type SecurityScheme struct {
+	Type             string               `json:"type"`             // apiKey, http, oauth2, openIdConnect (basic for Swagger 2.0)
+	Scheme           string               `json:"scheme,omitempty"` // basic, bearer, ... for http
+	Description      string               `json:"description,omitempty"`
+	Name             string               `json:"name,omitempty"`             // header, query parameter, or cookie for apiKey
+	In               string               `json:"in,omitempty"`               // header, query, or cookie for apiKey
+	BearerFormat     string               `json:"bearerFormat,omitempty"`     // e.g. JWT
+	OpenIDConnectURL string               `json:"openIdConnectUrl,omitempty"` // discovery document for openIdConnect
+	Flows            map[string]OAuthFlow `json:"flows,omitempty"`            // OAuth2 flows by name
+	Flow             string               `json:"flow,omitempty"`             // For Swagger 2.0: implicit, password, application, or accessCode
+	AuthorizationURL string               `json:"authorizationUrl,omitempty"` // For Swagger 2.0
+	TokenURL         string               `json:"tokenUrl,omitempty"`         // For Swagger 2.0
+	Scopes           map[string]string    `json:"scopes,omitempty"`           // For Swagger 2.0
+}
+
+// OAuthFlow describes an OAuth2 flow of a security scheme
+type OAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	RefreshURL       string            `json:"refreshUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes,omitempty"`
 }
 
 // PathItem describes the operations available on a single path
@@ -72,8 +125,11 @@ type Operation struct {
 	Summary     string                 `json:"summary,omitempty"`
 	Description string                 `json:"description,omitempty"`
 	OperationID string                 `json:"operationId,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Deprecated  bool                   `json:"deprecated,omitempty"`
 	Parameters  []Parameter            `json:"parameters,omitempty"`
 	Responses   map[string]interface{} `json:"responses,omitempty"`
+	Callbacks   map[string]interface{} `json:"callbacks,omitempty"`
 }
 
 // Parameter describes a single operation parameter
@@ -104,78 +160,130 @@ func (p *JSONParser) Parse(content []byte) (*models.APIDoc, error) {
 		return nil, errors.New("JSON does not appear to be an OpenAPI/Swagger document")
 	}
 
+	// Keep the raw document around to resolve $refs in response schemas
+	var root map[string]interface{}
+	json.Unmarshal(content, &root)
+
 	// Create API doc
 	apiDoc := &models.APIDoc{
 		ID:          fmt.Sprintf("openapi-%d", time.Now().Unix()),
 		Title:       openAPIDoc.Info.Title,
 		Description: openAPIDoc.Info.Description,
 		Version:     openAPIDoc.Info.Version,
+		IconURL:     openAPIDoc.Info.LogoURL(),
+		Servers:     openAPIDoc.ServerURLs(),
+		AuthTypes:   openAPIDoc.AuthTypes(),
+		AuthSchemes: authSchemes(openAPIDoc.SecuritySchemes()),
 		Endpoints:   []models.Endpoint{},
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 
-	// Extract endpoints
+	// Extract endpoints, and the requests the API sends to webhooks (OpenAPI 3.1)
 	for path, pathItem := range openAPIDoc.Paths {
-		// Process each HTTP method
-		operations := pathItem.Operations()
-		for method, operation := range operations {
-			// Create endpoint
-			endpoint := models.Endpoint{
-				Path:        path,
-				Method:      method,
-				Summary:     operation.Summary,
-				Description: operation.Description,
-				Parameters:  []models.Parameter{},
-				Responses:   []models.Response{},
-			}
+		for method, operation := range pathItem.Operations() {
+			apiDoc.Endpoints = append(apiDoc.Endpoints, operationEndpoint(root, "paths", models.EndpointRequest, path, method, operation))
+		}
+	}
+	for name, pathItem := range openAPIDoc.Webhooks {
+		for method, operation := range pathItem.Operations() {
+			apiDoc.Endpoints = append(apiDoc.Endpoints, operationEndpoint(root, "webhooks", models.EndpointWebhook, name, method, operation))
+		}
+	}
+	resolveLinkTargets(apiDoc.Endpoints)
+	apiDoc.Schemas = componentSchemas(root)
+	docLimits(apiDoc, root, apiDoc.Description)
+	apiDoc.Pricing = TextPricing(apiDoc.Description)
 
-			// Add parameters
-			for _, param := range operation.Parameters {
-				paramType := param.Type
-				if param.Schema != nil && param.Schema.Type != "" {
-					paramType = param.Schema.Type
-				}
+	return apiDoc, nil
+}
 
-				endpoint.Parameters = append(endpoint.Parameters, models.Parameter{
-					Name:        param.Name,
-					In:          param.In,
-					Required:    param.Required,
-					Type:        paramType,
-					Description: param.Description,
-				})
-			}
+// operationEndpoint converts an operation of a spec's paths, or of its webhooks for webhook endpoints, into an endpoint
+func operationEndpoint(root map[string]interface{}, section, kind, path, method string, operation Operation) models.Endpoint {
+	endpoint := models.Endpoint{
+		Path:        path,
+		Kind:        kind,
+		Method:      method,
+		Summary:     operation.Summary,
+		Description: operation.Description,
+		Tags:        operation.Tags,
+		Deprecated:  operation.Deprecated,
+		OperationID: operation.OperationID,
+		Parameters:  []models.Parameter{},
+		Responses:   []models.Response{},
+		Callbacks:   operationCallbacks(operation.Callbacks, root),
+	}
 
-			// Add responses
-			for statusCode, responseObj := range operation.Responses {
-				// Try to extract description from response object
-				description := ""
-				if respMap, ok := responseObj.(map[string]interface{}); ok {
-					if desc, ok := respMap["description"].(string); ok {
-						description = desc
-					}
-				}
+	// Add parameters
+	for _, param := range operation.Parameters {
+		paramType := param.Type
+		if param.Schema != nil && param.Schema.Type != "" {
+			paramType = param.Schema.Type
+		}
 
-				// Convert status code to int
-				code := 0
-				if statusCode == "default" {
-					code = 0
-				} else {
-					fmt.Sscanf(statusCode, "%d", &code)
-				}
+		// Form fields of Swagger 2.0 are body fields, whose content types are in the request bodies
+		in := param.In
+		if in == "formData" {
+			in = "body"
+		}
+
+		endpoint.Parameters = append(endpoint.Parameters, models.Parameter{
+			Name:        param.Name,
+			In:          in,
+			Required:    param.Required,
+			Type:        paramType,
+			Description: param.Description,
+		})
+	}
 
-				endpoint.Responses = append(endpoint.Responses, models.Response{
-					StatusCode:  code,
-					Description: description,
-					// Schema is omitted for simplicity
-				})
+	// Add the request body in every content type the operation accepts
+	endpoint.RequestBodies = operationRequestBodies(root, section, path, method)
+	if !hasBodyParameters(endpoint.Parameters) {
+		endpoint.Parameters = append(endpoint.Parameters, requestBodyParameters(endpoint.RequestBodies)...)
+	}
+
+	// Add responses
+	linkHeader := false
+	for statusCode, responseObj := range operation.Responses {
+		// Try to extract description and schema from response object
+		description := ""
+		schema := ""
+		respMap, _ := resolveRefs(responseObj, root, 0).(map[string]interface{})
+		if respMap != nil {
+			if desc, ok := respMap["description"].(string); ok {
+				description = desc
 			}
+			schema = responseSchema(respMap)
+			linkHeader = linkHeader || documentsLinkHeader(respMap)
+			if message, ok := responseEventStream(respMap); ok {
+				endpoint.Protocol = models.ProtocolSSE
+				endpoint.Messages = append(endpoint.Messages, message)
+			}
+		}
 
-			apiDoc.Endpoints = append(apiDoc.Endpoints, endpoint)
+		// Convert status code to int
+		code := 0
+		if statusCode == "default" {
+			code = 0
+		} else {
+			fmt.Sscanf(statusCode, "%d", &code)
 		}
-	}
 
-	return apiDoc, nil
+		endpoint.Responses = append(endpoint.Responses, models.Response{
+			StatusCode:  code,
+			Description: description,
+			Schema:      schema,
+			SchemaName:  responseSchemaName(responseObj, root),
+		})
+		endpoint.Links = append(endpoint.Links, responseLinks(respMap, code)...)
+	}
+	sort.Slice(endpoint.Links, func(i, j int) bool {
+		a, b := endpoint.Links[i], endpoint.Links[j]
+		return a.StatusCode < b.StatusCode || a.StatusCode == b.StatusCode && a.Name < b.Name
+	})
+	endpoint.Pagination = detectPagination(&endpoint, linkHeader)
+	ClassifySafety(&endpoint)
+	return endpoint
 }
 
 // OpenAPI returns the OpenAPI version (either from openapi or swagger field)
@@ -186,6 +294,287 @@ func (doc *OpenAPIDoc) OpenAPI() string {
 	return doc.Swagger
 }
 
+// ServerURLs returns the base URLs of the API (from servers, or host/basePath for Swagger 2.0)
+func (doc *OpenAPIDoc) ServerURLs() []string {
+	var urls []string
+	for _, server := range doc.Servers {
+		if server.URL != "" {
+			urls = append(urls, server.URL)
+		}
+	}
+
+	if doc.Host != "" {
+		schemes := doc.Schemes
+		if len(schemes) == 0 {
+			schemes = []string{"https"}
+		}
+		for _, scheme := range schemes {
+			urls = append(urls, scheme+"://"+doc.Host+doc.BasePath)
+		}
+	}
+
+	return urls
+}
+
+// SecuritySchemes returns the security schemes declared by the API, by name
+func (doc *OpenAPIDoc) SecuritySchemes() map[string]SecurityScheme {
+	if doc.Components != nil && len(doc.Components.SecuritySchemes) > 0 {
+		return doc.Components.SecuritySchemes
+	}
+	return doc.SecurityDefinitions
+}
+
+// AuthTypes returns the sorted, distinct authentication types declared by the API:
+// apiKey, basic, bearer, oauth2, or openIdConnect
+func (doc *OpenAPIDoc) AuthTypes() []string {
+	seen := make(map[string]bool)
+	var types []string
+	for _, scheme := range doc.SecuritySchemes() {
+		authType := scheme.Type
+		if authType == "http" {
+			authType = strings.ToLower(scheme.Scheme)
+		}
+		if authType != "" && !seen[authType] {
+			seen[authType] = true
+			types = append(types, authType)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// responseSchema returns the JSON schema of a response object as a string,
+// preferring the JSON media type for OpenAPI 3 responses
+func responseSchema(respMap map[string]interface{}) string {
+	schema := responseSchemaNode(respMap)
+	if schema == nil {
+		return ""
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// componentSchemas returns the reusable schemas of a document (components/schemas, or definitions
+// for Swagger 2.0) by name, as JSON with their $refs kept
+func componentSchemas(root map[string]interface{}) map[string]string {
+	definitions, _ := root["definitions"].(map[string]interface{})
+	if components, ok := root["components"].(map[string]interface{}); ok {
+		if schemas, ok := components["schemas"].(map[string]interface{}); ok {
+			definitions = schemas
+		}
+	}
+	if len(definitions) == 0 {
+		return nil
+	}
+
+	schemas := make(map[string]string, len(definitions))
+	for name, definition := range definitions {
+		if data, err := json.Marshal(definition); err == nil {
+			schemas[name] = string(data)
+		}
+	}
+	return schemas
+}
+
+// responseSchemaNode returns the schema of a response object, preferring the JSON media type for OpenAPI 3 responses
+func responseSchemaNode(respMap map[string]interface{}) interface{} {
+	schema, ok := respMap["schema"] // Swagger 2.0
+	if !ok {
+		if content, ok := respMap["content"].(map[string]interface{}); ok {
+			for mediaType, media := range content {
+				mediaMap, ok := media.(map[string]interface{})
+				if !ok || mediaMap["schema"] == nil {
+					continue
+				}
+				if schema == nil || strings.Contains(mediaType, "json") {
+					schema = mediaMap["schema"]
+				}
+			}
+		}
+	}
+	return schema
+}
+
+// responseSchemaName returns the name of the reusable schema a raw response object returns,
+// directly or as the items of an array, e.g. Order for #/components/schemas/Order
+func responseSchemaName(responseObj interface{}, root map[string]interface{}) string {
+	for depth := 0; depth < maxRefDepth; depth++ {
+		respMap, ok := responseObj.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		ref, ok := respMap["$ref"].(string)
+		if !ok {
+			schema, _ := responseSchemaNode(respMap).(map[string]interface{})
+			if items, ok := schema["items"].(map[string]interface{}); ok && schema["$ref"] == nil {
+				schema = items
+			}
+			return schemaRefName(schema)
+		}
+		responseObj = lookupRef(root, ref)
+	}
+	return ""
+}
+
+// schemaRefName returns the name of a schema referencing #/components/schemas or #/definitions
+func schemaRefName(schema map[string]interface{}) string {
+	ref, _ := schema["$ref"].(string)
+	for _, prefix := range []string{"#/components/schemas/", "#/definitions/"} {
+		if strings.HasPrefix(ref, prefix) {
+			return strings.TrimPrefix(ref, prefix)
+		}
+	}
+	return ""
+}
+
+// maxRefDepth bounds $ref resolution so recursive schemas terminate
+const maxRefDepth = 8
+
+// resolveRefs returns a copy of node with local $refs ("#/...") replaced by their targets
+func resolveRefs(node interface{}, root map[string]interface{}, depth int) interface{} {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := value["$ref"].(string); ok && strings.HasPrefix(ref, "#/") {
+			if depth >= maxRefDepth {
+				return map[string]interface{}{}
+			}
+			return resolveRefs(lookupRef(root, ref), root, depth+1)
+		}
+		resolved := make(map[string]interface{}, len(value))
+		for key, child := range value {
+			resolved[key] = resolveRefs(child, root, depth)
+		}
+		return resolved
+	case []interface{}:
+		resolved := make([]interface{}, len(value))
+		for i, child := range value {
+			resolved[i] = resolveRefs(child, root, depth)
+		}
+		return resolved
+	default:
+		return node
+	}
+}
+
+// lookupRef finds the target of a local JSON pointer reference
+func lookupRef(root map[string]interface{}, ref string) interface{} {
+	var current interface{} = root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		currentMap, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = currentMap[part]
+	}
+	return current
+}
+
+// responseLinks returns the links of a resolved response object
+func responseLinks(respMap map[string]interface{}, statusCode int) []models.Link {
+	linkMap, _ := respMap["links"].(map[string]interface{})
+
+	var links []models.Link
+	for name, value := range linkMap {
+		linkObj, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		link := models.Link{Name: name, StatusCode: statusCode}
+		link.OperationID, _ = linkObj["operationId"].(string)
+		link.OperationRef, _ = linkObj["operationRef"].(string)
+		link.Description, _ = linkObj["description"].(string)
+		if params, ok := linkObj["parameters"].(map[string]interface{}); ok {
+			link.Parameters = make(map[string]string, len(params))
+			for param, expression := range params {
+				link.Parameters[param] = fmt.Sprint(expression)
+			}
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// resolveLinkTargets sets the method and path of links to operations of the same doc,
+// found by operationId or by a local operationRef such as #/paths/~1users~1{id}/get
+func resolveLinkTargets(endpoints []models.Endpoint) {
+	byID := make(map[string]*models.Endpoint)
+	for i := range endpoints {
+		if endpoints[i].OperationID != "" {
+			byID[endpoints[i].OperationID] = &endpoints[i]
+		}
+	}
+
+	for i := range endpoints {
+		for j := range endpoints[i].Links {
+			link := &endpoints[i].Links[j]
+			if target, ok := byID[link.OperationID]; ok {
+				link.Method, link.Path = target.Method, target.Path
+			} else if strings.HasPrefix(link.OperationRef, "#/paths/") {
+				ref := strings.TrimPrefix(link.OperationRef, "#/paths/")
+				if k := strings.LastIndex(ref, "/"); k > 0 {
+					path := strings.ReplaceAll(strings.ReplaceAll(ref[:k], "~1", "/"), "~0", "~")
+					link.Method, link.Path = strings.ToUpper(ref[k+1:]), path
+				}
+			}
+		}
+	}
+}
+
+// operationCallbacks returns the callbacks of an operation: for each callback name, the
+// operations of the path items keyed by the runtime expression of the callback URL
+func operationCallbacks(callbacks map[string]interface{}, root map[string]interface{}) []models.Callback {
+	var result []models.Callback
+	for name, value := range callbacks {
+		expressions, ok := resolveRefs(value, root, 0).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for expression, item := range expressions {
+			operations, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for method, op := range operations {
+				opMap, ok := op.(map[string]interface{})
+				if !ok || !isHTTPMethod(method) {
+					continue
+				}
+				callback := models.Callback{Name: name, Expression: expression, Method: strings.ToUpper(method)}
+				callback.Summary, _ = opMap["summary"].(string)
+				callback.Description, _ = opMap["description"].(string)
+				result = append(result, callback)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Expression != b.Expression {
+			return a.Expression < b.Expression
+		}
+		return a.Method < b.Method
+	})
+	return result
+}
+
+// isHTTPMethod checks if a path item key is an operation rather than e.g. parameters or summary
+func isHTTPMethod(key string) bool {
+	switch strings.ToLower(key) {
+	case "get", "put", "post", "delete", "options", "head", "patch", "trace":
+		return true
+	}
+	return false
+}
+
 // Operations returns a map of HTTP method to Operation for a PathItem
 func (item *PathItem) Operations() map[string]Operation {
 	result := make(map[string]Operation)
@@ -238,7 +627,11 @@ func (p *YAMLParser) Parse(content []byte) (*models.APIDoc, error) {
 }
 
 // HTMLParser parses HTML API documentation
-type HTMLParser struct{}
+type HTMLParser struct {
+	Summary          SummaryOptions // how the summary of a long description is generated
+	KeepUnknownPaths bool           // keep endpoints whose path wasn't found, with the path "Unknown"
+	Rules            *Rules         // the heuristics endpoints are found with, overriding the defaults
+}
 
 // Parse implements the Parser interface for HTML
 func (p *HTMLParser) Parse(content []byte) (*models.APIDoc, error) {
@@ -248,71 +641,106 @@ func (p *HTMLParser) Parse(content []byte) (*models.APIDoc, error) {
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
+	// Structured data, when the page has it, is authoritative. OpenGraph tags come next: they're
+	// written for sharing, so they're usually cleaner than the title tag and the page's text.
+	data := pageStructuredData(doc)
+	og := pageOpenGraph(doc)
+	sources := make(map[string]string)
+
 	// Extract title
-	title := doc.Find("title").Text()
+	title := pickMetadata(sources, "title",
+		metadataCandidate{MetadataJSONLD, data.Title},
+		metadataCandidate{MetadataOGTitle, og.Title},
+		metadataCandidate{MetadataTitleTag, doc.Find("title").Text()},
+	)
 	if title == "" {
 		title = "Unknown API"
 	}
 
-	// Extract description
-	description := ""
+	// Extract description, falling back to the first paragraph or div of the content
+	metaDescription := ""
 	doc.Find("meta[name=description]").Each(func(i int, s *goquery.Selection) {
 		if content, exists := s.Attr("content"); exists {
-			description = content
+			metaDescription = content
 		}
 	})
+	firstParagraph := doc.Find("p").First().Text()
+	if firstParagraph == "" {
+		firstParagraph = doc.Find("div").First().Text()
+	}
+	description := pickMetadata(sources, "description",
+		metadataCandidate{MetadataJSONLD, data.Description},
+		metadataCandidate{MetadataOGDescription, og.Description},
+		metadataCandidate{MetadataMetaDescription, metaDescription},
+		metadataCandidate{MetadataFirstParagraph, strings.TrimSpace(firstParagraph)},
+	)
+
+	provider := pickMetadata(sources, "provider",
+		metadataCandidate{MetadataJSONLD, data.Provider},
+		metadataCandidate{MetadataOGSiteName, og.SiteName},
+	)
+
+	// Keep the full description and summarize it for listings
+	summary := Summarize(description, p.Summary)
+	if summary == description {
+		summary = ""
+	}
 
-	// If no meta description, try to find a description in the content
-	if description == "" {
-		// Look for a description in the first paragraph or div
-		description = doc.Find("p").First().Text()
-		if description == "" {
-			description = doc.Find("div").First().Text()
-		}
-		// Truncate if too long
-		if len(description) > 200 {
-			description = description[:197] + "..."
-		}
+	version := pickMetadata(sources, "version", metadataCandidate{MetadataJSONLD, data.Version})
+	if version == "" {
+		version = "Unknown"
 	}
 
 	// Create API doc
 	apiDoc := &models.APIDoc{
-		ID:          fmt.Sprintf("html-%d", time.Now().Unix()),
-		Title:       title,
-		Description: description,
-		Version:     "Unknown",
-		Endpoints:   []models.Endpoint{},
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:              fmt.Sprintf("html-%d", time.Now().Unix()),
+		Title:           title,
+		Description:     description,
+		Summary:         summary,
+		Version:         version,
+		Provider:        provider,
+		IconURL:         pageIcon(doc),
+		MetadataSources: sources,
+		Endpoints:       []models.Endpoint{},
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 
 	// Extract endpoints
-	// Look for common patterns in API documentation
+	// Look for common patterns in API documentation, with the configured heuristics
+	rules := DefaultRules().Override(p.Rules)
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
 
 	// Method 1: Look for headings that might indicate endpoints
-	doc.Find("h1, h2, h3, h4, h5, h6").Each(func(i int, s *goquery.Selection) {
+	endpointHeadings := make(map[*html.Node]bool)
+	doc.Find(rules.HeadingSelector).Each(func(i int, s *goquery.Selection) {
+		if text := strings.TrimSpace(s.Text()); text != "" && rules.containsEndpointIndicators(text) {
+			endpointHeadings[s.Get(0)] = true
+		}
+	})
+	isEndpointHeading := func(node *html.Node) bool { return endpointHeadings[node] }
+
+	doc.Find(rules.HeadingSelector).Each(func(i int, s *goquery.Selection) {
 		text := s.Text()
 		text = strings.TrimSpace(text)
 
-		// Skip if empty
-		if text == "" {
-			return
-		}
-
 		// Check if this heading looks like an API endpoint
-		if containsEndpointIndicators(text) {
+		if endpointHeadings[s.Get(0)] {
 			// Extract method and path
-			method, path := extractMethodAndPath(text)
+			method, path := rules.extractMethodAndPath(text)
 
-			// Get description from the next element
-			description := ""
-			next := s.Next()
-			if next.Length() > 0 {
-				description = next.Text()
-			}
+			// The endpoint's section runs to the next heading of its level or the next endpoint
+			paramSection := section(s, isEndpointHeading)
 
-			// Create endpoint
+			// Get description from the section's first paragraph
+			description := sectionText(paramSection)
+
+			// Create endpoint, which a section saying the API calls the client's URL documents as a webhook or callback
 			endpoint := models.Endpoint{
+				Kind:        sectionKind(text, description),
+				Protocol:    sectionProtocol(paramSection.Text(), path),
 				Path:        path,
 				Method:      method,
 				Summary:     text,
@@ -322,99 +750,49 @@ func (p *HTMLParser) Parse(content []byte) (*models.APIDoc, error) {
 			}
 
 			// Look for parameters in tables or lists
-			paramSection := s.NextUntil("h1, h2, h3, h4, h5, h6")
-			paramSection.Find("table tr").Each(func(i int, row *goquery.Selection) {
-				// Skip header row
-				if i == 0 {
-					return
-				}
-
-				// Extract parameter info from table row
-				cells := row.Find("td")
-				if cells.Length() >= 2 {
-					name := cells.Eq(0).Text()
-					desc := cells.Eq(1).Text()
-
-					// Try to determine parameter type and location
-					paramType := "string"
-					paramIn := "query"
-					required := false
-
-					// Check if path parameter
-					if strings.Contains(path, "{"+name+"}") || strings.Contains(path, ":"+name) {
-						paramIn = "path"
-						required = true
-					}
-
-					// Add parameter
-					endpoint.Parameters = append(endpoint.Parameters, models.Parameter{
-						Name:        name,
-						In:          paramIn,
-						Required:    required,
-						Type:        paramType,
-						Description: desc,
-					})
-				}
+			paramSection.Filter("table").AddSelection(paramSection.Find("table")).Each(func(_ int, table *goquery.Selection) {
+				endpoint.Parameters = append(endpoint.Parameters, tableParameters(table, path)...)
 			})
 
-			// Look for response codes
-			paramSection.Find("code, .code, pre").Each(func(i int, code *goquery.Selection) {
-				text := code.Text()
-
-				// Look for HTTP status codes
-				statusMatches := []string{"200", "201", "400", "401", "403", "404", "500"}
-				for _, status := range statusMatches {
-					if strings.Contains(text, status) {
-						statusCode := 0
-						fmt.Sscanf(status, "%d", &statusCode)
-
-						// Check if this status code is already added
-						alreadyAdded := false
-						for _, resp := range endpoint.Responses {
-							if resp.StatusCode == statusCode {
-								alreadyAdded = true
-								break
-							}
-						}
+			// Look for the status codes of the section's responses
+			addSectionResponses(&endpoint, paramSection, rules)
 
-						if !alreadyAdded {
-							endpoint.Responses = append(endpoint.Responses, models.Response{
-								StatusCode:  statusCode,
-								Description: getStatusCodeDescription(statusCode),
-							})
-						}
-					}
-				}
-			})
+			// Capture the request bodies of the endpoint's curl samples and JSON blocks, and its response examples
+			addSectionRequestExamples(&endpoint, paramSection, rules)
+			addSectionResponseExamples(&endpoint, paramSection, rules)
 
 			apiDoc.Endpoints = append(apiDoc.Endpoints, endpoint)
 		}
 	})
 
 	// Method 2: Look for code blocks that might contain API endpoints
-	doc.Find("pre, code, .code").Each(func(i int, s *goquery.Selection) {
+	doc.Find(rules.CodeSelector).Each(func(i int, s *goquery.Selection) {
 		text := s.Text()
 
-		// Check for common API request patterns
-		for _, method := range []string{"GET", "POST", "PUT", "DELETE", "PATCH"} {
+		// A curl command describes the whole request, including its body
+		if request, ok := parseCurl(text); ok {
+			apiDoc.Endpoints = append(apiDoc.Endpoints, curlEndpoint(request))
+			return
+		}
+
+		// WebSockets and SSE streams are opened at URLs rather than with request lines
+		apiDoc.Endpoints = append(apiDoc.Endpoints, codeStreamingEndpoints(text)...)
+
+		// Check for request lines like "GET /users" or "POST https://api.example.com/orders"
+		for _, method := range rules.Methods {
+			method = strings.ToUpper(method)
 			if strings.Contains(text, method+" ") || strings.Contains(text, method+"\t") {
 				lines := strings.Split(text, "\n")
 				for _, line := range lines {
-					if strings.Contains(line, method) {
-						// Extract path
-						parts := strings.Fields(line)
-						if len(parts) >= 2 {
-							path := parts[1]
-
-							// Clean up path
-							path = strings.TrimPrefix(path, "http://")
-							path = strings.TrimPrefix(path, "https://")
-							if idx := strings.Index(path, "/"); idx > 0 {
-								path = path[idx:]
-							}
-
+					parts := strings.Fields(line)
+					for k := 0; k+1 < len(parts); k++ {
+						if parts[k] != method {
+							continue
+						}
+						if path, ok := pathToken(parts[k+1]); ok {
 							// Create endpoint
 							endpoint := models.Endpoint{
+								Kind:        models.EndpointRequest,
 								Path:        path,
 								Method:      method,
 								Summary:     line,
@@ -429,18 +807,8 @@ func (p *HTMLParser) Parse(content []byte) (*models.APIDoc, error) {
 								Description: "OK",
 							})
 
-							// Check if this endpoint is already added
-							alreadyAdded := false
-							for _, ep := range apiDoc.Endpoints {
-								if ep.Path == path && ep.Method == method {
-									alreadyAdded = true
-									break
-								}
-							}
-
-							if !alreadyAdded {
-								apiDoc.Endpoints = append(apiDoc.Endpoints, endpoint)
-							}
+							// Endpoints found by both methods are merged below
+							apiDoc.Endpoints = append(apiDoc.Endpoints, endpoint)
 						}
 					}
 				}
@@ -448,86 +816,127 @@ func (p *HTMLParser) Parse(content []byte) (*models.APIDoc, error) {
 		}
 	})
 
-	return apiDoc, nil
-}
+	// Unify the path syntax of the endpoints and list their path parameters
+	NormalizeEndpoints(apiDoc)
 
-// Helper functions for HTML parser
+	// Merge the endpoints documented more than once, e.g. by a heading and a code block
+	MergeDuplicates(apiDoc)
 
-// containsEndpointIndicators checks if text contains indicators of an API endpoint
-func containsEndpointIndicators(text string) bool {
-	lowerText := strings.ToLower(text)
+	// An endpoint without a path can't be called, so it's usually a heading that isn't one
+	if !p.KeepUnknownPaths {
+		DropUnknownPaths(apiDoc)
+	}
 
-	// Check for common endpoint indicators
-	if strings.Contains(lowerText, "api") ||
-		strings.Contains(lowerText, "endpoint") ||
-		strings.Contains(lowerText, "route") ||
-		strings.Contains(lowerText, "request") {
-		return true
+	// List endpoints tell how to page through them by their parameters, and methods and
+	// idempotency keys whether endpoints are safe to call
+	for i := range apiDoc.Endpoints {
+		AnnotatePagination(&apiDoc.Endpoints[i])
+		ClassifySafety(&apiDoc.Endpoints[i])
 	}
 
-	// Check for HTTP methods
-	for _, method := range []string{"get", "post", "put", "delete", "patch"} {
-		if strings.Contains(lowerText, method) {
+	// Headers carrying credentials tell how the API authenticates
+	apiDoc.AuthTypes = docAuthTypes(apiDoc)
+	apiDoc.AuthSchemes = headerAuthSchemes(apiDoc)
+
+	// Rate limits, SLAs, and pricing are stated in the page's text
+	text := pageText(doc)
+	docLimits(apiDoc, nil, text)
+	apiDoc.Pricing = TextPricing(text)
+
+	return apiDoc, nil
+}
+
+// Helper functions for HTML parser
+
+// containsEndpointIndicators checks if text names an API endpoint: it has a path like /users/{id},
+// or an uppercase method like GET and none of the phrases of other headings, like "Overview"
+func (r *Rules) containsEndpointIndicators(text string) bool {
+	hasMethod := false
+	for _, word := range strings.Fields(text) {
+		if _, ok := pathToken(word); ok {
 			return true
 		}
+		if r.isMethodToken(word) && word == strings.ToUpper(word) {
+			hasMethod = true
+		}
 	}
-
-	// Check for URL path patterns
-	if strings.Contains(text, "/") &&
-		(strings.Contains(text, "{") || strings.Contains(text, ":")) {
-		return true
+	if !hasMethod {
+		return false
 	}
 
-	return false
+	lowerText := strings.ToLower(text)
+	for _, phrase := range r.NonEndpointHeadings {
+		if strings.Contains(lowerText, phrase) {
+			return false
+		}
+	}
+	return true
 }
 
-// extractMethodAndPath extracts HTTP method and path from text
-func extractMethodAndPath(text string) (string, string) {
+// extractMethodAndPath extracts HTTP method and path from text. The method is the first method
+// word, GET by default, and the path is "Unknown" when the text has none.
+func (r *Rules) extractMethodAndPath(text string) (string, string) {
 	// Default values
-	method := "GET"
-	path := "Unknown"
+	method := ""
+	path := UnknownPath
 
-	// Check for HTTP methods
-	methods := []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS", "HEAD"}
-	for _, m := range methods {
-		if strings.Contains(strings.ToUpper(text), m) {
-			method = m
-			break
+	for _, word := range strings.Fields(text) {
+		if method == "" && r.isMethodToken(word) {
+			method = strings.ToUpper(strings.Trim(word, tokenPunctuation))
 		}
+		if path == UnknownPath {
+			if token, ok := pathToken(word); ok {
+				path = token
+			}
+		}
+	}
+	if method == "" {
+		method = "GET"
 	}
 
-	// Extract path - look for patterns like /path, /path/{param}, etc.
-	words := strings.Fields(text)
-	for _, word := range words {
-		if strings.HasPrefix(word, "/") {
-			path = word
-			break
+	return method, path
+}
+
+// tokenPunctuation surrounds words of headings and code, like the quotes around `/users`
+const tokenPunctuation = "`'\"()[],;.:"
+
+// isMethodToken checks if a word is an HTTP method, in any case
+func (r *Rules) isMethodToken(word string) bool {
+	word = strings.ToUpper(strings.Trim(word, tokenPunctuation))
+	for _, method := range r.Methods {
+		if word == strings.ToUpper(method) {
+			return true
 		}
 	}
+	return false
+}
 
-	return method, path
+// pathToken returns the path of a word that is a path, like /users/{id}, or a URL with one
+func pathToken(word string) (string, bool) {
+	word = strings.Trim(word, "`'\"()[],;")
+	word = strings.TrimRight(word, ".:")
+	if i := strings.Index(word, "://"); i > 0 {
+		rest := word[i+3:]
+		slash := strings.Index(rest, "/")
+		if slash < 0 {
+			return "", false
+		}
+		word = rest[slash:]
+	}
+	if len(word) < 2 || word[0] != '/' {
+		return "", false
+	}
+	switch next := word[1]; {
+	case next >= 'a' && next <= 'z', next >= 'A' && next <= 'Z', next >= '0' && next <= '9', next == '{', next == ':', next == '<', next == '_':
+		return word, true
+	}
+	return "", false
 }
 
-// getStatusCodeDescription returns a description for common HTTP status codes
+// getStatusCodeDescription returns the standard reason text of an HTTP status code
 func getStatusCodeDescription(code int) string {
-	switch code {
-	case 200:
-		return "OK"
-	case 201:
-		return "Created"
-	case 204:
-		return "No Content"
-	case 400:
-		return "Bad Request"
-	case 401:
-		return "Unauthorized"
-	case 403:
-		return "Forbidden"
-	case 404:
-		return "Not Found"
-	case 500:
-		return "Internal Server Error"
-	default:
-		return "Unknown Status Code"
+	if text := http.StatusText(code); text != "" {
+		return text
 	}
+	return "Unknown Status Code"
 }
@@ -286,6 +286,51 @@ func TestHTMLParser(t *testing.T) {
 	}
 }
 
+// TestAsyncAPIParser tests the AsyncAPI parser
+func TestAsyncAPIParser(t *testing.T) {
+	parser := &AsyncAPIParser{}
+
+	apiDoc, err := parser.Parse([]byte(`asyncapi: 2.6.0
+info:
+  title: Orders Events
+  version: 1.2.0
+channels:
+  orders/created:
+    description: Order creation events
+    subscribe:
+      summary: Receive new orders
+    publish:
+      summary: Announce a new order
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse AsyncAPI: %v", err)
+	}
+
+	if apiDoc.Title != "Orders Events" {
+		t.Errorf("Expected title 'Orders Events', got '%s'", apiDoc.Title)
+	}
+
+	if len(apiDoc.Endpoints) != 2 {
+		t.Fatalf("Expected 2 endpoints, got %d", len(apiDoc.Endpoints))
+	}
+
+	subscribe := findEndpoint(apiDoc.Endpoints, "SUBSCRIBE", "orders/created")
+	if subscribe == nil {
+		t.Fatalf("SUBSCRIBE orders/created endpoint not found")
+	}
+
+	if subscribe.Description != "Order creation events" {
+		t.Errorf("Expected channel description fallback, got '%s'", subscribe.Description)
+	}
+	if subscribe.Kind != models.EndpointEvent {
+		t.Errorf("Expected an event endpoint, got %q", subscribe.Kind)
+	}
+
+	if _, err := parser.Parse([]byte(jsonTestData)); err == nil {
+		t.Errorf("Expected error parsing an OpenAPI document as AsyncAPI")
+	}
+}
+
 // Helper function to find an endpoint by method and path
 func findEndpoint(endpoints []models.Endpoint, method, path string) *models.Endpoint {
 	for i, endpoint := range endpoints {
@@ -295,3 +340,71 @@ func findEndpoint(endpoints []models.Endpoint, method, path string) *models.Endp
 	}
 	return nil
 }
+
+// linksTestData is an OpenAPI 3 document with links and callbacks
+const linksTestData = `{
+	"openapi": "3.0.0",
+	"info": {"title": "Orders API", "version": "1.0.0"},
+	"paths": {
+		"/orders": {
+			"post": {
+				"operationId": "createOrder",
+				"responses": {
+					"201": {
+						"description": "Created",
+						"links": {
+							"GetOrder": {"operationId": "getOrder", "parameters": {"id": "$response.body#/id"}},
+							"CancelOrder": {"$ref": "#/components/links/CancelOrder"}
+						}
+					}
+				},
+				"callbacks": {
+					"orderShipped": {
+						"{$request.body#/callbackUrl}": {
+							"post": {"summary": "Notifies the client that the order shipped", "responses": {"200": {"description": "OK"}}}
+						}
+					}
+				}
+			}
+		},
+		"/orders/{id}": {
+			"get": {"operationId": "getOrder", "responses": {"200": {"description": "OK"}}},
+			"delete": {"responses": {"204": {"description": "Cancelled"}}}
+		}
+	},
+	"components": {
+		"links": {
+			"CancelOrder": {"operationRef": "#/paths/~1orders~1{id}/delete", "parameters": {"id": "$response.body#/id"}}
+		}
+	}
+}`
+
+// TestOpenAPILinksAndCallbacks tests parsing OpenAPI 3 links and callbacks
+func TestOpenAPILinksAndCallbacks(t *testing.T) {
+	apiDoc, err := (&JSONParser{}).Parse([]byte(linksTestData))
+	if err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	create := findEndpoint(apiDoc.Endpoints, "POST", "/orders")
+	if create == nil || len(create.Links) != 2 {
+		t.Fatalf("Expected 2 links on POST /orders, got %+v", create)
+	}
+
+	cancel, get := create.Links[0], create.Links[1]
+	if cancel.Name != "CancelOrder" || cancel.Method != "DELETE" || cancel.Path != "/orders/{id}" {
+		t.Errorf("Expected the operationRef link to resolve to DELETE /orders/{id}, got %+v", cancel)
+	}
+	if get.Name != "GetOrder" || get.StatusCode != 201 || get.Method != "GET" || get.Path != "/orders/{id}" ||
+		get.Parameters["id"] != "$response.body#/id" {
+		t.Errorf("Expected the operationId link to resolve to GET /orders/{id}, got %+v", get)
+	}
+
+	if len(create.Callbacks) != 1 {
+		t.Fatalf("Expected 1 callback, got %+v", create.Callbacks)
+	}
+	callback := create.Callbacks[0]
+	if callback.Name != "orderShipped" || callback.Expression != "{$request.body#/callbackUrl}" || callback.Method != "POST" {
+		t.Errorf("Unexpected callback %+v", callback)
+	}
+}
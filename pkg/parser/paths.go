@@ -0,0 +1,173 @@
+package parser
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"universal_api/internal/models"
+)
+
+var (
+	// Path parameter syntaxes: {id}, {{id}} (Postman), :id (Express, Rails), and <id> or <int:id> (Flask)
+	bracedParam    = regexp.MustCompile(`^\{\{?([^{}]+)\}?\}$`)
+	colonParam     = regexp.MustCompile(`^:([A-Za-z_][A-Za-z0-9_-]*)$`)
+	angleParam     = regexp.MustCompile(`^<(?:([A-Za-z]+):)?([A-Za-z_][A-Za-z0-9_.-]*)>$`)
+	pathParamNames = regexp.MustCompile(`\{([^{}]+)\}`)
+	slashes        = regexp.MustCompile(`/{2,}`)
+	// placeholderValue matches query values that stand for a value, like {limit}, <limit>, :limit, or ...
+	placeholderValue = regexp.MustCompile(`^(\{.*\}|<.*>|:\w+|\.\.\.|\$\w+)$`)
+)
+
+// angleParamTypes maps Flask converters to parameter types
+var angleParamTypes = map[string]string{"int": "integer", "float": "number", "uuid": "string", "path": "string", "string": "string"}
+
+// NormalizePath unifies the parameter syntax of a path to {name}, collapses duplicate slashes,
+// and strips the trailing slash. A query string or fragment is kept as it is.
+func NormalizePath(path string) string {
+	path, _ = normalizePath(path)
+	return path
+}
+
+// normalizePath normalizes a path, also returning the types that parameters declared in it
+func normalizePath(path string) (string, map[string]string) {
+	rest := ""
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path, rest = path[:i], path[i:]
+	}
+	if !strings.HasPrefix(path, "/") {
+		return path + rest, nil
+	}
+
+	types := make(map[string]string)
+	segments := strings.Split(slashes.ReplaceAllString(path, "/"), "/")
+	for i, segment := range segments {
+		if match := bracedParam.FindStringSubmatch(segment); match != nil {
+			segments[i] = "{" + strings.TrimSpace(match[1]) + "}"
+		} else if match := colonParam.FindStringSubmatch(segment); match != nil {
+			segments[i] = "{" + match[1] + "}"
+		} else if match := angleParam.FindStringSubmatch(segment); match != nil {
+			segments[i] = "{" + match[2] + "}"
+			if paramType, ok := angleParamTypes[strings.ToLower(match[1])]; ok {
+				types[match[2]] = paramType
+			}
+		}
+	}
+
+	path = strings.Join(segments, "/")
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path + rest, types
+}
+
+// PathParameters returns the names of the parameters of a normalized path, in order
+func PathParameters(path string) []string {
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	var names []string
+	for _, match := range pathParamNames.FindAllStringSubmatch(path, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// normalizeEndpoint normalizes an endpoint's path and makes sure each of its path parameters is in
+// its parameters: parameters named like one are moved to the path, and missing ones are added. The
+// query string of an example URL is stripped from the path, its keys becoming query parameters.
+func normalizeEndpoint(endpoint *models.Endpoint) {
+	path, types := normalizePath(endpoint.Path)
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		addQueryParameters(endpoint, path[i:])
+		path = path[:i]
+	}
+	endpoint.Path = path
+
+	for _, name := range PathParameters(path) {
+		found := false
+		for i := range endpoint.Parameters {
+			param := &endpoint.Parameters[i]
+			if paramName := strings.TrimSpace(param.Name); paramName == name || paramName == ":"+name || paramName == "{"+name+"}" {
+				param.Name, param.In, param.Required = name, "path", true
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+
+		paramType := types[name]
+		if paramType == "" {
+			paramType = "string"
+		}
+		endpoint.Parameters = append(endpoint.Parameters, models.Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Type:     paramType,
+		})
+	}
+}
+
+// addQueryParameters adds the keys of an example URL's query string to an endpoint's query
+// parameters, with their values as examples. Placeholders like {limit} aren't examples.
+func addQueryParameters(endpoint *models.Endpoint, query string) {
+	query = strings.TrimPrefix(query, "?")
+	if i := strings.Index(query, "#"); i >= 0 {
+		query = query[:i]
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil && len(values) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, strings.TrimSuffix(name, "[]"))
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		example := values.Get(name)
+		if example == "" {
+			example = values.Get(name + "[]")
+		}
+		if placeholderValue.MatchString(example) {
+			example = ""
+		}
+
+		if i := findParameter(endpoint.Parameters, models.Parameter{Name: name, In: "query"}); i >= 0 {
+			if endpoint.Parameters[i].Example == "" {
+				endpoint.Parameters[i].Example = example
+			}
+			continue
+		}
+		endpoint.Parameters = append(endpoint.Parameters, models.Parameter{
+			Name:    name,
+			In:      "query",
+			Type:    exampleType(example),
+			Example: example,
+		})
+	}
+}
+
+// exampleType infers the type of a parameter from an example value
+func exampleType(example string) string {
+	if _, err := strconv.ParseInt(example, 10, 64); err == nil {
+		return "integer"
+	}
+	if _, err := strconv.ParseFloat(example, 64); err == nil {
+		return "number"
+	}
+	if example == "true" || example == "false" {
+		return "boolean"
+	}
+	return "string"
+}
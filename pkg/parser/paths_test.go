@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestNormalizePath tests unifying path parameter syntax and slashes
+func TestNormalizePath(t *testing.T) {
+	tests := map[string]string{
+		"/users/{id}":                "/users/{id}",
+		"/users/:id":                 "/users/{id}",
+		"/users/<id>":                "/users/{id}",
+		"/users/<int:id>/posts/":     "/users/{id}/posts",
+		"/users/{{userId}}":          "/users/{userId}",
+		"//users///:id/":             "/users/{id}",
+		"/":                          "/",
+		"/search/?q=a//b":            "/search?q=a//b",
+		"/orgs/:org/repos/:repo.git": "/orgs/{org}/repos/:repo.git",
+		"Unknown":                    "Unknown",
+	}
+
+	for path, expected := range tests {
+		if got := NormalizePath(path); got != expected {
+			t.Errorf("NormalizePath(%q): expected %q, got %q", path, expected, got)
+		}
+	}
+}
+
+// TestHTMLParserPathParameters tests that HTML-scraped endpoints get normalized paths and their path parameters
+func TestHTMLParserPathParameters(t *testing.T) {
+	page := `<html><head><title>Users API</title></head><body>
+<h2>GET /users/:userId/posts/&lt;int:postId&gt;/</h2>
+<p>Get a post of a user</p>
+<table><tr><th>Name</th><th>Description</th></tr><tr><td>userId</td><td>The user</td></tr></table>
+</body></html>`
+
+	apiDoc, err := (&HTMLParser{}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	endpoint := findEndpoint(apiDoc.Endpoints, "GET", "/users/{userId}/posts/{postId}")
+	if endpoint == nil {
+		t.Fatalf("GET /users/{userId}/posts/{postId} endpoint not found in %v", apiDoc.Endpoints)
+	}
+	if len(endpoint.Parameters) != 2 {
+		t.Fatalf("Expected 2 parameters, got %v", endpoint.Parameters)
+	}
+
+	userID, postID := endpoint.Parameters[0], endpoint.Parameters[1]
+	if userID.Name != "userId" || userID.In != "path" || !userID.Required || userID.Description != "The user" {
+		t.Errorf("Expected the documented userId to be a required path parameter, got %+v", userID)
+	}
+	if postID.Name != "postId" || postID.In != "path" || !postID.Required || postID.Type != "integer" {
+		t.Errorf("Expected postId to be a required integer path parameter, got %+v", postID)
+	}
+}
+
+// TestHTMLParserQueryParameters tests that the query string of an example URL becomes query parameters
+func TestHTMLParserQueryParameters(t *testing.T) {
+	page := `<html><head><title>Users API</title></head><body>
+<pre>GET https://api.example.com/users?limit=10&amp;offset=0&amp;active=true&amp;sort={field}&amp;tags[]=admin</pre>
+</body></html>`
+
+	apiDoc, err := (&HTMLParser{}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	endpoint := findEndpoint(apiDoc.Endpoints, "GET", "/users")
+	if endpoint == nil {
+		t.Fatalf("GET /users endpoint not found in %v", apiDoc.Endpoints)
+	}
+
+	expected := []models.Parameter{
+		{Name: "active", In: "query", Type: "boolean", Example: "true"},
+		{Name: "limit", In: "query", Type: "integer", Example: "10"},
+		{Name: "offset", In: "query", Type: "integer", Example: "0"},
+		{Name: "sort", In: "query", Type: "string"},
+		{Name: "tags", In: "query", Type: "string", Example: "admin"},
+	}
+	if len(endpoint.Parameters) != len(expected) {
+		t.Fatalf("Expected %d query parameters, got %v", len(expected), endpoint.Parameters)
+	}
+	for i, param := range expected {
+		if endpoint.Parameters[i] != param {
+			t.Errorf("Expected parameter %+v, got %+v", param, endpoint.Parameters[i])
+		}
+	}
+}
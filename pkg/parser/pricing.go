@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"universal_api/internal/models"
+)
+
+// freeTierStatement matches mentions of a free tier, like "free tier", "free plan", or "10,000 calls per month for free"
+var freeTierStatement = regexp.MustCompile(`(?i)\bfree\s+(?:tier|plan|quota|usage|trial)\b|\bfree\s+of\s+charge\b|\bfree\s+for\s+up\s+to\b|\b(?:per|a|/)\s*month\s+(?:for\s+)?free\b|\bfree\s+(?:up\s+to\s+)?\d[\d,]*\s+(?:requests?|calls?)`)
+
+// planName matches the names of plans, like "Pro plan" or "Enterprise tier"
+var planName = regexp.MustCompile(`\b(Free|Hobby|Starter|Basic|Developer|Standard|Pro|Professional|Plus|Team|Business|Growth|Scale|Premium|Enterprise|Ultimate)\s+(?:plan|tier)s?\b`)
+
+// planPrice matches prices like "$29/month", "€99 per month", or "$0.01 per request"
+var planPrice = regexp.MustCompile(`[$€£]\s?\d[\d,]*(?:\.\d+)?\s*(?:/|per\s+)\s*(?:month|mo|year|yr|request|call|user)\b`)
+
+// hasNumber matches text with a number, to tell the statements of free tier limits from mere mentions
+var hasNumber = regexp.MustCompile(`\d`)
+
+// TextPricing returns the pricing information stated in text, or nil if it has none
+func TextPricing(text string) *models.Pricing {
+	pricing := &models.Pricing{}
+	seenLimits := make(map[string]bool)
+	seenPlans := make(map[string]int)
+
+	for _, sentence := range sentences(text) {
+		if freeTierStatement.MatchString(sentence) {
+			pricing.FreeTier = true
+			if hasNumber.MatchString(sentence) && !seenLimits[sentence] {
+				seenLimits[sentence] = true
+				pricing.FreeTierLimits = append(pricing.FreeTierLimits, sentence)
+			}
+		}
+
+		prices := planPrice.FindAllStringIndex(sentence, -1)
+		matches := planName.FindAllStringSubmatchIndex(sentence, -1)
+		for i, match := range matches {
+			name := sentence[match[2]:match[3]]
+			// A plan's price follows its name, before the next plan's
+			next := len(sentence)
+			if i+1 < len(matches) {
+				next = matches[i+1][0]
+			}
+			price := ""
+			for _, at := range prices {
+				if at[0] >= match[1] && at[0] < next {
+					price = sentence[at[0]:at[1]]
+					break
+				}
+			}
+			if name == "Free" {
+				pricing.FreeTier = true
+			}
+			if i, ok := seenPlans[name]; ok {
+				if pricing.Plans[i].Price == "" {
+					pricing.Plans[i].Price = price
+				}
+				continue
+			}
+			seenPlans[name] = len(pricing.Plans)
+			pricing.Plans = append(pricing.Plans, models.Plan{Name: name, Price: price})
+		}
+	}
+
+	if !pricing.FreeTier && len(pricing.Plans) == 0 {
+		return nil
+	}
+	return pricing
+}
+
+// sentences splits text into its sentences and lines, with whitespace collapsed and long ones dropped
+func sentences(text string) []string {
+	var result []string
+	from := 0
+	for i := 0; i <= len(text); i++ {
+		if i < len(text) && !sentenceEnd(text, i) {
+			continue
+		}
+		end := min(i+1, len(text))
+		if sentence := strings.Join(strings.Fields(text[from:end]), " "); sentence != "" && len(sentence) <= maxStatementLength {
+			result = append(result, sentence)
+		}
+		from = end
+	}
+	return result
+}
@@ -0,0 +1,33 @@
+package parser
+
+import (
+	"testing"
+)
+
+// TestTextPricing tests finding free tiers, their limits, and plans with their prices
+func TestTextPricing(t *testing.T) {
+	text := `Get started on the Free plan with 1,000 requests per month.
+The Pro plan costs $29/month and the Enterprise plan is priced per contract.
+Upgrade to the Pro tier any time.`
+
+	pricing := TextPricing(text)
+	if pricing == nil || !pricing.FreeTier {
+		t.Fatalf("Expected a free tier, got %+v", pricing)
+	}
+	if len(pricing.FreeTierLimits) != 1 || pricing.FreeTierLimits[0] != "Get started on the Free plan with 1,000 requests per month." {
+		t.Errorf("Expected the free tier limit statement, got %v", pricing.FreeTierLimits)
+	}
+	if len(pricing.Plans) != 3 {
+		t.Fatalf("Expected 3 plans, got %+v", pricing.Plans)
+	}
+	if pricing.Plans[1].Name != "Pro" || pricing.Plans[1].Price != "$29/month" {
+		t.Errorf("Expected the Pro plan at $29/month, got %+v", pricing.Plans[1])
+	}
+	if pricing.Plans[2].Name != "Enterprise" || pricing.Plans[2].Price != "" {
+		t.Errorf("Expected the Enterprise plan without a price, got %+v", pricing.Plans[2])
+	}
+
+	if pricing := TextPricing("Returns the price of a product in cents."); pricing != nil {
+		t.Errorf("Expected no pricing, got %+v", pricing)
+	}
+}
@@ -0,0 +1,411 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"sort"
+	"strings"
+
+	"universal_api/internal/models"
+)
+
+// Content types of request bodies that get examples in their own format
+const (
+	contentTypeJSON      = "application/json"
+	contentTypeForm      = "application/x-www-form-urlencoded"
+	contentTypeMultipart = "multipart/form-data"
+)
+
+// exampleBoundary separates the parts of generated multipart/form-data examples
+const exampleBoundary = "example-boundary"
+
+// filePlaceholder stands for the content of files in multipart/form-data examples
+const filePlaceholder = "<file contents>"
+
+// maxExampleDepth bounds the nesting of examples generated from schemas
+const maxExampleDepth = 6
+
+// operationRequestBodies returns the request bodies of the raw operation at a path and method of a spec's
+// paths or webhooks section, one per content type it accepts: from the requestBody of OpenAPI 3 operations,
+// or from the body and formData parameters and consumes of Swagger 2.0 operations
+func operationRequestBodies(root map[string]interface{}, section, path, method string) []models.RequestBody {
+	pointer := "#/" + section + "/" + strings.ReplaceAll(strings.ReplaceAll(path, "~", "~0"), "/", "~1") + "/" + strings.ToLower(method)
+	operation, _ := lookupRef(root, pointer).(map[string]interface{})
+	if operation == nil {
+		return nil
+	}
+
+	if requestBody, ok := resolveRefs(operation["requestBody"], root, 0).(map[string]interface{}); ok {
+		return openAPIRequestBodies(requestBody)
+	}
+	return swaggerRequestBodies(operation, root)
+}
+
+// openAPIRequestBodies returns the request bodies of a resolved OpenAPI 3 request body object, sorted by content type
+func openAPIRequestBodies(requestBody map[string]interface{}) []models.RequestBody {
+	content, _ := requestBody["content"].(map[string]interface{})
+	required, _ := requestBody["required"].(bool)
+
+	contentTypes := make([]string, 0, len(content))
+	for contentType := range content {
+		contentTypes = append(contentTypes, contentType)
+	}
+	sort.Strings(contentTypes)
+
+	var bodies []models.RequestBody
+	for _, contentType := range contentTypes {
+		media, _ := content[contentType].(map[string]interface{})
+		example, ok := media["example"]
+		if !ok {
+			example = namedExample(media["examples"])
+		}
+		bodies = append(bodies, requestBodyOf(contentType, required, media["schema"], example))
+	}
+	return bodies
+}
+
+// namedExample returns the value of the first of named examples, by name, or nil
+func namedExample(examples interface{}) interface{} {
+	examplesMap, _ := examples.(map[string]interface{})
+	names := make([]string, 0, len(examplesMap))
+	for name := range examplesMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if example, ok := examplesMap[name].(map[string]interface{}); ok && example["value"] != nil {
+			return example["value"]
+		}
+	}
+	return nil
+}
+
+// swaggerRequestBodies returns the request bodies of a Swagger 2.0 operation: its body parameter's schema, or
+// an object of its formData parameters, in each content type it consumes
+func swaggerRequestBodies(operation map[string]interface{}, root map[string]interface{}) []models.RequestBody {
+	params, _ := resolveRefs(operation["parameters"], root, 0).([]interface{})
+
+	var schema map[string]interface{}
+	required := false
+	properties := make(map[string]interface{})
+	var requiredFields []interface{}
+	hasFile := false
+	for _, param := range params {
+		paramMap, _ := param.(map[string]interface{})
+		name, _ := paramMap["name"].(string)
+		paramRequired, _ := paramMap["required"].(bool)
+		switch paramMap["in"] {
+		case "body":
+			schema, _ = paramMap["schema"].(map[string]interface{})
+			required = paramRequired
+		case "formData":
+			property := make(map[string]interface{})
+			for _, key := range []string{"type", "format", "description", "items", "enum", "default"} {
+				if value, ok := paramMap[key]; ok {
+					property[key] = value
+				}
+			}
+			hasFile = hasFile || paramMap["type"] == "file"
+			properties[name] = property
+			if paramRequired {
+				requiredFields = append(requiredFields, name)
+				required = true
+			}
+		}
+	}
+	if schema == nil && len(properties) > 0 {
+		schema = map[string]interface{}{"type": "object", "properties": properties}
+		if len(requiredFields) > 0 {
+			schema["required"] = requiredFields
+		}
+	}
+	if schema == nil {
+		return nil
+	}
+
+	consumes := stringList(operation["consumes"])
+	if len(consumes) == 0 {
+		consumes = stringList(root["consumes"])
+	}
+	if len(consumes) == 0 {
+		switch {
+		case hasFile:
+			consumes = []string{contentTypeMultipart}
+		case len(properties) > 0:
+			consumes = []string{contentTypeForm}
+		default:
+			consumes = []string{contentTypeJSON}
+		}
+	}
+
+	bodies := make([]models.RequestBody, 0, len(consumes))
+	for _, contentType := range consumes {
+		bodies = append(bodies, requestBodyOf(contentType, required, schema, nil))
+	}
+	return bodies
+}
+
+// stringList returns the strings of a decoded JSON array
+func stringList(value interface{}) []string {
+	values, _ := value.([]interface{})
+	var result []string
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// requestBodyOf returns a request body in a content type with a schema, and its example: the
+// documented one, or one generated from the schema
+func requestBodyOf(contentType string, required bool, schema, example interface{}) models.RequestBody {
+	body := models.RequestBody{ContentType: contentType, Required: required}
+	if schema != nil {
+		if data, err := json.Marshal(schema); err == nil {
+			body.Schema = string(data)
+		}
+	}
+	if example == nil {
+		example = schemaExample(schema, 0)
+	}
+	body.Example = formatExample(contentType, example, fileProperties(schema))
+	return body
+}
+
+// fileProperties returns the names of the properties of an object schema holding files: binary strings, or
+// the file type of Swagger 2.0 form fields
+func fileProperties(schema interface{}) map[string]bool {
+	schemaMap, _ := schema.(map[string]interface{})
+	properties, _ := schemaMap["properties"].(map[string]interface{})
+	files := make(map[string]bool)
+	for name, property := range properties {
+		if isFileSchema(property) {
+			files[name] = true
+		}
+	}
+	return files
+}
+
+// isFileSchema checks if a schema describes a file: a binary string, or the file type of Swagger 2.0
+func isFileSchema(schema interface{}) bool {
+	schemaMap, _ := schema.(map[string]interface{})
+	return schemaMap["type"] == "file" || schemaMap["type"] == "string" && schemaMap["format"] == "binary"
+}
+
+// schemaExample returns an example value of a resolved JSON schema nested depth levels deep: its example,
+// default, or first enum value, or a value of its type built from the examples of its properties and items
+func schemaExample(schema interface{}, depth int) interface{} {
+	schemaMap, _ := schema.(map[string]interface{})
+	if schemaMap == nil || depth > maxExampleDepth {
+		return nil
+	}
+	for _, key := range []string{"example", "default"} {
+		if value, ok := schemaMap[key]; ok {
+			return value
+		}
+	}
+	if enum, ok := schemaMap["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+
+	if allOf, ok := schemaMap["allOf"].([]interface{}); ok {
+		merged := make(map[string]interface{})
+		for _, part := range allOf {
+			if fields, ok := schemaExample(part, depth+1).(map[string]interface{}); ok {
+				for name, value := range fields {
+					merged[name] = value
+				}
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if alternatives, ok := schemaMap[key].([]interface{}); ok && len(alternatives) > 0 {
+			return schemaExample(alternatives[0], depth+1)
+		}
+	}
+
+	schemaType, _ := schemaMap["type"].(string)
+	properties, hasProperties := schemaMap["properties"].(map[string]interface{})
+	switch {
+	case schemaType == "object" || hasProperties:
+		fields := make(map[string]interface{}, len(properties))
+		for name, property := range properties {
+			fields[name] = schemaExample(property, depth+1)
+		}
+		return fields
+	case schemaType == "array":
+		return []interface{}{schemaExample(schemaMap["items"], depth+1)}
+	case schemaType == "integer" || schemaType == "number":
+		return 0
+	case schemaType == "boolean":
+		return false
+	case schemaType == "string":
+		return stringExample(schemaMap["format"])
+	}
+	return nil
+}
+
+// stringExample returns an example string in a format
+func stringExample(format interface{}) string {
+	switch format {
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case "uri", "url":
+		return "https://example.com"
+	}
+	return "string"
+}
+
+// formatExample writes an example value as a body in a content type: indented JSON for JSON types,
+// fields for form-encoded and multipart bodies, where files are file parts, and strings as they are
+// for other types. It returns "" for values that can't be written in the content type.
+func formatExample(contentType string, value interface{}, files map[string]bool) string {
+	if value == nil {
+		return ""
+	}
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+
+	switch {
+	case strings.Contains(mediaType, "json"):
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	case mediaType == contentTypeForm:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		form := url.Values{}
+		for name, field := range fields {
+			form.Set(name, formValue(field))
+		}
+		return form.Encode()
+	case mediaType == contentTypeMultipart:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		filenames := make(map[string]string, len(files))
+		for name := range files {
+			filenames[name] = name + ".bin"
+		}
+		return multipartExample(fields, filenames)
+	}
+
+	if text, ok := value.(string); ok {
+		return text
+	}
+	return ""
+}
+
+// multipartExample writes the fields of a multipart/form-data example body, sorted by name. Fields
+// with a filename are file parts, with a placeholder for their content.
+func multipartExample(fields map[string]interface{}, filenames map[string]string) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	writer.SetBoundary(exampleBoundary)
+	for _, name := range names {
+		filename, ok := filenames[name]
+		if !ok {
+			writer.WriteField(name, formValue(fields[name]))
+			continue
+		}
+		if part, err := writer.CreateFormFile(name, filename); err == nil {
+			part.Write([]byte(filePlaceholder))
+		}
+	}
+	writer.Close()
+	return buf.String()
+}
+
+// formValue writes a field of a form: strings as they are, and other values as JSON
+func formValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	case float64, int, bool:
+		return fmt.Sprint(v)
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// requestBodyParameters returns the body parameters of an endpoint described by its request bodies: the
+// properties of their object schemas, sorted by name. JSON schemas come first, and binary properties,
+// which multipart bodies often add, are files.
+func requestBodyParameters(bodies []models.RequestBody) []models.Parameter {
+	ordered := make([]models.RequestBody, len(bodies))
+	copy(ordered, bodies)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return strings.Contains(ordered[i].ContentType, "json") && !strings.Contains(ordered[j].ContentType, "json")
+	})
+
+	var params []models.Parameter
+	seen := make(map[string]bool)
+	for _, body := range ordered {
+		var schema map[string]interface{}
+		if json.Unmarshal([]byte(body.Schema), &schema) != nil {
+			continue
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		required := make(map[string]bool)
+		for _, name := range stringList(schema["required"]) {
+			required[name] = true
+		}
+
+		for name, property := range properties {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			propertyMap, _ := property.(map[string]interface{})
+			paramType, _ := propertyMap["type"].(string)
+			if isFileSchema(propertyMap) {
+				paramType = "file"
+			}
+			description, _ := propertyMap["description"].(string)
+			params = append(params, models.Parameter{
+				Name:        name,
+				In:          "body",
+				Required:    required[name],
+				Type:        paramType,
+				Description: description,
+			})
+		}
+	}
+	sort.Slice(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+	return params
+}
+
+// hasBodyParameters checks if parameters include body parameters
+func hasBodyParameters(params []models.Parameter) bool {
+	for _, param := range params {
+		if param.In == "body" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestJSONParserRequestBodies tests modeling the request body of an operation in each content type it accepts
+func TestJSONParserRequestBodies(t *testing.T) {
+	spec := `{
+		"openapi": "3.0.0",
+		"info": {"title": "Pets API", "version": "1.0"},
+		"paths": {"/pets": {"post": {
+			"requestBody": {"required": true, "content": {
+				"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}},
+				"application/x-www-form-urlencoded": {"schema": {"$ref": "#/components/schemas/Pet"}},
+				"multipart/form-data": {"schema": {"$ref": "#/components/schemas/Pet"}}
+			}},
+			"responses": {"201": {"description": "Created"}}
+		}}},
+		"components": {"schemas": {"Pet": {"type": "object", "required": ["name"], "properties": {
+			"name": {"type": "string", "example": "Rex"},
+			"age": {"type": "integer"}
+		}}}}
+	}`
+
+	apiDoc, err := (&JSONParser{}).Parse([]byte(spec))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+	endpoint := apiDoc.Endpoints[0]
+	if len(endpoint.RequestBodies) != 3 {
+		t.Fatalf("Expected 3 request bodies, got %+v", endpoint.RequestBodies)
+	}
+
+	examples := map[string]string{}
+	for _, body := range endpoint.RequestBodies {
+		if !body.Required || body.Schema == "" {
+			t.Errorf("Expected a required body with a schema, got %+v", body)
+		}
+		examples[body.ContentType] = body.Example
+	}
+	if json := examples["application/json"]; !strings.Contains(json, `"name": "Rex"`) || !strings.Contains(json, `"age": 0`) {
+		t.Errorf("Unexpected JSON example: %s", json)
+	}
+	if form := examples["application/x-www-form-urlencoded"]; form != "age=0&name=Rex" {
+		t.Errorf("Unexpected form example: %s", form)
+	}
+	if multipart := examples["multipart/form-data"]; !strings.Contains(multipart, `Content-Disposition: form-data; name="name"`) || !strings.Contains(multipart, "--"+exampleBoundary) {
+		t.Errorf("Unexpected multipart example: %s", multipart)
+	}
+
+	if len(endpoint.Parameters) != 2 || endpoint.Parameters[1].Name != "name" || !endpoint.Parameters[1].Required {
+		t.Errorf("Expected body parameters from the schema's properties, got %+v", endpoint.Parameters)
+	}
+}
+
+// TestSwaggerRequestBodies tests modeling the form fields of Swagger 2.0 operations in the content types they consume
+func TestSwaggerRequestBodies(t *testing.T) {
+	spec := `{
+		"swagger": "2.0",
+		"info": {"title": "Pets API", "version": "1.0"},
+		"paths": {"/pets": {"post": {
+			"consumes": ["application/x-www-form-urlencoded"],
+			"parameters": [{"name": "name", "in": "formData", "type": "string", "required": true}],
+			"responses": {"201": {"description": "Created"}}
+		}}}
+	}`
+
+	apiDoc, err := (&JSONParser{}).Parse([]byte(spec))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+	endpoint := apiDoc.Endpoints[0]
+	if len(endpoint.RequestBodies) != 1 || endpoint.RequestBodies[0].ContentType != "application/x-www-form-urlencoded" || endpoint.RequestBodies[0].Example != "name=string" {
+		t.Errorf("Expected a form-encoded request body, got %+v", endpoint.RequestBodies)
+	}
+	if endpoint.Parameters[0].In != "body" {
+		t.Errorf("Expected the form field as a body parameter, got %+v", endpoint.Parameters[0])
+	}
+}
+
+// TestRequestContentType tests telling the content type of documented request bodies
+func TestRequestContentType(t *testing.T) {
+	tests := []struct {
+		body     string
+		headers  map[string]string
+		expected string
+	}{
+		{`{"amount": 100}`, nil, "application/json"},
+		{"amount=100&currency=usd", nil, "application/x-www-form-urlencoded"},
+		{"amount=100", map[string]string{"content-type": "text/csv"}, "text/csv"},
+	}
+	for _, test := range tests {
+		if got := requestContentType(test.body, test.headers); got != test.expected {
+			t.Errorf("requestContentType(%q): expected %q, got %q", test.body, test.expected, got)
+		}
+	}
+}
+
+// TestJSONParserFileUploads tests modeling the binary properties of multipart bodies as file parameters
+func TestJSONParserFileUploads(t *testing.T) {
+	spec := `{
+		"openapi": "3.0.0",
+		"info": {"title": "Files API", "version": "1.0"},
+		"paths": {"/files": {"post": {
+			"requestBody": {"content": {"multipart/form-data": {"schema": {"type": "object", "required": ["file"], "properties": {
+				"file": {"type": "string", "format": "binary"},
+				"purpose": {"type": "string", "example": "fine-tune"}
+			}}}}},
+			"responses": {"200": {"description": "OK"}}
+		}}}
+	}`
+
+	apiDoc, err := (&JSONParser{}).Parse([]byte(spec))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+	endpoint := apiDoc.Endpoints[0]
+	if len(endpoint.Parameters) != 2 || endpoint.Parameters[0].Name != "file" || endpoint.Parameters[0].Type != "file" || !endpoint.Parameters[0].Required {
+		t.Fatalf("Expected a required file parameter, got %+v", endpoint.Parameters)
+	}
+	example := endpoint.RequestBodies[0].Example
+	if !strings.Contains(example, `name="file"; filename="file.bin"`) || !strings.Contains(example, "fine-tune") {
+		t.Errorf("Expected a file part and the purpose field in the example, got %s", example)
+	}
+}
+
+// TestCurlFormUploads tests modeling the -F fields of curl samples as multipart bodies with file parameters
+func TestCurlFormUploads(t *testing.T) {
+	request, ok := parseCurl(`curl https://api.example.com/v1/files -F purpose=fine-tune -F "file=@data.jsonl"`)
+	if !ok {
+		t.Fatal("Expected a curl command")
+	}
+	endpoint := curlEndpoint(request)
+
+	if endpoint.Method != "POST" || len(endpoint.RequestBodies) != 1 || endpoint.RequestBodies[0].ContentType != "multipart/form-data" {
+		t.Fatalf("Expected a multipart POST, got %s %+v", endpoint.Method, endpoint.RequestBodies)
+	}
+	types := map[string]string{}
+	for _, param := range endpoint.Parameters {
+		types[param.Name] = param.Type
+	}
+	if types["file"] != "file" || types["purpose"] != "string" {
+		t.Errorf("Expected file and purpose body parameters, got %+v", endpoint.Parameters)
+	}
+}
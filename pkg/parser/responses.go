@@ -0,0 +1,207 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+
+	"universal_api/internal/models"
+)
+
+var (
+	// statusCodePattern matches a status code as a whole word, like the 201 of "Response (201 Created)"
+	// but not the digits of an ID like 12005
+	statusCodePattern = regexp.MustCompile(`\b([1-5][0-9][0-9])\b`)
+	// statusLine matches the status line of a raw HTTP response, like "HTTP/1.1 404 Not Found"
+	statusLine = regexp.MustCompile(`HTTP/[0-9.]+[ \t]+([1-5][0-9][0-9])\b[ \t]*([^\r\n]*)`)
+)
+
+// reasonSeparators separate a status code from its reason text, as in "404 - Not Found"
+const reasonSeparators = " \t-–—:()"
+
+// addSectionResponses adds the responses documented in an endpoint's section. Status codes are
+// read only from the parts of the section that list responses: the content under headings like
+// "Responses" or "Errors", tables of status codes, and tables and lists labelled as responses.
+// The text after a code, like the "Not Found" of "404 Not Found", is the response's description.
+// The status lines of raw HTTP responses in code blocks count anywhere in the section.
+func addSectionResponses(endpoint *models.Endpoint, content *goquery.Selection, rules *Rules) {
+	responseLevel := 0
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		if node.Type != html.ElementNode {
+			return
+		}
+		element := content.Slice(0, 0).AddNodes(node)
+
+		if level := headingLevel(node); level > 0 {
+			switch {
+			case responseLevel > 0 && level > responseLevel:
+				// A heading like "404 Not Found" under Responses
+				addStatusCodes(endpoint, element.Text(), rules.statusCode)
+			case rules.isResponseLabel(strings.ToLower(element.Text())):
+				responseLevel = level
+			default:
+				responseLevel = 0
+			}
+			return
+		}
+
+		switch goquery.NodeName(element) {
+		case "pre", "code":
+			for _, match := range statusLine.FindAllStringSubmatch(element.Text(), -1) {
+				code, _ := strconv.Atoi(match[1])
+				addResponse(endpoint, code, strings.TrimSpace(match[2]))
+			}
+			return
+		case "table":
+			if responseLevel > 0 || isStatusTable(element) || rules.isResponseLabel(label(element)) {
+				element.Find("tr").Each(func(_ int, row *goquery.Selection) {
+					addStatusRow(endpoint, row.Find("td, th"), rules.statusCode)
+				})
+			}
+			return
+		case "ul", "ol", "dl":
+			if responseLevel > 0 || rules.isResponseLabel(label(element)) {
+				element.Find("li, dt").Each(func(_ int, item *goquery.Selection) {
+					text := item.Text()
+					if goquery.NodeName(item) == "dt" {
+						text += " " + item.NextFiltered("dd").Text()
+					}
+					addStatusCodes(endpoint, text, rules.statusCode)
+				})
+			}
+			return
+		case "p":
+			if responseLevel > 0 {
+				addStatusCodes(endpoint, element.Text(), rules.statusCode)
+			}
+			return
+		}
+
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+
+	for _, node := range content.Nodes {
+		walk(node)
+	}
+}
+
+// isResponseLabel checks if a lowercased heading or label introduces a list of responses
+func (r *Rules) isResponseLabel(text string) bool {
+	for _, phrase := range r.ResponseLabels {
+		if strings.Contains(text, strings.ToLower(phrase)) {
+			return true
+		}
+	}
+	return false
+}
+
+// addStatusCodes adds a response for the first status code of each line of text, with the rest of
+// the line as its reason
+func addStatusCodes(endpoint *models.Endpoint, text string, statusCode *regexp.Regexp) {
+	for _, line := range strings.Split(text, "\n") {
+		match := statusCode.FindStringSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+		code, _ := strconv.Atoi(line[match[2]:match[3]])
+		addResponse(endpoint, code, strings.Trim(line[match[1]:], reasonSeparators))
+	}
+}
+
+// addStatusRow adds the response of a table row whose first cell holds a status code. Its
+// description is the row's last cell, or the reason after the code in the first cell.
+func addStatusRow(endpoint *models.Endpoint, cells *goquery.Selection, statusCode *regexp.Regexp) {
+	if cells.Length() == 0 {
+		return
+	}
+	first := strings.TrimSpace(cells.First().Text())
+	match := statusCode.FindStringSubmatchIndex(first)
+	if match == nil || match[0] != 0 {
+		return
+	}
+	code, _ := strconv.Atoi(first[match[2]:match[3]])
+	reason := strings.Trim(first[match[1]:], reasonSeparators)
+	if cells.Length() > 1 {
+		if description := strings.TrimSpace(cells.Last().Text()); description != "" {
+			reason = description
+		}
+	}
+	addResponse(endpoint, code, reason)
+}
+
+// addResponse adds a response with a status code to an endpoint, described by its reason text or
+// else the code's standard text. A documented reason replaces a standard description, and the
+// standard text before a reason, like the "OK" of "200 OK - The order", is dropped.
+func addResponse(endpoint *models.Endpoint, code int, reason string) {
+	reason = strings.Join(strings.Fields(reason), " ")
+	if standard := getStatusCodeDescription(code); len(reason) > len(standard) &&
+		strings.EqualFold(reason[:len(standard)], standard) && strings.ContainsRune(reasonSeparators, rune(reason[len(standard)])) {
+		reason = strings.TrimLeft(reason[len(standard):], reasonSeparators)
+	}
+	reason = Truncate(reason, maxLabelLength)
+	i := findResponse(endpoint.Responses, code)
+	if i < 0 {
+		if reason == "" {
+			reason = getStatusCodeDescription(code)
+		}
+		endpoint.Responses = append(endpoint.Responses, models.Response{StatusCode: code, Description: reason})
+		return
+	}
+	if existing := &endpoint.Responses[i]; reason != "" && (existing.Description == "" || existing.Description == getStatusCodeDescription(code)) {
+		existing.Description = reason
+	}
+}
+
+// addSectionResponseExamples attaches the example bodies of code blocks labelled as responses in an
+// endpoint's section to the responses with the status code of their labels, 200 by default
+func addSectionResponseExamples(endpoint *models.Endpoint, section *goquery.Selection, rules *Rules) {
+	blocks := section.Filter("pre, code").AddSelection(section.Find("pre, code"))
+	blocks.Each(func(_ int, block *goquery.Selection) {
+		// Code inside pre is part of the pre block
+		if goquery.NodeName(block) == "code" && block.ParentsFiltered("pre").Length() > 0 {
+			return
+		}
+
+		text := label(block)
+		if !rules.isResponseLabel(text) {
+			return
+		}
+		body := strings.TrimSpace(block.Text())
+		contentType := exampleContentType(body)
+		if body == "" || contentType == "" {
+			return
+		}
+
+		statusCode := 200
+		if match := rules.statusCode.FindStringSubmatch(text); match != nil {
+			statusCode, _ = strconv.Atoi(match[1])
+		}
+
+		i := findResponse(endpoint.Responses, statusCode)
+		if i < 0 {
+			endpoint.Responses = append(endpoint.Responses, models.Response{StatusCode: statusCode, Description: getStatusCodeDescription(statusCode)})
+			i = len(endpoint.Responses) - 1
+		}
+		if endpoint.Responses[i].Example == "" {
+			endpoint.Responses[i].Example = body
+			endpoint.Responses[i].ContentType = contentType
+		}
+	})
+}
+
+// exampleContentType infers the content type of an example body: JSON, XML, or "" for other text
+func exampleContentType(body string) string {
+	switch {
+	case isJSONBody(body):
+		return "application/json"
+	case strings.HasPrefix(body, "<?xml") || strings.HasPrefix(body, "<") && strings.HasSuffix(body, ">"):
+		return "application/xml"
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"testing"
+)
+
+// TestHTMLParserResponseExamples tests attaching the bodies labelled as responses to their responses
+func TestHTMLParserResponseExamples(t *testing.T) {
+	page := `<html><head><title>Orders API</title></head><body>
+<h2>POST /v1/orders</h2>
+<p>Create an order</p>
+<pre>{"amount": 100}</pre>
+<p>Response (201 Created)</p>
+<pre>{"id": "ord_1", "status": "pending"}</pre>
+<p>Error response 422</p>
+<div><pre>&lt;error&gt;&lt;code&gt;invalid&lt;/code&gt;&lt;/error&gt;</pre></div>
+<p>Response</p>
+<pre>Not a body</pre>
+</body></html>`
+
+	apiDoc, err := (&HTMLParser{}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	endpoint := findEndpoint(apiDoc.Endpoints, "POST", "/v1/orders")
+	if endpoint == nil {
+		t.Fatalf("POST /v1/orders endpoint not found in %v", apiDoc.Endpoints)
+	}
+
+	created := findResponse(endpoint.Responses, 201)
+	if created < 0 || endpoint.Responses[created].Example != `{"id": "ord_1", "status": "pending"}` || endpoint.Responses[created].ContentType != "application/json" {
+		t.Errorf("Expected the 201 response to have the JSON example, got %+v", endpoint.Responses)
+	}
+	invalid := findResponse(endpoint.Responses, 422)
+	if invalid < 0 || endpoint.Responses[invalid].ContentType != "application/xml" {
+		t.Errorf("Expected the 422 response to have the XML example, got %+v", endpoint.Responses)
+	}
+	for _, response := range endpoint.Responses {
+		if response.StatusCode == 200 && response.Example != "" {
+			t.Errorf("Expected text that isn't a body not to be an example, got %+v", response)
+		}
+	}
+	if len(endpoint.Examples) != 1 || endpoint.Examples[0].Request.Body != `{"amount":100}` {
+		t.Errorf("Expected only the request body as a request example, got %+v", endpoint.Examples)
+	}
+}
+
+// TestHTMLParserStatusCodes tests reading status codes and their reasons from the response parts of a section only
+func TestHTMLParserStatusCodes(t *testing.T) {
+	page := `<html><head><title>Orders API</title></head><body>
+<h2>GET /orders/{id}</h2>
+<p>Get order 12005, or any of the 404 orders.</p>
+<pre>{"id": 12005, "total": 500}</pre>
+<h3>Responses</h3>
+<ul>
+<li>200 OK - The order</li>
+<li>429: Too many requests</li>
+</ul>
+<h4>451</h4>
+<h3>Errors</h3>
+<table><tr><th>Status</th><th>Description</th></tr>
+<tr><td>404 Not Found</td><td>No order has the ID</td></tr>
+<tr><td>503</td><td></td></tr></table>
+<h3>Example</h3>
+<pre>HTTP/1.1 202 Accepted
+Content-Type: application/json</pre>
+<p>Returns 201 items at most.</p>
+</body></html>`
+
+	apiDoc, err := (&HTMLParser{}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	endpoint := findEndpoint(apiDoc.Endpoints, "GET", "/orders/{id}")
+	if endpoint == nil {
+		t.Fatalf("GET /orders/{id} endpoint not found in %v", apiDoc.Endpoints)
+	}
+
+	expected := map[int]string{
+		200: "The order",
+		429: "Too many requests",
+		451: "Unavailable For Legal Reasons",
+		404: "No order has the ID",
+		503: "Service Unavailable",
+		202: "Accepted",
+	}
+	if len(endpoint.Responses) != len(expected) {
+		t.Fatalf("Expected %d responses, got %v", len(expected), endpoint.Responses)
+	}
+	for _, response := range endpoint.Responses {
+		if description, ok := expected[response.StatusCode]; !ok || response.Description != description {
+			t.Errorf("Expected response %d to be %q, got %q", response.StatusCode, description, response.Description)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/andybalholm/cascadia"
+	"gopkg.in/yaml.v3"
+)
+
+// Rules are the heuristics the HTML parser finds endpoints with. Operators tune them with a rules
+// file instead of recompiling; fields left empty keep their defaults.
+type Rules struct {
+	// Methods are the method tokens that mark endpoint headings and request lines
+	Methods []string `json:"methods,omitempty" yaml:"methods,omitempty"`
+	// NonEndpointHeadings are phrases of headings that aren't endpoints unless they name a path
+	NonEndpointHeadings []string `json:"non_endpoint_headings,omitempty" yaml:"non_endpoint_headings,omitempty"`
+	// HeadingSelector selects the headings that may name endpoints
+	HeadingSelector string `json:"heading_selector,omitempty" yaml:"heading_selector,omitempty"`
+	// CodeSelector selects the code blocks searched for request lines and curl commands
+	CodeSelector string `json:"code_selector,omitempty" yaml:"code_selector,omitempty"`
+	// ResponseLabels are phrases of the headings and labels of the parts of a section that list responses
+	ResponseLabels []string `json:"response_labels,omitempty" yaml:"response_labels,omitempty"`
+	// StatusPattern matches a status code, captured by its first group
+	StatusPattern string `json:"status_pattern,omitempty" yaml:"status_pattern,omitempty"`
+
+	statusCode *regexp.Regexp
+}
+
+// DefaultRules returns the built-in heuristics
+func DefaultRules() *Rules {
+	return &Rules{
+		Methods: []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS", "HEAD"},
+		NonEndpointHeadings: []string{
+			"overview", "introduction", "getting started", "quickstart", "authentication", "authorization",
+			"errors", "changelog", "pagination", "rate limit", "versioning", "parameters", "headers",
+			"response", "request body", "example", "sdk", "libraries", "support", "faq", "glossary",
+		},
+		HeadingSelector: headingSelector,
+		CodeSelector:    "pre, code, .code",
+		ResponseLabels:  []string{"response", "status code", "error"},
+		StatusPattern:   `\b([1-5][0-9][0-9])\b`,
+		statusCode:      statusCodePattern,
+	}
+}
+
+// ParseRules parses a YAML or JSON rules file, on top of the default rules
+func ParseRules(data []byte) (*Rules, error) {
+	var rules Rules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+	merged := DefaultRules().Override(&rules)
+	if err := merged.Validate(); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// Override returns a copy of the rules with the fields set in override replacing theirs
+func (r *Rules) Override(override *Rules) *Rules {
+	merged := *r
+	if override == nil {
+		return &merged
+	}
+	if len(override.Methods) > 0 {
+		merged.Methods = override.Methods
+	}
+	if len(override.NonEndpointHeadings) > 0 {
+		merged.NonEndpointHeadings = override.NonEndpointHeadings
+	}
+	if override.HeadingSelector != "" {
+		merged.HeadingSelector = override.HeadingSelector
+	}
+	if override.CodeSelector != "" {
+		merged.CodeSelector = override.CodeSelector
+	}
+	if len(override.ResponseLabels) > 0 {
+		merged.ResponseLabels = override.ResponseLabels
+	}
+	if override.StatusPattern != "" {
+		merged.StatusPattern = override.StatusPattern
+		merged.statusCode = nil
+	}
+	return &merged
+}
+
+// Validate checks that the selectors and status pattern of the rules compile
+func (r *Rules) Validate() error {
+	for name, selector := range map[string]string{"heading_selector": r.HeadingSelector, "code_selector": r.CodeSelector} {
+		if selector == "" {
+			continue
+		}
+		if _, err := cascadia.ParseGroup(selector); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", name, selector, err)
+		}
+	}
+	if r.StatusPattern != "" && r.statusCode == nil {
+		pattern, err := regexp.Compile(r.StatusPattern)
+		if err != nil {
+			return fmt.Errorf("invalid status_pattern %q: %w", r.StatusPattern, err)
+		}
+		if pattern.NumSubexp() < 1 {
+			return fmt.Errorf("invalid status_pattern %q: the status code must be captured by a group", r.StatusPattern)
+		}
+		r.statusCode = pattern
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseRules tests loading rules over the defaults and rejecting ones that don't compile
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules([]byte(`
+methods: [GET, POST, SUBSCRIBE]
+heading_selector: h2, h3
+status_pattern: 'Status ([0-9]{3})'
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse rules: %v", err)
+	}
+	if len(rules.Methods) != 3 || rules.HeadingSelector != "h2, h3" || rules.CodeSelector != DefaultRules().CodeSelector {
+		t.Errorf("Expected the file's methods and heading selector over the defaults, got %+v", rules)
+	}
+
+	invalid := map[string]string{
+		"heading_selector: 'h2['":         "heading_selector",
+		"status_pattern: '[0-9'":          "status_pattern",
+		"status_pattern: '[1-5][0-9]{2}'": "captured",
+		"methods: {get: true}":            "failed to parse rules",
+	}
+	for data, expected := range invalid {
+		if _, err := ParseRules([]byte(data)); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("ParseRules(%q): expected an error about %s, got %v", data, expected, err)
+		}
+	}
+}
+
+// TestHTMLParserRules tests that tuned rules change how endpoints and responses are found
+func TestHTMLParserRules(t *testing.T) {
+	page := `<html><head><title>Events API</title></head><body>
+<h2>SUBSCRIBE orders</h2>
+<div class="endpoint">POST /orders</div>
+<h3>Outcomes</h3>
+<ul><li>Status 202: queued</li></ul>
+</body></html>`
+
+	rules := &Rules{
+		Methods:         []string{"SUBSCRIBE", "POST"},
+		CodeSelector:    ".endpoint",
+		ResponseLabels:  []string{"outcome"},
+		StatusPattern:   `Status ([0-9]{3})`,
+		HeadingSelector: "h2",
+	}
+	apiDoc, err := (&HTMLParser{Rules: rules, KeepUnknownPaths: true}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	subscribe := findEndpoint(apiDoc.Endpoints, "SUBSCRIBE", "Unknown")
+	if subscribe == nil {
+		t.Fatalf("SUBSCRIBE endpoint not found in %v", apiDoc.Endpoints)
+	}
+	if len(subscribe.Responses) != 1 || subscribe.Responses[0].StatusCode != 202 || subscribe.Responses[0].Description != "queued" {
+		t.Errorf("Expected the 202 response of the Outcomes list, got %v", subscribe.Responses)
+	}
+	if findEndpoint(apiDoc.Endpoints, "POST", "/orders") == nil {
+		t.Errorf("Expected POST /orders from the .endpoint block, got %v", apiDoc.Endpoints)
+	}
+
+	if _, err := (&HTMLParser{Rules: &Rules{StatusPattern: "("}}).Parse([]byte(page)); err == nil {
+		t.Error("Expected an error for a status pattern that doesn't compile")
+	}
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"net/http"
+	"strings"
+
+	"universal_api/internal/models"
+)
+
+// idempotencyHeaders are the names of the headers making retries of a request idempotent, compared like
+// pagination parameters
+var idempotencyHeaders = []string{"idempotencykey", "xidempotencykey", "idempotencytoken", "xidempotencytoken"}
+
+// ClassifySafety sets whether calling an endpoint is safe, idempotent, or unsafe. GET, HEAD, OPTIONS, and
+// TRACE are safe, and PUT and DELETE idempotent. Other methods are idempotent when the endpoint takes an
+// idempotency key header, documented or sent in recorded requests, and unsafe otherwise. Endpoints the
+// API calls, like webhooks, aren't classified.
+func ClassifySafety(endpoint *models.Endpoint) {
+	if endpoint.Incoming() {
+		endpoint.Safety, endpoint.IdempotencyKey = "", ""
+		return
+	}
+	endpoint.IdempotencyKey = idempotencyHeader(endpoint)
+
+	switch strings.ToUpper(endpoint.Method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		endpoint.Safety = models.SafetySafe
+	case http.MethodPut, http.MethodDelete:
+		endpoint.Safety = models.SafetyIdempotent
+	default:
+		if endpoint.IdempotencyKey != "" {
+			endpoint.Safety = models.SafetyIdempotent
+		} else {
+			endpoint.Safety = models.SafetyUnsafe
+		}
+	}
+}
+
+// idempotencyHeader returns the name of the idempotency key header of an endpoint's parameters or
+// recorded requests, or ""
+func idempotencyHeader(endpoint *models.Endpoint) string {
+	for _, header := range idempotencyHeaders {
+		for _, param := range endpoint.Parameters {
+			if param.In == "header" && paginationName(param.Name) == header {
+				return param.Name
+			}
+		}
+		for _, example := range endpoint.Examples {
+			for name := range example.Request.Headers {
+				if paginationName(name) == header {
+					return name
+				}
+			}
+		}
+	}
+	return ""
+}
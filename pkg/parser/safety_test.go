@@ -0,0 +1,34 @@
+package parser
+
+import (
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestClassifySafety tests classifying endpoints by their method and idempotency key header
+func TestClassifySafety(t *testing.T) {
+	idempotencyKey := []models.Parameter{{Name: "Idempotency-Key", In: "header"}}
+	tests := []struct {
+		endpoint models.Endpoint
+		safety   string
+		key      string
+	}{
+		{models.Endpoint{Method: "GET"}, models.SafetySafe, ""},
+		{models.Endpoint{Method: "head"}, models.SafetySafe, ""},
+		{models.Endpoint{Method: "PUT"}, models.SafetyIdempotent, ""},
+		{models.Endpoint{Method: "DELETE"}, models.SafetyIdempotent, ""},
+		{models.Endpoint{Method: "POST"}, models.SafetyUnsafe, ""},
+		{models.Endpoint{Method: "POST", Parameters: idempotencyKey}, models.SafetyIdempotent, "Idempotency-Key"},
+		{models.Endpoint{Method: "PATCH", Examples: []models.Example{{Request: models.ExampleRequest{
+			Headers: map[string]string{"X-Idempotency-Key": "3f1c"},
+		}}}}, models.SafetyIdempotent, "X-Idempotency-Key"},
+	}
+	for _, test := range tests {
+		endpoint := test.endpoint
+		ClassifySafety(&endpoint)
+		if endpoint.Safety != test.safety || endpoint.IdempotencyKey != test.key {
+			t.Errorf("%s: expected %s with key %q, got %s with key %q", endpoint.Method, test.safety, test.key, endpoint.Safety, endpoint.IdempotencyKey)
+		}
+	}
+}
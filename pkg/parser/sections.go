@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"universal_api/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// headingSelector matches the headings that divide a page into sections
+const headingSelector = "h1, h2, h3, h4, h5, h6"
+
+// headingLevel returns the level of a heading element, 1 for h1 through 6 for h6, or 0 for other nodes
+func headingLevel(node *html.Node) int {
+	if node.Type != html.ElementNode || len(node.Data) != 2 || node.Data[0] != 'h' || node.Data[1] < '1' || node.Data[1] > '6' {
+		return 0
+	}
+	return int(node.Data[1] - '0')
+}
+
+// section returns the content of a heading's section: the elements after it in document order up
+// to the next heading of the same or a higher level, or the next heading that starts a section of
+// its own, whichever comes first. Lower-level headings, like a Parameters heading under an
+// endpoint, are part of the section; content nested in wrappers is found too, so sections don't
+// depend on the headings being siblings.
+func section(heading *goquery.Selection, ownSection func(*html.Node) bool) *goquery.Selection {
+	if heading.Length() == 0 {
+		return heading
+	}
+	start := heading.Get(0)
+	level := headingLevel(start)
+	boundary := func(node *html.Node) bool {
+		if node == start {
+			return false
+		}
+		nodeLevel := headingLevel(node)
+		return nodeLevel > 0 && (nodeLevel <= level || ownSection(node))
+	}
+
+	var nodes []*html.Node
+	node := nextInOrder(start)
+	for node != nil {
+		if boundary(node) {
+			break
+		}
+		if containsNode(node, boundary) {
+			// Take the content before the boundary from inside the wrapper
+			node = node.FirstChild
+			continue
+		}
+		if node.Type == html.ElementNode {
+			nodes = append(nodes, node)
+		}
+		node = nextInOrder(node)
+	}
+	return heading.Slice(0, 0).AddNodes(nodes...)
+}
+
+// nextInOrder returns the node after a node and its descendants in document order, or nil at the end of the body
+func nextInOrder(node *html.Node) *html.Node {
+	for node != nil {
+		if node.NextSibling != nil {
+			return node.NextSibling
+		}
+		node = node.Parent
+		if node != nil && (node.Data == "body" || node.Type == html.DocumentNode) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// containsNode checks if any descendant of a node matches
+func containsNode(node *html.Node, match func(*html.Node) bool) bool {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if match(child) || containsNode(child, match) {
+			return true
+		}
+	}
+	return false
+}
+
+// sectionText returns the text of the first paragraph-like element of a section, skipping headings
+func sectionText(content *goquery.Selection) string {
+	text := ""
+	content.EachWithBreak(func(_ int, element *goquery.Selection) bool {
+		if headingLevel(element.Get(0)) > 0 || goquery.NodeName(element) == "table" || goquery.NodeName(element) == "pre" {
+			return true
+		}
+		text = strings.TrimSpace(element.Text())
+		return text == ""
+	})
+	return text
+}
+
+// incomingStatement matches statements that the API calls the client, like "we'll send a POST request to your URL"
+var incomingStatement = regexp.MustCompile(`(?i)\b(?:will|'ll)\s+(?:call|send|post|notify|deliver)\b[^.]{0,80}\byour\b|\b(?:sent|posted|delivered)\s+to\s+your\b|\byour\s+(?:server|endpoint|url|app)\s+(?:will\s+)?receives?\b`)
+
+// managementStatement matches descriptions of endpoints managing webhooks, like "Creates a webhook subscription"
+var managementStatement = regexp.MustCompile(`(?i)\b(?:creates?|registers?|lists?|deletes?|updates?|retrieves?|subscribes?)\b[^.]{0,30}\b(?:webhooks?|callbacks?|subscriptions?)\b`)
+
+// sectionKind returns the kind of the endpoint of a section from its heading and description: a webhook
+// or callback when the description says the API calls the client, and a request otherwise
+func sectionKind(heading, description string) string {
+	text := heading + "\n" + description
+	if !incomingStatement.MatchString(description) || managementStatement.MatchString(text) {
+		return models.EndpointRequest
+	}
+	if strings.Contains(strings.ToLower(text), "webhook") {
+		return models.EndpointWebhook
+	}
+	return models.EndpointCallback
+}
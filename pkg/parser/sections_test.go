@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestHTMLParserSections tests scoping endpoint details to the sections of the heading hierarchy
+func TestHTMLParserSections(t *testing.T) {
+	page := `<html><head><title>Orders</title></head><body>
+<h1>Orders</h1>
+<div class="endpoint">
+  <h3>GET /orders/{id}</h3>
+  <p>Get an order</p>
+  <h4>Parameters</h4>
+  <table><tr><th>Name</th><th>Description</th></tr><tr><td>expand</td><td>Related objects</td></tr></table>
+  <h4>Response</h4>
+  <pre>{"id": "ord_1"}</pre>
+</div>
+<div class="endpoint">
+  <h2>DELETE /orders/{id}</h2>
+  <div><p>Delete an order</p></div>
+  <h5>Headers</h5>
+  <table><tr><th>Name</th><th>Description</th></tr><tr><td>X-Api-Key</td><td>Your key</td></tr></table>
+</div>
+<h2>Changelog</h2>
+<table><tr><th>Name</th><th>Description</th></tr><tr><td>v2</td><td>Not a parameter</td></tr></table>
+</body></html>`
+
+	apiDoc, err := (&HTMLParser{}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	get := findEndpoint(apiDoc.Endpoints, "GET", "/orders/{id}")
+	if get == nil {
+		t.Fatalf("GET /orders/{id} endpoint not found in %v", apiDoc.Endpoints)
+	}
+	if get.Description != "Get an order" {
+		t.Errorf("Expected the section's first paragraph as description, got %q", get.Description)
+	}
+	if len(get.Parameters) != 2 || get.Parameters[0].Name != "expand" {
+		t.Errorf("Expected the expand parameter under the Parameters heading and the id path parameter, got %+v", get.Parameters)
+	}
+	if i := findResponse(get.Responses, 200); i < 0 || get.Responses[i].Example != `{"id": "ord_1"}` {
+		t.Errorf("Expected the example under the Response heading, got %+v", get.Responses)
+	}
+
+	remove := findEndpoint(apiDoc.Endpoints, "DELETE", "/orders/{id}")
+	if remove == nil {
+		t.Fatalf("DELETE /orders/{id} endpoint not found in %v", apiDoc.Endpoints)
+	}
+	if remove.Description != "Delete an order" {
+		t.Errorf("Expected the nested paragraph as description, got %q", remove.Description)
+	}
+	for _, param := range remove.Parameters {
+		if param.Name == "v2" || param.Name == "expand" {
+			t.Errorf("Expected no parameters from other sections, got %+v", remove.Parameters)
+		}
+	}
+	if len(remove.Parameters) != 2 || remove.Parameters[0].In != "header" || remove.Parameters[0].Auth != "apiKey" {
+		t.Errorf("Expected the X-Api-Key header and the id path parameter, got %+v", remove.Parameters)
+	}
+}
+
+// TestSectionKind tests telling webhook and callback sections from sections of requests to the API
+func TestSectionKind(t *testing.T) {
+	tests := []struct {
+		heading     string
+		description string
+		expected    string
+	}{
+		{"POST /your-webhook-url", "When an order ships, we'll send a POST request to your webhook URL.", models.EndpointWebhook},
+		{"Payment result", "The result is posted to your callback_url once the payment settles.", models.EndpointCallback},
+		{"POST /webhooks", "Creates a webhook subscription. We will send events to your URL.", models.EndpointRequest},
+		{"GET /orders", "Lists your orders.", models.EndpointRequest},
+	}
+	for _, test := range tests {
+		if kind := sectionKind(test.heading, test.description); kind != test.expected {
+			t.Errorf("sectionKind(%q) = %s, expected %s", test.heading, kind, test.expected)
+		}
+	}
+}
+
+// TestJSONParserWebhooks tests parsing the webhooks of an OpenAPI 3.1 spec as webhook endpoints
+func TestJSONParserWebhooks(t *testing.T) {
+	spec := `{
+		"openapi": "3.1.0",
+		"info": {"title": "Shop", "version": "1.0"},
+		"paths": {"/orders": {"get": {"responses": {"200": {"description": "OK"}}}}},
+		"webhooks": {"orderShipped": {"post": {
+			"summary": "An order shipped",
+			"parameters": [{"name": "X-Signature", "in": "header"}],
+			"requestBody": {"content": {"application/json": {"example": {"id": "ord_1"}}}},
+			"responses": {"200": {"description": "Received"}}
+		}}}
+	}`
+	apiDoc, err := (&JSONParser{}).Parse([]byte(spec))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	webhook := findEndpoint(apiDoc.Endpoints, "POST", "orderShipped")
+	if webhook == nil || webhook.Kind != models.EndpointWebhook || len(webhook.RequestBodies) != 1 || webhook.Safety != "" {
+		t.Fatalf("Expected an unclassified webhook with its request body, got %+v", webhook)
+	}
+	if orders := findEndpoint(apiDoc.Endpoints, "GET", "/orders"); orders == nil || orders.Kind != models.EndpointRequest {
+		t.Errorf("Expected GET /orders to be a request, got %+v", orders)
+	}
+}
@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"universal_api/internal/models"
+)
+
+// webSocketURL matches ws:// and wss:// URLs, as found in code samples like new WebSocket("wss://...")
+var webSocketURL = regexp.MustCompile(`\bwss?://[^\s"'<>()\x60]+`)
+
+// eventSourceURL matches the URL of an SSE stream opened in JavaScript, like new EventSource("/v1/events")
+var eventSourceURL = regexp.MustCompile(`\bEventSource\(\s*["'\x60]([^"'\x60]+)`)
+
+// eventStreamType is the content type of server-sent events
+const eventStreamType = "text/event-stream"
+
+// codeStreamingEndpoints returns the WebSocket and SSE endpoints opened in a code sample, by the path of their URLs
+func codeStreamingEndpoints(code string) []models.Endpoint {
+	var endpoints []models.Endpoint
+	for _, url := range webSocketURL.FindAllString(code, -1) {
+		if path, ok := streamPath(url); ok {
+			endpoints = append(endpoints, streamingEndpoint(path, models.ProtocolWebSocket))
+		}
+	}
+	for _, match := range eventSourceURL.FindAllStringSubmatch(code, -1) {
+		if path, ok := streamPath(match[1]); ok {
+			endpoints = append(endpoints, streamingEndpoint(path, models.ProtocolSSE))
+		}
+	}
+	return endpoints
+}
+
+// streamPath returns the path of a streaming URL, or / for URLs of a host alone
+func streamPath(url string) (string, bool) {
+	if i := strings.Index(url, "://"); i > 0 && !strings.Contains(url[i+3:], "/") {
+		return "/", true
+	}
+	if i := strings.IndexAny(url, "?#"); i >= 0 {
+		url = url[:i]
+	}
+	return pathToken(url)
+}
+
+// streamingEndpoint creates the endpoint of a stream opened at a path. WebSockets and SSE streams
+// both start with a GET request, which is upgraded or answered with a stream of events.
+func streamingEndpoint(path, protocol string) models.Endpoint {
+	summary := "WebSocket " + path
+	if protocol == models.ProtocolSSE {
+		summary = "Event stream " + path
+	}
+	return models.Endpoint{
+		Kind:       models.EndpointRequest,
+		Protocol:   protocol,
+		Path:       path,
+		Method:     "GET",
+		Summary:    summary,
+		Parameters: []models.Parameter{},
+		Responses:  []models.Response{},
+	}
+}
+
+// sectionProtocol returns the protocol of a section's endpoint at a path: websocket when the section has a
+// ws:// or wss:// URL to the path, sse when it mentions server-sent events or text/event-stream, or ""
+func sectionProtocol(text, path string) string {
+	for _, url := range webSocketURL.FindAllString(text, -1) {
+		if streamed, ok := streamPath(url); ok && streamed == path {
+			return models.ProtocolWebSocket
+		}
+	}
+	lower := strings.ToLower(text)
+	if strings.Contains(lower, eventStreamType) || strings.Contains(lower, "server-sent events") || eventSourceURL.MatchString(text) {
+		return models.ProtocolSSE
+	}
+	return ""
+}
+
+// responseEventStream returns the message of a spec's response object served as text/event-stream, with the
+// schema and example of its events, and whether it has one
+func responseEventStream(respMap map[string]interface{}) (models.Message, bool) {
+	content, _ := respMap["content"].(map[string]interface{})
+	for mediaType, media := range content {
+		if !strings.HasPrefix(mediaType, eventStreamType) {
+			continue
+		}
+		message := models.Message{Direction: models.MessageReceive}
+		mediaMap, _ := media.(map[string]interface{})
+		if schema, ok := mediaMap["schema"]; ok {
+			if data, err := json.Marshal(schema); err == nil {
+				message.Schema = string(data)
+			}
+		}
+		if example, ok := mediaMap["example"].(string); ok {
+			message.Example = example
+		}
+		return message, true
+	}
+	return models.Message{}, false
+}
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestHTMLParserStreaming tests finding WebSocket and SSE endpoints in code samples and sections
+func TestHTMLParserStreaming(t *testing.T) {
+	page := `<html><head><title>Market Data</title></head><body>
+<h2>GET /v1/quotes</h2>
+<p>Streams quotes as server-sent events.</p>
+<pre>curl -N -H "Accept: text/event-stream" https://api.example.com/v1/quotes</pre>
+<h2>Trades</h2>
+<pre>const socket = new WebSocket("wss://stream.example.com/v1/trades?symbol=BTC");</pre>
+<pre>const events = new EventSource("/v1/notifications");</pre>
+</body></html>`
+
+	apiDoc, err := (&HTMLParser{}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tests := map[string]string{
+		"/v1/quotes":        models.ProtocolSSE,
+		"/v1/trades":        models.ProtocolWebSocket,
+		"/v1/notifications": models.ProtocolSSE,
+	}
+	for path, protocol := range tests {
+		endpoint := findEndpoint(apiDoc.Endpoints, "GET", path)
+		if endpoint == nil || endpoint.Protocol != protocol {
+			t.Errorf("Expected GET %s with protocol %s, got %+v", path, protocol, endpoint)
+		}
+	}
+}
+
+// TestJSONParserEventStream tests reading SSE endpoints and their events from text/event-stream responses
+func TestJSONParserEventStream(t *testing.T) {
+	spec := `{
+		"openapi": "3.0.0",
+		"info": {"title": "Events", "version": "1.0"},
+		"paths": {"/events": {"get": {"responses": {"200": {"description": "Events", "content": {
+			"text/event-stream": {"schema": {"type": "string"}, "example": "event: order\ndata: {\"id\": 1}"}
+		}}}}}}
+	}`
+	apiDoc, err := (&JSONParser{}).Parse([]byte(spec))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	endpoint := apiDoc.Endpoints[0]
+	if endpoint.Protocol != models.ProtocolSSE || len(endpoint.Messages) != 1 || endpoint.Messages[0].Direction != models.MessageReceive {
+		t.Errorf("Expected an SSE endpoint receiving events, got %+v", endpoint)
+	}
+}
+
+// TestAsyncAPIParserWebSocket tests reading the protocol and messages of AsyncAPI channels served over WebSockets
+func TestAsyncAPIParserWebSocket(t *testing.T) {
+	apiDoc, err := (&AsyncAPIParser{}).Parse([]byte(`asyncapi: 2.6.0
+info:
+  title: Chat
+  version: 1.0.0
+servers:
+  production:
+    url: wss://chat.example.com
+    protocol: wss
+channels:
+  rooms/{id}:
+    publish:
+      message:
+        name: say
+        payload:
+          type: object
+          properties:
+            text: {type: string}
+        examples:
+          - payload: {text: hi}
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	endpoint := apiDoc.Endpoints[0]
+	if endpoint.Protocol != models.ProtocolWebSocket || len(endpoint.Messages) != 1 {
+		t.Fatalf("Expected a WebSocket channel with a message, got %+v", endpoint)
+	}
+	if message := endpoint.Messages[0]; message.Direction != models.MessageSend || message.Name != "say" || message.Example != `{"text":"hi"}` {
+		t.Errorf("Expected the client to send the say message, got %+v", message)
+	}
+}
@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultSummaryLength is the length in characters that summaries are truncated to by default
+const DefaultSummaryLength = 200
+
+// ellipsis marks truncated text
+const ellipsis = "..."
+
+// SummaryOptions controls how summaries of long descriptions are generated
+type SummaryOptions struct {
+	MaxLength     int  // in characters; 0 means DefaultSummaryLength
+	FirstSentence bool // summarize with the first sentence of the description
+}
+
+// Summarize returns a short summary of a description: its first sentence when enabled, truncated
+// to the maximum length. Whitespace is collapsed, so the summary fits on one line.
+func Summarize(text string, options SummaryOptions) string {
+	limit := options.MaxLength
+	if limit <= 0 {
+		limit = DefaultSummaryLength
+	}
+
+	summary := strings.Join(strings.Fields(text), " ")
+	if options.FirstSentence {
+		summary = FirstSentence(summary)
+	}
+	return Truncate(summary, limit)
+}
+
+// Truncate shortens text to at most limit characters, ending with "..." when it is cut. Text is cut
+// between runes, never inside one, and at the last word boundary when there is one in the second
+// half of the kept text; text without spaces, like Chinese or Japanese, is cut at the limit.
+func Truncate(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	if limit <= len(ellipsis) {
+		return string([]rune(text)[:limit])
+	}
+
+	runes := []rune(text)
+	kept := runes[:limit-len(ellipsis)]
+	if !unicode.IsSpace(runes[len(kept)]) {
+		// The cut falls inside a word, so move it back to the word's start
+		for i := len(kept) - 1; i >= len(kept)/2; i-- {
+			if unicode.IsSpace(kept[i]) {
+				kept = kept[:i]
+				break
+			}
+		}
+	}
+	return strings.TrimRightFunc(string(kept), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r) && r != ')' && r != ']'
+	}) + ellipsis
+}
+
+// FirstSentence returns the first sentence of text, or all of it if it is a single sentence. A
+// sentence ends at a full stop, question, or exclamation mark followed by whitespace and a capital
+// letter or digit, so abbreviations like "e.g. a token" and versions like "v1.2" don't end it;
+// ideographic full stops always end it.
+func FirstSentence(text string) string {
+	runes := []rune(text)
+	for i, r := range runes {
+		switch r {
+		case '。', '！', '？':
+			return strings.TrimSpace(string(runes[:i+1]))
+		case '.', '!', '?':
+			next := i + 1
+			if next == len(runes) || !unicode.IsSpace(runes[next]) {
+				continue
+			}
+			for next < len(runes) && unicode.IsSpace(runes[next]) {
+				next++
+			}
+			if next < len(runes) && (unicode.IsUpper(runes[next]) || unicode.IsDigit(runes[next])) {
+				return strings.TrimSpace(string(runes[:i+1]))
+			}
+		}
+	}
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestTruncate tests rune-safe truncation at word boundaries
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		text     string
+		limit    int
+		expected string
+	}{
+		{"Short text", 20, "Short text"},
+		{"Manage the customers of your account", 20, "Manage the..."},
+		{"Supercalifragilisticexpialidocious", 10, "Superca..."},
+		{"Create, update, and delete", 18, "Create, update..."},
+		{"日本語のドキュメントです", 8, "日本語のド..."},
+	}
+
+	for _, test := range tests {
+		got := Truncate(test.text, test.limit)
+		if got != test.expected {
+			t.Errorf("Truncate(%q, %d): expected %q, got %q", test.text, test.limit, test.expected, got)
+		}
+		if !utf8.ValidString(got) || utf8.RuneCountInString(got) > test.limit {
+			t.Errorf("Truncate(%q, %d): %q is invalid or too long", test.text, test.limit, got)
+		}
+	}
+}
+
+// TestFirstSentence tests first sentence extraction
+func TestFirstSentence(t *testing.T) {
+	tests := map[string]string{
+		"Lists charges. Results are paginated.":             "Lists charges.",
+		"Pass a key, e.g. the secret key. It never expires": "Pass a key, e.g. the secret key.",
+		"Supports v1.2 and later":                           "Supports v1.2 and later",
+		"課金を一覧表示します。結果はページ分割されます。":                          "課金を一覧表示します。",
+	}
+
+	for text, expected := range tests {
+		if got := FirstSentence(text); got != expected {
+			t.Errorf("FirstSentence(%q): expected %q, got %q", text, expected, got)
+		}
+	}
+}
+
+// TestHTMLParserSummary tests that long HTML descriptions are kept in full with a summary
+func TestHTMLParserSummary(t *testing.T) {
+	long := "The Payments API lets you charge cards. " + strings.Repeat("It supports many currencies and payment methods. ", 10)
+	page := "<html><head><title>Payments</title></head><body><p>" + long + "</p></body></html>"
+
+	apiDoc, err := (&HTMLParser{}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	if apiDoc.Description != strings.TrimSpace(long) {
+		t.Errorf("Expected the full description, got %q", apiDoc.Description)
+	}
+	if n := utf8.RuneCountInString(apiDoc.Summary); n == 0 || n > DefaultSummaryLength || !strings.HasSuffix(apiDoc.Summary, "...") {
+		t.Errorf("Expected a truncated summary, got %q", apiDoc.Summary)
+	}
+
+	apiDoc, err = (&HTMLParser{Summary: SummaryOptions{FirstSentence: true}}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	if apiDoc.Summary != "The Payments API lets you charge cards." {
+		t.Errorf("Expected the first sentence as summary, got %q", apiDoc.Summary)
+	}
+
+	apiDoc, err = (&HTMLParser{}).Parse([]byte(htmlTestData))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	if apiDoc.Summary != "" {
+		t.Errorf("Expected no summary for a short description, got %q", apiDoc.Summary)
+	}
+}
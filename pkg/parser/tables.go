@@ -0,0 +1,170 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"universal_api/internal/models"
+)
+
+// Columns of a parameter table
+const (
+	columnName        = "name"
+	columnType        = "type"
+	columnRequired    = "required"
+	columnIn          = "in"
+	columnDescription = "description"
+)
+
+// columnTitles maps the titles of parameter table columns, lowercased, to the columns they hold
+var columnTitles = map[string]string{
+	"name": columnName, "parameter": columnName, "param": columnName, "field": columnName, "header": columnName,
+	"key": columnName, "attribute": columnName, "property": columnName, "argument": columnName,
+	"type": columnType, "data type": columnType, "datatype": columnType, "format": columnType,
+	"required": columnRequired, "required?": columnRequired, "optional": columnRequired, "mandatory": columnRequired,
+	"in": columnIn, "location": columnIn, "where": columnIn, "parameter type": columnIn, "param type": columnIn,
+	"description": columnDescription, "details": columnDescription, "notes": columnDescription, "meaning": columnDescription,
+}
+
+// parameterLocations are the values of a table's In column that name a parameter location
+var parameterLocations = map[string]string{
+	"query": "query", "querystring": "query", "query string": "query", "path": "path", "url": "path",
+	"header": "header", "headers": "header", "body": "body", "form": "body", "formdata": "body", "cookie": "cookie",
+}
+
+// tableParameters extracts the parameters of a table, mapping its columns by the titles of its header
+// row. Tables without a recognizable header row have the name in the first column and the
+// description in the second.
+func tableParameters(table *goquery.Selection, path string) []models.Parameter {
+	rows := table.Find("tr")
+	header := tableHeader(table)
+
+	// Tables of status codes list responses, not parameters
+	if isStatusTable(table) {
+		return nil
+	}
+
+	columns := map[string]int{columnName: 0, columnDescription: 1}
+	optional := false
+	if header.Length() > 0 {
+		if mapped, inverted := tableColumns(header); mapped[columnName] >= 0 {
+			columns, optional = mapped, inverted
+		}
+		rows = rows.NotSelection(header)
+	}
+
+	// Tables labelled as headers list header parameters
+	headerTable := isHeaderTable(table)
+
+	var params []models.Parameter
+	rows.Each(func(_ int, row *goquery.Selection) {
+		cells := row.Find("td, th")
+		cell := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i < 0 || i >= cells.Length() {
+				return ""
+			}
+			return strings.TrimSpace(cells.Eq(i).Text())
+		}
+
+		name, required := nameAndRequired(cell(columnName))
+		if name == "" || cells.Length() < 2 {
+			return
+		}
+
+		param := models.Parameter{
+			Name:        name,
+			In:          "query",
+			Required:    required,
+			Type:        "string",
+			Description: cell(columnDescription),
+		}
+		if fields := strings.Fields(cell(columnType)); len(fields) > 0 {
+			param.Type = strings.Trim(strings.ToLower(fields[0]), ",;")
+		}
+		if value := cell(columnRequired); value != "" {
+			param.Required = isRequired(value) != optional
+		} else if strings.HasPrefix(strings.ToLower(param.Description), "required") {
+			param.Required = true
+		}
+
+		switch {
+		case headerTable:
+			param.In = "header"
+			if param.Auth = headerAuth(name, ""); param.Auth != "" {
+				param.Required = true
+			}
+		case strings.Contains(path, "{"+name+"}") || strings.Contains(path, ":"+name):
+			param.In = "path"
+			param.Required = true
+		default:
+			if location, ok := parameterLocations[strings.ToLower(cell(columnIn))]; ok {
+				param.In = location
+			}
+		}
+
+		params = append(params, param)
+	})
+	return params
+}
+
+// tableHeader returns the header row of a table: its row in <thead>, or a first row of only <th> cells
+func tableHeader(table *goquery.Selection) *goquery.Selection {
+	header := table.Find("thead tr").First()
+	if first := table.Find("tr").First(); header.Length() == 0 && first.Find("th").Length() > 0 && first.Find("td").Length() == 0 {
+		header = first
+	}
+	return header
+}
+
+// isStatusTable checks if a table lists status codes, by the titles of its header row
+func isStatusTable(table *goquery.Selection) bool {
+	return strings.Contains(strings.ToLower(tableHeader(table).Text()), "status")
+}
+
+// tableColumns maps the columns of a table's header row, with -1 for columns it doesn't have. It
+// also reports if the required column is titled Optional, so its yes means not required.
+func tableColumns(header *goquery.Selection) (map[string]int, bool) {
+	columns := map[string]int{columnName: -1, columnType: -1, columnRequired: -1, columnIn: -1, columnDescription: -1}
+	optional := false
+	header.Find("th, td").Each(func(i int, cell *goquery.Selection) {
+		title := strings.ToLower(strings.Join(strings.Fields(cell.Text()), " "))
+		if column, ok := columnTitles[title]; ok && columns[column] < 0 {
+			columns[column] = i
+			if column == columnRequired {
+				optional = title == "optional"
+			}
+		}
+	})
+	return columns, optional
+}
+
+// nameAndRequired splits a name cell like "amount required" or "limit (optional)" into the name and whether it's required
+func nameAndRequired(text string) (string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", false
+	}
+	required := false
+	for _, field := range fields[1:] {
+		switch strings.Trim(strings.ToLower(field), "()[]*,") {
+		case "required", "mandatory":
+			required = true
+		}
+	}
+	name := fields[0]
+	if strings.HasSuffix(name, "*") {
+		name, required = strings.TrimSuffix(name, "*"), true
+	}
+	return name, required
+}
+
+// isRequired checks if the value of a Required column says yes
+func isRequired(value string) bool {
+	switch strings.ToLower(strings.Trim(value, " .")) {
+	case "yes", "y", "true", "required", "mandatory", "✓", "✔", "x":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestHTMLParserParameterTables tests mapping parameter table columns by their titles
+func TestHTMLParserParameterTables(t *testing.T) {
+	page := `<html><head><title>Orders API</title></head><body>
+<h2>GET /v1/customers/{customer}/orders</h2>
+<p>List the orders of a customer</p>
+<table>
+<thead><tr><th>Description</th><th>Parameter</th><th>In</th><th>Data Type</th><th>Required</th></tr></thead>
+<tbody>
+<tr><td>The customer</td><td>customer</td><td>path</td><td>string</td><td>Yes</td></tr>
+<tr><td>Page size</td><td>limit</td><td>query</td><td>integer (int32)</td><td>No</td></tr>
+<tr><td>Request ID</td><td>X-Request-Id</td><td>header</td><td>string</td><td>No</td></tr>
+</tbody>
+</table>
+<table>
+<tr><th>Field</th><th>Type</th><th>Optional</th></tr>
+<tr><td>expand</td><td>boolean</td><td>yes</td></tr>
+<tr><td>status</td><td>string</td><td>no</td></tr>
+</table>
+<table>
+<tr><td>currency required</td><td>Three-letter ISO code</td></tr>
+</table>
+<table>
+<tr><th>Status</th><th>Description</th></tr>
+<tr><td>404</td><td>Not found</td></tr>
+</table>
+</body></html>`
+
+	apiDoc, err := (&HTMLParser{}).Parse([]byte(page))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	endpoint := findEndpoint(apiDoc.Endpoints, "GET", "/v1/customers/{customer}/orders")
+	if endpoint == nil {
+		t.Fatalf("GET /v1/customers/{customer}/orders endpoint not found in %v", apiDoc.Endpoints)
+	}
+
+	expected := []models.Parameter{
+		{Name: "customer", In: "path", Required: true, Type: "string", Description: "The customer"},
+		{Name: "limit", In: "query", Type: "integer", Description: "Page size"},
+		{Name: "X-Request-Id", In: "header", Type: "string", Description: "Request ID"},
+		{Name: "expand", In: "query", Type: "boolean"},
+		{Name: "status", In: "query", Required: true, Type: "string"},
+		{Name: "currency", In: "query", Required: true, Type: "string", Description: "Three-letter ISO code"},
+	}
+	if len(endpoint.Parameters) != len(expected) {
+		t.Fatalf("Expected %d parameters, got %+v", len(expected), endpoint.Parameters)
+	}
+	for i, param := range expected {
+		if endpoint.Parameters[i] != param {
+			t.Errorf("Expected parameter %+v, got %+v", param, endpoint.Parameters[i])
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"    // fetches go through
+	BreakerOpen     = "open"      // fetches fail fast until the cooldown ends
+	BreakerHalfOpen = "half_open" // a single probe fetch decides whether to close again
+)
+
+// breakerThreshold is the number of consecutive failures opening a host's breaker
+const breakerThreshold = 5
+
+// breakerCooldown is how long a breaker first stays open; it doubles with every failed probe
+const breakerCooldown = 30 * time.Second
+
+// maxBreakerCooldown caps the cooldown of a host that keeps failing
+const maxBreakerCooldown = 10 * time.Minute
+
+// maxRetryAfter caps how long a host's Retry-After can keep its breaker open
+const maxRetryAfter = time.Hour
+
+// breakers tracks the health of every host the scraper fetches from
+var breakers = &Breakers{hosts: make(map[string]*breaker)}
+
+// BreakerState describes the circuit breaker of a host
+type BreakerState struct {
+	Host                string    `json:"host"`
+	State               string    `json:"state"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	OpenUntil           time.Time `json:"open_until,omitempty"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastFailureAt       time.Time `json:"last_failure_at,omitempty"`
+}
+
+// Breakers stops fetching from hosts that keep failing or ask to slow down, probing them again after a cooldown
+type Breakers struct {
+	mu    sync.Mutex
+	hosts map[string]*breaker
+}
+
+// breaker is the circuit breaker of a single host
+type breaker struct {
+	state         string
+	failures      int
+	cooldown      time.Duration
+	openUntil     time.Time
+	probing       bool
+	lastError     string
+	lastFailureAt time.Time
+}
+
+// allow checks if a fetch from host may go through, turning an open breaker half-open once its cooldown ends
+func (b *Breakers) allow(host string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	br, ok := b.hosts[host]
+	if !ok || br.state == BreakerClosed {
+		return nil
+	}
+
+	if br.state == BreakerOpen && time.Now().After(br.openUntil) {
+		br.state = BreakerHalfOpen
+	}
+	if br.state == BreakerHalfOpen && !br.probing {
+		br.probing = true
+		return nil
+	}
+
+	return fmt.Errorf("scraping %s is paused until %s after repeated failures: %s",
+		host, br.openUntil.Format(time.RFC3339), br.lastError)
+}
+
+// record updates a host's breaker with the outcome of a fetch; resp is nil when the request failed
+func (b *Breakers) record(host string, resp *http.Response, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	br, ok := b.hosts[host]
+	if !ok {
+		br = &breaker{state: BreakerClosed, cooldown: breakerCooldown}
+		b.hosts[host] = br
+	}
+	probe := br.probing
+	br.probing = false
+
+	// Hosts asking to slow down are left alone for as long as they ask
+	var retryAfter time.Duration
+	switch {
+	case err != nil:
+		br.lastError = err.Error()
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		br.lastError = fmt.Sprintf("HTTP status code %d", resp.StatusCode)
+		retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	default:
+		delete(b.hosts, host)
+		return
+	}
+
+	now := time.Now()
+	br.failures++
+	br.lastFailureAt = now
+
+	switch {
+	case retryAfter > 0:
+		br.open(now.Add(retryAfter))
+	case probe:
+		br.cooldown = min(br.cooldown*2, maxBreakerCooldown)
+		br.open(now.Add(br.cooldown))
+	case br.failures >= breakerThreshold:
+		br.open(now.Add(br.cooldown))
+	}
+}
+
+// abandon ends a fetch from host given up by its caller, letting another fetch probe a half-open breaker
+// without counting the fetch as a failure
+func (b *Breakers) abandon(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if br, ok := b.hosts[host]; ok {
+		br.probing = false
+	}
+}
+
+// open opens the breaker until a time
+func (br *breaker) open(until time.Time) {
+	br.state = BreakerOpen
+	if until.After(br.openUntil) {
+		br.openUntil = until
+	}
+}
+
+// States returns the breakers of hosts with recent failures, ordered by host
+func (b *Breakers) States() []BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	states := make([]BreakerState, 0, len(b.hosts))
+	for host, br := range b.hosts {
+		state := BreakerState{
+			Host:                host,
+			State:               br.state,
+			ConsecutiveFailures: br.failures,
+			LastError:           br.lastError,
+			LastFailureAt:       br.lastFailureAt,
+		}
+		if br.state != BreakerClosed {
+			state.OpenUntil = br.openUntil
+		}
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Host < states[j].Host })
+	return states
+}
+
+// BreakerStates returns the circuit breakers of hosts the scraper recently failed to fetch from
+func BreakerStates() []BreakerState {
+	return breakers.States()
+}
+
+// parseRetryAfter parses a Retry-After header in seconds or as an HTTP date, capped at maxRetryAfter
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		wait = date.Sub(now)
+	}
+
+	if wait < 0 {
+		return 0
+	}
+	return min(wait, maxRetryAfter)
+}
@@ -0,0 +1,104 @@
+package scraper
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestBreakerOpensAndProbes tests opening after consecutive failures and closing after a successful probe
+func TestBreakerOpensAndProbes(t *testing.T) {
+	b := &Breakers{hosts: make(map[string]*breaker)}
+	failure := &http.Response{StatusCode: http.StatusBadGateway, Header: http.Header{}}
+
+	for i := 0; i < breakerThreshold; i++ {
+		if err := b.allow("docs.example.com"); err != nil {
+			t.Fatalf("Expected fetch %d to be allowed, got %v", i, err)
+		}
+		b.record("docs.example.com", failure, nil)
+	}
+	if err := b.allow("docs.example.com"); err == nil {
+		t.Fatal("Expected breaker to open after consecutive failures")
+	}
+	if err := b.allow("other.example.com"); err != nil {
+		t.Errorf("Expected other hosts to be unaffected, got %v", err)
+	}
+
+	// End the cooldown: one probe goes through, the rest wait for its outcome
+	b.hosts["docs.example.com"].openUntil = time.Now().Add(-time.Second)
+	if err := b.allow("docs.example.com"); err != nil {
+		t.Fatalf("Expected a probe after the cooldown, got %v", err)
+	}
+	if err := b.allow("docs.example.com"); err == nil {
+		t.Error("Expected a single probe at a time")
+	}
+
+	b.record("docs.example.com", nil, errors.New("connection refused"))
+	states := b.States()
+	if len(states) != 1 || states[0].State != BreakerOpen || time.Until(states[0].OpenUntil) < breakerCooldown {
+		t.Fatalf("Expected a failed probe to reopen with a longer cooldown, got %+v", states)
+	}
+
+	b.hosts["docs.example.com"].openUntil = time.Now().Add(-time.Second)
+	b.allow("docs.example.com")
+	b.record("docs.example.com", &http.Response{StatusCode: http.StatusOK}, nil)
+	if len(b.States()) != 0 {
+		t.Errorf("Expected a successful probe to close the breaker, got %+v", b.States())
+	}
+}
+
+// TestBreakerHonorsRetryAfter tests that a 429 opens the breaker for as long as the host asks
+func TestBreakerHonorsRetryAfter(t *testing.T) {
+	b := &Breakers{hosts: make(map[string]*breaker)}
+	b.record("api.example.com", &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"120"}}}, nil)
+
+	if err := b.allow("api.example.com"); err == nil {
+		t.Fatal("Expected Retry-After to open the breaker")
+	}
+	if wait := time.Until(b.States()[0].OpenUntil); wait < 110*time.Second || wait > 120*time.Second {
+		t.Errorf("Expected the breaker to stay open for 120s, got %v", wait)
+	}
+}
+
+// TestParseRetryAfter tests parsing Retry-After seconds and dates
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]time.Duration{
+		"":                              0,
+		"30":                            30 * time.Second,
+		"Wed, 01 May 2024 12:01:00 GMT": time.Minute,
+		"Wed, 01 May 2024 11:00:00 GMT": 0,
+		"999999":                        maxRetryAfter,
+		"soon":                          0,
+	}
+	for value, expected := range tests {
+		if got := parseRetryAfter(value, now); got != expected {
+			t.Errorf("parseRetryAfter(%q) = %v, expected %v", value, got, expected)
+		}
+	}
+}
+
+// TestBreakerAbandonedProbe tests a probe given up by its caller neither closes nor reopens the breaker
+func TestBreakerAbandonedProbe(t *testing.T) {
+	b := &Breakers{hosts: make(map[string]*breaker)}
+	failure := &http.Response{StatusCode: http.StatusBadGateway, Header: http.Header{}}
+	for i := 0; i < breakerThreshold; i++ {
+		b.allow("docs.example.com")
+		b.record("docs.example.com", failure, nil)
+	}
+
+	b.hosts["docs.example.com"].openUntil = time.Now().Add(-time.Second)
+	if err := b.allow("docs.example.com"); err != nil {
+		t.Fatalf("Expected a probe after the cooldown, got %v", err)
+	}
+	b.abandon("docs.example.com")
+
+	if err := b.allow("docs.example.com"); err != nil {
+		t.Errorf("Expected another probe after one was abandoned, got %v", err)
+	}
+	if states := b.States(); len(states) != 1 || states[0].State != BreakerHalfOpen || states[0].ConsecutiveFailures != breakerThreshold {
+		t.Errorf("Expected the abandoned probe not to count, got %+v", states)
+	}
+}
@@ -0,0 +1,222 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxCachedBody is the largest response body kept in the fetch cache
+const maxCachedBody = 10 << 20
+
+// maxMemoryCacheEntries caps the number of responses in a memory cache
+const maxMemoryCacheEntries = 500
+
+// CachedResponse is a successful fetch kept in the fetch cache
+type CachedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	FetchedAt  time.Time   `json:"fetched_at"`
+}
+
+// Cache stores fetched responses by URL
+type Cache interface {
+	Get(url string) (*CachedResponse, bool, error)
+	Set(url string, resp *CachedResponse, ttl time.Duration) error
+	Delete(url string) error
+	Purge() error
+}
+
+// CacheStats reports how well the fetch cache is doing
+type CacheStats struct {
+	Backend    string  `json:"backend"` // memory, redis, or off
+	TTLSeconds float64 `json:"ttl_seconds"`
+	Hits       int64   `json:"hits"`
+	Misses     int64   `json:"misses"`
+	HitRate    float64 `json:"hit_rate"`
+}
+
+// fetchCache is the cache used by fetches that allow caching, nil when disabled
+var fetchCache struct {
+	mu      sync.RWMutex
+	cache   Cache
+	backend string
+	ttl     time.Duration
+	hits    atomic.Int64
+	misses  atomic.Int64
+}
+
+// SetCache sets the cache of fetched responses and how long responses are kept; a nil cache disables caching
+func SetCache(cache Cache, backend string, ttl time.Duration) {
+	fetchCache.mu.Lock()
+	defer fetchCache.mu.Unlock()
+
+	fetchCache.cache = cache
+	fetchCache.backend = backend
+	fetchCache.ttl = ttl
+	fetchCache.hits.Store(0)
+	fetchCache.misses.Store(0)
+}
+
+// CacheStatistics returns the hits and misses of the fetch cache since it was set
+func CacheStatistics() CacheStats {
+	fetchCache.mu.RLock()
+	defer fetchCache.mu.RUnlock()
+
+	stats := CacheStats{Backend: "off", Hits: fetchCache.hits.Load(), Misses: fetchCache.misses.Load()}
+	if fetchCache.cache != nil {
+		stats.Backend = fetchCache.backend
+		stats.TTLSeconds = fetchCache.ttl.Seconds()
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// PurgeCache removes a URL from the fetch cache, or every URL when url is empty
+func PurgeCache(url string) error {
+	fetchCache.mu.RLock()
+	cache := fetchCache.cache
+	fetchCache.mu.RUnlock()
+
+	if cache == nil {
+		return nil
+	}
+	if url == "" {
+		return cache.Purge()
+	}
+	return cache.Delete(url)
+}
+
+// cachedFetch fetches a URL through the fetch cache, keeping successful responses for the cache TTL
+func cachedFetch(ctx context.Context, url string, auth RequestAuth, client *http.Client) (*http.Response, error) {
+	fetchCache.mu.RLock()
+	cache, ttl := fetchCache.cache, fetchCache.ttl
+	fetchCache.mu.RUnlock()
+
+	// Authenticated responses could leak across workspaces, so they're never cached
+	if cache == nil || auth != nil {
+		return fetch(ctx, url, auth, client)
+	}
+
+	if cached, ok, err := cache.Get(url); err == nil && ok {
+		fetchCache.hits.Add(1)
+		return cached.response(), nil
+	}
+	fetchCache.misses.Add(1)
+
+	resp, err := fetch(ctx, url, auth, client)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedBody+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	// Bodies too large to cache are handed on whole, the bytes read so far followed by the rest
+	if len(body) > maxCachedBody {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	cached := &CachedResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: body, FetchedAt: time.Now()}
+	cache.Set(url, cached, ttl)
+	return cached.response(), nil
+}
+
+// response turns a cached response back into an HTTP response
+func (c *CachedResponse) response() *http.Response {
+	return &http.Response{
+		StatusCode:    c.StatusCode,
+		Header:        c.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+	}
+}
+
+// MemoryCache keeps fetched responses in memory
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+}
+
+// memoryCacheEntry is a cached response and its expiry
+type memoryCacheEntry struct {
+	resp      *CachedResponse
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates an empty memory cache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]memoryCacheEntry)}
+}
+
+// Get returns the unexpired response of a URL
+func (m *MemoryCache) Get(url string) (*CachedResponse, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[url]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(m.entries, url)
+		return nil, false, nil
+	}
+	return entry.resp, true, nil
+}
+
+// Set keeps the response of a URL for ttl, evicting expired entries and then the oldest one when full
+func (m *MemoryCache) Set(url string, resp *CachedResponse, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.entries[url]; !ok && len(m.entries) >= maxMemoryCacheEntries {
+		now := time.Now()
+		oldest := ""
+		for key, entry := range m.entries {
+			if now.After(entry.expiresAt) {
+				delete(m.entries, key)
+			} else if oldest == "" || entry.expiresAt.Before(m.entries[oldest].expiresAt) {
+				oldest = key
+			}
+		}
+		if len(m.entries) >= maxMemoryCacheEntries {
+			delete(m.entries, oldest)
+		}
+	}
+
+	m.entries[url] = memoryCacheEntry{resp: resp, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// Delete removes the response of a URL
+func (m *MemoryCache) Delete(url string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, url)
+	return nil
+}
+
+// Purge removes every response
+func (m *MemoryCache) Purge() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries = make(map[string]memoryCacheEntry)
+	return nil
+}
@@ -0,0 +1,132 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"universal_api/internal/models"
+	"universal_api/internal/redis/redistest"
+)
+
+// TestCachedFetch tests that cached fetches reuse responses within the TTL and never cache credentials
+func TestCachedFetch(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"openapi": "3.0.0"}`))
+	}))
+	defer server.Close()
+
+	SetPolicySource(policyList{{Domain: models.DefaultPolicyDomain}})
+	defer SetPolicySource(nil)
+	SetCache(NewMemoryCache(), "memory", time.Minute)
+	defer SetCache(nil, "", 0)
+
+	for i := 0; i < 3; i++ {
+		resp, err := cachedFetch(context.Background(), server.URL, nil, nil)
+		if err != nil {
+			t.Fatalf("Failed to fetch: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != `{"openapi": "3.0.0"}` || resp.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected the cached response to match the original, got %q, %v", body, resp.Header)
+		}
+	}
+	if stats := CacheStatistics(); requests.Load() != 1 || stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("Expected one fetch and two hits, got %d fetches and %+v", requests.Load(), stats)
+	}
+
+	resp, _ := cachedFetch(context.Background(), server.URL, func(req *http.Request) { req.Header.Set("Authorization", "Bearer secret") }, nil)
+	resp.Body.Close()
+	if requests.Load() != 2 {
+		t.Errorf("Expected authenticated fetches to bypass the cache, got %d fetches", requests.Load())
+	}
+
+	PurgeCache(server.URL)
+	resp, _ = cachedFetch(context.Background(), server.URL, nil, nil)
+	resp.Body.Close()
+	if requests.Load() != 3 {
+		t.Errorf("Expected a purged URL to be fetched again, got %d fetches", requests.Load())
+	}
+}
+
+// TestCachedFetchLargeBody tests that bodies too large to cache are returned whole without being cached
+func TestCachedFetchLargeBody(t *testing.T) {
+	var requests atomic.Int64
+	large := bytes.Repeat([]byte("a"), maxCachedBody+maxCachedBody/10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write(large)
+	}))
+	defer server.Close()
+
+	SetPolicySource(policyList{{Domain: models.DefaultPolicyDomain}})
+	defer SetPolicySource(nil)
+	SetCache(NewMemoryCache(), "memory", time.Minute)
+	defer SetCache(nil, "", 0)
+
+	for i := 0; i < 2; i++ {
+		resp, err := cachedFetch(context.Background(), server.URL, nil, nil)
+		if err != nil {
+			t.Fatalf("Failed to fetch: %v", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil || !bytes.Equal(body, large) {
+			t.Errorf("Expected the whole body of %d bytes, got %d, %v", len(large), len(body), err)
+		}
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected the large body not to be cached, got %d fetches", requests.Load())
+	}
+}
+
+// TestMemoryCacheExpires tests that memory cache entries expire after their TTL
+func TestMemoryCacheExpires(t *testing.T) {
+	cache := NewMemoryCache()
+	cache.Set("https://docs.example.com/openapi.json", &CachedResponse{StatusCode: http.StatusOK}, -time.Second)
+
+	if _, ok, _ := cache.Get("https://docs.example.com/openapi.json"); ok {
+		t.Error("Expected an expired entry to be a miss")
+	}
+}
+
+// TestRedisCache tests the Redis cache against a minimal in-memory RESP server
+func TestRedisCache(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go redistest.Serve(listener)
+
+	cache, err := NewRedisCache("redis://" + listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to create Redis cache: %v", err)
+	}
+
+	resp := &CachedResponse{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": {"application/yaml"}}, Body: []byte("openapi: 3.0.0")}
+	if err := cache.Set("https://docs.example.com/openapi.yaml", resp, time.Minute); err != nil {
+		t.Fatalf("Failed to set: %v", err)
+	}
+	got, ok, err := cache.Get("https://docs.example.com/openapi.yaml")
+	if err != nil || !ok || string(got.Body) != "openapi: 3.0.0" || got.Header.Get("Content-Type") != "application/yaml" {
+		t.Fatalf("Expected the cached response back, got %+v, %v, %v", got, ok, err)
+	}
+
+	if err := cache.Purge(); err != nil {
+		t.Fatalf("Failed to purge: %v", err)
+	}
+	if _, ok, _ := cache.Get("https://docs.example.com/openapi.yaml"); ok {
+		t.Error("Expected purged entries to be gone")
+	}
+}
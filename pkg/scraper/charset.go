@@ -0,0 +1,29 @@
+package scraper
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/transform"
+)
+
+// utf8BOM is the byte order mark some editors put before UTF-8 content
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// decodeContent transcodes documentation to UTF-8, returning the content and the name of its original encoding.
+// A byte order mark or Content-Type charset is trusted; otherwise valid UTF-8 stays UTF-8, and anything else is
+// decoded as the page's meta charset, falling back to windows-1252 (the web's ISO-8859-1).
+func decodeContent(content []byte, contentType string) ([]byte, string, error) {
+	enc, name, certain := charset.DetermineEncoding(content, contentType)
+	if name == "utf-8" || (!certain && utf8.Valid(content)) {
+		return bytes.TrimPrefix(content, utf8BOM), "utf-8", nil
+	}
+
+	decoded, _, err := transform.Bytes(enc.NewDecoder(), content)
+	if err != nil {
+		return nil, name, fmt.Errorf("failed to decode %s content: %w", name, err)
+	}
+	return decoded, name, nil
+}
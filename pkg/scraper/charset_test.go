@@ -0,0 +1,38 @@
+package scraper
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+// TestDecodeContent tests transcoding documentation in legacy encodings to UTF-8
+func TestDecodeContent(t *testing.T) {
+	shiftJIS, _ := japanese.ShiftJIS.NewEncoder().String(`<html><head><meta charset="Shift_JIS"></head><body>ユーザー一覧</body></html>`)
+
+	tests := []struct {
+		name        string
+		content     string
+		contentType string
+		want        string
+		encoding    string
+	}{
+		{"Content-Type charset", "Caf\xe9 API", "text/html; charset=ISO-8859-1", "Café API", "windows-1252"},
+		{"meta charset", shiftJIS, "text/html", `<html><head><meta charset="Shift_JIS"></head><body>ユーザー一覧</body></html>`, "shift_jis"},
+		{"undeclared UTF-8", "Café API", "text/html", "Café API", "utf-8"},
+		{"UTF-8 byte order mark", "\xef\xbb\xbf{\"title\": \"Café\"}", "application/json", `{"title": "Café"}`, "utf-8"},
+		{"undeclared legacy", "Caf\xe9 API", "", "Café API", "windows-1252"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, encoding, err := decodeContent([]byte(tt.content), tt.contentType)
+			if err != nil {
+				t.Fatalf("Failed to decode: %v", err)
+			}
+			if string(content) != tt.want || encoding != tt.encoding {
+				t.Errorf("Expected %q in %s, got %q in %s", tt.want, tt.encoding, content, encoding)
+			}
+		})
+	}
+}
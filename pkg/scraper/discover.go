@@ -0,0 +1,136 @@
+package scraper
+
+import (
+	"bytes"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"universal_api/internal/models"
+)
+
+// maxSpecLinks bounds the spec links fetched from one page, so a page linking many files doesn't
+// turn one scrape into many
+const maxSpecLinks = 3
+
+// specLinkRels are the rel values of <link> elements pointing to a page's machine-readable spec
+var specLinkRels = []string{"openapi", "swagger", "service-desc"}
+
+// specFileNames are the file names of OpenAPI and Swagger specs
+var specFileNames = []string{
+	"openapi.json", "openapi.yaml", "openapi.yml", "swagger.json", "swagger.yaml", "swagger.yml",
+}
+
+// specDiscoveringParser parses an HTML page by the machine-readable spec it links to, in preference
+// to the page itself
+type specDiscoveringParser struct {
+	url     string
+	options Options
+}
+
+// Parse implements the Parser interface, falling back to the HTML parser when the page links to no spec
+func (p *specDiscoveringParser) Parse(content []byte) (*models.APIDoc, error) {
+	if apiDoc, ok := discoverSpec(p.url, content, p.options); ok {
+		return apiDoc, nil
+	}
+	return htmlParser(p.options.Rules).Parse(content)
+}
+
+// discoverSpec scrapes the spec an HTML page links to. The doc keeps the page's URL and records the
+// spec's in SpecURL, and the page's icon when the spec has no logo. It reports false when the page links to no spec that can be fetched and has
+// endpoints; links that turn out to be HTML pages aren't followed further.
+func discoverSpec(pageURL string, content []byte, options Options) (*models.APIDoc, bool) {
+	pipeline := NewPipeline(options)
+	for _, link := range specLinks(pageURL, content) {
+		doc, err := pipeline.Fetch(link)
+		if err != nil {
+			continue
+		}
+		if doc.Format = pipeline.Detect(doc); doc.Format == FormatHTML {
+			continue
+		}
+		apiDoc, err := pipeline.Process(doc)
+		if err != nil || len(apiDoc.Endpoints) == 0 {
+			continue
+		}
+		apiDoc.URL = pageURL
+		apiDoc.SpecURL = link
+		if apiDoc.IconURL == "" {
+			apiDoc.IconURL = pageIconURL(pageURL, content)
+		}
+		return apiDoc, true
+	}
+	return nil, false
+}
+
+// specLinks returns the absolute URLs of the specs an HTML page links to, best first: <link> elements
+// with a spec rel, then links to files named like specs, then download links to JSON or YAML files
+func specLinks(pageURL string, content []byte) []string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(content))
+	if err != nil {
+		return nil
+	}
+
+	var links []string
+	add := func(href string) {
+		ref, err := url.Parse(strings.TrimSpace(href))
+		if href == "" || err != nil {
+			return
+		}
+		link := base.ResolveReference(ref)
+		link.Fragment = ""
+		if (link.Scheme == "http" || link.Scheme == "https") && !containsString(links, link.String()) && len(links) < maxSpecLinks {
+			links = append(links, link.String())
+		}
+	}
+
+	doc.Find("link[rel][href]").Each(func(_ int, s *goquery.Selection) {
+		for _, rel := range strings.Fields(strings.ToLower(s.AttrOr("rel", ""))) {
+			if containsString(specLinkRels, rel) {
+				add(s.AttrOr("href", ""))
+				return
+			}
+		}
+	})
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		if containsString(specFileNames, linkFileName(s.AttrOr("href", ""))) {
+			add(s.AttrOr("href", ""))
+		}
+	})
+	doc.Find("a[href]").Each(func(_ int, s *goquery.Selection) {
+		_, download := s.Attr("download")
+		download = download || strings.Contains(strings.ToLower(s.Text()), "download")
+		switch path.Ext(linkFileName(s.AttrOr("href", ""))) {
+		case ".json", ".yaml", ".yml":
+			if download {
+				add(s.AttrOr("href", ""))
+			}
+		}
+	})
+	return links
+}
+
+// linkFileName returns the lowercased last segment of a link's path
+func linkFileName(href string) string {
+	ref, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(path.Base(ref.Path))
+}
+
+// containsString checks if a slice contains a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
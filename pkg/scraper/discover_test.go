@@ -0,0 +1,73 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestSpecLinks tests finding spec links in their order of preference
+func TestSpecLinks(t *testing.T) {
+	page := `<html><head>
+<link rel="stylesheet" href="/style.css">
+<link rel="alternate openapi" href="/specs/v2.yaml">
+</head><body>
+<a href="data.json" download>Download data</a>
+<a href="https://cdn.example.com/swagger.json#top">Swagger</a>
+<a href="/specs/v2.yaml">Spec</a>
+<a href="/guide.json">Guide</a>
+<a href="mailto:openapi.json">Mail</a>
+</body></html>`
+
+	links := specLinks("https://example.com/docs/api", []byte(page))
+	expected := []string{
+		"https://example.com/specs/v2.yaml",
+		"https://cdn.example.com/swagger.json",
+		"https://example.com/docs/data.json",
+	}
+	if len(links) != len(expected) {
+		t.Fatalf("Expected links %v, got %v", expected, links)
+	}
+	for i, link := range expected {
+		if links[i] != link {
+			t.Errorf("Expected link %d to be %s, got %s", i, link, links[i])
+		}
+	}
+}
+
+// TestScrapeDiscoveredSpec tests that a spec linked from an HTML page is scraped instead of the page
+func TestScrapeDiscoveredSpec(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/openapi.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"openapi": "3.0.0", "info": {"title": "Pets", "version": "2.0.0"},
+				"paths": {"/pets": {"get": {"summary": "List pets", "responses": {"200": {"description": "OK"}}}}}}`))
+		case "/empty.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"openapi": "3.0.0", "info": {"title": "Empty", "version": "1.0.0"}, "paths": {}}`))
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><head><title>Pets docs</title><link rel="icon" href="/favicon.png"></head><body>
+<a href="/missing/openapi.yaml">Broken</a>
+<a href="/empty.json" download>Empty</a>
+<a href="/openapi.json" download>Download the spec</a>
+<h2>GET /cats</h2></body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	apiDoc, err := ScrapeAPIDoc(server.URL + "/docs")
+	if err != nil {
+		t.Fatalf("Failed to scrape API doc: %v", err)
+	}
+	if apiDoc.Title != "Pets" || apiDoc.URL != server.URL+"/docs" || apiDoc.SpecURL != server.URL+"/openapi.json" {
+		t.Errorf("Expected the linked Pets spec recorded with the page, got %s from %s (spec %s)", apiDoc.Title, apiDoc.URL, apiDoc.SpecURL)
+	}
+	if len(apiDoc.Endpoints) != 1 || apiDoc.Endpoints[0].Path != "/pets" {
+		t.Errorf("Expected the spec's endpoints, got %v", apiDoc.Endpoints)
+	}
+	if apiDoc.IconURL != server.URL+"/favicon.png" {
+		t.Errorf("Expected the page's icon for a spec without a logo, got %q", apiDoc.IconURL)
+	}
+}
@@ -0,0 +1,94 @@
+package scraper
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"universal_api/internal/models"
+	"universal_api/pkg/parser"
+)
+
+// Hook processes a parsed doc before it's stored, e.g. to normalize, enrich, scrub, or tag it
+type Hook func(*models.APIDoc) error
+
+// hooks are the registered hooks by name and the chain of them configured to run on every parsed doc
+var hooks = struct {
+	sync.RWMutex
+	registered map[string]Hook
+	chain      []Hook
+}{
+	registered: map[string]Hook{
+		"normalize-paths":    parser.NormalizeEndpoints,
+		"merge-duplicates":   parser.MergeDuplicates,
+		"drop-unknown-paths": parser.DropUnknownPaths,
+		"summarize":          summarize,
+	},
+}
+
+// RegisterHook registers a hook under a name, so it can be configured by name, replacing any
+// hook registered under it before
+func RegisterHook(name string, hook Hook) {
+	hooks.Lock()
+	defer hooks.Unlock()
+	hooks.registered[name] = hook
+}
+
+// HookNames returns the names of the registered hooks, sorted
+func HookNames() []string {
+	hooks.RLock()
+	defer hooks.RUnlock()
+	names := make([]string, 0, len(hooks.registered))
+	for name := range hooks.registered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetHooks sets the chain of registered hooks that run, in order, on every parsed doc
+func SetHooks(names ...string) error {
+	hooks.Lock()
+	defer hooks.Unlock()
+	chain := make([]Hook, 0, len(names))
+	for _, name := range names {
+		hook, ok := hooks.registered[name]
+		if !ok {
+			return fmt.Errorf("unknown hook: %s", name)
+		}
+		chain = append(chain, hook)
+	}
+	hooks.chain = chain
+	return nil
+}
+
+// runHooks returns the post-processing stage running the configured hooks and then extra ones
+func runHooks(extra []Hook) PostProcessStage {
+	hooks.RLock()
+	chain := append(append([]Hook{}, hooks.chain...), extra...)
+	hooks.RUnlock()
+
+	return func(doc *Document, apiDoc *models.APIDoc) error {
+		for _, hook := range chain {
+			if err := hook(apiDoc); err != nil {
+				return fmt.Errorf("failed to post-process API documentation: %w", err)
+			}
+		}
+		return nil
+	}
+}
+
+// summarize summarizes a doc's long description with the configured summary options, if it has no summary
+func summarize(apiDoc *models.APIDoc) error {
+	if apiDoc.Summary != "" {
+		return nil
+	}
+	htmlOptions.RLock()
+	options := htmlOptions.summary
+	htmlOptions.RUnlock()
+
+	if summary := parser.Summarize(apiDoc.Description, options); summary != apiDoc.Description {
+		apiDoc.Summary = summary
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package scraper
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestSetHooks tests running configured built-in and registered hooks on parsed docs
+func TestSetHooks(t *testing.T) {
+	defer SetHooks()
+
+	RegisterHook("tag-internal", func(apiDoc *models.APIDoc) error {
+		for i := range apiDoc.Endpoints {
+			apiDoc.Endpoints[i].Tags = append(apiDoc.Endpoints[i].Tags, "internal")
+		}
+		return nil
+	})
+	if err := SetHooks("normalize-paths", "merge-duplicates", "tag-internal"); err != nil {
+		t.Fatalf("Failed to set hooks: %v", err)
+	}
+
+	apiDoc, err := ParseAPIDoc([]byte(`{"openapi": "3.0.0", "info": {"title": "Users", "version": "1.0.0"}, "paths": {
+		"/users/:id": {"get": {"summary": "Get a user", "responses": {"200": {"description": "OK"}}}},
+		"/users/{id}/": {"get": {"summary": "Get a user again", "responses": {"404": {"description": "Not Found"}}}}
+	}}`), "application/json")
+	if err != nil {
+		t.Fatalf("Failed to parse API doc: %v", err)
+	}
+	if len(apiDoc.Endpoints) != 1 || apiDoc.Endpoints[0].Path != "/users/{id}" || len(apiDoc.Endpoints[0].Responses) != 2 {
+		t.Fatalf("Expected the normalized paths merged into GET /users/{id}, got %+v", apiDoc.Endpoints)
+	}
+	if tags := apiDoc.Endpoints[0].Tags; len(tags) == 0 || tags[len(tags)-1] != "internal" {
+		t.Errorf("Expected the registered hook to tag the endpoint, got %v", tags)
+	}
+
+	if err := SetHooks("normalize-paths", "missing"); err == nil || !strings.Contains(err.Error(), "unknown hook: missing") {
+		t.Errorf("Expected an error for an unknown hook, got %v", err)
+	}
+}
+
+// TestHookErrors tests that a failing hook fails the scrape
+func TestHookErrors(t *testing.T) {
+	s := New(WithHook(func(apiDoc *models.APIDoc) error { return errors.New("rejected") }))
+	pipeline := s.Pipeline()
+	_, err := pipeline.Process(&Document{Content: []byte(`{"openapi": "3.0.0", "info": {"title": "A", "version": "1"}, "paths": {}}`), Format: FormatJSON})
+	if err == nil || !strings.Contains(err.Error(), "rejected") {
+		t.Errorf("Expected the hook's error, got %v", err)
+	}
+}
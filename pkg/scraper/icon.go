@@ -0,0 +1,37 @@
+package scraper
+
+import (
+	"net/url"
+	"strings"
+
+	"universal_api/internal/models"
+	"universal_api/pkg/parser"
+)
+
+// resolveIcon makes a parsed doc's icon URL absolute against the URL it was fetched from, dropping
+// icons that don't resolve to an HTTP URL
+func resolveIcon(doc *Document, apiDoc *models.APIDoc) error {
+	apiDoc.IconURL = resolveIconURL(doc.URL, apiDoc.IconURL)
+	return nil
+}
+
+// resolveIconURL resolves an icon reference against a base URL, returning "" when the result isn't an
+// HTTP URL. References without a base must already be absolute.
+func resolveIconURL(base, ref string) string {
+	iconRef, err := url.Parse(strings.TrimSpace(ref))
+	if ref == "" || err != nil {
+		return ""
+	}
+	if baseURL, err := url.Parse(base); base != "" && err == nil {
+		iconRef = baseURL.ResolveReference(iconRef)
+	}
+	if (iconRef.Scheme != "http" && iconRef.Scheme != "https") || iconRef.Host == "" {
+		return ""
+	}
+	return iconRef.String()
+}
+
+// pageIconURL returns the absolute URL of an HTML page's icon, or "" when it declares none
+func pageIconURL(pageURL string, content []byte) string {
+	return resolveIconURL(pageURL, parser.PageIcon(content))
+}
@@ -0,0 +1,45 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestResolveIconURL tests resolving icon references against the URL of the page they were found on
+func TestResolveIconURL(t *testing.T) {
+	tests := []struct {
+		base, ref, expected string
+	}{
+		{"https://example.com/docs/api", "/favicon.ico", "https://example.com/favicon.ico"},
+		{"https://example.com/docs/api", "img/logo.png", "https://example.com/docs/img/logo.png"},
+		{"https://example.com/docs/api", "//cdn.example.com/logo.png", "https://cdn.example.com/logo.png"},
+		{"", "https://example.com/logo.png", "https://example.com/logo.png"},
+		{"", "/favicon.ico", ""},
+		{"https://example.com/docs", "javascript:alert(1)", ""},
+		{"https://example.com/docs", "", ""},
+	}
+	for _, test := range tests {
+		if icon := resolveIconURL(test.base, test.ref); icon != test.expected {
+			t.Errorf("Expected %q against %q to resolve to %q, got %q", test.ref, test.base, test.expected, icon)
+		}
+	}
+}
+
+// TestScrapeIcon tests that a scraped page's icon is recorded as an absolute URL
+func TestScrapeIcon(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Docs</title><link rel="apple-touch-icon" href="static/touch.png"></head>
+<body><h2>GET /users</h2></body></html>`))
+	}))
+	defer server.Close()
+
+	apiDoc, err := ScrapeAPIDoc(server.URL + "/docs/")
+	if err != nil {
+		t.Fatalf("Failed to scrape API doc: %v", err)
+	}
+	if apiDoc.IconURL != server.URL+"/docs/static/touch.png" {
+		t.Errorf("Expected the page's touch icon, got %q", apiDoc.IconURL)
+	}
+}
@@ -0,0 +1,83 @@
+package scraper
+
+import (
+	"net/http"
+
+	"universal_api/internal/models"
+	"universal_api/pkg/parser"
+)
+
+// Scraper scrapes API documentation. Programs embed one to scrape without running the HTTP server:
+//
+//	s := scraper.New(scraper.WithClient(client), scraper.WithMaxBodySize(10<<20))
+//	doc, err := s.Scrape("https://example.com/openapi.json")
+type Scraper struct {
+	options Options
+}
+
+// Option configures a Scraper
+type Option func(*Options)
+
+// New returns a scraper configured with options
+func New(opts ...Option) *Scraper {
+	s := &Scraper{}
+	for _, opt := range opts {
+		opt(&s.options)
+	}
+	return s
+}
+
+// WithClient makes requests with the transport, timeout, and cookie jar of a client
+func WithClient(client *http.Client) Option {
+	return func(o *Options) { o.Client = client }
+}
+
+// WithAuth adds credentials to every request, for documentation behind a login
+func WithAuth(auth RequestAuth) Option {
+	return func(o *Options) { o.Auth = auth }
+}
+
+// WithCache reuses responses fetched within the fetch cache TTL
+func WithCache() Option {
+	return func(o *Options) { o.Cache = true }
+}
+
+// WithMaxBodySize rejects documentation larger than a number of bytes
+func WithMaxBodySize(bytes int64) Option {
+	return func(o *Options) { o.MaxBodySize = bytes }
+}
+
+// WithRules overrides the heuristics HTML docs are parsed with
+func WithRules(rules *parser.Rules) Option {
+	return func(o *Options) { o.Rules = rules }
+}
+
+// WithParser parses documentation of a format with a parser instead of the built-in one
+func WithParser(format Format, p parser.Parser) Option {
+	return func(o *Options) {
+		if o.Parsers == nil {
+			o.Parsers = make(map[Format]parser.Parser)
+		}
+		o.Parsers[format] = p
+	}
+}
+
+// WithFormat parses documentation as a format instead of detecting it
+func WithFormat(format Format) Option {
+	return func(o *Options) { o.Format = format }
+}
+
+// WithHook runs a hook on every parsed doc, after the configured ones
+func WithHook(hook Hook) Option {
+	return func(o *Options) { o.Hooks = append(o.Hooks, hook) }
+}
+
+// Scrape scrapes the API documentation at a URL
+func (s *Scraper) Scrape(url string) (*models.APIDoc, error) {
+	return s.Pipeline().Scrape(url)
+}
+
+// Pipeline returns the pipeline of the scraper, for callers that replace its stages
+func (s *Scraper) Pipeline() *Pipeline {
+	return NewPipeline(s.options)
+}
@@ -0,0 +1,85 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"universal_api/internal/models"
+	"universal_api/pkg/parser"
+)
+
+// TestScraperOptions tests configuring an embedded scraper with a client, a parser, a hook, and a body limit
+func TestScraperOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"openapi": "3.0.0", "info": {"title": "Pets", "version": "1.0.0"}, "paths": {}}`))
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	s := New(
+		WithClient(&http.Client{Transport: transport}),
+		WithParser(FormatJSON, &parser.YAMLParser{}),
+		WithHook(func(apiDoc *models.APIDoc) error {
+			apiDoc.Title += " (embedded)"
+			return nil
+		}),
+	)
+
+	apiDoc, err := s.Scrape(server.URL + "/openapi.json")
+	if err != nil {
+		t.Fatalf("Failed to scrape: %v", err)
+	}
+	if apiDoc.Title != "Pets (embedded)" || apiDoc.URL != server.URL+"/openapi.json" {
+		t.Errorf("Expected the hooked title and the URL, got %q from %s", apiDoc.Title, apiDoc.URL)
+	}
+	if transport.requests != 1 {
+		t.Errorf("Expected the request to go through the client's transport, got %d requests", transport.requests)
+	}
+
+	if _, err := New(WithMaxBodySize(10)).Scrape(server.URL + "/openapi.json"); err == nil || !strings.Contains(err.Error(), "larger than 10 bytes") {
+		t.Errorf("Expected documentation over the body size limit to be rejected, got %v", err)
+	}
+}
+
+// TestScraperContext tests a fetch is given up when the context of the scrape is done, without counting
+// against the host's circuit breaker
+func TestScraperContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := ScrapeAPIDocWithOptions(server.URL+"/openapi.json", Options{Context: ctx}); err == nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the scrape to fail with the context's deadline, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the fetch to be given up at the deadline, took %v", elapsed)
+	}
+	for _, state := range BreakerStates() {
+		if strings.HasPrefix(server.URL, "http://"+state.Host) {
+			t.Errorf("Expected the given up fetch not to count as a failure, got %+v", state)
+		}
+	}
+}
+
+// countingTransport counts the requests made through the default transport
+type countingTransport struct {
+	requests int
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
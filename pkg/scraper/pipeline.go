@@ -0,0 +1,236 @@
+package scraper
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"universal_api/internal/models"
+	"universal_api/pkg/parser"
+)
+
+// Format is the format of fetched documentation, which decides the parser it gets
+type Format string
+
+const (
+	FormatHTML Format = "html"
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+
+	// Spec formats are never detected, only given as hints. Their content may be JSON or YAML.
+	FormatOpenAPI  Format = "openapi"
+	FormatSwagger  Format = "swagger"
+	FormatAsyncAPI Format = "asyncapi"
+)
+
+// Formats are the formats documentation can be forced to be parsed as
+var Formats = []Format{FormatOpenAPI, FormatSwagger, FormatAsyncAPI, FormatHTML, FormatJSON, FormatYAML}
+
+// ParseFormat parses a format hint, ignoring case. An empty hint is no hint: the format is detected.
+func ParseFormat(hint string) (Format, error) {
+	hint = strings.ToLower(strings.TrimSpace(hint))
+	if hint == "" {
+		return "", nil
+	}
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		if Format(hint) == format {
+			return format, nil
+		}
+		names[i] = string(format)
+	}
+	return "", fmt.Errorf("unsupported format %q, expected one of %s", hint, strings.Join(names, ", "))
+}
+
+// Document is fetched documentation on its way through a pipeline
+type Document struct {
+	URL         string
+	Content     []byte // transcoded to UTF-8
+	Raw         []byte // as fetched, before transcoding
+	ContentType string
+	Encoding    string // original character encoding, e.g. shift_jis
+	Format      Format // detected when empty
+}
+
+// FetchStage fetches the documentation at a URL
+type FetchStage func(url string) (*Document, error)
+
+// DetectStage detects the format of fetched documentation
+type DetectStage func(doc *Document) Format
+
+// SelectStage selects the parser for fetched documentation of a detected format
+type SelectStage func(doc *Document) parser.Parser
+
+// PostProcessStage completes a parsed doc with what the parser can't know, like where it was fetched from
+type PostProcessStage func(doc *Document, apiDoc *models.APIDoc) error
+
+// Pipeline scrapes documentation in stages: fetch, detect the format, select a parser, parse, and
+// post-process. Each stage can be replaced, and post-processing stages added.
+type Pipeline struct {
+	Fetch       FetchStage
+	Detect      DetectStage
+	Select      SelectStage
+	PostProcess []PostProcessStage
+}
+
+// NewPipeline returns the pipeline that scrapes documentation with the given options
+func NewPipeline(options Options) *Pipeline {
+	return &Pipeline{
+		Fetch:       options.fetchDocument,
+		Detect:      options.detectFormat,
+		Select:      options.selectParser,
+		PostProcess: []PostProcessStage{keepRawSource, recordSource, resolveIcon, stampTimes, runHooks(options.Hooks)},
+	}
+}
+
+// Scrape fetches, parses, and post-processes the documentation at a URL
+func (p *Pipeline) Scrape(url string) (*models.APIDoc, error) {
+	doc, err := p.Fetch(url)
+	if err != nil {
+		return nil, err
+	}
+	return p.Process(doc)
+}
+
+// Process parses and post-processes fetched documentation, detecting its format when it isn't set
+func (p *Pipeline) Process(doc *Document) (*models.APIDoc, error) {
+	if doc.Format == "" {
+		doc.Format = p.Detect(doc)
+	}
+
+	apiDoc, err := p.Select(doc).Parse(doc.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse API documentation: %w", err)
+	}
+
+	for _, stage := range p.PostProcess {
+		if err := stage(doc, apiDoc); err != nil {
+			return nil, err
+		}
+	}
+	return apiDoc, nil
+}
+
+// fetchDocument fetches the documentation at a URL and transcodes it to UTF-8
+func (o Options) fetchDocument(url string) (*Document, error) {
+	resp, err := o.fetch(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP request failed with status code: %d", resp.StatusCode)
+	}
+
+	body := io.Reader(resp.Body)
+	if o.MaxBodySize > 0 {
+		body = io.LimitReader(resp.Body, o.MaxBodySize+1)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if o.MaxBodySize > 0 && int64(len(content)) > o.MaxBodySize {
+		return nil, fmt.Errorf("documentation is larger than %d bytes", o.MaxBodySize)
+	}
+
+	raw := content
+	contentType := resp.Header.Get("Content-Type")
+	content, encoding, err := decodeContent(content, contentType)
+	if err != nil {
+		return nil, err
+	}
+	return &Document{URL: url, Content: content, Raw: raw, ContentType: contentType, Encoding: encoding}, nil
+}
+
+// DetectFormat detects the format of documentation from its content type or, failing that, from
+// the content itself. Content that can't be told apart is JSON at Swagger/OpenAPI URLs and HTML
+// elsewhere.
+func DetectFormat(doc *Document) Format {
+	switch {
+	case strings.Contains(doc.ContentType, "html"):
+		return FormatHTML
+	case strings.Contains(doc.ContentType, "json"):
+		return FormatJSON
+	case strings.Contains(doc.ContentType, "yaml") || strings.Contains(doc.ContentType, "yml"):
+		return FormatYAML
+	case strings.HasPrefix(strings.TrimSpace(string(doc.Content)), "<"):
+		return FormatHTML
+	case isJSON(doc.Content):
+		return FormatJSON
+	case isYAML(doc.Content):
+		return FormatYAML
+	case isSwaggerURL(doc.URL):
+		return FormatJSON
+	}
+	return FormatHTML
+}
+
+// detectFormat returns the format set in the options, detecting it when none is
+func (o Options) detectFormat(doc *Document) Format {
+	if o.Format != "" {
+		return o.Format
+	}
+	return DetectFormat(doc)
+}
+
+// selectParser selects the parser of a format, the one set in the options if any. HTML pages are
+// parsed by the spec they link to, if any, and otherwise by the HTML parser with the configured options.
+// Pages forced to be parsed as HTML are parsed as they are.
+func (o Options) selectParser(doc *Document) parser.Parser {
+	if p, ok := o.Parsers[doc.Format]; ok {
+		return p
+	}
+	switch doc.Format {
+	case FormatJSON:
+		return &parser.JSONParser{}
+	case FormatYAML:
+		return &parser.YAMLParser{}
+	case FormatOpenAPI, FormatSwagger:
+		if isJSON(doc.Content) {
+			return &parser.JSONParser{}
+		}
+		return &parser.YAMLParser{}
+	case FormatAsyncAPI:
+		return &parser.AsyncAPIParser{}
+	}
+	if doc.URL == "" || o.Format == FormatHTML {
+		return htmlParser(o.Rules)
+	}
+	return &specDiscoveringParser{url: doc.URL, options: o}
+}
+
+// recordSource records the URL and original encoding of the documentation a doc was parsed from
+func recordSource(doc *Document, apiDoc *models.APIDoc) error {
+	if doc.URL != "" {
+		apiDoc.URL = doc.URL
+	}
+	apiDoc.Encoding = doc.Encoding
+	return nil
+}
+
+// keepRawSource hands the content a doc was parsed from to the caller, to archive. A doc parsed from
+// a spec discovered on a page keeps the spec's content, which is handed over first.
+func keepRawSource(doc *Document, apiDoc *models.APIDoc) error {
+	if apiDoc.RawSource != nil || doc.Raw == nil {
+		return nil
+	}
+	apiDoc.RawSource = &models.RawSource{
+		URL:         doc.URL,
+		ContentType: doc.ContentType,
+		Format:      string(doc.Format),
+		FetchedAt:   time.Now(),
+		Content:     doc.Raw,
+	}
+	return nil
+}
+
+// stampTimes sets the creation and update times of a freshly scraped doc
+func stampTimes(doc *Document, apiDoc *models.APIDoc) error {
+	apiDoc.CreatedAt = time.Now()
+	apiDoc.UpdatedAt = time.Now()
+	return nil
+}
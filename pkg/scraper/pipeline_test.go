@@ -0,0 +1,149 @@
+package scraper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"universal_api/internal/models"
+)
+
+// TestDetectFormat tests detecting the format from the content type, the content, and the URL
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		doc      Document
+		expected Format
+	}{
+		{Document{ContentType: "text/html; charset=utf-8", Content: []byte(`{"a": 1}`)}, FormatHTML},
+		{Document{ContentType: "application/json"}, FormatJSON},
+		{Document{ContentType: "application/x-yaml"}, FormatYAML},
+		{Document{Content: []byte("<html><p>Note: this is HTML</p></html>")}, FormatHTML},
+		{Document{Content: []byte(`{"openapi": "3.0.0"}`)}, FormatJSON},
+		{Document{Content: []byte("openapi: 3.0.0")}, FormatYAML},
+		{Document{URL: "https://example.com/swagger", Content: []byte("unknown")}, FormatJSON},
+		{Document{URL: "https://example.com/docs", Content: []byte("unknown")}, FormatHTML},
+	}
+
+	for _, test := range tests {
+		if format := DetectFormat(&test.doc); format != test.expected {
+			t.Errorf("DetectFormat(%s %q): expected %s, got %s", test.doc.ContentType, test.doc.Content, test.expected, format)
+		}
+	}
+}
+
+// TestPipelineStages tests replacing a stage and adding a post-processing stage
+func TestPipelineStages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("openapi: 3.0.0\ninfo:\n  title: Plain API\n  version: 1.0.0\npaths: {}\n"))
+	}))
+	defer server.Close()
+
+	pipeline := NewPipeline(Options{})
+	pipeline.Detect = func(doc *Document) Format { return FormatYAML }
+	pipeline.PostProcess = append(pipeline.PostProcess, func(doc *Document, apiDoc *models.APIDoc) error {
+		apiDoc.Description = "Fetched as " + doc.ContentType
+		return nil
+	})
+
+	apiDoc, err := pipeline.Scrape(server.URL + "/spec")
+	if err != nil {
+		t.Fatalf("Failed to scrape: %v", err)
+	}
+	if apiDoc.Title != "Plain API" || apiDoc.URL != server.URL+"/spec" || apiDoc.CreatedAt.IsZero() {
+		t.Errorf("Expected the YAML spec with its URL and times, got %+v", apiDoc)
+	}
+	if apiDoc.Description != "Fetched as text/plain" {
+		t.Errorf("Expected the added stage to run, got description %q", apiDoc.Description)
+	}
+}
+
+// TestParseFormat tests parsing format hints
+func TestParseFormat(t *testing.T) {
+	if format, err := ParseFormat(" AsyncAPI "); err != nil || format != FormatAsyncAPI {
+		t.Errorf("Expected the asyncapi format, got %q (%v)", format, err)
+	}
+	if format, err := ParseFormat(""); err != nil || format != "" {
+		t.Errorf("Expected no format for an empty hint, got %q (%v)", format, err)
+	}
+	if _, err := ParseFormat("raml"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
+
+// TestScrapeWithFormat tests that a format hint bypasses detection
+func TestScrapeWithFormat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/events.yaml":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("asyncapi: 2.6.0\ninfo:\n  title: Events\n  version: 1.0.0\nchannels:\n  orders:\n    subscribe:\n      summary: Order events\n"))
+		case "/openapi.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"openapi": "3.0.0", "info": {"title": "Spec", "version": "1.0.0"},
+				"paths": {"/pets": {"get": {"responses": {"200": {"description": "OK"}}}}}}`))
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><head><title>Page</title><link rel="openapi" href="/openapi.json"></head><body><h2>GET /cats</h2></body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	apiDoc, err := ScrapeAPIDocWithOptions(server.URL+"/events.yaml", Options{Format: FormatAsyncAPI})
+	if err != nil {
+		t.Fatalf("Failed to scrape as AsyncAPI: %v", err)
+	}
+	if apiDoc.Title != "Events" || len(apiDoc.Endpoints) != 1 {
+		t.Errorf("Expected the Events AsyncAPI doc with one channel, got %s with %d endpoints", apiDoc.Title, len(apiDoc.Endpoints))
+	}
+
+	apiDoc, err = ScrapeAPIDocWithOptions(server.URL+"/docs", Options{Format: FormatHTML})
+	if err != nil {
+		t.Fatalf("Failed to scrape as HTML: %v", err)
+	}
+	if apiDoc.Title != "Page" || apiDoc.SpecURL != "" {
+		t.Errorf("Expected the page itself parsed without discovering its spec, got %s (spec %q)", apiDoc.Title, apiDoc.SpecURL)
+	}
+}
+
+// TestScrapeKeepsRawSource tests that a scraped doc carries the content it was parsed from as fetched,
+// the discovered spec's for a page linking to one
+func TestScrapeKeepsRawSource(t *testing.T) {
+	spec := `{"openapi": "3.0.0", "info": {"title": "Spec", "version": "1.0.0"},
+		"paths": {"/pets": {"get": {"responses": {"200": {"description": "OK"}}}}}}`
+	page := "<html><head><title>Caf\xe9</title></head><body><h2>GET /cafes</h2></body></html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/openapi.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(spec))
+		case "/linked":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<html><head><link rel="openapi" href="/openapi.json"></head><body></body></html>`))
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=iso-8859-1")
+			w.Write([]byte(page))
+		}
+	}))
+	defer server.Close()
+
+	apiDoc, err := ScrapeAPIDoc(server.URL + "/docs")
+	if err != nil {
+		t.Fatalf("Failed to scrape API doc: %v", err)
+	}
+	source := apiDoc.RawSource
+	if source == nil || string(source.Content) != page || source.URL != server.URL+"/docs" || source.Format != "html" {
+		t.Fatalf("Expected the page as fetched, before transcoding, got %+v", source)
+	}
+	if source.ContentType != "text/html; charset=iso-8859-1" || apiDoc.Title != "Café" {
+		t.Errorf("Expected the served content type and a transcoded title, got %q and %q", source.ContentType, apiDoc.Title)
+	}
+
+	apiDoc, err = ScrapeAPIDoc(server.URL + "/linked")
+	if err != nil {
+		t.Fatalf("Failed to scrape API doc: %v", err)
+	}
+	if source := apiDoc.RawSource; source == nil || string(source.Content) != spec || source.URL != server.URL+"/openapi.json" {
+		t.Errorf("Expected the discovered spec as the raw source, got %+v", source)
+	}
+}
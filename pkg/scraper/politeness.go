@@ -0,0 +1,212 @@
+package scraper
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"universal_api/internal/models"
+)
+
+// DefaultUserAgent identifies the scraper to hosts without a policy setting one
+const DefaultUserAgent = "universal-api-scraper/1.0"
+
+// maxPolitenessWait is how long a fetch waits for its turn before giving up
+const maxPolitenessWait = time.Minute
+
+// politenessPollInterval is how often a fetch waiting for a concurrency slot checks again
+const politenessPollInterval = 50 * time.Millisecond
+
+// defaultPolicy applies to domains without a policy when no "*" policy is configured
+var defaultPolicy = models.ScrapePolicy{
+	Domain:             models.DefaultPolicyDomain,
+	MinIntervalSeconds: 1,
+	MaxConcurrency:     2,
+}
+
+// PolicySource provides the configured scrape policies
+type PolicySource interface {
+	GetAllScrapePolicies() ([]*models.ScrapePolicy, error)
+}
+
+// politeness spaces out and caps concurrent fetches per domain according to the scrape policies
+var politeness = &Politeness{local: NewMemoryLimiter()}
+
+// SetPolicySource sets where the scraper reads per-domain scrape policies from
+func SetPolicySource(source PolicySource) {
+	politeness.mu.Lock()
+	defer politeness.mu.Unlock()
+	politeness.source = source
+}
+
+// SetLimiterStore sets where the scraper keeps the politeness state of hosts; nil keeps it in memory.
+// A shared store keeps hosts' intervals and concurrency caps across restarts and replicas.
+func SetLimiterStore(store LimiterStore) {
+	politeness.mu.Lock()
+	defer politeness.mu.Unlock()
+	politeness.store = store
+}
+
+// LimiterStore keeps the politeness state of hosts: when each may be fetched next and how many fetches of it are running
+type LimiterStore interface {
+	// Reserve starts a fetch of a host if interval has passed since the last one started and fewer than
+	// maxActive fetches of it are running, without a cap when maxActive is 0. Otherwise it reports how
+	// long to wait before trying again.
+	Reserve(host string, interval time.Duration, maxActive int) (bool, time.Duration, error)
+	// Release ends a fetch of a host started by Reserve
+	Release(host string) error
+}
+
+// Politeness schedules fetches per host so no host is hit more often or more concurrently than its policy allows
+type Politeness struct {
+	mu     sync.Mutex
+	source PolicySource
+	store  LimiterStore   // shared state, when configured
+	local  *MemoryLimiter // state of this process, used without a shared store or when it fails
+}
+
+// acquire waits until a fetch from host is allowed, returning the host's policy and a func to call when the fetch is done
+func (p *Politeness) acquire(host string) (models.ScrapePolicy, func(), error) {
+	deadline := time.Now().Add(maxPolitenessWait)
+
+	for {
+		p.mu.Lock()
+		policy := p.policyFor(host)
+		store := p.store
+		p.mu.Unlock()
+
+		interval := time.Duration(policy.MinIntervalSeconds * float64(time.Second))
+		ok, wait, limiter := p.reserve(store, host, interval, policy.MaxConcurrency)
+		if ok {
+			return policy, func() { limiter.Release(host) }, nil
+		}
+
+		if wait < politenessPollInterval {
+			wait = politenessPollInterval
+		}
+		if time.Now().Add(wait).After(deadline) {
+			return policy, nil, fmt.Errorf("too many scrapes of %s queued, try again later", host)
+		}
+		time.Sleep(wait)
+	}
+}
+
+// reserve reserves a fetch of a host in the shared store, or in local state without one or when it fails,
+// returning the limiter to release the fetch in
+func (p *Politeness) reserve(store LimiterStore, host string, interval time.Duration, maxActive int) (bool, time.Duration, LimiterStore) {
+	if store != nil {
+		ok, wait, err := store.Reserve(host, interval, maxActive)
+		if err == nil {
+			return ok, wait, store
+		}
+		// Scraping goes on when the shared store is down, if only as politely as this process can manage
+		log.Printf("Failed to reserve a fetch of %s in the limiter store, using local state: %v", host, err)
+	}
+	ok, wait, _ := p.local.Reserve(host, interval, maxActive)
+	return ok, wait, p.local
+}
+
+// MemoryLimiter keeps the politeness state of hosts in memory, for a single process
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	domains map[string]*domainState
+}
+
+// domainState tracks the fetches to a host
+type domainState struct {
+	next   time.Time // earliest start of the next fetch
+	active int
+}
+
+// NewMemoryLimiter creates a limiter without fetches
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{domains: make(map[string]*domainState)}
+}
+
+// Reserve starts a fetch of a host if its interval has passed and it's under its concurrency cap
+func (m *MemoryLimiter) Reserve(host string, interval time.Duration, maxActive int) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.domains[host]
+	if !ok {
+		state = &domainState{}
+		m.domains[host] = state
+	}
+
+	now := time.Now()
+	if maxActive > 0 && state.active >= maxActive {
+		return false, 0, nil
+	}
+	if wait := state.next.Sub(now); wait > 0 {
+		return false, wait, nil
+	}
+	state.active++
+	state.next = now.Add(interval)
+	return true, 0, nil
+}
+
+// Release ends a fetch of a host
+func (m *MemoryLimiter) Release(host string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if state, ok := m.domains[host]; ok && state.active > 0 {
+		state.active--
+	}
+	return nil
+}
+
+// policyFor returns the policy of a host: its own, its closest parent domain's, or the default
+func (p *Politeness) policyFor(host string) models.ScrapePolicy {
+	if p.source == nil {
+		return defaultPolicy
+	}
+	policies, err := p.source.GetAllScrapePolicies()
+	if err != nil {
+		return defaultPolicy
+	}
+	return MatchPolicy(policies, host)
+}
+
+// MatchPolicy returns the policy of a host: the one of the longest matching domain, or the default policy
+func MatchPolicy(policies []*models.ScrapePolicy, host string) models.ScrapePolicy {
+	host = strings.ToLower(host)
+	if i := strings.LastIndex(host, ":"); i >= 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+
+	match := defaultPolicy
+	matched := ""
+	for _, policy := range policies {
+		domain := strings.ToLower(policy.Domain)
+		switch {
+		case domain == models.DefaultPolicyDomain:
+			if matched == "" {
+				match = *policy
+			}
+		case host == domain || strings.HasSuffix(host, "."+domain):
+			if len(domain) > len(matched) {
+				match = *policy
+				matched = domain
+			}
+		}
+	}
+	return match
+}
+
+// applyPolicy sets a policy's User-Agent and custom headers on a request
+func applyPolicy(req *http.Request, policy models.ScrapePolicy) {
+	userAgent := policy.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	for name, value := range policy.Headers {
+		req.Header.Set(name, value)
+	}
+}
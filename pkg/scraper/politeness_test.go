@@ -0,0 +1,147 @@
+package scraper
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"universal_api/internal/models"
+	"universal_api/internal/redis/redistest"
+)
+
+// policyList is a fixed PolicySource
+type policyList []*models.ScrapePolicy
+
+// GetAllScrapePolicies returns the policies
+func (l policyList) GetAllScrapePolicies() ([]*models.ScrapePolicy, error) {
+	return l, nil
+}
+
+// TestMatchPolicy tests choosing the most specific policy of a host
+func TestMatchPolicy(t *testing.T) {
+	policies := []*models.ScrapePolicy{
+		{Domain: "*", MinIntervalSeconds: 3},
+		{Domain: "example.com", MinIntervalSeconds: 5},
+		{Domain: "docs.example.com", MinIntervalSeconds: 10},
+	}
+
+	tests := map[string]float64{
+		"docs.example.com":     10,
+		"v2.docs.example.com":  10,
+		"api.example.com:8443": 5,
+		"EXAMPLE.COM":          5,
+		"notexample.com":       3,
+		"other.org":            3,
+	}
+	for host, expected := range tests {
+		if got := MatchPolicy(policies, host).MinIntervalSeconds; got != expected {
+			t.Errorf("Expected %s to get interval %v, got %v", host, expected, got)
+		}
+	}
+
+	if got := MatchPolicy(nil, "other.org"); got.MinIntervalSeconds != defaultPolicy.MinIntervalSeconds {
+		t.Errorf("Expected the built-in default without policies, got %+v", got)
+	}
+}
+
+// TestPolitenessSpacesFetches tests that fetches follow the interval, concurrency cap, and headers of a policy
+func TestPolitenessSpacesFetches(t *testing.T) {
+	var mu sync.Mutex
+	var starts []time.Time
+	active, maxActive := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+
+		if r.Header.Get("User-Agent") != "polite-bot" || r.Header.Get("X-Contact") != "ops@example.com" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		time.Sleep(30 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	SetPolicySource(policyList{{
+		Domain:             "127.0.0.1",
+		MinIntervalSeconds: 0.05,
+		MaxConcurrency:     1,
+		UserAgent:          "polite-bot",
+		Headers:            map[string]string{"X-Contact": "ops@example.com"},
+	}})
+	defer SetPolicySource(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := fetch(context.Background(), server.URL, nil, nil)
+			if err != nil {
+				t.Errorf("Failed to fetch: %v", err)
+				return
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("Expected policy headers to be sent, got status %d", resp.StatusCode)
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if maxActive != 1 {
+		t.Errorf("Expected at most 1 concurrent fetch, got %d", maxActive)
+	}
+	for i := 1; i < len(starts); i++ {
+		if gap := starts[i].Sub(starts[i-1]); gap < 45*time.Millisecond {
+			t.Errorf("Expected fetches at least 50ms apart, got %v", gap)
+		}
+	}
+}
+
+// TestRedisLimiter tests keeping the interval and concurrency cap of hosts in Redis
+func TestRedisLimiter(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go redistest.Serve(listener)
+
+	// Two limiters on the same Redis stand for two instances, or one before and after a restart
+	first, err := NewRedisLimiter("redis://" + listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to create Redis limiter: %v", err)
+	}
+	second, _ := NewRedisLimiter("redis://" + listener.Addr().String())
+
+	if ok, _, err := first.Reserve("docs.example.com", time.Minute, 0); !ok || err != nil {
+		t.Fatalf("Expected the first fetch to start, got %v, %v", ok, err)
+	}
+	ok, wait, err := second.Reserve("docs.example.com", time.Minute, 0)
+	if ok || err != nil || wait <= 50*time.Second {
+		t.Errorf("Expected the other instance to wait out the interval, got %v, %v, %v", ok, wait, err)
+	}
+
+	if ok, _, _ := first.Reserve("api.example.com", 0, 1); !ok {
+		t.Fatal("Expected a fetch under the concurrency cap to start")
+	}
+	if ok, _, _ := second.Reserve("api.example.com", 0, 1); ok {
+		t.Error("Expected the concurrency cap to count the fetches of every instance")
+	}
+	first.Release("api.example.com")
+	if ok, _, _ := second.Reserve("api.example.com", 0, 1); !ok {
+		t.Error("Expected a released slot to be free for the other instance")
+	}
+}
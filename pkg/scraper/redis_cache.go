@@ -0,0 +1,95 @@
+package scraper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"universal_api/internal/redis"
+)
+
+// redisKeyPrefix namespaces the fetch cache's keys in a shared Redis
+const redisKeyPrefix = "universal_api:fetch:"
+
+// RedisCache keeps fetched responses in Redis, shared by every instance of the service
+type RedisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache creates a cache on the Redis server at a redis://[:password@]host:port[/db] URL
+func NewRedisCache(rawURL string) (*RedisCache, error) {
+	client, err := redis.Dial(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisCache{client: client}, nil
+}
+
+// Get returns the cached response of a URL
+func (r *RedisCache) Get(url string) (*CachedResponse, bool, error) {
+	reply, err := r.client.Do("GET", redisKey(url))
+	if err != nil || reply == nil {
+		return nil, false, err
+	}
+
+	var resp CachedResponse
+	if err := json.Unmarshal([]byte(reply.(string)), &resp); err != nil {
+		return nil, false, err
+	}
+	return &resp, true, nil
+}
+
+// Set keeps the response of a URL for ttl
+func (r *RedisCache) Set(url string, resp *CachedResponse, ttl time.Duration) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	_, err = r.client.Do("SET", redisKey(url), string(data), "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	return err
+}
+
+// Delete removes the response of a URL
+func (r *RedisCache) Delete(url string) error {
+	_, err := r.client.Do("DEL", redisKey(url))
+	return err
+}
+
+// Purge removes every cached response
+func (r *RedisCache) Purge() error {
+	cursor := "0"
+	for {
+		reply, err := r.client.Do("SCAN", cursor, "MATCH", redisKeyPrefix+"*", "COUNT", "100")
+		if err != nil {
+			return err
+		}
+		result, ok := reply.([]interface{})
+		if !ok || len(result) != 2 {
+			return errors.New("unexpected SCAN reply")
+		}
+
+		keys, _ := result[1].([]interface{})
+		if len(keys) > 0 {
+			args := []string{"DEL"}
+			for _, key := range keys {
+				args = append(args, key.(string))
+			}
+			if _, err := r.client.Do(args...); err != nil {
+				return err
+			}
+		}
+
+		if cursor, _ = result[0].(string); cursor == "0" {
+			return nil
+		}
+	}
+}
+
+// redisKey returns the Redis key of a URL
+func redisKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return redisKeyPrefix + hex.EncodeToString(sum[:])
+}
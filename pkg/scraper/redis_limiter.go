@@ -0,0 +1,85 @@
+package scraper
+
+import (
+	"strconv"
+	"time"
+
+	"universal_api/internal/redis"
+)
+
+// redisLimiterPrefix namespaces the politeness state's keys in a shared Redis
+const redisLimiterPrefix = "universal_api:politeness:"
+
+// redisActiveTTL is how long a host's count of running fetches is kept after its last change, so the
+// fetches of an instance that stopped before releasing them don't hold the host's slots forever
+const redisActiveTTL = 5 * time.Minute
+
+// RedisLimiter keeps the politeness state of hosts in Redis, so their intervals and concurrency caps
+// hold across restarts and every instance of the service
+type RedisLimiter struct {
+	client *redis.Client
+}
+
+// NewRedisLimiter creates a limiter on the Redis server at a redis://[:password@]host:port[/db] URL
+func NewRedisLimiter(rawURL string) (*RedisLimiter, error) {
+	client, err := redis.Dial(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisLimiter{client: client}, nil
+}
+
+// Reserve starts a fetch of a host if it's under its concurrency cap and no other fetch of it started
+// within interval. The interval is a key set only if absent, expiring after the interval.
+func (r *RedisLimiter) Reserve(host string, interval time.Duration, maxActive int) (bool, time.Duration, error) {
+	activeKey, nextKey := redisLimiterPrefix+"active:"+host, redisLimiterPrefix+"next:"+host
+
+	reply, err := r.client.Do("INCR", activeKey)
+	if err != nil {
+		return false, 0, err
+	}
+	r.client.Do("PEXPIRE", activeKey, milliseconds(redisActiveTTL))
+	if active, _ := reply.(int64); maxActive > 0 && active > int64(maxActive) {
+		_, err := r.client.Do("DECR", activeKey)
+		return false, 0, err
+	}
+
+	if interval > 0 {
+		reply, err := r.client.Do("SET", nextKey, "1", "PX", milliseconds(interval), "NX")
+		if err != nil || reply == nil {
+			r.client.Do("DECR", activeKey)
+		}
+		if err != nil {
+			return false, 0, err
+		}
+		if reply == nil {
+			// Another fetch started within the interval, which ends when its key expires
+			ttl, err := r.client.Do("PTTL", nextKey)
+			remaining, _ := ttl.(int64)
+			return false, time.Duration(remaining) * time.Millisecond, err
+		}
+	}
+	return true, 0, nil
+}
+
+// Release ends a fetch of a host
+func (r *RedisLimiter) Release(host string) error {
+	activeKey := redisLimiterPrefix + "active:" + host
+	reply, err := r.client.Do("DECR", activeKey)
+	if err != nil {
+		return err
+	}
+	// The count expired while the fetch ran
+	if active, _ := reply.(int64); active < 0 {
+		_, err = r.client.Do("DEL", activeKey)
+	}
+	return err
+}
+
+// milliseconds formats a duration as a whole number of milliseconds, at least 1, for PX and PEXPIRE
+func milliseconds(d time.Duration) string {
+	if d < time.Millisecond {
+		d = time.Millisecond
+	}
+	return strconv.FormatInt(d.Milliseconds(), 10)
+}
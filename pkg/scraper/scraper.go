@@ -0,0 +1,247 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"universal_api/internal/models"
+	"universal_api/pkg/parser"
+)
+
+// RequestAuth adds credentials to a request for documentation behind a login
+type RequestAuth func(req *http.Request)
+
+// Options controls how documentation is fetched and parsed
+type Options struct {
+	Auth        RequestAuth              // adds credentials to every request
+	Cache       bool                     // reuse responses fetched within the fetch cache TTL
+	Rules       *parser.Rules            // overrides the configured heuristics of HTML docs
+	Client      *http.Client             // transport, timeout, and cookies of requests; redirects are always checked
+	MaxBodySize int64                    // largest documentation fetched, in bytes; 0 for no limit
+	Parsers     map[Format]parser.Parser // replace the built-in parsers of formats
+	Format      Format                   // parse documentation as this format instead of detecting it
+	Hooks       []Hook                   // run on every parsed doc after the configured hooks
+	Context     context.Context          // cancels fetches when done, such as when the request asking for the doc ends
+}
+
+// htmlOptions controls how scraped HTML docs summarize long descriptions and find and keep endpoints
+var htmlOptions struct {
+	sync.RWMutex
+	summary          parser.SummaryOptions
+	keepUnknownPaths bool
+	rules            *parser.Rules
+}
+
+// SetSummaryOptions sets how scraped HTML docs summarize long descriptions
+func SetSummaryOptions(options parser.SummaryOptions) {
+	htmlOptions.Lock()
+	defer htmlOptions.Unlock()
+	htmlOptions.summary = options
+}
+
+// SetKeepUnknownPaths sets whether scraped HTML docs keep endpoints whose path couldn't be found
+func SetKeepUnknownPaths(keep bool) {
+	htmlOptions.Lock()
+	defer htmlOptions.Unlock()
+	htmlOptions.keepUnknownPaths = keep
+}
+
+// SetRules sets the heuristics scraped HTML docs are parsed with, the defaults when nil
+func SetRules(rules *parser.Rules) {
+	htmlOptions.Lock()
+	defer htmlOptions.Unlock()
+	htmlOptions.rules = rules
+}
+
+// Rules returns the heuristics scraped HTML docs are parsed with
+func Rules() *parser.Rules {
+	htmlOptions.RLock()
+	defer htmlOptions.RUnlock()
+	return parser.DefaultRules().Override(htmlOptions.rules)
+}
+
+// htmlParser returns an HTML parser with the configured options, its rules overridden by those of a request
+func htmlParser(override *parser.Rules) parser.Parser {
+	htmlOptions.RLock()
+	defer htmlOptions.RUnlock()
+	return &parser.HTMLParser{
+		Summary:          htmlOptions.summary,
+		KeepUnknownPaths: htmlOptions.keepUnknownPaths,
+		Rules:            parser.DefaultRules().Override(htmlOptions.rules).Override(override),
+	}
+}
+
+// ScrapeAPIDoc scrapes API documentation from the given URL
+func ScrapeAPIDoc(url string) (*models.APIDoc, error) {
+	return ScrapeAPIDocWithOptions(url, Options{})
+}
+
+// ScrapeAPIDocWithAuth scrapes API documentation from the given URL, authenticating requests with auth
+func ScrapeAPIDocWithAuth(url string, auth RequestAuth) (*models.APIDoc, error) {
+	return ScrapeAPIDocWithOptions(url, Options{Auth: auth})
+}
+
+// ScrapeAPIDocWithOptions scrapes API documentation from the given URL
+func ScrapeAPIDocWithOptions(url string, options Options) (*models.APIDoc, error) {
+	return NewPipeline(options).Scrape(url)
+}
+
+// fetch makes a GET request for the documentation, through the fetch cache when enabled
+func (o Options) fetch(url string) (*http.Response, error) {
+	ctx := o.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if o.Cache {
+		return cachedFetch(ctx, url, o.Auth, o.Client)
+	}
+	return fetch(ctx, url, o.Auth, o.Client)
+}
+
+// fetch makes a GET request following the host's scrape policy and circuit breaker, adding credentials
+// when auth is set. Credentials are dropped when the documentation host redirects to another host.
+// The request is made with the transport, timeout, and cookie jar of base, when set, and canceled with ctx.
+func fetch(ctx context.Context, url string, auth RequestAuth, base *http.Client) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, release, err := politeness.acquire(req.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+	if err := breakers.allow(req.URL.Host); err != nil {
+		release()
+		return nil, err
+	}
+
+	applyPolicy(req, policy)
+	if auth != nil {
+		auth(req)
+	}
+
+	client := &http.Client{
+		CheckRedirect: func(next *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			if next.URL.Host != via[0].URL.Host {
+				next.Header = http.Header{}
+				applyPolicy(next, models.ScrapePolicy{UserAgent: policy.UserAgent})
+			} else if auth != nil {
+				auth(next)
+			}
+			return nil
+		},
+	}
+	if base != nil {
+		client.Transport, client.Timeout, client.Jar = base.Transport, base.Timeout, base.Jar
+	}
+
+	resp, err := client.Do(req)
+	if ctx.Err() != nil {
+		// A fetch given up by its caller says nothing about the host
+		breakers.abandon(req.URL.Host)
+	} else {
+		breakers.record(req.URL.Host, resp, err)
+	}
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	// The fetch counts against the host's concurrency cap until its body is closed
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releasingBody releases a politeness slot when the response body is closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+// Close closes the body and releases the slot
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// isSwaggerURL checks if the URL is for Swagger/OpenAPI documentation
+func isSwaggerURL(url string) bool {
+	return strings.Contains(url, "swagger") ||
+		strings.Contains(url, "openapi") ||
+		strings.Contains(url, "api-docs")
+}
+
+// ParseAPIDoc parses already-fetched API documentation, choosing a parser from
+// the content type or, failing that, from the content itself
+func ParseAPIDoc(content []byte, contentType string) (*models.APIDoc, error) {
+	content, encoding, err := decodeContent(content, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Content that wasn't fetched has no URL to record or to discover specs from
+	pipeline := NewPipeline(Options{})
+	pipeline.PostProcess = []PostProcessStage{recordSource, runHooks(nil)}
+	return pipeline.Process(&Document{Content: content, ContentType: contentType, Encoding: encoding})
+}
+
+// ParseSource re-parses documentation fetched earlier from a URL, as archived, with the current
+// parsers. The format is detected unless one is given. Pages are parsed themselves; the specs they
+// link to aren't fetched again.
+func ParseSource(url string, content []byte, contentType string, format Format) (*models.APIDoc, error) {
+	content, encoding, err := decodeContent(content, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	options := Options{Format: format}
+	pipeline := NewPipeline(options)
+	pipeline.Select = func(doc *Document) parser.Parser {
+		if doc.Format == FormatHTML {
+			return htmlParser(nil)
+		}
+		return options.selectParser(doc)
+	}
+	return pipeline.Process(&Document{URL: url, Content: content, ContentType: contentType, Encoding: encoding})
+}
+
+// Helper functions
+
+// isJSON checks if content is likely JSON
+func isJSON(content []byte) bool {
+	trimmed := strings.TrimSpace(string(content))
+	return (strings.HasPrefix(trimmed, "{") && strings.HasSuffix(trimmed, "}")) ||
+		(strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]"))
+}
+
+// isYAML checks if content is likely YAML
+func isYAML(content []byte) bool {
+	// Simple heuristic: YAML often has key: value pairs
+	trimmed := strings.TrimSpace(string(content))
+
+	// If it looks like JSON, it's not YAML
+	if isJSON(content) {
+		return false
+	}
+
+	lines := strings.Split(trimmed, "\n")
+	for _, line := range lines {
+		// Look for key: value pattern but exclude JSON-like patterns
+		if strings.Contains(line, ":") &&
+			!strings.Contains(line, "{:") &&
+			!strings.Contains(line, "\":") {
+			return true
+		}
+	}
+	return false
+}
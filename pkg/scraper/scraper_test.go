@@ -28,28 +28,6 @@ func TestIsSwaggerURL(t *testing.T) {
 	}
 }
 
-// TestIsRESTDocURL tests the isRESTDocURL function
-func TestIsRESTDocURL(t *testing.T) {
-	tests := []struct {
-		url      string
-		expected bool
-	}{
-		{"https://example.com/api/doc", true},
-		{"https://example.com/api/docs", true},
-		{"https://example.com/api/reference", true},
-		{"https://example.com/docs", false},
-		{"https://example.com/reference", false},
-		{"https://example.com/api", false},
-	}
-
-	for _, test := range tests {
-		result := isRESTDocURL(test.url)
-		if result != test.expected {
-			t.Errorf("isRESTDocURL(%s) = %v; expected %v", test.url, result, test.expected)
-		}
-	}
-}
-
 // TestIsJSON tests the isJSON function
 func TestIsJSON(t *testing.T) {
 	tests := []struct {
@@ -150,3 +128,46 @@ func TestScrapeAPIDoc(t *testing.T) {
 		t.Errorf("Expected title 'Test API Documentation', got '%s'", htmlDoc.Title)
 	}
 }
+
+// TestParseAPIDoc tests parsing already-fetched content with and without a content type
+func TestParseAPIDoc(t *testing.T) {
+	yamlDoc, err := ParseAPIDoc([]byte("openapi: 3.0.0\ninfo:\n  title: YAML API\n  version: 1.0.0\npaths: {}\n"), "")
+	if err != nil {
+		t.Fatalf("Failed to parse YAML API doc: %v", err)
+	}
+
+	if yamlDoc.Title != "YAML API" {
+		t.Errorf("Expected title 'YAML API', got '%s'", yamlDoc.Title)
+	}
+
+	jsonDoc, err := ParseAPIDoc([]byte(`{"swagger": "2.0", "info": {"title": "JSON API"}, "paths": {}}`), "application/json")
+	if err != nil {
+		t.Fatalf("Failed to parse JSON API doc: %v", err)
+	}
+
+	if jsonDoc.Title != "JSON API" {
+		t.Errorf("Expected title 'JSON API', got '%s'", jsonDoc.Title)
+	}
+}
+
+// TestParseSource tests re-parsing archived content without fetching the spec a page links to
+func TestParseSource(t *testing.T) {
+	page := []byte("<html><head><title>Caf\xe9</title><link rel=\"openapi\" href=\"http://127.0.0.1:1/openapi.json\"></head>" +
+		"<body><h2>GET /cafes</h2><link rel=\"icon\" href=\"/icon.png\"></body></html>")
+
+	apiDoc, err := ParseSource("https://example.com/docs", page, "text/html; charset=iso-8859-1", "")
+	if err != nil {
+		t.Fatalf("Failed to parse source: %v", err)
+	}
+	if apiDoc.Title != "Café" || apiDoc.SpecURL != "" || len(apiDoc.Endpoints) != 1 {
+		t.Errorf("Expected the page itself, transcoded, got %s (spec %q) with %d endpoints", apiDoc.Title, apiDoc.SpecURL, len(apiDoc.Endpoints))
+	}
+	if apiDoc.URL != "https://example.com/docs" || apiDoc.IconURL != "https://example.com/icon.png" {
+		t.Errorf("Expected the source's URL and an icon resolved against it, got %q and %q", apiDoc.URL, apiDoc.IconURL)
+	}
+
+	spec := []byte("asyncapi: 2.6.0\ninfo:\n  title: Events\n  version: 1.0.0\nchannels: {}\n")
+	if apiDoc, err := ParseSource("https://example.com/events.yaml", spec, "text/plain", FormatAsyncAPI); err != nil || apiDoc.Title != "Events" {
+		t.Errorf("Expected the AsyncAPI doc of the format hint, got %v (%v)", apiDoc, err)
+	}
+}